@@ -23,6 +23,7 @@ import (
 	"time"
 
 	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/metrics"
 	"github.com/aws/amazon-ecs-agent/agent/wsclient"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/cihub/seelog"
@@ -39,6 +40,7 @@ type clientServer struct {
 func New(url string, cfg *config.Config, credentialProvider *credentials.Credentials, rwTimeout time.Duration) wsclient.ClientServer {
 	cs := &clientServer{}
 	cs.URL = url
+	cs.LatencyMetricsService = metrics.ACSService
 	cs.CredentialProvider = credentialProvider
 	cs.AgentConfig = cfg
 	cs.ServiceError = &acsError{}
@@ -50,6 +50,9 @@ func init() {
 		ecsacs.TaskManifestMessage{},
 		ecsacs.TaskStopVerificationAck{},
 		ecsacs.TaskStopVerificationMessage{},
+		ecsacs.TaskRuntimeSettingsMessage{},
+		ecsacs.InstanceDrainMessage{},
+		ecsacs.InstanceDrainProgressMessage{},
 	}
 }
 
@@ -31,6 +31,7 @@ import (
 	rolecredentials "github.com/aws/amazon-ecs-agent/agent/credentials"
 	"github.com/aws/amazon-ecs-agent/agent/engine"
 	"github.com/aws/amazon-ecs-agent/agent/engine/dockerstate"
+	"github.com/aws/amazon-ecs-agent/agent/errorbudget"
 	"github.com/aws/amazon-ecs-agent/agent/eventhandler"
 	"github.com/aws/amazon-ecs-agent/agent/eventstream"
 	"github.com/aws/amazon-ecs-agent/agent/statemanager"
@@ -91,6 +92,7 @@ type session struct {
 	backoff                         retry.Backoff
 	resources                       sessionResources
 	latestSeqNumTaskManifest        *int64
+	attachmentTracker               *attachmentTracker
 	_heartbeatTimeout               time.Duration
 	_heartbeatJitter                time.Duration
 	_inactiveInstanceReconnectDelay time.Duration
@@ -166,6 +168,7 @@ func NewSession(ctx context.Context,
 		backoff:                         backoff,
 		resources:                       resources,
 		latestSeqNumTaskManifest:        latestSeqNumTaskManifest,
+		attachmentTracker:               newAttachmentTracker(),
 		_heartbeatTimeout:               heartbeatTimeout,
 		_heartbeatJitter:                heartbeatJitter,
 		_inactiveInstanceReconnectDelay: inactiveInstanceReconnectDelay,
@@ -214,6 +217,7 @@ func (acsSession *session) Start() error {
 			} else {
 				// Disconnected unexpectedly from ACS, compute backoff duration to
 				// reconnect
+				errorbudget.Global.RecordFailure(errorbudget.SourceACSDisconnect, acsError)
 				reconnectDelay := acsSession.computeReconnectDelay(isInactiveInstance)
 				seelog.Infof("Reconnecting to ACS in: %s", reconnectDelay.String())
 				waitComplete := acsSession.waitForDuration(reconnectDelay)
@@ -268,6 +272,22 @@ func (acsSession *session) startACSSession(client wsclient.ClientServer) error {
 
 	client.AddRequestHandler(refreshCredsHandler.handlerFunc())
 
+	taskRuntimeSettingsHandler := newTaskRuntimeSettingsHandler(acsSession.ctx, cfg.Cluster,
+		acsSession.containerInstanceARN, client, acsSession.taskEngine)
+	defer taskRuntimeSettingsHandler.clearAcks()
+	taskRuntimeSettingsHandler.start()
+	defer taskRuntimeSettingsHandler.stop()
+
+	client.AddRequestHandler(taskRuntimeSettingsHandler.handlerFunc())
+
+	instanceDrainHandler := newInstanceDrainHandler(acsSession.ctx, cfg.Cluster,
+		acsSession.containerInstanceARN, client, acsSession.taskEngine, cfg.InstanceDrainGracePeriod)
+	defer instanceDrainHandler.clearAcks()
+	instanceDrainHandler.start()
+	defer instanceDrainHandler.stop()
+
+	client.AddRequestHandler(instanceDrainHandler.handlerFunc())
+
 	// Add handler to ack task ENI attach message
 	eniAttachHandler := newAttachTaskENIHandler(
 		acsSession.ctx,
@@ -276,6 +296,8 @@ func (acsSession *session) startACSSession(client wsclient.ClientServer) error {
 		client,
 		acsSession.state,
 		acsSession.stateManager,
+		acsSession.attachmentTracker,
+		cfg.ENIAttachmentAckTimeout,
 	)
 	eniAttachHandler.start()
 	defer eniAttachHandler.stop()
@@ -290,6 +312,8 @@ func (acsSession *session) startACSSession(client wsclient.ClientServer) error {
 		client,
 		acsSession.state,
 		acsSession.stateManager,
+		acsSession.attachmentTracker,
+		cfg.ENIAttachmentAckTimeout,
 	)
 	instanceENIAttachHandler.start()
 	defer instanceENIAttachHandler.stop()
@@ -21,6 +21,7 @@ import (
 	apieni "github.com/aws/amazon-ecs-agent/agent/api/eni"
 	"github.com/aws/amazon-ecs-agent/agent/engine/dockerstate"
 	"github.com/aws/amazon-ecs-agent/agent/statemanager"
+	"github.com/aws/amazon-ecs-agent/agent/utils/retry"
 	"github.com/aws/amazon-ecs-agent/agent/wsclient"
 	"github.com/aws/aws-sdk-go/aws"
 
@@ -28,6 +29,16 @@ import (
 	"github.com/pkg/errors"
 )
 
+const (
+	// ackRetryNum is the number of times an attachment message is acked before
+	// giving up, ACS resends the attachments it doesn't get an ack for
+	ackRetryNum             = 5
+	ackRetryBackoffMin      = 100 * time.Millisecond
+	ackRetryBackoffMax      = 5 * time.Second
+	ackRetryBackoffJitter   = 0.2
+	ackRetryBackoffMultiple = 2
+)
+
 // ackTimeoutHandler remove ENI attachment from agent state after the ENI ack timeout
 type ackTimeoutHandler struct {
 	mac   string
@@ -46,36 +57,64 @@ func (handler *ackTimeoutHandler) handle() {
 	}
 }
 
-// sendAck sends ack for a certain ACS message
+// sendAck sends ack for a certain ACS message, retrying with backoff if the ack
+// can't be sent
 func sendAck(acsClient wsclient.ClientServer, clusterArn *string, containerInstanceArn *string, messageId *string) {
-	if err := acsClient.MakeRequest(&ecsacs.AckRequest{
-		Cluster:           clusterArn,
-		ContainerInstance: containerInstanceArn,
-		MessageId:         messageId,
-	}); err != nil {
-		seelog.Warnf("Failed to ack request with messageId: %s, error: %v", aws.StringValue(messageId), err)
+	backoff := retry.NewExponentialBackoff(ackRetryBackoffMin, ackRetryBackoffMax,
+		ackRetryBackoffJitter, ackRetryBackoffMultiple)
+	err := retry.RetryNWithBackoff(backoff, ackRetryNum, func() error {
+		err := acsClient.MakeRequest(&ecsacs.AckRequest{
+			Cluster:           clusterArn,
+			ContainerInstance: containerInstanceArn,
+			MessageId:         messageId,
+		})
+		if err != nil {
+			seelog.Warnf("Failed to ack request with messageId: %s, will retry: %v", aws.StringValue(messageId), err)
+		}
+		return err
+	})
+	if err != nil {
+		seelog.Errorf("Failed to ack request with messageId: %s, error: %v", aws.StringValue(messageId), err)
 	}
 }
 
+// attachmentAckTimeout returns the duration to wait for an attachment to be
+// acknowledged before it is removed from state. The timeout configured on the
+// instance, if any, takes precedence over the one specified in the message.
+func attachmentAckTimeout(waitTimeoutMs *int64, configuredTimeout time.Duration) time.Duration {
+	if configuredTimeout > 0 {
+		return configuredTimeout
+	}
+	return time.Duration(aws.Int64Value(waitTimeoutMs)) * time.Millisecond
+}
+
 // handleENIAttachment handles an ENI attachment via the following:
 // 1. Check whether we already have this attachment in state, if so, start its ack timer and return
-// 2. Otherwise add the attachment to state, start its ack timer, and save the state
+// 2. Check whether the attachment was already processed and removed from state, if so, drop the stale message
+// 3. Otherwise add the attachment to state, start its ack timer, and save the state
 // These are common tasks for handling a task ENI attachment and an instance ENI attachment, so they are put
 // into this function to be shared by both attachment handlers
 func handleENIAttachment(attachmentType, attachmentARN, taskARN, mac string,
 	expiresAt time.Time,
 	state dockerstate.TaskEngineState,
-	saver statemanager.Saver) error {
+	saver statemanager.Saver,
+	tracker *attachmentTracker) error {
 	seelog.Infof("Handling ENI attachment: %s", attachmentARN)
 
 	if eniAttachment, ok := state.ENIByMac(mac); ok {
 		seelog.Infof("Duplicate %s attachment message for ENI with MAC address: %s", attachmentType, mac)
+		tracker.record(attachmentARN, expiresAt)
 		eniAckTimeoutHandler := ackTimeoutHandler{mac: mac, state: state}
 		return eniAttachment.StartTimer(eniAckTimeoutHandler.handle)
 	}
+	if tracker.seen(attachmentARN) {
+		seelog.Warnf("Ignoring %s attachment message for already processed attachment: %s", attachmentType, attachmentARN)
+		return nil
+	}
 	if err := addENIAttachmentToState(attachmentType, attachmentARN, taskARN, mac, expiresAt, state); err != nil {
 		return errors.Wrapf(err, fmt.Sprintf("attach %s message handler: unable to add eni attachment to engine state", attachmentType))
 	}
+	tracker.record(attachmentARN, expiresAt)
 	if err := saver.Save(); err != nil {
 		return errors.Wrapf(err, fmt.Sprintf("attach %s message handler: unable to save agent state", attachmentType))
 	}
@@ -16,12 +16,16 @@
 package handler
 
 import (
+	"errors"
 	"testing"
 	"time"
 
+	"github.com/aws/amazon-ecs-agent/agent/acs/model/ecsacs"
 	apieni "github.com/aws/amazon-ecs-agent/agent/api/eni"
 	"github.com/aws/amazon-ecs-agent/agent/engine/dockerstate"
 	"github.com/aws/amazon-ecs-agent/agent/statemanager"
+	mock_wsclient "github.com/aws/amazon-ecs-agent/agent/wsclient/mock"
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 )
@@ -103,7 +107,7 @@ func testHandleENIAttachment(t *testing.T, attachmentType, taskArn string) {
 	taskEngineState := dockerstate.NewTaskEngineState()
 	expiresAt := time.Now().Add(time.Millisecond * waitTimeoutMillis)
 	stateManager := statemanager.NewNoopStateManager()
-	err := handleENIAttachment(attachmentType, attachmentArn, taskArn, randomMAC, expiresAt, taskEngineState, stateManager)
+	err := handleENIAttachment(attachmentType, attachmentArn, taskArn, randomMAC, expiresAt, taskEngineState, stateManager, newAttachmentTracker())
 	assert.NoError(t, err)
 	assert.Len(t, taskEngineState.(*dockerstate.DockerTaskEngineState).AllENIAttachments(), 1)
 	eniAttachment, ok := taskEngineState.(*dockerstate.DockerTaskEngineState).ENIByMac(randomMAC)
@@ -114,3 +118,53 @@ func testHandleENIAttachment(t *testing.T, attachmentType, taskArn string) {
 
 	assert.Len(t, taskEngineState.(*dockerstate.DockerTaskEngineState).AllENIAttachments(), 1)
 }
+
+// TestHandleENIAttachmentIgnoresProcessedAttachment tests that a redelivered attachment
+// message is dropped once the attachment has been removed from state
+func TestHandleENIAttachmentIgnoresProcessedAttachment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	taskEngineState := dockerstate.NewTaskEngineState()
+	expiresAt := time.Now().Add(time.Millisecond * waitTimeoutMillis)
+	stateManager := statemanager.NewNoopStateManager()
+	tracker := newAttachmentTracker()
+	err := handleENIAttachment(apieni.ENIAttachmentTypeTaskENI, attachmentArn, taskArn, randomMAC, expiresAt, taskEngineState, stateManager, tracker)
+	assert.NoError(t, err)
+	assert.Len(t, taskEngineState.(*dockerstate.DockerTaskEngineState).AllENIAttachments(), 1)
+
+	taskEngineState.RemoveENIAttachment(randomMAC)
+	err = handleENIAttachment(apieni.ENIAttachmentTypeTaskENI, attachmentArn, taskArn, randomMAC, expiresAt, taskEngineState, stateManager, tracker)
+	assert.NoError(t, err)
+	assert.Empty(t, taskEngineState.(*dockerstate.DockerTaskEngineState).AllENIAttachments())
+}
+
+func TestAttachmentAckTimeout(t *testing.T) {
+	assert.Equal(t, 2*time.Second, attachmentAckTimeout(aws.Int64(2000), 0))
+	assert.Equal(t, time.Minute, attachmentAckTimeout(aws.Int64(2000), time.Minute))
+}
+
+// TestSendAckRetries tests that an attachment message ack is retried until it's sent
+func TestSendAckRetries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockWSClient := mock_wsclient.NewMockClientServer(ctrl)
+	gomock.InOrder(
+		mockWSClient.EXPECT().MakeRequest(gomock.Any()).Return(errors.New("error")),
+		mockWSClient.EXPECT().MakeRequest(gomock.Any()).Do(func(ackRequest *ecsacs.AckRequest) {
+			assert.Equal(t, eniMessageId, aws.StringValue(ackRequest.MessageId))
+		}).Return(nil),
+	)
+	sendAck(mockWSClient, aws.String(clusterName), aws.String(containerInstanceArn), aws.String(eniMessageId))
+}
+
+// TestSendAckGivesUp tests that an attachment message ack is only retried a few times
+func TestSendAckGivesUp(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockWSClient := mock_wsclient.NewMockClientServer(ctrl)
+	mockWSClient.EXPECT().MakeRequest(gomock.Any()).Return(errors.New("error")).Times(ackRetryNum)
+	sendAck(mockWSClient, aws.String(clusterName), aws.String(containerInstanceArn), aws.String(eniMessageId))
+}
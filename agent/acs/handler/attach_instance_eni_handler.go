@@ -39,6 +39,8 @@ type attachInstanceENIHandler struct {
 	containerInstance *string
 	acsClient         wsclient.ClientServer
 	state             dockerstate.TaskEngineState
+	tracker           *attachmentTracker
+	ackTimeout        time.Duration
 }
 
 // newAttachInstanceENIHandler returns an instance of the attachInstanceENIHandler struct
@@ -47,7 +49,9 @@ func newAttachInstanceENIHandler(ctx context.Context,
 	containerInstanceArn string,
 	acsClient wsclient.ClientServer,
 	taskEngineState dockerstate.TaskEngineState,
-	saver statemanager.Saver) attachInstanceENIHandler {
+	saver statemanager.Saver,
+	tracker *attachmentTracker,
+	ackTimeout time.Duration) attachInstanceENIHandler {
 	// Create a cancelable context from the parent context
 	derivedContext, cancel := context.WithCancel(ctx)
 	return attachInstanceENIHandler{
@@ -59,6 +63,8 @@ func newAttachInstanceENIHandler(ctx context.Context,
 		acsClient:         acsClient,
 		state:             taskEngineState,
 		saver:             saver,
+		tracker:           tracker,
+		ackTimeout:        ackTimeout,
 	}
 }
 
@@ -108,8 +114,8 @@ func (handler *attachInstanceENIHandler) handleSingleMessage(message *ecsacs.Att
 	// Handle the attachment
 	attachmentARN := aws.StringValue(message.ElasticNetworkInterfaces[0].AttachmentArn)
 	mac := aws.StringValue(message.ElasticNetworkInterfaces[0].MacAddress)
-	expiresAt := receivedAt.Add(time.Duration(aws.Int64Value(message.WaitTimeoutMs)) * time.Millisecond)
-	return handleENIAttachment(apieni.ENIAttachmentTypeInstanceENI, attachmentARN, "", mac, expiresAt, handler.state, handler.saver)
+	expiresAt := receivedAt.Add(attachmentAckTimeout(message.WaitTimeoutMs, handler.ackTimeout))
+	return handleENIAttachment(apieni.ENIAttachmentTypeInstanceENI, attachmentARN, "", mac, expiresAt, handler.state, handler.saver, handler.tracker)
 }
 
 // validateAttachInstanceNetworkInterfacesMessage performs validation checks on the
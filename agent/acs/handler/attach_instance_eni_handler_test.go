@@ -151,7 +151,7 @@ func TestInstanceENIAckSingleMessage(t *testing.T) {
 
 	ctx := context.TODO()
 	mockWSClient := mock_wsclient.NewMockClientServer(ctrl)
-	handler := newAttachInstanceENIHandler(ctx, clusterName, containerInstanceArn, mockWSClient, taskEngineState, manager)
+	handler := newAttachInstanceENIHandler(ctx, clusterName, containerInstanceArn, mockWSClient, taskEngineState, manager, newAttachmentTracker(), 0)
 
 	var ackSent sync.WaitGroup
 	ackSent.Add(1)
@@ -202,7 +202,7 @@ func TestInstanceENIAckSingleMessageDuplicateENIAttachmentMessageStartsTimer(t *
 
 	ctx := context.TODO()
 	mockWSClient := mock_wsclient.NewMockClientServer(ctrl)
-	handler := newAttachInstanceENIHandler(ctx, clusterName, containerInstanceArn, mockWSClient, mockState, manager)
+	handler := newAttachInstanceENIHandler(ctx, clusterName, containerInstanceArn, mockWSClient, mockState, manager, newAttachmentTracker(), 0)
 
 	// To check that the timer is started, we set the expiresAt value of the attachment to be a value in the past
 	// to trigger an error in attachment.StartTimer and checks the error
@@ -254,7 +254,7 @@ func TestInstanceENIAckHappyPath(t *testing.T) {
 	manager := mock_statemanager.NewMockStateManager(ctrl)
 
 	mockWSClient := mock_wsclient.NewMockClientServer(ctrl)
-	handler := newAttachInstanceENIHandler(ctx, clusterName, containerInstanceArn, mockWSClient, taskEngineState, manager)
+	handler := newAttachInstanceENIHandler(ctx, clusterName, containerInstanceArn, mockWSClient, taskEngineState, manager, newAttachmentTracker(), 0)
 
 	var ackSent sync.WaitGroup
 	ackSent.Add(1)
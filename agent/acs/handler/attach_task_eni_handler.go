@@ -39,6 +39,8 @@ type attachTaskENIHandler struct {
 	containerInstance *string
 	acsClient         wsclient.ClientServer
 	state             dockerstate.TaskEngineState
+	tracker           *attachmentTracker
+	ackTimeout        time.Duration
 }
 
 // newAttachTaskENIHandler returns an instance of the attachENIHandler struct
@@ -47,7 +49,9 @@ func newAttachTaskENIHandler(ctx context.Context,
 	containerInstanceArn string,
 	acsClient wsclient.ClientServer,
 	taskEngineState dockerstate.TaskEngineState,
-	saver statemanager.Saver) attachTaskENIHandler {
+	saver statemanager.Saver,
+	tracker *attachmentTracker,
+	ackTimeout time.Duration) attachTaskENIHandler {
 
 	// Create a cancelable context from the parent context
 	derivedContext, cancel := context.WithCancel(ctx)
@@ -60,6 +64,8 @@ func newAttachTaskENIHandler(ctx context.Context,
 		acsClient:         acsClient,
 		state:             taskEngineState,
 		saver:             saver,
+		tracker:           tracker,
+		ackTimeout:        ackTimeout,
 	}
 }
 
@@ -106,12 +112,18 @@ func (attachTaskENIHandler *attachTaskENIHandler) handleSingleMessage(message *e
 	// Send ACK
 	go sendAck(attachTaskENIHandler.acsClient, message.ClusterArn, message.ContainerInstanceArn, message.MessageId)
 
-	// Handle the attachment
-	attachmentARN := aws.StringValue(message.ElasticNetworkInterfaces[0].AttachmentArn)
+	// Handle the attachment of each of the task's ENIs
 	taskARN := aws.StringValue(message.TaskArn)
-	mac := aws.StringValue(message.ElasticNetworkInterfaces[0].MacAddress)
-	expiresAt := receivedAt.Add(time.Duration(aws.Int64Value(message.WaitTimeoutMs)) * time.Millisecond)
-	return handleENIAttachment(apieni.ENIAttachmentTypeTaskENI, attachmentARN, taskARN, mac, expiresAt, attachTaskENIHandler.state, attachTaskENIHandler.saver)
+	expiresAt := receivedAt.Add(attachmentAckTimeout(message.WaitTimeoutMs, attachTaskENIHandler.ackTimeout))
+	for _, eni := range message.ElasticNetworkInterfaces {
+		attachmentARN := aws.StringValue(eni.AttachmentArn)
+		mac := aws.StringValue(eni.MacAddress)
+		if err := handleENIAttachment(apieni.ENIAttachmentTypeTaskENI, attachmentARN, taskARN, mac, expiresAt,
+			attachTaskENIHandler.state, attachTaskENIHandler.saver, attachTaskENIHandler.tracker); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // validateAttachTaskNetworkInterfacesMessage performs validation checks on the
@@ -137,13 +149,20 @@ func validateAttachTaskNetworkInterfacesMessage(message *ecsacs.AttachTaskNetwor
 	}
 
 	enis := message.ElasticNetworkInterfaces
-	if len(enis) != 1 {
-		return errors.Errorf("attach eni handler validation: incorrect number of ENIs in AttachTaskNetworkInterface message received from ECS. Obtained %d", len(enis))
+	if len(enis) == 0 {
+		return errors.Errorf("attach eni handler validation: no ENIs in AttachTaskNetworkInterface message received from ECS")
 	}
 
-	eni := enis[0]
-	if aws.StringValue(eni.MacAddress) == "" {
-		return errors.Errorf("attach eni handler validation: MACAddress not listed in AttachTaskNetworkInterface message received from ECS")
+	macs := make(map[string]struct{})
+	for _, eni := range enis {
+		mac := aws.StringValue(eni.MacAddress)
+		if mac == "" {
+			return errors.Errorf("attach eni handler validation: MACAddress not listed in AttachTaskNetworkInterface message received from ECS")
+		}
+		if _, ok := macs[mac]; ok {
+			return errors.Errorf("attach eni handler validation: duplicate MACAddress %s in AttachTaskNetworkInterface message received from ECS", mac)
+		}
+		macs[mac] = struct{}{}
 	}
 
 	taskArn := aws.StringValue(message.TaskArn)
@@ -96,9 +96,36 @@ func TestAttachENIMessageWithNoInterfaces(t *testing.T) {
 	assert.Error(t, err)
 }
 
-// TestAttachENIMessageWithMultipleInterfaceschecks checks the validator against an
+// TestAttachENIMessageWithMultipleInterfaces checks the validator against an
 // AttachTaskNetworkInterfacesMessage with multiple interfaces
 func TestAttachENIMessageWithMultipleInterfaces(t *testing.T) {
+	mockNetInterface1 := ecsacs.ElasticNetworkInterface{
+		MacAddress: aws.String(randomMAC),
+		Ec2Id:      aws.String("1"),
+	}
+	mockNetInterface2 := ecsacs.ElasticNetworkInterface{
+		MacAddress: aws.String("00:0a:95:9d:68:17"),
+		Ec2Id:      aws.String("2"),
+	}
+	message := &ecsacs.AttachTaskNetworkInterfacesMessage{
+		MessageId:            aws.String(eniMessageId),
+		ClusterArn:           aws.String(clusterName),
+		ContainerInstanceArn: aws.String(containerInstanceArn),
+		ElasticNetworkInterfaces: []*ecsacs.ElasticNetworkInterface{
+			&mockNetInterface1,
+			&mockNetInterface2,
+		},
+		TaskArn:       aws.String(taskArn),
+		WaitTimeoutMs: aws.Int64(waitTimeoutMillis),
+	}
+
+	err := validateAttachTaskNetworkInterfacesMessage(message)
+	assert.NoError(t, err)
+}
+
+// TestAttachENIMessageWithDuplicateInterfaces checks the validator against an
+// AttachTaskNetworkInterfacesMessage with multiple interfaces of the same MAC address
+func TestAttachENIMessageWithDuplicateInterfaces(t *testing.T) {
 	mockNetInterface1 := ecsacs.ElasticNetworkInterface{
 		MacAddress: aws.String(randomMAC),
 		Ec2Id:      aws.String("1"),
@@ -218,7 +245,7 @@ func TestENIAckSingleMessage(t *testing.T) {
 
 	ctx := context.TODO()
 	mockWSClient := mock_wsclient.NewMockClientServer(ctrl)
-	eniAttachHandler := newAttachTaskENIHandler(ctx, clusterName, containerInstanceArn, mockWSClient, taskEngineState, manager)
+	eniAttachHandler := newAttachTaskENIHandler(ctx, clusterName, containerInstanceArn, mockWSClient, taskEngineState, manager, newAttachmentTracker(), 0)
 
 	var ackSent sync.WaitGroup
 	ackSent.Add(1)
@@ -272,7 +299,7 @@ func TestENIAckSingleMessageDuplicateENIAttachmentMessageStartsTimer(t *testing.
 
 	ctx := context.TODO()
 	mockWSClient := mock_wsclient.NewMockClientServer(ctrl)
-	eniAttachHandler := newAttachTaskENIHandler(ctx, clusterName, containerInstanceArn, mockWSClient, mockState, manager)
+	eniAttachHandler := newAttachTaskENIHandler(ctx, clusterName, containerInstanceArn, mockWSClient, mockState, manager, newAttachmentTracker(), 0)
 
 	// Set expiresAt to a value in the past
 	expiresAt := time.Unix(time.Now().Unix()-1, 0)
@@ -323,7 +350,7 @@ func TestENIAckHappyPath(t *testing.T) {
 	manager := mock_statemanager.NewMockStateManager(ctrl)
 
 	mockWSClient := mock_wsclient.NewMockClientServer(ctrl)
-	eniAttachHandler := newAttachTaskENIHandler(ctx, clusterName, containerInstanceArn, mockWSClient, taskEngineState, manager)
+	eniAttachHandler := newAttachTaskENIHandler(ctx, clusterName, containerInstanceArn, mockWSClient, taskEngineState, manager, newAttachmentTracker(), 0)
 
 	var ackSent sync.WaitGroup
 	ackSent.Add(1)
@@ -0,0 +1,74 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package handler
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// attachmentRecordRetention is how long an attachment ARN is remembered after
+	// the attachment has expired. ACS may redeliver attachment messages after a
+	// reconnect; messages for attachments that were already processed and have
+	// since been removed from state are dropped during this window.
+	attachmentRecordRetention = 30 * time.Minute
+)
+
+// attachmentTracker records the ARNs of attachments that have been processed
+// from ACS messages. It is shared by the attachment handlers across ACS
+// sessions so that duplicate or delayed messages are not processed twice.
+type attachmentTracker struct {
+	// records maps the attachment ARN to the time after which the record
+	// can be forgotten
+	records map[string]time.Time
+	lock    sync.Mutex
+}
+
+// newAttachmentTracker returns an empty attachmentTracker
+func newAttachmentTracker() *attachmentTracker {
+	return &attachmentTracker{
+		records: make(map[string]time.Time),
+	}
+}
+
+// record remembers the attachment ARN until the retention window past
+// expiresAt has elapsed
+func (tracker *attachmentTracker) record(attachmentARN string, expiresAt time.Time) {
+	tracker.lock.Lock()
+	defer tracker.lock.Unlock()
+
+	tracker.pruneUnsafe()
+	tracker.records[attachmentARN] = expiresAt.Add(attachmentRecordRetention)
+}
+
+// seen returns true if the attachment ARN has already been recorded
+func (tracker *attachmentTracker) seen(attachmentARN string) bool {
+	tracker.lock.Lock()
+	defer tracker.lock.Unlock()
+
+	tracker.pruneUnsafe()
+	_, ok := tracker.records[attachmentARN]
+	return ok
+}
+
+// pruneUnsafe removes the records past their retention window
+func (tracker *attachmentTracker) pruneUnsafe() {
+	now := time.Now()
+	for attachmentARN, forgetAt := range tracker.records {
+		if now.After(forgetAt) {
+			delete(tracker.records, attachmentARN)
+		}
+	}
+}
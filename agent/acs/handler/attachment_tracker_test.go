@@ -0,0 +1,38 @@
+// +build unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package handler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttachmentTrackerRecord(t *testing.T) {
+	tracker := newAttachmentTracker()
+	assert.False(t, tracker.seen(attachmentArn))
+
+	tracker.record(attachmentArn, time.Now().Add(time.Minute))
+	assert.True(t, tracker.seen(attachmentArn))
+}
+
+func TestAttachmentTrackerForgetsAfterRetention(t *testing.T) {
+	tracker := newAttachmentTracker()
+	tracker.record(attachmentArn, time.Now().Add(-attachmentRecordRetention-time.Second))
+	assert.False(t, tracker.seen(attachmentArn))
+	assert.Empty(t, tracker.records)
+}
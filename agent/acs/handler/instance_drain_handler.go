@@ -0,0 +1,260 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/acs/model/ecsacs"
+	"github.com/aws/amazon-ecs-agent/agent/engine"
+	"github.com/aws/amazon-ecs-agent/agent/wsclient"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/cihub/seelog"
+)
+
+// drainProgressInterval is how often the progress of a drain is reported
+const drainProgressInterval = 30 * time.Second
+
+// instanceDrainer is implemented by task engines that stop their tasks and
+// reject new ones when the instance drains
+type instanceDrainer interface {
+	DrainInstanceWithGracePeriods(reason string, gracePeriod time.Duration, taskGracePeriods map[string]time.Duration)
+	DrainingTasks() int
+}
+
+// instanceDrainHandler handles the instance drain messages of the ACS client
+type instanceDrainHandler struct {
+	// messageBuffer is used to process InstanceDrainMessages received from the server
+	messageBuffer chan *ecsacs.InstanceDrainMessage
+	// ackRequest is used to send acks to the backend
+	ackRequest chan *ecsacs.AckRequest
+	ctx        context.Context
+	// cancel is used to stop go routines started by start() method
+	cancel            context.CancelFunc
+	cluster           *string
+	containerInstance *string
+	acsClient         wsclient.ClientServer
+	taskEngine        engine.TaskEngine
+	// gracePeriod is the grace period of the tasks when the drain message
+	// doesn't set one
+	gracePeriod time.Duration
+	// progressInterval is how often the progress of the drain is reported
+	progressInterval time.Duration
+	// reporting is whether the progress of the drain is being reported
+	reporting bool
+	lock      sync.Mutex
+}
+
+// newInstanceDrainHandler returns a new instanceDrainHandler object
+func newInstanceDrainHandler(ctx context.Context, cluster string, containerInstanceArn string,
+	acsClient wsclient.ClientServer, taskEngine engine.TaskEngine, gracePeriod time.Duration) instanceDrainHandler {
+	// Create a cancelable context from the parent context
+	derivedContext, cancel := context.WithCancel(ctx)
+	return instanceDrainHandler{
+		messageBuffer:     make(chan *ecsacs.InstanceDrainMessage),
+		ackRequest:        make(chan *ecsacs.AckRequest),
+		ctx:               derivedContext,
+		cancel:            cancel,
+		cluster:           aws.String(cluster),
+		containerInstance: aws.String(containerInstanceArn),
+		acsClient:         acsClient,
+		taskEngine:        taskEngine,
+		gracePeriod:       gracePeriod,
+		progressInterval:  drainProgressInterval,
+	}
+}
+
+// handlerFunc returns the request handler function for the ecsacs.InstanceDrainMessage
+func (drainHandler *instanceDrainHandler) handlerFunc() func(message *ecsacs.InstanceDrainMessage) {
+	// return a function that just enqueues InstanceDrain messages into the message buffer
+	return func(message *ecsacs.InstanceDrainMessage) {
+		drainHandler.messageBuffer <- message
+	}
+}
+
+// start invokes go routines to:
+// 1. handle messages in the instance drain message buffer
+// 2. handle ack requests to be sent to ACS
+func (drainHandler *instanceDrainHandler) start() {
+	go drainHandler.handleMessages()
+	go drainHandler.sendAcks()
+}
+
+// stop cancels the context being used by the instance drain handler. This is
+// used to stop the go routines started by 'start()' and the reporting of the
+// progress of the drain
+func (drainHandler *instanceDrainHandler) stop() {
+	drainHandler.cancel()
+}
+
+// sendAcks sends ack requests to ACS
+func (drainHandler *instanceDrainHandler) sendAcks() {
+	for {
+		select {
+		case ack := <-drainHandler.ackRequest:
+			drainHandler.ackMessage(ack)
+		case <-drainHandler.ctx.Done():
+			return
+		}
+	}
+}
+
+// ackMessage sends an AckRequest to the backend
+func (drainHandler *instanceDrainHandler) ackMessage(ack *ecsacs.AckRequest) {
+	err := drainHandler.acsClient.MakeRequest(ack)
+	if err != nil {
+		seelog.Warnf("Error 'ack'ing request with messageID: %s, error: %v", aws.StringValue(ack.MessageId), err)
+	}
+	seelog.Debugf("Acking instance drain message: %s", ack.String())
+}
+
+// handleMessages processes instance drain messages in the buffer in-order
+func (drainHandler *instanceDrainHandler) handleMessages() {
+	for {
+		select {
+		case message := <-drainHandler.messageBuffer:
+			drainHandler.handleSingleMessage(message)
+		case <-drainHandler.ctx.Done():
+			return
+		}
+	}
+}
+
+// handleSingleMessage processes a single instance drain message. The drain
+// starts once, later drain messages only restart the reporting of its
+// progress if it isn't being reported
+func (drainHandler *instanceDrainHandler) handleSingleMessage(message *ecsacs.InstanceDrainMessage) error {
+	// Validate fields in the message
+	err := validateInstanceDrainMessage(message)
+	if err != nil {
+		seelog.Errorf("Error validating instance drain message: %v", err)
+		return err
+	}
+	messageId := aws.StringValue(message.MessageId)
+	drainer, ok := drainHandler.taskEngine.(instanceDrainer)
+	if !ok {
+		seelog.Errorf("Task engine doesn't support draining the instance, messageId: %s", messageId)
+		return fmt.Errorf("task engine doesn't support draining the instance")
+	}
+
+	gracePeriod := drainHandler.gracePeriod
+	if message.GracePeriodSeconds != nil {
+		gracePeriod = time.Duration(aws.Int64Value(message.GracePeriodSeconds)) * time.Second
+	}
+	taskGracePeriods := make(map[string]time.Duration, len(message.TaskGracePeriodSeconds))
+	for taskArn, seconds := range message.TaskGracePeriodSeconds {
+		taskGracePeriods[taskArn] = time.Duration(aws.Int64Value(seconds)) * time.Second
+	}
+	reason := aws.StringValue(message.Reason)
+	if reason == "" {
+		reason = "the instance is draining"
+	}
+	seelog.Infof("Draining the instance, messageId: %s, reason: %s, grace period: %s", messageId, reason, gracePeriod)
+	drainer.DrainInstanceWithGracePeriods(reason, gracePeriod, taskGracePeriods)
+
+	drainHandler.lock.Lock()
+	if !drainHandler.reporting {
+		drainHandler.reporting = true
+		go drainHandler.reportProgress(drainer, message.MessageId)
+	}
+	drainHandler.lock.Unlock()
+
+	go func() {
+		response := &ecsacs.AckRequest{
+			Cluster:           drainHandler.cluster,
+			ContainerInstance: drainHandler.containerInstance,
+			MessageId:         message.MessageId,
+		}
+		drainHandler.ackRequest <- response
+	}()
+	return nil
+}
+
+// reportProgress reports the number of tasks of the instance that aren't
+// stopped yet, until all of them are stopped. The next drain message restarts
+// the reporting once it stops
+func (drainHandler *instanceDrainHandler) reportProgress(drainer instanceDrainer, messageId *string) {
+	ticker := time.NewTicker(drainHandler.progressInterval)
+	defer ticker.Stop()
+	defer func() {
+		drainHandler.lock.Lock()
+		defer drainHandler.lock.Unlock()
+		drainHandler.reporting = false
+	}()
+
+	for {
+		remaining := drainer.DrainingTasks()
+		progress := &ecsacs.InstanceDrainProgressMessage{
+			ClusterArn:           drainHandler.cluster,
+			ContainerInstanceArn: drainHandler.containerInstance,
+			Drained:              aws.Bool(remaining == 0),
+			MessageId:            messageId,
+			RemainingTasks:       aws.Int64(int64(remaining)),
+		}
+		if err := drainHandler.acsClient.MakeRequest(progress); err != nil {
+			seelog.Warnf("Error reporting the progress of the drain, messageId: %s, error: %v",
+				aws.StringValue(messageId), err)
+		}
+		if remaining == 0 {
+			seelog.Infof("The instance is drained, messageId: %s", aws.StringValue(messageId))
+			return
+		}
+
+		select {
+		case <-ticker.C:
+		case <-drainHandler.ctx.Done():
+			return
+		}
+	}
+}
+
+// validateInstanceDrainMessage validates fields in the InstanceDrainMessage
+// It returns an error if the messageId isn't set in the message, or if a grace
+// period is negative
+func validateInstanceDrainMessage(message *ecsacs.InstanceDrainMessage) error {
+	if message == nil {
+		return fmt.Errorf("empty instance drain message")
+	}
+
+	messageId := aws.StringValue(message.MessageId)
+	if messageId == "" {
+		return fmt.Errorf("message id not set in instance drain message")
+	}
+
+	if aws.Int64Value(message.GracePeriodSeconds) < 0 {
+		return fmt.Errorf("negative grace period in instance drain message: messageId: %s", messageId)
+	}
+	for taskArn, seconds := range message.TaskGracePeriodSeconds {
+		if aws.Int64Value(seconds) < 0 {
+			return fmt.Errorf("negative grace period of task %s in instance drain message: messageId: %s",
+				taskArn, messageId)
+		}
+	}
+
+	return nil
+}
+
+// clearAcks drains the ack request channel
+func (drainHandler *instanceDrainHandler) clearAcks() {
+	for {
+		select {
+		case <-drainHandler.ackRequest:
+		default:
+			return
+		}
+	}
+}
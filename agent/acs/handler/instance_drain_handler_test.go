@@ -0,0 +1,176 @@
+// +build unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/acs/model/ecsacs"
+	mock_engine "github.com/aws/amazon-ecs-agent/agent/engine/mocks"
+	mock_wsclient "github.com/aws/amazon-ecs-agent/agent/wsclient/mock"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+// drainingTaskEngine is a task engine that records the drain it's asked to
+// start, and whose tasks stop one by one as their progress is checked
+type drainingTaskEngine struct {
+	*mock_engine.MockTaskEngine
+	reason           string
+	gracePeriod      time.Duration
+	taskGracePeriods map[string]time.Duration
+	remaining        chan int
+}
+
+func (taskEngine *drainingTaskEngine) DrainInstanceWithGracePeriods(reason string, gracePeriod time.Duration,
+	taskGracePeriods map[string]time.Duration) {
+	taskEngine.reason = reason
+	taskEngine.gracePeriod = gracePeriod
+	taskEngine.taskGracePeriods = taskGracePeriods
+}
+
+func (taskEngine *drainingTaskEngine) DrainingTasks() int {
+	return <-taskEngine.remaining
+}
+
+func TestValidateInstanceDrainMessage(t *testing.T) {
+	assert.Error(t, validateInstanceDrainMessage(nil))
+	assert.Error(t, validateInstanceDrainMessage(&ecsacs.InstanceDrainMessage{}))
+	assert.Error(t, validateInstanceDrainMessage(&ecsacs.InstanceDrainMessage{
+		MessageId:          aws.String(messageId),
+		GracePeriodSeconds: aws.Int64(-1),
+	}))
+	assert.Error(t, validateInstanceDrainMessage(&ecsacs.InstanceDrainMessage{
+		MessageId:              aws.String(messageId),
+		TaskGracePeriodSeconds: aws.Int64Map(map[string]int64{taskArn: -1}),
+	}))
+	assert.NoError(t, validateInstanceDrainMessage(&ecsacs.InstanceDrainMessage{
+		MessageId: aws.String(messageId),
+	}))
+}
+
+func TestInstanceDrainMessageReportsProgress(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	mockWSClient := mock_wsclient.NewMockClientServer(ctrl)
+	taskEngine := &drainingTaskEngine{
+		MockTaskEngine: mock_engine.NewMockTaskEngine(ctrl),
+		remaining:      make(chan int, 2),
+	}
+	taskEngine.remaining <- 1
+	taskEngine.remaining <- 0
+	handler := newInstanceDrainHandler(ctx, cluster, containerInstance, mockWSClient, taskEngine, time.Minute)
+	handler.progressInterval = time.Millisecond
+
+	progress := func(remaining int64) *ecsacs.InstanceDrainProgressMessage {
+		return &ecsacs.InstanceDrainProgressMessage{
+			ClusterArn:           aws.String(cluster),
+			ContainerInstanceArn: aws.String(containerInstance),
+			Drained:              aws.Bool(remaining == 0),
+			MessageId:            aws.String(messageId),
+			RemainingTasks:       aws.Int64(remaining),
+		}
+	}
+	acked := make(chan struct{})
+	drained := make(chan struct{})
+	mockWSClient.EXPECT().MakeRequest(&ecsacs.AckRequest{
+		Cluster:           aws.String(cluster),
+		ContainerInstance: aws.String(containerInstance),
+		MessageId:         aws.String(messageId),
+	}).Do(func(interface{}) { close(acked) })
+	gomock.InOrder(
+		mockWSClient.EXPECT().MakeRequest(progress(1)),
+		mockWSClient.EXPECT().MakeRequest(progress(0)).Do(func(interface{}) { close(drained) }),
+	)
+	handler.start()
+	defer handler.stop()
+
+	handler.handlerFunc()(&ecsacs.InstanceDrainMessage{
+		MessageId:              aws.String(messageId),
+		Reason:                 aws.String("scale in"),
+		TaskGracePeriodSeconds: aws.Int64Map(map[string]int64{taskArn: 30}),
+	})
+	<-acked
+	<-drained
+
+	assert.Equal(t, "scale in", taskEngine.reason)
+	assert.Equal(t, time.Minute, taskEngine.gracePeriod)
+	assert.Equal(t, map[string]time.Duration{taskArn: 30 * time.Second}, taskEngine.taskGracePeriods)
+}
+
+func TestReportProgressStopsWhenDrained(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	mockWSClient := mock_wsclient.NewMockClientServer(ctrl)
+	taskEngine := &drainingTaskEngine{
+		MockTaskEngine: mock_engine.NewMockTaskEngine(ctrl),
+		remaining:      make(chan int, 1),
+	}
+	taskEngine.remaining <- 0
+	handler := newInstanceDrainHandler(ctx, cluster, containerInstance, mockWSClient, taskEngine, time.Minute)
+	handler.progressInterval = time.Millisecond
+	handler.reporting = true
+
+	mockWSClient.EXPECT().MakeRequest(gomock.Any())
+	handler.reportProgress(taskEngine, aws.String(messageId))
+	assert.False(t, handler.reporting, "the next drain message should restart the reporting")
+}
+
+func TestInstanceDrainMessageNotAcked(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testCases := []struct {
+		name       string
+		taskEngine *mock_engine.MockTaskEngine
+		message    *ecsacs.InstanceDrainMessage
+	}{
+		{
+			name:       "invalid message",
+			taskEngine: mock_engine.NewMockTaskEngine(ctrl),
+			message:    &ecsacs.InstanceDrainMessage{},
+		},
+		{
+			name:       "engine without draining",
+			taskEngine: mock_engine.NewMockTaskEngine(ctrl),
+			message:    &ecsacs.InstanceDrainMessage{MessageId: aws.String(messageId)},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			handler := newInstanceDrainHandler(ctx, cluster, containerInstance, nil, tc.taskEngine, 0)
+
+			assert.Error(t, handler.handleSingleMessage(tc.message))
+			select {
+			case <-handler.ackRequest:
+				t.Fatal("Received ack when none expected")
+			default:
+			}
+		})
+	}
+}
@@ -243,6 +243,11 @@ func (payloadHandler *payloadRequestHandler) addPayloadTasks(payload *ecsacs.Pay
 				continue
 			}
 			apiTask.SetAppMesh(appmesh)
+			if err := apiTask.ValidateAppMesh(); err != nil {
+				payloadHandler.handleUnrecognizedTask(task, err, payload)
+				allTasksOK = false
+				continue
+			}
 		}
 
 		if task.ExecutionRoleCredentials != nil {
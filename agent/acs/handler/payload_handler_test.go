@@ -698,6 +698,22 @@ func TestPayloadHandlerAddedAppMeshToTask(t *testing.T) {
 		Tasks: []*ecsacs.Task{
 			{
 				Arn: aws.String("arn"),
+				Containers: []*ecsacs.Container{
+					{Name: aws.String(mockContainerName)},
+				},
+				ElasticNetworkInterfaces: []*ecsacs.ElasticNetworkInterface{
+					{
+						AttachmentArn: aws.String("arn"),
+						Ec2Id:         aws.String("ec2id"),
+						Ipv4Addresses: []*ecsacs.IPv4AddressAssignment{
+							{
+								Primary:        aws.Bool(true),
+								PrivateAddress: aws.String("ipv4"),
+							},
+						},
+						MacAddress: aws.String("mac"),
+					},
+				},
 				ProxyConfiguration: &ecsacs.ProxyConfiguration{
 					Type: aws.String(appMeshType),
 					Properties: map[string]*string{
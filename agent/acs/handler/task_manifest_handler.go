@@ -191,7 +191,8 @@ func compareTasks(receivedTaskList []*ecsacs.TaskIdentifier, runningTaskList []*
 	for _, runningTask := range runningTaskList {
 		// For every task running on the instance check if the task is present in receivedTaskList with the DesiredState
 		// of running, if not add them to the list of task that needs to be stopped
-		if runningTask.GetDesiredStatus() == apitaskstatus.TaskRunning {
+		// Static tasks aren't run for ECS, so the manifest doesn't list them
+		if runningTask.GetDesiredStatus() == apitaskstatus.TaskRunning && !runningTask.IsStatic() {
 			taskPresent := false
 			for _, receivedTask := range receivedTaskList {
 				if *receivedTask.TaskArn == runningTask.Arn && *receivedTask.
@@ -484,3 +484,21 @@ func TestCompareTasksSameTasks(t *testing.T) {
 
 	assert.Equal(t, 0, len(compareTaskList))
 }
+
+func TestCompareTasksStaticTasks(t *testing.T) {
+	receivedTaskList := []*ecsacs.TaskIdentifier{
+		{
+			DesiredStatus: aws.String(apitaskstatus.TaskRunningString),
+			TaskArn:       aws.String("arn1"),
+		},
+	}
+
+	taskList := []*task.Task{
+		{Arn: "arn1", DesiredStatusUnsafe: apitaskstatus.TaskRunning},
+		{Arn: "static-arn", DesiredStatusUnsafe: apitaskstatus.TaskRunning, StaticName: "static"},
+	}
+
+	compareTaskList := compareTasks(receivedTaskList, taskList)
+
+	assert.Equal(t, 0, len(compareTaskList))
+}
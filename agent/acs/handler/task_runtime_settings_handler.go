@@ -0,0 +1,186 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/amazon-ecs-agent/agent/acs/model/ecsacs"
+	"github.com/aws/amazon-ecs-agent/agent/engine"
+	"github.com/aws/amazon-ecs-agent/agent/wsclient"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/cihub/seelog"
+)
+
+// taskRuntimeSettingsUpdater is implemented by task engines that apply the
+// runtime settings ACS sends for running tasks
+type taskRuntimeSettingsUpdater interface {
+	UpdateTaskRuntimeSettings(taskArn string, settings map[string]string, version int64) error
+}
+
+// taskRuntimeSettingsHandler handles the task runtime settings messages of
+// the ACS client
+type taskRuntimeSettingsHandler struct {
+	// messageBuffer is used to process TaskRuntimeSettingsMessages received from the server
+	messageBuffer chan *ecsacs.TaskRuntimeSettingsMessage
+	// ackRequest is used to send acks to the backend
+	ackRequest chan *ecsacs.AckRequest
+	ctx        context.Context
+	// cancel is used to stop go routines started by start() method
+	cancel            context.CancelFunc
+	cluster           *string
+	containerInstance *string
+	acsClient         wsclient.ClientServer
+	taskEngine        engine.TaskEngine
+}
+
+// newTaskRuntimeSettingsHandler returns a new taskRuntimeSettingsHandler object
+func newTaskRuntimeSettingsHandler(ctx context.Context, cluster string, containerInstanceArn string,
+	acsClient wsclient.ClientServer, taskEngine engine.TaskEngine) taskRuntimeSettingsHandler {
+	// Create a cancelable context from the parent context
+	derivedContext, cancel := context.WithCancel(ctx)
+	return taskRuntimeSettingsHandler{
+		messageBuffer:     make(chan *ecsacs.TaskRuntimeSettingsMessage),
+		ackRequest:        make(chan *ecsacs.AckRequest),
+		ctx:               derivedContext,
+		cancel:            cancel,
+		cluster:           aws.String(cluster),
+		containerInstance: aws.String(containerInstanceArn),
+		acsClient:         acsClient,
+		taskEngine:        taskEngine,
+	}
+}
+
+// handlerFunc returns the request handler function for the ecsacs.TaskRuntimeSettingsMessage
+func (settingsHandler *taskRuntimeSettingsHandler) handlerFunc() func(message *ecsacs.TaskRuntimeSettingsMessage) {
+	// return a function that just enqueues TaskRuntimeSettings messages into the message buffer
+	return func(message *ecsacs.TaskRuntimeSettingsMessage) {
+		settingsHandler.messageBuffer <- message
+	}
+}
+
+// start invokes go routines to:
+// 1. handle messages in the task runtime settings message buffer
+// 2. handle ack requests to be sent to ACS
+func (settingsHandler *taskRuntimeSettingsHandler) start() {
+	go settingsHandler.handleMessages()
+	go settingsHandler.sendAcks()
+}
+
+// stop cancels the context being used by the task runtime settings handler.
+// This is used to stop the go routines started by 'start()'
+func (settingsHandler *taskRuntimeSettingsHandler) stop() {
+	settingsHandler.cancel()
+}
+
+// sendAcks sends ack requests to ACS
+func (settingsHandler *taskRuntimeSettingsHandler) sendAcks() {
+	for {
+		select {
+		case ack := <-settingsHandler.ackRequest:
+			settingsHandler.ackMessage(ack)
+		case <-settingsHandler.ctx.Done():
+			return
+		}
+	}
+}
+
+// ackMessage sends an AckRequest to the backend
+func (settingsHandler *taskRuntimeSettingsHandler) ackMessage(ack *ecsacs.AckRequest) {
+	err := settingsHandler.acsClient.MakeRequest(ack)
+	if err != nil {
+		seelog.Warnf("Error 'ack'ing request with messageID: %s, error: %v", aws.StringValue(ack.MessageId), err)
+	}
+	seelog.Debugf("Acking task runtime settings message: %s", ack.String())
+}
+
+// handleMessages processes task runtime settings messages in the buffer in-order
+func (settingsHandler *taskRuntimeSettingsHandler) handleMessages() {
+	for {
+		select {
+		case message := <-settingsHandler.messageBuffer:
+			settingsHandler.handleSingleMessage(message)
+		case <-settingsHandler.ctx.Done():
+			return
+		}
+	}
+}
+
+// handleSingleMessage processes a single task runtime settings message. The
+// message is only acked once the settings are applied, so that ACS sends it
+// again otherwise
+func (settingsHandler *taskRuntimeSettingsHandler) handleSingleMessage(message *ecsacs.TaskRuntimeSettingsMessage) error {
+	// Validate fields in the message
+	err := validateTaskRuntimeSettingsMessage(message)
+	if err != nil {
+		seelog.Errorf("Error validating task runtime settings message: %v", err)
+		return err
+	}
+	taskArn := aws.StringValue(message.TaskArn)
+	messageId := aws.StringValue(message.MessageId)
+	updater, ok := settingsHandler.taskEngine.(taskRuntimeSettingsUpdater)
+	if !ok {
+		seelog.Errorf("Task engine doesn't support task runtime settings, arn: %s, messageId: %s", taskArn, messageId)
+		return fmt.Errorf("task engine doesn't support task runtime settings")
+	}
+
+	settings := aws.StringValueMap(message.Settings)
+	err = updater.UpdateTaskRuntimeSettings(taskArn, settings, aws.Int64Value(message.Version))
+	if err != nil {
+		seelog.Errorf("Unable to update runtime settings for task, arn: %s, messageId: %s, err: %v", taskArn, messageId, err)
+		return fmt.Errorf("unable to update runtime settings %v", err)
+	}
+
+	go func() {
+		response := &ecsacs.AckRequest{
+			Cluster:           settingsHandler.cluster,
+			ContainerInstance: settingsHandler.containerInstance,
+			MessageId:         message.MessageId,
+		}
+		settingsHandler.ackRequest <- response
+	}()
+	return nil
+}
+
+// validateTaskRuntimeSettingsMessage validates fields in the TaskRuntimeSettingsMessage
+// It returns an error if any of the following fields are not set in the message:
+// messageId, taskArn
+func validateTaskRuntimeSettingsMessage(message *ecsacs.TaskRuntimeSettingsMessage) error {
+	if message == nil {
+		return fmt.Errorf("empty task runtime settings message")
+	}
+
+	if aws.StringValue(message.MessageId) == "" {
+		return fmt.Errorf("message id not set in task runtime settings message")
+	}
+
+	if aws.StringValue(message.TaskArn) == "" {
+		return fmt.Errorf("task Arn not set in task runtime settings message: messageId: %s",
+			aws.StringValue(message.MessageId))
+	}
+
+	return nil
+}
+
+// clearAcks drains the ack request channel
+func (settingsHandler *taskRuntimeSettingsHandler) clearAcks() {
+	for {
+		select {
+		case <-settingsHandler.ackRequest:
+		default:
+			return
+		}
+	}
+}
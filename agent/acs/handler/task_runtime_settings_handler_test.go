@@ -0,0 +1,145 @@
+// +build unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/amazon-ecs-agent/agent/acs/model/ecsacs"
+	mock_engine "github.com/aws/amazon-ecs-agent/agent/engine/mocks"
+	mock_wsclient "github.com/aws/amazon-ecs-agent/agent/wsclient/mock"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+// runtimeSettingsTaskEngine is a task engine that records the runtime
+// settings it's asked to apply
+type runtimeSettingsTaskEngine struct {
+	*mock_engine.MockTaskEngine
+	taskArn  string
+	settings map[string]string
+	version  int64
+	err      error
+}
+
+func (taskEngine *runtimeSettingsTaskEngine) UpdateTaskRuntimeSettings(taskArn string, settings map[string]string,
+	version int64) error {
+	taskEngine.taskArn = taskArn
+	taskEngine.settings = settings
+	taskEngine.version = version
+	return taskEngine.err
+}
+
+func TestValidateTaskRuntimeSettingsMessage(t *testing.T) {
+	assert.Error(t, validateTaskRuntimeSettingsMessage(nil))
+	assert.Error(t, validateTaskRuntimeSettingsMessage(&ecsacs.TaskRuntimeSettingsMessage{
+		TaskArn: aws.String(taskArn),
+	}))
+	assert.Error(t, validateTaskRuntimeSettingsMessage(&ecsacs.TaskRuntimeSettingsMessage{
+		MessageId: aws.String(messageId),
+	}))
+	assert.NoError(t, validateTaskRuntimeSettingsMessage(&ecsacs.TaskRuntimeSettingsMessage{
+		MessageId: aws.String(messageId),
+		TaskArn:   aws.String(taskArn),
+	}))
+}
+
+func TestTaskRuntimeSettingsMessageApplied(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	mockWSClient := mock_wsclient.NewMockClientServer(ctrl)
+	taskEngine := &runtimeSettingsTaskEngine{MockTaskEngine: mock_engine.NewMockTaskEngine(ctrl)}
+	handler := newTaskRuntimeSettingsHandler(ctx, cluster, containerInstance, mockWSClient, taskEngine)
+
+	acked := make(chan struct{})
+	mockWSClient.EXPECT().MakeRequest(&ecsacs.AckRequest{
+		Cluster:           aws.String(cluster),
+		ContainerInstance: aws.String(containerInstance),
+		MessageId:         aws.String(messageId),
+	}).Do(func(interface{}) { close(acked) })
+	handler.start()
+	defer handler.stop()
+
+	handler.handlerFunc()(&ecsacs.TaskRuntimeSettingsMessage{
+		MessageId: aws.String(messageId),
+		TaskArn:   aws.String(taskArn),
+		Settings:  aws.StringMap(map[string]string{"feature": "on"}),
+		Version:   aws.Int64(2),
+	})
+	<-acked
+
+	assert.Equal(t, taskArn, taskEngine.taskArn)
+	assert.Equal(t, map[string]string{"feature": "on"}, taskEngine.settings)
+	assert.Equal(t, int64(2), taskEngine.version)
+}
+
+func TestTaskRuntimeSettingsMessageNotAcked(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	message := &ecsacs.TaskRuntimeSettingsMessage{
+		MessageId: aws.String(messageId),
+		TaskArn:   aws.String(taskArn),
+	}
+	testCases := []struct {
+		name       string
+		taskEngine *mock_engine.MockTaskEngine
+		updater    *runtimeSettingsTaskEngine
+		message    *ecsacs.TaskRuntimeSettingsMessage
+	}{
+		{
+			name:    "invalid message",
+			updater: &runtimeSettingsTaskEngine{},
+			message: &ecsacs.TaskRuntimeSettingsMessage{},
+		},
+		{
+			name:       "engine without runtime settings",
+			taskEngine: mock_engine.NewMockTaskEngine(ctrl),
+			message:    message,
+		},
+		{
+			name:    "settings not applied",
+			updater: &runtimeSettingsTaskEngine{err: errors.New("task not found")},
+			message: message,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			var handler taskRuntimeSettingsHandler
+			if tc.updater != nil {
+				handler = newTaskRuntimeSettingsHandler(ctx, cluster, containerInstance, nil, tc.updater)
+			} else {
+				handler = newTaskRuntimeSettingsHandler(ctx, cluster, containerInstance, nil, tc.taskEngine)
+			}
+
+			assert.Error(t, handler.handleSingleMessage(tc.message))
+			select {
+			case <-handler.ackRequest:
+				t.Fatal("Received ack when none expected")
+			default:
+			}
+		})
+	}
+}
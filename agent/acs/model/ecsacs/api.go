@@ -282,6 +282,8 @@ type Container struct {
 
 	DependsOn []*ContainerDependency `locationName:"dependsOn" type:"list"`
 
+	Devices []*Device `locationName:"devices" type:"list"`
+
 	DockerConfig *DockerConfig `locationName:"dockerConfig" type:"structure"`
 
 	EntryPoint []*string `locationName:"entryPoint" type:"list"`
@@ -296,10 +298,20 @@ type Container struct {
 
 	Image *string `locationName:"image" type:"string"`
 
+	InitProcessEnabled *bool `locationName:"initProcessEnabled" type:"boolean"`
+
+	Interactive *bool `locationName:"interactive" type:"boolean"`
+
+	KernelCapabilities *KernelCapabilities `locationName:"kernelCapabilities" type:"structure"`
+
 	Links []*string `locationName:"links" type:"list"`
 
 	LogsAuthStrategy *string `locationName:"logsAuthStrategy" type:"string" enum:"AuthStrategy"`
 
+	ManagedAgents []*ManagedAgent `locationName:"managedAgents" type:"list"`
+
+	MaxSwap *int64 `locationName:"maxSwap" type:"integer"`
+
 	Memory *int64 `locationName:"memory" type:"integer"`
 
 	MountPoints []*MountPoint `locationName:"mountPoints" type:"list"`
@@ -310,14 +322,30 @@ type Container struct {
 
 	PortMappings []*PortMapping `locationName:"portMappings" type:"list"`
 
+	PseudoTerminal *bool `locationName:"pseudoTerminal" type:"boolean"`
+
 	RegistryAuthentication *RegistryAuthenticationData `locationName:"registryAuthentication" type:"structure"`
 
+	ResourceRequirements []*ResourceRequirement `locationName:"resourceRequirements" type:"list"`
+
+	RestartPolicy *ContainerRestartPolicy `locationName:"restartPolicy" type:"structure"`
+
 	Secrets []*Secret `locationName:"secrets" type:"list"`
 
+	SharedMemorySize *int64 `locationName:"sharedMemorySize" type:"integer"`
+
 	StartTimeout *int64 `locationName:"startTimeout" type:"integer"`
 
 	StopTimeout *int64 `locationName:"stopTimeout" type:"integer"`
 
+	Swappiness *int64 `locationName:"swappiness" type:"integer"`
+
+	SystemControls []*SystemControl `locationName:"systemControls" type:"list"`
+
+	Tmpfs []*Tmpfs `locationName:"tmpfs" type:"list"`
+
+	Ulimits []*Ulimit `locationName:"ulimits" type:"list"`
+
 	VolumesFrom []*VolumeFrom `locationName:"volumesFrom" type:"list"`
 }
 
@@ -349,6 +377,66 @@ func (s ContainerDependency) GoString() string {
 	return s.String()
 }
 
+type ContainerRestartPolicy struct {
+	_ struct{} `type:"structure"`
+
+	BackoffSeconds *int64 `locationName:"backoffSeconds" type:"integer"`
+
+	MaximumAttempts *int64 `locationName:"maximumAttempts" type:"integer"`
+
+	ResetWindowSeconds *int64 `locationName:"resetWindowSeconds" type:"integer"`
+}
+
+// String returns the string representation
+func (s ContainerRestartPolicy) String() string {
+	return awsutil.Prettify(s)
+}
+
+// GoString returns the string representation
+func (s ContainerRestartPolicy) GoString() string {
+	return s.String()
+}
+
+type DNSConfig struct {
+	_ struct{} `type:"structure"`
+
+	Nameservers []*string `locationName:"nameservers" type:"list"`
+
+	Options []*string `locationName:"options" type:"list"`
+
+	SearchDomains []*string `locationName:"searchDomains" type:"list"`
+}
+
+// String returns the string representation
+func (s DNSConfig) String() string {
+	return awsutil.Prettify(s)
+}
+
+// GoString returns the string representation
+func (s DNSConfig) GoString() string {
+	return s.String()
+}
+
+type Device struct {
+	_ struct{} `type:"structure"`
+
+	ContainerPath *string `locationName:"containerPath" type:"string"`
+
+	HostPath *string `locationName:"hostPath" type:"string"`
+
+	Permissions []*string `locationName:"permissions" type:"list"`
+}
+
+// String returns the string representation
+func (s Device) String() string {
+	return awsutil.Prettify(s)
+}
+
+// GoString returns the string representation
+func (s Device) GoString() string {
+	return s.String()
+}
+
 type DockerConfig struct {
 	_ struct{} `type:"structure"`
 
@@ -415,6 +503,48 @@ func (s ECRAuthData) GoString() string {
 	return s.String()
 }
 
+type EFSAuthorizationConfig struct {
+	_ struct{} `type:"structure"`
+
+	AccessPointId *string `locationName:"accessPointId" type:"string"`
+
+	Iam *string `locationName:"iam" type:"string"`
+}
+
+// String returns the string representation
+func (s EFSAuthorizationConfig) String() string {
+	return awsutil.Prettify(s)
+}
+
+// GoString returns the string representation
+func (s EFSAuthorizationConfig) GoString() string {
+	return s.String()
+}
+
+type EFSVolumeConfiguration struct {
+	_ struct{} `type:"structure"`
+
+	AuthorizationConfig *EFSAuthorizationConfig `locationName:"authorizationConfig" type:"structure"`
+
+	FileSystemId *string `locationName:"fileSystemId" type:"string"`
+
+	RootDirectory *string `locationName:"rootDirectory" type:"string"`
+
+	TransitEncryption *string `locationName:"transitEncryption" type:"string"`
+
+	TransitEncryptionPort *int64 `locationName:"transitEncryptionPort" type:"integer"`
+}
+
+// String returns the string representation
+func (s EFSVolumeConfiguration) String() string {
+	return awsutil.Prettify(s)
+}
+
+// GoString returns the string representation
+func (s EFSVolumeConfiguration) GoString() string {
+	return s.String()
+}
+
 type ElasticNetworkInterface struct {
 	_ struct{} `type:"structure"`
 
@@ -439,6 +569,8 @@ type ElasticNetworkInterface struct {
 	PrivateDnsName *string `locationName:"privateDnsName" type:"string"`
 
 	SubnetGatewayIpv4Address *string `locationName:"subnetGatewayIpv4Address" type:"string"`
+
+	SubnetGatewayIpv6Address *string `locationName:"subnetGatewayIpv6Address" type:"string"`
 }
 
 // String returns the string representation
@@ -469,6 +601,22 @@ func (s EncodedString) GoString() string {
 	return s.String()
 }
 
+type EphemeralStorage struct {
+	_ struct{} `type:"structure"`
+
+	SizeInGiB *int64 `locationName:"sizeInGiB" type:"integer"`
+}
+
+// String returns the string representation
+func (s EphemeralStorage) String() string {
+	return awsutil.Prettify(s)
+}
+
+// GoString returns the string representation
+func (s EphemeralStorage) GoString() string {
+	return s.String()
+}
+
 type ErrorInput struct {
 	_ struct{} `type:"structure"`
 
@@ -713,6 +861,56 @@ func (s InactiveInstanceException) GoString() string {
 	return s.String()
 }
 
+type InstanceDrainMessage struct {
+	_ struct{} `type:"structure"`
+
+	ClusterArn *string `locationName:"clusterArn" type:"string"`
+
+	ContainerInstanceArn *string `locationName:"containerInstanceArn" type:"string"`
+
+	GracePeriodSeconds *int64 `locationName:"gracePeriodSeconds" type:"long"`
+
+	MessageId *string `locationName:"messageId" type:"string"`
+
+	Reason *string `locationName:"reason" type:"string"`
+
+	TaskGracePeriodSeconds map[string]*int64 `locationName:"taskGracePeriodSeconds" type:"map"`
+}
+
+// String returns the string representation
+func (s InstanceDrainMessage) String() string {
+	return awsutil.Prettify(s)
+}
+
+// GoString returns the string representation
+func (s InstanceDrainMessage) GoString() string {
+	return s.String()
+}
+
+type InstanceDrainProgressMessage struct {
+	_ struct{} `type:"structure"`
+
+	ClusterArn *string `locationName:"clusterArn" type:"string"`
+
+	ContainerInstanceArn *string `locationName:"containerInstanceArn" type:"string"`
+
+	Drained *bool `locationName:"drained" type:"boolean"`
+
+	MessageId *string `locationName:"messageId" type:"string"`
+
+	RemainingTasks *int64 `locationName:"remainingTasks" type:"long"`
+}
+
+// String returns the string representation
+func (s InstanceDrainProgressMessage) String() string {
+	return awsutil.Prettify(s)
+}
+
+// GoString returns the string representation
+func (s InstanceDrainProgressMessage) GoString() string {
+	return s.String()
+}
+
 type InvalidClusterException struct {
 	_ struct{} `type:"structure"`
 
@@ -745,11 +943,51 @@ func (s InvalidInstanceException) GoString() string {
 	return s.String()
 }
 
+type KernelCapabilities struct {
+	_ struct{} `type:"structure"`
+
+	Add []*string `locationName:"add" type:"list"`
+
+	Drop []*string `locationName:"drop" type:"list"`
+}
+
+// String returns the string representation
+func (s KernelCapabilities) String() string {
+	return awsutil.Prettify(s)
+}
+
+// GoString returns the string representation
+func (s KernelCapabilities) GoString() string {
+	return s.String()
+}
+
+type ManagedAgent struct {
+	_ struct{} `type:"structure"`
+
+	Name *string `locationName:"name" type:"string"`
+
+	Properties map[string]*string `locationName:"properties" type:"map"`
+}
+
+// String returns the string representation
+func (s ManagedAgent) String() string {
+	return awsutil.Prettify(s)
+}
+
+// GoString returns the string representation
+func (s ManagedAgent) GoString() string {
+	return s.String()
+}
+
 type MountPoint struct {
 	_ struct{} `type:"structure"`
 
+	Consistency *string `locationName:"consistency" type:"string"`
+
 	ContainerPath *string `locationName:"containerPath" type:"string"`
 
+	Propagation *string `locationName:"propagation" type:"string"`
+
 	ReadOnly *bool `locationName:"readOnly" type:"boolean"`
 
 	SourceVolume *string `locationName:"sourceVolume" type:"string"`
@@ -787,6 +1025,28 @@ func (s NackRequest) GoString() string {
 	return s.String()
 }
 
+type NetworkBandwidth struct {
+	_ struct{} `type:"structure"`
+
+	EgressBurst *int64 `locationName:"egressBurst" type:"long"`
+
+	EgressRate *int64 `locationName:"egressRate" type:"long"`
+
+	IngressBurst *int64 `locationName:"ingressBurst" type:"long"`
+
+	IngressRate *int64 `locationName:"ingressRate" type:"long"`
+}
+
+// String returns the string representation
+func (s NetworkBandwidth) String() string {
+	return awsutil.Prettify(s)
+}
+
+// GoString returns the string representation
+func (s NetworkBandwidth) GoString() string {
+	return s.String()
+}
+
 type NetworkInterfaceVlanProperties struct {
 	_ struct{} `type:"structure"`
 
@@ -805,6 +1065,24 @@ func (s NetworkInterfaceVlanProperties) GoString() string {
 	return s.String()
 }
 
+type OomScorePolicy struct {
+	_ struct{} `type:"structure"`
+
+	EssentialContainerOomScoreAdj *int64 `locationName:"essentialContainerOomScoreAdj" type:"integer"`
+
+	NonEssentialContainerOomScoreAdj *int64 `locationName:"nonEssentialContainerOomScoreAdj" type:"integer"`
+}
+
+// String returns the string representation
+func (s OomScorePolicy) String() string {
+	return awsutil.Prettify(s)
+}
+
+// GoString returns the string representation
+func (s OomScorePolicy) GoString() string {
+	return s.String()
+}
+
 type PayloadInput struct {
 	_ struct{} `type:"structure"`
 
@@ -1107,6 +1385,24 @@ func (s RegistryAuthenticationData) GoString() string {
 	return s.String()
 }
 
+type ResourceRequirement struct {
+	_ struct{} `type:"structure"`
+
+	Type *string `locationName:"type" type:"string"`
+
+	Value *string `locationName:"value" type:"string"`
+}
+
+// String returns the string representation
+func (s ResourceRequirement) String() string {
+	return awsutil.Prettify(s)
+}
+
+// GoString returns the string representation
+func (s ResourceRequirement) GoString() string {
+	return s.String()
+}
+
 type Secret struct {
 	_ struct{} `type:"structure"`
 
@@ -1215,6 +1511,24 @@ func (s StageUpdateOutput) GoString() string {
 	return s.String()
 }
 
+type SystemControl struct {
+	_ struct{} `type:"structure"`
+
+	Namespace *string `locationName:"namespace" type:"string"`
+
+	Value *string `locationName:"value" type:"string"`
+}
+
+// String returns the string representation
+func (s SystemControl) String() string {
+	return awsutil.Prettify(s)
+}
+
+// GoString returns the string representation
+func (s SystemControl) GoString() string {
+	return s.String()
+}
+
 type Task struct {
 	_ struct{} `type:"structure"`
 
@@ -1222,22 +1536,40 @@ type Task struct {
 
 	Associations []*Association `locationName:"associations" type:"list"`
 
+	BridgeNetworkName *string `locationName:"bridgeNetworkName" type:"string"`
+
+	CleanupWaitDurationSeconds *int64 `locationName:"cleanupWaitDurationSeconds" type:"integer"`
+
 	Containers []*Container `locationName:"containers" type:"list"`
 
 	Cpu *float64 `locationName:"cpu" type:"double"`
 
+	CpuBurst *float64 `locationName:"cpuBurst" type:"double"`
+
+	DNSConfig *DNSConfig `locationName:"dnsConfig" type:"structure"`
+
 	DesiredStatus *string `locationName:"desiredStatus" type:"string"`
 
 	ElasticNetworkInterfaces []*ElasticNetworkInterface `locationName:"elasticNetworkInterfaces" type:"list"`
 
+	EphemeralStorage *EphemeralStorage `locationName:"ephemeralStorage" type:"structure"`
+
 	ExecutionRoleCredentials *IAMRoleCredentials `locationName:"executionRoleCredentials" type:"structure"`
 
+	Experiments []*string `locationName:"experiments" type:"list"`
+
 	Family *string `locationName:"family" type:"string"`
 
+	GpuComputeMode *string `locationName:"gpuComputeMode" type:"string"`
+
 	IpcMode *string `locationName:"ipcMode" type:"string"`
 
 	Memory *int64 `locationName:"memory" type:"integer"`
 
+	NetworkBandwidth *NetworkBandwidth `locationName:"networkBandwidth" type:"structure"`
+
+	OomScorePolicy *OomScorePolicy `locationName:"oomScorePolicy" type:"structure"`
+
 	Overrides *string `locationName:"overrides" type:"string"`
 
 	PidMode *string `locationName:"pidMode" type:"string"`
@@ -1309,6 +1641,32 @@ func (s TaskManifestMessage) GoString() string {
 	return s.String()
 }
 
+type TaskRuntimeSettingsMessage struct {
+	_ struct{} `type:"structure"`
+
+	ClusterArn *string `locationName:"clusterArn" type:"string"`
+
+	ContainerInstanceArn *string `locationName:"containerInstanceArn" type:"string"`
+
+	MessageId *string `locationName:"messageId" type:"string"`
+
+	Settings map[string]*string `locationName:"settings" type:"map"`
+
+	TaskArn *string `locationName:"taskArn" type:"string"`
+
+	Version *int64 `locationName:"version" type:"long"`
+}
+
+// String returns the string representation
+func (s TaskRuntimeSettingsMessage) String() string {
+	return awsutil.Prettify(s)
+}
+
+// GoString returns the string representation
+func (s TaskRuntimeSettingsMessage) GoString() string {
+	return s.String()
+}
+
 type TaskStopVerificationAck struct {
 	_ struct{} `type:"structure"`
 
@@ -1347,6 +1705,46 @@ func (s TaskStopVerificationMessage) GoString() string {
 	return s.String()
 }
 
+type Tmpfs struct {
+	_ struct{} `type:"structure"`
+
+	ContainerPath *string `locationName:"containerPath" type:"string"`
+
+	MountOptions []*string `locationName:"mountOptions" type:"list"`
+
+	Size *int64 `locationName:"size" type:"integer"`
+}
+
+// String returns the string representation
+func (s Tmpfs) String() string {
+	return awsutil.Prettify(s)
+}
+
+// GoString returns the string representation
+func (s Tmpfs) GoString() string {
+	return s.String()
+}
+
+type Ulimit struct {
+	_ struct{} `type:"structure"`
+
+	HardLimit *int64 `locationName:"hardLimit" type:"long"`
+
+	Name *string `locationName:"name" type:"string"`
+
+	SoftLimit *int64 `locationName:"softLimit" type:"long"`
+}
+
+// String returns the string representation
+func (s Ulimit) String() string {
+	return awsutil.Prettify(s)
+}
+
+// GoString returns the string representation
+func (s Ulimit) GoString() string {
+	return s.String()
+}
+
 type UpdateFailureInput struct {
 	_ struct{} `type:"structure"`
 
@@ -1426,6 +1824,8 @@ type Volume struct {
 
 	DockerVolumeConfiguration *DockerVolumeConfiguration `locationName:"dockerVolumeConfiguration" type:"structure"`
 
+	EfsVolumeConfiguration *EFSVolumeConfiguration `locationName:"efsVolumeConfiguration" type:"structure"`
+
 	Host *HostVolumeProperties `locationName:"host" type:"structure"`
 
 	Name *string `locationName:"name" type:"string"`
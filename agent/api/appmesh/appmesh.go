@@ -14,6 +14,8 @@ package appmesh
 
 import (
 	"fmt"
+	"net"
+	"strconv"
 	"strings"
 
 	"github.com/aws/amazon-ecs-agent/agent/acs/model/ecsacs"
@@ -61,7 +63,7 @@ func AppMeshFromACS(proxyConfig *ecsacs.ProxyConfiguration) (*AppMesh, error) {
 		return nil, fmt.Errorf("agent does not support proxy type other than app mesh")
 	}
 
-	return &AppMesh{
+	appMesh := &AppMesh{
 		ContainerName:      aws.StringValue(proxyConfig.ContainerName),
 		IgnoredUID:         aws.StringValue(proxyConfig.Properties[ignoredUID]),
 		IgnoredGID:         aws.StringValue(proxyConfig.Properties[ignoredGID]),
@@ -70,35 +72,78 @@ func AppMeshFromACS(proxyConfig *ecsacs.ProxyConfiguration) (*AppMesh, error) {
 		AppPorts:           buildAppPorts(proxyConfig),
 		EgressIgnoredIPs:   buildEgressIgnoredIPs(proxyConfig),
 		EgressIgnoredPorts: buildEgressIgnoredPorts(proxyConfig),
-	}, nil
+	}
+	if err := appMesh.validate(); err != nil {
+		return nil, err
+	}
+	return appMesh, nil
+}
+
+// validate checks the format of the ports and IP addresses passed to the app
+// mesh plugin, which rejects the ones it can't parse, so that they fail the
+// task when it's received rather than when its network namespace is set up
+func (appMesh *AppMesh) validate() error {
+	ports := []struct {
+		property string
+		values   []string
+	}{
+		{proxyIngressPort, nonEmpty(appMesh.ProxyIngressPort)},
+		{proxyEgressPort, nonEmpty(appMesh.ProxyEgressPort)},
+		{appPorts, appMesh.AppPorts},
+		{egressIgnoredPorts, appMesh.EgressIgnoredPorts},
+	}
+	for _, property := range ports {
+		for _, value := range property.values {
+			if _, err := strconv.ParseUint(strings.TrimSpace(value), 10, 16); err != nil {
+				return fmt.Errorf("app mesh: invalid port %q in %s", value, property.property)
+			}
+		}
+	}
+	for _, value := range appMesh.EgressIgnoredIPs {
+		value = strings.TrimSpace(value)
+		if net.ParseIP(value) == nil {
+			if _, _, err := net.ParseCIDR(value); err != nil {
+				return fmt.Errorf("app mesh: invalid IP address %q in %s", value, egressIgnoredIPs)
+			}
+		}
+	}
+	return nil
+}
+
+func nonEmpty(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return []string{value}
 }
 
 // buildAppPorts creates app ports from proxy config
 func buildAppPorts(proxyConfig *ecsacs.ProxyConfiguration) []string {
-	var inputAppPorts []string
-	if proxyConfig.Properties[appPorts] != nil && len(*proxyConfig.Properties[appPorts]) > 0 {
-		inputAppPorts = strings.Split(*proxyConfig.Properties[appPorts], splitter)
-	}
-	return inputAppPorts
+	return splitProperty(proxyConfig, appPorts)
 }
 
 // buildEgressIgnoredIPs creates egress ignored IPs from proxy config
 func buildEgressIgnoredIPs(proxyConfig *ecsacs.ProxyConfiguration) []string {
-	var inputEgressIgnoredIPs []string
-	if proxyConfig.Properties[egressIgnoredIPs] != nil && len(*proxyConfig.Properties[egressIgnoredIPs]) > 0 {
-		inputEgressIgnoredIPs = strings.Split(*proxyConfig.Properties[egressIgnoredIPs], splitter)
-	}
 	// append agent default egress ignored IPs
-	return appendDefaultEgressIgnoredIPs(inputEgressIgnoredIPs)
+	return appendDefaultEgressIgnoredIPs(splitProperty(proxyConfig, egressIgnoredIPs))
 }
 
 // buildEgressIgnoredPorts creates egress ignored ports from proxy config
 func buildEgressIgnoredPorts(proxyConfig *ecsacs.ProxyConfiguration) []string {
-	var inputEgressIgnoredPorts []string
-	if proxyConfig.Properties[egressIgnoredPorts] != nil && len(*proxyConfig.Properties[egressIgnoredPorts]) > 0 {
-		inputEgressIgnoredPorts = strings.Split(*proxyConfig.Properties[egressIgnoredPorts], splitter)
+	return splitProperty(proxyConfig, egressIgnoredPorts)
+}
+
+// splitProperty splits the comma separated values of a property of the proxy
+// config, which are passed as is to the app mesh plugin, dropping the spaces
+// around them and the empty ones
+func splitProperty(proxyConfig *ecsacs.ProxyConfiguration, property string) []string {
+	var values []string
+	for _, value := range strings.Split(aws.StringValue(proxyConfig.Properties[property]), splitter) {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
 	}
-	return inputEgressIgnoredPorts
+	return values
 }
 
 // appendDefaultEgressIgnoredIPs append task metadata endpoint ip and
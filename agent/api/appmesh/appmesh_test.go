@@ -85,6 +85,21 @@ func TestAppMeshFromACSContainsDefaultEgressIgnoredIP(t *testing.T) {
 	assert.Equal(t, mockEgressIgnoredPort2, appMesh.EgressIgnoredPorts[1])
 }
 
+func TestAppMeshFromACSTrimsProperties(t *testing.T) {
+	testProxyConfig := prepareProxyConfig()
+	testProxyConfig.Properties[appPorts] = aws.String(mockAppPort1 + ", " + mockAppPort2)
+	testProxyConfig.Properties[egressIgnoredIPs] = aws.String(" " + mockEgressIgnoredIP1 + " ,," + mockEgressIgnoredIP2 + "/24 ")
+	testProxyConfig.Properties[egressIgnoredPorts] = aws.String(mockEgressIgnoredPort1 + " , " + mockEgressIgnoredPort2 + ",")
+
+	appMesh, err := AppMeshFromACS(&testProxyConfig)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{mockAppPort1, mockAppPort2}, appMesh.AppPorts)
+	assert.Equal(t, []string{mockEgressIgnoredIP1, mockEgressIgnoredIP2 + "/24", taskMetadataEndpointIP, instanceMetadataEndpointIP},
+		appMesh.EgressIgnoredIPs)
+	assert.Equal(t, []string{mockEgressIgnoredPort1, mockEgressIgnoredPort2}, appMesh.EgressIgnoredPorts)
+}
+
 func TestAppMeshFromACSNonAppMeshProxyInput(t *testing.T) {
 	someOtherProxyType := "fooProxy"
 	testProxyConfig := prepareProxyConfig()
@@ -144,3 +159,75 @@ func prepareProxyConfig() ecsacs.ProxyConfiguration {
 		ContainerName: aws.String(mockContainerName),
 	}
 }
+
+func TestAppMeshFromACSInvalidProperties(t *testing.T) {
+	testCases := []struct {
+		name       string
+		properties map[string]*string
+	}{
+		{
+			name:       "invalid proxy port",
+			properties: map[string]*string{proxyEgressPort: aws.String("envoy")},
+		},
+		{
+			name:       "invalid app port",
+			properties: map[string]*string{appPorts: aws.String("8000,70000")},
+		},
+		{
+			name:       "invalid egress ignored ip",
+			properties: map[string]*string{egressIgnoredIPs: aws.String("10.0.0.0/8,metadata")},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testProxyConfig := prepareProxyConfig()
+			for property, value := range tc.properties {
+				testProxyConfig.Properties[property] = value
+			}
+
+			_, err := AppMeshFromACS(&testProxyConfig)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestAppMeshFromACSIngressOnly(t *testing.T) {
+	testProxyConfig := prepareProxyConfig()
+	testProxyConfig.Properties = map[string]*string{
+		proxyIngressPort: aws.String(mockProxyIngressPort),
+		appPorts:         aws.String(mockAppPorts),
+	}
+
+	appMesh, err := AppMeshFromACS(&testProxyConfig)
+	assert.NoError(t, err)
+	assert.Equal(t, "", appMesh.ProxyEgressPort)
+}
+
+func TestAppMeshFromACSWithoutOptionalProperties(t *testing.T) {
+	testCases := []struct {
+		name       string
+		properties map[string]*string
+	}{
+		{
+			name:       "egress port without ignored uid or gid",
+			properties: map[string]*string{ignoredUID: aws.String(""), ignoredGID: aws.String("")},
+		},
+		{
+			name:       "app ports without ingress port",
+			properties: map[string]*string{proxyIngressPort: aws.String("")},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testProxyConfig := prepareProxyConfig()
+			for property, value := range tc.properties {
+				testProxyConfig.Properties[property] = value
+			}
+
+			_, err := AppMeshFromACS(&testProxyConfig)
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -17,6 +17,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -45,6 +46,10 @@ const (
 	// DockerHealthCheckType is the type of container health check provided by docker
 	DockerHealthCheckType = "docker"
 
+	// StdoutHealthCheckType is the type of container health check where the
+	// application reports its health through events written to its stdout
+	StdoutHealthCheckType = "stdout"
+
 	// AuthTypeECR is to use image pull auth over ECR
 	AuthTypeECR = "ecr"
 
@@ -64,11 +69,21 @@ const (
 	// SecretProviderASM is to show secret provider being ASM
 	SecretProviderASM = "asm"
 
+	// SecretProviderVault is to show secret provider being HashiCorp Vault
+	SecretProviderVault = "vault"
+
+	// SecretProviderFile is to show secret provider being a file on the instance
+	SecretProviderFile = "file"
+
 	// SecretTypeEnv is to show secret type being ENVIRONMENT_VARIABLE
 	SecretTypeEnv = "ENVIRONMENT_VARIABLE"
 
 	// TargetLogDriver is to show secret target being "LOG_DRIVER", the default will be "CONTAINER"
 	SecretTargetLogDriver = "LOG_DRIVER"
+
+	// ResourceTypeGPU is the type of the resource requirement for a number of
+	// GPUs
+	ResourceTypeGPU = "GPU"
 )
 
 // DockerConfig represents additional metadata about a container to run. It's
@@ -158,6 +173,41 @@ type Container struct {
 	StartTimeout uint
 	// StopTimeout specifies the time value to be passed as StopContainer api call
 	StopTimeout uint
+	// RestartPolicy specifies whether and how the agent restarts the container
+	// locally when it exits while the task is still running
+	RestartPolicy *RestartPolicy `json:"restartPolicy,omitempty"`
+	// Tmpfs are the tmpfs mounts of the container
+	Tmpfs []TmpfsMount `json:"tmpfs,omitempty"`
+	// SharedMemorySize is the size of /dev/shm of the container in MiB,
+	// docker's default size applies when it's not set
+	SharedMemorySize *int64 `json:"sharedMemorySize,omitempty"`
+	// Devices are the devices of the host exposed to the container
+	Devices []DeviceMapping `json:"devices,omitempty"`
+	// KernelCapabilities are the linux capabilities added to and dropped from
+	// the default capabilities of the container
+	KernelCapabilities *KernelCapabilities `json:"kernelCapabilities,omitempty"`
+	// InitProcessEnabled runs an init process as PID 1 of the container, which
+	// forwards signals and reaps zombie processes
+	InitProcessEnabled bool `json:"initProcessEnabled,omitempty"`
+	// Ulimits are the resource limits of the processes of the container
+	Ulimits []Ulimit `json:"ulimits,omitempty"`
+	// MaxSwap is the swap the container can use in MiB, on top of its memory
+	// limit. The container can't use swap when it's 0
+	MaxSwap *int64 `json:"maxSwap,omitempty"`
+	// Swappiness tunes how likely the memory of the container is to be
+	// swapped, between 0 and 100
+	Swappiness *int64 `json:"swappiness,omitempty"`
+	// PseudoTerminal allocates a tty to the container
+	PseudoTerminal bool `json:"pseudoTerminal,omitempty"`
+	// Interactive keeps the stdin of the container open. It isn't closed when
+	// a client attached to it detaches, so that REPLs keep running
+	Interactive bool `json:"interactive,omitempty"`
+	// SystemControls are the namespaced kernel parameters to set in the
+	// container, such as net.core.somaxconn
+	SystemControls []SystemControl `json:"systemControls,omitempty"`
+	// ResourceRequirements are the resources the container requires besides
+	// its CPU and memory, such as a number of GPUs the agent assigns to it
+	ResourceRequirements []ResourceRequirement `json:"resourceRequirements,omitempty"`
 
 	// lock is used for fields that are accessed and updated concurrently
 	lock sync.RWMutex
@@ -234,6 +284,11 @@ type Container struct {
 	// KnownPortBindingsUnsafe is an array of port bindings for the container.
 	KnownPortBindingsUnsafe []PortBinding `json:"KnownPortBindings"`
 
+	// DynamicHostPortsUnsafe are the host ports the agent assigned to the port
+	// mappings of the container without a host port, kept so that the ports
+	// stay assigned to the container across agent restarts
+	DynamicHostPortsUnsafe []PortBinding `json:"DynamicHostPorts,omitempty"`
+
 	// VolumesUnsafe is an array of volume mounts in the container.
 	VolumesUnsafe []types.MountPoint `json:"-"`
 
@@ -250,6 +305,25 @@ type Container struct {
 	// the JSON body while saving the state
 	SteadyStateStatusUnsafe *apicontainerstatus.ContainerStatus `json:"SteadyStateStatus,omitempty"`
 
+	// RestartCountUnsafe is the number of times the agent has restarted the
+	// container under its restart policy since the last reset window. It is
+	// exposed outside of the package so that it's persisted across agent restarts.
+	// NOTE: Do not access RestartCountUnsafe directly. Instead, use `GetRestartCount`,
+	// `IncrementRestartCount` and `ResetRestartCount`.
+	RestartCountUnsafe int `json:"RestartCount,omitempty"`
+
+	// ImagePullInfoUnsafe describes the pull of the image of the container. It's
+	// nil if the image hasn't been pulled by the agent.
+	// NOTE: Do not access ImagePullInfoUnsafe directly. Instead, use `GetImagePullInfo`
+	// and `SetImagePullInfo`.
+	ImagePullInfoUnsafe *ImagePullInfo `json:"ImagePullInfo,omitempty"`
+
+	// ManagedAgentsUnsafe are the agents, such as the ExecuteCommandAgent, that the
+	// ECS Agent runs inside of the container on behalf of the backend.
+	// NOTE: Do not access ManagedAgentsUnsafe directly. Instead, use `GetManagedAgents`,
+	// `GetManagedAgentByName` and `UpdateManagedAgentByName`.
+	ManagedAgentsUnsafe []ManagedAgent `json:"managedAgents,omitempty"`
+
 	createdAt  time.Time
 	startedAt  time.Time
 	finishedAt time.Time
@@ -279,6 +353,99 @@ type MountPoint struct {
 	SourceVolume  string `json:"sourceVolume"`
 	ContainerPath string `json:"containerPath"`
 	ReadOnly      bool   `json:"readOnly"`
+	// Propagation is the bind propagation of the mount, such as rshared for
+	// the mounts made beneath it on the host to show up in the container
+	Propagation string `json:"propagation,omitempty"`
+	// Consistency is the consistency requirement of the mount
+	Consistency string `json:"consistency,omitempty"`
+}
+
+var (
+	// mountPropagations are the bind propagations supported by docker
+	mountPropagations = map[string]struct{}{
+		"rshared":  {},
+		"shared":   {},
+		"rslave":   {},
+		"slave":    {},
+		"rprivate": {},
+		"private":  {},
+	}
+	// mountConsistencies are the consistency requirements supported by docker
+	mountConsistencies = map[string]struct{}{
+		"consistent": {},
+		"cached":     {},
+		"delegated":  {},
+	}
+)
+
+// BindOptions returns the options of the docker bind mount of the mount point
+func (mountPoint *MountPoint) BindOptions() ([]string, error) {
+	var options []string
+	if mountPoint.ReadOnly {
+		options = append(options, "ro")
+	}
+	if mountPoint.Propagation != "" {
+		if _, ok := mountPropagations[mountPoint.Propagation]; !ok {
+			return nil, fmt.Errorf("invalid propagation %s of mount point %s",
+				mountPoint.Propagation, mountPoint.ContainerPath)
+		}
+		options = append(options, mountPoint.Propagation)
+	}
+	if mountPoint.Consistency != "" {
+		if _, ok := mountConsistencies[mountPoint.Consistency]; !ok {
+			return nil, fmt.Errorf("invalid consistency %s of mount point %s",
+				mountPoint.Consistency, mountPoint.ContainerPath)
+		}
+		options = append(options, mountPoint.Consistency)
+	}
+	return options, nil
+}
+
+// TmpfsMount describes a tmpfs mount of a container
+type TmpfsMount struct {
+	ContainerPath string `json:"containerPath"`
+	// Size is the size of the mount in MiB
+	Size         int64    `json:"size"`
+	MountOptions []string `json:"mountOptions,omitempty"`
+}
+
+// DeviceMapping describes a device of the host exposed to a container
+type DeviceMapping struct {
+	HostPath string `json:"hostPath"`
+	// ContainerPath is the path of the device in the container, the path on
+	// the host is used when it's not set
+	ContainerPath string `json:"containerPath,omitempty"`
+	// Permissions are the permissions of the container on the device among
+	// read, write and mknod. The container has all of them when it's not set
+	Permissions []string `json:"permissions,omitempty"`
+}
+
+// KernelCapabilities describes the linux capabilities added to and dropped
+// from a container, such as NET_ADMIN or CAP_NET_ADMIN
+type KernelCapabilities struct {
+	Add  []string `json:"add,omitempty"`
+	Drop []string `json:"drop,omitempty"`
+}
+
+// Ulimit describes a resource limit of the processes of a container, such as
+// nofile
+type Ulimit struct {
+	Name      string `json:"name"`
+	SoftLimit int64  `json:"softLimit"`
+	HardLimit int64  `json:"hardLimit"`
+}
+
+// SystemControl is a namespaced kernel parameter to set in a container
+type SystemControl struct {
+	Namespace string `json:"namespace"`
+	Value     string `json:"value"`
+}
+
+// ResourceRequirement is an amount of a resource a container requires, such
+// as a number of GPUs
+type ResourceRequirement struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
 }
 
 // FirelensConfig describes the type and options of a Firelens container.
@@ -287,6 +454,60 @@ type FirelensConfig struct {
 	Options map[string]string `json:"options"`
 }
 
+// RestartPolicy describes how the agent restarts a container that exits while
+// its task is still running.
+type RestartPolicy struct {
+	// MaximumAttempts is the number of consecutive restarts allowed before the
+	// container is left stopped. Zero means unlimited.
+	MaximumAttempts int `json:"maximumAttempts"`
+	// BackoffSeconds is the delay before the first restart. It doubles with
+	// every consecutive restart.
+	BackoffSeconds int `json:"backoffSeconds"`
+	// ResetWindowSeconds is how long the container needs to run before its
+	// restart count is reset. Zero means the count is never reset.
+	ResetWindowSeconds int `json:"resetWindowSeconds"`
+}
+
+// ImagePullInfo describes where the image of a container was pulled from, and
+// how long it took to pull it.
+type ImagePullInfo struct {
+	// Registry is the host of the registry the image was pulled from, after
+	// resolving pull through cache rules
+	Registry string `json:"registry"`
+	// Size is the size of the image in bytes
+	Size int64 `json:"size"`
+	// PullStartedAt is the time the agent started pulling the image
+	PullStartedAt time.Time `json:"pullStartedAt"`
+	// PullStoppedAt is the time the agent finished pulling the image
+	PullStoppedAt time.Time `json:"pullStoppedAt"`
+}
+
+// ManagedAgent describes an agent that the ECS Agent runs inside of a container,
+// along with the state the ECS Agent keeps about it.
+type ManagedAgent struct {
+	// Name is the name of the managed agent, e.g. ExecuteCommandAgent
+	Name string `json:"name"`
+	// Properties are the agent specific settings sent by the backend
+	Properties map[string]string `json:"properties,omitempty"`
+	ManagedAgentState
+}
+
+// ManagedAgentState is the state of a managed agent in its container.
+type ManagedAgentState struct {
+	// ID identifies the files of the managed agent that are mounted into the
+	// container
+	ID string `json:"id,omitempty"`
+	// Status is the last known status of the managed agent
+	Status apicontainerstatus.ManagedAgentStatus `json:"status,omitempty"`
+	// Reason explains why the managed agent stopped
+	Reason string `json:"reason,omitempty"`
+	// LastStartedAt is the last time the managed agent was started
+	LastStartedAt time.Time `json:"lastStartedAt,omitempty"`
+	// InitFailed is set when the container could not be set up to run the
+	// managed agent, in which case the agent is never started
+	InitFailed bool `json:"initFailed,omitempty"`
+}
+
 // VolumeFrom is a volume which references another container as its source.
 type VolumeFrom struct {
 	SourceContainer string `json:"sourceContainer"`
@@ -310,6 +531,25 @@ func (s *Secret) GetSecretResourceCacheKey() string {
 	return s.ValueFrom + "_" + s.Region
 }
 
+// GetProvider returns the provider of the secret. Secrets whose valueFrom is
+// a vault:// or file:// URI are retrieved by the secret providers configured
+// on the instance, other secrets use the provider set by the backend.
+func (s *Secret) GetProvider() string {
+	for _, provider := range []string{SecretProviderVault, SecretProviderFile} {
+		if strings.HasPrefix(s.ValueFrom, provider+"://") {
+			return provider
+		}
+	}
+	return s.Provider
+}
+
+// IsProviderSecret returns true if the secret is retrieved by one of the
+// secret providers configured on the instance
+func (s *Secret) IsProviderSecret() bool {
+	provider := s.GetProvider()
+	return provider == SecretProviderVault || provider == SecretProviderFile
+}
+
 // String returns a human readable string representation of DockerContainer
 func (dc *DockerContainer) String() string {
 	if dc == nil {
@@ -624,6 +864,60 @@ func (c *Container) GetImageDigest() string {
 	return c.ImageDigest
 }
 
+// GetCommand returns the command the container runs, which is the command of
+// its overrides when they set one
+func (c *Container) GetCommand() []string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if c.Overrides.Command != nil {
+		return *c.Overrides.Command
+	}
+	return c.Command
+}
+
+// GetEntryPoint returns the entrypoint of the container, which is the
+// entrypoint of its overrides when they set one
+func (c *Container) GetEntryPoint() []string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if c.Overrides.EntryPoint != nil {
+		return *c.Overrides.EntryPoint
+	}
+	if c.EntryPoint != nil {
+		return *c.EntryPoint
+	}
+	return nil
+}
+
+// UsesSwap returns true if the container sets its swap
+func (c *Container) UsesSwap() bool {
+	return c.MaxSwap != nil || c.Swappiness != nil
+}
+
+// RequiredGPUs returns the number of GPUs the container requires
+func (c *Container) RequiredGPUs() (int, error) {
+	for _, requirement := range c.ResourceRequirements {
+		if requirement.Type != ResourceTypeGPU {
+			continue
+		}
+		count, err := strconv.Atoi(requirement.Value)
+		if err != nil || count < 0 {
+			return 0, fmt.Errorf("invalid number of GPUs required by container %s: %q",
+				c.Name, requirement.Value)
+		}
+		return count, nil
+	}
+	return 0, nil
+}
+
+// HasCommandOverrides returns true if the command or the entrypoint of the
+// container are overridden at run time
+func (c *Container) HasCommandOverrides() bool {
+	return c.Overrides.Command != nil || c.Overrides.EntryPoint != nil
+}
+
 // GetLabels gets the labels for a container
 func (c *Container) GetLabels() map[string]string {
 	c.lock.RLock()
@@ -648,6 +942,24 @@ func (c *Container) GetKnownPortBindings() []PortBinding {
 	return c.KnownPortBindingsUnsafe
 }
 
+// SetDynamicHostPorts sets the host ports the agent assigned to the port
+// mappings of the container without a host port
+func (c *Container) SetDynamicHostPorts(ports []PortBinding) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.DynamicHostPortsUnsafe = ports
+}
+
+// GetDynamicHostPorts gets the host ports the agent assigned to the port
+// mappings of the container without a host port
+func (c *Container) GetDynamicHostPorts() []PortBinding {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.DynamicHostPortsUnsafe
+}
+
 // SetVolumes sets the volumes mounted in a container
 func (c *Container) SetVolumes(volumes []types.MountPoint) {
 	c.lock.Lock()
@@ -699,6 +1011,12 @@ func (c *Container) GetNetworkMode() string {
 // HealthStatusShouldBeReported returns true if the health check is defined in
 // the task definition
 func (c *Container) HealthStatusShouldBeReported() bool {
+	return c.HealthCheckType == DockerHealthCheckType || c.HealthCheckType == StdoutHealthCheckType
+}
+
+// HealthStatusFromDocker returns true if the health status of the container
+// is the one docker reports
+func (c *Container) HealthStatusFromDocker() bool {
 	return c.HealthCheckType == DockerHealthCheckType
 }
 
@@ -774,6 +1092,32 @@ func (c *Container) BuildResourceDependency(resourceName string,
 	c.TransitionDependenciesMap[dependentStatus] = deps
 }
 
+// BuildResourceDependencyOnStatuses adds a new resource dependency that's
+// satisfied only when the resource is in one of the given statuses, rather
+// than in any status past a required one.
+// example: if container's CREATED transition is dependent on a volume resource
+// being HEALTHY, and a CREATED but unhealthy volume shouldn't satisfy it, then
+// satisfiedStatuses contains the volume's HEALTHY status only and
+// dependentStatus=ContainerCreated
+func (c *Container) BuildResourceDependencyOnStatuses(resourceName string,
+	satisfiedStatuses []resourcestatus.ResourceStatus,
+	dependentStatus apicontainerstatus.ContainerStatus) {
+
+	resourceDep := ResourceDependency{
+		Name:              resourceName,
+		SatisfiedStatuses: satisfiedStatuses,
+	}
+	if len(satisfiedStatuses) > 0 {
+		resourceDep.RequiredStatus = satisfiedStatuses[0]
+	}
+	if _, ok := c.TransitionDependenciesMap[dependentStatus]; !ok {
+		c.TransitionDependenciesMap[dependentStatus] = TransitionDependencySet{}
+	}
+	deps := c.TransitionDependenciesMap[dependentStatus]
+	deps.ResourceDependencies = append(deps.ResourceDependencies, resourceDep)
+	c.TransitionDependenciesMap[dependentStatus] = deps
+}
+
 // updateAppliedStatusUnsafe updates the container transitioning status
 func (c *Container) updateAppliedStatusUnsafe(knownStatus apicontainerstatus.ContainerStatus) {
 	if c.AppliedStatus == apicontainerstatus.ContainerStatusNone {
@@ -869,7 +1213,7 @@ func (c *Container) ShouldCreateWithSSMSecret() bool {
 	}
 
 	for _, secret := range c.Secrets {
-		if secret.Provider == SecretProviderSSM {
+		if secret.GetProvider() == SecretProviderSSM {
 			return true
 		}
 	}
@@ -888,7 +1232,21 @@ func (c *Container) ShouldCreateWithASMSecret() bool {
 	}
 
 	for _, secret := range c.Secrets {
-		if secret.Provider == SecretProviderASM {
+		if secret.GetProvider() == SecretProviderASM {
+			return true
+		}
+	}
+	return false
+}
+
+// ShouldCreateWithProviderSecret returns true if this container needs to get
+// secret values from the secret providers configured on the instance
+func (c *Container) ShouldCreateWithProviderSecret() bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	for _, secret := range c.Secrets {
+		if secret.IsProviderSecret() {
 			return true
 		}
 	}
@@ -942,6 +1300,101 @@ func (c *Container) GetStopTimeout() time.Duration {
 	return time.Duration(c.StopTimeout) * time.Second
 }
 
+// GetRestartPolicy returns the restart policy of the container
+func (c *Container) GetRestartPolicy() *RestartPolicy {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.RestartPolicy
+}
+
+// GetRestartCount returns the number of times the container has been restarted
+// by the agent
+func (c *Container) GetRestartCount() int {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.RestartCountUnsafe
+}
+
+// IncrementRestartCount increments the restart count of the container and
+// returns the new value
+func (c *Container) IncrementRestartCount() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.RestartCountUnsafe++
+	return c.RestartCountUnsafe
+}
+
+// ResetRestartCount resets the restart count of the container
+func (c *Container) ResetRestartCount() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.RestartCountUnsafe = 0
+}
+
+// GetImagePullInfo returns the pull info of the image of the container, or nil
+// if the image hasn't been pulled by the agent
+func (c *Container) GetImagePullInfo() *ImagePullInfo {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.ImagePullInfoUnsafe
+}
+
+// SetImagePullInfo sets the pull info of the image of the container
+func (c *Container) SetImagePullInfo(info *ImagePullInfo) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.ImagePullInfoUnsafe = info
+}
+
+// GetManagedAgents returns a copy of the managed agents of the container
+func (c *Container) GetManagedAgents() []ManagedAgent {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if len(c.ManagedAgentsUnsafe) == 0 {
+		return nil
+	}
+	managedAgents := make([]ManagedAgent, len(c.ManagedAgentsUnsafe))
+	copy(managedAgents, c.ManagedAgentsUnsafe)
+	return managedAgents
+}
+
+// GetManagedAgentByName returns the managed agent of the container with the
+// given name, and whether it was found
+func (c *Container) GetManagedAgentByName(agentName string) (ManagedAgent, bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	for _, ma := range c.ManagedAgentsUnsafe {
+		if ma.Name == agentName {
+			return ma, true
+		}
+	}
+	return ManagedAgent{}, false
+}
+
+// UpdateManagedAgentByName replaces the state of the managed agent of the
+// container with the given name. It returns false if the container has no such
+// managed agent
+func (c *Container) UpdateManagedAgentByName(agentName string, state ManagedAgentState) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for i, ma := range c.ManagedAgentsUnsafe {
+		if ma.Name == agentName {
+			c.ManagedAgentsUnsafe[i].ManagedAgentState = state
+			return true
+		}
+	}
+	return false
+}
+
 func (c *Container) GetDependsOn() []DependsOn {
 	c.lock.RLock()
 	defer c.lock.RUnlock()
@@ -1008,6 +1461,26 @@ func (c *Container) GetLogDriver() string {
 	return hostConfig.LogConfig.Type
 }
 
+// GetMemoryReservation returns the soft memory limit of the container in MiB,
+// the memory reservation of its host config
+func (c *Container) GetMemoryReservation() int64 {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if c.DockerConfig.HostConfig == nil {
+		return 0
+	}
+
+	hostConfig := &dockercontainer.HostConfig{}
+	err := json.Unmarshal([]byte(*c.DockerConfig.HostConfig), hostConfig)
+	if err != nil {
+		seelog.Warnf("Encountered error when trying to get memory reservation for container %s: %v", c.Name, err)
+		return 0
+	}
+
+	return hostConfig.MemoryReservation / (1024 * 1024)
+}
+
 // GetNetworkModeFromHostConfig returns the network mode used by the container from the host config .
 func (c *Container) GetNetworkModeFromHostConfig() string {
 	c.lock.RLock()
@@ -1028,6 +1501,40 @@ func (c *Container) GetNetworkModeFromHostConfig() string {
 	return hostConfig.NetworkMode.NetworkName()
 }
 
+// SetDefaultNetworkMode sets the network mode of the container in its host
+// config, unless the host config already sets one
+func (c *Container) SetDefaultNetworkMode(networkMode string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	hostConfig := make(map[string]interface{})
+	if c.DockerConfig.HostConfig != nil {
+		err := json.Unmarshal([]byte(*c.DockerConfig.HostConfig), &hostConfig)
+		if err != nil {
+			return fmt.Errorf("unable to decode host config of container %s: %v", c.Name, err)
+		}
+	}
+	// Keys of the host config are matched case insensitively when docker
+	// decodes it
+	for key, value := range hostConfig {
+		if !strings.EqualFold(key, "NetworkMode") {
+			continue
+		}
+		if value != nil && value != "" {
+			return nil
+		}
+		delete(hostConfig, key)
+	}
+	hostConfig["NetworkMode"] = networkMode
+
+	rawHostConfig, err := json.Marshal(hostConfig)
+	if err != nil {
+		return fmt.Errorf("unable to encode host config of container %s: %v", c.Name, err)
+	}
+	c.DockerConfig.HostConfig = aws.String(string(rawHostConfig))
+	return nil
+}
+
 // GetHostConfig returns the container's host config.
 func (c *Container) GetHostConfig() *string {
 	c.lock.RLock()
@@ -16,6 +16,7 @@
 package container
 
 import (
+	"encoding/json"
 	"fmt"
 	"reflect"
 	"testing"
@@ -25,6 +26,7 @@ import (
 	resourcestatus "github.com/aws/amazon-ecs-agent/agent/taskresource/status"
 
 	"github.com/aws/amazon-ecs-agent/agent/utils"
+	"github.com/aws/aws-sdk-go/aws"
 	dockercontainer "github.com/docker/docker/api/types/container"
 	"github.com/stretchr/testify/assert"
 )
@@ -68,6 +70,25 @@ func TestGetSteadyStateStatusReturnsRunningByDefault(t *testing.T) {
 	assert.Equal(t, container.GetSteadyStateStatus(), apicontainerstatus.ContainerRunning)
 }
 
+func TestGetCommandAndEntryPoint(t *testing.T) {
+	container := &Container{
+		Command:    []string{"run"},
+		EntryPoint: &[]string{"/bin/job"},
+	}
+	assert.False(t, container.HasCommandOverrides())
+	assert.Equal(t, []string{"run"}, container.GetCommand())
+	assert.Equal(t, []string{"/bin/job"}, container.GetEntryPoint())
+
+	container.Overrides = ContainerOverrides{
+		Command:    &[]string{"run", "--once"},
+		EntryPoint: &[]string{"/bin/sh", "-c"},
+	}
+	assert.True(t, container.HasCommandOverrides())
+	assert.Equal(t, []string{"run", "--once"}, container.GetCommand())
+	assert.Equal(t, []string{"/bin/sh", "-c"}, container.GetEntryPoint())
+	assert.Equal(t, []string{"run"}, container.Command)
+}
+
 func TestIsKnownSteadyState(t *testing.T) {
 	// This creates a container with `iota` ContainerStatus (NONE)
 	container := &Container{}
@@ -211,6 +232,9 @@ func TestHealthStatusShouldBeReported(t *testing.T) {
 	assert.False(t, container.HealthStatusShouldBeReported(), "Health status of container that does not have HealthCheckType set should not be reported")
 	container.HealthCheckType = DockerHealthCheckType
 	assert.True(t, container.HealthStatusShouldBeReported(), "Health status of container that has docker HealthCheckType set should be reported")
+	container.HealthCheckType = StdoutHealthCheckType
+	assert.True(t, container.HealthStatusShouldBeReported(), "Health status of container that has stdout HealthCheckType set should be reported")
+	assert.False(t, container.HealthStatusFromDocker(), "Health status of container that has stdout HealthCheckType set should not come from docker")
 	container.HealthCheckType = "unknown"
 	assert.False(t, container.HealthStatusShouldBeReported(), "Health status of container that has non-docker HealthCheckType set should not be reported")
 }
@@ -415,6 +439,36 @@ func TestShouldCreateWithASMSecret(t *testing.T) {
 	}
 }
 
+func TestSecretGetProvider(t *testing.T) {
+	cases := []struct {
+		secret   Secret
+		provider string
+	}{
+		{Secret{Provider: "ssm", ValueFrom: "/test/secretName"}, SecretProviderSSM},
+		{Secret{Provider: "asm", ValueFrom: "arn:aws:secretsmanager:us-west-2:123456789012:secret:secret"}, SecretProviderASM},
+		{Secret{Provider: "ssm", ValueFrom: "vault://secret/data/myapp#password"}, SecretProviderVault},
+		{Secret{Provider: "asm", ValueFrom: "file:///etc/ecs/secrets/password"}, SecretProviderFile},
+	}
+
+	for _, test := range cases {
+		t.Run(test.secret.ValueFrom, func(t *testing.T) {
+			assert.Equal(t, test.provider, test.secret.GetProvider())
+			assert.Equal(t, test.provider == SecretProviderVault || test.provider == SecretProviderFile,
+				test.secret.IsProviderSecret())
+		})
+	}
+}
+
+func TestShouldCreateWithProviderSecret(t *testing.T) {
+	container := Container{
+		Secrets: []Secret{{Provider: "ssm", ValueFrom: "/test/secretName"}},
+	}
+	assert.False(t, container.ShouldCreateWithProviderSecret())
+
+	container.Secrets = append(container.Secrets, Secret{Provider: "ssm", ValueFrom: "vault://secret/data/myapp#password"})
+	assert.True(t, container.ShouldCreateWithProviderSecret())
+}
+
 func TestHasSecret(t *testing.T) {
 	isEnvOrLogDriverSecret := func(s Secret) bool {
 		return s.Type == SecretTypeEnv || s.Target == SecretTargetLogDriver
@@ -655,3 +709,187 @@ func TestGetNetworkModeFromHostConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestSetDefaultNetworkMode(t *testing.T) {
+	testCases := []struct {
+		name                string
+		hostConfig          *string
+		expectedNetworkMode string
+	}{
+		{
+			name:                "no host config",
+			expectedNetworkMode: "none",
+		},
+		{
+			name:                "host config without network mode",
+			hostConfig:          aws.String(`{"Privileged":true}`),
+			expectedNetworkMode: "none",
+		},
+		{
+			name:                "empty network mode",
+			hostConfig:          aws.String(`{"networkMode":""}`),
+			expectedNetworkMode: "none",
+		},
+		{
+			name:                "network mode set",
+			hostConfig:          aws.String(`{"NetworkMode":"host"}`),
+			expectedNetworkMode: "host",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			container := &Container{Name: "c"}
+			container.DockerConfig.HostConfig = tc.hostConfig
+			assert.NoError(t, container.SetDefaultNetworkMode("none"))
+			assert.Equal(t, tc.expectedNetworkMode, container.GetNetworkModeFromHostConfig())
+		})
+	}
+
+	container := &Container{Name: "c"}
+	container.DockerConfig.HostConfig = aws.String(`{"Privileged":true}`)
+	assert.NoError(t, container.SetDefaultNetworkMode("none"))
+	assert.JSONEq(t, `{"Privileged":true,"NetworkMode":"none"}`, aws.StringValue(container.GetHostConfig()))
+
+	container.DockerConfig.HostConfig = aws.String("invalid")
+	assert.Error(t, container.SetDefaultNetworkMode("none"))
+}
+
+func TestRestartCountPersisted(t *testing.T) {
+	container := &Container{
+		RestartPolicy: &RestartPolicy{MaximumAttempts: 3, BackoffSeconds: 10, ResetWindowSeconds: 60},
+	}
+	assert.Equal(t, 1, container.IncrementRestartCount())
+	assert.Equal(t, 2, container.IncrementRestartCount())
+
+	data, err := json.Marshal(container)
+	assert.NoError(t, err)
+	var restored Container
+	assert.NoError(t, json.Unmarshal(data, &restored))
+	assert.Equal(t, 2, restored.GetRestartCount())
+	assert.Equal(t, container.GetRestartPolicy(), restored.GetRestartPolicy())
+
+	restored.ResetRestartCount()
+	assert.Equal(t, 0, restored.GetRestartCount())
+}
+
+func TestManagedAgentsPersisted(t *testing.T) {
+	container := &Container{
+		ManagedAgentsUnsafe: []ManagedAgent{
+			{
+				Name:       "ExecuteCommandAgent",
+				Properties: map[string]string{"key": "value"},
+			},
+		},
+	}
+	startedAt := time.Now().Round(time.Second).UTC()
+	assert.True(t, container.UpdateManagedAgentByName("ExecuteCommandAgent", ManagedAgentState{
+		ID:            "id",
+		Status:        apicontainerstatus.ManagedAgentRunning,
+		LastStartedAt: startedAt,
+	}))
+	assert.False(t, container.UpdateManagedAgentByName("UnknownAgent", ManagedAgentState{}))
+
+	data, err := json.Marshal(container)
+	assert.NoError(t, err)
+	var restored Container
+	assert.NoError(t, json.Unmarshal(data, &restored))
+
+	ma, ok := restored.GetManagedAgentByName("ExecuteCommandAgent")
+	assert.True(t, ok)
+	assert.Equal(t, "id", ma.ID)
+	assert.Equal(t, "value", ma.Properties["key"])
+	assert.Equal(t, apicontainerstatus.ManagedAgentRunning, ma.Status)
+	assert.Equal(t, startedAt, ma.LastStartedAt)
+	assert.Len(t, restored.GetManagedAgents(), 1)
+
+	_, ok = restored.GetManagedAgentByName("UnknownAgent")
+	assert.False(t, ok)
+}
+
+func TestMountPointBindOptions(t *testing.T) {
+	testCases := []struct {
+		name            string
+		mountPoint      MountPoint
+		expectedOptions []string
+		shouldFail      bool
+	}{
+		{
+			name:       "no options",
+			mountPoint: MountPoint{ContainerPath: "/data"},
+		},
+		{
+			name: "read only propagation and consistency",
+			mountPoint: MountPoint{
+				ContainerPath: "/data",
+				ReadOnly:      true,
+				Propagation:   "rslave",
+				Consistency:   "cached",
+			},
+			expectedOptions: []string{"ro", "rslave", "cached"},
+		},
+		{
+			name:       "invalid propagation",
+			mountPoint: MountPoint{ContainerPath: "/data", Propagation: "shared-ish"},
+			shouldFail: true,
+		},
+		{
+			name:       "invalid consistency",
+			mountPoint: MountPoint{ContainerPath: "/data", Consistency: "eventual"},
+			shouldFail: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			options, err := tc.mountPoint.BindOptions()
+			if tc.shouldFail {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedOptions, options)
+			}
+		})
+	}
+}
+
+func TestRequiredGPUs(t *testing.T) {
+	testCases := []struct {
+		name         string
+		requirements []ResourceRequirement
+		expected     int
+		shouldFail   bool
+	}{
+		{
+			name: "no requirements",
+		},
+		{
+			name:         "gpu requirement",
+			requirements: []ResourceRequirement{{Type: "InferenceAccelerator", Value: "dev1"}, {Type: ResourceTypeGPU, Value: "2"}},
+			expected:     2,
+		},
+		{
+			name:         "invalid gpu requirement",
+			requirements: []ResourceRequirement{{Type: ResourceTypeGPU, Value: "two"}},
+			shouldFail:   true,
+		},
+		{
+			name:         "negative gpu requirement",
+			requirements: []ResourceRequirement{{Type: ResourceTypeGPU, Value: "-1"}},
+			shouldFail:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			container := &Container{Name: "c", ResourceRequirements: tc.requirements}
+			count, err := container.RequiredGPUs()
+			if tc.shouldFail {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expected, count)
+			}
+		})
+	}
+}
@@ -23,7 +23,8 @@ import (
 
 // ContainerOverrides are overrides applied to the container
 type ContainerOverrides struct {
-	Command *[]string `json:"command"`
+	Command    *[]string `json:"command"`
+	EntryPoint *[]string `json:"entryPoint,omitempty"`
 }
 
 // ContainerOverridesCopy is a  type alias that doesn't have a custom unmarshaller so we
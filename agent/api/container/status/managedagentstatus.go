@@ -0,0 +1,79 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package status
+
+import (
+	"errors"
+	"strings"
+)
+
+const (
+	// ManagedAgentStatusNone is the zero state of a managed agent; the agent has not been started
+	ManagedAgentStatusNone ManagedAgentStatus = iota
+	// ManagedAgentRunning represents a managed agent that has been started in its container
+	ManagedAgentRunning
+	// ManagedAgentStopped represents a managed agent that has stopped or could not be started
+	ManagedAgentStopped
+)
+
+// ManagedAgentStatus is an enumeration of the states of an agent managed by the
+// ECS Agent inside of a container
+type ManagedAgentStatus int32
+
+var managedAgentStatusMap = map[string]ManagedAgentStatus{
+	"PENDING": ManagedAgentStatusNone,
+	"RUNNING": ManagedAgentRunning,
+	"STOPPED": ManagedAgentStopped,
+}
+
+// String returns the managed agent status recognized by backend
+func (as ManagedAgentStatus) String() string {
+	for k, v := range managedAgentStatusMap {
+		if v == as {
+			return k
+		}
+	}
+	return "PENDING"
+}
+
+// Terminal returns true if the managed agent status is STOPPED
+func (as ManagedAgentStatus) Terminal() bool {
+	return as == ManagedAgentStopped
+}
+
+// UnmarshalJSON overrides the logic for parsing the JSON-encoded ManagedAgentStatus data
+func (as *ManagedAgentStatus) UnmarshalJSON(b []byte) error {
+	*as = ManagedAgentStatusNone
+
+	if strings.ToLower(string(b)) == "null" {
+		return nil
+	}
+	if b[0] != '"' || b[len(b)-1] != '"' {
+		return errors.New("managed agent status unmarshal: status must be a string or null; Got " + string(b))
+	}
+	stat, ok := managedAgentStatusMap[string(b[1:len(b)-1])]
+	if !ok {
+		return errors.New("managed agent status unmarshal: unrecognized status: " + string(b))
+	}
+	*as = stat
+	return nil
+}
+
+// MarshalJSON overrides the logic for JSON-encoding the ManagedAgentStatus type
+func (as *ManagedAgentStatus) MarshalJSON() ([]byte, error) {
+	if as == nil {
+		return nil, nil
+	}
+	return []byte(`"` + as.String() + `"`), nil
+}
@@ -0,0 +1,63 @@
+// +build unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package status
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarshalUnmarshalManagedAgentStatus(t *testing.T) {
+	testCases := []struct {
+		Status ManagedAgentStatus
+		String string
+	}{
+		{
+			Status: ManagedAgentStatusNone,
+			String: `"PENDING"`,
+		},
+		{
+			Status: ManagedAgentRunning,
+			String: `"RUNNING"`,
+		},
+		{
+			Status: ManagedAgentStopped,
+			String: `"STOPPED"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("Status: %s, String value: %s", tc.Status, tc.String), func(t *testing.T) {
+			var status ManagedAgentStatus
+			err := json.Unmarshal([]byte(tc.String), &status)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.Status, status)
+
+			marshalled, err := json.Marshal(&status)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.String, string(marshalled))
+		})
+	}
+}
+
+func TestUnmarshalManagedAgentStatusUnrecognized(t *testing.T) {
+	var status ManagedAgentStatus
+	assert.Error(t, json.Unmarshal([]byte(`"STARTING"`), &status))
+	assert.Equal(t, ManagedAgentStatusNone, status)
+}
@@ -53,6 +53,11 @@ type ResourceDependency struct {
 	Name string `json:"Name"`
 	// RequiredStatus defines the status that satisfies the dependency
 	RequiredStatus resourcestatus.ResourceStatus `json:"RequiredStatus"`
+	// SatisfiedStatuses defines the set of statuses that satisfy the
+	// dependency, for resources whose statuses aren't ordered. If set, the
+	// dependency is satisfied by these statuses only instead of by any status
+	// past RequiredStatus
+	SatisfiedStatuses []resourcestatus.ResourceStatus `json:"SatisfiedStatuses,omitempty"`
 }
 
 // GetRequiredStatus returns the required status for the dependency
@@ -60,6 +65,28 @@ func (rd *ResourceDependency) GetRequiredStatus() resourcestatus.ResourceStatus
 	return rd.RequiredStatus
 }
 
+// GetSatisfiedStatuses returns the statuses that satisfy the dependency
+func (rd *ResourceDependency) GetSatisfiedStatuses() []resourcestatus.ResourceStatus {
+	if len(rd.SatisfiedStatuses) == 0 {
+		return []resourcestatus.ResourceStatus{rd.RequiredStatus}
+	}
+	return rd.SatisfiedStatuses
+}
+
+// SatisfiedBy returns true if the resource being in the given status
+// satisfies the dependency
+func (rd *ResourceDependency) SatisfiedBy(status resourcestatus.ResourceStatus) bool {
+	if len(rd.SatisfiedStatuses) == 0 {
+		return status >= rd.RequiredStatus
+	}
+	for _, satisfiedStatus := range rd.SatisfiedStatuses {
+		if status == satisfiedStatus {
+			return true
+		}
+	}
+	return false
+}
+
 // TransitionDependenciesMap is a map of the dependent container status to other
 // dependencies that must be satisfied.
 type TransitionDependenciesMap map[apicontainerstatus.ContainerStatus]TransitionDependencySet
@@ -20,6 +20,7 @@ import (
 	"testing"
 
 	apicontainerstatus "github.com/aws/amazon-ecs-agent/agent/api/container/status"
+	resourcestatus "github.com/aws/amazon-ecs-agent/agent/taskresource/status"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -58,3 +59,39 @@ func TestUnmarshalNewTransitionDependencySet(t *testing.T) {
 	assert.Equal(t, apicontainerstatus.ContainerRunning, dep[0].SatisfiedStatus)
 	assert.Equal(t, apicontainerstatus.ContainerStatusNone, dep[0].DependentStatus)
 }
+
+func TestResourceDependencySatisfiedBy(t *testing.T) {
+	required := ResourceDependency{Name: "resource", RequiredStatus: resourcestatus.ResourceCreated}
+	assert.False(t, required.SatisfiedBy(resourcestatus.ResourceStatusNone))
+	assert.True(t, required.SatisfiedBy(resourcestatus.ResourceCreated))
+	assert.True(t, required.SatisfiedBy(resourcestatus.ResourceRemoved))
+	assert.Equal(t, []resourcestatus.ResourceStatus{resourcestatus.ResourceCreated}, required.GetSatisfiedStatuses())
+
+	satisfied := ResourceDependency{
+		Name:              "resource",
+		SatisfiedStatuses: []resourcestatus.ResourceStatus{resourcestatus.ResourceCreated},
+	}
+	assert.False(t, satisfied.SatisfiedBy(resourcestatus.ResourceStatusNone))
+	assert.True(t, satisfied.SatisfiedBy(resourcestatus.ResourceCreated))
+	assert.False(t, satisfied.SatisfiedBy(resourcestatus.ResourceRemoved))
+}
+
+func TestBuildResourceDependencyOnStatuses(t *testing.T) {
+	container := &Container{
+		TransitionDependenciesMap: make(map[apicontainerstatus.ContainerStatus]TransitionDependencySet),
+	}
+	statuses := []resourcestatus.ResourceStatus{resourcestatus.ResourceStatus(3), resourcestatus.ResourceStatus(4)}
+	container.BuildResourceDependencyOnStatuses("volume", statuses, apicontainerstatus.ContainerCreated)
+
+	deps := container.TransitionDependenciesMap[apicontainerstatus.ContainerCreated].ResourceDependencies
+	assert.Len(t, deps, 1)
+	assert.Equal(t, "volume", deps[0].Name)
+	assert.Equal(t, statuses, deps[0].SatisfiedStatuses)
+	assert.Equal(t, resourcestatus.ResourceStatus(3), deps[0].RequiredStatus)
+
+	bytes, err := json.Marshal(container.TransitionDependenciesMap)
+	assert.NoError(t, err)
+	unmarshalledTdMap := TransitionDependenciesMap{}
+	assert.NoError(t, json.Unmarshal(bytes, &unmarshalledTdMap))
+	assert.Equal(t, container.TransitionDependenciesMap, unmarshalledTdMap)
+}
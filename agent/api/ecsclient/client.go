@@ -27,6 +27,7 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/config"
 	"github.com/aws/amazon-ecs-agent/agent/ec2"
 	"github.com/aws/amazon-ecs-agent/agent/ecs_client/model/ecs"
+	"github.com/aws/amazon-ecs-agent/agent/endpoints"
 	"github.com/aws/amazon-ecs-agent/agent/httpclient"
 	"github.com/aws/amazon-ecs-agent/agent/utils"
 	"github.com/aws/aws-sdk-go/aws"
@@ -68,6 +69,10 @@ func NewECSClient(
 	ecsConfig.HTTPClient = httpclient.New(roundtripTimeout, config.AcceptInsecureCert)
 	if config.APIEndpoint != "" {
 		ecsConfig.Endpoint = &config.APIEndpoint
+	} else if endpoint := endpoints.NewVariant(config).ServiceEndpoint(endpoints.ECS, config.AWSRegion); endpoint != "" {
+		// The endpoint is set explicitly so that requests are still signed
+		// for the region, rather than for a FIPS pseudo region
+		ecsConfig.Endpoint = aws.String(endpoint)
 	}
 	standardClient := ecs.New(session.New(&ecsConfig))
 	submitStateChangeClient := newSubmitStateChangeClient(&ecsConfig)
@@ -265,7 +270,13 @@ func (client *APIECSClient) getResources() ([]*ecs.Resource, error) {
 	// Micro-optimization, the pointer to this is used multiple times below
 	integerStr := "INTEGER"
 
-	cpu, mem := getCpuAndMemory()
+	cpu, mem := GetCPUAndMemory()
+	remainingCPU := cpu - client.config.ReservedCPU
+	if remainingCPU < 0 {
+		return nil, fmt.Errorf(
+			"api register-container-instance: reserved cpu is higher than available cpu on the host, total cpu: %d, reserved: %d",
+			cpu, client.config.ReservedCPU)
+	}
 	remainingMem := mem - int64(client.config.ReservedMemory)
 	seelog.Infof("Remaining mem: %d", remainingMem)
 	if remainingMem < 0 {
@@ -277,7 +288,7 @@ func (client *APIECSClient) getResources() ([]*ecs.Resource, error) {
 	cpuResource := ecs.Resource{
 		Name:         utils.Strptr("CPU"),
 		Type:         &integerStr,
-		IntegerValue: &cpu,
+		IntegerValue: &remainingCPU,
 	}
 	memResource := ecs.Resource{
 		Name:         utils.Strptr("MEMORY"),
@@ -298,7 +309,9 @@ func (client *APIECSClient) getResources() ([]*ecs.Resource, error) {
 	return []*ecs.Resource{&cpuResource, &memResource, &portResource, &udpPortResource}, nil
 }
 
-func getCpuAndMemory() (int64, int64) {
+// GetCPUAndMemory returns the CPU, in CPU units, and the memory, in MiB, of
+// the host the container instance is registered with
+func GetCPUAndMemory() (int64, int64) {
 	memInfo, err := system.ReadMemInfo()
 	mem := int64(0)
 	if err == nil {
@@ -395,11 +408,14 @@ func (client *APIECSClient) SubmitTaskStateChange(change api.TaskStateChange) er
 	}
 
 	containerEvents := make([]*ecs.ContainerStateChange, len(change.Containers))
+	var managedAgentEvents []*ecs.ManagedAgentStateChange
 	for i, containerEvent := range change.Containers {
 		containerEvents[i] = client.buildContainerStateChangePayload(containerEvent)
+		managedAgentEvents = append(managedAgentEvents, buildManagedAgentStateChangePayload(containerEvent)...)
 	}
 
 	req.Containers = containerEvents
+	req.ManagedAgents = managedAgentEvents
 
 	_, err := client.submitStateChangeClient.SubmitTaskStateChange(&req)
 	if err != nil {
@@ -419,6 +435,27 @@ func trimString(inputString string, maxLen int) string {
 	}
 }
 
+// buildManagedAgentStateChangePayload returns the state changes of the managed
+// agents of the container that have been started or stopped
+func buildManagedAgentStateChangePayload(change api.ContainerStateChange) []*ecs.ManagedAgentStateChange {
+	var managedAgents []*ecs.ManagedAgentStateChange
+	for _, ma := range change.ManagedAgents {
+		if ma.Status == apicontainerstatus.ManagedAgentStatusNone {
+			continue
+		}
+		managedAgent := &ecs.ManagedAgentStateChange{
+			ContainerName:    aws.String(change.ContainerName),
+			ManagedAgentName: aws.String(ma.Name),
+			Status:           aws.String(ma.Status.String()),
+		}
+		if ma.Reason != "" {
+			managedAgent.Reason = aws.String(trimString(ma.Reason, ecsMaxReasonLength))
+		}
+		managedAgents = append(managedAgents, managedAgent)
+	}
+	return managedAgents
+}
+
 func (client *APIECSClient) buildContainerStateChangePayload(change api.ContainerStateChange) *ecs.ContainerStateChange {
 	statechange := &ecs.ContainerStateChange{
 		ContainerName: aws.String(change.ContainerName),
@@ -449,6 +486,15 @@ func (client *APIECSClient) buildContainerStateChangePayload(change api.Containe
 		exitCode := int64(aws.IntValue(change.ExitCode))
 		statechange.ExitCode = aws.Int64(exitCode)
 	}
+	if change.Health != nil {
+		statechange.HealthStatus = aws.String(change.Health.Status.BackendStatus())
+	}
+	if change.ImagePullInfo != nil {
+		statechange.ImageRegistry = aws.String(change.ImagePullInfo.Registry)
+		statechange.ImageSize = aws.Int64(change.ImagePullInfo.Size)
+		statechange.ImagePullStartedAt = aws.Time(change.ImagePullInfo.PullStartedAt.UTC())
+		statechange.ImagePullStoppedAt = aws.Time(change.ImagePullInfo.PullStoppedAt.UTC())
+	}
 	networkBindings := make([]*ecs.NetworkBinding, len(change.PortBindings))
 	for i, binding := range change.PortBindings {
 		hostPort := int64(binding.HostPort)
@@ -495,6 +541,15 @@ func (client *APIECSClient) SubmitContainerStateChange(change api.ContainerState
 		exitCode := int64(*change.ExitCode)
 		req.ExitCode = &exitCode
 	}
+	if change.Health != nil {
+		req.HealthStatus = aws.String(change.Health.Status.BackendStatus())
+	}
+	if change.ImagePullInfo != nil {
+		req.ImageRegistry = aws.String(change.ImagePullInfo.Registry)
+		req.ImageSize = aws.Int64(change.ImagePullInfo.Size)
+		req.ImagePullStartedAt = aws.Time(change.ImagePullInfo.PullStartedAt.UTC())
+		req.ImagePullStoppedAt = aws.Time(change.ImagePullInfo.PullStoppedAt.UTC())
+	}
 	networkBindings := make([]*ecs.NetworkBinding, len(change.PortBindings))
 	for i, binding := range change.PortBindings {
 		hostPort := int64(binding.HostPort)
@@ -546,7 +601,7 @@ func (client *APIECSClient) DiscoverPollEndpoint(containerInstanceArn string) (s
 		return "", err
 	}
 
-	return aws.StringValue(resp.Endpoint), nil
+	return client.rewriteEndpoint(aws.StringValue(resp.Endpoint))
 }
 
 func (client *APIECSClient) DiscoverTelemetryEndpoint(containerInstanceArn string) (string, error) {
@@ -558,7 +613,16 @@ func (client *APIECSClient) DiscoverTelemetryEndpoint(containerInstanceArn strin
 		return "", errors.New("No telemetry endpoint returned; nil")
 	}
 
-	return aws.StringValue(resp.TelemetryEndpoint), nil
+	return client.rewriteEndpoint(aws.StringValue(resp.TelemetryEndpoint))
+}
+
+// rewriteEndpoint rewrites an endpoint discovered from ECS to the variant of
+// the endpoints enabled in the config, unless a custom ECS endpoint is used
+func (client *APIECSClient) rewriteEndpoint(endpoint string) (string, error) {
+	if client.config.APIEndpoint != "" {
+		return endpoint, nil
+	}
+	return endpoints.NewVariant(client.config).RewriteEndpoint(endpoint, client.config.AWSRegion)
 }
 
 func (client *APIECSClient) discoverPollEndpoint(containerInstanceArn string) (*ecs.DiscoverPollEndpointOutput, error) {
@@ -120,6 +120,11 @@ func (lhs *containerSubmitInputMatcher) Matches(x interface{}) bool {
 	return (equal(lhs.Cluster, rhs.Cluster) &&
 		equal(lhs.ContainerName, rhs.ContainerName) &&
 		equal(lhs.ExitCode, rhs.ExitCode) &&
+		equal(lhs.HealthStatus, rhs.HealthStatus) &&
+		equal(lhs.ImageRegistry, rhs.ImageRegistry) &&
+		equal(lhs.ImageSize, rhs.ImageSize) &&
+		equal(lhs.ImagePullStartedAt, rhs.ImagePullStartedAt) &&
+		equal(lhs.ImagePullStoppedAt, rhs.ImagePullStoppedAt) &&
 		equal(lhs.NetworkBindings, rhs.NetworkBindings) &&
 		equal(lhs.Reason, rhs.Reason) &&
 		equal(lhs.Status, rhs.Status) &&
@@ -250,6 +255,64 @@ func TestSubmitContainerStateChangeFull(t *testing.T) {
 	}
 }
 
+func TestSubmitContainerStateChangeHealth(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	client, _, mockSubmitStateClient := NewMockClient(mockCtrl, ec2.NewBlackholeEC2MetadataClient(), nil)
+
+	mockSubmitStateClient.EXPECT().SubmitContainerStateChange(&containerSubmitInputMatcher{
+		ecs.SubmitContainerStateChangeInput{
+			Cluster:         strptr(configuredCluster),
+			Task:            strptr("arn"),
+			ContainerName:   strptr("cont"),
+			Status:          strptr("RUNNING"),
+			HealthStatus:    strptr("HEALTHY"),
+			NetworkBindings: []*ecs.NetworkBinding{},
+		},
+	})
+	err := client.SubmitContainerStateChange(api.ContainerStateChange{
+		TaskArn:       "arn",
+		ContainerName: "cont",
+		Status:        apicontainerstatus.ContainerRunning,
+		Health:        &apicontainer.HealthStatus{Status: apicontainerstatus.ContainerHealthy},
+	})
+	assert.NoError(t, err)
+}
+
+func TestSubmitContainerStateChangeImagePullInfo(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	client, _, mockSubmitStateClient := NewMockClient(mockCtrl, ec2.NewBlackholeEC2MetadataClient(), nil)
+
+	pullStartedAt := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+	pullStoppedAt := pullStartedAt.Add(10 * time.Second)
+	mockSubmitStateClient.EXPECT().SubmitContainerStateChange(&containerSubmitInputMatcher{
+		ecs.SubmitContainerStateChangeInput{
+			Cluster:            strptr(configuredCluster),
+			Task:               strptr("arn"),
+			ContainerName:      strptr("cont"),
+			Status:             strptr("RUNNING"),
+			ImageRegistry:      strptr("registry.example.com"),
+			ImageSize:          aws.Int64(1024),
+			ImagePullStartedAt: aws.Time(pullStartedAt),
+			ImagePullStoppedAt: aws.Time(pullStoppedAt),
+			NetworkBindings:    []*ecs.NetworkBinding{},
+		},
+	})
+	err := client.SubmitContainerStateChange(api.ContainerStateChange{
+		TaskArn:       "arn",
+		ContainerName: "cont",
+		Status:        apicontainerstatus.ContainerRunning,
+		ImagePullInfo: &apicontainer.ImagePullInfo{
+			Registry:      "registry.example.com",
+			Size:          1024,
+			PullStartedAt: pullStartedAt,
+			PullStoppedAt: pullStoppedAt,
+		},
+	})
+	assert.NoError(t, err)
+}
+
 func TestSubmitContainerStateChangeReason(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
@@ -538,7 +601,7 @@ func TestRegisterContainerInstanceWithNegativeResource(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
 
-	_, mem := getCpuAndMemory()
+	_, mem := GetCPUAndMemory()
 	mockEC2Metadata := mock_ec2.NewMockEC2MetadataClient(mockCtrl)
 	client := NewECSClient(credentials.AnonymousCredentials,
 		&config.Config{Cluster: configuredCluster,
@@ -559,6 +622,33 @@ func TestRegisterContainerInstanceWithNegativeResource(t *testing.T) {
 	assert.Error(t, err, "Register resource with negative value should cause registration fail")
 }
 
+// TestRegisterContainerInstanceWithNegativeCPU tests the registration fails
+// when more cpu is reserved than the host has
+func TestRegisterContainerInstanceWithNegativeCPU(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	cpu, _ := GetCPUAndMemory()
+	mockEC2Metadata := mock_ec2.NewMockEC2MetadataClient(mockCtrl)
+	client := NewECSClient(credentials.AnonymousCredentials,
+		&config.Config{Cluster: configuredCluster,
+			AWSRegion:   "us-east-1",
+			ReservedCPU: cpu + 1,
+		}, mockEC2Metadata)
+	mockSDK := mock_api.NewMockECSSDK(mockCtrl)
+	mockSubmitStateSDK := mock_api.NewMockECSSubmitStateSDK(mockCtrl)
+	client.(*APIECSClient).SetSDK(mockSDK)
+	client.(*APIECSClient).SetSubmitStateChangeSDK(mockSubmitStateSDK)
+
+	gomock.InOrder(
+		mockEC2Metadata.EXPECT().GetDynamicData(ec2.InstanceIdentityDocumentResource).Return("instanceIdentityDocument", nil),
+		mockEC2Metadata.EXPECT().GetDynamicData(ec2.InstanceIdentityDocumentSignatureResource).Return("signature", nil),
+	)
+	_, _, err := client.RegisterContainerInstance("", nil, nil,
+		"", nil, "")
+	assert.Error(t, err, "Reserving more cpu than the host has should cause registration fail")
+}
+
 func TestRegisterContainerInstanceWithEmptyTags(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
@@ -736,6 +826,48 @@ func TestDiscoverTelemetryEndpoint(t *testing.T) {
 	}
 }
 
+func TestDiscoverEndpointsFIPSDualStack(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	client, mc, _ := NewMockClientWithConfig(mockCtrl, ec2.NewBlackholeEC2MetadataClient(), nil,
+		&config.Config{
+			Cluster:                   configuredCluster,
+			AWSRegion:                 "us-east-1",
+			FIPSEndpointsEnabled:      true,
+			DualStackEndpointsEnabled: true,
+		})
+	mc.EXPECT().DiscoverPollEndpoint(gomock.Any()).Return(&ecs.DiscoverPollEndpointOutput{
+		Endpoint:          aws.String("https://ecs-a-1.us-east-1.amazonaws.com/"),
+		TelemetryEndpoint: aws.String("https://ecs-t-1.us-east-1.amazonaws.com/"),
+	}, nil)
+
+	endpoint, err := client.DiscoverPollEndpoint("containerInstance")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://ecs-a-1-fips.us-east-1.api.aws/", endpoint)
+	endpoint, err = client.DiscoverTelemetryEndpoint("containerInstance")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://ecs-t-1-fips.us-east-1.api.aws/", endpoint)
+}
+
+func TestDiscoverEndpointsCustomBackend(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	client, mc, _ := NewMockClientWithConfig(mockCtrl, ec2.NewBlackholeEC2MetadataClient(), nil,
+		&config.Config{
+			Cluster:              configuredCluster,
+			AWSRegion:            "us-east-1",
+			APIEndpoint:          "https://ecs.example.com",
+			FIPSEndpointsEnabled: true,
+		})
+	mc.EXPECT().DiscoverPollEndpoint(gomock.Any()).Return(&ecs.DiscoverPollEndpointOutput{
+		Endpoint: aws.String("https://ecs-a-1.us-east-1.amazonaws.com/"),
+	}, nil)
+
+	endpoint, err := client.DiscoverPollEndpoint("containerInstance")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://ecs-a-1.us-east-1.amazonaws.com/", endpoint)
+}
+
 func TestDiscoverTelemetryEndpointError(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
@@ -980,6 +1112,32 @@ func TestSubmitTaskStateChangeWithoutAttachments(t *testing.T) {
 	assert.NoError(t, err, "Unable to submit task state change with no attachments")
 }
 
+func TestSubmitTaskStateChangeContainerHealth(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	client, _, mockSubmitStateClient := NewMockClient(mockCtrl, ec2.NewBlackholeEC2MetadataClient(), nil)
+	mockSubmitStateClient.EXPECT().SubmitTaskStateChange(gomock.Any()).Do(
+		func(req *ecs.SubmitTaskStateChangeInput) {
+			assert.Len(t, req.Containers, 1)
+			assert.Equal(t, "UNHEALTHY", aws.StringValue(req.Containers[0].HealthStatus))
+		})
+
+	err := client.SubmitTaskStateChange(api.TaskStateChange{
+		TaskARN: "task_arn",
+		Status:  apitaskstatus.TaskRunning,
+		Containers: []api.ContainerStateChange{
+			{
+				TaskArn:       "task_arn",
+				ContainerName: "cont",
+				Status:        apicontainerstatus.ContainerRunning,
+				Health:        &apicontainer.HealthStatus{Status: apicontainerstatus.ContainerUnhealthy},
+			},
+		},
+	})
+	assert.NoError(t, err)
+}
+
 // TestSubmitContainerStateChangeWhileTaskInPending tests the container state change was submitted
 // when the task is still in pending state
 func TestSubmitContainerStateChangeWhileTaskInPending(t *testing.T) {
@@ -46,6 +46,10 @@ type ENI struct {
 	PrivateDNSName string `json:",omitempty"`
 	// SubnetGatewayIPV4Address is the address to the subnet gateway for the eni
 	SubnetGatewayIPV4Address string `json:",omitempty"`
+	// SubnetGatewayIPV6Address is the address to the subnet gateway for the
+	// eni, with the prefix length of the ipv6 subnet, for enis with ipv6
+	// addresses
+	SubnetGatewayIPV6Address string `json:",omitempty"`
 }
 
 // InterfaceVlanProperties contains information for an interface that
@@ -61,6 +65,10 @@ const (
 
 	// VLANInterfaceAssociationProtocol represents the ENI with trunking enabled.
 	VLANInterfaceAssociationProtocol = "vlan"
+
+	// AmazonIPV6DNSServer is the address of the Amazon provided DNS server
+	// reachable from ipv6 only subnets
+	AmazonIPV6DNSServer = "fd00:ec2::253"
 )
 
 // GetIPV4Addresses returns a list of ipv4 addresses allocated to the ENI
@@ -96,6 +104,36 @@ func (eni *ENI) GetIPV6Addresses() []string {
 	return addresses
 }
 
+// GetPrimaryIPv6Address returns the first IPv6 address associated with the
+// ENI, empty if it has none
+func (eni *ENI) GetPrimaryIPv6Address() string {
+	if len(eni.IPV6Addresses) == 0 {
+		return ""
+	}
+	return eni.IPV6Addresses[0].Address
+}
+
+// IsIPv6Only returns true if the ENI only has IPv6 addresses, which is the case
+// of the ENIs of ipv6 only subnets
+func (eni *ENI) IsIPv6Only() bool {
+	return len(eni.IPV4Addresses) == 0 && len(eni.IPV6Addresses) > 0
+}
+
+// IsDualStack returns true if the ENI has both IPv4 and IPv6 addresses
+func (eni *ENI) IsDualStack() bool {
+	return len(eni.IPV4Addresses) > 0 && len(eni.IPV6Addresses) > 0
+}
+
+// GetDomainNameServers returns the nameserver IP addresses for the ENI. ENIs
+// of ipv6 only subnets without nameservers use the Amazon provided DNS server,
+// since the ipv4 one the instance uses isn't reachable from them
+func (eni *ENI) GetDomainNameServers() []string {
+	if len(eni.DomainNameServers) == 0 && eni.IsIPv6Only() {
+		return []string{AmazonIPV6DNSServer}
+	}
+	return eni.DomainNameServers
+}
+
 // GetHostname returns the hostname assigned to the ENI
 func (eni *ENI) GetHostname() string {
 	return eni.PrivateDNSName
@@ -107,6 +145,12 @@ func (eni *ENI) GetSubnetGatewayIPV4Address() string {
 	return eni.SubnetGatewayIPV4Address
 }
 
+// GetSubnetGatewayIPV6Address returns the subnet IPv6 gateway address assigned
+// to the ENI
+func (eni *ENI) GetSubnetGatewayIPV6Address() string {
+	return eni.SubnetGatewayIPV6Address
+}
+
 // IsStandardENI returns true if the ENI is a standard/regular ENI. That is, if it
 // has its association protocol as standard. To be backwards compatible, if the
 // association protocol is not set for an ENI, it's considered a standard ENI as well.
@@ -145,9 +189,9 @@ func (eni *ENI) String() string {
 
 	return fmt.Sprintf(
 		"eni id:%s, mac: %s, hostname: %s, ipv4addresses: [%s], ipv6addresses: [%s], dns: [%s], dns search: [%s],"+
-			" gateway ipv4: [%s][%s]", eni.ID, eni.MacAddress, eni.GetHostname(), strings.Join(ipv4Addresses, ","),
+			" gateway ipv4: [%s], gateway ipv6: [%s][%s]", eni.ID, eni.MacAddress, eni.GetHostname(), strings.Join(ipv4Addresses, ","),
 		strings.Join(ipv6Addresses, ","), strings.Join(eni.DomainNameServers, ","),
-		strings.Join(eni.DomainNameSearchList, ","), eni.SubnetGatewayIPV4Address, eniString)
+		strings.Join(eni.DomainNameSearchList, ","), eni.SubnetGatewayIPV4Address, eni.SubnetGatewayIPV6Address, eniString)
 }
 
 // ENIIPV4Address is the ipv4 information of the eni
@@ -203,6 +247,7 @@ func ENIFromACS(acsENI *ecsacs.ElasticNetworkInterface) (*ENI, error) {
 		MacAddress:                   aws.StringValue(acsENI.MacAddress),
 		PrivateDNSName:               aws.StringValue(acsENI.PrivateDnsName),
 		SubnetGatewayIPV4Address:     aws.StringValue(acsENI.SubnetGatewayIpv4Address),
+		SubnetGatewayIPV6Address:     aws.StringValue(acsENI.SubnetGatewayIpv6Address),
 		InterfaceAssociationProtocol: aws.StringValue(acsENI.InterfaceAssociationProtocol),
 		InterfaceVlanProperties:      &interfaceVlanProperties,
 	}
@@ -219,9 +264,10 @@ func ENIFromACS(acsENI *ecsacs.ElasticNetworkInterface) (*ENI, error) {
 
 // ValidateTaskENI validates the ENI information sent from ACS.
 func ValidateTaskENI(acsENI *ecsacs.ElasticNetworkInterface) error {
-	// At least one IPv4 address should be associated with the ENI.
-	if len(acsENI.Ipv4Addresses) < 1 {
-		return errors.Errorf("eni message validation: no ipv4 addresses in the message")
+	// At least one IPv4 address, or one IPv6 address for ENIs of ipv6 only
+	// subnets, should be associated with the ENI.
+	if len(acsENI.Ipv4Addresses) < 1 && len(acsENI.Ipv6Addresses) < 1 {
+		return errors.Errorf("eni message validation: no ipv4 or ipv6 addresses in the message")
 	}
 
 	if acsENI.MacAddress == nil {
@@ -247,6 +293,10 @@ func ValidateTaskENI(acsENI *ecsacs.ElasticNetworkInterface) error {
 			len(aws.StringValue(acsENI.InterfaceVlanProperties.TrunkInterfaceMacAddress)) == 0 {
 			return errors.New("vlan interface properties missing")
 		}
+		// The branch eni plugin only configures an ipv4 address
+		if len(acsENI.Ipv4Addresses) < 1 {
+			return errors.New("eni message validation: ipv6 only branch enis aren't supported")
+		}
 	}
 
 	return nil
@@ -21,6 +21,7 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/acs/model/ecsacs"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 const (
@@ -98,6 +99,61 @@ func TestValidateENIFromACS(t *testing.T) {
 	assert.Error(t, err)
 }
 
+// TestIPv6OnlyENIFromACS tests the enis of ipv6 only subnets from acs
+func TestIPv6OnlyENIFromACS(t *testing.T) {
+	acsENI := &ecsacs.ElasticNetworkInterface{
+		AttachmentArn: aws.String("arn"),
+		Ec2Id:         aws.String("ec2id"),
+		Ipv6Addresses: []*ecsacs.IPv6AddressAssignment{
+			{
+				Address: aws.String("2600:1f14:abcd::10"),
+			},
+		},
+		MacAddress:               aws.String("mac"),
+		SubnetGatewayIpv6Address: aws.String("2600:1f14:abcd::1/64"),
+	}
+	eni, err := ENIFromACS(acsENI)
+	require.NoError(t, err)
+	assert.True(t, eni.IsIPv6Only())
+	assert.False(t, eni.IsDualStack())
+	assert.Equal(t, "2600:1f14:abcd::10", eni.GetPrimaryIPv6Address())
+	assert.Equal(t, "2600:1f14:abcd::1/64", eni.GetSubnetGatewayIPV6Address())
+	assert.Equal(t, []string{AmazonIPV6DNSServer}, eni.GetDomainNameServers())
+
+	eni.DomainNameServers = []string{"2600:1f14:abcd::2"}
+	assert.Equal(t, []string{"2600:1f14:abcd::2"}, eni.GetDomainNameServers())
+}
+
+// TestIPv6OnlyBranchENIFromACS tests that ipv6 only branch enis are rejected
+func TestIPv6OnlyBranchENIFromACS(t *testing.T) {
+	acsENI := &ecsacs.ElasticNetworkInterface{
+		InterfaceAssociationProtocol: aws.String(VLANInterfaceAssociationProtocol),
+		AttachmentArn:                aws.String("arn"),
+		Ec2Id:                        aws.String("ec2id"),
+		Ipv6Addresses: []*ecsacs.IPv6AddressAssignment{
+			{
+				Address: aws.String("2600:1f14:abcd::10"),
+			},
+		},
+		MacAddress: aws.String("mac"),
+		InterfaceVlanProperties: &ecsacs.NetworkInterfaceVlanProperties{
+			TrunkInterfaceMacAddress: aws.String("trunk-mac"),
+			VlanId:                   aws.String("12345"),
+		},
+	}
+	assert.Error(t, ValidateTaskENI(acsENI))
+}
+
+func TestDualStackENIDomainNameServers(t *testing.T) {
+	eni := &ENI{
+		IPV4Addresses: []*ENIIPV4Address{{Primary: true, Address: "10.0.0.2"}},
+		IPV6Addresses: []*ENIIPV6Address{{Address: "2600:1f14:abcd::10"}},
+	}
+	assert.True(t, eni.IsDualStack())
+	assert.False(t, eni.IsIPv6Only())
+	assert.Empty(t, eni.GetDomainNameServers())
+}
+
 func TestInvalidENIInterfaceVlanPropertyMissing(t *testing.T) {
 	acsENI := &ecsacs.ElasticNetworkInterface{
 		InterfaceAssociationProtocol: aws.String(VLANInterfaceAssociationProtocol),
@@ -127,3 +127,27 @@ func (err *ResourceInitError) Error() string {
 func (err *ResourceInitError) ErrorName() string {
 	return "ResourceInitializationError"
 }
+
+// NetworkModeNotAllowedError is a task error for a container in a network
+// mode the agent is configured to not run tasks in
+type NetworkModeNotAllowedError struct {
+	// NetworkMode is the network mode that isn't allowed
+	NetworkMode string
+	// ContainerName is the name of the container in that network mode, empty
+	// when it's the network mode of the task, such as awsvpc
+	ContainerName string
+}
+
+// Error returns the error as a string
+func (err *NetworkModeNotAllowedError) Error() string {
+	if err.ContainerName == "" {
+		return fmt.Sprintf("network mode %s of the task is not allowed on this instance", err.NetworkMode)
+	}
+	return fmt.Sprintf("network mode %s of container %s is not allowed on this instance",
+		err.NetworkMode, err.ContainerName)
+}
+
+// ErrorName is the name of the error
+func (err *NetworkModeNotAllowedError) ErrorName() string {
+	return "NetworkModeNotAllowedError"
+}
@@ -50,6 +50,13 @@ type ContainerStateChange struct {
 	// PortBindings are the details of the host ports picked for the specified
 	// container ports
 	PortBindings []apicontainer.PortBinding
+	// Health is the health of the container, if it has a health check
+	Health *apicontainer.HealthStatus
+	// ManagedAgents are the agents the ECS Agent runs inside of the container
+	ManagedAgents []apicontainer.ManagedAgent
+	// ImagePullInfo describes where the image of the container was pulled from
+	// and how long it took, if the agent pulled it
+	ImagePullInfo *apicontainer.ImagePullInfo
 
 	// Container is a pointer to the container involved in the state change that gives the event handler a hook into
 	// storing what status was sent.  This is used to ensure the same event is handled only once.
@@ -147,9 +154,15 @@ func NewContainerStateChangeEvent(task *apitask.Task, cont *apicontainer.Contain
 		ExitCode:      cont.GetKnownExitCode(),
 		PortBindings:  cont.GetKnownPortBindings(),
 		ImageDigest:   cont.GetImageDigest(),
+		ImagePullInfo: cont.GetImagePullInfo(),
+		ManagedAgents: cont.GetManagedAgents(),
 		Reason:        reason,
 		Container:     cont,
 	}
+	if cont.HealthStatusShouldBeReported() {
+		health := cont.GetHealthStatus()
+		event.Health = &health
+	}
 	return event, nil
 }
 
@@ -172,6 +185,16 @@ func (c *ContainerStateChange) String() string {
 	if len(c.PortBindings) != 0 {
 		res += fmt.Sprintf(", Ports %v", c.PortBindings)
 	}
+	if c.Health != nil {
+		res += ", Health " + c.Health.Status.String()
+	}
+	for _, ma := range c.ManagedAgents {
+		res += fmt.Sprintf(", %s %s", ma.Name, ma.Status.String())
+	}
+	if c.ImagePullInfo != nil {
+		res += fmt.Sprintf(", Image registry %s, Image size %d, Image pull duration %s", c.ImagePullInfo.Registry,
+			c.ImagePullInfo.Size, c.ImagePullInfo.PullStoppedAt.Sub(c.ImagePullInfo.PullStartedAt))
+	}
 	if c.Container != nil {
 		res += ", Known Sent: " + c.Container.GetSentStatus().String()
 	}
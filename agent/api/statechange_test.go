@@ -125,3 +125,60 @@ func TestSetImageDigest(t *testing.T) {
 	assert.NoError(t, ok, "error create newContainerStateChangeEvent")
 	assert.Equal(t, "sha256:d1c14fcf2e9476ed58ebc4251b211f403f271e96b6c3d9ada0f1c5454ca4d230", resp.ImageDigest)
 }
+
+func TestSetContainerHealth(t *testing.T) {
+	task := &apitask.Task{}
+	steadyStateStatus := apicontainerstatus.ContainerRunning
+	Containers := []*apicontainer.Container{
+		{
+			HealthCheckType:         apicontainer.DockerHealthCheckType,
+			KnownStatusUnsafe:       apicontainerstatus.ContainerRunning,
+			SentStatusUnsafe:        apicontainerstatus.ContainerStatusNone,
+			Type:                    apicontainer.ContainerNormal,
+			SteadyStateStatusUnsafe: &steadyStateStatus,
+		},
+		{
+			KnownStatusUnsafe:       apicontainerstatus.ContainerRunning,
+			SentStatusUnsafe:        apicontainerstatus.ContainerStatusNone,
+			Type:                    apicontainer.ContainerNormal,
+			SteadyStateStatusUnsafe: &steadyStateStatus,
+		},
+	}
+	Containers[0].SetHealthStatus(apicontainer.HealthStatus{Status: apicontainerstatus.ContainerHealthy})
+
+	task.Containers = Containers
+	resp, err := NewContainerStateChangeEvent(task, task.Containers[0], "")
+	assert.NoError(t, err, "error create newContainerStateChangeEvent")
+	assert.Equal(t, apicontainerstatus.ContainerHealthy, resp.Health.Status)
+
+	resp, err = NewContainerStateChangeEvent(task, task.Containers[1], "")
+	assert.NoError(t, err, "error create newContainerStateChangeEvent")
+	assert.Nil(t, resp.Health)
+}
+
+func TestSetImagePullInfo(t *testing.T) {
+	task := &apitask.Task{}
+	steadyStateStatus := apicontainerstatus.ContainerRunning
+	container := &apicontainer.Container{
+		KnownStatusUnsafe:       apicontainerstatus.ContainerRunning,
+		SentStatusUnsafe:        apicontainerstatus.ContainerStatusNone,
+		Type:                    apicontainer.ContainerNormal,
+		SteadyStateStatusUnsafe: &steadyStateStatus,
+	}
+	task.Containers = []*apicontainer.Container{container}
+
+	resp, err := NewContainerStateChangeEvent(task, container, "")
+	assert.NoError(t, err, "error create newContainerStateChangeEvent")
+	assert.Nil(t, resp.ImagePullInfo)
+
+	info := &apicontainer.ImagePullInfo{
+		Registry:      "docker.io",
+		Size:          1024,
+		PullStartedAt: time.Now().Add(-time.Minute),
+		PullStoppedAt: time.Now(),
+	}
+	container.SetImagePullInfo(info)
+	resp, err = NewContainerStateChangeEvent(task, container, "")
+	assert.NoError(t, err, "error create newContainerStateChangeEvent")
+	assert.Equal(t, info, resp.ImagePullInfo)
+}
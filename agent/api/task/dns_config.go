@@ -0,0 +1,60 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package task
+
+import (
+	"net"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// maxDNSNameservers is the number of nameservers the resolver reads from
+// resolv.conf, the others are ignored
+const maxDNSNameservers = 3
+
+// DNSConfig overrides the DNS settings written to the resolv.conf of the
+// network namespace of an awsvpc task, which are otherwise those of the VPC
+type DNSConfig struct {
+	// Nameservers are the IP addresses of the nameservers
+	Nameservers []string `json:"nameservers,omitempty"`
+	// SearchDomains are the domains of the search list
+	SearchDomains []string `json:"searchDomains,omitempty"`
+	// Options are the resolver options, such as ndots:2
+	Options []string `json:"options,omitempty"`
+}
+
+// Validate returns an error if the DNS settings can't be written to resolv.conf
+func (dnsConfig *DNSConfig) Validate() error {
+	if len(dnsConfig.Nameservers) > maxDNSNameservers {
+		return errors.Errorf("too many nameservers: %d, at most %d are supported",
+			len(dnsConfig.Nameservers), maxDNSNameservers)
+	}
+	for _, nameserver := range dnsConfig.Nameservers {
+		if net.ParseIP(nameserver) == nil {
+			return errors.Errorf("invalid nameserver: %q", nameserver)
+		}
+	}
+	for _, searchDomain := range dnsConfig.SearchDomains {
+		if searchDomain == "" || strings.ContainsAny(searchDomain, " \t\n") {
+			return errors.Errorf("invalid search domain: %q", searchDomain)
+		}
+	}
+	for _, option := range dnsConfig.Options {
+		if option == "" || strings.ContainsAny(option, " \t\n") {
+			return errors.Errorf("invalid resolver option: %q", option)
+		}
+	}
+	return nil
+}
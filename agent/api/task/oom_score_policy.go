@@ -0,0 +1,43 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package task
+
+import (
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/pkg/errors"
+)
+
+// OOMScorePolicy is the oom_score_adj a task sets for its containers, so that
+// the kernel kills its least important containers first when the instance runs
+// out of memory. A score that isn't set falls back to the agent config
+type OOMScorePolicy struct {
+	// EssentialContainerOOMScoreAdj is the oom_score_adj of the essential
+	// containers of the task and of the containers the agent runs for it
+	EssentialContainerOOMScoreAdj *int `json:"essentialContainerOomScoreAdj,omitempty"`
+	// NonEssentialContainerOOMScoreAdj is the oom_score_adj of the
+	// non-essential containers of the task
+	NonEssentialContainerOOMScoreAdj *int `json:"nonEssentialContainerOomScoreAdj,omitempty"`
+}
+
+// Validate returns an error if a score is out of the bounds accepted by the
+// kernel
+func (policy *OOMScorePolicy) Validate() error {
+	for _, oomScoreAdj := range []*int{policy.EssentialContainerOOMScoreAdj, policy.NonEssentialContainerOOMScoreAdj} {
+		if oomScoreAdj != nil && (*oomScoreAdj < config.MinimumOOMScoreAdj || *oomScoreAdj > config.MaximumOOMScoreAdj) {
+			return errors.Errorf("oom score adjustment %d is not within [%d, %d]",
+				*oomScoreAdj, config.MinimumOOMScoreAdj, config.MaximumOOMScoreAdj)
+		}
+	}
+	return nil
+}
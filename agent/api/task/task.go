@@ -29,6 +29,7 @@ import (
 	"github.com/aws/aws-sdk-go/aws/arn"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/go-connections/nat"
+	"github.com/docker/go-units"
 
 	"github.com/aws/amazon-ecs-agent/agent/acs/model/ecsacs"
 	apiappmesh "github.com/aws/amazon-ecs-agent/agent/api/appmesh"
@@ -42,10 +43,16 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/dockerclient"
 	"github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi"
 	"github.com/aws/amazon-ecs-agent/agent/ecscni"
+	"github.com/aws/amazon-ecs-agent/agent/experiments"
+	"github.com/aws/amazon-ecs-agent/agent/gpu"
+	"github.com/aws/amazon-ecs-agent/agent/secretprovider"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource/asmauth"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource/asmsecret"
+	taskresourceefs "github.com/aws/amazon-ecs-agent/agent/taskresource/efs"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource/firelens"
+	taskresourcenetwork "github.com/aws/amazon-ecs-agent/agent/taskresource/network"
+	"github.com/aws/amazon-ecs-agent/agent/taskresource/providersecret"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource/ssmsecret"
 	resourcestatus "github.com/aws/amazon-ecs-agent/agent/taskresource/status"
 	resourcetype "github.com/aws/amazon-ecs-agent/agent/taskresource/types"
@@ -79,6 +86,9 @@ const (
 	ContainerOrderingCreateCondition = "CREATE"
 	ContainerOrderingStartCondition  = "START"
 
+	// cpuUnitsPerVCPU is the number of CPU units ECS accounts for each vCPU
+	cpuUnitsPerVCPU = 1024
+
 	arnResourceSections  = 2
 	arnResourceDelimiter = "/"
 	// networkModeNone specifies the string used to define the `none` docker networking mode
@@ -98,6 +108,10 @@ const (
 	ipcModeSharable = "shareable"
 	ipcModeNone     = "none"
 
+	// efsMountDirectory is the directory, under the data directory of the agent,
+	// where the EFS volumes of the tasks are mounted
+	efsMountDirectory = "efs"
+
 	// firelensConfigBindFormatFluentd and firelensConfigBindFormatFluentbit specify the format of the firelens
 	// config file bind mount for fluentd and fluentbit firelens container respectively.
 	// First placeholder is host data dir, second placeholder is taskID.
@@ -163,8 +177,42 @@ type Task struct {
 	// CPU is a task-level limit for compute resources. A value of 1 means that
 	// the task may access 100% of 1 vCPU on the instance
 	CPU float64 `json:"Cpu,omitempty"`
+	// CPUBurst is the CPU time, in vCPUs like CPU, the task may use above its
+	// CPU limit to absorb short spikes, out of the quota it left unused in
+	// previous periods. It can't be greater than CPU
+	CPUBurst float64 `json:"CpuBurst,omitempty"`
 	// Memory is a task-level limit for memory resources in bytes
 	Memory int64 `json:"Memory,omitempty"`
+	// Experiments are the experimental features the task opts into. An
+	// experiment applies to the task only when it's enabled on the instance too
+	Experiments []string `json:"experiments,omitempty"`
+	// StaticName is the name of the static task the task runs, for the tasks
+	// the agent runs from the static tasks directory rather than for ECS
+	StaticName string `json:"StaticName,omitempty"`
+	// GPUComputeMode is the compute mode the task requests for the GPUs
+	// assigned to its containers, e.g. EXCLUSIVE_PROCESS. The GPUs are set
+	// back to the default compute mode once the task stops
+	GPUComputeMode string `json:"gpuComputeMode,omitempty"`
+	// EphemeralStorage is the size the writable layers of the containers of
+	// the task add up to, enforced with docker's storage quota
+	EphemeralStorage *EphemeralStorage `json:"ephemeralStorage,omitempty"`
+	// CleanupWaitDurationSeconds overrides the time the agent waits after the
+	// task stops before it cleans up its containers, 0 to use the time of the
+	// agent config
+	CleanupWaitDurationSeconds int64 `json:"cleanupWaitDurationSeconds,omitempty"`
+	// NetworkBandwidth is the limit of the bandwidth of the traffic of the
+	// task, shaped on its ENI by the bandwidth CNI plugin
+	NetworkBandwidth *ecscni.NetworkBandwidth `json:"networkBandwidth,omitempty"`
+	// DNSConfig overrides the DNS settings of the VPC in the resolv.conf of
+	// the network namespace of an awsvpc task
+	DNSConfig *DNSConfig `json:"dnsConfig,omitempty"`
+	// BridgeNetworkName is the name of the docker network the task asks the
+	// agent to create for its containers in bridge network mode, so that they
+	// can find each other by container name
+	BridgeNetworkName string `json:"bridgeNetworkName,omitempty"`
+	// OOMScorePolicy overrides the oom_score_adj the agent config sets for
+	// the essential and non-essential containers of the task
+	OOMScorePolicy *OOMScorePolicy `json:"oomScorePolicy,omitempty"`
 	// DesiredStatusUnsafe represents the state where the task should go. Generally,
 	// the desired status is informed by the ECS backend as a result of either
 	// API calls made to ECS or decisions made by the ECS service scheduler.
@@ -196,6 +244,16 @@ type Task struct {
 	// PullStoppedAtUnsafe is the timestamp when the task finished pulling the last container,
 	// it won't be set if the pull never happens
 	PullStoppedAtUnsafe time.Time `json:"PullStoppedAt"`
+	// PinnedImageDigestsUnsafe maps the images of the task to the digests they
+	// were resolved to when pulling images by digest
+	// NOTE: Do not access PinnedImageDigestsUnsafe directly, instead use
+	// `PinImageDigest` and `GetPinnedImageDigest`.
+	PinnedImageDigestsUnsafe map[string]string `json:"PinnedImageDigests,omitempty"`
+	// RestartTimesUnsafe are the times the containers of the task were restarted
+	// by the agent within the task restart limit window
+	// NOTE: Do not access RestartTimesUnsafe directly, instead use
+	// `RecordContainerRestart`.
+	RestartTimesUnsafe []time.Time `json:"RestartTimes,omitempty"`
 	// ExecutionStoppedAtUnsafe is the timestamp when the task desired status moved to stopped,
 	// which is when the any of the essential containers stopped
 	ExecutionStoppedAtUnsafe time.Time `json:"ExecutionStoppedAt"`
@@ -250,10 +308,25 @@ type Task struct {
 	// NvidiaRuntime is the runtime to pass Nvidia GPU devices to containers
 	NvidiaRuntime string `json:"NvidiaRuntime,omitempty"`
 
+	// RuntimeSettings are the key-value settings ACS sends for the task while
+	// it runs, which the agent writes to the runtime settings file of its
+	// containers
+	RuntimeSettings map[string]string `json:"RuntimeSettings,omitempty"`
+	// RuntimeSettingsVersion is the version of RuntimeSettings, settings with
+	// an older version received later are ignored
+	RuntimeSettingsVersion int64 `json:"RuntimeSettingsVersion,omitempty"`
+
 	// lock is for protecting all fields in the task struct
 	lock sync.RWMutex
 }
 
+// EphemeralStorage is the ephemeral storage the containers of a task get
+type EphemeralStorage struct {
+	// SizeInGiB is the size split evenly between the writable layers of the
+	// containers of the task
+	SizeInGiB int64 `json:"sizeInGiB"`
+}
+
 // TaskFromACS translates ecsacs.Task to apitask.Task by first marshaling the received
 // ecsacs.Task to json and unmarshaling it as apitask.Task
 func TaskFromACS(acsTask *ecsacs.Task, envelope *ecsacs.PayloadMessage) (*Task, error) {
@@ -272,11 +345,9 @@ func TaskFromACS(acsTask *ecsacs.Task, envelope *ecsacs.PayloadMessage) (*Task,
 		task.StopSequenceNumber = *envelope.SeqNum
 	}
 
-	// Overrides the container command if it's set
+	// The command and entrypoint overrides of the containers are applied when
+	// they're created, so that the values of the task definition are kept
 	for _, container := range task.Containers {
-		if (container.Overrides != apicontainer.ContainerOverrides{}) && container.Overrides.Command != nil {
-			container.Command = *container.Overrides.Command
-		}
 		container.TransitionDependenciesMap = make(map[apicontainerstatus.ContainerStatus]apicontainer.TransitionDependencySet)
 	}
 
@@ -326,6 +397,10 @@ func (task *Task) PostUnmarshalTask(cfg *config.Config,
 		task.initializeASMSecretResource(credentialsManager, resourceFields)
 	}
 
+	if task.requiresProviderSecret() {
+		task.initializeProviderSecretResource(resourceFields)
+	}
+
 	err = task.initializeDockerLocalVolumes(dockerClient, ctx)
 	if err != nil {
 		return apierrors.NewResourceInitError(task.Arn, err)
@@ -334,6 +409,10 @@ func (task *Task) PostUnmarshalTask(cfg *config.Config,
 	if err != nil {
 		return apierrors.NewResourceInitError(task.Arn, err)
 	}
+	err = task.initializeEFSVolumes(cfg, ctx)
+	if err != nil {
+		return apierrors.NewResourceInitError(task.Arn, err)
+	}
 	if cfg.GPUSupportEnabled {
 		err = task.addGPUResource()
 		if err != nil {
@@ -342,6 +421,38 @@ func (task *Task) PostUnmarshalTask(cfg *config.Config,
 		}
 		task.NvidiaRuntime = cfg.NvidiaRuntime
 	}
+	if task.GPUComputeMode != "" && !gpu.ValidComputeMode(task.GPUComputeMode) {
+		err = errors.Errorf("invalid GPU compute mode: %s", task.GPUComputeMode)
+		return apierrors.NewResourceInitError(task.Arn, err)
+	}
+	if task.EphemeralStorage != nil && task.EphemeralStorage.SizeInGiB <= 0 {
+		err = errors.Errorf("invalid ephemeral storage size: %d GiB", task.EphemeralStorage.SizeInGiB)
+		return apierrors.NewResourceInitError(task.Arn, err)
+	}
+	if task.CleanupWaitDurationSeconds < 0 {
+		err = errors.Errorf("invalid cleanup wait duration: %d seconds", task.CleanupWaitDurationSeconds)
+		return apierrors.NewResourceInitError(task.Arn, err)
+	}
+	if err = task.validateNetworkBandwidth(); err != nil {
+		return apierrors.NewResourceInitError(task.Arn, err)
+	}
+	if err = task.validateDNSConfig(); err != nil {
+		return apierrors.NewResourceInitError(task.Arn, err)
+	}
+	if task.OOMScorePolicy != nil {
+		if err = task.OOMScorePolicy.Validate(); err != nil {
+			return apierrors.NewResourceInitError(task.Arn, errors.Wrap(err, "invalid oom score policy"))
+		}
+	}
+	if err = task.validateENIs(cfg); err != nil {
+		return apierrors.NewResourceInitError(task.Arn, err)
+	}
+	if err = task.validateBridgeNetworkName(); err != nil {
+		return apierrors.NewResourceInitError(task.Arn, err)
+	}
+	if err = task.validateStdoutHealthChecks(); err != nil {
+		return apierrors.NewResourceInitError(task.Arn, err)
+	}
 	task.initializeCredentialsEndpoint(credentialsManager)
 	task.initializeContainersV3MetadataEndpoint(utils.NewDynamicUUIDProvider())
 	err = task.addNetworkResourceProvisioningDependency(cfg)
@@ -349,9 +460,27 @@ func (task *Task) PostUnmarshalTask(cfg *config.Config,
 		seelog.Errorf("Task [%s]: could not provision network resource: %v", task.Arn, err)
 		return apierrors.NewResourceInitError(task.Arn, err)
 	}
+	if err = task.validateNamespaceModes(); err != nil {
+		return apierrors.NewResourceInitError(task.Arn, err)
+	}
+	if err = task.validateSystemControls(); err != nil {
+		return apierrors.NewResourceInitError(task.Arn, err)
+	}
+	if err = task.applyNetworkModePolicy(cfg); err != nil {
+		seelog.Errorf("Task [%s]: network mode is not allowed: %v", task.Arn, err)
+		return err
+	}
 	// Adds necessary Pause containers for sharing PID or IPC namespaces
 	task.addNamespaceSharingProvisioningDependency(cfg)
 
+	if (cfg.TaskBridgeNetworkEnabled || task.BridgeNetworkName != "") && task.requiresTaskBridgeNetwork() {
+		err = task.initializeTaskBridgeNetwork(cfg, dockerClient, ctx)
+		if err != nil {
+			seelog.Errorf("Task [%s]: could not initialize task bridge network: %v", task.Arn, err)
+			return apierrors.NewResourceInitError(task.Arn, err)
+		}
+	}
+
 	firelensContainer := task.GetFirelensContainer()
 	if firelensContainer != nil {
 		err = task.applyFirelensSetup(cfg, resourceFields, firelensContainer, credentialsManager)
@@ -362,6 +491,69 @@ func (task *Task) PostUnmarshalTask(cfg *config.Config,
 	return nil
 }
 
+// requiresTaskBridgeNetwork returns true if the task isn't an awsvpc task and
+// has containers in bridge network mode
+func (task *Task) requiresTaskBridgeNetwork() bool {
+	if task.IsNetworkModeAWSVPC() {
+		return false
+	}
+	for _, container := range task.Containers {
+		if !container.IsInternal() && usesBridgeNetworkMode(container) {
+			return true
+		}
+	}
+	return false
+}
+
+// usesBridgeNetworkMode returns true if the container is in bridge network
+// mode, which is the default mode of docker
+func usesBridgeNetworkMode(container *apicontainer.Container) bool {
+	networkMode := container.GetNetworkModeFromHostConfig()
+	return networkMode == "" || networkMode == BridgeNetworkMode
+}
+
+// initializeTaskBridgeNetwork adds the docker network resource the containers
+// of the task in bridge network mode are attached to, so that the network is
+// created before them and removed when the task is cleaned up
+func (task *Task) initializeTaskBridgeNetwork(cfg *config.Config, dockerClient dockerapi.DockerClient,
+	ctx context.Context) error {
+	taskID, err := task.GetID()
+	if err != nil {
+		return err
+	}
+	networkResource := taskresourcenetwork.NewNetworkResource(ctx, task.Arn,
+		taskresourcenetwork.NetworkName(taskID, task.BridgeNetworkName), cfg.TaskNetworkCleanupAttempts, dockerClient)
+	task.AddResource(taskresourcenetwork.ResourceName, networkResource)
+	for _, container := range task.Containers {
+		if container.IsInternal() || !usesBridgeNetworkMode(container) {
+			continue
+		}
+		container.BuildResourceDependency(networkResource.GetName(), resourcestatus.ResourceCreated,
+			apicontainerstatus.ContainerCreated)
+	}
+	return nil
+}
+
+// GetTaskBridgeNetworkName returns the name of the docker network the
+// containers of the task in bridge network mode are attached to, if the task
+// has one
+func (task *Task) GetTaskBridgeNetworkName() (string, bool) {
+	task.lock.RLock()
+	defer task.lock.RUnlock()
+
+	res, ok := task.ResourcesMapUnsafe[taskresourcenetwork.ResourceName]
+	if !ok || len(res) == 0 {
+		return "", false
+	}
+	return res[0].GetName(), true
+}
+
+// IsExperimentEnabled returns true if the task opts into the experiment and the
+// experiment is enabled on the instance
+func (task *Task) IsExperimentEnabled(cfg *config.Config, experiment string) bool {
+	return experiments.Enabled(cfg.EnabledExperiments, task.Experiments, experiment)
+}
+
 func (task *Task) applyFirelensSetup(cfg *config.Config, resourceFields *taskresource.ResourceFields,
 	firelensContainer *apicontainer.Container, credentialsManager credentials.Manager) error {
 	err := task.initializeFirelensResource(cfg, resourceFields, firelensContainer, credentialsManager)
@@ -404,6 +596,12 @@ func (task *Task) isGPUEnabled() bool {
 			return true
 		}
 	}
+	// GPUs can also be assigned by the agent to the containers that require them
+	for _, container := range task.Containers {
+		if len(container.GPUIDs) > 0 {
+			return true
+		}
+	}
 	return false
 }
 
@@ -590,6 +788,45 @@ func (task *Task) addSharedVolumes(SharedVolumeMatchFullConfig bool, ctx context
 	return nil
 }
 
+// initializeEFSVolumes adds the efs resources that mount the EFS volumes of the
+// task on the host before the containers using them are created. The file
+// systems are mounted under the data directory of the agent, in a directory
+// per task, and bind mounted from there into the containers
+func (task *Task) initializeEFSVolumes(cfg *config.Config, ctx context.Context) error {
+	for i, vol := range task.Volumes {
+		if vol.Type != EFSVolumeType {
+			continue
+		}
+
+		volumeConfig, ok := vol.Volume.(*taskresourceefs.EFSVolumeConfig)
+		if !ok {
+			return errors.New("task volume: volume configuration does not match the type 'efs'")
+		}
+		taskID, err := task.GetID()
+		if err != nil {
+			return err
+		}
+		var credentialsRelativeURI string
+		if credentialsID := task.GetCredentialsID(); credentialsID != "" {
+			credentialsRelativeURI = (&credentials.IAMRoleCredentials{
+				CredentialsID: credentialsID,
+			}).GenerateCredentialsEndpointRelativeURI()
+		}
+		volumeConfig.HostPath = filepath.Join(cfg.DataDirOnHost, "data", efsMountDirectory, taskID, vol.Name)
+		efsResource, err := taskresourceefs.NewEFSResource(ctx, vol.Name, task.Arn, *volumeConfig,
+			filepath.Join(cfg.DataDir, efsMountDirectory, taskID, vol.Name), credentialsRelativeURI,
+			taskresourceefs.NewMounter())
+		if err != nil {
+			return err
+		}
+
+		task.Volumes[i].Volume = volumeConfig
+		task.AddResource(resourcetype.EFSKey, efsResource)
+		task.updateContainerVolumeDependency(vol.Name)
+	}
+	return nil
+}
+
 // updateContainerVolumeDependency adds the volume resource to container dependency
 func (task *Task) updateContainerVolumeDependency(name string) {
 	// Find all the container that depends on the volume
@@ -722,7 +959,7 @@ func (task *Task) initializeSSMSecretResource(credentialsManager credentials.Man
 // a certain provider type.
 func (task *Task) firelensDependsOnSecretResource(secretProvider string) bool {
 	isLogDriverSecretWithGivenProvider := func(s apicontainer.Secret) bool {
-		return s.Provider == secretProvider && s.Target == apicontainer.SecretTargetLogDriver
+		return s.GetProvider() == secretProvider && s.Target == apicontainer.SecretTargetLogDriver
 	}
 	for _, container := range task.Containers {
 		if container.GetLogDriver() == firelensDriverName && container.HasSecret(isLogDriverSecretWithGivenProvider) {
@@ -739,7 +976,7 @@ func (task *Task) getAllSSMSecretRequirements() map[string][]apicontainer.Secret
 
 	for _, container := range task.Containers {
 		for _, secret := range container.Secrets {
-			if secret.Provider == apicontainer.SecretProviderSSM {
+			if secret.GetProvider() == apicontainer.SecretProviderSSM {
 				if _, ok := reqs[secret.Region]; !ok {
 					reqs[secret.Region] = []apicontainer.Secret{}
 				}
@@ -792,7 +1029,59 @@ func (task *Task) getAllASMSecretRequirements() map[string]apicontainer.Secret {
 
 	for _, container := range task.Containers {
 		for _, secret := range container.Secrets {
-			if secret.Provider == apicontainer.SecretProviderASM {
+			if secret.GetProvider() == apicontainer.SecretProviderASM {
+				secretKey := secret.GetSecretResourceCacheKey()
+				if _, ok := reqs[secretKey]; !ok {
+					reqs[secretKey] = secret
+				}
+			}
+		}
+	}
+	return reqs
+}
+
+// requiresProviderSecret returns true if at least one container in the task
+// needs to retrieve secret from the secret providers configured on the instance
+func (task *Task) requiresProviderSecret() bool {
+	for _, container := range task.Containers {
+		if container.ShouldCreateWithProviderSecret() {
+			return true
+		}
+	}
+	return false
+}
+
+// initializeProviderSecretResource builds the resource dependency map for the providersecret resource
+func (task *Task) initializeProviderSecretResource(resourceFields *taskresource.ResourceFields) {
+	providerSecretResource := providersecret.NewProviderSecretResource(task.Arn,
+		task.getAllProviderSecretRequirements(), resourceFields.SecretProviders)
+	task.AddResource(providersecret.ResourceName, providerSecretResource)
+
+	// for every container that needs provider secret vending as envvar, it needs to wait all secrets got retrieved
+	for _, container := range task.Containers {
+		if container.ShouldCreateWithProviderSecret() {
+			container.BuildResourceDependency(providerSecretResource.GetName(),
+				resourcestatus.ResourceStatus(providersecret.ProviderSecretCreated),
+				apicontainerstatus.ContainerCreated)
+		}
+
+		// Firelens container needs to depends on secret if other containers use secret log options.
+		if container.GetFirelensConfig() != nil && (task.firelensDependsOnSecretResource(apicontainer.SecretProviderVault) ||
+			task.firelensDependsOnSecretResource(apicontainer.SecretProviderFile)) {
+			container.BuildResourceDependency(providerSecretResource.GetName(),
+				resourcestatus.ResourceStatus(providersecret.ProviderSecretCreated),
+				apicontainerstatus.ContainerCreated)
+		}
+	}
+}
+
+// getAllProviderSecretRequirements stores the provider secrets in a task in a map
+func (task *Task) getAllProviderSecretRequirements() map[string]apicontainer.Secret {
+	reqs := make(map[string]apicontainer.Secret)
+
+	for _, container := range task.Containers {
+		for _, secret := range container.Secrets {
+			if secret.IsProviderSecret() {
 				secretKey := secret.GetSecretResourceCacheKey()
 				if _, ok := reqs[secretKey]; !ok {
 					reqs[secretKey] = secret
@@ -1025,17 +1314,21 @@ func (task *Task) BuildCNIConfig(includeIPAMConfig bool, cniConfig *ecscni.Confi
 	var ifName string
 	var err error
 
-	// Build a CNI network configuration for each ENI.
-	for _, eni := range task.ENIs {
+	// Build a CNI network configuration for each ENI. The ENIs are named in the
+	// task's namespace in the order they were sent in, starting with the
+	// primary ENI as eth0.
+	for index, eni := range task.ENIs {
+		// The bridge IPAM allocation is identified by the primary ENI
+		if index == 0 {
+			cniConfig.ID = eni.MacAddress
+		}
 		switch eni.InterfaceAssociationProtocol {
 		// If the association protocol is set to "default" or unset (to preserve backwards
 		// compatibility), consider it a "standard" ENI attachment.
 		case "", apieni.DefaultInterfaceAssociationProtocol:
-			cniConfig.ID = eni.MacAddress
-			ifName, netconf, err = ecscni.NewENINetworkConfig(eni, cniConfig)
+			ifName, netconf, err = ecscni.NewENINetworkConfig(eni, index, cniConfig)
 		case apieni.VLANInterfaceAssociationProtocol:
-			cniConfig.ID = eni.MacAddress
-			ifName, netconf, err = ecscni.NewBranchENINetworkConfig(eni, cniConfig)
+			ifName, netconf, err = ecscni.NewBranchENINetworkConfig(eni, index, cniConfig)
 		default:
 			err = errors.Errorf("task config: unknown interface association type: %s",
 				eni.InterfaceAssociationProtocol)
@@ -1075,9 +1368,89 @@ func (task *Task) BuildCNIConfig(includeIPAMConfig bool, cniConfig *ecscni.Confi
 		})
 	}
 
+	// Build a CNI network configuration to shape the traffic of the task if
+	// it has a network bandwidth.
+	if task.NetworkBandwidth != nil {
+		ifName, netconf, err = ecscni.NewBandwidthNetworkConfig(task.NetworkBandwidth, cniConfig)
+		if err != nil {
+			return nil, err
+		}
+		cniConfig.NetworkConfigs = append(cniConfig.NetworkConfigs, &ecscni.NetworkConfig{
+			IfName:           ifName,
+			CNINetworkConfig: netconf,
+		})
+	}
+
 	return cniConfig, nil
 }
 
+// validateNetworkBandwidth returns an error if the task has a network
+// bandwidth the bandwidth CNI plugin can't shape
+func (task *Task) validateNetworkBandwidth() error {
+	if task.NetworkBandwidth == nil {
+		return nil
+	}
+	if !task.IsNetworkModeAWSVPC() {
+		return errors.New("network bandwidth is only supported for tasks with the awsvpc network mode")
+	}
+	return task.NetworkBandwidth.Validate()
+}
+
+// validateENIs returns an error if the task has several ENIs and the eni
+// plugins can't set up its additional ENIs without routing the default traffic
+// of the task through them
+func (task *Task) validateENIs(cfg *config.Config) error {
+	if len(task.ENIs) > 1 && !cfg.CNIAdditionalENIsSupported {
+		return errors.Errorf("the eni plugins don't support the %s capability required by tasks with %d ENIs",
+			ecscni.CapabilityAdditionalENIs, len(task.ENIs))
+	}
+	return nil
+}
+
+// validateDNSConfig returns an error if the task has DNS settings that can't
+// be written to the resolv.conf of its network namespace
+func (task *Task) validateDNSConfig() error {
+	if task.DNSConfig == nil {
+		return nil
+	}
+	if !task.IsNetworkModeAWSVPC() {
+		return errors.New("dns config is only supported for tasks with the awsvpc network mode")
+	}
+	return errors.Wrap(task.DNSConfig.Validate(), "invalid dns config")
+}
+
+// validateBridgeNetworkName returns an error if the name of the docker network
+// requested by the task isn't a valid network name
+func (task *Task) validateBridgeNetworkName() error {
+	if task.BridgeNetworkName == "" {
+		return nil
+	}
+	if task.IsNetworkModeAWSVPC() {
+		return errors.New("bridge network name is not supported for tasks with the awsvpc network mode")
+	}
+	if !taskresourcenetwork.IsValidNetworkName(task.BridgeNetworkName) {
+		return errors.Errorf("invalid bridge network name: %q", task.BridgeNetworkName)
+	}
+	return nil
+}
+
+// validateStdoutHealthChecks returns an error if a container with a stdout
+// health check uses a logging driver docker can't read its stdout back from,
+// as its health would never be known
+func (task *Task) validateStdoutHealthChecks() error {
+	for _, container := range task.Containers {
+		if container.HealthCheckType != apicontainer.StdoutHealthCheckType {
+			continue
+		}
+		logDriver := container.GetLogDriver()
+		if !dockerclient.IsReadableLoggingDriver(dockerclient.LoggingDriver(logDriver)) {
+			return errors.Errorf("container %s has a stdout health check but uses the %s logging driver, "+
+				"which docker can't read the output of", container.Name, logDriver)
+		}
+	}
+	return nil
+}
+
 // IsNetworkModeAWSVPC checks if the task is configured to use the AWSVPC task networking feature.
 func (task *Task) IsNetworkModeAWSVPC() bool {
 	return len(task.ENIs) > 0
@@ -1143,6 +1516,66 @@ func (task *Task) addNetworkResourceProvisioningDependency(cfg *config.Config) e
 	return nil
 }
 
+// validateNamespaceModes returns an error if the PID or IPC mode of the task
+// isn't supported, rather than leaving its containers in their own namespaces
+func (task *Task) validateNamespaceModes() error {
+	switch task.getPIDMode() {
+	case "", pidModeHost, pidModeTask:
+	default:
+		return errors.Errorf("invalid PID mode: %s", task.getPIDMode())
+	}
+	switch task.getIPCMode() {
+	case "", ipcModeHost, ipcModeTask, ipcModeNone:
+	default:
+		return errors.Errorf("invalid IPC mode: %s", task.getIPCMode())
+	}
+	return nil
+}
+
+// applyNetworkModePolicy sets the default network mode of the agent on the
+// containers that don't set one, and returns an error if the task or one of
+// its containers is in a network mode the agent doesn't allow
+func (task *Task) applyNetworkModePolicy(cfg *config.Config) error {
+	if task.IsNetworkModeAWSVPC() {
+		// The network mode of the containers is overridden with the one of
+		// the pause container
+		if networkModeDisallowed(cfg, AWSVPCNetworkMode) {
+			return &apierrors.NetworkModeNotAllowedError{NetworkMode: AWSVPCNetworkMode}
+		}
+		return nil
+	}
+	for _, container := range task.Containers {
+		if container.IsInternal() {
+			continue
+		}
+		if cfg.DefaultNetworkMode != "" {
+			if err := container.SetDefaultNetworkMode(cfg.DefaultNetworkMode); err != nil {
+				return apierrors.NewResourceInitError(task.Arn, err)
+			}
+		}
+		networkMode := container.GetNetworkModeFromHostConfig()
+		if networkMode == "" || networkMode == "default" {
+			networkMode = BridgeNetworkMode
+		}
+		if networkModeDisallowed(cfg, networkMode) {
+			return &apierrors.NetworkModeNotAllowedError{
+				NetworkMode:   networkMode,
+				ContainerName: container.Name,
+			}
+		}
+	}
+	return nil
+}
+
+func networkModeDisallowed(cfg *config.Config, networkMode string) bool {
+	for _, disallowed := range cfg.DisallowedNetworkModes {
+		if disallowed == networkMode {
+			return true
+		}
+	}
+	return false
+}
+
 func (task *Task) addNamespaceSharingProvisioningDependency(cfg *config.Config) {
 	// Pause container does not need to be created if no namespace sharing will be done at task level
 	if task.getIPCMode() != ipcModeTask && task.getPIDMode() != pidModeTask {
@@ -1288,17 +1721,15 @@ func (task *Task) dockerConfig(container *apicontainer.Container, apiVersion doc
 		dockerEnv = append(dockerEnv, envKey+"="+envVal)
 	}
 
-	var entryPoint []string
-	if container.EntryPoint != nil {
-		entryPoint = *container.EntryPoint
-	}
-
 	containerConfig := &dockercontainer.Config{
 		Image:        container.Image,
-		Cmd:          container.Command,
-		Entrypoint:   entryPoint,
+		Cmd:          container.GetCommand(),
+		Entrypoint:   container.GetEntryPoint(),
 		ExposedPorts: task.dockerExposedPorts(container),
 		Env:          dockerEnv,
+		Tty:          container.PseudoTerminal,
+		OpenStdin:    container.Interactive,
+		StdinOnce:    false,
 	}
 
 	if container.DockerConfig.Config != nil {
@@ -1382,7 +1813,28 @@ func (task *Task) dockerHostConfig(container *apicontainer.Container, dockerCont
 		return nil, &apierrors.HostConfigError{Msg: err.Error()}
 	}
 
+	tmpfs, err := task.dockerTmpfs(container)
+	if err != nil {
+		return nil, &apierrors.HostConfigError{Msg: err.Error()}
+	}
+
+	shmSize, err := task.dockerShmSize(container)
+	if err != nil {
+		return nil, &apierrors.HostConfigError{Msg: err.Error()}
+	}
+
 	resources := task.getDockerResources(container)
+	resources.Devices, err = task.dockerDevices(container)
+	if err != nil {
+		return nil, &apierrors.HostConfigError{Msg: err.Error()}
+	}
+	resources.Ulimits, err = task.dockerUlimits(container)
+	if err != nil {
+		return nil, &apierrors.HostConfigError{Msg: err.Error()}
+	}
+	if err = task.dockerSwap(container, &resources); err != nil {
+		return nil, &apierrors.HostConfigError{Msg: err.Error()}
+	}
 
 	// Populate hostConfig
 	hostConfig := &dockercontainer.HostConfig{
@@ -1390,9 +1842,16 @@ func (task *Task) dockerHostConfig(container *apicontainer.Container, dockerCont
 		Binds:        binds,
 		PortBindings: dockerPortMap,
 		VolumesFrom:  volumesFrom,
+		Tmpfs:        tmpfs,
+		ShmSize:      shmSize,
 		Resources:    resources,
 	}
 
+	if container.InitProcessEnabled {
+		init := true
+		hostConfig.Init = &init
+	}
+
 	if task.isGPUEnabled() && task.shouldRequireNvidiaRuntime(container) {
 		if task.NvidiaRuntime == "" {
 			return nil, &apierrors.HostConfigError{Msg: "Runtime is not set for GPU containers"}
@@ -1424,6 +1883,12 @@ func (task *Task) dockerHostConfig(container *apicontainer.Container, dockerCont
 				hostConfig.ExtraHosts = append(hostConfig.ExtraHosts, hosts...)
 			}
 
+			// The pause container owns the network namespace of the task, so it
+			// sets the network system controls of the containers
+			if err := task.dockerSysctls(container, hostConfig); err != nil {
+				return nil, &apierrors.HostConfigError{Msg: err.Error()}
+			}
+
 			// Override the DNS settings for the pause container if ENI has custom
 			// DNS settings
 			return task.overrideDNS(hostConfig), nil
@@ -1440,9 +1905,308 @@ func (task *Task) dockerHostConfig(container *apicontainer.Container, dockerCont
 		hostConfig.IpcMode = dockercontainer.IpcMode(ipcMode)
 	}
 
+	// The namespaces the system controls apply to are only known once the
+	// network and IPC modes are
+	if err := task.dockerSysctls(container, hostConfig); err != nil {
+		return nil, &apierrors.HostConfigError{Msg: err.Error()}
+	}
+
 	return hostConfig, nil
 }
 
+// dockerSysctls adds the system controls of the container to the sysctls of
+// its host config. Only the namespaced kernel parameters can be set, and not
+// in the namespaces the container shares with the host. Docker doesn't set
+// kernel parameters in a namespace a container joins, so the ones of the
+// namespaces owned by a pause container, the network namespace of awsvpc
+// tasks and the IPC namespace of tasks in the task IPC mode, are set on the
+// pause container instead
+func (task *Task) dockerSysctls(container *apicontainer.Container, hostConfig *dockercontainer.HostConfig) error {
+	var sysctls map[string]string
+	var err error
+	switch {
+	case container.Type == apicontainer.ContainerCNIPause:
+		sysctls, err = task.sharedNamespaceSysctls(isNetSysctl)
+	case container.Type == apicontainer.ContainerNamespacePause && task.getIPCMode() == ipcModeTask:
+		sysctls, err = task.sharedNamespaceSysctls(isIPCSysctl)
+	default:
+		sysctls, err = task.containerSysctls(container, hostConfig)
+	}
+	if err != nil {
+		return err
+	}
+	if len(sysctls) == 0 {
+		return nil
+	}
+	if hostConfig.Sysctls == nil {
+		hostConfig.Sysctls = make(map[string]string, len(sysctls))
+	}
+	for namespace, value := range sysctls {
+		hostConfig.Sysctls[namespace] = value
+	}
+	return nil
+}
+
+// containerSysctls returns the system controls of the container, but for the
+// ones of the namespaces it joins
+func (task *Task) containerSysctls(container *apicontainer.Container,
+	hostConfig *dockercontainer.HostConfig) (map[string]string, error) {
+	sysctls := make(map[string]string, len(container.SystemControls))
+	seen := make(map[string]struct{}, len(container.SystemControls))
+	for _, systemControl := range container.SystemControls {
+		namespace := systemControl.Namespace
+		if _, ok := seen[namespace]; ok {
+			return nil, errors.Errorf("duplicate system control %s of container %s", namespace, container.Name)
+		}
+		seen[namespace] = struct{}{}
+		switch {
+		case isNetSysctl(namespace):
+			if hostConfig.NetworkMode.IsHost() {
+				return nil, errors.Errorf("system control %s of container %s can't be set in host network mode",
+					namespace, container.Name)
+			}
+			if task.IsNetworkModeAWSVPC() {
+				continue
+			}
+		case isIPCSysctl(namespace):
+			if hostConfig.IpcMode.IsHost() {
+				return nil, errors.Errorf("system control %s of container %s can't be set in host IPC mode",
+					namespace, container.Name)
+			}
+			if task.getIPCMode() == ipcModeTask {
+				continue
+			}
+		default:
+			return nil, errors.Errorf("system control %s of container %s isn't namespaced", namespace, container.Name)
+		}
+		sysctls[namespace] = systemControl.Value
+	}
+	return sysctls, nil
+}
+
+// sharedNamespaceSysctls returns the system controls the containers of the
+// task set in a namespace they all join. The containers can't set the same
+// kernel parameter to different values, as the namespace has a single value
+func (task *Task) sharedNamespaceSysctls(inNamespace func(string) bool) (map[string]string, error) {
+	sysctls := make(map[string]string)
+	for _, container := range task.Containers {
+		if container.IsInternal() {
+			continue
+		}
+		for _, systemControl := range container.SystemControls {
+			namespace := systemControl.Namespace
+			if !inNamespace(namespace) {
+				continue
+			}
+			if value, ok := sysctls[namespace]; ok && value != systemControl.Value {
+				return nil, errors.Errorf("system control %s is set to different values by the containers of the task, "+
+					"which share its namespace", namespace)
+			}
+			sysctls[namespace] = systemControl.Value
+		}
+	}
+	return sysctls, nil
+}
+
+// validateSystemControls returns an error if the containers of the task set a
+// kernel parameter of a namespace they share to different values
+func (task *Task) validateSystemControls() error {
+	if task.IsNetworkModeAWSVPC() {
+		if _, err := task.sharedNamespaceSysctls(isNetSysctl); err != nil {
+			return err
+		}
+	}
+	if task.getIPCMode() == ipcModeTask {
+		if _, err := task.sharedNamespaceSysctls(isIPCSysctl); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isNetSysctl(namespace string) bool {
+	return strings.HasPrefix(namespace, "net.")
+}
+
+// ipcSysctls are the kernel parameters of the IPC namespace docker allows to
+// set, besides the ones of the POSIX message queues
+var ipcSysctls = map[string]struct{}{
+	"kernel.msgmax":          {},
+	"kernel.msgmnb":          {},
+	"kernel.msgmni":          {},
+	"kernel.sem":             {},
+	"kernel.shmall":          {},
+	"kernel.shmmax":          {},
+	"kernel.shmmni":          {},
+	"kernel.shm_rmid_forced": {},
+}
+
+func isIPCSysctl(namespace string) bool {
+	_, ok := ipcSysctls[namespace]
+	return ok || strings.HasPrefix(namespace, "fs.mqueue.")
+}
+
+// dockerTmpfs returns the tmpfs mounts of the container, with the options of
+// each mount starting with its size
+func (task *Task) dockerTmpfs(container *apicontainer.Container) (map[string]string, error) {
+	if len(container.Tmpfs) == 0 {
+		return nil, nil
+	}
+	tmpfs := make(map[string]string, len(container.Tmpfs))
+	for _, mount := range container.Tmpfs {
+		if mount.ContainerPath == "" || mount.Size <= 0 {
+			return nil, errors.Errorf("invalid tmpfs mount of container %s: path [%s], size %d MiB",
+				container.Name, mount.ContainerPath, mount.Size)
+		}
+		options := append([]string{fmt.Sprintf("size=%dm", mount.Size)}, mount.MountOptions...)
+		tmpfs[mount.ContainerPath] = strings.Join(options, ",")
+	}
+	return tmpfs, nil
+}
+
+// dockerShmSize returns the size of /dev/shm of the container in bytes, or 0
+// for docker's default size
+func (task *Task) dockerShmSize(container *apicontainer.Container) (int64, error) {
+	if container.SharedMemorySize == nil {
+		return 0, nil
+	}
+	if *container.SharedMemorySize <= 0 {
+		return 0, errors.Errorf("invalid shared memory size of container %s: %d MiB",
+			container.Name, *container.SharedMemorySize)
+	}
+	return *container.SharedMemorySize * 1024 * 1024, nil
+}
+
+// devicePermissions maps the permissions of device mappings to docker's cgroup
+// permissions
+var devicePermissions = map[string]string{
+	"read":  "r",
+	"write": "w",
+	"mknod": "m",
+}
+
+// dockerDevices returns the devices of the host exposed to the container
+func (task *Task) dockerDevices(container *apicontainer.Container) ([]dockercontainer.DeviceMapping, error) {
+	if len(container.Devices) == 0 {
+		return nil, nil
+	}
+	devices := make([]dockercontainer.DeviceMapping, len(container.Devices))
+	for i, device := range container.Devices {
+		if device.HostPath == "" {
+			return nil, errors.Errorf("invalid device of container %s: host path not set", container.Name)
+		}
+		containerPath := device.ContainerPath
+		if containerPath == "" {
+			containerPath = device.HostPath
+		}
+		permissions := "rwm"
+		if len(device.Permissions) > 0 {
+			permissions = ""
+			for _, permission := range device.Permissions {
+				cgroupPermission, ok := devicePermissions[permission]
+				if !ok {
+					return nil, errors.Errorf("invalid permission %s of device %s of container %s",
+						permission, device.HostPath, container.Name)
+				}
+				permissions += cgroupPermission
+			}
+		}
+		devices[i] = dockercontainer.DeviceMapping{
+			PathOnHost:        device.HostPath,
+			PathInContainer:   containerPath,
+			CgroupPermissions: permissions,
+		}
+	}
+	return devices, nil
+}
+
+// maxSwappiness is the maximum swappiness of containers
+const maxSwappiness = 100
+
+// dockerSwap sets the swap of the container in its resources. Docker's
+// MemorySwap is the limit of the memory and swap of the container combined
+func (task *Task) dockerSwap(container *apicontainer.Container, resources *dockercontainer.Resources) error {
+	if container.MaxSwap != nil {
+		if *container.MaxSwap < 0 {
+			return errors.Errorf("invalid max swap of container %s: %d MiB", container.Name, *container.MaxSwap)
+		}
+		if resources.Memory == 0 {
+			return errors.Errorf("max swap of container %s requires its memory limit to be set", container.Name)
+		}
+		resources.MemorySwap = resources.Memory + *container.MaxSwap*1024*1024
+	}
+	if container.Swappiness != nil {
+		if *container.Swappiness < 0 || *container.Swappiness > maxSwappiness {
+			return errors.Errorf("invalid swappiness of container %s: %d", container.Name, *container.Swappiness)
+		}
+		swappiness := *container.Swappiness
+		resources.MemorySwappiness = &swappiness
+	}
+	return nil
+}
+
+// unlimitedUlimit is the value of a ulimit without limit, e.g. memlock=-1:-1
+const unlimitedUlimit = -1
+
+// ulimitNames are the names of the ulimits docker supports
+var ulimitNames = map[string]struct{}{
+	"core":       {},
+	"cpu":        {},
+	"data":       {},
+	"fsize":      {},
+	"locks":      {},
+	"memlock":    {},
+	"msgqueue":   {},
+	"nice":       {},
+	"nofile":     {},
+	"nproc":      {},
+	"rss":        {},
+	"rtprio":     {},
+	"rttime":     {},
+	"sigpending": {},
+	"stack":      {},
+}
+
+// dockerUlimits returns the ulimits of the container
+func (task *Task) dockerUlimits(container *apicontainer.Container) ([]*units.Ulimit, error) {
+	if len(container.Ulimits) == 0 {
+		return nil, nil
+	}
+	ulimits := make([]*units.Ulimit, len(container.Ulimits))
+	seen := make(map[string]struct{}, len(container.Ulimits))
+	for i, ulimit := range container.Ulimits {
+		if _, ok := ulimitNames[ulimit.Name]; !ok {
+			return nil, errors.Errorf("invalid ulimit %s of container %s", ulimit.Name, container.Name)
+		}
+		if _, ok := seen[ulimit.Name]; ok {
+			return nil, errors.Errorf("ulimit %s of container %s is set more than once", ulimit.Name, container.Name)
+		}
+		seen[ulimit.Name] = struct{}{}
+		if !validUlimitLimits(ulimit.SoftLimit, ulimit.HardLimit) {
+			return nil, errors.Errorf("invalid limits of ulimit %s of container %s: soft %d, hard %d",
+				ulimit.Name, container.Name, ulimit.SoftLimit, ulimit.HardLimit)
+		}
+		ulimits[i] = &units.Ulimit{
+			Name: ulimit.Name,
+			Soft: ulimit.SoftLimit,
+			Hard: ulimit.HardLimit,
+		}
+	}
+	return ulimits, nil
+}
+
+// validUlimitLimits returns whether the limits aren't negative or are unlimited, and
+// the soft limit doesn't exceed the hard limit
+func validUlimitLimits(soft, hard int64) bool {
+	if soft < unlimitedUlimit || hard < unlimitedUlimit {
+		return false
+	}
+	if hard == unlimitedUlimit {
+		return true
+	}
+	return soft != unlimitedUlimit && soft <= hard
+}
+
 // Requires an *apicontainer.Container and returns the Resources for the HostConfig struct
 func (task *Task) getDockerResources(container *apicontainer.Container) dockercontainer.Resources {
 	// Convert MB to B and set Memory
@@ -1475,6 +2239,15 @@ func (task *Task) shouldOverrideNetworkMode(container *apicontainer.Container, d
 		return true, networkModeNone
 	}
 
+	// Containers in bridge network mode are attached to the network of the
+	// task instead of the default docker bridge, when the task has one
+	if networkName, ok := task.GetTaskBridgeNetworkName(); ok {
+		if usesBridgeNetworkMode(container) {
+			return true, networkName
+		}
+		return false, ""
+	}
+
 	// For other types of containers, determine if the container map contains
 	// a pause container. Since a pause container is only added to the task
 	// when using non docker daemon supported network modes, its existence
@@ -1509,6 +2282,7 @@ func (task *Task) shouldOverrideNetworkMode(container *apicontainer.Container, d
 // true:
 // 1. Task has an ENI associated with it
 // 2. ENI has custom DNS IPs and search list associated with it
+// The DNS settings of the task, if any, take precedence over those of the ENI.
 // This should only be done for the pause container as other containers inherit
 // /etc/resolv.conf of this container (they share the network namespace)
 func (task *Task) overrideDNS(hostConfig *dockercontainer.HostConfig) *dockercontainer.HostConfig {
@@ -1517,9 +2291,21 @@ func (task *Task) overrideDNS(hostConfig *dockercontainer.HostConfig) *dockercon
 		return hostConfig
 	}
 
-	hostConfig.DNS = eni.DomainNameServers
+	hostConfig.DNS = eni.GetDomainNameServers()
 	hostConfig.DNSSearch = eni.DomainNameSearchList
 
+	if task.DNSConfig != nil {
+		if len(task.DNSConfig.Nameservers) > 0 {
+			hostConfig.DNS = task.DNSConfig.Nameservers
+		}
+		if len(task.DNSConfig.SearchDomains) > 0 {
+			hostConfig.DNSSearch = task.DNSConfig.SearchDomains
+		}
+		if len(task.DNSConfig.Options) > 0 {
+			hostConfig.DNSOptions = task.DNSConfig.Options
+		}
+	}
+
 	return hostConfig
 }
 
@@ -1767,9 +2553,13 @@ func (task *Task) dockerHostBinds(container *apicontainer.Container) ([]string,
 				container.Name, mountPoint.SourceVolume, hv.Source(), mountPoint.ContainerPath)
 		}
 
+		options, err := mountPoint.BindOptions()
+		if err != nil {
+			return []string{}, err
+		}
 		bind := hv.Source() + ":" + mountPoint.ContainerPath
-		if mountPoint.ReadOnly {
-			bind += ":ro"
+		if len(options) > 0 {
+			bind += ":" + strings.Join(options, ",")
 		}
 		binds[i] = bind
 	}
@@ -1991,6 +2781,23 @@ func (task *Task) GetAppMesh() *apiappmesh.AppMesh {
 	return task.AppMesh
 }
 
+// ValidateAppMesh checks that the app mesh config of the task can be applied:
+// the traffic is only redirected in the network namespace of awsvpc tasks, to
+// a proxy container of the task
+func (task *Task) ValidateAppMesh() error {
+	appMesh := task.GetAppMesh()
+	if appMesh == nil {
+		return nil
+	}
+	if !task.IsNetworkModeAWSVPC() {
+		return errors.New("app mesh is only supported for tasks in the awsvpc network mode")
+	}
+	if _, ok := task.ContainerByName(appMesh.ContainerName); !ok {
+		return errors.Errorf("app mesh proxy container %s isn't in the task", appMesh.ContainerName)
+	}
+	return nil
+}
+
 // GetStopSequenceNumber returns the stop sequence number of a task
 func (task *Task) GetStopSequenceNumber() int64 {
 	task.lock.RLock()
@@ -2029,6 +2836,48 @@ func (task *Task) GetPullStartedAt() time.Time {
 	return task.PullStartedAtUnsafe
 }
 
+// PinImageDigest pins the image to the digest, unless it's already pinned, and
+// returns the digest the image is pinned to
+func (task *Task) PinImageDigest(image string, digest string) string {
+	task.lock.Lock()
+	defer task.lock.Unlock()
+
+	if pinnedDigest, ok := task.PinnedImageDigestsUnsafe[image]; ok {
+		return pinnedDigest
+	}
+	if task.PinnedImageDigestsUnsafe == nil {
+		task.PinnedImageDigestsUnsafe = make(map[string]string)
+	}
+	task.PinnedImageDigestsUnsafe[image] = digest
+	return digest
+}
+
+// GetPinnedImageDigest returns the digest the image is pinned to
+func (task *Task) GetPinnedImageDigest(image string) (string, bool) {
+	task.lock.RLock()
+	defer task.lock.RUnlock()
+
+	digest, ok := task.PinnedImageDigestsUnsafe[image]
+	return digest, ok
+}
+
+// RecordContainerRestart records a restart of one of the task's containers at
+// the given time and returns the number of restarts within the window ending
+// at that time, including this one
+func (task *Task) RecordContainerRestart(restartedAt time.Time, window time.Duration) int {
+	task.lock.Lock()
+	defer task.lock.Unlock()
+
+	var restartTimes []time.Time
+	for _, restartTime := range task.RestartTimesUnsafe {
+		if restartedAt.Sub(restartTime) < window {
+			restartTimes = append(restartTimes, restartTime)
+		}
+	}
+	task.RestartTimesUnsafe = append(restartTimes, restartedAt)
+	return len(task.RestartTimesUnsafe)
+}
+
 // SetPullStoppedAt sets the task pullstoppedat timestamp
 func (task *Task) SetPullStoppedAt(timestamp time.Time) {
 	task.lock.Lock()
@@ -2065,6 +2914,15 @@ func (task *Task) GetExecutionStoppedAt() time.Time {
 	return task.ExecutionStoppedAtUnsafe
 }
 
+// GetCleanupWaitDuration returns the time to wait after the task stops before
+// cleaning it up, the given default unless the task overrides it
+func (task *Task) GetCleanupWaitDuration(defaultDuration time.Duration) time.Duration {
+	if task.CleanupWaitDurationSeconds <= 0 {
+		return defaultDuration
+	}
+	return time.Duration(task.CleanupWaitDurationSeconds) * time.Second
+}
+
 // String returns a human readable string representation of this object
 func (task *Task) String() string {
 	task.lock.Lock()
@@ -2098,6 +2956,35 @@ func (task *Task) stringUnsafe() string {
 	return res
 }
 
+// IsStatic returns whether the task is a static task, which the agent runs
+// independently of ECS
+func (task *Task) IsStatic() bool {
+	return task.StaticName != ""
+}
+
+// CPUAndMemory returns the CPU, in CPU units, and the memory, in MiB, ECS
+// accounts for the task: its task level limits when set, the sum of the
+// limits of its containers otherwise. The memory of a container without a
+// hard limit is its memory reservation
+func (task *Task) CPUAndMemory() (int64, int64) {
+	var cpu, memory int64
+	for _, container := range task.Containers {
+		cpu += int64(container.CPU)
+		if container.Memory > 0 {
+			memory += int64(container.Memory)
+		} else {
+			memory += container.GetMemoryReservation()
+		}
+	}
+	if task.CPU > 0 {
+		cpu = int64(task.CPU * cpuUnitsPerVCPU)
+	}
+	if task.Memory > 0 {
+		memory = task.Memory
+	}
+	return cpu, memory
+}
+
 // GetID is used to retrieve the taskID from taskARN
 // Reference: http://docs.aws.amazon.com/general/latest/gr/aws-arns-and-namespaces.html#arn-syntax-ecs
 func (task *Task) GetID() (string, error) {
@@ -2181,6 +3068,33 @@ func (task *Task) SetTerminalReason(reason string) {
 	})
 }
 
+// SetRuntimeSettings replaces the runtime settings of the task, unless the
+// version of the settings is older than the version the task has. It returns
+// false if the settings were ignored
+func (task *Task) SetRuntimeSettings(settings map[string]string, version int64) bool {
+	task.lock.Lock()
+	defer task.lock.Unlock()
+
+	if version < task.RuntimeSettingsVersion {
+		return false
+	}
+	task.RuntimeSettings = settings
+	task.RuntimeSettingsVersion = version
+	return true
+}
+
+// GetRuntimeSettings returns a copy of the runtime settings of the task
+func (task *Task) GetRuntimeSettings() map[string]string {
+	task.lock.RLock()
+	defer task.lock.RUnlock()
+
+	settings := make(map[string]string, len(task.RuntimeSettings))
+	for key, value := range task.RuntimeSettings {
+		settings[key] = value
+	}
+	return settings
+}
+
 // GetTerminalReason retrieves the terminalReason string
 func (task *Task) GetTerminalReason() string {
 	task.lock.RLock()
@@ -2225,33 +3139,62 @@ func (task *Task) getSSMSecretsResource() ([]taskresource.TaskResource, bool) {
 	return res, ok
 }
 
+// secretResourceName returns the name of the task resource that retrieves the
+// value of the secret
+func secretResourceName(secret apicontainer.Secret) string {
+	if secret.IsProviderSecret() {
+		return providersecret.ResourceName
+	}
+	switch secret.GetProvider() {
+	case apicontainer.SecretProviderSSM:
+		return ssmsecret.ResourceName
+	case apicontainer.SecretProviderASM:
+		return asmsecret.ResourceName
+	}
+	return ""
+}
+
+// getSecretResources returns the secret resources of the task, by resource
+// name. The secret values of every provider are resolved through them
+func (task *Task) getSecretResources() map[string]secretprovider.Provider {
+	task.lock.RLock()
+	defer task.lock.RUnlock()
+
+	secretResources := make(map[string]secretprovider.Provider)
+	for _, name := range []string{ssmsecret.ResourceName, asmsecret.ResourceName, providersecret.ResourceName} {
+		res, ok := task.ResourcesMapUnsafe[name]
+		if !ok || len(res) == 0 {
+			continue
+		}
+		if provider, ok := res[0].(secretprovider.Provider); ok {
+			secretResources[name] = provider
+		}
+	}
+	return secretResources
+}
+
 // PopulateSecrets appends secrets to container's env var map and hostconfig section
 func (task *Task) PopulateSecrets(hostConfig *dockercontainer.HostConfig, container *apicontainer.Container) *apierrors.DockerClientConfigError {
-	var ssmRes *ssmsecret.SSMSecretResource
-	var asmRes *asmsecret.ASMSecretResource
+	secretResources := task.getSecretResources()
 
-	if container.ShouldCreateWithSSMSecret() {
-		resource, ok := task.getSSMSecretsResource()
-		if !ok {
-			return &apierrors.DockerClientConfigError{Msg: "task secret data: unable to fetch SSM Secrets resource"}
-		}
-		ssmRes = resource[0].(*ssmsecret.SSMSecretResource)
+	if _, ok := secretResources[ssmsecret.ResourceName]; !ok && container.ShouldCreateWithSSMSecret() {
+		return &apierrors.DockerClientConfigError{Msg: "task secret data: unable to fetch SSM Secrets resource"}
 	}
 
-	if container.ShouldCreateWithASMSecret() {
-		resource, ok := task.getASMSecretsResource()
-		if !ok {
-			return &apierrors.DockerClientConfigError{Msg: "task secret data: unable to fetch ASM Secrets resource"}
-		}
-		asmRes = resource[0].(*asmsecret.ASMSecretResource)
+	if _, ok := secretResources[asmsecret.ResourceName]; !ok && container.ShouldCreateWithASMSecret() {
+		return &apierrors.DockerClientConfigError{Msg: "task secret data: unable to fetch ASM Secrets resource"}
+	}
+
+	if _, ok := secretResources[providersecret.ResourceName]; !ok && container.ShouldCreateWithProviderSecret() {
+		return &apierrors.DockerClientConfigError{Msg: "task secret data: unable to fetch provider Secrets resource"}
 	}
 
-	populateContainerSecrets(hostConfig, container, ssmRes, asmRes)
+	populateContainerSecrets(hostConfig, container, secretResources)
 	return nil
 }
 
 func populateContainerSecrets(hostConfig *dockercontainer.HostConfig, container *apicontainer.Container,
-	ssmRes *ssmsecret.SSMSecretResource, asmRes *asmsecret.ASMSecretResource) {
+	secretResources map[string]secretprovider.Provider) {
 	envVars := make(map[string]string)
 
 	logDriverTokenName := ""
@@ -2260,16 +3203,8 @@ func populateContainerSecrets(hostConfig *dockercontainer.HostConfig, container
 	for _, secret := range container.Secrets {
 		secretVal := ""
 
-		if secret.Provider == apicontainer.SecretProviderSSM {
-			k := secret.GetSecretResourceCacheKey()
-			if secretValue, ok := ssmRes.GetCachedSecretValue(k); ok {
-				secretVal = secretValue
-			}
-		}
-
-		if secret.Provider == apicontainer.SecretProviderASM {
-			k := secret.GetSecretResourceCacheKey()
-			if secretValue, ok := asmRes.GetCachedSecretValue(k); ok {
+		if res, ok := secretResources[secretResourceName(secret)]; ok {
+			if secretValue, err := res.GetSecretValue(secret); err == nil {
 				secretVal = secretValue
 			}
 		}
@@ -2309,25 +3244,14 @@ func populateContainerSecrets(hostConfig *dockercontainer.HostConfig, container
 func (task *Task) PopulateSecretLogOptionsToFirelensContainer(firelensContainer *apicontainer.Container) *apierrors.DockerClientConfigError {
 	firelensENVs := make(map[string]string)
 
-	var ssmRes *ssmsecret.SSMSecretResource
-	var asmRes *asmsecret.ASMSecretResource
-
-	resource, ok := task.getSSMSecretsResource()
-	if ok {
-		ssmRes = resource[0].(*ssmsecret.SSMSecretResource)
-	}
-
-	resource, ok = task.getASMSecretsResource()
-	if ok {
-		asmRes = resource[0].(*asmsecret.ASMSecretResource)
-	}
+	secretResources := task.getSecretResources()
 
 	for _, container := range task.Containers {
 		if container.GetLogDriver() != firelensDriverName {
 			continue
 		}
 
-		logDriverSecretData, err := collectLogDriverSecretData(container.Secrets, ssmRes, asmRes)
+		logDriverSecretData, err := collectLogDriverSecretData(container.Secrets, secretResources)
 		if err != nil {
 			return &apierrors.DockerClientConfigError{
 				Msg: fmt.Sprintf("unable to generate config to create firelens container: %v", err),
@@ -2351,8 +3275,8 @@ func (task *Task) PopulateSecretLogOptionsToFirelensContainer(firelensContainer
 }
 
 // collectLogDriverSecretData collects all the secret values for log driver secrets.
-func collectLogDriverSecretData(secrets []apicontainer.Secret, ssmRes *ssmsecret.SSMSecretResource,
-	asmRes *asmsecret.ASMSecretResource) (map[string]string, error) {
+func collectLogDriverSecretData(secrets []apicontainer.Secret,
+	secretResources map[string]secretprovider.Provider) (map[string]string, error) {
 	secretData := make(map[string]string)
 	for _, secret := range secrets {
 		if secret.Target != apicontainer.SecretTargetLogDriver {
@@ -2360,21 +3284,13 @@ func collectLogDriverSecretData(secrets []apicontainer.Secret, ssmRes *ssmsecret
 		}
 
 		secretVal := ""
-		cacheKey := secret.GetSecretResourceCacheKey()
-		if secret.Provider == apicontainer.SecretProviderSSM {
-			if ssmRes == nil {
-				return nil, errors.Errorf("missing secret value for secret %s", secret.Name)
-			}
-
-			if secretValue, ok := ssmRes.GetCachedSecretValue(cacheKey); ok {
-				secretVal = secretValue
-			}
-		} else if secret.Provider == apicontainer.SecretProviderASM {
-			if asmRes == nil {
+		if resourceName := secretResourceName(secret); resourceName != "" {
+			res, ok := secretResources[resourceName]
+			if !ok {
 				return nil, errors.Errorf("missing secret value for secret %s", secret.Name)
 			}
 
-			if secretValue, ok := asmRes.GetCachedSecretValue(cacheKey); ok {
+			if secretValue, err := res.GetSecretValue(secret); err == nil {
 				secretVal = secretValue
 			}
 		}
@@ -60,8 +60,13 @@ func (task *Task) initializeCgroupResourceSpec(cgroupPath string, cGroupCPUPerio
 	if err != nil {
 		return errors.Wrapf(err, "cgroup resource: unable to build resource spec for task")
 	}
+	cpuBurst, err := task.BuildLinuxCPUBurst(cGroupCPUPeriod)
+	if err != nil {
+		return errors.Wrapf(err, "cgroup resource: unable to build CPU burst for task")
+	}
 	cgroupResource := cgroup.NewCgroupResource(task.Arn, resourceFields.Control,
 		resourceFields.IOUtil, cgroupRoot, cgroupPath, resSpec)
+	cgroupResource.SetCPUBurst(cpuBurst)
 	task.AddResource(resourcetype.CgroupKey, cgroupResource)
 	for _, container := range task.Containers {
 		container.BuildResourceDependency(cgroupResource.GetName(),
@@ -138,6 +143,25 @@ func (task *Task) buildExplicitLinuxCPUSpec(cGroupCPUPeriod time.Duration) (spec
 	}, nil
 }
 
+// BuildLinuxCPUBurst returns the CPU burst of the task cgroup in microseconds,
+// that is the CPU time the task may use above its CPU quota in a period. The
+// runtime spec of the cgroup doesn't carry the CPU burst, so the cgroup
+// resource sets it once the cgroup is created
+func (task *Task) BuildLinuxCPUBurst(cGroupCPUPeriod time.Duration) (uint64, error) {
+	if task.CPUBurst == 0 {
+		return 0, nil
+	}
+	if task.CPU <= 0 {
+		return 0, errors.New("task CPU burst builder: CPU burst requires task CPU limits")
+	}
+	if task.CPUBurst < 0 || task.CPUBurst > task.CPU {
+		return 0, errors.Errorf("task CPU burst builder: unsupported CPU burst, requested=%f, max-supported=%f",
+			task.CPUBurst, task.CPU)
+	}
+	taskCPUPeriod := uint64(cGroupCPUPeriod / time.Microsecond)
+	return uint64(task.CPUBurst * float64(taskCPUPeriod)), nil
+}
+
 // buildImplicitLinuxCPUSpec builds the implicit task CPU spec when
 // task CPU and memory limit feature is enabled
 func (task *Task) buildImplicitLinuxCPUSpec() specs.LinuxCPU {
@@ -26,8 +26,10 @@ import (
 	apieni "github.com/aws/amazon-ecs-agent/agent/api/eni"
 	"github.com/aws/amazon-ecs-agent/agent/config"
 	"github.com/aws/amazon-ecs-agent/agent/dockerclient"
+	"github.com/aws/amazon-ecs-agent/agent/secretprovider"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource/asmsecret"
+	"github.com/aws/amazon-ecs-agent/agent/taskresource/cgroup"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource/cgroup/control/mock_control"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource/firelens"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource/ssmsecret"
@@ -481,6 +483,62 @@ func TestDockerHostConfigRawConfigMerging(t *testing.T) {
 	assertSetStructFieldsEqual(t, expected, *hostConfig)
 }
 
+// TestBuildLinuxCPUBurst validates the CPU burst builder
+func TestBuildLinuxCPUBurst(t *testing.T) {
+	testCases := []struct {
+		name          string
+		cpu           float64
+		cpuBurst      float64
+		expectedBurst uint64
+		expectedError bool
+	}{
+		{
+			name: "no burst",
+			cpu:  1,
+		},
+		{
+			name:          "burst",
+			cpu:           1,
+			cpuBurst:      0.5,
+			expectedBurst: uint64(defaultCPUPeriod/time.Microsecond) / 2,
+		},
+		{
+			name:          "burst without task CPU limits",
+			cpuBurst:      0.5,
+			expectedError: true,
+		},
+		{
+			name:          "burst greater than task CPU",
+			cpu:           1,
+			cpuBurst:      2,
+			expectedError: true,
+		},
+		{
+			name:          "negative burst",
+			cpu:           1,
+			cpuBurst:      -1,
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			task := &Task{
+				Arn:      validTaskArn,
+				CPU:      tc.cpu,
+				CPUBurst: tc.cpuBurst,
+			}
+			cpuBurst, err := task.BuildLinuxCPUBurst(defaultCPUPeriod)
+			if tc.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedBurst, cpuBurst)
+		})
+	}
+}
+
 func TestInitCgroupResourceSpecHappyPath(t *testing.T) {
 	taskMemoryLimit := int64(taskMemoryLimit)
 	task := &Task{
@@ -529,6 +587,37 @@ func TestInitCgroupResourceSpecInvalidARN(t *testing.T) {
 	assert.Equal(t, 0, len(task.Containers[0].TransitionDependenciesMap))
 }
 
+func TestInitCgroupResourceSpecCPUBurst(t *testing.T) {
+	task := &Task{
+		Arn:      validTaskArn,
+		CPU:      float64(taskVCPULimit),
+		CPUBurst: 1,
+		Containers: []*apicontainer.Container{
+			{
+				Name:                      "c1",
+				TransitionDependenciesMap: make(map[apicontainerstatus.ContainerStatus]apicontainer.TransitionDependencySet),
+			},
+		},
+		MemoryCPULimitsEnabled: true,
+		ResourcesMapUnsafe:     make(map[string][]taskresource.TaskResource),
+	}
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockControl := mock_control.NewMockControl(ctrl)
+	mockIO := mock_ioutilwrapper.NewMockIOUtil(ctrl)
+	assert.NoError(t, task.initializeCgroupResourceSpec("cgroupPath", defaultCPUPeriod, &taskresource.ResourceFields{
+		Control: mockControl,
+		ResourceFieldsCommon: &taskresource.ResourceFieldsCommon{
+			IOUtil: mockIO,
+		},
+	}))
+	resources := task.GetResources()
+	assert.Len(t, resources, 1)
+	cgroupResource, ok := resources[0].(*cgroup.CgroupResource)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(defaultCPUPeriod/time.Microsecond), cgroupResource.GetCPUBurst())
+}
+
 func TestInitCgroupResourceSpecInvalidMem(t *testing.T) {
 	taskMemoryLimit := int64(taskMemoryLimit)
 	task := &Task{
@@ -1128,7 +1217,11 @@ func TestCollectLogDriverSecretData(t *testing.T) {
 		},
 	}
 
-	secretData, err := collectLogDriverSecretData(secrets, ssmRes, asmRes)
+	secretResources := map[string]secretprovider.Provider{
+		ssmsecret.ResourceName: ssmRes,
+		asmsecret.ResourceName: asmRes,
+	}
+	secretData, err := collectLogDriverSecretData(secrets, secretResources)
 	assert.NoError(t, err)
 	assert.Len(t, secretData, 2)
 	assert.Equal(t, "secret-val", secretData["secret-name"])
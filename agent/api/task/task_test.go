@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"reflect"
 	"runtime"
+	"strings"
 	"testing"
 	"time"
 
@@ -28,6 +29,7 @@ import (
 	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
 	apicontainerstatus "github.com/aws/amazon-ecs-agent/agent/api/container/status"
 	apieni "github.com/aws/amazon-ecs-agent/agent/api/eni"
+	apierrors "github.com/aws/amazon-ecs-agent/agent/api/errors"
 	apitaskstatus "github.com/aws/amazon-ecs-agent/agent/api/task/status"
 	"github.com/aws/amazon-ecs-agent/agent/asm"
 	mock_factory "github.com/aws/amazon-ecs-agent/agent/asm/factory/mocks"
@@ -39,6 +41,7 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi"
 	mock_dockerapi "github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi/mocks"
 	"github.com/aws/amazon-ecs-agent/agent/ecscni"
+	"github.com/aws/amazon-ecs-agent/agent/experiments"
 	mock_ssm_factory "github.com/aws/amazon-ecs-agent/agent/ssm/factory/mocks"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource/asmauth"
@@ -49,6 +52,7 @@ import (
 	"github.com/aws/aws-sdk-go/service/secretsmanager"
 
 	"github.com/aws/amazon-ecs-agent/agent/taskresource/asmsecret"
+	"github.com/aws/amazon-ecs-agent/agent/taskresource/providersecret"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource/ssmsecret"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/docker/docker/api/types"
@@ -111,6 +115,32 @@ func TestDockerConfigPortBinding(t *testing.T) {
 	}
 }
 
+func TestDockerConfigInteractive(t *testing.T) {
+	testTask := &Task{
+		Containers: []*apicontainer.Container{
+			{
+				Name:           "c1",
+				PseudoTerminal: true,
+				Interactive:    true,
+			},
+			{
+				Name: "c2",
+			},
+		},
+	}
+
+	config, err := testTask.DockerConfig(testTask.Containers[0], defaultDockerClientAPIVersion)
+	assert.Nil(t, err)
+	assert.True(t, config.Tty)
+	assert.True(t, config.OpenStdin)
+	assert.False(t, config.StdinOnce)
+
+	config, err = testTask.DockerConfig(testTask.Containers[1], defaultDockerClientAPIVersion)
+	assert.Nil(t, err)
+	assert.False(t, config.Tty)
+	assert.False(t, config.OpenStdin)
+}
+
 func TestDockerHostConfigCPUShareZero(t *testing.T) {
 	testTask := &Task{
 		Containers: []*apicontainer.Container{
@@ -230,6 +260,530 @@ func TestDockerHostConfigVolumesFrom(t *testing.T) {
 	}
 }
 
+func TestDockerHostConfigBindOptions(t *testing.T) {
+	testTask := &Task{
+		Containers: []*apicontainer.Container{
+			{
+				Name: "c1",
+				MountPoints: []apicontainer.MountPoint{
+					{
+						SourceVolume:  "csi",
+						ContainerPath: "/csi",
+						Propagation:   "rshared",
+					},
+					{
+						SourceVolume:  "data",
+						ContainerPath: "/data",
+						ReadOnly:      true,
+						Consistency:   "delegated",
+					},
+				},
+			},
+		},
+		Volumes: []TaskVolume{
+			{
+				Name:   "csi",
+				Type:   HostVolumeType,
+				Volume: &taskresourcevolume.FSHostVolume{FSSourcePath: "/var/lib/csi"},
+			},
+			{
+				Name:   "data",
+				Type:   HostVolumeType,
+				Volume: &taskresourcevolume.FSHostVolume{FSSourcePath: "/data"},
+			},
+		},
+	}
+
+	config, err := testTask.DockerHostConfig(testTask.Containers[0], dockerMap(testTask), defaultDockerClientAPIVersion)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"/var/lib/csi:/csi:rshared", "/data:/data:ro,delegated"}, config.Binds)
+}
+
+func TestDockerHostConfigBindOptionsInvalid(t *testing.T) {
+	testTask := &Task{
+		Containers: []*apicontainer.Container{
+			{
+				Name: "c1",
+				MountPoints: []apicontainer.MountPoint{
+					{
+						SourceVolume:  "csi",
+						ContainerPath: "/csi",
+						Propagation:   "invalid",
+					},
+				},
+			},
+		},
+		Volumes: []TaskVolume{
+			{
+				Name:   "csi",
+				Type:   HostVolumeType,
+				Volume: &taskresourcevolume.FSHostVolume{FSSourcePath: "/var/lib/csi"},
+			},
+		},
+	}
+
+	_, err := testTask.DockerHostConfig(testTask.Containers[0], dockerMap(testTask), defaultDockerClientAPIVersion)
+	assert.NotNil(t, err)
+}
+
+func TestDockerHostConfigTmpfsAndShmSize(t *testing.T) {
+	testTask := &Task{
+		Containers: []*apicontainer.Container{
+			{
+				Name: "c1",
+				Tmpfs: []apicontainer.TmpfsMount{
+					{
+						ContainerPath: "/run",
+						Size:          64,
+						MountOptions:  []string{"rw", "noexec"},
+					},
+				},
+				SharedMemorySize: aws.Int64(512),
+			},
+		},
+	}
+
+	config, err := testTask.DockerHostConfig(testTask.Containers[0], dockerMap(testTask), defaultDockerClientAPIVersion)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"/run": "size=64m,rw,noexec"}, config.Tmpfs)
+	assert.Equal(t, int64(512*1024*1024), config.ShmSize)
+}
+
+func TestDockerHostConfigInvalidTmpfsAndShmSize(t *testing.T) {
+	testCases := []struct {
+		name      string
+		container *apicontainer.Container
+	}{
+		{
+			name: "tmpfs without size",
+			container: &apicontainer.Container{
+				Name:  "c1",
+				Tmpfs: []apicontainer.TmpfsMount{{ContainerPath: "/run"}},
+			},
+		},
+		{
+			name: "tmpfs without path",
+			container: &apicontainer.Container{
+				Name:  "c1",
+				Tmpfs: []apicontainer.TmpfsMount{{Size: 64}},
+			},
+		},
+		{
+			name: "negative shared memory size",
+			container: &apicontainer.Container{
+				Name:             "c1",
+				SharedMemorySize: aws.Int64(-1),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testTask := &Task{Containers: []*apicontainer.Container{tc.container}}
+			_, err := testTask.DockerHostConfig(tc.container, dockerMap(testTask), defaultDockerClientAPIVersion)
+			assert.NotNil(t, err)
+		})
+	}
+}
+
+func TestTaskFromACSTmpfsShmSizeAndDevices(t *testing.T) {
+	taskFromACS := ecsacs.Task{
+		Arn:           strptr("myArn"),
+		DesiredStatus: strptr("RUNNING"),
+		Containers: []*ecsacs.Container{
+			{
+				Name: strptr("myName"),
+				Tmpfs: []*ecsacs.Tmpfs{
+					{
+						ContainerPath: strptr("/run"),
+						Size:          aws.Int64(64),
+						MountOptions:  []*string{strptr("noexec")},
+					},
+				},
+				SharedMemorySize: aws.Int64(512),
+				Devices: []*ecsacs.Device{
+					{
+						HostPath:    strptr("/dev/fuse"),
+						Permissions: []*string{strptr("read")},
+					},
+				},
+			},
+		},
+	}
+	seqNum := int64(42)
+	task, err := TaskFromACS(&taskFromACS, &ecsacs.PayloadMessage{SeqNum: &seqNum})
+	assert.NoError(t, err)
+	assert.Equal(t, []apicontainer.TmpfsMount{
+		{
+			ContainerPath: "/run",
+			Size:          64,
+			MountOptions:  []string{"noexec"},
+		},
+	}, task.Containers[0].Tmpfs)
+	assert.Equal(t, aws.Int64(512), task.Containers[0].SharedMemorySize)
+	assert.Equal(t, []apicontainer.DeviceMapping{
+		{
+			HostPath:    "/dev/fuse",
+			Permissions: []string{"read"},
+		},
+	}, task.Containers[0].Devices)
+}
+
+func TestTaskFromACSKernelCapabilities(t *testing.T) {
+	taskFromACS := ecsacs.Task{
+		Arn:           strptr("myArn"),
+		DesiredStatus: strptr("RUNNING"),
+		Containers: []*ecsacs.Container{
+			{
+				Name: strptr("myName"),
+				KernelCapabilities: &ecsacs.KernelCapabilities{
+					Add:  []*string{strptr("SYS_NICE")},
+					Drop: []*string{strptr("NET_RAW")},
+				},
+			},
+		},
+	}
+	seqNum := int64(42)
+	task, err := TaskFromACS(&taskFromACS, &ecsacs.PayloadMessage{SeqNum: &seqNum})
+	assert.NoError(t, err)
+	assert.Equal(t, &apicontainer.KernelCapabilities{
+		Add:  []string{"SYS_NICE"},
+		Drop: []string{"NET_RAW"},
+	}, task.Containers[0].KernelCapabilities)
+}
+
+func TestDockerHostConfigDevices(t *testing.T) {
+	testTask := &Task{
+		Containers: []*apicontainer.Container{
+			{
+				Name: "c1",
+				Devices: []apicontainer.DeviceMapping{
+					{HostPath: "/dev/fuse"},
+					{
+						HostPath:      "/dev/ttyS0",
+						ContainerPath: "/dev/serial",
+						Permissions:   []string{"read", "write"},
+					},
+				},
+			},
+		},
+	}
+
+	config, err := testTask.DockerHostConfig(testTask.Containers[0], dockerMap(testTask), defaultDockerClientAPIVersion)
+	assert.Nil(t, err)
+	assert.Equal(t, []dockercontainer.DeviceMapping{
+		{PathOnHost: "/dev/fuse", PathInContainer: "/dev/fuse", CgroupPermissions: "rwm"},
+		{PathOnHost: "/dev/ttyS0", PathInContainer: "/dev/serial", CgroupPermissions: "rw"},
+	}, config.Devices)
+	assert.False(t, config.Privileged)
+}
+
+func TestDockerHostConfigInitProcess(t *testing.T) {
+	testTask := &Task{
+		Containers: []*apicontainer.Container{
+			{
+				Name:               "c1",
+				InitProcessEnabled: true,
+			},
+			{
+				Name: "c2",
+			},
+		},
+	}
+
+	config, err := testTask.DockerHostConfig(testTask.Containers[0], dockerMap(testTask), defaultDockerClientAPIVersion)
+	assert.Nil(t, err)
+	require.NotNil(t, config.Init)
+	assert.True(t, *config.Init)
+
+	config, err = testTask.DockerHostConfig(testTask.Containers[1], dockerMap(testTask), defaultDockerClientAPIVersion)
+	assert.Nil(t, err)
+	assert.Nil(t, config.Init)
+}
+
+func TestTaskFromACSInitProcessEnabled(t *testing.T) {
+	taskFromACS := ecsacs.Task{
+		Arn:           strptr("myArn"),
+		DesiredStatus: strptr("RUNNING"),
+		Containers: []*ecsacs.Container{
+			{
+				Name:               strptr("myName"),
+				InitProcessEnabled: aws.Bool(true),
+			},
+		},
+	}
+	seqNum := int64(42)
+	task, err := TaskFromACS(&taskFromACS, &ecsacs.PayloadMessage{SeqNum: &seqNum})
+	assert.NoError(t, err)
+	assert.True(t, task.Containers[0].InitProcessEnabled)
+}
+
+func TestDockerHostConfigInvalidDevices(t *testing.T) {
+	testCases := []struct {
+		name   string
+		device apicontainer.DeviceMapping
+	}{
+		{
+			name:   "no host path",
+			device: apicontainer.DeviceMapping{ContainerPath: "/dev/fuse"},
+		},
+		{
+			name:   "invalid permission",
+			device: apicontainer.DeviceMapping{HostPath: "/dev/fuse", Permissions: []string{"execute"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testTask := &Task{
+				Containers: []*apicontainer.Container{
+					{
+						Name:    "c1",
+						Devices: []apicontainer.DeviceMapping{tc.device},
+					},
+				},
+			}
+			_, err := testTask.DockerHostConfig(testTask.Containers[0], dockerMap(testTask), defaultDockerClientAPIVersion)
+			assert.NotNil(t, err)
+		})
+	}
+}
+
+func TestDockerHostConfigUlimits(t *testing.T) {
+	testTask := &Task{
+		Containers: []*apicontainer.Container{
+			{
+				Name: "c1",
+				Ulimits: []apicontainer.Ulimit{
+					{Name: "nofile", SoftLimit: 1024, HardLimit: 4096},
+					{Name: "memlock", SoftLimit: -1, HardLimit: -1},
+				},
+			},
+		},
+	}
+
+	config, err := testTask.DockerHostConfig(testTask.Containers[0], dockerMap(testTask), defaultDockerClientAPIVersion)
+	assert.Nil(t, err)
+	assert.Equal(t, []*units.Ulimit{
+		{Name: "nofile", Soft: 1024, Hard: 4096},
+		{Name: "memlock", Soft: -1, Hard: -1},
+	}, config.Ulimits)
+}
+
+func TestDockerHostConfigInvalidUlimits(t *testing.T) {
+	testCases := []struct {
+		name    string
+		ulimits []apicontainer.Ulimit
+	}{
+		{
+			name:    "unknown name",
+			ulimits: []apicontainer.Ulimit{{Name: "as", SoftLimit: 1, HardLimit: 1}},
+		},
+		{
+			name: "duplicate name",
+			ulimits: []apicontainer.Ulimit{
+				{Name: "nofile", SoftLimit: 1024, HardLimit: 1024},
+				{Name: "nofile", SoftLimit: 2048, HardLimit: 2048},
+			},
+		},
+		{
+			name:    "soft limit above hard limit",
+			ulimits: []apicontainer.Ulimit{{Name: "nofile", SoftLimit: 4096, HardLimit: 1024}},
+		},
+		{
+			name:    "unlimited soft limit with hard limit",
+			ulimits: []apicontainer.Ulimit{{Name: "nofile", SoftLimit: -1, HardLimit: 1024}},
+		},
+		{
+			name:    "negative limit",
+			ulimits: []apicontainer.Ulimit{{Name: "nofile", SoftLimit: -2, HardLimit: -2}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testTask := &Task{
+				Containers: []*apicontainer.Container{
+					{
+						Name:    "c1",
+						Ulimits: tc.ulimits,
+					},
+				},
+			}
+			_, err := testTask.DockerHostConfig(testTask.Containers[0], dockerMap(testTask), defaultDockerClientAPIVersion)
+			assert.NotNil(t, err)
+		})
+	}
+}
+
+func TestDockerHostConfigSysctls(t *testing.T) {
+	testTask := &Task{
+		Containers: []*apicontainer.Container{
+			{
+				Name: "c1",
+				SystemControls: []apicontainer.SystemControl{
+					{Namespace: "net.core.somaxconn", Value: "4096"},
+					{Namespace: "kernel.shmmax", Value: "68719476736"},
+					{Namespace: "fs.mqueue.msg_max", Value: "100"},
+				},
+				DockerConfig: apicontainer.DockerConfig{
+					HostConfig: strptr(`{"Sysctls":{"net.ipv4.tcp_keepalive_time":"60"}}`),
+				},
+			},
+		},
+	}
+
+	config, err := testTask.DockerHostConfig(testTask.Containers[0], dockerMap(testTask), defaultDockerClientAPIVersion)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{
+		"net.ipv4.tcp_keepalive_time": "60",
+		"net.core.somaxconn":          "4096",
+		"kernel.shmmax":               "68719476736",
+		"fs.mqueue.msg_max":           "100",
+	}, config.Sysctls)
+}
+
+func TestDockerHostConfigSharedNamespaceSysctls(t *testing.T) {
+	app := &apicontainer.Container{
+		Name: "app",
+		SystemControls: []apicontainer.SystemControl{
+			{Namespace: "net.core.somaxconn", Value: "4096"},
+			{Namespace: "kernel.shmmax", Value: "68719476736"},
+		},
+	}
+	sidecar := &apicontainer.Container{
+		Name:           "sidecar",
+		SystemControls: []apicontainer.SystemControl{{Namespace: "net.ipv4.tcp_keepalive_time", Value: "60"}},
+	}
+	networkPause := &apicontainer.Container{Name: NetworkPauseContainerName, Type: apicontainer.ContainerCNIPause}
+	namespacePause := &apicontainer.Container{Name: NamespacePauseContainerName, Type: apicontainer.ContainerNamespacePause}
+	testTask := &Task{
+		ENIs:       []*apieni.ENI{{ID: "eniID"}},
+		IPCMode:    ipcModeTask,
+		Containers: []*apicontainer.Container{app, sidecar, networkPause, namespacePause},
+	}
+	require.NoError(t, testTask.validateSystemControls())
+
+	// The containers join the namespaces of the pause containers, which set
+	// the kernel parameters of all the containers
+	config, err := testTask.DockerHostConfig(app, dockerMap(testTask), defaultDockerClientAPIVersion)
+	require.Nil(t, err)
+	assert.Empty(t, config.Sysctls)
+
+	config, err = testTask.DockerHostConfig(networkPause, dockerMap(testTask), defaultDockerClientAPIVersion)
+	require.Nil(t, err)
+	assert.Equal(t, map[string]string{
+		"net.core.somaxconn":          "4096",
+		"net.ipv4.tcp_keepalive_time": "60",
+	}, config.Sysctls)
+
+	config, err = testTask.DockerHostConfig(namespacePause, dockerMap(testTask), defaultDockerClientAPIVersion)
+	require.Nil(t, err)
+	assert.Equal(t, map[string]string{"kernel.shmmax": "68719476736"}, config.Sysctls)
+
+	// The containers can't set a parameter of the namespace they share to
+	// different values
+	sidecar.SystemControls = append(sidecar.SystemControls,
+		apicontainer.SystemControl{Namespace: "net.core.somaxconn", Value: "1024"})
+	assert.Error(t, testTask.validateSystemControls())
+}
+
+func TestDockerHostConfigInvalidSysctls(t *testing.T) {
+	testCases := []struct {
+		name           string
+		systemControls []apicontainer.SystemControl
+		hostConfig     string
+	}{
+		{
+			name:           "not namespaced",
+			systemControls: []apicontainer.SystemControl{{Namespace: "vm.swappiness", Value: "10"}},
+		},
+		{
+			name: "duplicate namespace",
+			systemControls: []apicontainer.SystemControl{
+				{Namespace: "net.core.somaxconn", Value: "1024"},
+				{Namespace: "net.core.somaxconn", Value: "4096"},
+			},
+		},
+		{
+			name:           "net in host network mode",
+			systemControls: []apicontainer.SystemControl{{Namespace: "net.core.somaxconn", Value: "4096"}},
+			hostConfig:     `{"NetworkMode":"host"}`,
+		},
+		{
+			name:           "ipc in host ipc mode",
+			systemControls: []apicontainer.SystemControl{{Namespace: "kernel.sem", Value: "250 32000 100 128"}},
+			hostConfig:     `{"IpcMode":"host"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			container := &apicontainer.Container{
+				Name:           "c1",
+				SystemControls: tc.systemControls,
+			}
+			if tc.hostConfig != "" {
+				container.DockerConfig.HostConfig = strptr(tc.hostConfig)
+			}
+			testTask := &Task{Containers: []*apicontainer.Container{container}}
+			_, err := testTask.DockerHostConfig(testTask.Containers[0], dockerMap(testTask), defaultDockerClientAPIVersion)
+			assert.NotNil(t, err)
+		})
+	}
+}
+
+func TestDockerHostConfigSwap(t *testing.T) {
+	maxSwap := int64(512)
+	swappiness := int64(10)
+	testTask := &Task{
+		Containers: []*apicontainer.Container{
+			{
+				Name:       "c1",
+				Memory:     256,
+				MaxSwap:    &maxSwap,
+				Swappiness: &swappiness,
+			},
+		},
+	}
+
+	config, err := testTask.DockerHostConfig(testTask.Containers[0], dockerMap(testTask), defaultDockerClientAPIVersion)
+	assert.Nil(t, err)
+	assert.Equal(t, int64((256+512)*1024*1024), config.MemorySwap)
+	assert.Equal(t, &swappiness, config.MemorySwappiness)
+}
+
+func TestDockerHostConfigInvalidSwap(t *testing.T) {
+	int64ptr := func(i int64) *int64 {
+		return &i
+	}
+	testCases := []struct {
+		name      string
+		container *apicontainer.Container
+	}{
+		{
+			name:      "no memory limit",
+			container: &apicontainer.Container{Name: "c1", MaxSwap: int64ptr(512)},
+		},
+		{
+			name:      "negative max swap",
+			container: &apicontainer.Container{Name: "c1", Memory: 256, MaxSwap: int64ptr(-1)},
+		},
+		{
+			name:      "swappiness above 100",
+			container: &apicontainer.Container{Name: "c1", Swappiness: int64ptr(101)},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testTask := &Task{Containers: []*apicontainer.Container{tc.container}}
+			_, err := testTask.DockerHostConfig(testTask.Containers[0], dockerMap(testTask), defaultDockerClientAPIVersion)
+			assert.NotNil(t, err)
+		})
+	}
+}
+
 func TestDockerHostConfigRawConfig(t *testing.T) {
 	rawHostConfigInput := dockercontainer.HostConfig{
 		Privileged:     true,
@@ -734,39 +1288,328 @@ func TestPostUnmarshalTaskWithLocalVolumes(t *testing.T) {
 				},
 			},
 		},
-		Volumes: []*ecsacs.Volume{
+		Volumes: []*ecsacs.Volume{
+			{
+				Name: strptr("localvol1"),
+				Type: strptr("host"),
+				Host: &ecsacs.HostVolumeProperties{},
+			},
+			{
+				Name: strptr("localvol2"),
+				Type: strptr("host"),
+				Host: &ecsacs.HostVolumeProperties{},
+			},
+		},
+	}
+	seqNum := int64(42)
+	task, err := TaskFromACS(&taskFromACS, &ecsacs.PayloadMessage{SeqNum: &seqNum})
+	assert.Nil(t, err, "Should be able to handle acs task")
+	assert.Equal(t, 2, len(task.Containers)) // before PostUnmarshalTask
+	cfg := config.Config{}
+	task.PostUnmarshalTask(&cfg, nil, nil, nil, nil)
+
+	assert.Equal(t, 2, len(task.Containers), "Should match the number of containers as before PostUnmarshalTask")
+	taskResources := task.getResourcesUnsafe()
+	assert.Equal(t, 2, len(taskResources), "Should have created 2 volume resources")
+
+	for _, r := range taskResources {
+		vol := r.(*taskresourcevolume.VolumeResource)
+		assert.Equal(t, "task", vol.VolumeConfig.Scope)
+		assert.Equal(t, false, vol.VolumeConfig.Autoprovision)
+		assert.Equal(t, "local", vol.VolumeConfig.Driver)
+		assert.Equal(t, 0, len(vol.VolumeConfig.DriverOpts))
+		assert.Equal(t, 0, len(vol.VolumeConfig.Labels))
+	}
+
+}
+
+func TestPostUnmarshalTaskWithTaskBridgeNetwork(t *testing.T) {
+	taskFromACS := ecsacs.Task{
+		Arn:           strptr("arn:aws:ecs:us-west-2:123456789012:task/task-id"),
+		DesiredStatus: strptr("RUNNING"),
+		Family:        strptr("myFamily"),
+		Version:       strptr("1"),
+		Containers: []*ecsacs.Container{
+			{
+				Name: strptr("bridge"),
+			},
+			{
+				Name: strptr("host"),
+				DockerConfig: &ecsacs.DockerConfig{
+					HostConfig: strptr(`{"NetworkMode":"host"}`),
+				},
+			},
+		},
+	}
+	seqNum := int64(42)
+	task, err := TaskFromACS(&taskFromACS, &ecsacs.PayloadMessage{SeqNum: &seqNum})
+	require.NoError(t, err)
+	cfg := config.Config{TaskBridgeNetworkEnabled: true, TaskNetworkCleanupAttempts: 5}
+	require.NoError(t, task.PostUnmarshalTask(&cfg, nil, nil, nil, nil))
+
+	networkName, ok := task.GetTaskBridgeNetworkName()
+	require.True(t, ok)
+	assert.Equal(t, "ecs-task-task-id", networkName)
+
+	bridgeDeps := task.Containers[0].TransitionDependenciesMap[apicontainerstatus.ContainerCreated].ResourceDependencies
+	require.Len(t, bridgeDeps, 1)
+	assert.Equal(t, networkName, bridgeDeps[0].Name)
+	assert.Empty(t, task.Containers[1].TransitionDependenciesMap[apicontainerstatus.ContainerCreated].ResourceDependencies)
+
+	ok, networkMode := task.shouldOverrideNetworkMode(task.Containers[0], nil)
+	assert.True(t, ok)
+	assert.Equal(t, networkName, networkMode)
+	ok, _ = task.shouldOverrideNetworkMode(task.Containers[1], nil)
+	assert.False(t, ok)
+}
+
+func TestPostUnmarshalTaskWithTaskBridgeNetworkDisabled(t *testing.T) {
+	task := &Task{
+		Arn: "arn:aws:ecs:us-west-2:123456789012:task/task-id",
+		Containers: []*apicontainer.Container{
+			{
+				Name:                      "bridge",
+				TransitionDependenciesMap: make(map[apicontainerstatus.ContainerStatus]apicontainer.TransitionDependencySet),
+			},
+		},
+		ResourcesMapUnsafe: make(map[string][]taskresource.TaskResource),
+	}
+	require.NoError(t, task.PostUnmarshalTask(&config.Config{}, nil, nil, nil, nil))
+
+	_, ok := task.GetTaskBridgeNetworkName()
+	assert.False(t, ok)
+}
+
+func TestPostUnmarshalTaskWithRequestedBridgeNetwork(t *testing.T) {
+	taskFromACS := ecsacs.Task{
+		Arn:               strptr("arn:aws:ecs:us-west-2:123456789012:task/task-id"),
+		DesiredStatus:     strptr("RUNNING"),
+		Family:            strptr("myFamily"),
+		Version:           strptr("1"),
+		BridgeNetworkName: strptr("backend"),
+		Containers: []*ecsacs.Container{
+			{
+				Name: strptr("web"),
+			},
+		},
+	}
+	seqNum := int64(42)
+	task, err := TaskFromACS(&taskFromACS, &ecsacs.PayloadMessage{SeqNum: &seqNum})
+	require.NoError(t, err)
+	assert.Equal(t, "backend", task.BridgeNetworkName)
+	require.NoError(t, task.PostUnmarshalTask(&config.Config{}, nil, nil, nil, nil))
+
+	networkName, ok := task.GetTaskBridgeNetworkName()
+	require.True(t, ok)
+	assert.Equal(t, "ecs-task-backend-task-id", networkName)
+	ok, networkMode := task.shouldOverrideNetworkMode(task.Containers[0], nil)
+	assert.True(t, ok)
+	assert.Equal(t, networkName, networkMode)
+}
+
+func TestPostUnmarshalTaskWithInvalidBridgeNetworkName(t *testing.T) {
+	testCases := []struct {
+		name              string
+		bridgeNetworkName string
+		enis              []*apieni.ENI
+	}{
+		{
+			name:              "invalid characters",
+			bridgeNetworkName: "my network",
+		},
+		{
+			name:              "leading dash",
+			bridgeNetworkName: "-backend",
+		},
+		{
+			name:              "too long",
+			bridgeNetworkName: strings.Repeat("a", 65),
+		},
+		{
+			name:              "awsvpc task",
+			bridgeNetworkName: "backend",
+			enis:              []*apieni.ENI{{ID: "eni-id"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			task := &Task{
+				Arn:               "arn:aws:ecs:us-west-2:123456789012:task/task-id",
+				BridgeNetworkName: tc.bridgeNetworkName,
+				ENIs:              tc.enis,
+				Containers: []*apicontainer.Container{
+					{
+						Name:                      "web",
+						TransitionDependenciesMap: make(map[apicontainerstatus.ContainerStatus]apicontainer.TransitionDependencySet),
+					},
+				},
+				ResourcesMapUnsafe: make(map[string][]taskresource.TaskResource),
+			}
+			assert.Error(t, task.PostUnmarshalTask(&config.Config{}, nil, nil, nil, nil))
+		})
+	}
+}
+
+func TestPostUnmarshalTaskWithInvalidGPUComputeMode(t *testing.T) {
+	task := &Task{
+		Arn: "arn:aws:ecs:us-west-2:123456789012:task/task-id",
+		Containers: []*apicontainer.Container{
+			{
+				Name:                      "trainer",
+				TransitionDependenciesMap: make(map[apicontainerstatus.ContainerStatus]apicontainer.TransitionDependencySet),
+			},
+		},
+		ResourcesMapUnsafe: make(map[string][]taskresource.TaskResource),
+		GPUComputeMode:     "EXCLUSIVE_THREAD",
+	}
+	assert.Error(t, task.PostUnmarshalTask(&config.Config{}, nil, nil, nil, nil))
+}
+
+func TestTaskFromACSEphemeralStorage(t *testing.T) {
+	task, err := TaskFromACS(&ecsacs.Task{
+		Arn:              strptr("myArn"),
+		EphemeralStorage: &ecsacs.EphemeralStorage{SizeInGiB: aws.Int64(30)},
+	}, &ecsacs.PayloadMessage{})
+	require.NoError(t, err)
+	require.NotNil(t, task.EphemeralStorage)
+	assert.Equal(t, int64(30), task.EphemeralStorage.SizeInGiB)
+}
+
+func TestPostUnmarshalTaskWithInvalidEphemeralStorage(t *testing.T) {
+	task := &Task{
+		Arn: "arn:aws:ecs:us-west-2:123456789012:task/task-id",
+		Containers: []*apicontainer.Container{
+			{
+				Name:                      "app",
+				TransitionDependenciesMap: make(map[apicontainerstatus.ContainerStatus]apicontainer.TransitionDependencySet),
+			},
+		},
+		ResourcesMapUnsafe: make(map[string][]taskresource.TaskResource),
+		EphemeralStorage:   &EphemeralStorage{SizeInGiB: 0},
+	}
+	assert.Error(t, task.PostUnmarshalTask(&config.Config{}, nil, nil, nil, nil))
+}
+
+func TestTaskFromACSCleanupWaitDuration(t *testing.T) {
+	task, err := TaskFromACS(&ecsacs.Task{
+		Arn:                        strptr("myArn"),
+		CleanupWaitDurationSeconds: aws.Int64(600),
+	}, &ecsacs.PayloadMessage{})
+	require.NoError(t, err)
+	assert.Equal(t, 10*time.Minute, task.GetCleanupWaitDuration(3*time.Hour))
+	assert.Equal(t, 3*time.Hour, (&Task{}).GetCleanupWaitDuration(3*time.Hour))
+}
+
+func TestTaskFromACSDNSConfig(t *testing.T) {
+	task, err := TaskFromACS(&ecsacs.Task{
+		Arn: strptr("myArn"),
+		DNSConfig: &ecsacs.DNSConfig{
+			Nameservers:   aws.StringSlice([]string{"10.0.0.2"}),
+			SearchDomains: aws.StringSlice([]string{"example.com"}),
+			Options:       aws.StringSlice([]string{"ndots:2"}),
+		},
+	}, &ecsacs.PayloadMessage{})
+	require.NoError(t, err)
+	assert.Equal(t, &DNSConfig{
+		Nameservers:   []string{"10.0.0.2"},
+		SearchDomains: []string{"example.com"},
+		Options:       []string{"ndots:2"},
+	}, task.DNSConfig)
+}
+
+func TestPostUnmarshalTaskWithInvalidCleanupWaitDuration(t *testing.T) {
+	task := &Task{
+		Arn: "arn:aws:ecs:us-west-2:123456789012:task/task-id",
+		Containers: []*apicontainer.Container{
 			{
-				Name: strptr("localvol1"),
-				Type: strptr("host"),
-				Host: &ecsacs.HostVolumeProperties{},
+				Name:                      "app",
+				TransitionDependenciesMap: make(map[apicontainerstatus.ContainerStatus]apicontainer.TransitionDependencySet),
 			},
+		},
+		ResourcesMapUnsafe:         make(map[string][]taskresource.TaskResource),
+		CleanupWaitDurationSeconds: -1,
+	}
+	assert.Error(t, task.PostUnmarshalTask(&config.Config{}, nil, nil, nil, nil))
+}
+
+func TestTaskFromACSOOMScorePolicy(t *testing.T) {
+	task, err := TaskFromACS(&ecsacs.Task{
+		Arn: strptr("myArn"),
+		OomScorePolicy: &ecsacs.OomScorePolicy{
+			EssentialContainerOomScoreAdj: aws.Int64(-500),
+		},
+	}, &ecsacs.PayloadMessage{})
+	require.NoError(t, err)
+	require.NotNil(t, task.OOMScorePolicy)
+	assert.Equal(t, aws.Int(-500), task.OOMScorePolicy.EssentialContainerOOMScoreAdj)
+	assert.Nil(t, task.OOMScorePolicy.NonEssentialContainerOOMScoreAdj)
+}
+
+func TestPostUnmarshalTaskWithInvalidOOMScorePolicy(t *testing.T) {
+	task := &Task{
+		Arn: "arn:aws:ecs:us-west-2:123456789012:task/task-id",
+		Containers: []*apicontainer.Container{
 			{
-				Name: strptr("localvol2"),
-				Type: strptr("host"),
-				Host: &ecsacs.HostVolumeProperties{},
+				Name:                      "app",
+				TransitionDependenciesMap: make(map[apicontainerstatus.ContainerStatus]apicontainer.TransitionDependencySet),
 			},
 		},
+		ResourcesMapUnsafe: make(map[string][]taskresource.TaskResource),
+		OOMScorePolicy:     &OOMScorePolicy{NonEssentialContainerOOMScoreAdj: aws.Int(1001)},
 	}
-	seqNum := int64(42)
-	task, err := TaskFromACS(&taskFromACS, &ecsacs.PayloadMessage{SeqNum: &seqNum})
-	assert.Nil(t, err, "Should be able to handle acs task")
-	assert.Equal(t, 2, len(task.Containers)) // before PostUnmarshalTask
-	cfg := config.Config{}
-	task.PostUnmarshalTask(&cfg, nil, nil, nil, nil)
-
-	assert.Equal(t, 2, len(task.Containers), "Should match the number of containers as before PostUnmarshalTask")
-	taskResources := task.getResourcesUnsafe()
-	assert.Equal(t, 2, len(taskResources), "Should have created 2 volume resources")
+	assert.Error(t, task.PostUnmarshalTask(&config.Config{}, nil, nil, nil, nil))
+}
 
-	for _, r := range taskResources {
-		vol := r.(*taskresourcevolume.VolumeResource)
-		assert.Equal(t, "task", vol.VolumeConfig.Scope)
-		assert.Equal(t, false, vol.VolumeConfig.Autoprovision)
-		assert.Equal(t, "local", vol.VolumeConfig.Driver)
-		assert.Equal(t, 0, len(vol.VolumeConfig.DriverOpts))
-		assert.Equal(t, 0, len(vol.VolumeConfig.Labels))
+func TestPostUnmarshalTaskWithInvalidNetworkBandwidth(t *testing.T) {
+	testCases := []struct {
+		name      string
+		bandwidth *ecscni.NetworkBandwidth
+		eni       *apieni.ENI
+	}{
+		{
+			name:      "task without eni",
+			bandwidth: &ecscni.NetworkBandwidth{IngressRate: 1000000, IngressBurst: 100000},
+		},
+		{
+			name:      "rate without burst",
+			bandwidth: &ecscni.NetworkBandwidth{IngressRate: 1000000},
+			eni:       &apieni.ENI{ID: "eni-id"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			task := &Task{
+				Arn: "arn:aws:ecs:us-west-2:123456789012:task/task-id",
+				Containers: []*apicontainer.Container{
+					{
+						Name:                      "app",
+						TransitionDependenciesMap: make(map[apicontainerstatus.ContainerStatus]apicontainer.TransitionDependencySet),
+					},
+				},
+				ResourcesMapUnsafe: make(map[string][]taskresource.TaskResource),
+				NetworkBandwidth:   tc.bandwidth,
+			}
+			if tc.eni != nil {
+				task.AddTaskENI(tc.eni)
+			}
+			assert.Error(t, task.PostUnmarshalTask(&config.Config{}, nil, nil, nil, nil))
+		})
 	}
+}
+
+func TestIsExperimentEnabled(t *testing.T) {
+	task, err := TaskFromACS(&ecsacs.Task{
+		Arn:         strptr("myArn"),
+		Experiments: []*string{strptr(experiments.LazyPull)},
+	}, &ecsacs.PayloadMessage{})
+	require.NoError(t, err)
 
+	cfg := &config.Config{EnabledExperiments: []string{experiments.LazyPull}}
+	assert.True(t, task.IsExperimentEnabled(cfg, experiments.LazyPull))
+	assert.False(t, task.IsExperimentEnabled(&config.Config{}, experiments.LazyPull))
+	task.Experiments = nil
+	assert.False(t, task.IsExperimentEnabled(cfg, experiments.LazyPull))
 }
 
 // Slice of structs for Table Driven testing for sharing PID and IPC resources
@@ -986,6 +1829,128 @@ func TestAddNamespaceSharingProvisioningDependency(t *testing.T) {
 	}
 }
 
+func TestValidateNamespaceModes(t *testing.T) {
+	testCases := []struct {
+		pidMode string
+		ipcMode string
+		valid   bool
+	}{
+		{pidMode: "", ipcMode: "", valid: true},
+		{pidMode: "host", ipcMode: "none", valid: true},
+		{pidMode: "task", ipcMode: "task", valid: true},
+		{pidMode: "container", ipcMode: "", valid: false},
+		{pidMode: "", ipcMode: "shareable", valid: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("pid %q ipc %q", tc.pidMode, tc.ipcMode), func(t *testing.T) {
+			testTask := &Task{PIDMode: tc.pidMode, IPCMode: tc.ipcMode}
+			assert.Equal(t, tc.valid, testTask.validateNamespaceModes() == nil)
+		})
+	}
+}
+
+func TestValidateStdoutHealthChecks(t *testing.T) {
+	testCases := []struct {
+		healthCheckType string
+		logDriver       string
+		valid           bool
+	}{
+		{healthCheckType: apicontainer.StdoutHealthCheckType, logDriver: "", valid: true},
+		{healthCheckType: apicontainer.StdoutHealthCheckType, logDriver: "json-file", valid: true},
+		{healthCheckType: apicontainer.StdoutHealthCheckType, logDriver: "journald", valid: true},
+		{healthCheckType: apicontainer.StdoutHealthCheckType, logDriver: "awslogs", valid: false},
+		{healthCheckType: apicontainer.StdoutHealthCheckType, logDriver: "awsfirelens", valid: false},
+		{healthCheckType: apicontainer.DockerHealthCheckType, logDriver: "awslogs", valid: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("%s health check with %q logging driver", tc.healthCheckType, tc.logDriver), func(t *testing.T) {
+			hostConfig := fmt.Sprintf(`{"LogConfig":{"Type":%q}}`, tc.logDriver)
+			testTask := &Task{
+				Containers: []*apicontainer.Container{
+					{
+						Name:            "app",
+						HealthCheckType: tc.healthCheckType,
+						DockerConfig:    apicontainer.DockerConfig{HostConfig: &hostConfig},
+					},
+				},
+			}
+			assert.Equal(t, tc.valid, testTask.validateStdoutHealthChecks() == nil)
+		})
+	}
+}
+
+func TestApplyNetworkModePolicy(t *testing.T) {
+	testCases := []struct {
+		name                   string
+		hostConfig             *string
+		awsvpc                 bool
+		defaultNetworkMode     string
+		disallowedNetworkModes []string
+		expectedNetworkMode    string
+		expectedErr            *apierrors.NetworkModeNotAllowedError
+	}{
+		{
+			name:                "default network mode",
+			defaultNetworkMode:  "none",
+			expectedNetworkMode: "none",
+		},
+		{
+			name:                "network mode set by the container",
+			hostConfig:          aws.String(`{"NetworkMode":"host"}`),
+			defaultNetworkMode:  "none",
+			expectedNetworkMode: "host",
+		},
+		{
+			name:                   "bridge network mode disallowed",
+			disallowedNetworkModes: []string{"bridge"},
+			expectedErr:            &apierrors.NetworkModeNotAllowedError{NetworkMode: "bridge", ContainerName: "c1"},
+		},
+		{
+			name:                   "default network mode disallowed",
+			defaultNetworkMode:     "host",
+			disallowedNetworkModes: []string{"host"},
+			expectedErr:            &apierrors.NetworkModeNotAllowedError{NetworkMode: "host", ContainerName: "c1"},
+		},
+		{
+			name:                   "awsvpc network mode disallowed",
+			awsvpc:                 true,
+			disallowedNetworkModes: []string{"awsvpc"},
+			expectedErr:            &apierrors.NetworkModeNotAllowedError{NetworkMode: "awsvpc"},
+		},
+		{
+			name:                   "awsvpc network mode allowed",
+			awsvpc:                 true,
+			defaultNetworkMode:     "host",
+			disallowedNetworkModes: []string{"host"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			container := &apicontainer.Container{Name: "c1"}
+			container.DockerConfig.HostConfig = tc.hostConfig
+			testTask := &Task{Containers: []*apicontainer.Container{container}}
+			if tc.awsvpc {
+				testTask.ENIs = []*apieni.ENI{{ID: "eni-1"}}
+			}
+			cfg := &config.Config{
+				DefaultNetworkMode:     tc.defaultNetworkMode,
+				DisallowedNetworkModes: tc.disallowedNetworkModes,
+			}
+
+			err := testTask.applyNetworkModePolicy(cfg)
+			if tc.expectedErr != nil {
+				assert.Equal(t, tc.expectedErr, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedNetworkMode, container.GetNetworkModeFromHostConfig())
+		})
+	}
+}
+
 func TestTaskFromACS(t *testing.T) {
 	testTime := ttime.Now().Truncate(1 * time.Second).Format(time.RFC3339)
 
@@ -1105,7 +2070,7 @@ func TestTaskFromACS(t *testing.T) {
 			{
 				Name:        "myName",
 				Image:       "image:tag",
-				Command:     []string{"a", "b", "c"},
+				Command:     []string{"command", "command2"},
 				Links:       []string{"link1", "link2"},
 				EntryPoint:  &[]string{"sh", "-c"},
 				Essential:   true,
@@ -1517,7 +2482,7 @@ func TestTaskGetPrimaryENI(t *testing.T) {
 	assert.Nil(t, eni)
 }
 
-// TestTaskFromACSWithOverrides tests the container command is overridden correctly
+// TestTaskFromACSWithOverrides tests the container command and entrypoint are overridden correctly
 func TestTaskFromACSWithOverrides(t *testing.T) {
 	taskFromACS := ecsacs.Task{
 		Arn:           strptr("myArn"),
@@ -1533,7 +2498,8 @@ func TestTaskFromACSWithOverrides(t *testing.T) {
 						SourceVolume:  strptr("volumeName1"),
 					},
 				},
-				Overrides: strptr(`{"command": ["foo", "bar"]}`),
+				Command:   []*string{strptr("command")},
+				Overrides: strptr(`{"command": ["foo", "bar"], "entryPoint": ["sh", "-c"]}`),
 			},
 			{
 				Name:    strptr("myName2"),
@@ -1553,9 +2519,17 @@ func TestTaskFromACSWithOverrides(t *testing.T) {
 	assert.Nil(t, err, "Should be able to handle acs task")
 	assert.Equal(t, 2, len(task.Containers)) // before PostUnmarshalTask
 
-	assert.Equal(t, task.Containers[0].Command[0], "foo")
-	assert.Equal(t, task.Containers[0].Command[1], "bar")
-	assert.Equal(t, task.Containers[1].Command[0], "command")
+	// The overrides are applied at container create, keeping the values of
+	// the task definition
+	assert.Equal(t, []string{"command"}, task.Containers[0].Command)
+	config, configErr := task.DockerConfig(task.Containers[0], defaultDockerClientAPIVersion)
+	assert.Nil(t, configErr)
+	assert.Equal(t, []string{"foo", "bar"}, []string(config.Cmd))
+	assert.Equal(t, []string{"sh", "-c"}, []string(config.Entrypoint))
+
+	config, configErr = task.DockerConfig(task.Containers[1], defaultDockerClientAPIVersion)
+	assert.Nil(t, configErr)
+	assert.Equal(t, []string{"command"}, []string(config.Cmd))
 }
 
 // TestSetPullStartedAt tests the task SetPullStartedAt
@@ -2612,6 +3586,57 @@ func TestPopulateSecrets(t *testing.T) {
 	assert.Equal(t, "option", hostConfig.LogConfig.Config["splunk-option"])
 }
 
+func TestInitializeAndPopulateProviderSecrets(t *testing.T) {
+	vaultSecret := apicontainer.Secret{
+		Provider:  "ssm",
+		Name:      "vault-secret",
+		Region:    "us-west-2",
+		Type:      "ENVIRONMENT_VARIABLE",
+		ValueFrom: "vault://secret/data/myapp#password",
+	}
+	ssmSecret := apicontainer.Secret{
+		Provider:  "ssm",
+		Name:      "ssm-secret",
+		Region:    "us-west-2",
+		Type:      "ENVIRONMENT_VARIABLE",
+		ValueFrom: "/test/secretName",
+	}
+	container := &apicontainer.Container{
+		Name:                      "myName",
+		Image:                     "image:tag",
+		Secrets:                   []apicontainer.Secret{vaultSecret, ssmSecret},
+		TransitionDependenciesMap: make(map[apicontainerstatus.ContainerStatus]apicontainer.TransitionDependencySet),
+	}
+	task := &Task{
+		Arn:                "test",
+		ResourcesMapUnsafe: make(map[string][]taskresource.TaskResource),
+		Containers:         []*apicontainer.Container{container},
+	}
+
+	assert.True(t, task.requiresProviderSecret())
+	assert.Len(t, task.getAllSSMSecretRequirements()["us-west-2"], 1, "vault secrets aren't retrieved from SSM")
+	task.initializeProviderSecretResource(&taskresource.ResourceFields{
+		ResourceFieldsCommon: &taskresource.ResourceFieldsCommon{},
+	})
+	resourceDep := apicontainer.ResourceDependency{
+		Name:           providersecret.ResourceName,
+		RequiredStatus: resourcestatus.ResourceStatus(providersecret.ProviderSecretCreated),
+	}
+	assert.Equal(t, resourceDep, container.TransitionDependenciesMap[apicontainerstatus.ContainerCreated].ResourceDependencies[0])
+
+	res, ok := task.getSecretResources()[providersecret.ResourceName]
+	require.True(t, ok)
+	res.(*providersecret.ProviderSecretResource).SetCachedSecretValue(vaultSecret.GetSecretResourceCacheKey(), "vault-value")
+	ssmRes := &ssmsecret.SSMSecretResource{}
+	ssmRes.SetCachedSecretValue(ssmSecret.GetSecretResourceCacheKey(), "ssm-value")
+	task.AddResource(ssmsecret.ResourceName, ssmRes)
+
+	hostConfig := &dockercontainer.HostConfig{}
+	assert.Nil(t, task.PopulateSecrets(hostConfig, container))
+	assert.Equal(t, "vault-value", container.Environment["vault-secret"])
+	assert.Equal(t, "ssm-value", container.Environment["ssm-secret"])
+}
+
 func TestPopulateSecretsNoConfigInHostConfig(t *testing.T) {
 	secret1 := apicontainer.Secret{
 		Provider:  "ssm",
@@ -2839,6 +3864,30 @@ func TestDockerHostConfigNvidiaRuntime(t *testing.T) {
 	assert.Equal(t, testTask.NvidiaRuntime, dockerHostConfig.Runtime)
 }
 
+func TestDockerHostConfigNvidiaRuntimeAssignedGPUs(t *testing.T) {
+	testTask := &Task{
+		Arn: "test",
+		Containers: []*apicontainer.Container{
+			{
+				Name:  "myName1",
+				Image: "image:tag",
+				ResourceRequirements: []apicontainer.ResourceRequirement{
+					{Type: apicontainer.ResourceTypeGPU, Value: "1"},
+				},
+				GPUIDs: []string{"gpu1"},
+				Environment: map[string]string{
+					NvidiaVisibleDevicesEnvVar: "gpu1",
+				},
+			},
+		},
+		NvidiaRuntime: config.DefaultNvidiaRuntime,
+	}
+
+	dockerHostConfig, err := testTask.DockerHostConfig(testTask.Containers[0], dockerMap(testTask), defaultDockerClientAPIVersion)
+	assert.Nil(t, err)
+	assert.Equal(t, testTask.NvidiaRuntime, dockerHostConfig.Runtime)
+}
+
 func TestDockerHostConfigRuntimeWithoutGPU(t *testing.T) {
 	testTask := &Task{
 		Arn: "test",
@@ -3217,3 +4266,212 @@ func TestBuildCNIConfigTrunkBranchENI(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildCNIConfigWithNetworkBandwidth(t *testing.T) {
+	testTask := &Task{
+		NetworkBandwidth: &ecscni.NetworkBandwidth{
+			EgressRate:  1000000,
+			EgressBurst: 100000,
+		},
+	}
+	testTask.AddTaskENI(&apieni.ENI{
+		ID: "TestBuildCNIConfigWithNetworkBandwidth",
+		IPV4Addresses: []*apieni.ENIIPV4Address{
+			{
+				Primary: true,
+				Address: ipv4,
+			},
+		},
+		MacAddress: mac,
+	})
+	cniConfig, err := testTask.BuildCNIConfig(true, &ecscni.Config{})
+	require.NoError(t, err)
+	// We expect 3 NetworkConfig objects in the cni Config wrapper object:
+	// ENI, Bridge and Bandwidth
+	require.Len(t, cniConfig.NetworkConfigs, 3)
+	assert.Equal(t, "eth0", cniConfig.NetworkConfigs[2].IfName)
+	var bandwidthConfig ecscni.BandwidthConfig
+	err = json.Unmarshal(cniConfig.NetworkConfigs[2].CNINetworkConfig.Bytes, &bandwidthConfig)
+	require.NoError(t, err)
+	assert.Equal(t, ecscni.ECSBandwidthPluginName, bandwidthConfig.Type)
+	assert.Equal(t, *testTask.NetworkBandwidth, bandwidthConfig.NetworkBandwidth)
+}
+
+func TestBuildCNIConfigMultipleENIs(t *testing.T) {
+	testTask := &Task{}
+	for i, eniMAC := range []string{mac, "02:7b:64:49:b1:41"} {
+		testTask.AddTaskENI(&apieni.ENI{
+			ID: fmt.Sprintf("TestBuildCNIConfigMultipleENIs-%d", i),
+			IPV4Addresses: []*apieni.ENIIPV4Address{
+				{
+					Primary: true,
+					Address: ipv4,
+				},
+			},
+			MacAddress: eniMAC,
+		})
+	}
+	cniConfig, err := testTask.BuildCNIConfig(true, &ecscni.Config{BlockInstanceMetadata: true})
+	require.NoError(t, err)
+	// We expect 3 NetworkConfig objects in the cni Config wrapper object:
+	// both ENIs and Bridge
+	require.Len(t, cniConfig.NetworkConfigs, 3)
+	assert.Equal(t, "eth0", cniConfig.NetworkConfigs[0].IfName)
+	assert.Equal(t, "eth1", cniConfig.NetworkConfigs[1].IfName)
+	var primaryENIConfig, eniConfig ecscni.ENIConfig
+	require.NoError(t, json.Unmarshal(cniConfig.NetworkConfigs[0].CNINetworkConfig.Bytes, &primaryENIConfig))
+	assert.True(t, primaryENIConfig.BlockInstanceMetadata)
+	assert.False(t, primaryENIConfig.SkipDefaultRoute)
+	require.NoError(t, json.Unmarshal(cniConfig.NetworkConfigs[1].CNINetworkConfig.Bytes, &eniConfig))
+	assert.Equal(t, "02:7b:64:49:b1:41", eniConfig.MACAddress)
+	// Only the primary ENI carries the default route and blocks instance
+	// metadata
+	assert.False(t, eniConfig.BlockInstanceMetadata)
+	assert.True(t, eniConfig.SkipDefaultRoute)
+	// The bridge IPAM allocation is identified by the primary ENI
+	assert.Equal(t, mac, cniConfig.ID)
+}
+
+func TestValidateENIs(t *testing.T) {
+	testTask := &Task{ENIs: []*apieni.ENI{{ID: "eni-1"}}}
+	assert.NoError(t, testTask.validateENIs(&config.Config{}))
+
+	testTask.ENIs = append(testTask.ENIs, &apieni.ENI{ID: "eni-2"})
+	assert.Error(t, testTask.validateENIs(&config.Config{}))
+	assert.NoError(t, testTask.validateENIs(&config.Config{CNIAdditionalENIsSupported: true}))
+}
+
+func TestDockerHostConfigPauseContainerDNSConfig(t *testing.T) {
+	testTask := &Task{
+		ENIs: []*apieni.ENI{
+			{
+				ID:                   "eniID",
+				DomainNameServers:    []string{"169.254.169.253"},
+				DomainNameSearchList: []string{"us-west-2.compute.internal"},
+			},
+		},
+		Containers: []*apicontainer.Container{
+			{
+				Name: NetworkPauseContainerName,
+				Type: apicontainer.ContainerCNIPause,
+			},
+		},
+		DNSConfig: &DNSConfig{
+			Nameservers: []string{"10.0.0.2", "10.0.0.3"},
+			Options:     []string{"ndots:2"},
+		},
+	}
+
+	config, err := testTask.DockerHostConfig(testTask.Containers[0], dockerMap(testTask), defaultDockerClientAPIVersion)
+	require.Nil(t, err)
+	assert.Equal(t, []string{"10.0.0.2", "10.0.0.3"}, config.DNS)
+	// The search list of the ENI is kept as the task doesn't override it
+	assert.Equal(t, []string{"us-west-2.compute.internal"}, config.DNSSearch)
+	assert.Equal(t, []string{"ndots:2"}, config.DNSOptions)
+}
+
+func TestValidateDNSConfig(t *testing.T) {
+	testCases := []struct {
+		name      string
+		task      *Task
+		expectErr bool
+	}{
+		{
+			name: "no dns config",
+			task: &Task{},
+		},
+		{
+			name: "valid dns config",
+			task: &Task{
+				ENIs: []*apieni.ENI{{ID: "eniID"}},
+				DNSConfig: &DNSConfig{
+					Nameservers:   []string{"10.0.0.2", "2600:1f14:abcd::2"},
+					SearchDomains: []string{"example.com"},
+					Options:       []string{"ndots:2", "rotate"},
+				},
+			},
+		},
+		{
+			name: "not awsvpc",
+			task: &Task{
+				DNSConfig: &DNSConfig{Nameservers: []string{"10.0.0.2"}},
+			},
+			expectErr: true,
+		},
+		{
+			name: "invalid nameserver",
+			task: &Task{
+				ENIs:      []*apieni.ENI{{ID: "eniID"}},
+				DNSConfig: &DNSConfig{Nameservers: []string{"dns.example.com"}},
+			},
+			expectErr: true,
+		},
+		{
+			name: "too many nameservers",
+			task: &Task{
+				ENIs:      []*apieni.ENI{{ID: "eniID"}},
+				DNSConfig: &DNSConfig{Nameservers: []string{"10.0.0.2", "10.0.0.3", "10.0.0.4", "10.0.0.5"}},
+			},
+			expectErr: true,
+		},
+		{
+			name: "invalid option",
+			task: &Task{
+				ENIs:      []*apieni.ENI{{ID: "eniID"}},
+				DNSConfig: &DNSConfig{Options: []string{"ndots:2 rotate"}},
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.task.validateDNSConfig()
+			if tc.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRecordContainerRestart(t *testing.T) {
+	now := time.Now()
+	task := &Task{
+		RestartTimesUnsafe: []time.Time{now.Add(-time.Hour), now.Add(-time.Minute)},
+	}
+	assert.Equal(t, 2, task.RecordContainerRestart(now, 10*time.Minute))
+	assert.Equal(t, []time.Time{now.Add(-time.Minute), now}, task.RestartTimesUnsafe)
+	assert.Equal(t, 1, task.RecordContainerRestart(now.Add(time.Hour), 10*time.Minute))
+}
+
+func TestValidateAppMesh(t *testing.T) {
+	task := &Task{
+		Arn:        "arn:aws:ecs:us-west-2:123456789012:task/task-id",
+		Containers: []*apicontainer.Container{{Name: "app"}, {Name: "envoy"}},
+	}
+	assert.NoError(t, task.ValidateAppMesh(), "no app mesh")
+
+	task.SetAppMesh(&appmesh.AppMesh{ContainerName: "envoy", ProxyIngressPort: "9000"})
+	assert.Error(t, task.ValidateAppMesh(), "not an awsvpc task")
+
+	task.AddTaskENI(&apieni.ENI{ID: "eni-id"})
+	assert.NoError(t, task.ValidateAppMesh())
+
+	task.SetAppMesh(&appmesh.AppMesh{ContainerName: "proxy", ProxyIngressPort: "9000"})
+	assert.Error(t, task.ValidateAppMesh(), "no proxy container")
+}
+
+func TestSetRuntimeSettings(t *testing.T) {
+	task := &Task{}
+	assert.True(t, task.SetRuntimeSettings(map[string]string{"feature": "on"}, 2))
+	assert.False(t, task.SetRuntimeSettings(map[string]string{"feature": "off"}, 1))
+	assert.True(t, task.SetRuntimeSettings(map[string]string{"feature": "off", "rollout": "50"}, 2))
+
+	settings := task.GetRuntimeSettings()
+	assert.Equal(t, map[string]string{"feature": "off", "rollout": "50"}, settings)
+	assert.Equal(t, int64(2), task.RuntimeSettingsVersion)
+	settings["feature"] = "on"
+	assert.Equal(t, "off", task.GetRuntimeSettings()["feature"])
+}
@@ -17,6 +17,7 @@ package task
 
 import (
 	"errors"
+	"fmt"
 	"path/filepath"
 	"regexp"
 	"runtime"
@@ -37,6 +38,11 @@ const (
 	minimumCPUPercent = 1
 )
 
+// windowsBindPattern splits a bind into its source and destination, which are
+// either named pipes, paths starting with a drive or volume names
+var windowsBindPattern = regexp.MustCompile(
+	`^(\\\\\.\\pipe\\[^:]+|[a-zA-Z]:[^:]*|[^:\\]+):(\\\\\.\\pipe\\[^:]+|[a-zA-Z]:[^:]*)(:.*)?$`)
+
 // PlatformFields consists of fields specific to Windows for a task
 type PlatformFields struct {
 	// CpuUnbounded determines whether a mix of unbounded and bounded CPU tasks
@@ -130,6 +136,32 @@ func (task *Task) platformHostConfigOverride(hostConfig *dockercontainer.HostCon
 		hostConfig.MemoryReservation = 0
 	}
 
+	return validateNamedPipeBinds(hostConfig)
+}
+
+// validateNamedPipeBinds checks the binds of named pipes, such as
+// \\.\pipe\docker_engine, which let containers talk to services of the host.
+// Docker only maps a named pipe of the host to a named pipe of the container,
+// and only for process isolated containers, as the named pipes of the host
+// can't be reached from the utility VM of Hyper-V isolated containers
+func validateNamedPipeBinds(hostConfig *dockercontainer.HostConfig) error {
+	for _, bind := range hostConfig.Binds {
+		matches := windowsBindPattern.FindStringSubmatch(bind)
+		if matches == nil {
+			continue
+		}
+		source, destination := strings.ToLower(matches[1]), strings.ToLower(matches[2])
+		sourceIsPipe, destinationIsPipe := isNamedPipesPath(source), isNamedPipesPath(destination)
+		if !sourceIsPipe && !destinationIsPipe {
+			continue
+		}
+		if sourceIsPipe != destinationIsPipe {
+			return fmt.Errorf("invalid mount %s: named pipes can only be mounted to named pipes", bind)
+		}
+		if hostConfig.Isolation.IsHyperV() {
+			return fmt.Errorf("invalid mount %s: named pipes can't be mounted in Hyper-V isolated containers", bind)
+		}
+	}
 	return nil
 }
 
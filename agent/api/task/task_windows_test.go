@@ -141,6 +141,58 @@ func TestWindowsPlatformHostConfigOverride(t *testing.T) {
 	assert.Empty(t, hostConfig.CPUShares)
 }
 
+func TestWindowsPlatformHostConfigOverrideNamedPipes(t *testing.T) {
+	testCases := []struct {
+		name        string
+		bind        string
+		isolation   dockercontainer.Isolation
+		expectedErr bool
+	}{
+		{
+			name: "named pipe",
+			bind: `\\.\pipe\docker_engine:\\.\pipe\docker_engine`,
+		},
+		{
+			name: "directory",
+			bind: `c:\data:c:\data:ro`,
+		},
+		{
+			name:        "named pipe to a directory",
+			bind:        `\\.\pipe\docker_engine:c:\docker_engine`,
+			expectedErr: true,
+		},
+		{
+			name:        "directory to a named pipe",
+			bind:        `c:\data:\\.\pipe\data`,
+			expectedErr: true,
+		},
+		{
+			name:        "named pipe in a Hyper-V isolated container",
+			bind:        `\\.\pipe\docker_engine:\\.\pipe\docker_engine`,
+			isolation:   dockercontainer.Isolation("hyperv"),
+			expectedErr: true,
+		},
+		{
+			name:      "directory in a Hyper-V isolated container",
+			bind:      `c:\data:c:\data`,
+			isolation: dockercontainer.Isolation("hyperv"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			task := &Task{}
+			hostConfig := &dockercontainer.HostConfig{Binds: []string{tc.bind}, Isolation: tc.isolation}
+			err := task.platformHostConfigOverride(hostConfig)
+			if tc.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
 func TestDockerHostConfigRawConfigMerging(t *testing.T) {
 	// Use a struct that will marshal to the actual message we expect; not
 	// dockercontainer.HostConfig which will include a lot of zero values.
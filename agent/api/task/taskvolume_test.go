@@ -21,9 +21,11 @@ import (
 
 	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
 	apicontainerstatus "github.com/aws/amazon-ecs-agent/agent/api/container/status"
+	"github.com/aws/amazon-ecs-agent/agent/config"
 	"github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi"
 	mock_dockerapi "github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi/mocks"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource"
+	taskresourceefs "github.com/aws/amazon-ecs-agent/agent/taskresource/efs"
 	taskresourcevolume "github.com/aws/amazon-ecs-agent/agent/taskresource/volume"
 
 	"github.com/docker/docker/api/types"
@@ -67,6 +69,8 @@ func TestMarshalUnmarshalTaskVolumes(t *testing.T) {
 			{Name: "1", Type: HostVolumeType, Volume: &taskresourcevolume.LocalDockerVolume{}},
 			{Name: "2", Type: HostVolumeType, Volume: &taskresourcevolume.FSHostVolume{FSSourcePath: "/path"}},
 			{Name: "3", Type: DockerVolumeType, Volume: &taskresourcevolume.DockerVolumeConfig{Scope: "task", Driver: "local"}},
+			{Name: "4", Type: EFSVolumeType, Volume: &taskresourceefs.EFSVolumeConfig{
+				FileSystemID: "fs-12345678", RootDirectory: "/data", TransitEncryption: taskresourceefs.EFSEnabled}},
 		},
 	}
 
@@ -76,9 +80,9 @@ func TestMarshalUnmarshalTaskVolumes(t *testing.T) {
 	var out Task
 	err = json.Unmarshal(marshal, &out)
 	require.NoError(t, err, "Could not unmarshal task")
-	require.Len(t, out.Volumes, 3, "Incorrect number of volumes")
+	require.Len(t, out.Volumes, 4, "Incorrect number of volumes")
 
-	var v1, v2, v3 TaskVolume
+	var v1, v2, v3, v4 TaskVolume
 
 	for _, v := range out.Volumes {
 		switch v.Name {
@@ -88,6 +92,8 @@ func TestMarshalUnmarshalTaskVolumes(t *testing.T) {
 			v2 = v
 		case "3":
 			v3 = v
+		case "4":
+			v4 = v
 		}
 	}
 
@@ -102,6 +108,12 @@ func TestMarshalUnmarshalTaskVolumes(t *testing.T) {
 	assert.True(t, ok, "incorrect DockerVolumeConfig type")
 	assert.Equal(t, "task", dockerVolume.Scope)
 	assert.Equal(t, "local", dockerVolume.Driver)
+
+	efsVolume, ok := v4.Volume.(*taskresourceefs.EFSVolumeConfig)
+	assert.True(t, ok, "incorrect EFSVolumeConfig type")
+	assert.Equal(t, "fs-12345678", efsVolume.FileSystemID)
+	assert.Equal(t, "/data", efsVolume.RootDirectory)
+	assert.Equal(t, taskresourceefs.EFSEnabled, efsVolume.TransitEncryption)
 }
 
 func TestInitializeLocalDockerVolume(t *testing.T) {
@@ -450,3 +462,65 @@ func TestInitializeTaskVolume(t *testing.T) {
 	assert.Len(t, testTask.ResourcesMapUnsafe, 1, "expect the resource map has an empty volume resource")
 	assert.Len(t, testTask.Containers[0].TransitionDependenciesMap, 1, "expect a volume resource as the container dependency")
 }
+
+func TestInitializeEFSVolume(t *testing.T) {
+	testTask := &Task{
+		Arn:                "arn:aws:ecs:us-west-2:123456789012:task/task-id",
+		ResourcesMapUnsafe: make(map[string][]taskresource.TaskResource),
+		Containers: []*apicontainer.Container{
+			{
+				MountPoints: []apicontainer.MountPoint{
+					{
+						SourceVolume:  "efs-volume-test",
+						ContainerPath: "/ecs",
+					},
+				},
+				TransitionDependenciesMap: make(map[apicontainerstatus.ContainerStatus]apicontainer.TransitionDependencySet),
+			},
+		},
+		Volumes: []TaskVolume{
+			{
+				Name: "efs-volume-test",
+				Type: EFSVolumeType,
+				Volume: &taskresourceefs.EFSVolumeConfig{
+					FileSystemID: "fs-12345678",
+				},
+			},
+		},
+	}
+	cfg := &config.Config{
+		DataDir:       "/data",
+		DataDirOnHost: "/var/lib/ecs",
+	}
+
+	err := testTask.initializeEFSVolumes(cfg, nil)
+	require.NoError(t, err)
+	require.Len(t, testTask.ResourcesMapUnsafe[taskresourceefs.ResourceName], 1)
+	efsResource := testTask.ResourcesMapUnsafe[taskresourceefs.ResourceName][0].(*taskresourceefs.EFSResource)
+	assert.Equal(t, "/data/efs/task-id/efs-volume-test", efsResource.MountPath)
+	assert.Equal(t, "/var/lib/ecs/data/efs/task-id/efs-volume-test", testTask.Volumes[0].Volume.Source())
+	assert.Len(t, testTask.Containers[0].TransitionDependenciesMap, 1, "expect the efs resource as the container dependency")
+}
+
+func TestInitializeEFSVolumeInvalidConfig(t *testing.T) {
+	testTask := &Task{
+		Arn:                "arn:aws:ecs:us-west-2:123456789012:task/task-id",
+		ResourcesMapUnsafe: make(map[string][]taskresource.TaskResource),
+		Volumes: []TaskVolume{
+			{
+				Name: "efs-volume-test",
+				Type: EFSVolumeType,
+				Volume: &taskresourceefs.EFSVolumeConfig{
+					FileSystemID: "fs-12345678",
+					AuthConfig: taskresourceefs.EFSAuthConfig{
+						IAM: taskresourceefs.EFSEnabled,
+					},
+				},
+			},
+		},
+	}
+
+	err := testTask.initializeEFSVolumes(&config.Config{}, nil)
+	assert.Error(t, err, "expect an error when IAM authorization is enabled without transit encryption")
+	assert.Empty(t, testTask.ResourcesMapUnsafe[taskresourceefs.ResourceName])
+}
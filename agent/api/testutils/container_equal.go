@@ -91,15 +91,13 @@ func ContainersEqual(lhs, rhs *apicontainer.Container) bool {
 
 // ContainerOverridesEqual determines if two container overrides are equal
 func ContainerOverridesEqual(lhs, rhs apicontainer.ContainerOverrides) bool {
-	if lhs.Command == nil || rhs.Command == nil {
-		if lhs.Command != rhs.Command {
-			return false
-		}
-	} else {
-		if !utils.StrSliceEqual(*lhs.Command, *rhs.Command) {
-			return false
-		}
-	}
+	return stringSlicePtrEqual(lhs.Command, rhs.Command) &&
+		stringSlicePtrEqual(lhs.EntryPoint, rhs.EntryPoint)
+}
 
-	return true
+func stringSlicePtrEqual(lhs, rhs *[]string) bool {
+	if lhs == nil || rhs == nil {
+		return lhs == rhs
+	}
+	return utils.StrSliceEqual(*lhs, *rhs)
 }
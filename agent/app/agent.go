@@ -18,9 +18,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
 	"time"
 
 	"github.com/aws/amazon-ecs-agent/agent/metrics"
+	"github.com/aws/amazon-ecs-agent/agent/preflight"
 
 	acshandler "github.com/aws/amazon-ecs-agent/agent/acs/handler"
 	"github.com/aws/amazon-ecs-agent/agent/api"
@@ -40,12 +42,18 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/engine"
 	"github.com/aws/amazon-ecs-agent/agent/engine/dockerstate"
 	"github.com/aws/amazon-ecs-agent/agent/eni/pause"
+	"github.com/aws/amazon-ecs-agent/agent/errorbudget"
 	"github.com/aws/amazon-ecs-agent/agent/eventhandler"
 	"github.com/aws/amazon-ecs-agent/agent/eventstream"
 	"github.com/aws/amazon-ecs-agent/agent/handlers"
+	"github.com/aws/amazon-ecs-agent/agent/httpclient"
+	"github.com/aws/amazon-ecs-agent/agent/localdns"
+	s3factory "github.com/aws/amazon-ecs-agent/agent/s3/factory"
 	"github.com/aws/amazon-ecs-agent/agent/sighandlers"
 	"github.com/aws/amazon-ecs-agent/agent/sighandlers/exitcodes"
 	"github.com/aws/amazon-ecs-agent/agent/statemanager"
+	"github.com/aws/amazon-ecs-agent/agent/statemirror"
+	"github.com/aws/amazon-ecs-agent/agent/statictask"
 	"github.com/aws/amazon-ecs-agent/agent/stats"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource"
 	tcshandler "github.com/aws/amazon-ecs-agent/agent/tcs/handler"
@@ -72,6 +80,7 @@ const (
 
 var (
 	instanceNotLaunchedInVPCError = errors.New("instance not launched in VPC")
+	unsupportedCNIPluginsError    = errors.New("unsupported CNI plugin versions")
 )
 
 // agent interface is used by the app runner to interact with the ecsAgent
@@ -122,7 +131,8 @@ func newAgent(
 	blackholeEC2Metadata bool,
 	acceptInsecureCert *bool) (agent, error) {
 
-	ec2MetadataClient := ec2.NewEC2MetadataClient(nil)
+	ec2MetadataClient := ec2.NewEC2MetadataClientWithTransport(
+		httpclient.WithLatencyMetrics(http.DefaultTransport, metrics.IMDSService))
 	if blackholeEC2Metadata {
 		ec2MetadataClient = ec2.NewBlackholeEC2MetadataClient()
 	}
@@ -156,7 +166,7 @@ func newAgent(
 		// We use the default API client for the metadata inspect call. This version has some information
 		// missing which means if we need those fields later we will need to change this client to
 		// the appropriate version
-		metadataManager = containermetadata.NewManager(dockerClient, cfg)
+		metadataManager = containermetadata.NewManager(ctx, dockerClient, cfg)
 	}
 
 	initialSeqNumber := int64(-1)
@@ -223,6 +233,11 @@ func (agent *ecsAgent) doStart(containerChangeEventStream *eventstream.EventStre
 	imageManager engine.ImageManager,
 	client api.ECSClient) int {
 
+	// Validate the prerequisites of the agent before anything depends on them
+	if exitcode, ok := agent.runPreflightChecks(); !ok {
+		return exitcode
+	}
+
 	// check docker version >= 1.9.0, exit agent if older
 	if exitcode, ok := agent.verifyRequiredDockerVersion(); !ok {
 		return exitcode
@@ -243,6 +258,15 @@ func (agent *ecsAgent) doStart(containerChangeEventStream *eventstream.EventStre
 		}
 	}
 
+	// Reserve the resources of the static tasks, which the instance doesn't
+	// register with ECS
+	if agent.cfg.StaticTasksDir != "" {
+		if err := statictask.ReserveResources(agent.cfg); err != nil {
+			seelog.Errorf("Unable to reserve the resources of the static tasks of %s: %v",
+				agent.cfg.StaticTasksDir, err)
+		}
+	}
+
 	// Create the task engine
 	taskEngine, currentEC2InstanceID, err := agent.newTaskEngine(containerChangeEventStream,
 		credentialsManager, state, imageManager)
@@ -263,7 +287,7 @@ func (agent *ecsAgent) doStart(containerChangeEventStream *eventstream.EventStre
 	var vpcSubnetAttributes []*ecs.Attribute
 	// Check if Task ENI is enabled
 	if agent.cfg.TaskENIEnabled {
-		err, terminal := agent.initializeTaskENIDependencies(state, taskEngine)
+		err, terminal := agent.initializeTaskENIDependencies(state, taskEngine, imageManager)
 		switch err {
 		case nil:
 			// No error, we can proceed with the rest of initialization
@@ -276,6 +300,11 @@ func (agent *ecsAgent) doStart(containerChangeEventStream *eventstream.EventStre
 			// move on
 			seelog.Warnf("Unable to detect VPC ID for the Instance, disabling Task ENI capability: %v", err)
 			agent.cfg.TaskENIEnabled = false
+		case unsupportedCNIPluginsError:
+			// The CNI plugins are older than the agent supports, don't
+			// register the awsvpc capability rather than fail the tasks
+			seelog.Criticalf("Unsupported CNI plugins, disabling Task ENI capability: %v", err)
+			agent.cfg.TaskENIEnabled = false
 		default:
 			// Encountered an error initializing dependencies for dealing with
 			// ENIs for Tasks. Exit with the appropriate error code
@@ -295,6 +324,8 @@ func (agent *ecsAgent) doStart(containerChangeEventStream *eventstream.EventStre
 		}
 		return exitcodes.ExitTerminal
 	}
+	metrics.MetricsEngineGlobal.SetAvailabilityZone(agent.availabilityZone)
+	metrics.MetricsEngineGlobal.SetStateCounter(engine.NewStateCounter(state))
 	// Add container instance ARN to metadata manager
 	if agent.cfg.ContainerMetadataEnabled {
 		agent.metadataManager.SetContainerInstanceARN(agent.containerInstanceARN)
@@ -303,6 +334,12 @@ func (agent *ecsAgent) doStart(containerChangeEventStream *eventstream.EventStre
 		agent.metadataManager.SetHostPublicIPv4Address(agent.getHostPublicIPv4AddressFromEC2Metadata())
 	}
 
+	if len(agent.cfg.AWSVPCPublishedPorts) != 0 {
+		if publisher, ok := taskEngine.(engine.PortPublisher); ok {
+			publisher.SetPortPublishingAddress(agent.getHostPrivateIPv4AddressFromEC2Metadata())
+		}
+	}
+
 	// Begin listening to the docker daemon and saving changes
 	taskEngine.SetSaver(stateManager)
 	imageManager.SetSaver(stateManager)
@@ -313,10 +350,16 @@ func (agent *ecsAgent) doStart(containerChangeEventStream *eventstream.EventStre
 		deregisterContainerInstanceEventStreamName, agent.ctx)
 	deregisterInstanceEventStream.StartListening()
 	taskHandler := eventhandler.NewTaskHandler(agent.ctx, stateManager, state, client)
+	taskHandler.SetAggregationWindow(agent.cfg.StateChangeAggregationWindow)
 	attachmentEventHandler := eventhandler.NewAttachmentEventHandler(agent.ctx, stateManager, client)
 	agent.startAsyncRoutines(containerChangeEventStream, credentialsManager, imageManager,
 		taskEngine, stateManager, deregisterInstanceEventStream, client, taskHandler, attachmentEventHandler, state)
 
+	// Report the states of the tasks restored from the state file before
+	// accepting new ones, so that they aren't placed on an instance whose
+	// actual state is still unknown
+	agent.waitForRestoredTasksReported(taskEngine)
+
 	// Start the acs session, which should block doStart
 	return agent.startACSSession(credentialsManager, taskEngine, stateManager,
 		deregisterInstanceEventStream, client, state, taskHandler)
@@ -582,6 +625,24 @@ func (agent *ecsAgent) reregisterContainerInstance(client api.ECSClient, capabil
 	return transientError{err}
 }
 
+// restoredTasksReporter is implemented by task engines able to wait for the
+// states of the tasks they restored to be reported
+type restoredTasksReporter interface {
+	WaitForRestoredTasksReported(ctx context.Context, timeout time.Duration) bool
+}
+
+// waitForRestoredTasksReported waits, up to the configured time budget, for the
+// states of the tasks restored after a restart to be reconciled and reported
+func (agent *ecsAgent) waitForRestoredTasksReported(taskEngine engine.TaskEngine) {
+	reporter, ok := taskEngine.(restoredTasksReporter)
+	if !ok {
+		return
+	}
+	if !reporter.WaitForRestoredTasksReported(agent.ctx, agent.cfg.RestoreReconcileTimeout) {
+		seelog.Warnf("Accepting new tasks before the states of all restored tasks were reported")
+	}
+}
+
 // startAsyncRoutines starts all of the background methods
 func (agent *ecsAgent) startAsyncRoutines(
 	containerChangeEventStream *eventstream.EventStream,
@@ -600,17 +661,56 @@ func (agent *ecsAgent) startAsyncRoutines(
 		go imageManager.StartImageCleanupProcess(agent.ctx)
 	}
 
+	// Start listening to image events to prune the states of images removed outside of the agent
+	go imageManager.StartImageEventsListener(agent.ctx)
+
+	// Start resolving the tags of the images to re-pull, if configured to
+	if len(agent.cfg.ImageRepullImages) > 0 {
+		go engine.NewImageRepuller(agent.cfg, agent.dockerClient).Start(agent.ctx)
+	}
+
+	// Run the static tasks of the instance, if configured to
+	if agent.cfg.StaticTasksDir != "" {
+		staticTasks, err := statictask.NewManager(agent.cfg.StaticTasksDir, agent.containerInstanceARN, taskEngine)
+		if err != nil {
+			seelog.Errorf("Unable to load the static tasks of %s: %v", agent.cfg.StaticTasksDir, err)
+		} else {
+			go staticTasks.Start(agent.ctx)
+		}
+	}
+
 	// Start automatic spot instance draining poller routine
 	if agent.cfg.SpotInstanceDrainingEnabled {
-		go agent.startSpotInstanceDrainingPoller(client)
+		go agent.startSpotInstanceDrainingPoller(client, taskEngine)
 	}
 
-	go agent.terminationHandler(stateManager, taskEngine)
+	// Mirror the state to S3 periodically and at shutdown, if configured to
+	var saver statemanager.Saver = stateManager
+	if agent.cfg.StateMirrorS3ARN != "" {
+		mirror, err := statemirror.NewMirror(agent.cfg, agent.containerInstanceARN, stateManager,
+			s3factory.NewS3ClientCreator(), agent.credentialProvider)
+		if err != nil {
+			seelog.Warnf("Unable to set up state mirroring to %s: %v", agent.cfg.StateMirrorS3ARN, err)
+		} else {
+			if err := mirror.Subscribe(containerChangeEventStream); err != nil {
+				seelog.Warnf("Unable to record container events for state mirroring: %v", err)
+			}
+			go mirror.Start(agent.ctx)
+			saver = mirror.Saver(stateManager)
+		}
+	}
+
+	go agent.terminationHandler(saver, taskEngine)
+
+	// Track the internal failures of the agent against its error budgets, if
+	// configured to
+	errorbudget.Init(agent.cfg, agent.containerInstanceARN, agent.credentialProvider)
 
 	// Agent introspection api
 	go handlers.ServeIntrospectionHTTPEndpoint(&agent.containerInstanceARN, taskEngine, agent.cfg)
 
 	statsEngine := stats.NewDockerStatsEngine(agent.cfg, agent.dockerClient, containerChangeEventStream)
+	agent.startGPUStatsSampling(statsEngine)
 
 	// Start serving the endpoint to fetch IAM Role credentials and other task metadata
 	if agent.cfg.TaskMetadataAZDisabled {
@@ -620,6 +720,11 @@ func (agent *ecsAgent) startAsyncRoutines(
 		go handlers.ServeTaskHTTPEndpoint(credentialsManager, state, client, agent.containerInstanceARN, agent.cfg, statsEngine, agent.availabilityZone)
 	}
 
+	// Answer DNS queries for the containers of tasks on the instance
+	if agent.cfg.LocalDNSEnabled {
+		go localdns.NewServer(agent.cfg.LocalDNSListenAddress, state).Serve(agent.ctx)
+	}
+
 	// Start sending events to the backend
 	go eventhandler.HandleEngineEvents(taskEngine, client, taskHandler, attachmentEventHandler)
 
@@ -638,46 +743,137 @@ func (agent *ecsAgent) startAsyncRoutines(
 	go tcshandler.StartMetricsSession(&telemetrySessionParams)
 }
 
-func (agent *ecsAgent) startSpotInstanceDrainingPoller(client api.ECSClient) {
-	for !agent.spotInstanceDrainingPoller(client) {
-		time.Sleep(time.Second)
-	}
+// instanceDrainer is implemented by task engines that reject new tasks while
+// the instance drains
+type instanceDrainer interface {
+	RejectNewTasks(reason string)
+	AcceptNewTasks()
 }
 
-// spotInstanceDrainingPoller returns true if spot instance interruption has been
-// set AND the container instance state is successfully updated to DRAINING.
-func (agent *ecsAgent) spotInstanceDrainingPoller(client api.ECSClient) bool {
-	// this endpoint 404s unless a interruption has been set, so expect failure in most cases.
-	resp, err := agent.ec2MetadataClient.SpotInstanceAction()
-	if err == nil {
-		type InstanceAction struct {
-			Time   string
-			Action string
-		}
-		ia := InstanceAction{}
+// instanceInterruption is why the spot instance draining poller drained the
+// instance
+type instanceInterruption int
 
-		err := json.Unmarshal([]byte(resp), &ia)
-		if err != nil {
-			seelog.Errorf("Invalid response from /spot/instance-action endpoint: %s Error: %s", resp, err)
-			return false
-		}
+const (
+	noInterruption instanceInterruption = iota
+	// spotInterruption is final, spot interruptions aren't called off
+	spotInterruption
+	// autoScalingTermination is called off if the auto scaling group puts the
+	// instance back in service
+	autoScalingTermination
+)
 
-		switch ia.Action {
-		case "hibernate", "terminate", "stop":
-		default:
-			seelog.Errorf("Invalid response from /spot/instance-action endpoint: %s, Error: unrecognized action (%s)", resp, ia.Action)
-			return false
+const (
+	lifecycleStateTerminated = "Terminated"
+	lifecycleStateInService  = "InService"
+)
+
+func (agent *ecsAgent) startSpotInstanceDrainingPoller(client api.ECSClient, taskEngine engine.TaskEngine) {
+	drained := noInterruption
+	for {
+		drained = agent.spotInstanceDrainingPoller(client, taskEngine, drained)
+		if drained == spotInterruption {
+			return
 		}
+		time.Sleep(time.Second)
+	}
+}
 
-		seelog.Infof("Received a spot interruption (%s) scheduled for %s, setting state to DRAINING", ia.Action, ia.Time)
-		err = client.UpdateContainerInstancesState(agent.containerInstanceARN, "DRAINING")
+// spotInstanceDrainingPoller returns why the instance is drained. The instance
+// is drained once a spot instance interruption or the termination of the
+// instance by its auto scaling group has been set AND the container instance
+// state is successfully updated to DRAINING, for ECS to replace the tasks
+// running on the instance. The task engine rejects new tasks as soon as the
+// interruption is noticed. The instance is set back to ACTIVE, and the task
+// engine accepts new tasks again, if its auto scaling group puts it back in
+// service.
+func (agent *ecsAgent) spotInstanceDrainingPoller(client api.ECSClient, taskEngine engine.TaskEngine,
+	drained instanceInterruption) instanceInterruption {
+	drainer, _ := taskEngine.(instanceDrainer)
+	if drained == noInterruption {
+		reason, interruption := agent.instanceInterruption()
+		if interruption == noInterruption {
+			return noInterruption
+		}
+		seelog.Infof("%s, setting state to DRAINING", reason)
+		if drainer != nil {
+			drainer.RejectNewTasks(reason)
+		}
+		err := client.UpdateContainerInstancesState(agent.containerInstanceARN, "DRAINING")
 		if err != nil {
 			seelog.Errorf("Error setting instance [ARN: %s] state to DRAINING: %s", agent.containerInstanceARN, err)
-		} else {
-			return true
+			return noInterruption
 		}
+		return interruption
+	}
+
+	if drained != autoScalingTermination || agent.targetLifecycleState() != lifecycleStateInService {
+		return drained
+	}
+	seelog.Info("Auto scaling group put the instance back in service, setting state to ACTIVE")
+	err := client.UpdateContainerInstancesState(agent.containerInstanceARN, "ACTIVE")
+	if err != nil {
+		seelog.Errorf("Error setting instance [ARN: %s] state to ACTIVE: %s", agent.containerInstanceARN, err)
+		return drained
+	}
+	if drainer != nil {
+		drainer.AcceptNewTasks()
+	}
+	return noInterruption
+}
+
+// instanceInterruption returns the interruption of the instance, either by a
+// spot interruption or by its auto scaling group terminating it
+func (agent *ecsAgent) instanceInterruption() (string, instanceInterruption) {
+	if reason, ok := agent.spotInstanceInterruption(); ok {
+		return reason, spotInterruption
+	}
+	if agent.targetLifecycleState() == lifecycleStateTerminated {
+		return "Auto scaling group is terminating the instance", autoScalingTermination
+	}
+	return "", noInterruption
+}
+
+// spotInstanceInterruption returns the spot interruption of the instance, if
+// one has been set
+func (agent *ecsAgent) spotInstanceInterruption() (string, bool) {
+	// this endpoint 404s unless a interruption has been set, so expect failure in most cases.
+	resp, err := agent.ec2MetadataClient.SpotInstanceAction()
+	if err != nil {
+		return "", false
+	}
+	type InstanceAction struct {
+		Time   string
+		Action string
+	}
+	ia := InstanceAction{}
+
+	err = json.Unmarshal([]byte(resp), &ia)
+	if err != nil {
+		seelog.Errorf("Invalid response from /spot/instance-action endpoint: %s Error: %s", resp, err)
+		return "", false
+	}
+
+	switch ia.Action {
+	case "hibernate", "terminate", "stop":
+	default:
+		seelog.Errorf("Invalid response from /spot/instance-action endpoint: %s, Error: unrecognized action (%s)", resp, ia.Action)
+		return "", false
 	}
-	return false
+	return fmt.Sprintf("Received a spot interruption (%s) scheduled for %s", ia.Action, ia.Time), true
+}
+
+// targetLifecycleState returns the lifecycle state the auto scaling group of
+// the instance is moving it to, or an empty string if it's unknown. The
+// instances moved to a warm pool aren't drained: they're stopped or hibernated
+// in the pool, and put back in service from there
+func (agent *ecsAgent) targetLifecycleState() string {
+	// this endpoint 404s unless the instance is in an auto scaling group
+	state, err := agent.ec2MetadataClient.TargetLifecycleState()
+	if err != nil {
+		return ""
+	}
+	return state
 }
 
 // startACSSession starts a session with ECS's Agent Communication service. This
@@ -715,6 +911,25 @@ func (agent *ecsAgent) startACSSession(
 	return exitcodes.ExitError
 }
 
+// runPreflightChecks checks the prerequisites of the agent and logs a report of
+// the checks. When a check fails, it returns the exit code of its class
+func (agent *ecsAgent) runPreflightChecks() (int, bool) {
+	if agent.cfg.PreflightChecksDisabled {
+		return -1, true
+	}
+
+	checks := []preflight.Check{preflight.DockerCheck(agent.ctx, agent.dockerClient)}
+	checks = append(checks, agent.platformPreflightChecks()...)
+	checks = append(checks, preflight.CredentialsCheck(agent.credentialProvider))
+	report := preflight.Run(checks)
+	if !report.Passed {
+		seelog.Criticalf("Preflight checks failed: %s", report)
+		return report.ExitCode(), false
+	}
+	seelog.Infof("Preflight checks passed: %s", report)
+	return -1, true
+}
+
 // validateRequiredVersion validates docker version.
 // Minimum docker version supported is 1.9.0, maps to api version 1.21
 // see https://docs.docker.com/develop/sdk/#api-version-matrix
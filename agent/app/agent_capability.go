@@ -56,6 +56,13 @@ const (
 	capabilityFirelensConfigFile                = "firelens.options.config.file"
 	capabilityFirelensConfigS3                  = "firelens.options.config.s3"
 	capabilityFullTaskSync                      = "full-sync"
+	capabilityExecuteCommand                    = "execute-command"
+	capabilityExperimentInfix                   = "experiment."
+	capabilityEFS                               = "efs"
+	capabilityInitProcess                       = "container-init-process"
+	capabilityContainerSwap                     = "container-swap"
+	capabilityEphemeralStorage                  = "task-ephemeral-storage"
+	capabilityTaskNetworkBandwidth              = "task-network-bandwidth"
 )
 
 // capabilities returns the supported capabilities of this agent / docker-client pair.
@@ -100,6 +107,12 @@ const (
 //    ecs.capability.firelens.options.config.file
 //    ecs.capability.firelens.options.config.s3
 // 	  ecs.capability.full-sync
+//    ecs.capability.execute-command
+//    ecs.capability.experiment.${experimentName}
+//    ecs.capability.efs
+//    ecs.capability.container-init-process
+//    ecs.capability.task-ephemeral-storage
+//    ecs.capability.task-network-bandwidth
 func (agent *ecsAgent) capabilities() ([]*ecs.Attribute, error) {
 	var capabilities []*ecs.Attribute
 
@@ -107,13 +120,13 @@ func (agent *ecsAgent) capabilities() ([]*ecs.Attribute, error) {
 		capabilities = appendNameOnlyAttribute(capabilities, capabilityPrefix+"privileged-container")
 	}
 
-	supportedVersions := make(map[dockerclient.DockerVersion]bool)
-	// Determine API versions to report as supported. Supported versions are also used for capability-enablement, except
-	// logging drivers.
-	for _, version := range agent.dockerClient.SupportedVersions() {
+	// Determine API versions to report as supported. The highest of the supported versions is negotiated
+	// for capability-enablement, except logging drivers.
+	supportedVersions := agent.dockerClient.SupportedVersions()
+	for _, version := range supportedVersions {
 		capabilities = appendNameOnlyAttribute(capabilities, capabilityPrefix+"docker-remote-api."+string(version))
-		supportedVersions[version] = true
 	}
+	negotiatedVersion := dockerclient.NegotiateAPIVersion(supportedVersions)
 
 	capabilities = agent.appendLoggingDriverCapabilities(capabilities)
 
@@ -124,16 +137,20 @@ func (agent *ecsAgent) capabilities() ([]*ecs.Attribute, error) {
 		capabilities = appendNameOnlyAttribute(capabilities, capabilityPrefix+"apparmor")
 	}
 
-	capabilities = agent.appendTaskIamRoleCapabilities(capabilities, supportedVersions)
+	capabilities = agent.appendTaskIamRoleCapabilities(capabilities, negotiatedVersion)
 
-	capabilities, err := agent.appendTaskCPUMemLimitCapabilities(capabilities, supportedVersions)
+	capabilities, err := agent.appendTaskCPUMemLimitCapabilities(capabilities, negotiatedVersion)
 	if err != nil {
 		return nil, err
 	}
 
 	capabilities = agent.appendTaskENICapabilities(capabilities)
 	capabilities = agent.appendENITrunkingCapabilities(capabilities)
-	capabilities = agent.appendDockerDependentCapabilities(capabilities, supportedVersions)
+	capabilities = agent.appendDockerDependentCapabilities(capabilities, negotiatedVersion)
+	capabilities = agent.appendInitProcessCapabilities(capabilities, negotiatedVersion)
+	capabilities = agent.appendSwapCapabilities(capabilities)
+	capabilities = agent.appendEphemeralStorageCapabilities(capabilities)
+	capabilities = agent.appendTaskNetworkBandwidthCapabilities(capabilities)
 
 	// TODO: gate this on docker api version when ecs supported docker includes
 	// credentials endpoint feature from upstream docker
@@ -193,17 +210,33 @@ func (agent *ecsAgent) capabilities() ([]*ecs.Attribute, error) {
 	// support external firelens config
 	capabilities = agent.appendFirelensConfigCapabilities(capabilities)
 
+	// support ecs exec when the execute command agent is installed
+	capabilities = agent.appendExecCapabilities(capabilities)
+
+	// support efs volumes when the efs mount helper is installed
+	capabilities = agent.appendEFSCapabilities(capabilities)
+
+	// experiments enabled on the instance
+	capabilities = agent.appendExperimentCapabilities(capabilities)
+
 	return capabilities, nil
 }
 
+func (agent *ecsAgent) appendExperimentCapabilities(capabilities []*ecs.Attribute) []*ecs.Attribute {
+	for _, experiment := range agent.cfg.EnabledExperiments {
+		capabilities = appendNameOnlyAttribute(capabilities, attributePrefix+capabilityExperimentInfix+experiment)
+	}
+	return capabilities
+}
+
 func (agent *ecsAgent) appendDockerDependentCapabilities(capabilities []*ecs.Attribute,
-	supportedVersions map[dockerclient.DockerVersion]bool) []*ecs.Attribute {
-	if _, ok := supportedVersions[dockerclient.Version_1_19]; ok {
+	negotiatedVersion dockerclient.DockerVersion) []*ecs.Attribute {
+	if negotiatedVersion.Supports(dockerclient.ECRAuthFeature) {
 		capabilities = appendNameOnlyAttribute(capabilities, capabilityPrefix+"ecr-auth")
 		capabilities = appendNameOnlyAttribute(capabilities, attributePrefix+"execution-role-ecr-pull")
 	}
 
-	if _, ok := supportedVersions[dockerclient.Version_1_24]; ok && !agent.cfg.DisableDockerHealthCheck {
+	if negotiatedVersion.Supports(dockerclient.HealthCheckFeature) && !agent.cfg.DisableDockerHealthCheck {
 		// Docker health check was added in API 1.24
 		capabilities = appendNameOnlyAttribute(capabilities, attributePrefix+"container-health-check")
 	}
@@ -227,12 +260,12 @@ func (agent *ecsAgent) appendLoggingDriverCapabilities(capabilities []*ecs.Attri
 	return capabilities
 }
 
-func (agent *ecsAgent) appendTaskIamRoleCapabilities(capabilities []*ecs.Attribute, supportedVersions map[dockerclient.DockerVersion]bool) []*ecs.Attribute {
+func (agent *ecsAgent) appendTaskIamRoleCapabilities(capabilities []*ecs.Attribute, negotiatedVersion dockerclient.DockerVersion) []*ecs.Attribute {
 	if agent.cfg.TaskIAMRoleEnabled {
 		// The "task-iam-role" capability is supported for docker v1.7.x onwards
 		// Refer https://github.com/docker/docker/blob/master/docs/reference/api/docker_remote_api.md
 		// to lookup the table of docker supportedVersions to API supportedVersions
-		if _, ok := supportedVersions[dockerclient.Version_1_19]; ok {
+		if negotiatedVersion.Supports(dockerclient.TaskIAMRoleFeature) {
 			capabilities = appendNameOnlyAttribute(capabilities, capabilityPrefix+capabilityTaskIAMRole)
 		} else {
 			seelog.Warn("Task IAM Role not enabled due to unsuppported Docker version")
@@ -241,7 +274,7 @@ func (agent *ecsAgent) appendTaskIamRoleCapabilities(capabilities []*ecs.Attribu
 
 	if agent.cfg.TaskIAMRoleEnabledForNetworkHost {
 		// The "task-iam-role-network-host" capability is supported for docker v1.7.x onwards
-		if _, ok := supportedVersions[dockerclient.Version_1_19]; ok {
+		if negotiatedVersion.Supports(dockerclient.TaskIAMRoleFeature) {
 			capabilities = appendNameOnlyAttribute(capabilities, capabilityPrefix+capabilityTaskIAMRoleNetHost)
 		} else {
 			seelog.Warn("Task IAM Role for Host Network not enabled due to unsuppported Docker version")
@@ -250,9 +283,9 @@ func (agent *ecsAgent) appendTaskIamRoleCapabilities(capabilities []*ecs.Attribu
 	return capabilities
 }
 
-func (agent *ecsAgent) appendTaskCPUMemLimitCapabilities(capabilities []*ecs.Attribute, supportedVersions map[dockerclient.DockerVersion]bool) ([]*ecs.Attribute, error) {
+func (agent *ecsAgent) appendTaskCPUMemLimitCapabilities(capabilities []*ecs.Attribute, negotiatedVersion dockerclient.DockerVersion) ([]*ecs.Attribute, error) {
 	if agent.cfg.TaskCPUMemLimit.Enabled() {
-		if _, ok := supportedVersions[dockerclient.Version_1_22]; ok {
+		if negotiatedVersion.Supports(dockerclient.TaskCPUMemLimitFeature) {
 			capabilities = appendNameOnlyAttribute(capabilities, attributePrefix+capabilityTaskCPUMemLimit)
 		} else if agent.cfg.TaskCPUMemLimit == config.ExplicitlyEnabled {
 			// explicitly enabled -- return an error because we cannot fulfil an explicit request
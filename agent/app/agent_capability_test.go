@@ -30,6 +30,7 @@ import (
 	mock_dockerapi "github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi/mocks"
 	"github.com/aws/amazon-ecs-agent/agent/ecscni"
 	mock_ecscni "github.com/aws/amazon-ecs-agent/agent/ecscni/mocks"
+	"github.com/aws/amazon-ecs-agent/agent/experiments"
 	mock_mobypkgwrapper "github.com/aws/amazon-ecs-agent/agent/utils/mobypkgwrapper/mocks"
 	"github.com/aws/aws-sdk-go/aws"
 	aws_credentials "github.com/aws/aws-sdk-go/aws/credentials"
@@ -63,6 +64,7 @@ func TestCapabilities(t *testing.T) {
 	}
 	// Scan() and ListPluginsWithFilters() are tested with
 	// AnyTimes() because they are not called in windows.
+	cniClient.EXPECT().Capabilities(ecscni.ECSBandwidthPluginName).AnyTimes().Return(nil, errors.New("plugin not found"))
 	gomock.InOrder(
 		client.EXPECT().SupportedVersions().Return([]dockerclient.DockerVersion{
 			dockerclient.Version_1_17,
@@ -439,6 +441,7 @@ func TestCapabilitiesExecutionRoleAWSLogs(t *testing.T) {
 	})
 	client.EXPECT().KnownVersions().Return(nil)
 	cniClient.EXPECT().Version(ecscni.ECSENIPluginName).Return("v1", errors.New("some error happened"))
+	cniClient.EXPECT().Capabilities(ecscni.ECSBandwidthPluginName).AnyTimes().Return(nil, errors.New("plugin not found"))
 	mockMobyPlugins.EXPECT().Scan().AnyTimes().Return([]string{}, nil)
 	client.EXPECT().ListPluginsWithFilters(gomock.Any(), gomock.Any(), gomock.Any(),
 		gomock.Any()).AnyTimes().Return([]string{}, nil)
@@ -711,3 +714,15 @@ func TestCapabilitesScanPluginsErrorCase(t *testing.T) {
 		}
 	}
 }
+
+func TestCapabilitiesExperiments(t *testing.T) {
+	agent := &ecsAgent{
+		cfg: &config.Config{
+			EnabledExperiments: []string{experiments.LazyPull},
+		},
+	}
+
+	capabilities := agent.appendExperimentCapabilities(nil)
+	require.Len(t, capabilities, 1)
+	assert.Equal(t, "ecs.capability.experiment.lazy-pull", aws.StringValue(capabilities[0].Name))
+}
@@ -16,6 +16,7 @@
 package app
 
 import (
+	"os"
 	"strings"
 
 	"github.com/aws/amazon-ecs-agent/agent/config"
@@ -23,10 +24,14 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi"
 	"github.com/aws/amazon-ecs-agent/agent/ecs_client/model/ecs"
 	"github.com/aws/amazon-ecs-agent/agent/ecscni"
+	"github.com/aws/amazon-ecs-agent/agent/engine"
+	"github.com/aws/amazon-ecs-agent/agent/engine/execcmd"
+	taskresourceefs "github.com/aws/amazon-ecs-agent/agent/taskresource/efs"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource/volume"
 	"github.com/aws/amazon-ecs-agent/agent/utils"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/cihub/seelog"
+	"github.com/docker/docker/pkg/system"
 )
 
 const (
@@ -37,6 +42,18 @@ const (
 	CpuInfoPath = "/proc/cpuinfo"
 )
 
+// execCommandAgentBinDir is where the execute command agent is installed on
+// the host. It's a variable so that it can be overridden in tests
+var execCommandAgentBinDir = execcmd.HostBinDir
+
+// efsMountHelperPath is where the efs mount helper is installed. It's a
+// variable so that it can be overridden in tests
+var efsMountHelperPath = taskresourceefs.MountHelperPath
+
+// readMemInfo reads the memory info of the host. It's a variable so that it
+// can be overridden in tests
+var readMemInfo = system.ReadMemInfo
+
 func (agent *ecsAgent) appendVolumeDriverCapabilities(capabilities []*ecs.Attribute) []*ecs.Attribute {
 	// "local" is default docker driver
 	capabilities = appendNameOnlyAttribute(capabilities, attributePrefix+capabilityDockerPluginInfix+volume.DockerLocalVolumeDriver)
@@ -161,3 +178,82 @@ func (agent *ecsAgent) appendFirelensConfigCapabilities(capabilities []*ecs.Attr
 	capabilities = appendNameOnlyAttribute(capabilities, attributePrefix+capabilityFirelensConfigFile)
 	return appendNameOnlyAttribute(capabilities, attributePrefix+capabilityFirelensConfigS3)
 }
+
+func (agent *ecsAgent) appendExecCapabilities(capabilities []*ecs.Attribute) []*ecs.Attribute {
+	if _, err := execcmd.InstalledVersion(execCommandAgentBinDir); err != nil {
+		seelog.Infof("Execute command agent is not installed, not registering the %s capability: %v",
+			capabilityExecuteCommand, err)
+		return capabilities
+	}
+	return appendNameOnlyAttribute(capabilities, attributePrefix+capabilityExecuteCommand)
+}
+
+func (agent *ecsAgent) appendEFSCapabilities(capabilities []*ecs.Attribute) []*ecs.Attribute {
+	if _, err := os.Stat(efsMountHelperPath); err != nil {
+		seelog.Infof("EFS mount helper is not installed, not registering the %s capability: %v",
+			capabilityEFS, err)
+		return capabilities
+	}
+	return appendNameOnlyAttribute(capabilities, attributePrefix+capabilityEFS)
+}
+
+func (agent *ecsAgent) appendInitProcessCapabilities(capabilities []*ecs.Attribute,
+	negotiatedVersion dockerclient.DockerVersion) []*ecs.Attribute {
+	// Docker supports running an init process in containers since API 1.25
+	if !negotiatedVersion.Supports(dockerclient.InitProcessFeature) {
+		return capabilities
+	}
+	return appendNameOnlyAttribute(capabilities, attributePrefix+capabilityInitProcess)
+}
+
+// appendSwapCapabilities registers the container swap capability when the host
+// has swap, so that tasks whose containers require swap are only placed on
+// instances that have some
+func (agent *ecsAgent) appendSwapCapabilities(capabilities []*ecs.Attribute) []*ecs.Attribute {
+	memInfo, err := readMemInfo()
+	if err != nil {
+		seelog.Warnf("Unable to read the swap of the host, not registering the %s capability: %v",
+			capabilityContainerSwap, err)
+		return capabilities
+	}
+	if memInfo.SwapTotal == 0 {
+		return capabilities
+	}
+	return appendNameOnlyAttribute(capabilities, attributePrefix+capabilityContainerSwap)
+}
+
+// appendEphemeralStorageCapabilities registers the task ephemeral storage
+// capability when it's enabled and the storage of docker supports quotas, so
+// that tasks with an ephemeral storage size are only placed on instances that
+// can enforce it
+func (agent *ecsAgent) appendEphemeralStorageCapabilities(capabilities []*ecs.Attribute) []*ecs.Attribute {
+	if !agent.cfg.TaskEphemeralStorageEnabled {
+		return capabilities
+	}
+	if err := engine.CheckEphemeralStorageSupport(agent.ctx, agent.dockerClient); err != nil {
+		seelog.Infof("Docker doesn't support storage quotas, not registering the %s capability: %v",
+			capabilityEphemeralStorage, err)
+		return capabilities
+	}
+	return appendNameOnlyAttribute(capabilities, attributePrefix+capabilityEphemeralStorage)
+}
+
+// appendTaskNetworkBandwidthCapabilities registers the task network bandwidth
+// capability when awsvpc is enabled and the bandwidth plugin is installed, so
+// that tasks with a network bandwidth are only placed on instances that can
+// shape their traffic
+func (agent *ecsAgent) appendTaskNetworkBandwidthCapabilities(capabilities []*ecs.Attribute) []*ecs.Attribute {
+	if !agent.cfg.TaskENIEnabled {
+		return capabilities
+	}
+	pluginCapabilities, err := agent.cniClient.Capabilities(ecscni.ECSBandwidthPluginName)
+	if err != nil {
+		seelog.Infof("Unable to query the capabilities of the plugin '%s', not registering the %s capability: %v",
+			ecscni.ECSBandwidthPluginName, capabilityTaskNetworkBandwidth, err)
+		return capabilities
+	}
+	if !contains(pluginCapabilities, ecscni.CapabilityTaskBandwidth) {
+		return capabilities
+	}
+	return appendNameOnlyAttribute(capabilities, attributePrefix+capabilityTaskNetworkBandwidth)
+}
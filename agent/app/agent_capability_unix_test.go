@@ -17,6 +17,8 @@ package app
 
 import (
 	"context"
+	"errors"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"testing"
@@ -34,8 +36,11 @@ import (
 	mock_mobypkgwrapper "github.com/aws/amazon-ecs-agent/agent/utils/mobypkgwrapper/mocks"
 	"github.com/aws/aws-sdk-go/aws"
 	aws_credentials "github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/system"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestVolumeDriverCapabilitiesUnix(t *testing.T) {
@@ -62,6 +67,7 @@ func TestVolumeDriverCapabilitiesUnix(t *testing.T) {
 		TaskCleanupWaitDuration:    config.DefaultConfig().TaskCleanupWaitDuration,
 	}
 
+	cniClient.EXPECT().Capabilities(ecscni.ECSBandwidthPluginName).Return(nil, errors.New("plugin not found"))
 	gomock.InOrder(
 		client.EXPECT().SupportedVersions().Return([]dockerclient.DockerVersion{
 			dockerclient.Version_1_17,
@@ -309,6 +315,7 @@ func TestENITrunkingCapabilitiesUnix(t *testing.T) {
 		ENITrunkingEnabled: true,
 	}
 
+	cniClient.EXPECT().Capabilities(ecscni.ECSBandwidthPluginName).Return(nil, errors.New("plugin not found"))
 	gomock.InOrder(
 		client.EXPECT().SupportedVersions().Return([]dockerclient.DockerVersion{
 			dockerclient.Version_1_17,
@@ -395,6 +402,7 @@ func TestNoENITrunkingCapabilitiesUnix(t *testing.T) {
 		ENITrunkingEnabled: false,
 	}
 
+	cniClient.EXPECT().Capabilities(ecscni.ECSBandwidthPluginName).Return(nil, errors.New("plugin not found"))
 	gomock.InOrder(
 		client.EXPECT().SupportedVersions().Return([]dockerclient.DockerVersion{
 			dockerclient.Version_1_17,
@@ -860,3 +868,91 @@ func TestFirelensConfigCapabilitiesUnix(t *testing.T) {
 	assert.Contains(t, capabilities, &ecs.Attribute{Name: aws.String(attributePrefix + capabilityFirelensConfigFile)})
 	assert.Contains(t, capabilities, &ecs.Attribute{Name: aws.String(attributePrefix + capabilityFirelensConfigS3)})
 }
+
+func TestEFSCapabilitiesUnix(t *testing.T) {
+	dir, err := ioutil.TempDir("", "efs-capability")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	defer func(path string) {
+		efsMountHelperPath = path
+	}(efsMountHelperPath)
+
+	agent := &ecsAgent{}
+	efsMountHelperPath = filepath.Join(dir, "mount.efs")
+	assert.NotContains(t, agent.appendEFSCapabilities(nil),
+		&ecs.Attribute{Name: aws.String(attributePrefix + capabilityEFS)})
+
+	require.NoError(t, ioutil.WriteFile(efsMountHelperPath, []byte{}, 0755))
+	assert.Contains(t, agent.appendEFSCapabilities(nil),
+		&ecs.Attribute{Name: aws.String(attributePrefix + capabilityEFS)})
+}
+
+func TestInitProcessCapabilitiesUnix(t *testing.T) {
+	agent := &ecsAgent{}
+	assert.NotContains(t, agent.appendInitProcessCapabilities(nil, dockerclient.Version_1_24),
+		&ecs.Attribute{Name: aws.String(attributePrefix + capabilityInitProcess)})
+	assert.Contains(t, agent.appendInitProcessCapabilities(nil, dockerclient.Version_1_25),
+		&ecs.Attribute{Name: aws.String(attributePrefix + capabilityInitProcess)})
+}
+
+func TestAppendSwapCapabilities(t *testing.T) {
+	defer func() {
+		readMemInfo = system.ReadMemInfo
+	}()
+	agent := &ecsAgent{}
+
+	readMemInfo = func() (*system.MemInfo, error) {
+		return &system.MemInfo{}, nil
+	}
+	assert.NotContains(t, agent.appendSwapCapabilities(nil),
+		&ecs.Attribute{Name: aws.String(attributePrefix + capabilityContainerSwap)})
+
+	readMemInfo = func() (*system.MemInfo, error) {
+		return &system.MemInfo{SwapTotal: 1024 * 1024 * 1024}, nil
+	}
+	assert.Contains(t, agent.appendSwapCapabilities(nil),
+		&ecs.Attribute{Name: aws.String(attributePrefix + capabilityContainerSwap)})
+}
+
+func TestAppendEphemeralStorageCapabilities(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock_dockerapi.NewMockDockerClient(ctrl)
+	agent := &ecsAgent{
+		ctx:          context.TODO(),
+		cfg:          &config.Config{},
+		dockerClient: client,
+	}
+	capability := &ecs.Attribute{Name: aws.String(attributePrefix + capabilityEphemeralStorage)}
+
+	// Docker isn't asked when ephemeral storage limits aren't enabled
+	assert.NotContains(t, agent.appendEphemeralStorageCapabilities(nil), capability)
+
+	agent.cfg.TaskEphemeralStorageEnabled = true
+	client.EXPECT().Info(gomock.Any(), gomock.Any()).Return(types.Info{Driver: "devicemapper"}, nil)
+	assert.NotContains(t, agent.appendEphemeralStorageCapabilities(nil), capability)
+}
+
+func TestAppendTaskNetworkBandwidthCapabilities(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cniClient := mock_ecscni.NewMockCNIClient(ctrl)
+	agent := &ecsAgent{
+		cfg:       &config.Config{},
+		cniClient: cniClient,
+	}
+	capability := &ecs.Attribute{Name: aws.String(attributePrefix + capabilityTaskNetworkBandwidth)}
+
+	// The plugin isn't asked when awsvpc isn't enabled
+	assert.NotContains(t, agent.appendTaskNetworkBandwidthCapabilities(nil), capability)
+
+	agent.cfg.TaskENIEnabled = true
+	gomock.InOrder(
+		cniClient.EXPECT().Capabilities(ecscni.ECSBandwidthPluginName).Return(nil, errors.New("plugin not found")),
+		cniClient.EXPECT().Capabilities(ecscni.ECSBandwidthPluginName).Return([]string{ecscni.CapabilityAWSVPCNetworkingMode}, nil),
+		cniClient.EXPECT().Capabilities(ecscni.ECSBandwidthPluginName).Return([]string{ecscni.CapabilityTaskBandwidth}, nil),
+	)
+	assert.NotContains(t, agent.appendTaskNetworkBandwidthCapabilities(nil), capability)
+	assert.NotContains(t, agent.appendTaskNetworkBandwidthCapabilities(nil), capability)
+	assert.Contains(t, agent.appendTaskNetworkBandwidthCapabilities(nil), capability)
+}
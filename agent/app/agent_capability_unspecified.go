@@ -101,3 +101,28 @@ func (agent *ecsAgent) appendFirelensLoggingDriverCapabilities(capabilities []*e
 func (agent *ecsAgent) appendFirelensConfigCapabilities(capabilities []*ecs.Attribute) []*ecs.Attribute {
 	return capabilities
 }
+
+func (agent *ecsAgent) appendExecCapabilities(capabilities []*ecs.Attribute) []*ecs.Attribute {
+	return capabilities
+}
+
+func (agent *ecsAgent) appendEFSCapabilities(capabilities []*ecs.Attribute) []*ecs.Attribute {
+	return capabilities
+}
+
+func (agent *ecsAgent) appendInitProcessCapabilities(capabilities []*ecs.Attribute,
+	negotiatedVersion dockerclient.DockerVersion) []*ecs.Attribute {
+	return capabilities
+}
+
+func (agent *ecsAgent) appendSwapCapabilities(capabilities []*ecs.Attribute) []*ecs.Attribute {
+	return capabilities
+}
+
+func (agent *ecsAgent) appendEphemeralStorageCapabilities(capabilities []*ecs.Attribute) []*ecs.Attribute {
+	return capabilities
+}
+
+func (agent *ecsAgent) appendTaskNetworkBandwidthCapabilities(capabilities []*ecs.Attribute) []*ecs.Attribute {
+	return capabilities
+}
@@ -272,6 +272,33 @@ func TestDoStartRegisterContainerInstanceErrorTerminal(t *testing.T) {
 	assert.Equal(t, exitcodes.ExitTerminal, exitCode)
 }
 
+func TestDoStartPreflightChecksFailure(t *testing.T) {
+	ctrl, credentialsManager, state, imageManager, client,
+		dockerClient, _, _ := setup(t)
+	defer ctrl.Finish()
+	mockCredentialsProvider := app_mocks.NewMockProvider(ctrl)
+
+	dockerClient.EXPECT().Version(gomock.Any(), gomock.Any()).Return("", errors.New("connection refused"))
+	mockCredentialsProvider.EXPECT().Retrieve().Return(aws_credentials.Value{}, errors.New("no credentials"))
+
+	cfg := getTestConfig()
+	cfg.PreflightChecksDisabled = false
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	agent := &ecsAgent{
+		ctx:                ctx,
+		cfg:                &cfg,
+		credentialProvider: aws_credentials.NewCredentials(mockCredentialsProvider),
+		dockerClient:       dockerClient,
+	}
+
+	// All the checks run, and the exit code is the one of the first check
+	// that failed
+	exitCode := agent.doStart(eventstream.NewEventStream("events", ctx),
+		credentialsManager, state, imageManager, client)
+	assert.Equal(t, exitcodes.ExitPreflightDocker, exitCode)
+}
+
 func TestDoStartRegisterContainerInstanceErrorNonTerminal(t *testing.T) {
 	ctrl, credentialsManager, state, imageManager, client,
 		dockerClient, _, _ := setup(t)
@@ -329,6 +356,7 @@ func TestDoStartRegisterAvailabilityZone(t *testing.T) {
 	mockCredentialsProvider := app_mocks.NewMockProvider(ctrl)
 	containermetadata := mock_containermetadata.NewMockManager(ctrl)
 	imageManager.EXPECT().StartImageCleanupProcess(gomock.Any()).MaxTimes(1)
+	imageManager.EXPECT().StartImageEventsListener(gomock.Any()).MaxTimes(1)
 	dockerClient.EXPECT().ListContainers(gomock.Any(), gomock.Any(), gomock.Any()).Return(
 		dockerapi.ListContainersResponse{}).AnyTimes()
 	client.EXPECT().DiscoverPollEndpoint(gomock.Any()).Do(func(x interface{}) {
@@ -1280,7 +1308,7 @@ func TestSpotInstanceActionCheck_Sunny(t *testing.T) {
 		ec2MetadataClient.EXPECT().SpotInstanceAction().Return(test.jsonresp, nil)
 		ecsClient.EXPECT().UpdateContainerInstancesState(myARN, "DRAINING").Return(nil)
 
-		assert.True(t, agent.spotInstanceDrainingPoller(ecsClient))
+		assert.Equal(t, spotInterruption, agent.spotInstanceDrainingPoller(ecsClient, nil, noInterruption))
 	}
 }
 
@@ -1307,10 +1335,11 @@ func TestSpotInstanceActionCheck_Fail(t *testing.T) {
 			containerInstanceARN: myARN,
 		}
 		ec2MetadataClient.EXPECT().SpotInstanceAction().Return(test.jsonresp, nil)
+		ec2MetadataClient.EXPECT().TargetLifecycleState().Return("", fmt.Errorf("404"))
 		// Container state should NOT be updated because the termination time field is empty.
 		ecsClient.EXPECT().UpdateContainerInstancesState(gomock.Any(), gomock.Any()).Times(0)
 
-		assert.False(t, agent.spotInstanceDrainingPoller(ecsClient))
+		assert.Equal(t, noInterruption, agent.spotInstanceDrainingPoller(ecsClient, nil, noInterruption))
 	}
 }
 
@@ -1329,15 +1358,109 @@ func TestSpotInstanceActionCheck_NoInstanceActionYet(t *testing.T) {
 		containerInstanceARN: myARN,
 	}
 	ec2MetadataClient.EXPECT().SpotInstanceAction().Return("", fmt.Errorf("404"))
+	ec2MetadataClient.EXPECT().TargetLifecycleState().Return("InService", nil)
 
 	// Container state should NOT be updated because there is no termination time.
 	ecsClient.EXPECT().UpdateContainerInstancesState(gomock.Any(), gomock.Any()).Times(0)
 
-	assert.False(t, agent.spotInstanceDrainingPoller(ecsClient))
+	assert.Equal(t, noInterruption, agent.spotInstanceDrainingPoller(ecsClient, nil, noInterruption))
+}
+
+func TestAutoScalingTerminationCheck(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ec2MetadataClient := mock_ec2.NewMockEC2MetadataClient(ctrl)
+	ecsClient := mock_api.NewMockECSClient(ctrl)
+	taskEngine := mock_engine.NewMockTaskEngine(ctrl)
+	drainer := &mockInstanceDrainer{TaskEngine: taskEngine}
+
+	myARN := "myARN"
+	agent := &ecsAgent{
+		ec2MetadataClient:    ec2MetadataClient,
+		containerInstanceARN: myARN,
+	}
+	ec2MetadataClient.EXPECT().SpotInstanceAction().Return("", fmt.Errorf("404")).Times(2)
+	ec2MetadataClient.EXPECT().TargetLifecycleState().Return("Terminated", nil).Times(2)
+	gomock.InOrder(
+		ecsClient.EXPECT().UpdateContainerInstancesState(myARN, "DRAINING").Return(errors.New("error")),
+		ecsClient.EXPECT().UpdateContainerInstancesState(myARN, "DRAINING").Return(nil),
+	)
+
+	// The engine rejects new tasks even if the instance state couldn't be
+	// updated yet
+	assert.Equal(t, noInterruption, agent.spotInstanceDrainingPoller(ecsClient, drainer, noInterruption))
+	assert.Equal(t, "Auto scaling group is terminating the instance", drainer.reason)
+	assert.Equal(t, autoScalingTermination, agent.spotInstanceDrainingPoller(ecsClient, drainer, noInterruption))
+}
+
+func TestAutoScalingWarmPoolCheck(t *testing.T) {
+	for _, state := range []string{"Warmed:Stopped", "Warmed:Hibernated", "Warmed:Running"} {
+		t.Run(state, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			ec2MetadataClient := mock_ec2.NewMockEC2MetadataClient(ctrl)
+			ecsClient := mock_api.NewMockECSClient(ctrl)
+			agent := &ecsAgent{
+				ec2MetadataClient:    ec2MetadataClient,
+				containerInstanceARN: "myARN",
+			}
+			ec2MetadataClient.EXPECT().SpotInstanceAction().Return("", fmt.Errorf("404"))
+			ec2MetadataClient.EXPECT().TargetLifecycleState().Return(state, nil)
+			ecsClient.EXPECT().UpdateContainerInstancesState(gomock.Any(), gomock.Any()).Times(0)
+
+			assert.Equal(t, noInterruption, agent.spotInstanceDrainingPoller(ecsClient, nil, noInterruption))
+		})
+	}
+}
+
+func TestAutoScalingTerminationCalledOff(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ec2MetadataClient := mock_ec2.NewMockEC2MetadataClient(ctrl)
+	ecsClient := mock_api.NewMockECSClient(ctrl)
+	taskEngine := mock_engine.NewMockTaskEngine(ctrl)
+	drainer := &mockInstanceDrainer{TaskEngine: taskEngine, reason: "Auto scaling group is terminating the instance"}
+
+	myARN := "myARN"
+	agent := &ecsAgent{
+		ec2MetadataClient:    ec2MetadataClient,
+		containerInstanceARN: myARN,
+	}
+	gomock.InOrder(
+		ec2MetadataClient.EXPECT().TargetLifecycleState().Return("Terminated", nil),
+		ec2MetadataClient.EXPECT().TargetLifecycleState().Return("InService", nil),
+	)
+	ecsClient.EXPECT().UpdateContainerInstancesState(myARN, "ACTIVE").Return(nil)
+
+	assert.Equal(t, autoScalingTermination, agent.spotInstanceDrainingPoller(ecsClient, drainer, autoScalingTermination))
+	assert.Equal(t, noInterruption, agent.spotInstanceDrainingPoller(ecsClient, drainer, autoScalingTermination))
+	assert.Empty(t, drainer.reason)
+
+	// Spot interruptions aren't called off
+	assert.Equal(t, spotInterruption, agent.spotInstanceDrainingPoller(ecsClient, drainer, spotInterruption))
+}
+
+type mockInstanceDrainer struct {
+	engine.TaskEngine
+	reason string
+}
+
+func (drainer *mockInstanceDrainer) RejectNewTasks(reason string) {
+	drainer.reason = reason
+}
+
+func (drainer *mockInstanceDrainer) AcceptNewTasks() {
+	drainer.reason = ""
 }
 
 func getTestConfig() config.Config {
 	cfg := config.DefaultConfig()
 	cfg.TaskCPUMemLimit = config.ExplicitlyDisabled
+	// The preflight checks are tested on their own, they'd otherwise need
+	// to be mocked by every test starting the agent
+	cfg.PreflightChecksDisabled = true
 	return cfg
 }
@@ -17,6 +17,7 @@ package app
 
 import (
 	"fmt"
+	"time"
 
 	asmfactory "github.com/aws/amazon-ecs-agent/agent/asm/factory"
 	"github.com/aws/amazon-ecs-agent/agent/config"
@@ -29,8 +30,11 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/eni/udevwrapper"
 	"github.com/aws/amazon-ecs-agent/agent/eni/watcher"
 	"github.com/aws/amazon-ecs-agent/agent/gpu"
+	"github.com/aws/amazon-ecs-agent/agent/preflight"
+	"github.com/aws/amazon-ecs-agent/agent/secretprovider"
 	ssmfactory "github.com/aws/amazon-ecs-agent/agent/ssm/factory"
 	"github.com/aws/amazon-ecs-agent/agent/statechange"
+	"github.com/aws/amazon-ecs-agent/agent/stats"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource"
 	cgroup "github.com/aws/amazon-ecs-agent/agent/taskresource/cgroup/control"
 	"github.com/aws/amazon-ecs-agent/agent/utils/ioutilwrapper"
@@ -39,8 +43,17 @@ import (
 	"github.com/pkg/errors"
 )
 
-// initPID defines the process identifier for the init process
-const initPID = 1
+const (
+	// initPID defines the process identifier for the init process
+	initPID = 1
+	// ipForwardPath is the sysctl that enables IP forwarding
+	ipForwardPath = "/proc/sys/net/ipv4/ip_forward"
+	// minimumDataDirFreeBytes is the disk space the agent needs to save its
+	// state in the data directory
+	minimumDataDirFreeBytes = 100 * 1024 * 1024
+	// gpuStatsSamplingInterval is how often the usage of the GPUs is sampled
+	gpuStatsSamplingInterval = 5 * time.Second
+)
 
 // awsVPCCNIPlugins is a list of CNI plugins required by the ECS Agent
 // to configure the ENI for a task
@@ -61,7 +74,8 @@ func (agent *ecsAgent) startWindowsService() int {
 // the Agent to support the 'awsvpc' networking mode. A non nil error is returned
 // if an error is encountered during this process. An additional boolean flag to
 // indicate if this error is considered terminal is also returned
-func (agent *ecsAgent) initializeTaskENIDependencies(state dockerstate.TaskEngineState, taskEngine engine.TaskEngine) (error, bool) {
+func (agent *ecsAgent) initializeTaskENIDependencies(state dockerstate.TaskEngineState, taskEngine engine.TaskEngine,
+	imageManager engine.ImageManager) (error, bool) {
 	// Check if the Agent process's pid  == 1, which means it's running without an init system
 	if agent.os.Getpid() == initPID {
 		// This is a terminal error. Bad things happen with invoking the
@@ -85,8 +99,15 @@ func (agent *ecsAgent) initializeTaskENIDependencies(state dockerstate.TaskEngin
 		return err, true
 	}
 
+	// Validate that the CNI plugins aren't older than the agent supports
+	if err := agent.verifyCNIPluginsVersions(); err != nil {
+		seelog.Errorf("Unable to verify the versions of the CNI plugins: %v", err)
+		return unsupportedCNIPluginsError, false
+	}
+
 	// Load the pause container's image from the 'disk'
-	if _, err := agent.pauseLoader.LoadImage(agent.ctx, agent.cfg, agent.dockerClient); err != nil {
+	pauseImage, err := agent.pauseLoader.LoadImage(agent.ctx, agent.cfg, agent.dockerClient)
+	if err != nil {
 		if pause.IsNoSuchFileError(err) || pause.UnsupportedPlatform(err) {
 			// If the pause container's image tarball doesn't exist or if the
 			// invocation is done for an unsupported platform, we cannot recover.
@@ -95,6 +116,9 @@ func (agent *ecsAgent) initializeTaskENIDependencies(state dockerstate.TaskEngin
 		}
 		return err, false
 	}
+	// The pause container's image can't be pulled, pin it so that the image
+	// cleanup never removes it
+	imageManager.ExcludeImagesFromCleanup(pauseImage.RepoTags)
 
 	if err := agent.startUdevWatcher(state, taskEngine.StateChangeEvents()); err != nil {
 		// If udev watcher was not initialized in this run because of the udev socket
@@ -150,7 +174,10 @@ func isInstanceLaunchedInVPC(err error) bool {
 // c. ecs-ipam
 // d. aws-appmesh
 // e. vpc-branch-eni
+// It also records whether the eni plugins can set up the additional ENIs of
+// tasks with several ENIs.
 func (agent *ecsAgent) verifyCNIPluginsCapabilities() error {
+	additionalENIsSupported := true
 	// Check if we can get capabilities from each plugin
 	for _, plugin := range awsVPCCNIPlugins {
 		// skip verifying branch cni plugin if eni trunking is not enabled
@@ -170,8 +197,42 @@ func (agent *ecsAgent) verifyCNIPluginsCapabilities() error {
 			return errors.Errorf("plugin '%s' doesn't support the capability: %s",
 				plugin, ecscni.CapabilityAWSVPCNetworkingMode)
 		}
+		if (plugin == ecscni.ECSENIPluginName || plugin == ecscni.ECSBranchENIPluginName) &&
+			!contains(capabilities, ecscni.CapabilityAdditionalENIs) {
+			additionalENIsSupported = false
+		}
 	}
 
+	if agent.cfg != nil {
+		agent.cfg.CNIAdditionalENIsSupported = additionalENIsSupported
+	}
+	return nil
+}
+
+// verifyCNIPluginsVersions records the versions of the CNI plugins of the
+// awsvpc network mode, and returns an error if there's an error querying them
+// or if any of them is older than the agent supports
+func (agent *ecsAgent) verifyCNIPluginsVersions() error {
+	versions := make(map[string]string)
+	for _, plugin := range awsVPCCNIPlugins {
+		// skip verifying branch cni plugin if eni trunking is not enabled
+		if plugin == ecscni.ECSBranchENIPluginName && agent.cfg != nil && !agent.cfg.ENITrunkingEnabled {
+			continue
+		}
+
+		version, err := agent.cniClient.Version(plugin)
+		if err != nil {
+			return errors.Wrapf(err, "unable to get the version of plugin '%s'", plugin)
+		}
+		seelog.Infof("Detected version %s of CNI plugin '%s'", version, plugin)
+		versions[plugin] = version
+		if err := ecscni.CheckPluginVersion(plugin, version); err != nil {
+			return err
+		}
+	}
+	if agent.cfg != nil {
+		agent.cfg.CNIPluginVersions = versions
+	}
 	return nil
 }
 
@@ -211,6 +272,7 @@ func (agent *ecsAgent) initializeResourceFields(credentialsManager credentials.M
 			IOUtil:             ioutilwrapper.NewIOUtil(),
 			ASMClientCreator:   asmfactory.NewClientCreator(),
 			SSMClientCreator:   ssmfactory.NewSSMClientCreator(),
+			SecretProviders:    secretprovider.NewProviders(agent.cfg),
 			CredentialsManager: credentialsManager,
 			EC2InstanceID:      agent.getEC2InstanceID(),
 		},
@@ -220,6 +282,28 @@ func (agent *ecsAgent) initializeResourceFields(credentialsManager credentials.M
 	}
 }
 
+// platformPreflightChecks returns the checks of the prerequisites of the
+// features enabled on Linux
+func (agent *ecsAgent) platformPreflightChecks() []preflight.Check {
+	var checks []preflight.Check
+	// cgroupInit disables task limits rather than failing when they're only
+	// enabled by default
+	if agent.cfg.TaskCPUMemLimit == config.ExplicitlyEnabled {
+		checks = append(checks, preflight.CgroupCheck(agent.cfg.CgroupPath))
+	}
+	if agent.cfg.TaskENIEnabled {
+		checks = append(checks, preflight.CNIPluginsCheck(agent.cfg.CNIPluginsPath, awsVPCCNIPlugins),
+			preflight.IPForwardingCheck(ipForwardPath))
+	}
+	if agent.cfg.Checkpoint {
+		checks = append(checks, preflight.DiskSpaceCheck(agent.cfg.DataDir, minimumDataDirFreeBytes))
+	}
+	if agent.cfg.MultiTenantIsolationEnabled {
+		checks = append(checks, preflight.UsernsRemapCheck(agent.ctx, agent.dockerClient))
+	}
+	return checks
+}
+
 func (agent *ecsAgent) cgroupInit() error {
 	err := agent.resourceFields.Control.Init()
 	// When task CPU and memory limits are enabled, all tasks are placed
@@ -242,6 +326,16 @@ func (agent *ecsAgent) initializeGPUManager() error {
 	return nil
 }
 
+// startGPUStatsSampling samples the usage of the GPUs, so that the stats engine
+// reports it for the containers they're assigned to
+func (agent *ecsAgent) startGPUStatsSampling(statsEngine *stats.DockerStatsEngine) {
+	if !agent.cfg.GPUSupportEnabled || agent.resourceFields == nil || agent.resourceFields.NvidiaGPUManager == nil {
+		return
+	}
+	go agent.resourceFields.NvidiaGPUManager.StartSampling(agent.ctx, gpuStatsSamplingInterval)
+	statsEngine.SetGPUStatsProvider(agent.resourceFields.NvidiaGPUManager)
+}
+
 func (agent *ecsAgent) getPlatformDevices() []*ecs.PlatformDevice {
 	if agent.cfg.GPUSupportEnabled {
 		if agent.resourceFields != nil && agent.resourceFields.NvidiaGPUManager != nil {
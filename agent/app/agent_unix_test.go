@@ -46,14 +46,16 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/docker/docker/api/types"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 )
 
 const (
-	mac      = "01:23:45:67:89:ab"
-	vpcID    = "vpc-1234"
-	subnetID = "subnet-1234"
+	mac              = "01:23:45:67:89:ab"
+	vpcID            = "vpc-1234"
+	subnetID         = "subnet-1234"
+	cniPluginVersion = "226db36-2019.10.0"
 )
 
 func TestDoStartHappyPath(t *testing.T) {
@@ -72,6 +74,7 @@ func TestDoStartHappyPath(t *testing.T) {
 	dockerClient.EXPECT().Version(gomock.Any(), gomock.Any()).AnyTimes()
 	dockerClient.EXPECT().SupportedVersions().Return(apiVersions)
 	imageManager.EXPECT().StartImageCleanupProcess(gomock.Any()).MaxTimes(1)
+	imageManager.EXPECT().StartImageEventsListener(gomock.Any()).MaxTimes(1)
 	mockCredentialsProvider.EXPECT().IsExpired().Return(false).AnyTimes()
 	dockerClient.EXPECT().ListContainers(gomock.Any(), gomock.Any(), gomock.Any()).Return(
 		dockerapi.ListContainersResponse{}).AnyTimes()
@@ -159,6 +162,7 @@ func TestDoStartTaskENIHappyPath(t *testing.T) {
 	dockerClient.EXPECT().ListContainers(gomock.Any(), gomock.Any(), gomock.Any()).Return(
 		dockerapi.ListContainersResponse{}).AnyTimes()
 	imageManager.EXPECT().StartImageCleanupProcess(gomock.Any()).MaxTimes(1)
+	imageManager.EXPECT().StartImageEventsListener(gomock.Any()).MaxTimes(1)
 	client.EXPECT().DiscoverPollEndpoint(gomock.Any()).Do(func(x interface{}) {
 		// Ensures that the test waits until acs session has bee started
 		discoverEndpointsInvoked.Done()
@@ -182,13 +186,21 @@ func TestDoStartTaskENIHappyPath(t *testing.T) {
 		cniClient.EXPECT().Capabilities(ecscni.ECSIPAMPluginName).Return(cniCapabilities, nil),
 		cniClient.EXPECT().Capabilities(ecscni.ECSAppMeshPluginName).Return(cniCapabilities, nil),
 		cniClient.EXPECT().Capabilities(ecscni.ECSBranchENIPluginName).Return(cniCapabilities, nil),
-		mockPauseLoader.EXPECT().LoadImage(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil),
+		cniClient.EXPECT().Version(ecscni.ECSENIPluginName).Return(cniPluginVersion, nil),
+		cniClient.EXPECT().Version(ecscni.ECSBridgePluginName).Return(cniPluginVersion, nil),
+		cniClient.EXPECT().Version(ecscni.ECSIPAMPluginName).Return(cniPluginVersion, nil),
+		cniClient.EXPECT().Version(ecscni.ECSAppMeshPluginName).Return(cniPluginVersion, nil),
+		cniClient.EXPECT().Version(ecscni.ECSBranchENIPluginName).Return(cniPluginVersion, nil),
+		mockPauseLoader.EXPECT().LoadImage(gomock.Any(), gomock.Any(), gomock.Any()).Return(
+			&types.ImageInspect{RepoTags: []string{config.CachedImageNamePauseContainer}}, nil),
+		imageManager.EXPECT().ExcludeImagesFromCleanup([]string{config.CachedImageNamePauseContainer}),
 		state.EXPECT().ENIByMac(gomock.Any()).Return(nil, false).AnyTimes(),
 		mockCredentialsProvider.EXPECT().Retrieve().Return(credentials.Value{}, nil),
 		dockerClient.EXPECT().SupportedVersions().Return(nil),
 		dockerClient.EXPECT().KnownVersions().Return(nil),
 		cniClient.EXPECT().Version(ecscni.ECSENIPluginName).Return("v1", nil),
 		cniClient.EXPECT().Version(ecscni.ECSBranchENIPluginName).Return("v2", nil),
+		cniClient.EXPECT().Capabilities(ecscni.ECSBandwidthPluginName).Return(cniCapabilities, nil),
 		mockMobyPlugins.EXPECT().Scan().Return([]string{}, nil),
 		dockerClient.EXPECT().ListPluginsWithFilters(gomock.Any(), gomock.Any(), gomock.Any(),
 			gomock.Any()).Return([]string{}, nil),
@@ -355,6 +367,41 @@ func TestQueryCNIPluginsCapabilitiesHappyPath(t *testing.T) {
 	assert.NoError(t, agent.verifyCNIPluginsCapabilities())
 }
 
+func TestQueryCNIPluginsCapabilitiesAdditionalENIs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cniCapabilities := []string{ecscni.CapabilityAWSVPCNetworkingMode}
+	eniCapabilities := []string{ecscni.CapabilityAWSVPCNetworkingMode, ecscni.CapabilityAdditionalENIs}
+	cniClient := mock_ecscni.NewMockCNIClient(ctrl)
+	gomock.InOrder(
+		cniClient.EXPECT().Capabilities(ecscni.ECSENIPluginName).Return(eniCapabilities, nil),
+		cniClient.EXPECT().Capabilities(ecscni.ECSBridgePluginName).Return(cniCapabilities, nil),
+		cniClient.EXPECT().Capabilities(ecscni.ECSIPAMPluginName).Return(cniCapabilities, nil),
+		cniClient.EXPECT().Capabilities(ecscni.ECSAppMeshPluginName).Return(cniCapabilities, nil),
+		cniClient.EXPECT().Capabilities(ecscni.ECSBranchENIPluginName).Return(cniCapabilities, nil),
+	)
+	agent := &ecsAgent{
+		cniClient: cniClient,
+		cfg: &config.Config{
+			ENITrunkingEnabled: true,
+		},
+	}
+	assert.NoError(t, agent.verifyCNIPluginsCapabilities())
+	// The branch eni plugin can't set up additional ENIs
+	assert.False(t, agent.cfg.CNIAdditionalENIsSupported)
+
+	gomock.InOrder(
+		cniClient.EXPECT().Capabilities(ecscni.ECSENIPluginName).Return(eniCapabilities, nil),
+		cniClient.EXPECT().Capabilities(ecscni.ECSBridgePluginName).Return(cniCapabilities, nil),
+		cniClient.EXPECT().Capabilities(ecscni.ECSIPAMPluginName).Return(cniCapabilities, nil),
+		cniClient.EXPECT().Capabilities(ecscni.ECSAppMeshPluginName).Return(cniCapabilities, nil),
+	)
+	agent.cfg.ENITrunkingEnabled = false
+	assert.NoError(t, agent.verifyCNIPluginsCapabilities())
+	assert.True(t, agent.cfg.CNIAdditionalENIsSupported)
+}
+
 func TestQueryCNIPluginsCapabilitiesEmptyCapabilityListFromPlugin(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -424,7 +471,7 @@ func TestInitializeTaskENIDependenciesNoInit(t *testing.T) {
 		os: mockOS,
 	}
 
-	err, ok := agent.initializeTaskENIDependencies(state, taskEngine)
+	err, ok := agent.initializeTaskENIDependencies(state, taskEngine, nil)
 	assert.Error(t, err)
 	assert.True(t, ok)
 }
@@ -442,7 +489,7 @@ func TestInitializeTaskENIDependenciesSetVPCSubnetError(t *testing.T) {
 		os:                mockOS,
 		ec2MetadataClient: mockMetadata,
 	}
-	err, ok := agent.initializeTaskENIDependencies(state, taskEngine)
+	err, ok := agent.initializeTaskENIDependencies(state, taskEngine, nil)
 	assert.Error(t, err)
 	assert.False(t, ok)
 }
@@ -465,11 +512,42 @@ func TestInitializeTaskENIDependenciesQueryCNICapabilitiesError(t *testing.T) {
 		ec2MetadataClient: mockMetadata,
 		cniClient:         cniClient,
 	}
-	err, ok := agent.initializeTaskENIDependencies(state, taskEngine)
+	err, ok := agent.initializeTaskENIDependencies(state, taskEngine, nil)
 	assert.Error(t, err)
 	assert.True(t, ok)
 }
 
+func TestInitializeTaskENIDependenciesUnsupportedCNIPluginVersion(t *testing.T) {
+	ctrl, state, taskEngine, mockOS := setupMocksForInitializeTaskENIDependencies(t)
+	defer ctrl.Finish()
+
+	mockMetadata := mock_ec2.NewMockEC2MetadataClient(ctrl)
+	cniClient := mock_ecscni.NewMockCNIClient(ctrl)
+	cniCapabilities := []string{ecscni.CapabilityAWSVPCNetworkingMode}
+	gomock.InOrder(
+		mockOS.EXPECT().Getpid().Return(10),
+		mockMetadata.EXPECT().PrimaryENIMAC().Return(mac, nil),
+		mockMetadata.EXPECT().VPCID(mac).Return(vpcID, nil),
+		mockMetadata.EXPECT().SubnetID(mac).Return(subnetID, nil),
+		cniClient.EXPECT().Capabilities(ecscni.ECSENIPluginName).Return(cniCapabilities, nil),
+		cniClient.EXPECT().Capabilities(ecscni.ECSBridgePluginName).Return(cniCapabilities, nil),
+		cniClient.EXPECT().Capabilities(ecscni.ECSIPAMPluginName).Return(cniCapabilities, nil),
+		cniClient.EXPECT().Capabilities(ecscni.ECSAppMeshPluginName).Return(cniCapabilities, nil),
+		cniClient.EXPECT().Version(ecscni.ECSENIPluginName).Return("226db36-2017.06.0", nil),
+	)
+	cfg := getTestConfig()
+	agent := &ecsAgent{
+		os:                mockOS,
+		ec2MetadataClient: mockMetadata,
+		cniClient:         cniClient,
+		cfg:               &cfg,
+	}
+	err, ok := agent.initializeTaskENIDependencies(state, taskEngine, nil)
+	assert.Equal(t, unsupportedCNIPluginsError, err)
+	assert.False(t, ok)
+	assert.Empty(t, cfg.CNIPluginVersions)
+}
+
 func TestInitializeTaskENIDependenciesPauseLoaderError(t *testing.T) {
 	errorsToIsTerminal := map[error]bool{
 		errors.New("error"):                                    false,
@@ -495,6 +573,10 @@ func TestInitializeTaskENIDependenciesPauseLoaderError(t *testing.T) {
 				cniClient.EXPECT().Capabilities(ecscni.ECSBridgePluginName).Return(cniCapabilities, nil),
 				cniClient.EXPECT().Capabilities(ecscni.ECSIPAMPluginName).Return(cniCapabilities, nil),
 				cniClient.EXPECT().Capabilities(ecscni.ECSAppMeshPluginName).Return(cniCapabilities, nil),
+				cniClient.EXPECT().Version(ecscni.ECSENIPluginName).Return(cniPluginVersion, nil),
+				cniClient.EXPECT().Version(ecscni.ECSBridgePluginName).Return(cniPluginVersion, nil),
+				cniClient.EXPECT().Version(ecscni.ECSIPAMPluginName).Return(cniPluginVersion, nil),
+				cniClient.EXPECT().Version(ecscni.ECSAppMeshPluginName).Return(cniPluginVersion, nil),
 				mockPauseLoader.EXPECT().LoadImage(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, loadErr),
 			)
 			cfg := getTestConfig()
@@ -505,7 +587,7 @@ func TestInitializeTaskENIDependenciesPauseLoaderError(t *testing.T) {
 				pauseLoader:       mockPauseLoader,
 				cfg:               &cfg,
 			}
-			err, ok := agent.initializeTaskENIDependencies(state, taskEngine)
+			err, ok := agent.initializeTaskENIDependencies(state, taskEngine, nil)
 			assert.Error(t, err)
 			assert.Equal(t, expectedIsTerminal, ok)
 		})
@@ -532,6 +614,7 @@ func TestDoStartCgroupInitHappyPath(t *testing.T) {
 	dockerClient.EXPECT().Version(gomock.Any(), gomock.Any()).AnyTimes()
 	dockerClient.EXPECT().SupportedVersions().Return(apiVersions)
 	imageManager.EXPECT().StartImageCleanupProcess(gomock.Any()).MaxTimes(1)
+	imageManager.EXPECT().StartImageEventsListener(gomock.Any()).MaxTimes(1)
 	mockCredentialsProvider.EXPECT().IsExpired().Return(false).AnyTimes()
 	ec2MetadataClient.EXPECT().OutpostARN().Return("", nil)
 
@@ -566,6 +649,7 @@ func TestDoStartCgroupInitHappyPath(t *testing.T) {
 	)
 
 	cfg := config.DefaultConfig()
+	cfg.PreflightChecksDisabled = true
 	ctx, cancel := context.WithCancel(context.TODO())
 	// Cancel the context to cancel async routines
 	agent := &ecsAgent{
@@ -611,6 +695,7 @@ func TestDoStartCgroupInitErrorPath(t *testing.T) {
 	dockerClient.EXPECT().Version(gomock.Any(), gomock.Any()).AnyTimes()
 	dockerClient.EXPECT().SupportedVersions().Return(apiVersions)
 	imageManager.EXPECT().StartImageCleanupProcess(gomock.Any()).MaxTimes(1)
+	imageManager.EXPECT().StartImageEventsListener(gomock.Any()).MaxTimes(1)
 	mockCredentialsProvider.EXPECT().IsExpired().Return(false).AnyTimes()
 
 	mockControl.EXPECT().Init().Return(errors.New("test error"))
@@ -668,8 +753,10 @@ func TestDoStartGPUManagerHappyPath(t *testing.T) {
 	dockerClient.EXPECT().Version(gomock.Any(), gomock.Any()).AnyTimes()
 	dockerClient.EXPECT().SupportedVersions().Return(apiVersions)
 	imageManager.EXPECT().StartImageCleanupProcess(gomock.Any()).MaxTimes(1)
+	imageManager.EXPECT().StartImageEventsListener(gomock.Any()).MaxTimes(1)
 	mockCredentialsProvider.EXPECT().IsExpired().Return(false).AnyTimes()
 	ec2MetadataClient.EXPECT().OutpostARN().Return("", nil)
+	mockGPUManager.EXPECT().StartSampling(gomock.Any(), gpuStatsSamplingInterval).MaxTimes(1)
 
 	gomock.InOrder(
 		mockGPUManager.EXPECT().Initialize().Return(nil),
@@ -750,6 +837,7 @@ func TestDoStartGPUManagerInitError(t *testing.T) {
 	dockerClient.EXPECT().Version(gomock.Any(), gomock.Any()).AnyTimes()
 	dockerClient.EXPECT().SupportedVersions().Return(apiVersions)
 	imageManager.EXPECT().StartImageCleanupProcess(gomock.Any()).MaxTimes(1)
+	imageManager.EXPECT().StartImageEventsListener(gomock.Any()).MaxTimes(1)
 	mockCredentialsProvider.EXPECT().IsExpired().Return(false).AnyTimes()
 	mockGPUManager.EXPECT().Initialize().Return(errors.New("init error"))
 
@@ -22,10 +22,13 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/ecs_client/model/ecs"
 	"github.com/aws/amazon-ecs-agent/agent/engine"
 	"github.com/aws/amazon-ecs-agent/agent/engine/dockerstate"
+	"github.com/aws/amazon-ecs-agent/agent/preflight"
+	"github.com/aws/amazon-ecs-agent/agent/stats"
 	"github.com/cihub/seelog"
 )
 
-func (agent *ecsAgent) initializeTaskENIDependencies(state dockerstate.TaskEngineState, taskEngine engine.TaskEngine) (error, bool) {
+func (agent *ecsAgent) initializeTaskENIDependencies(state dockerstate.TaskEngineState, taskEngine engine.TaskEngine,
+	imageManager engine.ImageManager) (error, bool) {
 	return errors.New("unsupported platform"), true
 }
 
@@ -38,6 +41,11 @@ func (agent *ecsAgent) startWindowsService() int {
 func (agent *ecsAgent) initializeResourceFields(credentialsManager credentials.Manager) {
 }
 
+// platformPreflightChecks returns no checks on unspecified platforms
+func (agent *ecsAgent) platformPreflightChecks() []preflight.Check {
+	return nil
+}
+
 func (agent *ecsAgent) cgroupInit() error {
 	return nil
 }
@@ -46,6 +54,9 @@ func (agent *ecsAgent) initializeGPUManager() error {
 	return nil
 }
 
+func (agent *ecsAgent) startGPUStatsSampling(statsEngine *stats.DockerStatsEngine) {
+}
+
 func (agent *ecsAgent) getPlatformDevices() []*ecs.PlatformDevice {
 	return nil
 }
@@ -26,10 +26,13 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/ecs_client/model/ecs"
 	"github.com/aws/amazon-ecs-agent/agent/engine"
 	"github.com/aws/amazon-ecs-agent/agent/engine/dockerstate"
+	"github.com/aws/amazon-ecs-agent/agent/preflight"
+	"github.com/aws/amazon-ecs-agent/agent/secretprovider"
 	"github.com/aws/amazon-ecs-agent/agent/sighandlers"
 	"github.com/aws/amazon-ecs-agent/agent/sighandlers/exitcodes"
 	ssmfactory "github.com/aws/amazon-ecs-agent/agent/ssm/factory"
 	"github.com/aws/amazon-ecs-agent/agent/statemanager"
+	"github.com/aws/amazon-ecs-agent/agent/stats"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource"
 	"github.com/cihub/seelog"
 	"golang.org/x/sys/windows/svc"
@@ -40,7 +43,8 @@ const (
 	EcsSvcName = "AmazonECS"
 )
 
-func (agent *ecsAgent) initializeTaskENIDependencies(state dockerstate.TaskEngineState, taskEngine engine.TaskEngine) (error, bool) {
+func (agent *ecsAgent) initializeTaskENIDependencies(state dockerstate.TaskEngineState, taskEngine engine.TaskEngine,
+	imageManager engine.ImageManager) (error, bool) {
 	return errors.New("unsupported platform"), true
 }
 
@@ -257,6 +261,7 @@ func (agent *ecsAgent) initializeResourceFields(credentialsManager credentials.M
 		ResourceFieldsCommon: &taskresource.ResourceFieldsCommon{
 			ASMClientCreator:   asmfactory.NewClientCreator(),
 			SSMClientCreator:   ssmfactory.NewSSMClientCreator(),
+			SecretProviders:    secretprovider.NewProviders(agent.cfg),
 			CredentialsManager: credentialsManager,
 		},
 		Ctx:          agent.ctx,
@@ -264,6 +269,11 @@ func (agent *ecsAgent) initializeResourceFields(credentialsManager credentials.M
 	}
 }
 
+// platformPreflightChecks returns no checks on Windows
+func (agent *ecsAgent) platformPreflightChecks() []preflight.Check {
+	return nil
+}
+
 func (agent *ecsAgent) cgroupInit() error {
 	return errors.New("unsupported platform")
 }
@@ -272,6 +282,9 @@ func (agent *ecsAgent) initializeGPUManager() error {
 	return nil
 }
 
+func (agent *ecsAgent) startGPUStatsSampling(statsEngine *stats.DockerStatsEngine) {
+}
+
 func (agent *ecsAgent) getPlatformDevices() []*ecs.PlatformDevice {
 	return nil
 }
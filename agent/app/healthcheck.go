@@ -20,12 +20,18 @@ import (
 	"github.com/cihub/seelog"
 )
 
-// runHealthcheck runs the Agent's healthcheck
+// runHealthcheck runs the Agent's healthcheck. The agent is unhealthy when it
+// doesn't respond or when one of its error budgets is exhausted
 func runHealthcheck() int {
-	_, err := http.Get("http://localhost:51678/v1/metadata")
+	resp, err := http.Get("http://localhost:51678/v1/health")
 	if err != nil {
 		seelog.Warnf("Health check failed with error: %v", err)
 		return exitcodes.ExitError
 	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		seelog.Warnf("Health check failed with status: %d", resp.StatusCode)
+		return exitcodes.ExitError
+	}
 	return exitcodes.ExitSuccess
 }
@@ -15,6 +15,8 @@ package asm
 
 import (
 	"encoding/json"
+	"fmt"
+	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/secretsmanager"
@@ -23,6 +25,62 @@ import (
 	"github.com/pkg/errors"
 )
 
+const (
+	// secretARNParts is the number of colon separated parts of a secret ARN,
+	// arn:aws:secretsmanager:region:account:secret:name
+	secretARNParts = 7
+	// secretReferenceParts is the maximum number of colon separated parts of a
+	// secret reference, which is a secret ARN optionally followed by
+	// :json-key:version-stage:version-id
+	secretReferenceParts = secretARNParts + 3
+)
+
+// SecretReference identifies a secret value in AWS Secrets Manager and,
+// optionally, the key of the JSON object in the secret value to use
+type SecretReference struct {
+	SecretID     string
+	JSONKey      string
+	VersionStage string
+	VersionID    string
+}
+
+// ParseSecretReference parses the valueFrom of a container secret, which is
+// either the name or the ARN of a secret. An ARN can be followed by
+// :json-key:version-stage:version-id, where each of them may be left empty
+func ParseSecretReference(valueFrom string) (SecretReference, error) {
+	if !strings.HasPrefix(valueFrom, "arn:") {
+		return SecretReference{SecretID: valueFrom}, nil
+	}
+	parts := strings.Split(valueFrom, ":")
+	if len(parts) < secretARNParts || len(parts) > secretReferenceParts {
+		return SecretReference{}, errors.Errorf("invalid secret reference %s: expected "+
+			"arn:aws:secretsmanager:region:account:secret:name[:json-key:version-stage:version-id]", valueFrom)
+	}
+	// pad the optional parts so that they can be read by index
+	parts = append(parts, make([]string, secretReferenceParts-len(parts))...)
+	return SecretReference{
+		SecretID:     strings.Join(parts[:secretARNParts], ":"),
+		JSONKey:      parts[secretARNParts],
+		VersionStage: parts[secretARNParts+1],
+		VersionID:    parts[secretARNParts+2],
+	}, nil
+}
+
+// GetSecretValueInput returns the input of the GetSecretValue call that
+// retrieves the referenced secret value
+func (ref SecretReference) GetSecretValueInput() *secretsmanager.GetSecretValueInput {
+	in := &secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(ref.SecretID),
+	}
+	if ref.VersionStage != "" {
+		in.VersionStage = aws.String(ref.VersionStage)
+	}
+	if ref.VersionID != "" {
+		in.VersionId = aws.String(ref.VersionID)
+	}
+	return in
+}
+
 // AuthDataValue is the schema for
 // the SecretStringValue returned by ASM
 type AuthDataValue struct {
@@ -94,10 +152,35 @@ func GetSecretFromASM(secretID string, client secretsmanageriface.SecretsManager
 		SecretId: aws.String(secretID),
 	}
 
+	return GetSecretFromASMWithInput(in, client)
+}
+
+// GetSecretFromASMWithInput makes the api call to the AWS Secrets Manager
+// service to retrieve the secret value with the given input
+func GetSecretFromASMWithInput(in *secretsmanager.GetSecretValueInput,
+	client secretsmanageriface.SecretsManagerAPI) (string, error) {
 	out, err := client.GetSecretValue(in)
 	if err != nil {
-		return "", errors.Wrapf(err, "secret %s", secretID)
+		return "", errors.Wrapf(err, "secret %s", aws.StringValue(in.SecretId))
 	}
 
 	return aws.StringValue(out.SecretString), nil
 }
+
+// ExtractJSONKey returns the value of the key of the JSON object in the secret
+// value. Values that aren't strings are returned as JSON
+func ExtractJSONKey(secretValue string, jsonKey string) (string, error) {
+	var secretObject map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(secretValue), &secretObject); err != nil {
+		return "", errors.New("secret value is not a JSON object")
+	}
+	value, ok := secretObject[jsonKey]
+	if !ok {
+		return "", fmt.Errorf("secret value does not contain JSON key %s", jsonKey)
+	}
+	var stringValue string
+	if err := json.Unmarshal(value, &stringValue); err == nil {
+		return stringValue, nil
+	}
+	return string(value), nil
+}
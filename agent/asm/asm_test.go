@@ -119,3 +119,86 @@ func TestGetSecretFromASM(t *testing.T) {
 	_, err := GetSecretFromASM("secretName", asmClient)
 	assert.NoError(t, err)
 }
+
+func TestParseSecretReference(t *testing.T) {
+	const secretARN = "arn:aws:secretsmanager:us-west-2:123456789012:secret:db-AbCdEf"
+	cases := []struct {
+		Name      string
+		ValueFrom string
+		Expected  SecretReference
+		ShouldErr bool
+	}{
+		{
+			Name:      "name",
+			ValueFrom: "db",
+			Expected:  SecretReference{SecretID: "db"},
+		},
+		{
+			Name:      "arn",
+			ValueFrom: secretARN,
+			Expected:  SecretReference{SecretID: secretARN},
+		},
+		{
+			Name:      "arn with json key",
+			ValueFrom: secretARN + ":password::",
+			Expected:  SecretReference{SecretID: secretARN, JSONKey: "password"},
+		},
+		{
+			Name:      "arn with json key and version stage",
+			ValueFrom: secretARN + ":password:AWSPREVIOUS",
+			Expected:  SecretReference{SecretID: secretARN, JSONKey: "password", VersionStage: "AWSPREVIOUS"},
+		},
+		{
+			Name:      "arn with version id only",
+			ValueFrom: secretARN + ":::version-id",
+			Expected:  SecretReference{SecretID: secretARN, VersionID: "version-id"},
+		},
+		{
+			Name:      "arn with too many parts",
+			ValueFrom: secretARN + ":password:AWSCURRENT:version-id:extra",
+			ShouldErr: true,
+		},
+		{
+			Name:      "truncated arn",
+			ValueFrom: "arn:aws:secretsmanager:us-west-2:123456789012:secret",
+			ShouldErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			ref, err := ParseSecretReference(c.ValueFrom)
+			if c.ShouldErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, c.Expected, ref)
+		})
+	}
+}
+
+func TestSecretReferenceGetSecretValueInput(t *testing.T) {
+	in := SecretReference{SecretID: "db", JSONKey: "password", VersionStage: "AWSPREVIOUS"}.GetSecretValueInput()
+	assert.Equal(t, "db", aws.StringValue(in.SecretId))
+	assert.Equal(t, "AWSPREVIOUS", aws.StringValue(in.VersionStage))
+	assert.Nil(t, in.VersionId)
+}
+
+func TestExtractJSONKey(t *testing.T) {
+	const secretValue = `{"username":"admin","port":5432}`
+
+	value, err := ExtractJSONKey(secretValue, "username")
+	assert.NoError(t, err)
+	assert.Equal(t, "admin", value)
+
+	value, err = ExtractJSONKey(secretValue, "port")
+	assert.NoError(t, err)
+	assert.Equal(t, "5432", value)
+
+	_, err = ExtractJSONKey(secretValue, "password")
+	assert.Error(t, err)
+
+	_, err = ExtractJSONKey("not-json", "username")
+	assert.Error(t, err)
+}
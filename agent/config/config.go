@@ -18,6 +18,7 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"net"
 	"os"
 	"reflect"
 	"strings"
@@ -26,6 +27,7 @@ import (
 	apierrors "github.com/aws/amazon-ecs-agent/agent/api/errors"
 	"github.com/aws/amazon-ecs-agent/agent/dockerclient"
 	"github.com/aws/amazon-ecs-agent/agent/ec2"
+	"github.com/aws/amazon-ecs-agent/agent/s3"
 	"github.com/aws/amazon-ecs-agent/agent/utils"
 	"github.com/cihub/seelog"
 )
@@ -64,6 +66,10 @@ const (
 	// This is only used when PollMetrics is set to true
 	DefaultPollingMetricsWaitDuration = 15 * time.Second
 
+	// DefaultTelemetryPublishInterval specifies the default interval at which the metrics of the
+	// containers are published to the backend
+	DefaultTelemetryPublishInterval = 20 * time.Second
+
 	// defaultDockerStopTimeout specifies the value for container stop timeout duration
 	defaultDockerStopTimeout = 30 * time.Second
 
@@ -100,12 +106,96 @@ const (
 	minimumPollingMetricsWaitDuration = 1 * time.Second
 
 	// maximumPollingMetricsWaitDuration specifies the maximum duration to wait before polling for new stats
-	// from docker. This is only used when PollMetrics is set to true
+	// from docker, unless the telemetry publish interval is longer. This is only used when PollMetrics is
+	// set to true
 	maximumPollingMetricsWaitDuration = 20 * time.Second
 
+	// minimumTelemetryPublishInterval specifies the minimum interval at which the metrics of the
+	// containers are published to the backend
+	minimumTelemetryPublishInterval = 5 * time.Second
+
+	// maximumTelemetryPublishInterval specifies the maximum interval at which the metrics of the
+	// containers are published to the backend, past which the backend has no datapoint for some minutes
+	maximumTelemetryPublishInterval = 1 * time.Minute
+
 	// minimumDockerStopTimeout specifies the minimum value for docker StopContainer API
 	minimumDockerStopTimeout = 1 * time.Second
 
+	// minimumENIAttachmentAckTimeout specifies the minimum time to wait for an ENI attachment to be
+	// reported before it's removed from state.
+	minimumENIAttachmentAckTimeout = 10 * time.Second
+
+	// defaultImageVerificationTimeout specifies the default time the image verification
+	// hook is allowed to run for
+	defaultImageVerificationTimeout = 1 * time.Minute
+
+	// defaultLocalDNSListenAddress is the default address the agent answers
+	// ecs.local DNS queries on, the address of the default docker bridge, so
+	// that task containers can reach it
+	defaultLocalDNSListenAddress = "172.17.0.1:53"
+
+	// defaultTaskRestartLimit is the default number of container restarts a
+	// task is allowed within the task restart limit window
+	defaultTaskRestartLimit = 10
+
+	// defaultTaskRestartLimitWindow is the default window the task restart
+	// limit applies to
+	defaultTaskRestartLimitWindow = 10 * time.Minute
+
+	// defaultImageRepullInterval is the default interval the tags of the
+	// re-pulled images are resolved at
+	defaultImageRepullInterval = 15 * time.Minute
+
+	// minimumImageRepullInterval is the minimum interval the tags of the
+	// re-pulled images are resolved at
+	minimumImageRepullInterval = 1 * time.Minute
+
+	// defaultStateMirrorInterval is the default interval the agent mirrors its
+	// state to S3 at
+	defaultStateMirrorInterval = 5 * time.Minute
+
+	// minimumStateMirrorInterval is the minimum interval the agent mirrors its
+	// state to S3 at
+	minimumStateMirrorInterval = 1 * time.Minute
+
+	// maximumStateChangeAggregationWindow is the maximum time task state
+	// changes are held before being submitted
+	maximumStateChangeAggregationWindow = 10 * time.Second
+
+	// defaultContainerMetadataMaxStartDelay is the default maximum time the
+	// start of a container waits for its metadata file and credentials
+	defaultContainerMetadataMaxStartDelay = 10 * time.Second
+
+	// defaultErrorBudgetWindow is the default sliding window agent internal
+	// failures are counted in
+	defaultErrorBudgetWindow = 10 * time.Minute
+
+	// defaultRestoreReconcileTimeout is the default maximum time the agent
+	// waits for its restored tasks to be reported before accepting new ones
+	defaultRestoreReconcileTimeout = 30 * time.Second
+
+	// DefaultContainerHealthEventMarker is the default prefix of the lines of
+	// the stdout of containers that carry health events
+	DefaultContainerHealthEventMarker = "ECS_HEALTH_EVENT "
+
+	// defaultTaskNetworkCleanupAttempts is the default number of times the
+	// agent tries to remove the docker network of a task
+	defaultTaskNetworkCleanupAttempts = 5
+
+	// defaultCNIMaxConcurrency is the default maximum number of CNI plugin
+	// invocations the agent runs at the same time
+	defaultCNIMaxConcurrency = 4
+
+	// defaultCNISetupTimeout is the default maximum time the agent waits for
+	// the network namespace of an awsvpc task to be set up
+	defaultCNISetupTimeout = 1 * time.Minute
+
+	// minAWSVPCTaskMTU and maxAWSVPCTaskMTU are the bounds of the MTU of the
+	// interfaces of awsvpc tasks: the minimum MTU of IPv6 and the MTU of the
+	// jumbo frames of EC2
+	minAWSVPCTaskMTU = 1280
+	maxAWSVPCTaskMTU = 9001
+
 	// minimumImageCleanupInterval specifies the minimum time for agent to wait before performing
 	// image cleanup.
 	minimumImageCleanupInterval = 10 * time.Minute
@@ -142,6 +232,11 @@ const (
 	defaultCgroupCPUPeriod = 100 * time.Millisecond
 	maximumCgroupCPUPeriod = 100 * time.Millisecond
 	minimumCgroupCPUPeriod = 8 * time.Millisecond
+
+	// MinimumOOMScoreAdj and MaximumOOMScoreAdj are the bounds the kernel
+	// accepts for the oom_score_adj of a process
+	MinimumOOMScoreAdj = -1000
+	MaximumOOMScoreAdj = 1000
 )
 
 const (
@@ -163,6 +258,48 @@ const (
 	ImagePullPreferCachedBehavior
 )
 
+const (
+	// ImagePullDigestDisabled specifies that images are pulled by the reference
+	// set in the task definition.
+	ImagePullDigestDisabled ImagePullDigestModeType = iota
+
+	// ImagePullDigestResolve specifies that image tags are resolved to digests
+	// when the task is accepted and images are pulled by digest.
+	ImagePullDigestResolve
+
+	// ImagePullDigestRequire specifies that tasks with images not referenced by
+	// digest in the task definition are rejected.
+	ImagePullDigestRequire
+)
+
+const (
+	// ImageRepullNotify specifies that the agent only logs that an image tag
+	// points to a new digest.
+	ImageRepullNotify ImageRepullActionType = iota
+
+	// ImageRepullPull specifies that the agent also pulls the image, so that
+	// tasks using the tag start from a warm copy of the new image.
+	ImageRepullPull
+)
+
+const (
+	// RegistryAuthTypeECR authenticates with the ECR auth data of the task,
+	// using the execution role of the task or the instance role
+	RegistryAuthTypeECR = "ecr"
+
+	// RegistryAuthTypeASM authenticates with the repository credentials of the
+	// task stored in Secrets Manager
+	RegistryAuthTypeASM = "asm"
+
+	// RegistryAuthTypeDocker authenticates with the auth config of the
+	// registry in EngineAuthData
+	RegistryAuthTypeDocker = "docker"
+
+	// RegistryAuthTypeCredentialHelper authenticates with the credentials
+	// provided by a docker credential helper
+	RegistryAuthTypeCredentialHelper = "credentialhelper"
+)
+
 const (
 	// When ContainerInstancePropagateTagsFromNoneType is specified, no DescribeTags
 	// API call will be made.
@@ -314,11 +451,119 @@ func (cfg *Config) validateAndOverrideBounds() error {
 		cfg.TaskCleanupWaitDuration = DefaultTaskCleanupWaitDuration
 	}
 
+	if cfg.TaskCleanupWaitJitter < 0 {
+		seelog.Warnf("Invalid value for ECS_ENGINE_TASK_CLEANUP_WAIT_JITTER, no jitter will be added to the task cleanup wait. Parsed value: %v.", cfg.TaskCleanupWaitJitter)
+		cfg.TaskCleanupWaitJitter = 0
+	}
+
 	if cfg.ImagePullInactivityTimeout < minimumImagePullInactivityTimeout {
 		seelog.Warnf("Invalid value for image pull inactivity timeout duration, will be overridden with the default value: %s. Parsed value: %v, minimum value: %v.", defaultImagePullInactivityTimeout.String(), cfg.ImagePullInactivityTimeout, minimumImagePullInactivityTimeout)
 		cfg.ImagePullInactivityTimeout = defaultImagePullInactivityTimeout
 	}
 
+	if cfg.ENIAttachmentAckTimeout != 0 && cfg.ENIAttachmentAckTimeout < minimumENIAttachmentAckTimeout {
+		seelog.Warnf("Invalid value for ECS_ENI_ATTACHMENT_ACK_TIMEOUT, will be overridden with the timeout specified by the backend. Parsed value: %v, minimum value: %v.", cfg.ENIAttachmentAckTimeout, minimumENIAttachmentAckTimeout)
+		cfg.ENIAttachmentAckTimeout = 0
+	}
+
+	if cfg.ImageVerificationTimeout <= 0 {
+		cfg.ImageVerificationTimeout = defaultImageVerificationTimeout
+	}
+
+	if cfg.VaultAddress != "" && cfg.VaultTokenFile == "" {
+		seelog.Warnf("ECS_VAULT_ADDR is set without ECS_VAULT_TOKEN_FILE, secrets won't be retrieved from Vault")
+		cfg.VaultAddress = ""
+	}
+
+	for registry, registryAuth := range cfg.RegistryAuth {
+		if err := registryAuth.validate(); err != nil {
+			seelog.Warnf("Invalid auth config for registry %s, images from it will be pulled with the auth data of their tasks: %v", registry, err)
+			delete(cfg.RegistryAuth, registry)
+		}
+	}
+
+	var cniAdditionalPlugins []CNIPluginConfig
+	for _, plugin := range cfg.CNIAdditionalPlugins {
+		if err := plugin.validate(); err != nil {
+			seelog.Warnf("Invalid additional CNI plugin config, the plugin %q won't be invoked for awsvpc tasks: %v", plugin.Type, err)
+			continue
+		}
+		cniAdditionalPlugins = append(cniAdditionalPlugins, plugin)
+	}
+	cfg.CNIAdditionalPlugins = cniAdditionalPlugins
+
+	if cfg.LocalDNSListenAddress == "" {
+		cfg.LocalDNSListenAddress = defaultLocalDNSListenAddress
+	} else if _, _, err := net.SplitHostPort(cfg.LocalDNSListenAddress); err != nil {
+		seelog.Warnf("Invalid value for ECS_LOCAL_DNS_LISTEN_ADDRESS, will be overridden with the default value: %s. Parsed value: %s, error: %v.", defaultLocalDNSListenAddress, cfg.LocalDNSListenAddress, err)
+		cfg.LocalDNSListenAddress = defaultLocalDNSListenAddress
+	}
+
+	if cfg.TaskRestartLimit <= 0 {
+		cfg.TaskRestartLimit = defaultTaskRestartLimit
+	}
+
+	if cfg.TaskRestartLimitWindow <= 0 {
+		cfg.TaskRestartLimitWindow = defaultTaskRestartLimitWindow
+	}
+
+	if cfg.StateMirrorS3ARN != "" {
+		if _, _, err := s3.ParseS3ARN(cfg.StateMirrorS3ARN); err != nil {
+			seelog.Warnf("Invalid value for ECS_STATE_MIRROR_S3_ARN, state won't be mirrored: %v", err)
+			cfg.StateMirrorS3ARN = ""
+		}
+	}
+
+	if cfg.ImageRepullInterval <= 0 {
+		cfg.ImageRepullInterval = defaultImageRepullInterval
+	} else if cfg.ImageRepullInterval < minimumImageRepullInterval {
+		seelog.Warnf("Invalid value for ECS_IMAGE_REPULL_INTERVAL, will be overridden with the minimum value: %s. Parsed value: %v.", minimumImageRepullInterval.String(), cfg.ImageRepullInterval)
+		cfg.ImageRepullInterval = minimumImageRepullInterval
+	}
+
+	if cfg.StateMirrorInterval <= 0 {
+		cfg.StateMirrorInterval = defaultStateMirrorInterval
+	} else if cfg.StateMirrorInterval < minimumStateMirrorInterval {
+		seelog.Warnf("Invalid value for ECS_STATE_MIRROR_INTERVAL, will be overridden with the minimum value: %s. Parsed value: %v.", minimumStateMirrorInterval.String(), cfg.StateMirrorInterval)
+		cfg.StateMirrorInterval = minimumStateMirrorInterval
+	}
+
+	if cfg.ContainerMetadataMaxStartDelay <= 0 {
+		cfg.ContainerMetadataMaxStartDelay = defaultContainerMetadataMaxStartDelay
+	}
+
+	if cfg.ErrorBudgetWindow <= 0 {
+		cfg.ErrorBudgetWindow = defaultErrorBudgetWindow
+	}
+
+	if cfg.RestoreReconcileTimeout <= 0 {
+		cfg.RestoreReconcileTimeout = defaultRestoreReconcileTimeout
+	}
+
+	if cfg.ContainerHealthEventMarker == "" {
+		cfg.ContainerHealthEventMarker = DefaultContainerHealthEventMarker
+	}
+
+	if cfg.StateChangeAggregationWindow < 0 {
+		seelog.Warnf("Invalid value for ECS_STATE_CHANGE_AGGREGATION_WINDOW, state changes won't be aggregated. Parsed value: %v.", cfg.StateChangeAggregationWindow)
+		cfg.StateChangeAggregationWindow = 0
+	} else if cfg.StateChangeAggregationWindow > maximumStateChangeAggregationWindow {
+		seelog.Warnf("Invalid value for ECS_STATE_CHANGE_AGGREGATION_WINDOW, will be overridden with the maximum value: %s. Parsed value: %v.", maximumStateChangeAggregationWindow.String(), cfg.StateChangeAggregationWindow)
+		cfg.StateChangeAggregationWindow = maximumStateChangeAggregationWindow
+	}
+
+	if cfg.TaskNetworkCleanupAttempts <= 0 {
+		cfg.TaskNetworkCleanupAttempts = defaultTaskNetworkCleanupAttempts
+	}
+
+	if cfg.CNIMaxConcurrency <= 0 {
+		cfg.CNIMaxConcurrency = defaultCNIMaxConcurrency
+	}
+
+	if cfg.CNISetupTimeout <= 0 {
+		cfg.CNISetupTimeout = defaultCNISetupTimeout
+	}
+
 	if cfg.ImageCleanupInterval < minimumImageCleanupInterval {
 		seelog.Warnf("Invalid value for ECS_IMAGE_CLEANUP_INTERVAL, will be overridden with the default value: %s. Parsed value: %v, minimum value: %v.", DefaultImageCleanupTimeInterval.String(), cfg.ImageCleanupInterval, minimumImageCleanupInterval)
 		cfg.ImageCleanupInterval = DefaultImageCleanupTimeInterval
@@ -335,14 +580,30 @@ func (cfg *Config) validateAndOverrideBounds() error {
 		cfg.TaskMetadataBurstRate = DefaultTaskMetadataBurstRate
 	}
 
+	cfg.telemetryPublishIntervalOverrides()
+
 	// check the PollMetrics specific configurations
 	cfg.pollMetricsOverrides()
 
+	cfg.multiTenantIsolationOverrides()
+
 	cfg.platformOverrides()
 
 	return nil
 }
 
+func (cfg *Config) telemetryPublishIntervalOverrides() {
+	if cfg.TelemetryPublishInterval < minimumTelemetryPublishInterval {
+		seelog.Warnf("Invalid value for telemetry publish interval, will be overridden with the default value: %s. Parsed value: %v, minimum value: %v.", DefaultTelemetryPublishInterval.String(), cfg.TelemetryPublishInterval, minimumTelemetryPublishInterval)
+		cfg.TelemetryPublishInterval = DefaultTelemetryPublishInterval
+	}
+
+	if cfg.TelemetryPublishInterval > maximumTelemetryPublishInterval {
+		seelog.Warnf("Invalid value for telemetry publish interval, will be overridden with the default value: %s. Parsed value: %v, maximum value: %v.", DefaultTelemetryPublishInterval.String(), cfg.TelemetryPublishInterval, maximumTelemetryPublishInterval)
+		cfg.TelemetryPublishInterval = DefaultTelemetryPublishInterval
+	}
+}
+
 func (cfg *Config) pollMetricsOverrides() {
 	if cfg.PollMetrics {
 		if cfg.PollingMetricsWaitDuration < minimumPollingMetricsWaitDuration {
@@ -350,13 +611,36 @@ func (cfg *Config) pollMetricsOverrides() {
 			cfg.PollingMetricsWaitDuration = DefaultPollingMetricsWaitDuration
 		}
 
-		if cfg.PollingMetricsWaitDuration > maximumPollingMetricsWaitDuration {
-			seelog.Warnf("Invalid value for polling metrics wait duration, will be overridden with the default value: %s. Parsed value: %v, maximum value: %v.", DefaultPollingMetricsWaitDuration.String(), cfg.PollingMetricsWaitDuration, maximumPollingMetricsWaitDuration)
+		// polling less often than the metrics are published leaves publish intervals without
+		// new stats, so the longest wait follows the publish interval when it's longer
+		maximumWaitDuration := maximumPollingMetricsWaitDuration
+		if cfg.TelemetryPublishInterval > maximumWaitDuration {
+			maximumWaitDuration = cfg.TelemetryPublishInterval
+		}
+		if cfg.PollingMetricsWaitDuration > maximumWaitDuration {
+			seelog.Warnf("Invalid value for polling metrics wait duration, will be overridden with the default value: %s. Parsed value: %v, maximum value: %v.", DefaultPollingMetricsWaitDuration.String(), cfg.PollingMetricsWaitDuration, maximumWaitDuration)
 			cfg.PollingMetricsWaitDuration = DefaultPollingMetricsWaitDuration
 		}
 	}
 }
 
+// multiTenantIsolationOverrides turns on the isolation features the multi
+// tenant isolation mode relies on, and turns off the ones that let a task
+// reach the host or other tasks, whatever they were configured to
+func (cfg *Config) multiTenantIsolationOverrides() {
+	if !cfg.MultiTenantIsolationEnabled {
+		return
+	}
+	seelog.Info("Multi tenant isolation is enabled, overriding the security baseline, privileged container, " +
+		"task network and instance metadata configuration")
+	cfg.SecurityBaselineEnabled = true
+	cfg.SecurityBaselineAllowOptOut = false
+	cfg.PrivilegedDisabled = true
+	cfg.TaskIAMRoleEnabledForNetworkHost = false
+	cfg.TaskBridgeNetworkEnabled = true
+	cfg.AWSVPCBlockInstanceMetdata = true
+}
+
 // checkMissingAndDeprecated checks all zero-valued fields for tags of the form
 // missing:STRING and acts based on that string. Current options are: fatal,
 // warn. Fatal will result in an error being returned, warn will result in a
@@ -480,6 +764,8 @@ func environmentConfig() (Config, error) {
 	dataDir := os.Getenv("ECS_DATADIR")
 
 	steadyStateRate, burstRate := parseTaskMetadataThrottles()
+	taskLaunchRate, taskLaunchBurst := parseTaskLaunchRateLimit()
+	dynamicHostPortRangeStart, dynamicHostPortRangeEnd := parseDynamicHostPortRange()
 
 	var errs []error
 	instanceAttributes, errs := parseInstanceAttributes(errs)
@@ -488,6 +774,20 @@ func environmentConfig() (Config, error) {
 
 	additionalLocalRoutes, errs := parseAdditionalLocalRoutes(errs)
 
+	pullThroughCacheRules, errs := parsePullThroughCacheRules(errs)
+
+	registryAuth, errs := parseRegistryAuth(errs)
+
+	cniAdditionalPlugins, errs := parseCNIAdditionalPlugins(errs)
+
+	errorBudgetThresholds, errs := parseErrorBudgetThresholds(errs)
+
+	defaultUlimits, errs := parseDefaultUlimits(errs)
+
+	imagePolicyRequiredLabels, errs := parseImagePolicyRequiredLabels(errs)
+
+	imagePolicyDisallowedDigests, errs := parseImagePolicyDisallowedDigests(errs)
+
 	var err error
 	if len(errs) > 0 {
 		err = apierrors.NewMultiError(errs...)
@@ -499,6 +799,8 @@ func environmentConfig() (Config, error) {
 		DockerEndpoint:                      os.Getenv("DOCKER_HOST"),
 		ReservedPorts:                       parseReservedPorts("ECS_RESERVED_PORTS"),
 		ReservedPortsUDP:                    parseReservedPorts("ECS_RESERVED_PORTS_UDP"),
+		DynamicHostPortRangeStart:           dynamicHostPortRangeStart,
+		DynamicHostPortRangeEnd:             dynamicHostPortRangeEnd,
 		DataDir:                             dataDir,
 		Checkpoint:                          parseCheckpoint(dataDir),
 		EngineAuthType:                      os.Getenv("ECS_ENGINE_AUTH_TYPE"),
@@ -510,15 +812,22 @@ func environmentConfig() (Config, error) {
 		AvailableLoggingDrivers:             parseAvailableLoggingDrivers(),
 		PrivilegedDisabled:                  utils.ParseBool(os.Getenv("ECS_DISABLE_PRIVILEGED"), false),
 		SELinuxCapable:                      utils.ParseBool(os.Getenv("ECS_SELINUX_CAPABLE"), false),
+		SecurityBaselineEnabled:             utils.ParseBool(os.Getenv("ECS_ENABLE_SECURITY_BASELINE"), false),
+		SecurityBaselineAllowOptOut:         utils.ParseBool(os.Getenv("ECS_SECURITY_BASELINE_ALLOW_OPT_OUT"), false),
+		AllowedKernelCapabilities:           parseAllowedKernelCapabilities(),
+		DefaultUlimits:                      defaultUlimits,
+		MultiTenantIsolationEnabled:         utils.ParseBool(os.Getenv("ECS_ENABLE_MULTI_TENANT_ISOLATION"), false),
 		AppArmorCapable:                     utils.ParseBool(os.Getenv("ECS_APPARMOR_CAPABLE"), false),
 		TaskCleanupWaitDuration:             parseEnvVariableDuration("ECS_ENGINE_TASK_CLEANUP_WAIT_DURATION"),
 		TaskENIEnabled:                      utils.ParseBool(os.Getenv("ECS_ENABLE_TASK_ENI"), false),
+		ENIAttachmentAckTimeout:             parseEnvVariableDuration("ECS_ENI_ATTACHMENT_ACK_TIMEOUT"),
 		TaskIAMRoleEnabled:                  utils.ParseBool(os.Getenv("ECS_ENABLE_TASK_IAM_ROLE"), false),
 		DeleteNonECSImagesEnabled:           utils.ParseBool(os.Getenv("ECS_ENABLE_UNTRACKED_IMAGE_CLEANUP"), false),
 		TaskCPUMemLimit:                     parseTaskCPUMemLimitEnabled(),
 		DockerStopTimeout:                   parseDockerStopTimeout(),
 		ContainerStartTimeout:               parseContainerStartTimeout(),
 		ImagePullInactivityTimeout:          parseImagePullInactivityTimeout(),
+		ImagePullMinimumBandwidth:           parseImagePullMinimumBandwidth(),
 		CredentialsAuditLogFile:             os.Getenv("ECS_AUDIT_LOGFILE"),
 		CredentialsAuditLogDisabled:         utils.ParseBool(os.Getenv("ECS_AUDIT_LOGFILE_DISABLED"), false),
 		TaskIAMRoleEnabledForNetworkHost:    utils.ParseBool(os.Getenv("ECS_ENABLE_TASK_IAM_ROLE_NETWORK_HOST"), false),
@@ -529,12 +838,32 @@ func environmentConfig() (Config, error) {
 		NumImagesToDeletePerCycle:           parseNumImagesToDeletePerCycle(),
 		NumNonECSContainersToDeletePerCycle: parseNumNonECSContainersToDeletePerCycle(),
 		ImagePullBehavior:                   parseImagePullBehavior(),
+		ImagePullDigestMode:                 parseImagePullDigestMode(),
+		PullThroughCacheRules:               pullThroughCacheRules,
+		RegistryAuth:                        registryAuth,
+		ImageRepullImages:                   parseImageRepullImages(),
+		ImageRepullInterval:                 parseEnvVariableDuration("ECS_IMAGE_REPULL_INTERVAL"),
+		ImageRepullAction:                   parseImageRepullAction(),
+		ImageVerificationHook:               os.Getenv("ECS_IMAGE_VERIFICATION_HOOK"),
+		ImageVerificationTimeout:            parseEnvVariableDuration("ECS_IMAGE_VERIFICATION_TIMEOUT"),
+		ImagePolicyRequiredLabels:           imagePolicyRequiredLabels,
+		ImagePolicyDisallowedDigests:        imagePolicyDisallowedDigests,
+		VaultAddress:                        os.Getenv("ECS_VAULT_ADDR"),
+		VaultTokenFile:                      os.Getenv("ECS_VAULT_TOKEN_FILE"),
+		SecretsFileProviderDir:              os.Getenv("ECS_SECRETS_FILE_DIR"),
 		ImageCleanupExclusionList:           parseImageCleanupExclusionList("ECS_EXCLUDE_UNTRACKED_IMAGE"),
 		InstanceAttributes:                  instanceAttributes,
 		CNIPluginsPath:                      os.Getenv("ECS_CNI_PLUGINS_PATH"),
+		PauseContainerTarballPath:           os.Getenv("ECS_PAUSE_CONTAINER_TARBALL_PATH"),
 		AWSVPCBlockInstanceMetdata:          utils.ParseBool(os.Getenv("ECS_AWSVPC_BLOCK_IMDS"), false),
 		AWSVPCAdditionalLocalRoutes:         additionalLocalRoutes,
+		AWSVPCTaskMTU:                       parseAWSVPCTaskMTU(),
+		AWSVPCDNSOptions:                    parseAWSVPCDNSOptions(),
+		AWSVPCDNSServers:                    parseAWSVPCDNSServers(),
+		AWSVPCDNSSearchDomains:              strings.Fields(os.Getenv("ECS_AWSVPC_DNS_SEARCH_DOMAINS")),
 		ContainerMetadataEnabled:            utils.ParseBool(os.Getenv("ECS_ENABLE_CONTAINER_METADATA"), false),
+		ContainerMetadataStartDependency:    utils.ParseBool(os.Getenv("ECS_CONTAINER_METADATA_START_DEPENDENCY"), false),
+		ContainerMetadataMaxStartDelay:      parseEnvVariableDuration("ECS_CONTAINER_METADATA_MAX_START_DELAY"),
 		DataDirOnHost:                       os.Getenv("ECS_HOST_DATA_DIR"),
 		OverrideAWSLogsExecutionRole:        utils.ParseBool(os.Getenv("ECS_ENABLE_AWSLOGS_EXECUTIONROLE_OVERRIDE"), false),
 		CgroupPath:                          os.Getenv("ECS_CGROUP_PATH"),
@@ -545,12 +874,50 @@ func environmentConfig() (Config, error) {
 		ContainerInstancePropagateTagsFrom:  parseContainerInstancePropagateTagsFrom(),
 		PollMetrics:                         utils.ParseBool(os.Getenv("ECS_POLL_METRICS"), false),
 		PollingMetricsWaitDuration:          parseEnvVariableDuration("ECS_POLLING_METRICS_WAIT_DURATION"),
+		CgroupStatsEnabled:                  utils.ParseBool(os.Getenv("ECS_ENABLE_CGROUP_STATS"), false),
+		TelemetryPublishInterval:            parseEnvVariableDuration("ECS_TELEMETRY_PUBLISH_INTERVAL"),
 		DisableDockerHealthCheck:            utils.ParseBool(os.Getenv("ECS_DISABLE_DOCKER_HEALTH_CHECK"), false),
 		GPUSupportEnabled:                   utils.ParseBool(os.Getenv("ECS_ENABLE_GPU_SUPPORT"), false),
 		NvidiaRuntime:                       os.Getenv("ECS_NVIDIA_RUNTIME"),
 		TaskMetadataAZDisabled:              utils.ParseBool(os.Getenv("ECS_DISABLE_TASK_METADATA_AZ"), false),
 		CgroupCPUPeriod:                     parseCgroupCPUPeriod(),
 		SpotInstanceDrainingEnabled:         utils.ParseBool(os.Getenv("ECS_ENABLE_SPOT_INSTANCE_DRAINING"), false),
+		FIPSEndpointsEnabled:                utils.ParseBool(os.Getenv("ECS_ENABLE_FIPS_ENDPOINTS"), false),
+		DualStackEndpointsEnabled:           utils.ParseBool(os.Getenv("ECS_ENABLE_DUALSTACK_ENDPOINTS"), false),
+		LocalDNSEnabled:                     utils.ParseBool(os.Getenv("ECS_ENABLE_LOCAL_DNS"), false),
+		LocalDNSListenAddress:               os.Getenv("ECS_LOCAL_DNS_LISTEN_ADDRESS"),
+		TaskRestartLimit:                    parseTaskRestartLimit(),
+		TaskRestartLimitWindow:              parseEnvVariableDuration("ECS_TASK_RESTART_LIMIT_WINDOW"),
+		StateMirrorS3ARN:                    os.Getenv("ECS_STATE_MIRROR_S3_ARN"),
+		StateMirrorInterval:                 parseEnvVariableDuration("ECS_STATE_MIRROR_INTERVAL"),
+		TaskBridgeNetworkEnabled:            utils.ParseBool(os.Getenv("ECS_ENABLE_TASK_BRIDGE_NETWORK"), false),
+		TaskNetworkCleanupAttempts:          parseTaskNetworkCleanupAttempts(),
+		StateChangeAggregationWindow:        parseEnvVariableDuration("ECS_STATE_CHANGE_AGGREGATION_WINDOW"),
+		StaticTasksDir:                      os.Getenv("ECS_STATIC_TASKS_DIR"),
+		PreflightChecksDisabled:             utils.ParseBool(os.Getenv("ECS_DISABLE_PREFLIGHT_CHECKS"), false),
+		EnabledExperiments:                  parseEnabledExperiments(),
+		ErrorBudgetThresholds:               errorBudgetThresholds,
+		ErrorBudgetWindow:                   parseEnvVariableDuration("ECS_ERROR_BUDGET_WINDOW"),
+		ErrorBudgetWebhookURL:               os.Getenv("ECS_ERROR_BUDGET_WEBHOOK_URL"),
+		ErrorBudgetSNSTopicARN:              os.Getenv("ECS_ERROR_BUDGET_SNS_TOPIC_ARN"),
+		RestoreReconcileTimeout:             parseEnvVariableDuration("ECS_RESTORE_RECONCILE_TIMEOUT"),
+		ContainerHealthEventMarker:          os.Getenv("ECS_CONTAINER_HEALTH_EVENT_MARKER"),
+		AWSVPCPublishedPorts:                parseReservedPorts("ECS_AWSVPC_PUBLISHED_PORTS"),
+		TaskEphemeralStorageEnabled:         utils.ParseBool(os.Getenv("ECS_ENABLE_TASK_EPHEMERAL_STORAGE"), false),
+		EssentialContainerOOMScoreAdj:       parseOOMScoreAdj("ECS_ESSENTIAL_CONTAINER_OOM_SCORE_ADJ"),
+		NonEssentialContainerOOMScoreAdj:    parseOOMScoreAdj("ECS_NONESSENTIAL_CONTAINER_OOM_SCORE_ADJ"),
+		DefaultNetworkMode:                  parseDefaultNetworkMode(),
+		DisallowedNetworkModes:              parseDisallowedNetworkModes(),
+		TaskRuntimeSettingsEnabled:          utils.ParseBool(os.Getenv("ECS_ENABLE_TASK_RUNTIME_SETTINGS"), false),
+		InstanceDrainGracePeriod:            parseEnvVariableDuration("ECS_INSTANCE_DRAIN_GRACE_PERIOD"),
+		CNIMaxConcurrency:                   parseCNIMaxConcurrency(),
+		CNISetupTimeout:                     parseEnvVariableDuration("ECS_CNI_SETUP_TIMEOUT"),
+		TaskLaunchMaxConcurrency:            parseTaskLaunchMaxConcurrency(),
+		TaskLaunchRate:                      taskLaunchRate,
+		TaskLaunchBurst:                     taskLaunchBurst,
+		TaskCleanupWaitJitter:               parseEnvVariableDuration("ECS_ENGINE_TASK_CLEANUP_WAIT_JITTER"),
+		DeprecatedFieldsDisabled:            utils.ParseBool(os.Getenv("ECS_DISABLE_INTROSPECTION_DEPRECATED_FIELDS"), false),
+		CNIAdditionalPlugins:                cniAdditionalPlugins,
 	}, err
 }
 
@@ -576,6 +943,7 @@ func (cfg *Config) String() string {
 			"DisableMetrics: %v, "+
 			"PollMetrics: %v, "+
 			"PollingMetricsWaitDuration: %v, "+
+			"TelemetryPublishInterval: %v, "+
 			"ReservedMem: %v, "+
 			"TaskCleanupWaitDuration: %v, "+
 			"DockerStopTimeout: %v, "+
@@ -591,6 +959,7 @@ func (cfg *Config) String() string {
 		cfg.DisableMetrics,
 		cfg.PollMetrics,
 		cfg.PollingMetricsWaitDuration,
+		cfg.TelemetryPublishInterval,
 		cfg.ReservedMemory,
 		cfg.TaskCleanupWaitDuration,
 		cfg.DockerStopTimeout,
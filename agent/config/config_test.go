@@ -25,10 +25,13 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/dockerclient"
 	"github.com/aws/amazon-ecs-agent/agent/ec2"
 	mock_ec2 "github.com/aws/amazon-ecs-agent/agent/ec2/mocks"
+	"github.com/aws/amazon-ecs-agent/agent/experiments"
 
 	"github.com/aws/aws-sdk-go/aws/ec2metadata"
+	"github.com/docker/go-units"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestMerge(t *testing.T) {
@@ -118,6 +121,8 @@ func TestEnvironmentConfig(t *testing.T) {
 	defer setTestEnv("ECS_NVIDIA_RUNTIME", "nvidia")()
 	defer setTestEnv("ECS_POLL_METRICS", "true")()
 	defer setTestEnv("ECS_POLLING_METRICS_WAIT_DURATION", "10s")()
+	defer setTestEnv("ECS_TELEMETRY_PUBLISH_INTERVAL", "10s")()
+	defer setTestEnv("ECS_ENABLE_CGROUP_STATS", "true")()
 	defer setTestEnv("ECS_CGROUP_CPU_PERIOD", "")
 	additionalLocalRoutesJSON := `["1.2.3.4/22","5.6.7.8/32"]`
 	setTestEnv("ECS_AWSVPC_ADDITIONAL_LOCAL_ROUTES", additionalLocalRoutesJSON)
@@ -147,6 +152,8 @@ func TestEnvironmentConfig(t *testing.T) {
 	assert.True(t, conf.PollMetrics, "Wrong value for PollMetrics")
 	expectedDurationPollingMetricsWaitDuration, _ := time.ParseDuration("10s")
 	assert.Equal(t, expectedDurationPollingMetricsWaitDuration, conf.PollingMetricsWaitDuration)
+	assert.Equal(t, 10*time.Second, conf.TelemetryPublishInterval)
+	assert.True(t, conf.CgroupStatsEnabled, "Wrong value for CgroupStatsEnabled")
 	assert.True(t, conf.TaskENIEnabled, "Wrong value for TaskNetwork")
 	assert.Equal(t, (30 * time.Minute), conf.MinimumImageDeletionAge)
 	assert.Equal(t, (30 * time.Minute), conf.NonECSMinimumImageDeletionAge)
@@ -227,6 +234,21 @@ func TestBadTagsSerialization(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestPullThroughCacheRules(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_PULL_THROUGH_CACHE_RULES", `{"quay.io": "012345678910.dkr.ecr.us-west-2.amazonaws.com/quay"}`)()
+	conf, err := environmentConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"quay.io": "012345678910.dkr.ecr.us-west-2.amazonaws.com/quay"}, conf.PullThroughCacheRules)
+}
+
+func TestBadPullThroughCacheRulesSerialization(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_PULL_THROUGH_CACHE_RULES", "This is not valid JSON")()
+	_, err := environmentConfig()
+	assert.Error(t, err)
+}
+
 func TestInvalidLoggingDriver(t *testing.T) {
 	conf := DefaultConfig()
 	conf.AWSRegion = "us-west-2"
@@ -367,6 +389,40 @@ func TestInvalidValueMinPollingMetricsWaitDuration(t *testing.T) {
 	assert.Equal(t, conf.PollingMetricsWaitDuration, DefaultPollingMetricsWaitDuration, "Wrong value for PollingMetricsWaitDuration")
 }
 
+func TestPollingMetricsWaitDurationFollowsTelemetryPublishInterval(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_POLL_METRICS", "true")()
+	defer setTestEnv("ECS_POLLING_METRICS_WAIT_DURATION", "45s")()
+	defer setTestEnv("ECS_TELEMETRY_PUBLISH_INTERVAL", "1m")()
+	conf, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, 45*time.Second, conf.PollingMetricsWaitDuration, "Wrong value for PollingMetricsWaitDuration")
+	assert.Equal(t, time.Minute, conf.TelemetryPublishInterval, "Wrong value for TelemetryPublishInterval")
+}
+
+func TestDefaultTelemetryPublishInterval(t *testing.T) {
+	defer setTestRegion()()
+	conf, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultTelemetryPublishInterval, conf.TelemetryPublishInterval, "Wrong value for TelemetryPublishInterval")
+}
+
+func TestInvalidValueMaxTelemetryPublishInterval(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_TELEMETRY_PUBLISH_INTERVAL", "2m")()
+	conf, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultTelemetryPublishInterval, conf.TelemetryPublishInterval, "Wrong value for TelemetryPublishInterval")
+}
+
+func TestInvalidValueMinTelemetryPublishInterval(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_TELEMETRY_PUBLISH_INTERVAL", "1s")()
+	conf, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultTelemetryPublishInterval, conf.TelemetryPublishInterval, "Wrong value for TelemetryPublishInterval")
+}
+
 func TestInvalidFormatParseEnvVariableUint16(t *testing.T) {
 	defer setTestRegion()()
 	setTestEnv("FOO", "foo")
@@ -422,6 +478,603 @@ func TestTaskCleanupTimeout(t *testing.T) {
 	assert.Equal(t, cfg.TaskCleanupWaitDuration, 10*time.Minute, "Task cleanup wait duration set incorrectly")
 }
 
+func TestImagePullMinimumBandwidth(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_IMAGE_PULL_MINIMUM_BANDWIDTH", "2MB")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2000000), cfg.ImagePullMinimumBandwidth)
+}
+
+func TestInvalidImagePullMinimumBandwidth(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_IMAGE_PULL_MINIMUM_BANDWIDTH", "fast")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Zero(t, cfg.ImagePullMinimumBandwidth)
+}
+
+func TestAWSVPCDNSOptions(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_AWSVPC_DNS_OPTIONS", "ndots:2 timeout:0 attempts:3 rotate debug ndots")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"ndots:2", "attempts:3", "rotate"}, cfg.AWSVPCDNSOptions)
+}
+
+func TestAWSVPCDNSServersAndSearchDomains(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_AWSVPC_DNS_SERVERS", "10.0.0.2 invalid 2600:1f14:abcd::2 10.0.0.3 10.0.0.4")()
+	defer setTestEnv("ECS_AWSVPC_DNS_SEARCH_DOMAINS", "corp.example.com  example.com")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.2", "2600:1f14:abcd::2", "10.0.0.3"}, cfg.AWSVPCDNSServers)
+	assert.Equal(t, []string{"corp.example.com", "example.com"}, cfg.AWSVPCDNSSearchDomains)
+}
+
+func TestSecurityBaseline(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_ENABLE_SECURITY_BASELINE", "true")()
+	defer setTestEnv("ECS_SECURITY_BASELINE_ALLOW_OPT_OUT", "true")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.True(t, cfg.SecurityBaselineEnabled, "Wrong value for SecurityBaselineEnabled")
+	assert.True(t, cfg.SecurityBaselineAllowOptOut, "Wrong value for SecurityBaselineAllowOptOut")
+}
+
+func TestAllowedKernelCapabilities(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_ALLOWED_KERNEL_CAPABILITIES", `["NET_ADMIN", "SYS_PTRACE"]`)()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"NET_ADMIN", "SYS_PTRACE"}, cfg.AllowedKernelCapabilities)
+}
+
+func TestInvalidAllowedKernelCapabilities(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_ALLOWED_KERNEL_CAPABILITIES", "NET_ADMIN")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.NotContains(t, cfg.AllowedKernelCapabilities, "NET_ADMIN")
+}
+
+func TestContainerMetadataStartDependency(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_CONTAINER_METADATA_START_DEPENDENCY", "true")()
+	defer setTestEnv("ECS_CONTAINER_METADATA_MAX_START_DELAY", "30s")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.True(t, cfg.ContainerMetadataStartDependency)
+	assert.Equal(t, 30*time.Second, cfg.ContainerMetadataMaxStartDelay)
+}
+
+func TestContainerMetadataDefaultMaxStartDelay(t *testing.T) {
+	defer setTestRegion()()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.False(t, cfg.ContainerMetadataStartDependency)
+	assert.Equal(t, defaultContainerMetadataMaxStartDelay, cfg.ContainerMetadataMaxStartDelay)
+}
+
+func TestImagePolicy(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_IMAGE_POLICY_REQUIRED_LABELS", `{"org.example.team": ""}`)()
+	defer setTestEnv("ECS_IMAGE_POLICY_DISALLOWED_DIGESTS", `["sha256:abc"]`)()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"org.example.team": ""}, cfg.ImagePolicyRequiredLabels)
+	assert.Equal(t, []string{"sha256:abc"}, cfg.ImagePolicyDisallowedDigests)
+}
+
+func TestInvalidImagePolicy(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_IMAGE_POLICY_DISALLOWED_DIGESTS", "sha256:abc")()
+	_, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.Error(t, err)
+}
+
+func TestDefaultUlimits(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_DEFAULT_ULIMITS", `["nofile=1024:4096", "nproc=2048"]`)()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, []*units.Ulimit{
+		{Name: "nofile", Soft: 1024, Hard: 4096},
+		{Name: "nproc", Soft: 2048, Hard: 2048},
+	}, cfg.DefaultUlimits)
+}
+
+func TestInvalidDefaultUlimits(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_DEFAULT_ULIMITS", `["nofile=4096:1024"]`)()
+	_, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.Error(t, err)
+}
+
+func TestMultiTenantIsolation(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_ENABLE_MULTI_TENANT_ISOLATION", "true")()
+	defer setTestEnv("ECS_SECURITY_BASELINE_ALLOW_OPT_OUT", "true")()
+	defer setTestEnv("ECS_ENABLE_TASK_IAM_ROLE_NETWORK_HOST", "true")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.True(t, cfg.MultiTenantIsolationEnabled, "Wrong value for MultiTenantIsolationEnabled")
+	assert.True(t, cfg.SecurityBaselineEnabled, "Wrong value for SecurityBaselineEnabled")
+	assert.False(t, cfg.SecurityBaselineAllowOptOut, "Wrong value for SecurityBaselineAllowOptOut")
+	assert.True(t, cfg.PrivilegedDisabled, "Wrong value for PrivilegedDisabled")
+	assert.False(t, cfg.TaskIAMRoleEnabledForNetworkHost, "Wrong value for TaskIAMRoleEnabledForNetworkHost")
+	assert.True(t, cfg.TaskBridgeNetworkEnabled, "Wrong value for TaskBridgeNetworkEnabled")
+	assert.True(t, cfg.AWSVPCBlockInstanceMetdata, "Wrong value for AWSVPCBlockInstanceMetdata")
+}
+
+func TestImageVerification(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_IMAGE_VERIFICATION_HOOK", "/usr/bin/verify-image")()
+	defer setTestEnv("ECS_IMAGE_VERIFICATION_TIMEOUT", "30s")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, "/usr/bin/verify-image", cfg.ImageVerificationHook)
+	assert.Equal(t, 30*time.Second, cfg.ImageVerificationTimeout)
+}
+
+func TestStateChangeAggregationWindow(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_STATE_CHANGE_AGGREGATION_WINDOW", "2s")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, 2*time.Second, cfg.StateChangeAggregationWindow)
+}
+
+func TestStateChangeAggregationWindowTooLong(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_STATE_CHANGE_AGGREGATION_WINDOW", "1m")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, maximumStateChangeAggregationWindow, cfg.StateChangeAggregationWindow)
+}
+
+func TestImageRepull(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_IMAGE_REPULL_IMAGES", `["nginx:latest", "public.ecr.aws/agent:stable"]`)()
+	defer setTestEnv("ECS_IMAGE_REPULL_INTERVAL", "5m")()
+	defer setTestEnv("ECS_IMAGE_REPULL_ACTION", "pull")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"nginx:latest", "public.ecr.aws/agent:stable"}, cfg.ImageRepullImages)
+	assert.Equal(t, 5*time.Minute, cfg.ImageRepullInterval)
+	assert.Equal(t, ImageRepullPull, cfg.ImageRepullAction)
+}
+
+func TestInvalidImageRepull(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_IMAGE_REPULL_IMAGES", "nginx:latest")()
+	defer setTestEnv("ECS_IMAGE_REPULL_INTERVAL", "10s")()
+	defer setTestEnv("ECS_IMAGE_REPULL_ACTION", "invalid")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Empty(t, cfg.ImageRepullImages)
+	assert.Equal(t, minimumImageRepullInterval, cfg.ImageRepullInterval)
+	assert.Equal(t, ImageRepullNotify, cfg.ImageRepullAction)
+}
+
+func TestDefaultImageVerificationTimeout(t *testing.T) {
+	defer setTestRegion()()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, defaultImageVerificationTimeout, cfg.ImageVerificationTimeout)
+}
+
+func TestRegistryAuth(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_REGISTRY_AUTH_CONFIG", `{
+		"registry.example.com": {"authTypes": ["credentialhelper", "docker"], "credentialHelper": "example"},
+		"123456789012.dkr.ecr.us-west-2.amazonaws.com": {"authTypes": ["ecr"]},
+		"invalid.example.com": {"authTypes": ["credentialhelper"]},
+		"unknown.example.com": {"authTypes": ["unknown"]}
+	}`)()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]RegistryAuthConfig{
+		"registry.example.com": {
+			AuthTypes:        []string{RegistryAuthTypeCredentialHelper, RegistryAuthTypeDocker},
+			CredentialHelper: "example",
+		},
+		"123456789012.dkr.ecr.us-west-2.amazonaws.com": {AuthTypes: []string{RegistryAuthTypeECR}},
+	}, cfg.RegistryAuth)
+}
+
+func TestCNIAdditionalPlugins(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_CNI_ADDITIONAL_PLUGINS", `[
+		{"type": "mirror", "config": {"target": "10.0.0.10", "source": "{{.ENIIPv4Address}}"}},
+		{"type": "snat", "ifName": "ecs-eth0", "config": {"snat": true}},
+		{"type": "", "config": {}},
+		{"type": "../../bin/sh", "config": {}},
+		{"type": "array", "config": []},
+		{"type": "template", "config": {"task": "{{.TaskARN"}}
+	]`)()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	require.Len(t, cfg.CNIAdditionalPlugins, 2)
+	assert.Equal(t, "mirror", cfg.CNIAdditionalPlugins[0].Type)
+	assert.JSONEq(t, `{"target": "10.0.0.10", "source": "{{.ENIIPv4Address}}"}`,
+		string(cfg.CNIAdditionalPlugins[0].ConfigTemplate))
+	assert.Equal(t, "snat", cfg.CNIAdditionalPlugins[1].Type)
+	assert.Equal(t, "ecs-eth0", cfg.CNIAdditionalPlugins[1].IfName)
+}
+
+func TestInvalidFormatCNIAdditionalPlugins(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_CNI_ADDITIONAL_PLUGINS", `{"type": "mirror"}`)()
+	_, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.Error(t, err)
+}
+
+func TestInvalidFormatRegistryAuth(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_REGISTRY_AUTH_CONFIG", `["ecr"]`)()
+	_, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.Error(t, err)
+}
+
+func TestEndpointVariants(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_ENABLE_FIPS_ENDPOINTS", "true")()
+	defer setTestEnv("ECS_ENABLE_DUALSTACK_ENDPOINTS", "true")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.True(t, cfg.FIPSEndpointsEnabled)
+	assert.True(t, cfg.DualStackEndpointsEnabled)
+}
+
+func TestLocalDNS(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_ENABLE_LOCAL_DNS", "true")()
+	defer setTestEnv("ECS_LOCAL_DNS_LISTEN_ADDRESS", "10.0.0.10:53")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.True(t, cfg.LocalDNSEnabled)
+	assert.Equal(t, "10.0.0.10:53", cfg.LocalDNSListenAddress)
+}
+
+func TestInvalidLocalDNSListenAddress(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_LOCAL_DNS_LISTEN_ADDRESS", "172.17.0.1")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.False(t, cfg.LocalDNSEnabled)
+	assert.Equal(t, defaultLocalDNSListenAddress, cfg.LocalDNSListenAddress)
+}
+
+func TestStateMirror(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_STATE_MIRROR_S3_ARN", "arn:aws:s3:::bucket/mirror")()
+	defer setTestEnv("ECS_STATE_MIRROR_INTERVAL", "10m")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, "arn:aws:s3:::bucket/mirror", cfg.StateMirrorS3ARN)
+	assert.Equal(t, 10*time.Minute, cfg.StateMirrorInterval)
+}
+
+func TestErrorBudget(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_ERROR_BUDGET_THRESHOLDS", `{"docker": 10, "state-save": 3}`)()
+	defer setTestEnv("ECS_ERROR_BUDGET_WINDOW", "5m")()
+	defer setTestEnv("ECS_ERROR_BUDGET_WEBHOOK_URL", "http://localhost:8080/alarm")()
+	defer setTestEnv("ECS_ERROR_BUDGET_SNS_TOPIC_ARN", "arn:aws:sns:us-west-2:123456789012:agent-alarms")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"docker": 10, "state-save": 3}, cfg.ErrorBudgetThresholds)
+	assert.Equal(t, 5*time.Minute, cfg.ErrorBudgetWindow)
+	assert.Equal(t, "http://localhost:8080/alarm", cfg.ErrorBudgetWebhookURL)
+	assert.Equal(t, "arn:aws:sns:us-west-2:123456789012:agent-alarms", cfg.ErrorBudgetSNSTopicARN)
+}
+
+func TestErrorBudgetDefaultWindow(t *testing.T) {
+	defer setTestRegion()()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Empty(t, cfg.ErrorBudgetThresholds)
+	assert.Equal(t, defaultErrorBudgetWindow, cfg.ErrorBudgetWindow)
+}
+
+func TestInvalidErrorBudgetThresholds(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_ERROR_BUDGET_THRESHOLDS", `{"docker": "ten"}`)()
+	_, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.Error(t, err)
+}
+
+func TestRestoreReconcileTimeout(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_RESTORE_RECONCILE_TIMEOUT", "2m")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, 2*time.Minute, cfg.RestoreReconcileTimeout)
+}
+
+func TestRestoreReconcileDefaultTimeout(t *testing.T) {
+	defer setTestRegion()()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, defaultRestoreReconcileTimeout, cfg.RestoreReconcileTimeout)
+}
+
+func TestContainerHealthEventMarker(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_CONTAINER_HEALTH_EVENT_MARKER", "@health ")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, "@health ", cfg.ContainerHealthEventMarker)
+}
+
+func TestContainerHealthEventDefaultMarker(t *testing.T) {
+	defer setTestRegion()()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultContainerHealthEventMarker, cfg.ContainerHealthEventMarker)
+}
+
+func TestAWSVPCPublishedPorts(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_AWSVPC_PUBLISHED_PORTS", "[80,443]")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, []uint16{80, 443}, cfg.AWSVPCPublishedPorts)
+}
+
+func TestTaskEphemeralStorageEnabled(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_ENABLE_TASK_EPHEMERAL_STORAGE", "true")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.True(t, cfg.TaskEphemeralStorageEnabled)
+}
+
+func TestContainerOOMScoreAdj(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_ESSENTIAL_CONTAINER_OOM_SCORE_ADJ", "-500")()
+	defer setTestEnv("ECS_NONESSENTIAL_CONTAINER_OOM_SCORE_ADJ", "500")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, -500, cfg.EssentialContainerOOMScoreAdj)
+	assert.Equal(t, 500, cfg.NonEssentialContainerOOMScoreAdj)
+}
+
+func TestInvalidContainerOOMScoreAdj(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_ESSENTIAL_CONTAINER_OOM_SCORE_ADJ", "-1001")()
+	defer setTestEnv("ECS_NONESSENTIAL_CONTAINER_OOM_SCORE_ADJ", "high")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Zero(t, cfg.EssentialContainerOOMScoreAdj)
+	assert.Zero(t, cfg.NonEssentialContainerOOMScoreAdj)
+}
+
+func TestNetworkModePolicy(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_DEFAULT_NETWORK_MODE", "none")()
+	defer setTestEnv("ECS_DISALLOWED_NETWORK_MODES", `["host","awsvpc"]`)()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, "none", cfg.DefaultNetworkMode)
+	assert.Equal(t, []string{"host", "awsvpc"}, cfg.DisallowedNetworkModes)
+}
+
+func TestInvalidNetworkModePolicy(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_DEFAULT_NETWORK_MODE", "awsvpc")()
+	defer setTestEnv("ECS_DISALLOWED_NETWORK_MODES", "host")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Empty(t, cfg.DefaultNetworkMode)
+	assert.Empty(t, cfg.DisallowedNetworkModes)
+}
+
+func TestTaskRuntimeSettingsEnabled(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_ENABLE_TASK_RUNTIME_SETTINGS", "true")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.True(t, cfg.TaskRuntimeSettingsEnabled)
+}
+
+func TestInstanceDrainGracePeriod(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_INSTANCE_DRAIN_GRACE_PERIOD", "2m")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, 2*time.Minute, cfg.InstanceDrainGracePeriod)
+}
+
+func TestCNIProvisioning(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_CNI_MAX_CONCURRENCY", "8")()
+	defer setTestEnv("ECS_CNI_SETUP_TIMEOUT", "2m")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, 8, cfg.CNIMaxConcurrency)
+	assert.Equal(t, 2*time.Minute, cfg.CNISetupTimeout)
+}
+
+func TestDefaultCNIProvisioning(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_CNI_MAX_CONCURRENCY", "invalid")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, defaultCNIMaxConcurrency, cfg.CNIMaxConcurrency)
+	assert.Equal(t, defaultCNISetupTimeout, cfg.CNISetupTimeout)
+}
+
+func TestTaskLaunchThrottling(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_TASK_LAUNCH_MAX_CONCURRENCY", "5")()
+	defer setTestEnv("ECS_TASK_LAUNCH_RATE_LIMIT", "2,10")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, 5, cfg.TaskLaunchMaxConcurrency)
+	assert.Equal(t, 2, cfg.TaskLaunchRate)
+	assert.Equal(t, 10, cfg.TaskLaunchBurst)
+}
+
+func TestInvalidTaskLaunchThrottling(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_TASK_LAUNCH_MAX_CONCURRENCY", "-1")()
+	defer setTestEnv("ECS_TASK_LAUNCH_RATE_LIMIT", "2")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Zero(t, cfg.TaskLaunchMaxConcurrency)
+	assert.Zero(t, cfg.TaskLaunchRate)
+	assert.Zero(t, cfg.TaskLaunchBurst)
+}
+
+func TestDynamicHostPortRange(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_DYNAMIC_HOST_PORT_RANGE", "40000-49999")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(40000), cfg.DynamicHostPortRangeStart)
+	assert.Equal(t, uint16(49999), cfg.DynamicHostPortRangeEnd)
+}
+
+func TestInvalidDynamicHostPortRange(t *testing.T) {
+	for _, portRange := range []string{"40000", "49999-40000", "0-100", "40000-70000", "a-b"} {
+		t.Run(portRange, func(t *testing.T) {
+			defer setTestRegion()()
+			defer setTestEnv("ECS_DYNAMIC_HOST_PORT_RANGE", portRange)()
+			cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+			assert.NoError(t, err)
+			assert.Zero(t, cfg.DynamicHostPortRangeStart)
+			assert.Zero(t, cfg.DynamicHostPortRangeEnd)
+		})
+	}
+}
+
+func TestAWSVPCTaskMTU(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_AWSVPC_TASK_MTU", "9001")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, 9001, cfg.AWSVPCTaskMTU)
+}
+
+func TestInvalidAWSVPCTaskMTU(t *testing.T) {
+	for _, mtu := range []string{"mtu", "576", "9216"} {
+		t.Run(mtu, func(t *testing.T) {
+			defer setTestRegion()()
+			defer setTestEnv("ECS_AWSVPC_TASK_MTU", mtu)()
+			cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+			assert.NoError(t, err)
+			assert.Zero(t, cfg.AWSVPCTaskMTU)
+		})
+	}
+}
+
+func TestTaskCleanupWaitJitter(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_ENGINE_TASK_CLEANUP_WAIT_JITTER", "10m")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, 10*time.Minute, cfg.TaskCleanupWaitJitter)
+}
+
+func TestInvalidTaskCleanupWaitJitter(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_ENGINE_TASK_CLEANUP_WAIT_JITTER", "-10m")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Zero(t, cfg.TaskCleanupWaitJitter)
+}
+
+func TestStaticTasksDir(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_STATIC_TASKS_DIR", "/etc/ecs/static-tasks")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, "/etc/ecs/static-tasks", cfg.StaticTasksDir)
+}
+
+func TestInvalidStateMirror(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_STATE_MIRROR_S3_ARN", "s3://bucket/mirror")()
+	defer setTestEnv("ECS_STATE_MIRROR_INTERVAL", "10s")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Empty(t, cfg.StateMirrorS3ARN)
+	assert.Equal(t, minimumStateMirrorInterval, cfg.StateMirrorInterval)
+}
+
+func TestTaskBridgeNetwork(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_ENABLE_TASK_BRIDGE_NETWORK", "true")()
+	defer setTestEnv("ECS_TASK_NETWORK_CLEANUP_ATTEMPTS", "3")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.True(t, cfg.TaskBridgeNetworkEnabled)
+	assert.Equal(t, 3, cfg.TaskNetworkCleanupAttempts)
+}
+
+func TestDefaultTaskNetworkCleanupAttempts(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_TASK_NETWORK_CLEANUP_ATTEMPTS", "invalid")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.False(t, cfg.TaskBridgeNetworkEnabled)
+	assert.Equal(t, defaultTaskNetworkCleanupAttempts, cfg.TaskNetworkCleanupAttempts)
+}
+
+func TestEnabledExperiments(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_ENABLED_EXPERIMENTS", "lazy-pull, time-travel,cgroup-v2")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{experiments.LazyPull}, cfg.EnabledExperiments)
+}
+
+func TestPreflightChecksDisabled(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_DISABLE_PREFLIGHT_CHECKS", "true")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.True(t, cfg.PreflightChecksDisabled)
+}
+
+func TestTaskRestartLimit(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_TASK_RESTART_LIMIT", "5")()
+	defer setTestEnv("ECS_TASK_RESTART_LIMIT_WINDOW", "30m")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, 5, cfg.TaskRestartLimit)
+	assert.Equal(t, 30*time.Minute, cfg.TaskRestartLimitWindow)
+}
+
+func TestDefaultTaskRestartLimit(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_TASK_RESTART_LIMIT", "invalid")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, defaultTaskRestartLimit, cfg.TaskRestartLimit)
+	assert.Equal(t, defaultTaskRestartLimitWindow, cfg.TaskRestartLimitWindow)
+}
+
+func TestENIAttachmentAckTimeout(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_ENI_ATTACHMENT_ACK_TIMEOUT", "5m")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, 5*time.Minute, cfg.ENIAttachmentAckTimeout, "Wrong value for ENIAttachmentAckTimeout")
+}
+
+func TestInvalidENIAttachmentAckTimeoutOverridesToZero(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_ENI_ATTACHMENT_ACK_TIMEOUT", "1s")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	// If an invalid value is set, the timeout from the attachment message is used
+	assert.Zero(t, cfg.ENIAttachmentAckTimeout, "Wrong value for ENIAttachmentAckTimeout")
+}
+
 func TestInvalidReservedMemoryOverridesToZero(t *testing.T) {
 	defer setTestRegion()()
 	defer setTestEnv("ECS_RESERVED_MEMORY", "-1")()
@@ -555,6 +1208,48 @@ func TestParseImagePullBehavior(t *testing.T) {
 	}
 }
 
+func TestParseImagePullDigestMode(t *testing.T) {
+	testcases := []struct {
+		name                        string
+		envVarVal                   string
+		expectedImagePullDigestMode ImagePullDigestModeType
+	}{
+		{
+			name:                        "unset",
+			envVarVal:                   "",
+			expectedImagePullDigestMode: ImagePullDigestDisabled,
+		},
+		{
+			name:                        "disabled",
+			envVarVal:                   "disabled",
+			expectedImagePullDigestMode: ImagePullDigestDisabled,
+		},
+		{
+			name:                        "resolve",
+			envVarVal:                   "resolve",
+			expectedImagePullDigestMode: ImagePullDigestResolve,
+		},
+		{
+			name:                        "require",
+			envVarVal:                   "require",
+			expectedImagePullDigestMode: ImagePullDigestRequire,
+		},
+		{
+			name:                        "invalid",
+			envVarVal:                   "invalid",
+			expectedImagePullDigestMode: ImagePullDigestDisabled,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			defer setTestRegion()()
+			defer setTestEnv("ECS_IMAGE_PULL_DIGEST_MODE", tc.envVarVal)()
+			assert.Equal(t, tc.expectedImagePullDigestMode, parseImagePullDigestMode(), "Wrong value for ImagePullDigestMode")
+		})
+	}
+}
+
 func TestTaskResourceLimitsOverride(t *testing.T) {
 	defer setTestRegion()()
 	defer setTestEnv("ECS_ENABLE_TASK_CPU_MEM_LIMIT", "false")()
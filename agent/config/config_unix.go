@@ -42,6 +42,28 @@ const (
 	defaultImagePullInactivityTimeout = 1 * time.Minute
 )
 
+// DefaultAllowedKernelCapabilities are the linux capabilities containers are
+// allowed to add by default. These are the capabilities docker grants to
+// containers by default, along with IPC_LOCK and SYS_NICE
+var DefaultAllowedKernelCapabilities = []string{
+	"AUDIT_WRITE",
+	"CHOWN",
+	"DAC_OVERRIDE",
+	"FOWNER",
+	"FSETID",
+	"IPC_LOCK",
+	"KILL",
+	"MKNOD",
+	"NET_BIND_SERVICE",
+	"NET_RAW",
+	"SETFCAP",
+	"SETGID",
+	"SETPCAP",
+	"SETUID",
+	"SYS_CHROOT",
+	"SYS_NICE",
+}
+
 // DefaultConfig returns the default configuration for Linux
 func DefaultConfig() Config {
 	return Config{
@@ -80,8 +102,10 @@ func DefaultConfig() Config {
 		PrometheusMetricsEnabled:            false,
 		PollMetrics:                         false,
 		PollingMetricsWaitDuration:          DefaultPollingMetricsWaitDuration,
+		TelemetryPublishInterval:            DefaultTelemetryPublishInterval,
 		NvidiaRuntime:                       DefaultNvidiaRuntime,
 		CgroupCPUPeriod:                     defaultCgroupCPUPeriod,
+		AllowedKernelCapabilities:           DefaultAllowedKernelCapabilities,
 	}
 }
 
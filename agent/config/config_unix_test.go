@@ -59,7 +59,9 @@ func TestConfigDefault(t *testing.T) {
 	assert.Equal(t, DefaultImageCleanupTimeInterval, cfg.ImageCleanupInterval, "ImageCleanupInterval default is set incorrectly")
 	assert.Equal(t, DefaultNumImagesToDeletePerCycle, cfg.NumImagesToDeletePerCycle, "NumImagesToDeletePerCycle default is set incorrectly")
 	assert.Equal(t, defaultCNIPluginsPath, cfg.CNIPluginsPath, "CNIPluginsPath default is set incorrectly")
+	assert.Equal(t, pauseContainerTarballPath, cfg.PauseContainerTarballPath, "PauseContainerTarballPath default is set incorrectly")
 	assert.False(t, cfg.AWSVPCBlockInstanceMetdata, "AWSVPCBlockInstanceMetdata default is incorrectly set")
+	assert.Equal(t, DefaultAllowedKernelCapabilities, cfg.AllowedKernelCapabilities, "AllowedKernelCapabilities default is set incorrectly")
 	assert.Equal(t, "/var/lib/ecs", cfg.DataDirOnHost, "Default DataDirOnHost set incorrectly")
 	assert.Equal(t, DefaultTaskMetadataSteadyStateRate, cfg.TaskMetadataSteadyStateRate,
 		"Default TaskMetadataSteadyStateRate is set incorrectly")
@@ -216,6 +218,14 @@ func TestENITrunkingDisabled(t *testing.T) {
 	assert.False(t, cfg.ENITrunkingEnabled, "ENI trunking should be disabled")
 }
 
+func TestPauseContainerTarballPath(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_PAUSE_CONTAINER_TARBALL_PATH", "/var/lib/ecs/images/amazon-ecs-pause.tar")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	require.NoError(t, err)
+	assert.Equal(t, "/var/lib/ecs/images/amazon-ecs-pause.tar", cfg.PauseContainerTarballPath)
+}
+
 // setupFileConfiguration create a temp file store the configuration
 func setupFileConfiguration(t *testing.T, configContent string) string {
 	file, err := ioutil.TempFile("", "ecs-test")
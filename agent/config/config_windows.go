@@ -103,6 +103,7 @@ func DefaultConfig() Config {
 		SharedVolumeMatchFullConfig:         false, //only requiring shared volumes to match on name, which is default docker behavior
 		PollMetrics:                         false,
 		PollingMetricsWaitDuration:          DefaultPollingMetricsWaitDuration,
+		TelemetryPublishInterval:            DefaultTelemetryPublishInterval,
 	}
 }
 
@@ -119,6 +120,10 @@ func (cfg *Config) platformOverrides() {
 	// ensure TaskResourceLimit is disabled
 	cfg.TaskCPUMemLimit = ExplicitlyDisabled
 
+	// ensure the stats of the containers are read from docker, there's no
+	// cgroup on windows
+	cfg.CgroupStatsEnabled = false
+
 	cpuUnbounded := utils.ParseBool(os.Getenv("ECS_ENABLE_CPU_UNBOUNDED_WINDOWS_WORKAROUND"), false)
 	memoryUnbounded := utils.ParseBool(os.Getenv("ECS_ENABLE_MEMORY_UNBOUNDED_WINDOWS_WORKAROUND"), false)
 
@@ -17,15 +17,18 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"os"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/aws/amazon-ecs-agent/agent/dockerclient"
+	"github.com/aws/amazon-ecs-agent/agent/experiments"
 	"github.com/aws/amazon-ecs-agent/agent/utils"
 	"github.com/cihub/seelog"
 	cnitypes "github.com/containernetworking/cni/pkg/types"
+	"github.com/docker/go-units"
 )
 
 func parseCheckpoint(dataDir string) bool {
@@ -104,6 +107,19 @@ func parseImagePullInactivityTimeout() time.Duration {
 	return imagePullInactivityTimeout
 }
 
+func parseImagePullMinimumBandwidth() int64 {
+	bandwidthEnv := os.Getenv("ECS_IMAGE_PULL_MINIMUM_BANDWIDTH")
+	if bandwidthEnv == "" {
+		return 0
+	}
+	bandwidth, err := units.FromHumanSize(bandwidthEnv)
+	if err != nil || bandwidth <= 0 {
+		seelog.Warnf("Invalid format for \"ECS_IMAGE_PULL_MINIMUM_BANDWIDTH\", expected a size per second such as 1MB, using a fixed image pull timeout instead. Parsed value: %s", bandwidthEnv)
+		return 0
+	}
+	return bandwidth
+}
+
 func parseAvailableLoggingDrivers() []dockerclient.LoggingDriver {
 	availableLoggingDriversEnv := os.Getenv("ECS_AVAILABLE_LOGGING_DRIVERS")
 	loggingDriverDecoder := json.NewDecoder(strings.NewReader(availableLoggingDriversEnv))
@@ -120,6 +136,20 @@ func parseAvailableLoggingDrivers() []dockerclient.LoggingDriver {
 	return availableLoggingDrivers
 }
 
+func parseAllowedKernelCapabilities() []string {
+	capabilitiesEnv := os.Getenv("ECS_ALLOWED_KERNEL_CAPABILITIES")
+	if capabilitiesEnv == "" {
+		return nil
+	}
+	var capabilities []string
+	err := json.Unmarshal([]byte(capabilitiesEnv), &capabilities)
+	if err != nil {
+		seelog.Warnf("Invalid format for \"ECS_ALLOWED_KERNEL_CAPABILITIES\" environment variable; expected a JSON array like [\"SYS_NICE\"]. err %v", err)
+		return nil
+	}
+	return capabilities
+}
+
 func parseNumImagesToDeletePerCycle() int {
 	numImagesToDeletePerCycleEnvVal := os.Getenv("ECS_NUM_IMAGES_DELETE_PER_CYCLE")
 	numImagesToDeletePerCycle, err := strconv.Atoi(numImagesToDeletePerCycleEnvVal)
@@ -139,6 +169,64 @@ func parseNumNonECSContainersToDeletePerCycle() int {
 	return numNonEcsContainersToDeletePerCycle
 }
 
+func parseTaskRestartLimit() int {
+	taskRestartLimitEnvVal := os.Getenv("ECS_TASK_RESTART_LIMIT")
+	taskRestartLimit, err := strconv.Atoi(taskRestartLimitEnvVal)
+	if taskRestartLimitEnvVal != "" && err != nil {
+		seelog.Warnf("Invalid format for \"ECS_TASK_RESTART_LIMIT\", expected an integer. err %v", err)
+	}
+	return taskRestartLimit
+}
+
+func parseTaskNetworkCleanupAttempts() int {
+	taskNetworkCleanupAttemptsEnvVal := os.Getenv("ECS_TASK_NETWORK_CLEANUP_ATTEMPTS")
+	taskNetworkCleanupAttempts, err := strconv.Atoi(taskNetworkCleanupAttemptsEnvVal)
+	if taskNetworkCleanupAttemptsEnvVal != "" && err != nil {
+		seelog.Warnf("Invalid format for \"ECS_TASK_NETWORK_CLEANUP_ATTEMPTS\", expected an integer. err %v", err)
+	}
+	return taskNetworkCleanupAttempts
+}
+
+func parseCNIMaxConcurrency() int {
+	cniMaxConcurrencyEnvVal := os.Getenv("ECS_CNI_MAX_CONCURRENCY")
+	cniMaxConcurrency, err := strconv.Atoi(cniMaxConcurrencyEnvVal)
+	if cniMaxConcurrencyEnvVal != "" && err != nil {
+		seelog.Warnf("Invalid format for \"ECS_CNI_MAX_CONCURRENCY\", expected an integer. err %v", err)
+	}
+	return cniMaxConcurrency
+}
+
+func parseAWSVPCTaskMTU() int {
+	mtuEnvVal := os.Getenv("ECS_AWSVPC_TASK_MTU")
+	if mtuEnvVal == "" {
+		return 0
+	}
+	mtu, err := strconv.Atoi(mtuEnvVal)
+	if err != nil || mtu < minAWSVPCTaskMTU || mtu > maxAWSVPCTaskMTU {
+		seelog.Warnf("Invalid format for \"ECS_AWSVPC_TASK_MTU\", expected an integer between %d and %d. err %v",
+			minAWSVPCTaskMTU, maxAWSVPCTaskMTU, err)
+		return 0
+	}
+	return mtu
+}
+
+func parseEnabledExperiments() []string {
+	experimentsEnv := os.Getenv("ECS_ENABLED_EXPERIMENTS")
+	if experimentsEnv == "" {
+		return nil
+	}
+	var enabledExperiments []string
+	for _, experiment := range strings.Split(experimentsEnv, ",") {
+		experiment = strings.TrimSpace(experiment)
+		if !experiments.IsKnown(experiment) {
+			seelog.Warnf("Unknown experiment %q in \"ECS_ENABLED_EXPERIMENTS\" environment variable, ignoring it", experiment)
+			continue
+		}
+		enabledExperiments = append(enabledExperiments, experiment)
+	}
+	return enabledExperiments
+}
+
 func parseImagePullBehavior() ImagePullBehaviorType {
 	ImagePullBehaviorString := os.Getenv("ECS_IMAGE_PULL_BEHAVIOR")
 	switch ImagePullBehaviorString {
@@ -155,6 +243,50 @@ func parseImagePullBehavior() ImagePullBehaviorType {
 	}
 }
 
+func parseImagePullDigestMode() ImagePullDigestModeType {
+	imagePullDigestModeString := os.Getenv("ECS_IMAGE_PULL_DIGEST_MODE")
+	switch imagePullDigestModeString {
+	case "resolve":
+		return ImagePullDigestResolve
+	case "require":
+		return ImagePullDigestRequire
+	case "", "disabled":
+		return ImagePullDigestDisabled
+	default:
+		seelog.Warnf("Invalid value for ECS_IMAGE_PULL_DIGEST_MODE: %s, pulling images by digest is disabled",
+			imagePullDigestModeString)
+		return ImagePullDigestDisabled
+	}
+}
+
+func parseImageRepullImages() []string {
+	imagesEnv := os.Getenv("ECS_IMAGE_REPULL_IMAGES")
+	if imagesEnv == "" {
+		return nil
+	}
+	var images []string
+	err := json.Unmarshal([]byte(imagesEnv), &images)
+	if err != nil {
+		seelog.Warnf("Invalid format for \"ECS_IMAGE_REPULL_IMAGES\" environment variable; expected a JSON array like [\"nginx:latest\"]. err %v", err)
+		return nil
+	}
+	return images
+}
+
+func parseImageRepullAction() ImageRepullActionType {
+	imageRepullActionString := os.Getenv("ECS_IMAGE_REPULL_ACTION")
+	switch imageRepullActionString {
+	case "", "notify":
+		return ImageRepullNotify
+	case "pull":
+		return ImageRepullPull
+	default:
+		seelog.Warnf("Invalid value for ECS_IMAGE_REPULL_ACTION: %s, image digest changes are only logged",
+			imageRepullActionString)
+		return ImageRepullNotify
+	}
+}
+
 func parseInstanceAttributes(errs []error) (map[string]string, []error) {
 	var instanceAttributes map[string]string
 	instanceAttributesEnv := os.Getenv("ECS_INSTANCE_ATTRIBUTES")
@@ -173,6 +305,122 @@ func parseInstanceAttributes(errs []error) (map[string]string, []error) {
 	return instanceAttributes, errs
 }
 
+func parseErrorBudgetThresholds(errs []error) (map[string]int, []error) {
+	var errorBudgetThresholds map[string]int
+	errorBudgetThresholdsEnv := os.Getenv("ECS_ERROR_BUDGET_THRESHOLDS")
+	if errorBudgetThresholdsEnv != "" {
+		err := json.Unmarshal([]byte(errorBudgetThresholdsEnv), &errorBudgetThresholds)
+		if err != nil {
+			wrappedErr := fmt.Errorf("Invalid format for ECS_ERROR_BUDGET_THRESHOLDS. Expected a json hash of integers: %v", err)
+			seelog.Error(wrappedErr)
+			errs = append(errs, wrappedErr)
+		}
+	}
+
+	return errorBudgetThresholds, errs
+}
+
+func parseDefaultUlimits(errs []error) ([]*units.Ulimit, []error) {
+	defaultUlimitsEnv := os.Getenv("ECS_DEFAULT_ULIMITS")
+	if defaultUlimitsEnv == "" {
+		return nil, errs
+	}
+	var ulimitArgs []string
+	err := json.Unmarshal([]byte(defaultUlimitsEnv), &ulimitArgs)
+	if err != nil {
+		wrappedErr := fmt.Errorf("Invalid format for ECS_DEFAULT_ULIMITS. Expected a json array like [\"nofile=1024:4096\"]: %v", err)
+		seelog.Error(wrappedErr)
+		return nil, append(errs, wrappedErr)
+	}
+	var defaultUlimits []*units.Ulimit
+	for _, ulimitArg := range ulimitArgs {
+		ulimit, err := units.ParseUlimit(ulimitArg)
+		if err != nil {
+			wrappedErr := fmt.Errorf("Invalid ulimit %s in ECS_DEFAULT_ULIMITS: %v", ulimitArg, err)
+			seelog.Error(wrappedErr)
+			errs = append(errs, wrappedErr)
+			continue
+		}
+		defaultUlimits = append(defaultUlimits, ulimit)
+	}
+	return defaultUlimits, errs
+}
+
+func parseImagePolicyRequiredLabels(errs []error) (map[string]string, []error) {
+	var requiredLabels map[string]string
+	requiredLabelsEnv := os.Getenv("ECS_IMAGE_POLICY_REQUIRED_LABELS")
+	if requiredLabelsEnv != "" {
+		err := json.Unmarshal([]byte(requiredLabelsEnv), &requiredLabels)
+		if err != nil {
+			wrappedErr := fmt.Errorf("Invalid format for ECS_IMAGE_POLICY_REQUIRED_LABELS. Expected a json hash: %v", err)
+			seelog.Error(wrappedErr)
+			errs = append(errs, wrappedErr)
+		}
+	}
+
+	return requiredLabels, errs
+}
+
+func parseImagePolicyDisallowedDigests(errs []error) ([]string, []error) {
+	var disallowedDigests []string
+	disallowedDigestsEnv := os.Getenv("ECS_IMAGE_POLICY_DISALLOWED_DIGESTS")
+	if disallowedDigestsEnv != "" {
+		err := json.Unmarshal([]byte(disallowedDigestsEnv), &disallowedDigests)
+		if err != nil {
+			wrappedErr := fmt.Errorf("Invalid format for ECS_IMAGE_POLICY_DISALLOWED_DIGESTS. Expected a json array: %v", err)
+			seelog.Error(wrappedErr)
+			errs = append(errs, wrappedErr)
+		}
+	}
+
+	return disallowedDigests, errs
+}
+
+func parsePullThroughCacheRules(errs []error) (map[string]string, []error) {
+	var pullThroughCacheRules map[string]string
+	pullThroughCacheRulesEnv := os.Getenv("ECS_PULL_THROUGH_CACHE_RULES")
+	if pullThroughCacheRulesEnv != "" {
+		err := json.Unmarshal([]byte(pullThroughCacheRulesEnv), &pullThroughCacheRules)
+		if err != nil {
+			wrappedErr := fmt.Errorf("Invalid format for ECS_PULL_THROUGH_CACHE_RULES. Expected a json hash: %v", err)
+			seelog.Error(wrappedErr)
+			errs = append(errs, wrappedErr)
+		}
+	}
+
+	return pullThroughCacheRules, errs
+}
+
+func parseRegistryAuth(errs []error) (map[string]RegistryAuthConfig, []error) {
+	var registryAuth map[string]RegistryAuthConfig
+	registryAuthEnv := os.Getenv("ECS_REGISTRY_AUTH_CONFIG")
+	if registryAuthEnv != "" {
+		err := json.Unmarshal([]byte(registryAuthEnv), &registryAuth)
+		if err != nil {
+			wrappedErr := fmt.Errorf("Invalid format for ECS_REGISTRY_AUTH_CONFIG. Expected a json hash of registries to auth configs: %v", err)
+			seelog.Error(wrappedErr)
+			errs = append(errs, wrappedErr)
+		}
+	}
+
+	return registryAuth, errs
+}
+
+func parseCNIAdditionalPlugins(errs []error) ([]CNIPluginConfig, []error) {
+	var cniAdditionalPlugins []CNIPluginConfig
+	cniAdditionalPluginsEnv := os.Getenv("ECS_CNI_ADDITIONAL_PLUGINS")
+	if cniAdditionalPluginsEnv != "" {
+		err := json.Unmarshal([]byte(cniAdditionalPluginsEnv), &cniAdditionalPlugins)
+		if err != nil {
+			wrappedErr := fmt.Errorf("Invalid format for ECS_CNI_ADDITIONAL_PLUGINS. Expected a json array of plugin configs: %v", err)
+			seelog.Error(wrappedErr)
+			errs = append(errs, wrappedErr)
+		}
+	}
+
+	return cniAdditionalPlugins, errs
+}
+
 func parseAdditionalLocalRoutes(errs []error) ([]cnitypes.IPNet, []error) {
 	var additionalLocalRoutes []cnitypes.IPNet
 	additionalLocalRoutesEnv := os.Getenv("ECS_AWSVPC_ADDITIONAL_LOCAL_ROUTES")
@@ -187,6 +435,62 @@ func parseAdditionalLocalRoutes(errs []error) ([]cnitypes.IPNet, []error) {
 	return additionalLocalRoutes, errs
 }
 
+// maxAWSVPCDNSServers is the number of nameservers the resolver reads from
+// resolv.conf
+const maxAWSVPCDNSServers = 3
+
+func parseAWSVPCDNSServers() []string {
+	var dnsServers []string
+	for _, server := range strings.Fields(os.Getenv("ECS_AWSVPC_DNS_SERVERS")) {
+		if net.ParseIP(server) == nil {
+			seelog.Warnf("Discarded invalid nameserver in ECS_AWSVPC_DNS_SERVERS: %s", server)
+			continue
+		}
+		if len(dnsServers) == maxAWSVPCDNSServers {
+			seelog.Warnf("Discarded nameserver in ECS_AWSVPC_DNS_SERVERS: %s, at most %d are supported",
+				server, maxAWSVPCDNSServers)
+			continue
+		}
+		dnsServers = append(dnsServers, server)
+	}
+
+	return dnsServers
+}
+
+// awsvpcDNSOptionLimits maps the resolver options that can be set for awsvpc
+// tasks to the range of values accepted for them
+var awsvpcDNSOptionLimits = map[string][2]int{
+	"ndots":    {0, 15},
+	"timeout":  {1, 30},
+	"attempts": {1, 5},
+}
+
+func parseAWSVPCDNSOptions() []string {
+	var dnsOptions []string
+	dnsOptionsEnv := os.Getenv("ECS_AWSVPC_DNS_OPTIONS")
+	for _, option := range strings.Fields(dnsOptionsEnv) {
+		if option == "rotate" {
+			dnsOptions = append(dnsOptions, option)
+			continue
+		}
+		kv := strings.SplitN(option, ":", 2)
+		limits, ok := awsvpcDNSOptionLimits[kv[0]]
+		if !ok || len(kv) != 2 {
+			seelog.Warnf("Discarded unsupported option in ECS_AWSVPC_DNS_OPTIONS: %s", option)
+			continue
+		}
+		value, err := strconv.Atoi(kv[1])
+		if err != nil || value < limits[0] || value > limits[1] {
+			seelog.Warnf("Discarded invalid option in ECS_AWSVPC_DNS_OPTIONS: %s, expected %s:n with n in [%d, %d]",
+				option, kv[0], limits[0], limits[1])
+			continue
+		}
+		dnsOptions = append(dnsOptions, option)
+	}
+
+	return dnsOptions
+}
+
 func parseTaskCPUMemLimitEnabled() Conditional {
 	var taskCPUMemLimitEnabled Conditional
 	taskCPUMemLimitConfigString := os.Getenv("ECS_ENABLE_TASK_CPU_MEM_LIMIT")
@@ -228,6 +532,64 @@ func parseTaskMetadataThrottles() (int, int) {
 	return steadyStateRate, burstRate
 }
 
+func parseTaskLaunchRateLimit() (int, int) {
+	rateLimitEnvVal := os.Getenv("ECS_TASK_LAUNCH_RATE_LIMIT")
+	if rateLimitEnvVal == "" {
+		return 0, 0
+	}
+	rateLimitSplits := strings.Split(rateLimitEnvVal, ",")
+	if len(rateLimitSplits) != 2 {
+		seelog.Warn(`Invalid format for "ECS_TASK_LAUNCH_RATE_LIMIT", expected: "rateLimit,burst"`)
+		return 0, 0
+	}
+	rate, err := strconv.Atoi(strings.TrimSpace(rateLimitSplits[0]))
+	if err != nil || rate < 0 {
+		seelog.Warnf(`Invalid format for "ECS_TASK_LAUNCH_RATE_LIMIT", expected a positive integer for rate: %v`, err)
+		return 0, 0
+	}
+	burst, err := strconv.Atoi(strings.TrimSpace(rateLimitSplits[1]))
+	if err != nil || burst < 0 {
+		seelog.Warnf(`Invalid format for "ECS_TASK_LAUNCH_RATE_LIMIT", expected a positive integer for burst: %v`, err)
+		return 0, 0
+	}
+	return rate, burst
+}
+
+func parseDynamicHostPortRange() (uint16, uint16) {
+	portRangeEnvVal := os.Getenv("ECS_DYNAMIC_HOST_PORT_RANGE")
+	if portRangeEnvVal == "" {
+		return 0, 0
+	}
+	portRangeSplits := strings.Split(portRangeEnvVal, "-")
+	if len(portRangeSplits) != 2 {
+		seelog.Warn(`Invalid format for "ECS_DYNAMIC_HOST_PORT_RANGE", expected: "start-end"`)
+		return 0, 0
+	}
+	start, err := strconv.ParseUint(strings.TrimSpace(portRangeSplits[0]), 10, 16)
+	if err != nil || start == 0 {
+		seelog.Warnf(`Invalid format for "ECS_DYNAMIC_HOST_PORT_RANGE", expected a port for start: %v`, err)
+		return 0, 0
+	}
+	end, err := strconv.ParseUint(strings.TrimSpace(portRangeSplits[1]), 10, 16)
+	if err != nil || end < start {
+		seelog.Warnf(`Invalid format for "ECS_DYNAMIC_HOST_PORT_RANGE", expected a port not lower than start for end: %v`, err)
+		return 0, 0
+	}
+	return uint16(start), uint16(end)
+}
+
+func parseTaskLaunchMaxConcurrency() int {
+	maxConcurrencyEnvVal := os.Getenv("ECS_TASK_LAUNCH_MAX_CONCURRENCY")
+	maxConcurrency, err := strconv.Atoi(maxConcurrencyEnvVal)
+	if maxConcurrencyEnvVal != "" && err != nil {
+		seelog.Warnf("Invalid format for \"ECS_TASK_LAUNCH_MAX_CONCURRENCY\", expected an integer. err %v", err)
+	}
+	if maxConcurrency < 0 {
+		return 0
+	}
+	return maxConcurrency
+}
+
 func parseContainerInstanceTags(errs []error) (map[string]string, []error) {
 	var containerInstanceTags map[string]string
 	containerInstanceTagsConfigString := os.Getenv("ECS_CONTAINER_INSTANCE_TAGS")
@@ -275,6 +637,53 @@ func parseEnvVariableUint16(envVar string) uint16 {
 	return var16
 }
 
+// parseOOMScoreAdj returns the oom_score_adj set in an environment variable,
+// or 0, which leaves the score of containers untouched, if it's missing or
+// out of the bounds accepted by the kernel
+func parseOOMScoreAdj(envVar string) int {
+	envVal := os.Getenv(envVar)
+	if envVal == "" {
+		return 0
+	}
+	oomScoreAdj, err := strconv.Atoi(envVal)
+	if err != nil {
+		seelog.Warnf("Invalid format for \"%s\", expected an integer. err %v", envVar, err)
+		return 0
+	}
+	if oomScoreAdj < MinimumOOMScoreAdj || oomScoreAdj > MaximumOOMScoreAdj {
+		seelog.Warnf("OOM score adjustment %d for environment variable %s is not within [%d, %d], ignoring it",
+			oomScoreAdj, envVar, MinimumOOMScoreAdj, MaximumOOMScoreAdj)
+		return 0
+	}
+	return oomScoreAdj
+}
+
+func parseDefaultNetworkMode() string {
+	networkMode := os.Getenv("ECS_DEFAULT_NETWORK_MODE")
+	switch networkMode {
+	case "", "bridge", "host", "none":
+		return networkMode
+	default:
+		seelog.Warnf("Invalid value for ECS_DEFAULT_NETWORK_MODE: %s, expected one of bridge, host or none; ignoring it",
+			networkMode)
+		return ""
+	}
+}
+
+func parseDisallowedNetworkModes() []string {
+	networkModesEnv := os.Getenv("ECS_DISALLOWED_NETWORK_MODES")
+	if networkModesEnv == "" {
+		return nil
+	}
+	var networkModes []string
+	err := json.Unmarshal([]byte(networkModesEnv), &networkModes)
+	if err != nil {
+		seelog.Warnf("Invalid format for \"ECS_DISALLOWED_NETWORK_MODES\" environment variable; expected a JSON array like [\"host\"]. err %v", err)
+		return nil
+	}
+	return networkModes
+}
+
 func parseEnvVariableDuration(envVar string) time.Duration {
 	var duration time.Duration
 	envVal := os.Getenv(envVar)
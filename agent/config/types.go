@@ -14,16 +14,31 @@
 package config
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"text/template"
 	"time"
 
 	"github.com/aws/amazon-ecs-agent/agent/dockerclient"
 	cnitypes "github.com/containernetworking/cni/pkg/types"
+	"github.com/docker/go-units"
 )
 
 // ImagePullBehaviorType is an enum variable type corresponding to different agent pull
 // behaviors including default, always, never and once.
 type ImagePullBehaviorType int8
 
+// ImagePullDigestModeType is an enum variable type corresponding to the agent pull
+// by digest modes including disabled, resolve and require.
+type ImagePullDigestModeType int8
+
+// ImageRepullActionType is an enum variable type corresponding to the actions
+// taken when a re-pulled image tag points to a new digest, including notify
+// and pull.
+type ImageRepullActionType int8
+
 // ContainerInstancePropagateTagsFromType is an enum variable type corresponding to different
 // ways to propagate tags, it includes none (default) and ec2_instance.
 type ContainerInstancePropagateTagsFromType int8
@@ -57,6 +72,12 @@ type Config struct {
 	// ReservedPortsUDP is an array of UDP ports which should be registered as
 	// unavailable. If not set, it defaults to [].
 	ReservedPortsUDP []uint16
+	// DynamicHostPortRangeStart and DynamicHostPortRangeEnd are the range,
+	// inclusive, of the host ports the agent picks from for the port mappings
+	// without a host port of the containers in bridge network mode. When not
+	// set, docker picks them from the ephemeral port range of the kernel
+	DynamicHostPortRangeStart uint16
+	DynamicHostPortRangeEnd   uint16
 
 	// DataDir is the directory data is saved to in order to preserve state
 	// across agent restarts.
@@ -97,6 +118,15 @@ type Config struct {
 	// again when PollMetrics is set to true
 	PollingMetricsWaitDuration time.Duration
 
+	// CgroupStatsEnabled configures whether the usage of the containers is
+	// read from their cgroups rather than from a docker stats stream for each
+	// container
+	CgroupStatsEnabled bool
+
+	// TelemetryPublishInterval is how often the metrics of the containers and
+	// their health are published to the backend
+	TelemetryPublishInterval time.Duration
+
 	// DisableDockerHealthCheck configures whether container health feature was enabled
 	// on the instance
 	DisableDockerHealthCheck bool
@@ -115,6 +145,11 @@ type Config struct {
 	// ImagePullInactivityTimeout is here to override the amount of time to wait when pulling and extracting a container
 	ImagePullInactivityTimeout time.Duration
 
+	// ImagePullMinimumBandwidth is the minimum bandwidth, in bytes per second,
+	// assumed when pulling images. When set, the image pull timeout is computed
+	// from the compressed size of the image layers instead of being fixed
+	ImagePullMinimumBandwidth int64
+
 	// AvailableLoggingDrivers specifies the logging drivers available for use
 	// with Docker.  If not set, it defaults to ["json-file","none"].
 	AvailableLoggingDrivers []dockerclient.LoggingDriver
@@ -127,6 +162,33 @@ type Config struct {
 	// security options
 	SELinuxCapable bool
 
+	// SecurityBaselineEnabled specifies whether the agent hardens the host
+	// config of all task containers with no-new-privileges, and keeps the
+	// default seccomp profile, the masked paths and the user namespace of the
+	// docker daemon
+	SecurityBaselineEnabled bool
+
+	// SecurityBaselineAllowOptOut specifies whether containers can opt out of
+	// the security baseline with the com.amazonaws.ecs.security-baseline=disabled
+	// docker label
+	SecurityBaselineAllowOptOut bool
+
+	// AllowedKernelCapabilities are the linux capabilities containers are
+	// allowed to add with their kernel capabilities. Any capability can be
+	// dropped
+	AllowedKernelCapabilities []string
+
+	// DefaultUlimits are the ulimits of task containers that don't set them
+	// in their task definition, such as nofile
+	DefaultUlimits []*units.Ulimit
+
+	// MultiTenantIsolationEnabled specifies whether the agent enforces stricter
+	// isolation between tasks, for instances running untrusted code of several
+	// tenants. It turns on the security baseline, per task bridge networks and
+	// the blocking of instance metadata, and containers get read-only root
+	// filesystems and can't mount host paths or share the host namespaces
+	MultiTenantIsolationEnabled bool
+
 	// AppArmorCapable specifies whether the Agent is capable of using AppArmor
 	// security options
 	AppArmorCapable bool
@@ -163,6 +225,11 @@ type Config struct {
 	// task with ENI Trunking
 	ENITrunkingEnabled bool
 
+	// ENIAttachmentAckTimeout specifies how long to wait for an ENI attachment
+	// to be reported before the attachment is removed from state. When set, it
+	// overrides the timeout specified in the attachment message from ACS.
+	ENIAttachmentAckTimeout time.Duration
+
 	// ImageCleanupDisabled specifies whether the Agent will periodically perform
 	// automated image cleanup
 	ImageCleanupDisabled bool
@@ -190,6 +257,64 @@ type Config struct {
 	// local Docker image cache
 	ImagePullBehavior ImagePullBehaviorType
 
+	// ImagePullDigestMode specifies whether the images of a task are pinned to
+	// the digests they point to when the task is accepted, so that all of its
+	// containers run the same image, even across agent restarts
+	ImagePullDigestMode ImagePullDigestModeType
+
+	// RegistryAuth maps registries, optionally followed by a repository prefix,
+	// to the auth mechanisms used to pull images from them. Images from other
+	// registries are pulled with the auth data of the task, if any, or else
+	// with EngineAuthData
+	RegistryAuth map[string]RegistryAuthConfig
+
+	// PullThroughCacheRules maps upstream registries to the ECR repository
+	// prefixes of the pull through cache rules configured for them. Images
+	// from these registries are pulled through the cache with ECR auth
+	PullThroughCacheRules map[string]string
+
+	// ImageRepullImages are the image references whose tags are periodically
+	// resolved in the registry to detect that they point to a new digest, e.g.
+	// the :latest tag of the image of a daemon service
+	ImageRepullImages []string
+
+	// ImageRepullInterval is how often the tags of ImageRepullImages are resolved
+	ImageRepullInterval time.Duration
+
+	// ImageRepullAction is what the agent does when the tag of one of
+	// ImageRepullImages points to a new digest
+	ImageRepullAction ImageRepullActionType
+
+	// ImageVerificationHook is the path of an executable run with the image
+	// reference of each task container before the container is created. The
+	// container isn't created if the executable exits with a non zero status
+	ImageVerificationHook string
+
+	// ImageVerificationTimeout is the time the image verification hook is
+	// allowed to run for before the verification fails
+	ImageVerificationTimeout time.Duration
+
+	// ImagePolicyRequiredLabels are the labels the images of task containers
+	// must have, with the value they must be set to, or any value when it's
+	// empty
+	ImagePolicyRequiredLabels map[string]string
+
+	// ImagePolicyDisallowedDigests are the digests of images task containers
+	// can't use, whether as their image or as its base image
+	ImagePolicyDisallowedDigests []string
+
+	// VaultAddress is the address of the HashiCorp Vault server used to retrieve
+	// the secrets whose valueFrom is a vault:// URI
+	VaultAddress string
+
+	// VaultTokenFile is the path of the file containing the token used to
+	// authenticate with the Vault server
+	VaultTokenFile string
+
+	// SecretsFileProviderDir is the directory of the files that can be used as
+	// secrets with a file:// URI valueFrom. Meant for development environments
+	SecretsFileProviderDir string
+
 	// InstanceAttributes contains key/value pairs representing
 	// attributes to be associated with this instance within the
 	// ECS service and used to influence behavior such as launch
@@ -236,10 +361,40 @@ type Config struct {
 	// instance bridge interface rather than via the ENI.
 	AWSVPCAdditionalLocalRoutes []cnitypes.IPNet
 
+	// AWSVPCTaskMTU is the MTU of the interfaces of tasks launched with network
+	// mode "awsvpc", set by the CNI plugins on the ENIs and the veth pair to
+	// the instance bridge. 0 keeps the MTU the plugins set by default
+	AWSVPCTaskMTU int
+
+	// AWSVPCDNSOptions specifies the resolver options, such as ndots, timeout,
+	// attempts and rotate, written to the resolv.conf of tasks launched with
+	// network mode "awsvpc"
+	AWSVPCDNSOptions []string
+
+	// AWSVPCDNSServers specifies the nameservers written to the resolv.conf of
+	// tasks launched with network mode "awsvpc" instead of those of the VPC,
+	// unless the task overrides them
+	AWSVPCDNSServers []string
+
+	// AWSVPCDNSSearchDomains specifies the search domains written to the
+	// resolv.conf of tasks launched with network mode "awsvpc" instead of those
+	// of the VPC, unless the task overrides them
+	AWSVPCDNSSearchDomains []string
+
 	// ContainerMetadataEnabled specifies if the agent should provide a metadata
 	// file for containers.
 	ContainerMetadataEnabled bool
 
+	// ContainerMetadataStartDependency specifies whether the start of task
+	// containers waits for their metadata file to be written, and for the
+	// credentials endpoint to serve the credentials of tasks with a role
+	ContainerMetadataStartDependency bool
+
+	// ContainerMetadataMaxStartDelay is the maximum time the start of a
+	// container waits for its metadata file and credentials, the container is
+	// started anyway afterwards
+	ContainerMetadataMaxStartDelay time.Duration
+
 	// OverrideAWSLogsExecutionRole is config option used to enable awslogs
 	// driver authentication over the task's execution role
 	OverrideAWSLogsExecutionRole bool
@@ -299,10 +454,262 @@ type Config struct {
 	CgroupCPUPeriod time.Duration
 
 	// SpotInstanceDrainingEnabled, if true, agent will poll the container instance's metadata endpoint for an ec2 spot
-	//   instance termination notice. If EC2 sends a spot termination notice, then agent will set the instance's state
-	//   to DRAINING, which gracefully shuts down all running tasks on the instance.
+	//   instance termination notice, and for the target lifecycle state of the instance in its auto scaling group.
+	//   If EC2 sends a spot termination notice, or the auto scaling group terminates the instance, then agent will
+	//   set the instance's state to DRAINING, which gracefully shuts down all running tasks on the instance, and
+	//   reject the new tasks it receives. If the auto scaling group puts the instance back in service, agent will set
+	//   the instance's state back to ACTIVE and accept new tasks again.
 	// If the instance is not spot then the poller will still run but it will never receive a termination notice.
 	// Defaults to false.
 	// see https://docs.aws.amazon.com/AmazonECS/latest/developerguide/container-instance-draining.html
 	SpotInstanceDrainingEnabled bool
+
+	// FIPSEndpointsEnabled specifies whether the agent uses the FIPS 140-2
+	// validated endpoints of ECS and ECR, including the ACS and TCS endpoints
+	FIPSEndpointsEnabled bool
+
+	// DualStackEndpointsEnabled specifies whether the agent uses the dual-stack
+	// endpoints of ECS and ECR, which are reachable over IPv6, including the
+	// ACS and TCS endpoints
+	DualStackEndpointsEnabled bool
+
+	// LocalDNSEnabled specifies whether the agent answers DNS queries for the
+	// names of task containers in the ecs.local zone, of the form
+	// <container>.<task-family>.ecs.local, with their current addresses
+	LocalDNSEnabled bool
+
+	// LocalDNSListenAddress is the UDP address the agent answers ecs.local DNS
+	// queries on. It's set as the first nameserver of the task containers on
+	// docker networks, so it should be an address of the instance on port 53,
+	// such as the address of the docker bridge
+	LocalDNSListenAddress string
+
+	// TaskRestartLimit is the number of container restarts, across all the
+	// containers of a task, allowed within TaskRestartLimitWindow. A task whose
+	// containers restart more often than that is considered crash looping and
+	// is stopped
+	TaskRestartLimit int
+
+	// TaskRestartLimitWindow is the sliding window TaskRestartLimit applies to
+	TaskRestartLimitWindow time.Duration
+
+	// StateMirrorS3ARN is the S3 ARN of the prefix, of the form
+	// arn:aws:s3:::bucket/prefix, the agent mirrors its state and recent
+	// container events to, so that they can be examined after the instance
+	// dies. Mirroring is disabled when it's not set
+	StateMirrorS3ARN string
+
+	// StateMirrorInterval is how often the agent mirrors its state to
+	// StateMirrorS3ARN, in addition to mirroring it at shutdown
+	StateMirrorInterval time.Duration
+
+	// TaskBridgeNetworkEnabled makes the agent create a docker bridge network
+	// for each task in bridge network mode, instead of attaching the
+	// containers of all the tasks to the default docker bridge
+	TaskBridgeNetworkEnabled bool
+
+	// TaskNetworkCleanupAttempts is the number of times the agent tries to
+	// remove the docker network of a task during its cleanup before reporting
+	// the network as leaked
+	TaskNetworkCleanupAttempts int
+
+	// StateChangeAggregationWindow is how long task state changes are held
+	// before being submitted, so that the changes of the task and its containers
+	// happening within the window are submitted together. Disabled when zero
+	StateChangeAggregationWindow time.Duration
+
+	// StaticTasksDir is the directory of the static tasks, the tasks the agent
+	// runs on the instance independently of ECS and restarts when they stop
+	StaticTasksDir string
+
+	// ReservedCPU is the CPU, in CPU units, reserved for the static tasks,
+	// which the agent doesn't register with ECS. It isn't read from the
+	// environment, the agent sets it from the static tasks at startup
+	ReservedCPU int64
+
+	// PreflightChecksDisabled skips the checks of the prerequisites of the
+	// agent, such as docker being reachable, the agent runs at startup
+	PreflightChecksDisabled bool
+
+	// EnabledExperiments are the experimental features enabled on the
+	// instance. An experiment applies to the tasks that opt into it
+	EnabledExperiments []string
+
+	// ErrorBudgetThresholds are the numbers of agent internal failures of each
+	// kind, e.g. "docker" or "state-save", allowed within ErrorBudgetWindow
+	// before the agent reports itself unhealthy and raises an alarm. The error
+	// budget is disabled when it's empty
+	ErrorBudgetThresholds map[string]int
+
+	// ErrorBudgetWindow is the sliding window the agent internal failures are
+	// counted in
+	ErrorBudgetWindow time.Duration
+
+	// ErrorBudgetWebhookURL is the URL the alarm is posted to when an error
+	// budget is exhausted
+	ErrorBudgetWebhookURL string
+
+	// ErrorBudgetSNSTopicARN is the SNS topic the alarm is published to when
+	// an error budget is exhausted
+	ErrorBudgetSNSTopicARN string
+
+	// RestoreReconcileTimeout is the maximum time the agent waits, after a
+	// restart, for the states of the tasks it restored to be reconciled with
+	// docker and reported before it accepts new tasks from ACS
+	RestoreReconcileTimeout time.Duration
+
+	// ContainerHealthEventMarker is the prefix of the lines of the stdout of
+	// containers with a stdout health check that carry health events
+	ContainerHealthEventMarker string
+
+	// AWSVPCPublishedPorts are the container ports of awsvpc tasks that are
+	// published on the primary IP address of the instance, with iptables DNAT
+	// rules managed by the agent
+	AWSVPCPublishedPorts []uint16
+
+	// TaskEphemeralStorageEnabled specifies whether the ephemeral storage size
+	// of tasks is enforced with the storage quotas of docker, which requires
+	// overlay2 on xfs mounted with project quotas
+	TaskEphemeralStorageEnabled bool
+
+	// EssentialContainerOOMScoreAdj is the oom_score_adj of the essential
+	// containers of tasks, and of the containers the agent runs for them, such
+	// as the pause container. 0 leaves the score set by docker
+	EssentialContainerOOMScoreAdj int
+
+	// NonEssentialContainerOOMScoreAdj is the oom_score_adj of the non-essential
+	// containers of tasks, such as sidecars. Setting it above the score of
+	// essential containers makes the kernel kill sidecars first when the
+	// instance runs out of memory. 0 leaves the score set by docker
+	NonEssentialContainerOOMScoreAdj int
+
+	// DefaultNetworkMode is the network mode, one of "bridge", "host" or
+	// "none", of the containers whose host config doesn't set one, outside of
+	// awsvpc tasks. An empty value leaves the default network mode of docker
+	DefaultNetworkMode string
+
+	// DisallowedNetworkModes are the network modes, such as "host" or
+	// "awsvpc", the agent refuses to run tasks in. Tasks with a container in
+	// one of these modes are stopped when they're added to the agent
+	DisallowedNetworkModes []string
+
+	// TaskRuntimeSettingsEnabled specifies whether the agent mounts the runtime
+	// settings file of tasks in their containers, and applies the settings ACS
+	// sends for running tasks to it
+	TaskRuntimeSettingsEnabled bool
+
+	// InstanceDrainGracePeriod is how long the tasks of the instance are given
+	// to stop on their own when ACS drains the instance, before the agent
+	// stops them, unless the drain message sets a grace period. 0 stops them
+	// right away
+	InstanceDrainGracePeriod time.Duration
+
+	// CNIMaxConcurrency is the maximum number of CNI plugin invocations, to
+	// set up or clean up the network namespace of awsvpc tasks, the agent
+	// runs at the same time. Tasks launched in bursts wait for their turn
+	CNIMaxConcurrency int
+
+	// CNISetupTimeout is the maximum time the agent waits for the network
+	// namespace of an awsvpc task to be set up, including the time it waits
+	// for its turn to invoke the CNI plugins
+	CNISetupTimeout time.Duration
+
+	// TaskLaunchMaxConcurrency is the maximum number of tasks the agent pulls
+	// the images of and creates the containers of at the same time. Other
+	// tasks wait for their turn before they're launched. 0 doesn't limit them
+	TaskLaunchMaxConcurrency int
+
+	// TaskLaunchRate is the number of tasks per second the agent starts
+	// launching, in steady state. 0 doesn't limit them
+	TaskLaunchRate int
+
+	// TaskLaunchBurst is the number of tasks the agent starts launching at
+	// once, on top of TaskLaunchRate, such as when it receives a large payload
+	TaskLaunchBurst int
+
+	// TaskCleanupWaitJitter is the maximum random time added to the cleanup
+	// wait of every task, so that tasks stopped together aren't all cleaned up
+	// at the same time. 0 doesn't add any
+	TaskCleanupWaitJitter time.Duration
+
+	// DeprecatedFieldsDisabled stops serving the deprecated fields of the
+	// introspection API, which are served by default so that clients can
+	// migrate off of them
+	DeprecatedFieldsDisabled bool
+
+	// CNIAdditionalPlugins are the CNI plugins the agent invokes after its
+	// own plugins to set up the network namespace of awsvpc tasks, each on
+	// its own rather than chained, without a previous result
+	CNIAdditionalPlugins []CNIPluginConfig
+
+	// CNIPluginVersions are the versions of the CNI plugins the agent
+	// detected at startup, by plugin. They aren't read from the environment,
+	// the agent sets them to serve them on the introspection API
+	CNIPluginVersions map[string]string
+
+	// CNIAdditionalENIsSupported is set when the eni plugins detected at
+	// startup can set up the ENIs of a task other than its primary ENI. It
+	// isn't read from the environment, tasks with several ENIs are rejected
+	// without it
+	CNIAdditionalENIsSupported bool
+}
+
+// CNIPluginConfig specifies a CNI plugin the agent invokes after the plugins it
+// invokes for awsvpc tasks, such as a traffic mirroring or policy plugin
+type CNIPluginConfig struct {
+	// Type is the name of the plugin binary, in the CNI plugins path
+	Type string `json:"type"`
+	// IfName is the interface the plugin is invoked for, the ENI of the task
+	// if not set
+	IfName string `json:"ifName,omitempty"`
+	// ConfigTemplate is the network configuration of the plugin, a json
+	// object whose strings can refer to the task with text/template actions,
+	// such as "{{.TaskARN}}" or "{{.ENIIPv4Address}}"
+	ConfigTemplate json.RawMessage `json:"config"`
+}
+
+func (plugin CNIPluginConfig) validate() error {
+	if plugin.Type == "" {
+		return errors.New("no plugin type")
+	}
+	if strings.ContainsAny(plugin.Type, `/\`) {
+		return errors.New("plugin type is a path: " + plugin.Type)
+	}
+	if !bytes.HasPrefix(bytes.TrimSpace(plugin.ConfigTemplate), []byte("{")) {
+		return errors.New("plugin config isn't a json object")
+	}
+	if _, err := template.New(plugin.Type).Parse(string(plugin.ConfigTemplate)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// RegistryAuthConfig specifies the auth mechanisms used to pull images from a
+// registry
+type RegistryAuthConfig struct {
+	// AuthTypes are the auth mechanisms tried in order until one of them
+	// provides credentials for the registry. Mechanisms that don't apply to a
+	// task, such as "asm" for tasks without repository credentials, are skipped
+	AuthTypes []string `json:"authTypes"`
+	// CredentialHelper is the name of the docker credential helper used by the
+	// "credentialhelper" mechanism, which is run as docker-credential-<name>
+	CredentialHelper string `json:"credentialHelper,omitempty"`
+}
+
+func (registryAuth RegistryAuthConfig) validate() error {
+	if len(registryAuth.AuthTypes) == 0 {
+		return errors.New("no auth types")
+	}
+	for _, authType := range registryAuth.AuthTypes {
+		switch authType {
+		case RegistryAuthTypeECR, RegistryAuthTypeASM, RegistryAuthTypeDocker:
+		case RegistryAuthTypeCredentialHelper:
+			if registryAuth.CredentialHelper == "" {
+				return errors.New("no credential helper for auth type " + authType)
+			}
+		default:
+			return errors.New("unknown auth type " + authType)
+		}
+	}
+	return nil
 }
@@ -44,6 +44,7 @@ type Manager interface {
 	SetHostPublicIPv4Address(string)
 	Create(*dockercontainer.Config, *dockercontainer.HostConfig, *apitask.Task, string) error
 	Update(context.Context, string, *apitask.Task, string) error
+	WaitWritten(context.Context, string, string) error
 	Clean(string) error
 }
 
@@ -72,17 +73,26 @@ type metadataManager struct {
 	hostPrivateIPv4Address string
 	// hostPublicIPv4Address is the public IPv4 address associated with the EC2 instance
 	hostPublicIPv4Address string
+	// writer writes the metadata files in the background
+	writer *metadataWriter
 }
 
 // NewManager creates a metadataManager for a given DockerTaskEngine settings.
-func NewManager(client DockerMetadataClient, cfg *config.Config) Manager {
+// The metadata files are written in the background until the context is
+// cancelled
+func NewManager(ctx context.Context, client DockerMetadataClient, cfg *config.Config) Manager {
+	osWrap := oswrapper.NewOS()
+	ioutilWrap := ioutilwrapper.NewIOUtil()
+	writer := newMetadataWriter(osWrap, ioutilWrap, cfg.DataDir)
+	go writer.run(ctx)
 	return &metadataManager{
 		client:        client,
 		cluster:       cfg.Cluster,
 		dataDir:       cfg.DataDir,
 		dataDirOnHost: cfg.DataDirOnHost,
-		osWrap:        oswrapper.NewOS(),
-		ioutilWrap:    ioutilwrapper.NewIOUtil(),
+		osWrap:        osWrap,
+		ioutilWrap:    ioutilWrap,
+		writer:        writer,
 	}
 }
 
@@ -110,8 +120,8 @@ func (manager *metadataManager) SetHostPublicIPv4Address(ipv4address string) {
 	manager.hostPublicIPv4Address = ipv4address
 }
 
-// Create creates the metadata file and adds the metadata directory to
-// the container's mounted host volumes
+// Create creates the metadata directory, queues the write of the metadata file
+// and adds the metadata directory to the container's mounted host volumes
 // Pointer hostConfig is modified directly so there is risk of concurrency errors.
 func (manager *metadataManager) Create(config *dockercontainer.Config, hostConfig *dockercontainer.HostConfig, task *apitask.Task, containerName string) error {
 	// Create task and container directories if they do not yet exist
@@ -126,9 +136,9 @@ func (manager *metadataManager) Create(config *dockercontainer.Config, hostConfi
 		return fmt.Errorf("creating metadata directory for task %s: %v", task.Arn, err)
 	}
 
-	// Acquire the metadata then write it in JSON format to the file
+	// Acquire the metadata then queue its write in JSON format to the file
 	metadata := manager.parseMetadataAtContainerCreate(task, containerName)
-	err = manager.marshalAndWrite(metadata, task.Arn, containerName)
+	err = manager.marshalAndEnqueue(metadata, task.Arn, containerName)
 	if err != nil {
 		return err
 	}
@@ -141,7 +151,8 @@ func (manager *metadataManager) Create(config *dockercontainer.Config, hostConfi
 	return nil
 }
 
-// Update updates the metadata file after container starts and dynamic metadata is available
+// Update queues the update of the metadata file after container starts and dynamic
+// metadata is available
 func (manager *metadataManager) Update(ctx context.Context, dockerID string, task *apitask.Task, containerName string) error {
 	// Get docker container information through api call
 	dockerContainer, err := manager.client.InspectContainer(ctx, dockerID, dockerclient.InspectContainerTimeout)
@@ -154,9 +165,15 @@ func (manager *metadataManager) Update(ctx context.Context, dockerID string, tas
 		return fmt.Errorf("container metadata update for container %s in task %s: container not running or invalid", containerName, task.Arn)
 	}
 
-	// Acquire the metadata then write it in JSON format to the file
+	// Acquire the metadata then queue its write in JSON format to the file
 	metadata := manager.parseMetadata(dockerContainer, task, containerName)
-	return manager.marshalAndWrite(metadata, task.Arn, containerName)
+	return manager.marshalAndEnqueue(metadata, task.Arn, containerName)
+}
+
+// WaitWritten waits until the metadata queued for the container has been
+// written to its metadata file
+func (manager *metadataManager) WaitWritten(ctx context.Context, taskARN string, containerName string) error {
+	return manager.writer.waitWritten(ctx, taskARN, containerName)
 }
 
 // Clean removes the metadata files of all containers associated with a task
@@ -165,15 +182,17 @@ func (manager *metadataManager) Clean(taskARN string) error {
 	if err != nil {
 		return fmt.Errorf("clean task metadata: unable to get metadata directory for task %s: %v", taskARN, err)
 	}
+	manager.writer.drop(taskARN)
 	return manager.osWrap.RemoveAll(metadataPath)
 }
 
-func (manager *metadataManager) marshalAndWrite(metadata Metadata, taskARN string, containerName string) error {
+func (manager *metadataManager) marshalAndEnqueue(metadata Metadata, taskARN string, containerName string) error {
 	data, err := json.MarshalIndent(metadata, "", "\t")
 	if err != nil {
 		return fmt.Errorf("create metadata for container %s in task %s: failed to marshal metadata: %v", containerName, taskARN, err)
 	}
 
-	// Write the metadata to file
-	return writeToMetadataFile(manager.osWrap, manager.ioutilWrap, data, taskARN, containerName, manager.dataDir)
+	// Queue the write of the metadata to file
+	manager.writer.enqueue(taskARN, containerName, data)
+	return nil
 }
@@ -200,6 +200,7 @@ func TestHappyPath(t *testing.T) {
 
 	newManager := &metadataManager{
 		osWrap: mockOS,
+		writer: newMetadataWriter(mockOS, nil, ""),
 	}
 
 	gomock.InOrder(
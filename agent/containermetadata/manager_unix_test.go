@@ -52,8 +52,10 @@ func TestCreate(t *testing.T) {
 	newManager := &metadataManager{
 		osWrap:     mockOS,
 		ioutilWrap: mockIOUtil,
+		writer:     newMetadataWriter(mockOS, mockIOUtil, ""),
 	}
 	err := newManager.Create(mockConfig, mockHostConfig, mockTask, mockContainerName)
+	newManager.writer.writeBatch()
 
 	assert.NoError(t, err)
 	assert.Equal(t, 1, len(mockConfig.Env), "Unexpected number of environment variables in config")
@@ -92,6 +94,7 @@ func TestUpdate(t *testing.T) {
 		client:     mockClient,
 		osWrap:     mockOS,
 		ioutilWrap: mockIOUtil,
+		writer:     newMetadataWriter(mockOS, mockIOUtil, ""),
 	}
 
 	gomock.InOrder(
@@ -106,6 +109,7 @@ func TestUpdate(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.TODO())
 	defer cancel()
 	err := newManager.Update(ctx, mockDockerID, mockTask, mockContainerName)
+	newManager.writer.writeBatch()
 
 	assert.NoError(t, err)
 }
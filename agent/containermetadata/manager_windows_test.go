@@ -30,7 +30,7 @@ import (
 
 // TestCreate is the mainline case for metadata create
 func TestCreate(t *testing.T) {
-	_, _, mockOS, mockFile, done := managerSetup(t)
+	_, mockIOUtil, mockOS, mockFile, done := managerSetup(t)
 	defer done()
 
 	mockTaskARN := validTaskARN
@@ -41,16 +41,21 @@ func TestCreate(t *testing.T) {
 
 	gomock.InOrder(
 		mockOS.EXPECT().MkdirAll(gomock.Any(), gomock.Any()).Return(nil),
-		mockOS.EXPECT().OpenFile(gomock.Any(), gomock.Any(), gomock.Any()).Return(mockFile, nil),
+		mockIOUtil.EXPECT().TempFile(gomock.Any(), gomock.Any()).Return(mockFile, nil),
 		mockFile.EXPECT().Write(gomock.Any()).Return(0, nil),
 		mockFile.EXPECT().Sync().Return(nil),
 		mockFile.EXPECT().Close().Return(nil),
+		mockFile.EXPECT().Name().Return(""),
+		mockOS.EXPECT().Rename(gomock.Any(), gomock.Any()).Return(nil),
 	)
 
 	newManager := &metadataManager{
-		osWrap: mockOS,
+		osWrap:     mockOS,
+		ioutilWrap: mockIOUtil,
+		writer:     newMetadataWriter(mockOS, mockIOUtil, ""),
 	}
 	err := newManager.Create(mockConfig, mockHostConfig, mockTask, mockContainerName)
+	newManager.writer.writeBatch()
 
 	assert.NoError(t, err)
 	assert.Equal(t, 1, len(mockConfig.Env), "Unexpected number of environment variables in config")
@@ -59,7 +64,7 @@ func TestCreate(t *testing.T) {
 
 // TestUpdate is happy path case for metadata update
 func TestUpdate(t *testing.T) {
-	mockClient, _, mockOS, mockFile, done := managerSetup(t)
+	mockClient, mockIOUtil, mockOS, mockFile, done := managerSetup(t)
 	defer done()
 
 	mockDockerID := dockerID
@@ -84,20 +89,25 @@ func TestUpdate(t *testing.T) {
 	}
 
 	newManager := &metadataManager{
-		client: mockClient,
-		osWrap: mockOS,
+		client:     mockClient,
+		osWrap:     mockOS,
+		ioutilWrap: mockIOUtil,
+		writer:     newMetadataWriter(mockOS, mockIOUtil, ""),
 	}
 
 	gomock.InOrder(
 		mockClient.EXPECT().InspectContainer(gomock.Any(), mockDockerID, dockerclient.InspectContainerTimeout).Return(&mockContainer, nil),
-		mockOS.EXPECT().OpenFile(gomock.Any(), gomock.Any(), gomock.Any()).Return(mockFile, nil),
+		mockIOUtil.EXPECT().TempFile(gomock.Any(), gomock.Any()).Return(mockFile, nil),
 		mockFile.EXPECT().Write(gomock.Any()).Return(0, nil),
 		mockFile.EXPECT().Sync().Return(nil),
 		mockFile.EXPECT().Close().Return(nil),
+		mockFile.EXPECT().Name().Return(""),
+		mockOS.EXPECT().Rename(gomock.Any(), gomock.Any()).Return(nil),
 	)
 	ctx, cancel := context.WithCancel(context.TODO())
 	defer cancel()
 	err := newManager.Update(ctx, mockDockerID, mockTask, mockContainerName)
+	newManager.writer.writeBatch()
 
 	assert.NoError(t, err)
 }
@@ -142,6 +142,20 @@ func (mr *MockManagerMockRecorder) Update(arg0, arg1, arg2, arg3 interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockManager)(nil).Update), arg0, arg1, arg2, arg3)
 }
 
+// WaitWritten mocks base method
+func (m *MockManager) WaitWritten(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WaitWritten", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WaitWritten indicates an expected call of WaitWritten
+func (mr *MockManagerMockRecorder) WaitWritten(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WaitWritten", reflect.TypeOf((*MockManager)(nil).WaitWritten), arg0, arg1, arg2)
+}
+
 // MockDockerMetadataClient is a mock of DockerMetadataClient interface
 type MockDockerMetadataClient struct {
 	ctrl     *gomock.Controller
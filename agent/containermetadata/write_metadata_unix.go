@@ -52,18 +52,24 @@ func writeToMetadataFile(osWrap oswrapper.OS, ioutilWrap ioutilwrapper.IOUtil, d
 	}
 	metadataFileName := filepath.Join(metadataFileDir, metadataFile)
 
+	// Write to a temporary file that's renamed to the metadata file, so that
+	// the metadata file is never partially written
 	temp, err := ioutilWrap.TempFile(metadataFileDir, tempFile)
 	if err != nil {
 		return err
 	}
 	defer temp.Close()
 	_, err = temp.Write(data)
-	if err != nil {
-		return err
+	if err == nil {
+		err = temp.Chmod(metadataPerm)
+	}
+	if err == nil {
+		err = osWrap.Rename(temp.Name(), metadataFileName)
 	}
-	err = temp.Chmod(metadataPerm)
 	if err != nil {
+		// Don't leave temporary files behind, e.g. when the volume is full
+		osWrap.RemoveAll(temp.Name())
 		return err
 	}
-	return osWrap.Rename(temp.Name(), metadataFileName)
+	return nil
 }
@@ -46,7 +46,7 @@ func TestWriteTempFileFail(t *testing.T) {
 
 // TestWriteFileWriteFail checks case where write to file fails
 func TestWriteFileWriteFail(t *testing.T) {
-	mockIOUtil, _, mockFile, done := writeSetup(t)
+	mockIOUtil, mockOS, mockFile, done := writeSetup(t)
 	defer done()
 
 	mockData := []byte("")
@@ -57,10 +57,12 @@ func TestWriteFileWriteFail(t *testing.T) {
 	gomock.InOrder(
 		mockIOUtil.EXPECT().TempFile(gomock.Any(), gomock.Any()).Return(mockFile, nil),
 		mockFile.EXPECT().Write(mockData).Return(0, errors.New("write fail")),
+		mockFile.EXPECT().Name().Return("temp"),
+		mockOS.EXPECT().RemoveAll("temp").Return(nil),
 		mockFile.EXPECT().Close(),
 	)
 
-	err := writeToMetadataFile(nil, mockIOUtil, mockData, mockTaskARN, mockContainerName, mockDataDir)
+	err := writeToMetadataFile(mockOS, mockIOUtil, mockData, mockTaskARN, mockContainerName, mockDataDir)
 	expectErrorMessage := "write fail"
 
 	assert.Error(t, err)
@@ -69,7 +71,7 @@ func TestWriteFileWriteFail(t *testing.T) {
 
 // TestWriteChmodFail checks case where chmod fails
 func TestWriteChmodFail(t *testing.T) {
-	mockIOUtil, _, mockFile, done := writeSetup(t)
+	mockIOUtil, mockOS, mockFile, done := writeSetup(t)
 	defer done()
 
 	mockData := []byte("")
@@ -81,12 +83,36 @@ func TestWriteChmodFail(t *testing.T) {
 		mockIOUtil.EXPECT().TempFile(gomock.Any(), gomock.Any()).Return(mockFile, nil),
 		mockFile.EXPECT().Write(mockData).Return(0, nil),
 		mockFile.EXPECT().Chmod(gomock.Any()).Return(errors.New("chmod fail")),
+		mockFile.EXPECT().Name().Return("temp"),
+		mockOS.EXPECT().RemoveAll("temp").Return(nil),
 		mockFile.EXPECT().Close(),
 	)
 
-	err := writeToMetadataFile(nil, mockIOUtil, mockData, mockTaskARN, mockContainerName, mockDataDir)
+	err := writeToMetadataFile(mockOS, mockIOUtil, mockData, mockTaskARN, mockContainerName, mockDataDir)
 	expectErrorMessage := "chmod fail"
 
 	assert.Error(t, err)
 	assert.Equal(t, expectErrorMessage, err.Error())
 }
+
+// TestWriteRenameFail checks case where the temp file can't be renamed
+func TestWriteRenameFail(t *testing.T) {
+	mockIOUtil, mockOS, mockFile, done := writeSetup(t)
+	defer done()
+
+	mockData := []byte("")
+
+	gomock.InOrder(
+		mockIOUtil.EXPECT().TempFile(gomock.Any(), gomock.Any()).Return(mockFile, nil),
+		mockFile.EXPECT().Write(mockData).Return(0, nil),
+		mockFile.EXPECT().Chmod(gomock.Any()).Return(nil),
+		mockFile.EXPECT().Name().Return("temp"),
+		mockOS.EXPECT().Rename("temp", gomock.Any()).Return(errors.New("rename fail")),
+		mockFile.EXPECT().Name().Return("temp"),
+		mockOS.EXPECT().RemoveAll("temp").Return(nil),
+		mockFile.EXPECT().Close(),
+	)
+
+	err := writeToMetadataFile(mockOS, mockIOUtil, mockData, validTaskARN, containerName, dataDir)
+	assert.EqualError(t, err, "rename fail")
+}
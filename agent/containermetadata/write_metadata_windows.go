@@ -17,7 +17,6 @@ package containermetadata
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
 
 	"github.com/aws/amazon-ecs-agent/agent/utils/ioutilwrapper"
@@ -28,6 +27,7 @@ import (
 
 const (
 	mountPoint = `C:\ProgramData\Amazon\ECS\metadata`
+	tempFile   = "temp_metadata_file"
 )
 
 // createBindsEnv will do the appropriate formatting to add a new mount in a container's HostConfig
@@ -51,15 +51,25 @@ func writeToMetadataFile(osWrap oswrapper.OS, ioutilWrap ioutilwrapper.IOUtil, d
 	}
 	metadataFileName := filepath.Join(metadataFileDir, metadataFile)
 
-	file, err := osWrap.OpenFile(metadataFileName, os.O_WRONLY|os.O_CREATE, metadataPerm)
+	// Write to a temporary file that's renamed to the metadata file, so that
+	// the metadata file is never partially written
+	temp, err := ioutilWrap.TempFile(metadataFileDir, tempFile)
 	if err != nil {
 		return err
 	}
-	defer file.Close()
-
-	_, err = file.Write(data)
+	_, err = temp.Write(data)
+	if err == nil {
+		err = temp.Sync()
+	}
+	// The file has to be closed before it's renamed on Windows
+	temp.Close()
+	if err == nil {
+		err = osWrap.Rename(temp.Name(), metadataFileName)
+	}
 	if err != nil {
+		// Don't leave temporary files behind, e.g. when the volume is full
+		osWrap.RemoveAll(temp.Name())
 		return err
 	}
-	return file.Sync()
+	return nil
 }
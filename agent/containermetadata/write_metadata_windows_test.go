@@ -23,49 +23,58 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
-// TestWriteOpenFileFail checks case where open file fails and does not return a NotExist error
-func TestWriteOpenFileFail(t *testing.T) {
-	_, mockOS, _, done := writeSetup(t)
+// TestWriteTempFileFail checks case where temp file cannot be made
+func TestWriteTempFileFail(t *testing.T) {
+	mockIOUtil, _, _, done := writeSetup(t)
 	defer done()
 
 	mockData := []byte("")
-	mockTaskARN := validTaskARN
-	mockContainerName := containerName
-	mockDataDir := dataDir
-	mockOpenErr := errors.New("does exist")
 
 	gomock.InOrder(
-		mockOS.EXPECT().OpenFile(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, mockOpenErr),
+		mockIOUtil.EXPECT().TempFile(gomock.Any(), gomock.Any()).Return(nil, errors.New("temp file fail")),
 	)
 
-	err := writeToMetadataFile(mockOS, nil, mockData, mockTaskARN, mockContainerName, mockDataDir)
-
-	expectErrorMessage := "does exist"
-
-	assert.Error(t, err)
-	assert.Equal(t, expectErrorMessage, err.Error())
+	err := writeToMetadataFile(nil, mockIOUtil, mockData, validTaskARN, containerName, dataDir)
+	assert.EqualError(t, err, "temp file fail")
 }
 
-// TestWriteFileWrtieFail checks case where we fail to write to file
+// TestWriteFileWriteFail checks case where we fail to write to file
 func TestWriteFileWriteFail(t *testing.T) {
-	_, mockOS, mockFile, done := writeSetup(t)
+	mockIOUtil, mockOS, mockFile, done := writeSetup(t)
 	defer done()
 
 	mockData := []byte("")
-	mockTaskARN := validTaskARN
-	mockContainerName := containerName
-	mockDataDir := dataDir
 
 	gomock.InOrder(
-		mockOS.EXPECT().OpenFile(gomock.Any(), gomock.Any(), gomock.Any()).Return(mockFile, nil),
+		mockIOUtil.EXPECT().TempFile(gomock.Any(), gomock.Any()).Return(mockFile, nil),
 		mockFile.EXPECT().Write(mockData).Return(0, errors.New("write fail")),
 		mockFile.EXPECT().Close(),
+		mockFile.EXPECT().Name().Return("temp"),
+		mockOS.EXPECT().RemoveAll("temp").Return(nil),
 	)
 
-	err := writeToMetadataFile(mockOS, nil, mockData, mockTaskARN, mockContainerName, mockDataDir)
+	err := writeToMetadataFile(mockOS, mockIOUtil, mockData, validTaskARN, containerName, dataDir)
+	assert.EqualError(t, err, "write fail")
+}
+
+// TestWriteRenameFail checks case where the temp file can't be renamed
+func TestWriteRenameFail(t *testing.T) {
+	mockIOUtil, mockOS, mockFile, done := writeSetup(t)
+	defer done()
+
+	mockData := []byte("")
 
-	expectErrorMessage := "write fail"
+	gomock.InOrder(
+		mockIOUtil.EXPECT().TempFile(gomock.Any(), gomock.Any()).Return(mockFile, nil),
+		mockFile.EXPECT().Write(mockData).Return(0, nil),
+		mockFile.EXPECT().Sync().Return(nil),
+		mockFile.EXPECT().Close(),
+		mockFile.EXPECT().Name().Return("temp"),
+		mockOS.EXPECT().Rename("temp", gomock.Any()).Return(errors.New("rename fail")),
+		mockFile.EXPECT().Name().Return("temp"),
+		mockOS.EXPECT().RemoveAll("temp").Return(nil),
+	)
 
-	assert.Error(t, err)
-	assert.Equal(t, expectErrorMessage, err.Error())
+	err := writeToMetadataFile(mockOS, mockIOUtil, mockData, validTaskARN, containerName, dataDir)
+	assert.EqualError(t, err, "rename fail")
 }
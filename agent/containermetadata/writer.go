@@ -0,0 +1,158 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package containermetadata
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/metrics"
+	"github.com/aws/amazon-ecs-agent/agent/utils/ioutilwrapper"
+	"github.com/aws/amazon-ecs-agent/agent/utils/oswrapper"
+	"github.com/cihub/seelog"
+)
+
+// waitWrittenInterval is the interval at which the writes waited for are
+// checked
+const waitWrittenInterval = 20 * time.Millisecond
+
+// metadataFileKey identifies the metadata file of a container
+type metadataFileKey struct {
+	taskARN       string
+	containerName string
+}
+
+// metadataWriter writes the metadata files in the background, so that slow or
+// full volumes don't delay container state transitions. The writes queued while
+// a batch is being written are written in the next batch, and only the latest
+// metadata of each container is written.
+type metadataWriter struct {
+	osWrap     oswrapper.OS
+	ioutilWrap ioutilwrapper.IOUtil
+	dataDir    string
+	// pending maps the metadata files to the data to write to them in the
+	// next batch
+	pending map[metadataFileKey][]byte
+	// writing are the metadata files of the batch being written
+	writing map[metadataFileKey][]byte
+	// notify signals the writer that there are pending writes
+	notify chan struct{}
+	lock   sync.Mutex
+}
+
+func newMetadataWriter(osWrap oswrapper.OS, ioutilWrap ioutilwrapper.IOUtil, dataDir string) *metadataWriter {
+	return &metadataWriter{
+		osWrap:     osWrap,
+		ioutilWrap: ioutilWrap,
+		dataDir:    dataDir,
+		pending:    make(map[metadataFileKey][]byte),
+		notify:     make(chan struct{}, 1),
+	}
+}
+
+// run writes the pending batches as they're queued, until the context is
+// cancelled
+func (writer *metadataWriter) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-writer.notify:
+			writer.writeBatch()
+		}
+	}
+}
+
+// enqueue queues the data to be written to the metadata file of the container,
+// replacing the data pending for it, if any
+func (writer *metadataWriter) enqueue(taskARN string, containerName string, data []byte) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+
+	writer.pending[metadataFileKey{taskARN: taskARN, containerName: containerName}] = data
+	select {
+	case writer.notify <- struct{}{}:
+	default:
+		// The writer has already been notified
+	}
+}
+
+// drop discards the pending writes to the metadata files of the task
+func (writer *metadataWriter) drop(taskARN string) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+
+	for key := range writer.pending {
+		if key.taskARN == taskARN {
+			delete(writer.pending, key)
+		}
+	}
+}
+
+// writeBatch writes all the pending writes
+func (writer *metadataWriter) writeBatch() {
+	writer.lock.Lock()
+	batch := writer.pending
+	writer.pending = make(map[metadataFileKey][]byte)
+	writer.writing = batch
+	writer.lock.Unlock()
+
+	for key, data := range batch {
+		writer.write(key, data)
+	}
+
+	writer.lock.Lock()
+	writer.writing = nil
+	writer.lock.Unlock()
+}
+
+// waitWritten waits until the metadata queued for the container, if any, has
+// been written to its metadata file
+func (writer *metadataWriter) waitWritten(ctx context.Context, taskARN string, containerName string) error {
+	key := metadataFileKey{taskARN: taskARN, containerName: containerName}
+	ticker := time.NewTicker(waitWrittenInterval)
+	defer ticker.Stop()
+	for writer.isQueued(key) {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+// isQueued returns true if the metadata of the container is pending or being
+// written
+func (writer *metadataWriter) isQueued(key metadataFileKey) bool {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+
+	_, pending := writer.pending[key]
+	_, writing := writer.writing[key]
+	return pending || writing
+}
+
+func (writer *metadataWriter) write(key metadataFileKey, data []byte) {
+	defer metrics.MetricsEngineGlobal.RecordContainerMetadataMetric("WRITE_METADATA")()
+	err := writeToMetadataFile(writer.osWrap, writer.ioutilWrap, data, key.taskARN, key.containerName, writer.dataDir)
+	if err != nil {
+		metrics.MetricsEngineGlobal.RecordContainerMetadataMetric("WRITE_METADATA_ERROR")()
+		seelog.Warnf("Failed to write metadata file for container %s in task %s: %v",
+			key.containerName, key.taskARN, err)
+		return
+	}
+	seelog.Debugf("Wrote metadata file for container %s in task %s", key.containerName, key.taskARN)
+}
@@ -0,0 +1,100 @@
+// +build unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package containermetadata
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetadataWriterCoalescesWrites(t *testing.T) {
+	_, _, mockOS, _, done := managerSetup(t)
+	defer done()
+
+	writer := newMetadataWriter(mockOS, nil, dataDir)
+	writer.enqueue(validTaskARN, containerName, []byte("create"))
+	writer.enqueue(validTaskARN, containerName, []byte("update"))
+	writer.enqueue(validTaskARN, "other", []byte("create"))
+
+	assert.Len(t, writer.pending, 2)
+	assert.Equal(t, []byte("update"), writer.pending[metadataFileKey{validTaskARN, containerName}])
+	// The writer is only notified once per batch
+	assert.Len(t, writer.notify, 1)
+}
+
+func TestMetadataWriterDrop(t *testing.T) {
+	writer := newMetadataWriter(nil, nil, dataDir)
+	writer.enqueue(validTaskARN, containerName, []byte("create"))
+	writer.enqueue("arn:aws:ecs:region:account-id:task/other-task-id", containerName, []byte("create"))
+
+	writer.drop(validTaskARN)
+	assert.Len(t, writer.pending, 1)
+	_, ok := writer.pending[metadataFileKey{validTaskARN, containerName}]
+	assert.False(t, ok)
+}
+
+func TestMetadataWriterWriteBatch(t *testing.T) {
+	mockIOUtil, _, _, done := writeSetup(t)
+	defer done()
+
+	writer := newMetadataWriter(nil, mockIOUtil, dataDir)
+	writer.enqueue(validTaskARN, containerName, []byte("create"))
+	writer.enqueue(invalidTaskARN, containerName, []byte("create"))
+
+	// Failed writes are dropped, with the invalid task ARN failing before
+	// the temp file is created
+	mockIOUtil.EXPECT().TempFile(gomock.Any(), gomock.Any()).Return(nil, errors.New("no space left on device"))
+	writer.writeBatch()
+	assert.Empty(t, writer.pending)
+}
+
+func TestMetadataWriterWaitWritten(t *testing.T) {
+	writer := newMetadataWriter(nil, nil, dataDir)
+	assert.NoError(t, writer.waitWritten(context.TODO(), validTaskARN, containerName))
+
+	writer.enqueue(validTaskARN, containerName, []byte("create"))
+	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Millisecond)
+	defer cancel()
+	assert.Error(t, writer.waitWritten(ctx, validTaskARN, containerName))
+
+	// Other containers don't wait for the pending write
+	assert.NoError(t, writer.waitWritten(context.TODO(), validTaskARN, "other"))
+
+	writer.drop(validTaskARN)
+	assert.NoError(t, writer.waitWritten(context.TODO(), validTaskARN, containerName))
+}
+
+func TestMetadataWriterRunStopsWhenContextIsCancelled(t *testing.T) {
+	writer := newMetadataWriter(nil, nil, dataDir)
+	ctx, cancel := context.WithCancel(context.TODO())
+	stopped := make(chan struct{})
+	go func() {
+		writer.run(ctx)
+		close(stopped)
+	}()
+
+	cancel()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Error("Timed out waiting for the writer to stop")
+	}
+}
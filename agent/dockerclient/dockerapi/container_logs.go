@@ -0,0 +1,115 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package dockerapi
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"time"
+
+	"github.com/cihub/seelog"
+	"github.com/docker/docker/api/types"
+)
+
+const (
+	// stdoutStreamType is the type of the frames of the stdout stream in the
+	// multiplexed output of containers without a tty
+	stdoutStreamType = 1
+	// streamFrameHeaderLength is the length of the header of the frames of the
+	// multiplexed output of containers, which holds the stream type and the
+	// size of the frame
+	streamFrameHeaderLength = 8
+	// maxStdoutLineLength is the length beyond which lines written to stdout
+	// by containers can't be read
+	maxStdoutLineLength = 1024 * 1024
+)
+
+// ContainerStdoutLines returns a channel of the lines the container writes to stdout since the time provided
+func (dg *dockerGoClient) ContainerStdoutLines(ctx context.Context, containerID string, tty bool,
+	since time.Time) (<-chan string, error) {
+	client, err := dg.sdkDockerClient()
+	if err != nil {
+		return nil, err
+	}
+	options := types.ContainerLogsOptions{
+		ShowStdout: true,
+		Follow:     true,
+	}
+	if !since.IsZero() {
+		options.Since = fmt.Sprintf("%d.%09d", since.Unix(), since.Nanosecond())
+	}
+	logs, err := client.ContainerLogs(ctx, containerID, options)
+	if err != nil {
+		return nil, err
+	}
+
+	var stdout io.Reader = logs
+	if !tty {
+		stdout = &stdoutDemuxer{reader: logs}
+	}
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+		defer logs.Close()
+		scanner := bufio.NewScanner(stdout)
+		scanner.Buffer(nil, maxStdoutLineLength)
+		for scanner.Scan() {
+			select {
+			case lines <- scanner.Text():
+			case <-ctx.Done():
+				return
+			}
+		}
+		if err := scanner.Err(); err != nil && ctx.Err() == nil {
+			seelog.Warnf("DockerGoClient: unable to read stdout of container %s: %v", containerID, err)
+		}
+	}()
+	return lines, nil
+}
+
+// stdoutDemuxer reads the stdout stream out of the multiplexed output of a
+// container, skipping the frames of the other streams
+type stdoutDemuxer struct {
+	reader io.Reader
+	// remaining is the number of bytes left to read in the current frame
+	remaining int64
+	// stdout is whether the current frame belongs to the stdout stream
+	stdout bool
+}
+
+func (demuxer *stdoutDemuxer) Read(p []byte) (int, error) {
+	for demuxer.remaining == 0 || !demuxer.stdout {
+		if demuxer.remaining > 0 {
+			if _, err := io.CopyN(ioutil.Discard, demuxer.reader, demuxer.remaining); err != nil {
+				return 0, err
+			}
+		}
+		header := make([]byte, streamFrameHeaderLength)
+		if _, err := io.ReadFull(demuxer.reader, header); err != nil {
+			return 0, err
+		}
+		demuxer.stdout = header[0] == stdoutStreamType
+		demuxer.remaining = int64(binary.BigEndian.Uint32(header[4:]))
+	}
+	if int64(len(p)) > demuxer.remaining {
+		p = p[:demuxer.remaining]
+	}
+	n, err := demuxer.reader.Read(p)
+	demuxer.remaining -= int64(n)
+	return n, err
+}
@@ -0,0 +1,102 @@
+// +build unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package dockerapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// multiplexedFrame returns a frame of the multiplexed output of a container
+func multiplexedFrame(streamType byte, data string) []byte {
+	frame := make([]byte, streamFrameHeaderLength, streamFrameHeaderLength+len(data))
+	frame[0] = streamType
+	binary.BigEndian.PutUint32(frame[4:], uint32(len(data)))
+	return append(frame, data...)
+}
+
+func readStdoutLines(lines <-chan string) []string {
+	var read []string
+	for line := range lines {
+		read = append(read, line)
+	}
+	return read
+}
+
+func TestContainerStdoutLines(t *testing.T) {
+	mockDockerSDK, client, _, _, _, done := dockerClientSetup(t)
+	defer done()
+
+	var output bytes.Buffer
+	output.Write(multiplexedFrame(stdoutStreamType, "first line\nsecond "))
+	output.Write(multiplexedFrame(2, "error line\n"))
+	output.Write(multiplexedFrame(stdoutStreamType, ""))
+	output.Write(multiplexedFrame(stdoutStreamType, "line\nthird line\n"))
+	mockDockerSDK.EXPECT().ContainerLogs(gomock.Any(), "id", types.ContainerLogsOptions{
+		ShowStdout: true,
+		Follow:     true,
+	}).Return(ioutil.NopCloser(&output), nil)
+
+	lines, err := client.ContainerStdoutLines(context.TODO(), "id", false, time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"first line", "second line", "third line"}, readStdoutLines(lines))
+}
+
+func TestContainerStdoutLinesTTY(t *testing.T) {
+	mockDockerSDK, client, _, _, _, done := dockerClientSetup(t)
+	defer done()
+
+	output := bytes.NewBufferString("first line\r\nsecond line\n")
+	mockDockerSDK.EXPECT().ContainerLogs(gomock.Any(), "id", gomock.Any()).Return(ioutil.NopCloser(output), nil)
+
+	lines, err := client.ContainerStdoutLines(context.TODO(), "id", true, time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"first line", "second line"}, readStdoutLines(lines))
+}
+
+func TestContainerStdoutLinesSince(t *testing.T) {
+	mockDockerSDK, client, _, _, _, done := dockerClientSetup(t)
+	defer done()
+
+	mockDockerSDK.EXPECT().ContainerLogs(gomock.Any(), "id", types.ContainerLogsOptions{
+		ShowStdout: true,
+		Follow:     true,
+		Since:      "1577836800.000000042",
+	}).Return(ioutil.NopCloser(&bytes.Buffer{}), nil)
+
+	lines, err := client.ContainerStdoutLines(context.TODO(), "id", true, time.Unix(1577836800, 42))
+	require.NoError(t, err)
+	assert.Empty(t, readStdoutLines(lines))
+}
+
+func TestContainerStdoutLinesError(t *testing.T) {
+	mockDockerSDK, client, _, _, _, done := dockerClientSetup(t)
+	defer done()
+
+	mockDockerSDK.EXPECT().ContainerLogs(gomock.Any(), "id", gomock.Any()).Return(nil, errors.New("error"))
+	_, err := client.ContainerStdoutLines(context.TODO(), "id", false, time.Time{})
+	assert.Error(t, err)
+}
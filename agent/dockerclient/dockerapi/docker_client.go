@@ -39,6 +39,7 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/dockerclient/sdkclient"
 	"github.com/aws/amazon-ecs-agent/agent/dockerclient/sdkclientfactory"
 	"github.com/aws/amazon-ecs-agent/agent/ecr"
+	"github.com/aws/amazon-ecs-agent/agent/endpoints"
 	"github.com/aws/amazon-ecs-agent/agent/metrics"
 	"github.com/aws/amazon-ecs-agent/agent/utils"
 	"github.com/aws/amazon-ecs-agent/agent/utils/retry"
@@ -51,6 +52,7 @@ import (
 	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/api/types/network"
 	"github.com/docker/docker/api/types/volume"
+	dockersdkclient "github.com/docker/docker/client"
 )
 
 const (
@@ -69,6 +71,13 @@ const (
 	maxHealthCheckOutputLength = 1024
 	// VolumeDriverType is one of the plugin capabilities see https://docs.docker.com/engine/reference/commandline/plugin_ls/#filtering
 	VolumeDriverType = "volumedriver"
+	// bridgeNetworkDriver is the docker driver of the networks created by the agent
+	bridgeNetworkDriver = "bridge"
+	// redactedRegistrySecret replaces registry credentials in pull errors
+	redactedRegistrySecret = "********"
+	// sigkillExitCode is the exit code of containers killed with SIGKILL,
+	// which is what the kernel OOM killer sends
+	sigkillExitCode = 137
 )
 
 // Timelimits for docker operations enforced above docker
@@ -93,6 +102,16 @@ const (
 
 var ctxTimeoutStopContainer = dockerclient.StopContainerTimeout
 
+// pullAuthErrorMessages are the lowercase substrings of the pull errors of
+// registries rejecting the credentials the image is pulled with
+var pullAuthErrorMessages = []string{
+	"unauthorized",
+	"authentication required",
+	"no basic auth credentials",
+	"authorization token has expired",
+	"expiredtoken",
+}
+
 type inactivityTimeoutHandlerFunc func(reader io.ReadCloser, timeout time.Duration, cancelRequest func(), canceled *uint32) (io.ReadCloser, chan<- struct{})
 
 // DockerClient interface to make testing it easier
@@ -111,12 +130,17 @@ type DockerClient interface {
 	// be processed by the listener.
 	ContainerEvents(context.Context) (<-chan DockerContainerChangeEvent, error)
 
+	// ImageEvents returns a channel of DockerImageEvents for images being untagged or deleted. The channel is closed
+	// when the context is canceled.
+	ImageEvents(context.Context) (<-chan DockerImageEvent, error)
+
 	// PullImage pulls an image. authData should contain authentication data provided by the ECS backend.
 	PullImage(context.Context, string, *apicontainer.RegistryAuthenticationData, time.Duration) DockerContainerMetadata
 
-	// CreateContainer creates a container with the provided Config, HostConfig, and name. A timeout value
-	// and a context should be provided for the request.
-	CreateContainer(context.Context, *dockercontainer.Config, *dockercontainer.HostConfig, string, time.Duration) DockerContainerMetadata
+	// CreateContainer creates a container with the provided Config, HostConfig, NetworkingConfig, and name. A
+	// timeout value and a context should be provided for the request.
+	CreateContainer(context.Context, *dockercontainer.Config, *dockercontainer.HostConfig, *network.NetworkingConfig,
+		string, time.Duration) DockerContainerMetadata
 
 	// StartContainer starts the container identified by the name provided. A timeout value and a context should be
 	// provided for the request.
@@ -126,6 +150,10 @@ type DockerClient interface {
 	// for the request.
 	StopContainer(context.Context, string, time.Duration) DockerContainerMetadata
 
+	// KillContainer sends the signal provided, such as SIGHUP, to the container identified by the id provided. A
+	// timeout value and a context should be provided for the request.
+	KillContainer(ctx context.Context, dockerID string, signal string, timeout time.Duration) error
+
 	// DescribeContainer returns status information about the specified container. A context should be provided
 	// for the request
 	DescribeContainer(context.Context, string) (apicontainerstatus.ContainerStatus, DockerContainerMetadata)
@@ -154,6 +182,17 @@ type DockerClient interface {
 	// RemoveVolume removes a volume by its name. A timeout value should be provided for the request
 	RemoveVolume(context.Context, string, time.Duration) error
 
+	// CreateNetwork creates a docker bridge network with the labels provided and returns its id. A timeout value
+	// should be provided for the request
+	CreateNetwork(context.Context, string, map[string]string, time.Duration) (string, error)
+
+	// RemoveNetwork removes a network by its name or id. A timeout value should be provided for the request
+	RemoveNetwork(context.Context, string, time.Duration) error
+
+	// ListNetworks returns the networks known to the Docker daemon, filtered by the filters provided. A timeout
+	// value should be provided for the request
+	ListNetworks(context.Context, filters.Args, time.Duration) ([]types.NetworkResource, error)
+
 	// ListPluginsWithFilters returns the set of docker plugins installed on the host, filtered by options provided.
 	// A timeout value should be provided for the request.
 	// TODO ListPluginsWithFilters can be removed since ListPlugins takes in filters
@@ -170,6 +209,10 @@ type DockerClient interface {
 	// Version returns the version of the Docker daemon.
 	Version(context.Context, time.Duration) (string, error)
 
+	// Info returns system-wide information about the Docker daemon, such as the
+	// security options it runs with
+	Info(context.Context, time.Duration) (types.Info, error)
+
 	// APIVersion returns the api version of the client
 	APIVersion() (dockerclient.DockerVersion, error)
 
@@ -182,6 +225,33 @@ type DockerClient interface {
 
 	// LoadImage loads an image from an input stream. A timeout value and a context should be provided for the request.
 	LoadImage(context.Context, io.Reader, time.Duration) error
+
+	// TagImage adds the target name as a tag of the source image. A timeout value and a context should be provided
+	// for the request.
+	TagImage(context.Context, string, string, time.Duration) error
+
+	// ResolveImageDigest returns the digest of the manifest the image reference points to in the registry. authData
+	// should contain authentication data provided by the ECS backend. A timeout value and a context should be
+	// provided for the request.
+	ResolveImageDigest(context.Context, string, *apicontainer.RegistryAuthenticationData, time.Duration) (string, error)
+
+	// CreateContainerExec creates an exec process with the provided config in the container identified by the id
+	// provided. A timeout value and a context should be provided for the request.
+	CreateContainerExec(ctx context.Context, containerID string, execConfig types.ExecConfig, timeout time.Duration) (*types.IDResponse, error)
+
+	// StartContainerExec starts the exec process identified by the id provided, detached. A timeout value and a
+	// context should be provided for the request.
+	StartContainerExec(ctx context.Context, execID string, timeout time.Duration) error
+
+	// InspectContainerExec returns information about the exec process identified by the id provided. A timeout
+	// value and a context should be provided for the request.
+	InspectContainerExec(ctx context.Context, execID string, timeout time.Duration) (*types.ContainerExecInspect, error)
+
+	// ContainerStdoutLines returns a channel of the lines the container identified by the id provided writes to its
+	// stdout since the time provided, so that the output of earlier runs of a restarted container isn't read again.
+	// The channel is closed once the container stops or the context is canceled. tty should be set if the container
+	// has a tty allocated, as its output isn't multiplexed then.
+	ContainerStdoutLines(ctx context.Context, containerID string, tty bool, since time.Time) (<-chan string, error)
 }
 
 // DockerGoClient wraps the underlying go-dockerclient and docker/docker library.
@@ -217,7 +287,10 @@ type dockerGoClient struct {
 	_timeOnce sync.Once
 
 	daemonVersionUnsafe string
-	lock                sync.Mutex
+	// oomEventContainers are the containers docker sent an oom event for since
+	// they last started
+	oomEventContainers map[string]struct{}
+	lock               sync.Mutex
 }
 
 type ImagePullResponse struct {
@@ -273,7 +346,7 @@ func NewDockerGoClient(sdkclientFactory sdkclientfactory.Factory,
 	return &dockerGoClient{
 		sdkClientFactory: sdkclientFactory,
 		auth:             dockerauth.NewDockerAuthProvider(cfg.EngineAuthType, dockerAuthData),
-		ecrClientFactory: ecr.NewECRFactory(cfg.AcceptInsecureCert),
+		ecrClientFactory: ecr.NewECRFactory(cfg.AcceptInsecureCert, endpoints.NewVariant(cfg)),
 		ecrTokenCache:    async.NewLRUCache(tokenCacheSize, tokenCacheTTL),
 		config:           cfg,
 		context:          ctx,
@@ -302,14 +375,23 @@ func (dg *dockerGoClient) time() ttime.Time {
 
 func (dg *dockerGoClient) PullImage(ctx context.Context, image string,
 	authData *apicontainer.RegistryAuthenticationData, timeout time.Duration) DockerContainerMetadata {
-	ctx, cancel := context.WithTimeout(ctx, timeout)
+	var cancel context.CancelFunc
+	var deadline *pullDeadline
+	if dg.config.ImagePullMinimumBandwidth > 0 {
+		// The timeout is computed from the size of the image instead
+		ctx, cancel = context.WithCancel(ctx)
+		deadline = newPullDeadline(dg.time(), dg.config.ImagePullMinimumBandwidth, cancel)
+		defer deadline.stop()
+	} else {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	}
 	defer cancel()
 	defer metrics.MetricsEngineGlobal.RecordDockerMetric("PULL_IMAGE")()
 	response := make(chan DockerContainerMetadata, 1)
 	go func() {
 		err := retry.RetryNWithBackoffCtx(ctx, dg.imagePullBackoff, maximumPullRetries,
 			func() error {
-				err := dg.pullImage(ctx, image, authData)
+				err := dg.pullImage(ctx, image, authData, deadline)
 				if err != nil {
 					seelog.Warnf("DockerGoClient: failed to pull image %s: %s", image, err.Error())
 				}
@@ -328,6 +410,9 @@ func (dg *dockerGoClient) PullImage(ctx context.Context, image string,
 		if err == context.DeadlineExceeded {
 			return DockerContainerMetadata{Error: &DockerTimeoutError{timeout, "pulled"}}
 		}
+		if deadline.hasExpired() {
+			return DockerContainerMetadata{Error: &DockerTimeoutError{deadline.getTimeout(), "pulled"}}
+		}
 		// Context was canceled even though there was no timeout. Send
 		// back an error.
 		return DockerContainerMetadata{Error: &CannotPullContainerError{err}}
@@ -347,7 +432,7 @@ func wrapPullErrorAsNamedError(err error) apierrors.NamedError {
 }
 
 func (dg *dockerGoClient) pullImage(ctx context.Context, image string,
-	authData *apicontainer.RegistryAuthenticationData) apierrors.NamedError {
+	authData *apicontainer.RegistryAuthenticationData, deadline *pullDeadline) apierrors.NamedError {
 	seelog.Debugf("DockerGoClient: pulling image: %s", image)
 	client, err := dg.sdkDockerClient()
 	if err != nil {
@@ -420,6 +505,7 @@ func (dg *dockerGoClient) pullImage(ctx context.Context, image string,
 			})
 
 			statusDisplayed = dg.filterPullDebugOutput(data, image, statusDisplayed)
+			deadline.observe(data)
 
 			data = new(ImagePullResponse)
 		}
@@ -431,7 +517,7 @@ func (dg *dockerGoClient) pullImage(ctx context.Context, image string,
 		break
 	case pullErr := <-pullFinished:
 		if pullErr != nil {
-			return CannotPullContainerError{pullErr}
+			return dg.pullError(pullErr, authData, sdkAuthConfig)
 		}
 		seelog.Debugf("DockerGoClient: pulling image complete: %s", image)
 		return nil
@@ -442,13 +528,68 @@ func (dg *dockerGoClient) pullImage(ctx context.Context, image string,
 
 	err = <-pullFinished
 	if err != nil {
-		return CannotPullContainerError{err}
+		return dg.pullError(err, authData, sdkAuthConfig)
 	}
 
 	seelog.Debugf("DockerGoClient: pulling image complete: %s", image)
 	return nil
 }
 
+// pullError wraps the error returned by the docker daemon while pulling an
+// image. Errors caused by the registry rejecting the credentials, such as ECR
+// tokens expiring during long pulls, are returned as auth errors so that the
+// pull isn't retried with the same credentials, and the cached ECR token is
+// dropped so that the next pull retrieves a new one
+func (dg *dockerGoClient) pullError(err error, authData *apicontainer.RegistryAuthenticationData,
+	authConfig types.AuthConfig) apierrors.NamedError {
+	err = redactAuthConfig(err, authConfig)
+	if !IsPullAuthError(err) {
+		return CannotPullContainerError{err}
+	}
+	if authData != nil && authData.Type == apicontainer.AuthTypeECR {
+		dockerauth.InvalidateECRAuthconfig(dg.ecrTokenCache, authData)
+	}
+	return CannotPullContainerAuthError{err}
+}
+
+// IsPullAuthError returns true if the pull failed because the registry
+// rejected the credentials the image was pulled with, or because they
+// couldn't be used to get a registry token. It accepts both the errors of
+// the docker daemon and the errors the client returns for them
+func IsPullAuthError(err error) bool {
+	switch err.(type) {
+	case nil:
+		return false
+	case CannotPullContainerAuthError, CannotPullECRContainerError:
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	for _, authErrorMsg := range pullAuthErrorMessages {
+		if strings.Contains(msg, authErrorMsg) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactAuthConfig removes the registry secrets in authConfig from the error
+// returned by the docker daemon, so that they don't end up in the agent logs or
+// in the container's state change reason
+func redactAuthConfig(err error, authConfig types.AuthConfig) error {
+	msg := err.Error()
+	redacted := msg
+	for _, secret := range []string{authConfig.Password, authConfig.Auth,
+		authConfig.IdentityToken, authConfig.RegistryToken} {
+		if secret != "" {
+			redacted = strings.Replace(redacted, secret, redactedRegistrySecret, -1)
+		}
+	}
+	if redacted == msg {
+		return err
+	}
+	return errors.New(redacted)
+}
+
 func (dg *dockerGoClient) filterPullDebugOutput(data *ImagePullResponse, image string, statusDisplayed time.Time) time.Time {
 
 	now := time.Now()
@@ -487,6 +628,16 @@ func (dg *dockerGoClient) InspectImage(image string) (*types.ImageInspect, error
 
 func (dg *dockerGoClient) getAuthdata(image string, authData *apicontainer.RegistryAuthenticationData) (types.AuthConfig, error) {
 
+	if registryAuth, ok := dockerauth.FindRegistryAuthConfig(dg.config.RegistryAuth, image); ok {
+		provider := dockerauth.NewChainAuthProvider(registryAuth, dg.auth,
+			dockerauth.NewECRAuthProvider(dg.ecrClientFactory, dg.ecrTokenCache))
+		authConfig, err := provider.GetAuthconfig(image, authData)
+		if err != nil {
+			return authConfig, CannotPullContainerAuthError{err}
+		}
+		return authConfig, nil
+	}
+
 	if authData == nil {
 		return dg.auth.GetAuthconfig(image, nil)
 	}
@@ -511,6 +662,7 @@ func (dg *dockerGoClient) getAuthdata(image string, authData *apicontainer.Regis
 func (dg *dockerGoClient) CreateContainer(ctx context.Context,
 	config *dockercontainer.Config,
 	hostConfig *dockercontainer.HostConfig,
+	networkingConfig *network.NetworkingConfig,
 	name string,
 	timeout time.Duration) DockerContainerMetadata {
 	ctx, cancel := context.WithTimeout(ctx, timeout)
@@ -519,7 +671,7 @@ func (dg *dockerGoClient) CreateContainer(ctx context.Context,
 	// Buffered channel so in the case of timeout it takes one write, never gets
 	// read, and can still be GC'd
 	response := make(chan DockerContainerMetadata, 1)
-	go func() { response <- dg.createContainer(ctx, config, hostConfig, name) }()
+	go func() { response <- dg.createContainer(ctx, config, hostConfig, networkingConfig, name) }()
 
 	// Wait until we get a response or for the 'done' context channel
 	select {
@@ -541,13 +693,17 @@ func (dg *dockerGoClient) CreateContainer(ctx context.Context,
 func (dg *dockerGoClient) createContainer(ctx context.Context,
 	config *dockercontainer.Config,
 	hostConfig *dockercontainer.HostConfig,
+	networkingConfig *network.NetworkingConfig,
 	name string) DockerContainerMetadata {
 	client, err := dg.sdkDockerClient()
 	if err != nil {
 		return DockerContainerMetadata{Error: CannotGetDockerClientError{version: dg.version, err: err}}
 	}
 
-	dockerContainer, err := client.ContainerCreate(ctx, config, hostConfig, &network.NetworkingConfig{}, name)
+	if networkingConfig == nil {
+		networkingConfig = &network.NetworkingConfig{}
+	}
+	dockerContainer, err := client.ContainerCreate(ctx, config, hostConfig, networkingConfig, name)
 	if err != nil {
 		return DockerContainerMetadata{Error: CannotCreateContainerError{err}}
 	}
@@ -923,6 +1079,7 @@ func (dg *dockerGoClient) handleContainerEvents(ctx context.Context,
 			continue
 		case "start":
 			status = apicontainerstatus.ContainerRunning
+			dg.takeOOMEvent(containerID)
 		case "stop":
 			fallthrough
 		case "die":
@@ -939,6 +1096,7 @@ func (dg *dockerGoClient) handleContainerEvents(ctx context.Context,
 			// "oom" can either means any process got OOM'd, but doesn't always
 			// mean the container dies (non-init processes). If the container also
 			// dies, you see a "die" status as well; we'll update suitably there
+			dg.recordOOMEvent(containerID)
 			continue
 		case "health_status: healthy":
 			fallthrough
@@ -951,6 +1109,9 @@ func (dg *dockerGoClient) handleContainerEvents(ctx context.Context,
 		}
 
 		metadata := dg.containerMetadata(ctx, containerID)
+		if status == apicontainerstatus.ContainerStopped && dg.takeOOMEvent(containerID) {
+			applyOOMEvent(&metadata)
+		}
 
 		changedContainers <- DockerContainerChangeEvent{
 			Status:                  status,
@@ -960,6 +1121,100 @@ func (dg *dockerGoClient) handleContainerEvents(ctx context.Context,
 	}
 }
 
+// recordOOMEvent records that a process of the container was killed by the
+// kernel OOM killer
+func (dg *dockerGoClient) recordOOMEvent(containerID string) {
+	dg.lock.Lock()
+	defer dg.lock.Unlock()
+
+	if dg.oomEventContainers == nil {
+		dg.oomEventContainers = make(map[string]struct{})
+	}
+	dg.oomEventContainers[containerID] = struct{}{}
+}
+
+// takeOOMEvent returns whether a process of the container was killed by the
+// kernel OOM killer since the container last started, and forgets about it
+func (dg *dockerGoClient) takeOOMEvent(containerID string) bool {
+	dg.lock.Lock()
+	defer dg.lock.Unlock()
+
+	_, ok := dg.oomEventContainers[containerID]
+	delete(dg.oomEventContainers, containerID)
+	return ok
+}
+
+// applyOOMEvent reports a container that was sent an oom event and then got
+// killed as out of memory. Docker doesn't always flag such containers as
+// OOMKilled, e.g. when the OOM killer picks the init process of the
+// container once its children were killed, which leaves users with a bare
+// 137 exit code
+func applyOOMEvent(metadata *DockerContainerMetadata) {
+	if metadata.Error != nil || metadata.ExitCode == nil || *metadata.ExitCode != sigkillExitCode {
+		return
+	}
+	metadata.Error = OutOfMemoryError{}
+}
+
+// ImageEvents subscribes to the image events of the docker daemon and returns
+// a channel of the image untag and delete events
+func (dg *dockerGoClient) ImageEvents(ctx context.Context) (<-chan DockerImageEvent, error) {
+	client, err := dg.sdkDockerClient()
+	if err != nil {
+		return nil, err
+	}
+
+	options := types.EventsOptions{
+		Filters: filters.NewArgs(filters.Arg("type", events.ImageEventType)),
+	}
+	imageEvents := make(chan DockerImageEvent)
+	go func() {
+		defer close(imageEvents)
+		for {
+			derivedCtx, cancel := context.WithCancel(ctx)
+			dockerEvents, eventErr := client.Events(derivedCtx, options)
+			err := dg.handleImageEvents(ctx, dockerEvents, eventErr, imageEvents)
+			cancel()
+			// If parent ctx has been canceled, stop listening and return. Otherwise reopen the stream.
+			if ctx.Err() != nil {
+				return
+			}
+			if err == io.EOF {
+				seelog.Infof("DockerGoClient: Docker image events stream closed with: %v", err)
+			} else {
+				seelog.Errorf("DockerGoClient: Docker image events stream closed with error: %v", err)
+			}
+		}
+	}()
+	return imageEvents, nil
+}
+
+// handleImageEvents forwards the image untag and delete events until the event
+// stream is closed with an error or the context is canceled
+func (dg *dockerGoClient) handleImageEvents(ctx context.Context,
+	dockerEvents <-chan events.Message,
+	eventErr <-chan error,
+	imageEvents chan<- DockerImageEvent) error {
+	for {
+		select {
+		case event := <-dockerEvents:
+			if event.Action != "untag" && event.Action != "delete" {
+				continue
+			}
+			seelog.Debugf("DockerGoClient: got image event from docker daemon: %v", event)
+			select {
+			case imageEvents <- DockerImageEvent{Action: event.Action, ImageID: event.Actor.ID}:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		case err := <-eventErr:
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
 // ListContainers returns a slice of container IDs.
 func (dg *dockerGoClient) ListContainers(ctx context.Context, all bool, timeout time.Duration) ListContainersResponse {
 	ctx, cancel := context.WithTimeout(ctx, timeout)
@@ -1075,6 +1330,17 @@ func (dg *dockerGoClient) Version(ctx context.Context, timeout time.Duration) (s
 	return version, nil
 }
 
+func (dg *dockerGoClient) Info(ctx context.Context, timeout time.Duration) (types.Info, error) {
+	derivedCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	client, err := dg.sdkDockerClient()
+	if err != nil {
+		return types.Info{}, err
+	}
+	return client.Info(derivedCtx)
+}
+
 func (dg *dockerGoClient) getDaemonVersion() string {
 	dg.lock.Lock()
 	defer dg.lock.Unlock()
@@ -1225,6 +1491,137 @@ func (dg *dockerGoClient) removeVolume(ctx context.Context, name string) error {
 	return nil
 }
 
+func (dg *dockerGoClient) CreateNetwork(ctx context.Context, name string, labels map[string]string,
+	timeout time.Duration) (string, error) {
+	type createNetworkResponse struct {
+		id  string
+		err error
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	defer metrics.MetricsEngineGlobal.RecordDockerMetric("CREATE_NETWORK")()
+	// Buffered channel so in the case of timeout it takes one write, never gets
+	// read, and can still be GC'd
+	response := make(chan createNetworkResponse, 1)
+	go func() {
+		id, err := dg.createNetwork(ctx, name, labels)
+		response <- createNetworkResponse{id, err}
+	}()
+
+	// Wait until we get a response or for the 'done' context channel
+	select {
+	case resp := <-response:
+		return resp.id, resp.err
+	case <-ctx.Done():
+		err := ctx.Err()
+		if err == context.DeadlineExceeded {
+			return "", &DockerTimeoutError{timeout, "creating network"}
+		}
+		return "", &CannotCreateNetworkError{err}
+	}
+}
+
+func (dg *dockerGoClient) createNetwork(ctx context.Context, name string, labels map[string]string) (string, error) {
+	client, err := dg.sdkDockerClient()
+	if err != nil {
+		return "", &CannotGetDockerClientError{version: dg.version, err: err}
+	}
+
+	networkOptions := types.NetworkCreate{
+		CheckDuplicate: true,
+		Driver:         bridgeNetworkDriver,
+		Labels:         labels,
+	}
+	resp, err := client.NetworkCreate(ctx, name, networkOptions)
+	if err != nil {
+		return "", &CannotCreateNetworkError{err}
+	}
+	return resp.ID, nil
+}
+
+func (dg *dockerGoClient) RemoveNetwork(ctx context.Context, name string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	defer metrics.MetricsEngineGlobal.RecordDockerMetric("REMOVE_NETWORK")()
+	// Buffered channel so in the case of timeout it takes one write, never gets
+	// read, and can still be GC'd
+	response := make(chan error, 1)
+	go func() { response <- dg.removeNetwork(ctx, name) }()
+
+	// Wait until we get a response or for the 'done' context channel
+	select {
+	case resp := <-response:
+		return resp
+	case <-ctx.Done():
+		err := ctx.Err()
+		if err == context.DeadlineExceeded {
+			return &DockerTimeoutError{timeout, "removing network"}
+		}
+		return &CannotRemoveNetworkError{err}
+	}
+}
+
+func (dg *dockerGoClient) removeNetwork(ctx context.Context, name string) error {
+	client, err := dg.sdkDockerClient()
+	if err != nil {
+		return &CannotGetDockerClientError{version: dg.version, err: err}
+	}
+
+	err = client.NetworkRemove(ctx, name)
+	if err != nil {
+		if dockersdkclient.IsErrNotFound(err) {
+			// The network is already gone, which is what the caller wants
+			seelog.Debugf("DockerGoClient: network %s not found, skipping its removal", name)
+			return nil
+		}
+		return &CannotRemoveNetworkError{err}
+	}
+	return nil
+}
+
+func (dg *dockerGoClient) ListNetworks(ctx context.Context, filterArgs filters.Args,
+	timeout time.Duration) ([]types.NetworkResource, error) {
+	type listNetworksResponse struct {
+		networks []types.NetworkResource
+		err      error
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	defer metrics.MetricsEngineGlobal.RecordDockerMetric("LIST_NETWORKS")()
+	// Buffered channel so in the case of timeout it takes one write, never gets
+	// read, and can still be GC'd
+	response := make(chan listNetworksResponse, 1)
+	go func() {
+		networks, err := dg.listNetworks(ctx, filterArgs)
+		response <- listNetworksResponse{networks, err}
+	}()
+
+	// Wait until we get a response or for the 'done' context channel
+	select {
+	case resp := <-response:
+		return resp.networks, resp.err
+	case <-ctx.Done():
+		err := ctx.Err()
+		if err == context.DeadlineExceeded {
+			return nil, &DockerTimeoutError{timeout, "listing networks"}
+		}
+		return nil, &CannotListNetworksError{err}
+	}
+}
+
+func (dg *dockerGoClient) listNetworks(ctx context.Context, filterArgs filters.Args) ([]types.NetworkResource, error) {
+	client, err := dg.sdkDockerClient()
+	if err != nil {
+		return nil, &CannotGetDockerClientError{version: dg.version, err: err}
+	}
+
+	networks, err := client.NetworkList(ctx, types.NetworkListOptions{Filters: filterArgs})
+	if err != nil {
+		return nil, &CannotListNetworksError{err}
+	}
+	return networks, nil
+}
+
 // ListPluginsWithFilters takes in filter arguments and returns the string of filtered Plugin names
 func (dg *dockerGoClient) ListPluginsWithFilters(ctx context.Context, enabled bool, capabilities []string, timeout time.Duration) ([]string, error) {
 	// Create filter list
@@ -1406,6 +1803,220 @@ func (dg *dockerGoClient) removeImage(ctx context.Context, imageName string) err
 	return err
 }
 
+// TagImage tags the source image with the target name, with a specified timeout
+func (dg *dockerGoClient) TagImage(ctx context.Context, source string, target string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	response := make(chan error, 1)
+	go func() { response <- dg.tagImage(ctx, source, target) }()
+	select {
+	case resp := <-response:
+		return resp
+	case <-ctx.Done():
+		return &DockerTimeoutError{timeout, "tagging image"}
+	}
+}
+
+func (dg *dockerGoClient) tagImage(ctx context.Context, source string, target string) error {
+	client, err := dg.sdkDockerClient()
+	if err != nil {
+		return err
+	}
+	return client.ImageTag(ctx, source, target)
+}
+
+// KillContainer sends a signal to a container, with a specified timeout
+func (dg *dockerGoClient) KillContainer(ctx context.Context, dockerID string, signal string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	defer metrics.MetricsEngineGlobal.RecordDockerMetric("KILL_CONTAINER")()
+
+	response := make(chan error, 1)
+	go func() { response <- dg.killContainer(ctx, dockerID, signal) }()
+	select {
+	case resp := <-response:
+		return resp
+	case <-ctx.Done():
+		err := ctx.Err()
+		if err == context.DeadlineExceeded {
+			return &DockerTimeoutError{timeout, "killed"}
+		}
+		return CannotKillContainerError{err}
+	}
+}
+
+func (dg *dockerGoClient) killContainer(ctx context.Context, dockerID string, signal string) error {
+	client, err := dg.sdkDockerClient()
+	if err != nil {
+		return err
+	}
+	if err := client.ContainerKill(ctx, dockerID, signal); err != nil {
+		return CannotKillContainerError{err}
+	}
+	return nil
+}
+
+// CreateContainerExec creates an exec process in the container, with a specified timeout
+func (dg *dockerGoClient) CreateContainerExec(ctx context.Context, containerID string, execConfig types.ExecConfig,
+	timeout time.Duration) (*types.IDResponse, error) {
+	type createExecResponse struct {
+		execID *types.IDResponse
+		err    error
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	defer metrics.MetricsEngineGlobal.RecordDockerMetric("CREATE_CONTAINER_EXEC")()
+
+	response := make(chan createExecResponse, 1)
+	go func() {
+		execID, err := dg.createContainerExec(ctx, containerID, execConfig)
+		response <- createExecResponse{execID, err}
+	}()
+	select {
+	case resp := <-response:
+		return resp.execID, resp.err
+	case <-ctx.Done():
+		err := ctx.Err()
+		if err == context.DeadlineExceeded {
+			return nil, &DockerTimeoutError{timeout, "creating exec"}
+		}
+		return nil, CannotCreateContainerExecError{err}
+	}
+}
+
+func (dg *dockerGoClient) createContainerExec(ctx context.Context, containerID string,
+	execConfig types.ExecConfig) (*types.IDResponse, error) {
+	client, err := dg.sdkDockerClient()
+	if err != nil {
+		return nil, err
+	}
+	execID, err := client.ContainerExecCreate(ctx, containerID, execConfig)
+	if err != nil {
+		return nil, CannotCreateContainerExecError{err}
+	}
+	return &execID, nil
+}
+
+// StartContainerExec starts an exec process created by CreateContainerExec, with a specified timeout
+func (dg *dockerGoClient) StartContainerExec(ctx context.Context, execID string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	defer metrics.MetricsEngineGlobal.RecordDockerMetric("START_CONTAINER_EXEC")()
+
+	response := make(chan error, 1)
+	go func() { response <- dg.startContainerExec(ctx, execID) }()
+	select {
+	case resp := <-response:
+		return resp
+	case <-ctx.Done():
+		err := ctx.Err()
+		if err == context.DeadlineExceeded {
+			return &DockerTimeoutError{timeout, "starting exec"}
+		}
+		return CannotStartContainerExecError{err}
+	}
+}
+
+func (dg *dockerGoClient) startContainerExec(ctx context.Context, execID string) error {
+	client, err := dg.sdkDockerClient()
+	if err != nil {
+		return err
+	}
+	if err := client.ContainerExecStart(ctx, execID, types.ExecStartCheck{Detach: true}); err != nil {
+		return CannotStartContainerExecError{err}
+	}
+	return nil
+}
+
+// InspectContainerExec returns information about an exec process, with a specified timeout
+func (dg *dockerGoClient) InspectContainerExec(ctx context.Context, execID string,
+	timeout time.Duration) (*types.ContainerExecInspect, error) {
+	type inspectExecResponse struct {
+		execInspect *types.ContainerExecInspect
+		err         error
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	defer metrics.MetricsEngineGlobal.RecordDockerMetric("INSPECT_CONTAINER_EXEC")()
+
+	response := make(chan inspectExecResponse, 1)
+	go func() {
+		execInspect, err := dg.inspectContainerExec(ctx, execID)
+		response <- inspectExecResponse{execInspect, err}
+	}()
+	select {
+	case resp := <-response:
+		return resp.execInspect, resp.err
+	case <-ctx.Done():
+		err := ctx.Err()
+		if err == context.DeadlineExceeded {
+			return nil, &DockerTimeoutError{timeout, "inspecting exec"}
+		}
+		return nil, CannotInspectContainerExecError{err}
+	}
+}
+
+func (dg *dockerGoClient) inspectContainerExec(ctx context.Context, execID string) (*types.ContainerExecInspect, error) {
+	client, err := dg.sdkDockerClient()
+	if err != nil {
+		return nil, err
+	}
+	execInspect, err := client.ContainerExecInspect(ctx, execID)
+	if err != nil {
+		return nil, CannotInspectContainerExecError{err}
+	}
+	return &execInspect, nil
+}
+
+// ResolveImageDigest returns the digest of the image in the registry, with a specified timeout
+func (dg *dockerGoClient) ResolveImageDigest(ctx context.Context, image string,
+	authData *apicontainer.RegistryAuthenticationData, timeout time.Duration) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	defer metrics.MetricsEngineGlobal.RecordDockerMetric("RESOLVE_IMAGE_DIGEST")()
+
+	type digestResponse struct {
+		digest string
+		err    error
+	}
+	response := make(chan digestResponse, 1)
+	go func() {
+		digest, err := dg.resolveImageDigest(ctx, image, authData)
+		response <- digestResponse{digest, err}
+	}()
+	select {
+	case resp := <-response:
+		return resp.digest, resp.err
+	case <-ctx.Done():
+		return "", &DockerTimeoutError{timeout, "resolving image digest"}
+	}
+}
+
+func (dg *dockerGoClient) resolveImageDigest(ctx context.Context, image string,
+	authData *apicontainer.RegistryAuthenticationData) (string, error) {
+	client, err := dg.sdkDockerClient()
+	if err != nil {
+		return "", CannotGetDockerClientError{version: dg.version, err: err}
+	}
+
+	sdkAuthConfig, err := dg.getAuthdata(image, authData)
+	if err != nil {
+		return "", wrapPullErrorAsNamedError(err)
+	}
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(sdkAuthConfig); err != nil {
+		return "", CannotPullECRContainerError{err}
+	}
+
+	distributionInspect, err := client.DistributionInspect(ctx, getRepository(image),
+		base64.URLEncoding.EncodeToString(buf.Bytes()))
+	if err != nil {
+		return "", CannotResolveImageDigestError{redactAuthConfig(err, sdkAuthConfig)}
+	}
+	return distributionInspect.Descriptor.Digest.String(), nil
+}
+
 // LoadImage invokes loads an image from an input stream, with a specified timeout
 func (dg *dockerGoClient) LoadImage(ctx context.Context, inputStream io.Reader, timeout time.Duration) error {
 	ctx, cancel := context.WithTimeout(ctx, timeout)
@@ -49,9 +49,12 @@ import (
 	"github.com/docker/docker/api/types/events"
 	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/registry"
 	"github.com/docker/docker/api/types/volume"
 	"github.com/docker/go-connections/nat"
 	"github.com/golang/mock/gomock"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -293,6 +296,110 @@ func TestPullImageECRSuccess(t *testing.T) {
 	assert.NoError(t, metadata.Error, "Expected pull to succeed")
 }
 
+func TestPullImageECRTokenExpired(t *testing.T) {
+	mockDockerSDK, client, mockTime, ctrl, ecrClientFactory, done := dockerClientSetup(t)
+	defer done()
+
+	mockTime.EXPECT().After(gomock.Any()).AnyTimes()
+	ecrClient := mock_ecr.NewMockECRClient(ctrl)
+
+	authData := &apicontainer.RegistryAuthenticationData{
+		Type: "ecr",
+		ECRAuthData: &apicontainer.ECRAuthData{
+			RegistryID: "123456789012",
+			Region:     "eu-west-1",
+		},
+	}
+	image := "registry.endpoint/myimage:tag"
+
+	// The token is retrieved again for the second pull, as the first one was
+	// rejected by the registry
+	ecrClientFactory.EXPECT().GetClient(authData.ECRAuthData).Return(ecrClient, nil).Times(2)
+	ecrClient.EXPECT().GetAuthorizationToken("123456789012").Return(
+		&ecrapi.AuthorizationData{
+			ProxyEndpoint:      aws.String("https://registry.endpoint"),
+			AuthorizationToken: aws.String(base64.StdEncoding.EncodeToString([]byte("username:password"))),
+			ExpiresAt:          aws.Time(time.Now().Add(12 * time.Hour)),
+		}, nil).Times(2)
+	gomock.InOrder(
+		mockDockerSDK.EXPECT().ImagePull(gomock.Any(), image, gomock.Any()).Return(
+			mockReadCloser{
+				reader: strings.NewReader(`{"status":"Downloading"}
+{"error":"denied: Your authorization token has expired. Reauthenticate and try again."}`),
+			}, nil),
+		mockDockerSDK.EXPECT().ImagePull(gomock.Any(), image, gomock.Any()).Return(
+			mockReadCloser{
+				reader: strings.NewReader(`{"status":"pull complete"}`),
+			}, nil),
+	)
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	metadata := client.PullImage(ctx, image, authData, dockerclient.PullImageTimeout)
+	require.Error(t, metadata.Error)
+	assert.Equal(t, "CannotPullContainerAuthError", metadata.Error.ErrorName())
+
+	metadata = client.PullImage(ctx, image, authData, dockerclient.PullImageTimeout)
+	assert.NoError(t, metadata.Error)
+}
+
+func TestGetAuthdataRegistryAuthChain(t *testing.T) {
+	_, client, _, ctrl, ecrClientFactory, done := dockerClientSetupWithConfig(t, config.Config{
+		RegistryAuth: map[string]config.RegistryAuthConfig{
+			"registry.endpoint": {AuthTypes: []string{config.RegistryAuthTypeASM, config.RegistryAuthTypeECR}},
+		},
+	})
+	defer done()
+
+	ecrClient := mock_ecr.NewMockECRClient(ctrl)
+	authData := &apicontainer.RegistryAuthenticationData{
+		Type: "ecr",
+		ECRAuthData: &apicontainer.ECRAuthData{
+			RegistryID: "123456789012",
+			Region:     "eu-west-1",
+		},
+	}
+	ecrClientFactory.EXPECT().GetClient(authData.ECRAuthData).Return(ecrClient, nil)
+	ecrClient.EXPECT().GetAuthorizationToken("123456789012").Return(
+		&ecrapi.AuthorizationData{
+			ProxyEndpoint:      aws.String("https://registry.endpoint"),
+			AuthorizationToken: aws.String(base64.StdEncoding.EncodeToString([]byte("username:password"))),
+		}, nil)
+
+	authConfig, err := client.getAuthdata("registry.endpoint/myimage:tag", authData)
+	assert.NoError(t, err)
+	assert.Equal(t, "username", authConfig.Username)
+	assert.Equal(t, "password", authConfig.Password)
+}
+
+func TestGetAuthdataRegistryAuthChainError(t *testing.T) {
+	_, client, _, _, ecrClientFactory, done := dockerClientSetupWithConfig(t, config.Config{
+		RegistryAuth: map[string]config.RegistryAuthConfig{
+			"registry.endpoint": {AuthTypes: []string{config.RegistryAuthTypeECR, config.RegistryAuthTypeDocker}},
+		},
+	})
+	defer done()
+
+	authData := &apicontainer.RegistryAuthenticationData{
+		Type:        "ecr",
+		ECRAuthData: &apicontainer.ECRAuthData{Region: "eu-west-1"},
+	}
+	ecrClientFactory.EXPECT().GetClient(authData.ECRAuthData).Return(nil, errors.New("no credentials"))
+
+	_, err := client.getAuthdata("registry.endpoint/myimage:tag", authData)
+	assert.IsType(t, CannotPullContainerAuthError{}, err)
+}
+
+func TestIsPullAuthError(t *testing.T) {
+	var noError apierrors.NamedError
+	assert.False(t, IsPullAuthError(noError))
+	assert.False(t, IsPullAuthError(errors.New("manifest unknown")))
+	assert.False(t, IsPullAuthError(CannotPullContainerError{errors.New("manifest unknown")}))
+	assert.True(t, IsPullAuthError(errors.New("unauthorized: authentication required")))
+	assert.True(t, IsPullAuthError(CannotPullContainerAuthError{errors.New("unauthorized")}))
+	assert.True(t, IsPullAuthError(CannotPullECRContainerError{errors.New("no credentials")}))
+}
+
 func TestPullImageECRAuthFail(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -359,6 +466,57 @@ func TestPullImageError(t *testing.T) {
 	assert.Equal(t, "CannotPullContainerError", metadata.Error.(apierrors.NamedError).ErrorName(), "Wrong error type")
 }
 
+func TestPullImageAdaptiveTimeout(t *testing.T) {
+	mockDockerSDK, client, testTime, ctrl, _, done := dockerClientSetup(t)
+	defer done()
+	client.config.ImagePullMinimumBandwidth = 1000
+	mockTimer := mock_ttime.NewMockTimer(ctrl)
+
+	testTime.EXPECT().After(gomock.Any()).AnyTimes()
+	testTime.EXPECT().Now().Return(time.Now()).AnyTimes()
+	testTime.EXPECT().AfterFunc(minimumAdaptivePullTimeout, gomock.Any()).Return(mockTimer)
+	mockTimer.EXPECT().Reset(gomock.Any())
+	mockTimer.EXPECT().Stop()
+	mockDockerSDK.EXPECT().ImagePull(gomock.Any(), "image:latest", gomock.Any()).Return(
+		mockReadCloser{
+			reader: strings.NewReader(`{"id":"layer1","status":"Downloading","progressDetail":{"current":100,"total":5000}}
+{"status":"pull complete"}`),
+		}, nil)
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	metadata := client.PullImage(ctx, "image", nil, dockerclient.PullImageTimeout)
+	assert.NoError(t, metadata.Error, "Expected pull to succeed")
+}
+
+func TestPullImageASMErrorRedactsPassword(t *testing.T) {
+	mockDockerSDK, client, testTime, _, _, done := dockerClientSetup(t)
+	defer done()
+
+	testTime.EXPECT().After(gomock.Any()).AnyTimes()
+	asmAuthData := &apicontainer.ASMAuthData{
+		CredentialsParameter: "secret-arn",
+		Region:               "us-west-2",
+	}
+	asmAuthData.SetDockerAuthConfig(types.AuthConfig{
+		Username: "user",
+		Password: "s3cr3t",
+	})
+	authData := &apicontainer.RegistryAuthenticationData{
+		Type:        apicontainer.AuthTypeASM,
+		ASMAuthData: asmAuthData,
+	}
+	mockDockerSDK.EXPECT().ImagePull(gomock.Any(), "registry/image:latest", gomock.Any()).Return(
+		nil, errors.New("login failed for user:s3cr3t")).Times(maximumPullRetries)
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	metadata := client.PullImage(ctx, "registry/image", authData, dockerclient.PullImageTimeout)
+	require.Error(t, metadata.Error)
+	assert.NotContains(t, metadata.Error.Error(), "s3cr3t")
+	assert.Contains(t, metadata.Error.Error(), "user:"+redactedRegistrySecret)
+}
+
 type mockReadCloser struct {
 	reader io.Reader
 	delay  time.Duration
@@ -398,7 +556,7 @@ func TestCreateContainerTimeout(t *testing.T) {
 	}).MaxTimes(1).Return(dockercontainer.ContainerCreateCreatedBody{}, errors.New("test error"))
 	ctx, cancel := context.WithCancel(context.TODO())
 	defer cancel()
-	metadata := client.CreateContainer(ctx, &dockercontainer.Config{}, hostConfig, "containerName", xContainerShortTimeout)
+	metadata := client.CreateContainer(ctx, &dockercontainer.Config{}, hostConfig, nil, "containerName", xContainerShortTimeout)
 	assert.Error(t, metadata.Error, "expected error for pull timeout")
 	assert.Equal(t, "DockerTimeoutError", metadata.Error.(apierrors.NamedError).ErrorName())
 	wait.Done()
@@ -427,7 +585,7 @@ func TestCreateContainer(t *testing.T) {
 	)
 	ctx, cancel := context.WithCancel(context.TODO())
 	defer cancel()
-	metadata := client.CreateContainer(ctx, nil, hostConfig, name, dockerclient.CreateContainerTimeout)
+	metadata := client.CreateContainer(ctx, nil, hostConfig, nil, name, dockerclient.CreateContainerTimeout)
 	assert.NoError(t, metadata.Error)
 	assert.Equal(t, "id", metadata.DockerID)
 	assert.Nil(t, metadata.ExitCode, "Expected a created container to not have an exit code")
@@ -518,6 +676,22 @@ func TestStopContainer(t *testing.T) {
 	assert.Equal(t, "id", metadata.DockerID)
 }
 
+func TestKillContainer(t *testing.T) {
+	mockDockerSDK, client, _, _, _, done := dockerClientSetup(t)
+	defer done()
+
+	gomock.InOrder(
+		mockDockerSDK.EXPECT().ContainerKill(gomock.Any(), "id", "SIGHUP").Return(nil),
+		mockDockerSDK.EXPECT().ContainerKill(gomock.Any(), "id", "SIGHUP").Return(errors.New("test error")),
+	)
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	assert.NoError(t, client.KillContainer(ctx, "id", "SIGHUP", dockerclient.KillContainerTimeout))
+	err := client.KillContainer(ctx, "id", "SIGHUP", dockerclient.KillContainerTimeout)
+	assert.Error(t, err)
+	assert.Equal(t, "CannotKillContainerError", err.(apierrors.NamedError).ErrorName())
+}
+
 func TestRemoveContainerTimeout(t *testing.T) {
 	mockDockerSDK, client, _, _, _, done := dockerClientSetup(t)
 	defer done()
@@ -605,6 +779,33 @@ func TestInspectContainer(t *testing.T) {
 	assert.True(t, reflect.DeepEqual(&containerOutput, container))
 }
 
+func TestImageEvents(t *testing.T) {
+	mockDockerSDK, client, _, _, _, done := dockerClientSetup(t)
+	defer done()
+
+	eventsChan := make(chan events.Message)
+	errChan := make(chan error)
+	mockDockerSDK.EXPECT().Events(gomock.Any(), gomock.Any()).Do(func(ctx context.Context, options types.EventsOptions) {
+		assert.True(t, options.Filters.ExactMatch("type", events.ImageEventType))
+	}).Return(eventsChan, errChan)
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	imageEvents, err := client.ImageEvents(ctx)
+	require.NoError(t, err)
+	go func() {
+		eventsChan <- events.Message{Type: events.ImageEventType, Action: "pull", Actor: events.Actor{ID: "sha256:pulled"}}
+		eventsChan <- events.Message{Type: events.ImageEventType, Action: "delete", Actor: events.Actor{ID: "sha256:deleted"}}
+	}()
+
+	event := <-imageEvents
+	assert.Equal(t, DockerImageEvent{Action: "delete", ImageID: "sha256:deleted"}, event)
+
+	cancel()
+	_, ok := <-imageEvents
+	assert.False(t, ok, "Expected image events channel to be closed")
+}
+
 func TestContainerEvents(t *testing.T) {
 	mockDockerSDK, client, _, _, _, done := dockerClientSetup(t)
 	defer done()
@@ -763,6 +964,56 @@ func TestContainerEvents(t *testing.T) {
 	}
 }
 
+func TestContainerEventsOOMKilled(t *testing.T) {
+	mockDockerSDK, client, _, _, _, done := dockerClientSetup(t)
+	defer done()
+
+	eventsChan := make(chan events.Message, dockerEventBufferSize)
+	errChan := make(chan error)
+	mockDockerSDK.EXPECT().Events(gomock.Any(), gomock.Any()).Return(eventsChan, errChan)
+
+	dockerEvents, err := client.ContainerEvents(context.TODO())
+	require.NoError(t, err, "Could not get container events")
+
+	stoppedContainer := func(id string, exitCode int) types.ContainerJSON {
+		return types.ContainerJSON{
+			ContainerJSONBase: &types.ContainerJSONBase{
+				ID: id,
+				State: &types.ContainerState{
+					FinishedAt: (time.Now()).Format(time.RFC3339),
+					ExitCode:   exitCode,
+				},
+			},
+		}
+	}
+	mockDockerSDK.EXPECT().ContainerInspect(gomock.Any(), "killed").Return(stoppedContainer("killed", 137), nil)
+	mockDockerSDK.EXPECT().ContainerInspect(gomock.Any(), "survived").Return(stoppedContainer("survived", 0), nil)
+	mockDockerSDK.EXPECT().ContainerInspect(gomock.Any(), "stopped").Return(stoppedContainer("stopped", 137), nil)
+	go func() {
+		// An oom event followed by the container dying with SIGKILL
+		eventsChan <- events.Message{Type: "container", ID: "killed", Status: "oom"}
+		eventsChan <- events.Message{Type: "container", ID: "killed", Status: "die"}
+		// A non-init process got OOM killed, but the container exited on its own
+		eventsChan <- events.Message{Type: "container", ID: "survived", Status: "oom"}
+		eventsChan <- events.Message{Type: "container", ID: "survived", Status: "die"}
+		// The container was killed without running out of memory
+		eventsChan <- events.Message{Type: "container", ID: "stopped", Status: "die"}
+	}()
+
+	event := <-dockerEvents
+	assert.Equal(t, "killed", event.DockerID)
+	require.Error(t, event.Error)
+	assert.Equal(t, OutOfMemoryErrorName, event.Error.ErrorName())
+
+	event = <-dockerEvents
+	assert.Equal(t, "survived", event.DockerID)
+	assert.NoError(t, event.Error)
+
+	event = <-dockerEvents
+	assert.Equal(t, "stopped", event.DockerID)
+	assert.NoError(t, event.Error)
+}
+
 func TestContainerEventsError(t *testing.T) {
 	testCases := []struct {
 		name string
@@ -818,6 +1069,21 @@ func TestDockerVersion(t *testing.T) {
 	assert.Equal(t, "1.6.0", str, "Got unexpected version string: "+str)
 }
 
+func TestDockerInfo(t *testing.T) {
+	mockDockerSDK, client, _, _, _, done := dockerClientSetup(t)
+	defer done()
+
+	mockDockerSDK.EXPECT().Info(gomock.Any()).Return(types.Info{
+		SecurityOptions: []string{"name=seccomp,profile=default", "name=userns"},
+	}, nil)
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	info, err := client.Info(ctx, dockerclient.InfoTimeout)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"name=seccomp,profile=default", "name=userns"}, info.SecurityOptions)
+}
+
 func TestDockerVersionCached(t *testing.T) {
 	_, client, _, _, _, done := dockerClientSetup(t)
 	defer done()
@@ -1156,6 +1422,95 @@ func TestRemoveImage(t *testing.T) {
 	assert.NoError(t, err, "Did not expect error, err: %v", err)
 }
 
+func TestTagImage(t *testing.T) {
+	mockDockerSDK, client, _, _, _, done := dockerClientSetup(t)
+	defer done()
+
+	mockDockerSDK.EXPECT().ImageTag(gomock.Any(), "source", "target").Return(nil)
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	err := client.TagImage(ctx, "source", "target", dockerclient.TagImageTimeout)
+	assert.NoError(t, err)
+}
+
+func TestResolveImageDigest(t *testing.T) {
+	mockDockerSDK, client, _, _, _, done := dockerClientSetup(t)
+	defer done()
+
+	mockDockerSDK.EXPECT().DistributionInspect(gomock.Any(), "image:latest", gomock.Any()).Return(
+		registry.DistributionInspect{
+			Descriptor: ocispec.Descriptor{Digest: digest.Digest("sha256:abc")},
+		}, nil)
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	imageDigest, err := client.ResolveImageDigest(ctx, "image", nil, dockerclient.ResolveImageDigestTimeout)
+	assert.NoError(t, err)
+	assert.Equal(t, "sha256:abc", imageDigest)
+}
+
+func TestResolveImageDigestError(t *testing.T) {
+	mockDockerSDK, client, _, _, _, done := dockerClientSetup(t)
+	defer done()
+
+	mockDockerSDK.EXPECT().DistributionInspect(gomock.Any(), "image:tag", gomock.Any()).Return(
+		registry.DistributionInspect{}, errors.New("manifest unknown"))
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	_, err := client.ResolveImageDigest(ctx, "image:tag", nil, dockerclient.ResolveImageDigestTimeout)
+	assert.IsType(t, CannotResolveImageDigestError{}, err)
+}
+
+func TestContainerExec(t *testing.T) {
+	mockDockerSDK, client, _, _, _, done := dockerClientSetup(t)
+	defer done()
+
+	execConfig := types.ExecConfig{Detach: true, Cmd: []string{"ls"}}
+	gomock.InOrder(
+		mockDockerSDK.EXPECT().ContainerExecCreate(gomock.Any(), "id", execConfig).Return(
+			types.IDResponse{ID: "exec-id"}, nil),
+		mockDockerSDK.EXPECT().ContainerExecStart(gomock.Any(), "exec-id", types.ExecStartCheck{Detach: true}).Return(nil),
+		mockDockerSDK.EXPECT().ContainerExecInspect(gomock.Any(), "exec-id").Return(
+			types.ContainerExecInspect{ExecID: "exec-id", Running: true}, nil),
+	)
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	execID, err := client.CreateContainerExec(ctx, "id", execConfig, dockerclient.ContainerExecTimeout)
+	assert.NoError(t, err)
+	assert.Equal(t, "exec-id", execID.ID)
+	assert.NoError(t, client.StartContainerExec(ctx, execID.ID, dockerclient.ContainerExecTimeout))
+	inspect, err := client.InspectContainerExec(ctx, execID.ID, dockerclient.ContainerExecTimeout)
+	assert.NoError(t, err)
+	assert.True(t, inspect.Running)
+}
+
+func TestCreateContainerExecError(t *testing.T) {
+	mockDockerSDK, client, _, _, _, done := dockerClientSetup(t)
+	defer done()
+
+	mockDockerSDK.EXPECT().ContainerExecCreate(gomock.Any(), "id", gomock.Any()).Return(
+		types.IDResponse{}, errors.New("container is not running"))
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	_, err := client.CreateContainerExec(ctx, "id", types.ExecConfig{}, dockerclient.ContainerExecTimeout)
+	assert.IsType(t, CannotCreateContainerExecError{}, err)
+}
+
+func TestTagImageTimeout(t *testing.T) {
+	mockDockerSDK, client, _, _, _, done := dockerClientSetup(t)
+	defer done()
+
+	wait := sync.WaitGroup{}
+	wait.Add(1)
+	mockDockerSDK.EXPECT().ImageTag(gomock.Any(), "source", "target").Do(func(x, y, z interface{}) {
+		wait.Wait()
+	})
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	err := client.TagImage(ctx, "source", "target", 2*time.Millisecond)
+	assert.Error(t, err, "Expected error for tag image timeout")
+	wait.Done()
+}
+
 func TestLoadImageHappyPath(t *testing.T) {
 	mockDockerSDK, client, _, _, _, done := dockerClientSetup(t)
 	defer done()
@@ -1660,6 +2015,107 @@ func TestRemoveVolume(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestCreateNetworkTimeout(t *testing.T) {
+	mockDockerSDK, client, _, _, _, done := dockerClientSetup(t)
+	defer done()
+
+	wait := &sync.WaitGroup{}
+	wait.Add(1)
+	mockDockerSDK.EXPECT().NetworkCreate(gomock.Any(), "name", gomock.Any()).Do(func(ctx context.Context,
+		x interface{}, y interface{}) {
+		wait.Wait()
+	}).MaxTimes(1).Return(types.NetworkCreateResponse{}, nil)
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	_, err := client.CreateNetwork(ctx, "name", nil, xContainerShortTimeout)
+	assert.Error(t, err, "expected error for timeout")
+	assert.Equal(t, "DockerTimeoutError", err.(apierrors.NamedError).ErrorName())
+	wait.Done()
+}
+
+func TestCreateNetwork(t *testing.T) {
+	mockDockerSDK, client, _, _, _, done := dockerClientSetup(t)
+	defer done()
+
+	labels := map[string]string{"label": "value"}
+	mockDockerSDK.EXPECT().NetworkCreate(gomock.Any(), "name", gomock.Any()).Do(func(ctx context.Context,
+		name string, opts types.NetworkCreate) {
+		assert.True(t, opts.CheckDuplicate)
+		assert.Equal(t, bridgeNetworkDriver, opts.Driver)
+		assert.Equal(t, labels, opts.Labels)
+	}).Return(types.NetworkCreateResponse{ID: "id"}, nil)
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	id, err := client.CreateNetwork(ctx, "name", labels, dockerclient.CreateNetworkTimeout)
+	assert.NoError(t, err)
+	assert.Equal(t, "id", id)
+}
+
+func TestCreateNetworkError(t *testing.T) {
+	mockDockerSDK, client, _, _, _, done := dockerClientSetup(t)
+	defer done()
+
+	mockDockerSDK.EXPECT().NetworkCreate(gomock.Any(), gomock.Any(), gomock.Any()).Return(
+		types.NetworkCreateResponse{}, errors.New("some docker error"))
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	_, err := client.CreateNetwork(ctx, "name", nil, dockerclient.CreateNetworkTimeout)
+	assert.Equal(t, "CannotCreateNetworkError", err.(apierrors.NamedError).ErrorName())
+}
+
+// networkNotFoundError is the kind of error the docker client returns for
+// networks that don't exist
+type networkNotFoundError struct{}
+
+func (networkNotFoundError) Error() string  { return "network not found" }
+func (networkNotFoundError) NotFound() bool { return true }
+
+func TestRemoveNetwork(t *testing.T) {
+	mockDockerSDK, client, _, _, _, done := dockerClientSetup(t)
+	defer done()
+
+	mockDockerSDK.EXPECT().NetworkRemove(gomock.Any(), "name").Return(nil)
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	assert.NoError(t, client.RemoveNetwork(ctx, "name", dockerclient.RemoveNetworkTimeout))
+}
+
+func TestRemoveNetworkNotFound(t *testing.T) {
+	mockDockerSDK, client, _, _, _, done := dockerClientSetup(t)
+	defer done()
+
+	mockDockerSDK.EXPECT().NetworkRemove(gomock.Any(), "name").Return(networkNotFoundError{})
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	assert.NoError(t, client.RemoveNetwork(ctx, "name", dockerclient.RemoveNetworkTimeout))
+}
+
+func TestRemoveNetworkError(t *testing.T) {
+	mockDockerSDK, client, _, _, _, done := dockerClientSetup(t)
+	defer done()
+
+	mockDockerSDK.EXPECT().NetworkRemove(gomock.Any(), "name").Return(errors.New("network has active endpoints"))
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	err := client.RemoveNetwork(ctx, "name", dockerclient.RemoveNetworkTimeout)
+	assert.Equal(t, "CannotRemoveNetworkError", err.(apierrors.NamedError).ErrorName())
+}
+
+func TestListNetworks(t *testing.T) {
+	mockDockerSDK, client, _, _, _, done := dockerClientSetup(t)
+	defer done()
+
+	networkFilters := filters.NewArgs(filters.Arg("label", "label"))
+	mockDockerSDK.EXPECT().NetworkList(gomock.Any(), types.NetworkListOptions{Filters: networkFilters}).Return(
+		[]types.NetworkResource{{Name: "name"}}, nil)
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	networks, err := client.ListNetworks(ctx, networkFilters, dockerclient.ListNetworksTimeout)
+	assert.NoError(t, err)
+	require.Len(t, networks, 1)
+	assert.Equal(t, "name", networks[0].Name)
+}
+
 func TestListPluginsTimeout(t *testing.T) {
 	mockDockerSDK, client, _, _, _, done := dockerClientSetup(t)
 	defer done()
@@ -52,7 +52,8 @@ func NewInfiniteBuffer() *InfiniteBuffer {
 }
 
 // StartListening starts reading from the input channel and writes to the buffer
-// When context is cancelled, stop listening
+// in the order the events were received, so that an oom event is seen before
+// the die event of the same container. When context is cancelled, stop listening
 func (buffer *InfiniteBuffer) StartListening(ctx context.Context, eventChan <-chan events.Message) {
 	for {
 		select {
@@ -60,11 +61,11 @@ func (buffer *InfiniteBuffer) StartListening(ctx context.Context, eventChan <-ch
 		case <-ctx.Done():
 			for len(eventChan) > 0 {
 				event := <-eventChan
-				go buffer.CopyEvents(&event)
+				buffer.CopyEvents(&event)
 			}
 			return
 		case event := <-eventChan:
-			go buffer.CopyEvents(&event)
+			buffer.CopyEvents(&event)
 		}
 	}
 }
@@ -28,6 +28,9 @@ const (
 	CannotStartContainerErrorName = "CannotStartContainerError"
 	// CannotDescribeContainerErrorName is the name of describe container error.
 	CannotDescribeContainerErrorName = "CannotDescribeContainerError"
+	// OutOfMemoryErrorName is the name of the error of containers killed by
+	// the kernel OOM killer.
+	OutOfMemoryErrorName = "OutOfMemoryError"
 )
 
 // DockerTimeoutError is an error type for describing timeouts
@@ -51,7 +54,7 @@ type OutOfMemoryError struct{}
 func (err OutOfMemoryError) Error() string { return "Container killed due to memory usage" }
 
 // ErrorName returns the name of the error
-func (err OutOfMemoryError) ErrorName() string { return "OutOfMemoryError" }
+func (err OutOfMemoryError) ErrorName() string { return OutOfMemoryErrorName }
 
 // DockerStateError is a wrapper around the error docker puts in the '.State.Error' field of its inspect output.
 type DockerStateError struct {
@@ -158,6 +161,21 @@ func (err CannotPullECRContainerError) Retry() bool {
 	return false
 }
 
+// CannotResolveImageDigestError indicates any error when trying to resolve
+// the digest of a container image in its registry
+type CannotResolveImageDigestError struct {
+	FromError error
+}
+
+func (err CannotResolveImageDigestError) Error() string {
+	return err.FromError.Error()
+}
+
+// ErrorName returns name of the CannotResolveImageDigestError.
+func (err CannotResolveImageDigestError) ErrorName() string {
+	return "CannotResolveImageDigestError"
+}
+
 // CannotPullContainerAuthError indicates any error when trying to pull
 // a container image
 type CannotPullContainerAuthError struct {
@@ -339,3 +357,94 @@ func (err NoSuchContainerError) Error() string {
 func (err NoSuchContainerError) ErrorName() string {
 	return "NoSuchContainerError"
 }
+
+// CannotCreateNetworkError indicates any error when trying to create a network
+type CannotCreateNetworkError struct {
+	fromError error
+}
+
+func (err CannotCreateNetworkError) Error() string {
+	return err.fromError.Error()
+}
+
+func (err CannotCreateNetworkError) ErrorName() string {
+	return "CannotCreateNetworkError"
+}
+
+// CannotRemoveNetworkError indicates any error when trying to remove a network
+type CannotRemoveNetworkError struct {
+	fromError error
+}
+
+func (err CannotRemoveNetworkError) Error() string {
+	return err.fromError.Error()
+}
+
+func (err CannotRemoveNetworkError) ErrorName() string {
+	return "CannotRemoveNetworkError"
+}
+
+// CannotListNetworksError indicates any error when trying to list networks
+type CannotListNetworksError struct {
+	fromError error
+}
+
+func (err CannotListNetworksError) Error() string {
+	return err.fromError.Error()
+}
+
+func (err CannotListNetworksError) ErrorName() string {
+	return "CannotListNetworksError"
+}
+
+// CannotCreateContainerExecError indicates any error when trying to create an exec process
+type CannotCreateContainerExecError struct {
+	fromError error
+}
+
+func (err CannotCreateContainerExecError) Error() string {
+	return err.fromError.Error()
+}
+
+func (err CannotCreateContainerExecError) ErrorName() string {
+	return "CannotCreateContainerExecError"
+}
+
+// CannotKillContainerError indicates any error when trying to send a signal to a container
+type CannotKillContainerError struct {
+	fromError error
+}
+
+func (err CannotKillContainerError) Error() string {
+	return err.fromError.Error()
+}
+
+func (err CannotKillContainerError) ErrorName() string {
+	return "CannotKillContainerError"
+}
+
+// CannotStartContainerExecError indicates any error when trying to start an exec process
+type CannotStartContainerExecError struct {
+	fromError error
+}
+
+func (err CannotStartContainerExecError) Error() string {
+	return err.fromError.Error()
+}
+
+func (err CannotStartContainerExecError) ErrorName() string {
+	return "CannotStartContainerExecError"
+}
+
+// CannotInspectContainerExecError indicates any error when trying to inspect an exec process
+type CannotInspectContainerExecError struct {
+	fromError error
+}
+
+func (err CannotInspectContainerExecError) Error() string {
+	return err.fromError.Error()
+}
+
+func (err CannotInspectContainerExecError) ErrorName() string {
+	return "CannotInspectContainerExecError"
+}
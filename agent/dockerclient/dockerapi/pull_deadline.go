@@ -0,0 +1,115 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package dockerapi
+
+import (
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/utils/ttime"
+)
+
+const (
+	// minimumAdaptivePullTimeout is the time allowed for an image pull before
+	// any of its layers are reported by the docker daemon, when the pull timeout
+	// is computed from the size of the image. The time needed to download the
+	// layers at the minimum bandwidth is added to it.
+	minimumAdaptivePullTimeout = 10 * time.Minute
+
+	// pullStatusDownloading is the status of the pull progress messages that
+	// report the compressed size of a layer
+	pullStatusDownloading = "Downloading"
+)
+
+// pullDeadline is the deadline of an image pull computed from the compressed
+// sizes of the image layers, as reported by the docker daemon while pulling.
+// When the deadline expires, the pull is canceled.
+type pullDeadline struct {
+	time       ttime.Time
+	start      time.Time
+	timer      ttime.Timer
+	bandwidth  int64
+	layerSizes map[string]int64
+	totalSize  int64
+	timeout    time.Duration
+	expired    bool
+	lock       sync.Mutex
+}
+
+// newPullDeadline returns a pullDeadline that calls cancel when the time needed
+// to pull the image at bandwidth bytes per second has elapsed
+func newPullDeadline(t ttime.Time, bandwidth int64, cancel func()) *pullDeadline {
+	deadline := &pullDeadline{
+		time:       t,
+		start:      t.Now(),
+		bandwidth:  bandwidth,
+		layerSizes: make(map[string]int64),
+		timeout:    minimumAdaptivePullTimeout,
+	}
+	deadline.timer = t.AfterFunc(minimumAdaptivePullTimeout, func() {
+		deadline.lock.Lock()
+		deadline.expired = true
+		deadline.lock.Unlock()
+		cancel()
+	})
+	return deadline
+}
+
+// observe extends the deadline by the time needed to download the layer
+// reported in the pull progress message, the first time the layer is seen
+func (deadline *pullDeadline) observe(data *ImagePullResponse) {
+	if deadline == nil || data.Id == "" || data.Status != pullStatusDownloading || data.ProgressDetail.Total <= 0 {
+		return
+	}
+
+	deadline.lock.Lock()
+	defer deadline.lock.Unlock()
+
+	if deadline.expired {
+		return
+	}
+	if _, ok := deadline.layerSizes[data.Id]; ok {
+		return
+	}
+	deadline.layerSizes[data.Id] = data.ProgressDetail.Total
+	deadline.totalSize += data.ProgressDetail.Total
+	downloadTime := time.Duration(float64(deadline.totalSize) / float64(deadline.bandwidth) * float64(time.Second))
+	deadline.timeout = minimumAdaptivePullTimeout + downloadTime
+	deadline.timer.Reset(deadline.timeout - deadline.time.Now().Sub(deadline.start))
+}
+
+// getTimeout returns the current timeout of the pull
+func (deadline *pullDeadline) getTimeout() time.Duration {
+	deadline.lock.Lock()
+	defer deadline.lock.Unlock()
+
+	return deadline.timeout
+}
+
+// hasExpired returns true if the pull was canceled because of the deadline
+func (deadline *pullDeadline) hasExpired() bool {
+	if deadline == nil {
+		return false
+	}
+
+	deadline.lock.Lock()
+	defer deadline.lock.Unlock()
+
+	return deadline.expired
+}
+
+// stop releases the timer of the deadline
+func (deadline *pullDeadline) stop() {
+	deadline.timer.Stop()
+}
@@ -0,0 +1,82 @@
+// +build unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package dockerapi
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/utils/ttime/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPullDeadlineExtendedByLayerSizes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockTime := mock_ttime.NewMockTime(ctrl)
+	mockTimer := mock_ttime.NewMockTimer(ctrl)
+
+	start := time.Now()
+	mockTime.EXPECT().Now().Return(start)
+	mockTime.EXPECT().AfterFunc(minimumAdaptivePullTimeout, gomock.Any()).Return(mockTimer)
+	deadline := newPullDeadline(mockTime, 1000, func() {})
+
+	gomock.InOrder(
+		mockTime.EXPECT().Now().Return(start.Add(time.Minute)),
+		mockTimer.EXPECT().Reset(minimumAdaptivePullTimeout+10*time.Second-time.Minute),
+		mockTime.EXPECT().Now().Return(start.Add(2*time.Minute)),
+		mockTimer.EXPECT().Reset(minimumAdaptivePullTimeout+30*time.Second-2*time.Minute),
+	)
+	layer := func(id, status string, total int64) *ImagePullResponse {
+		data := &ImagePullResponse{Id: id, Status: status}
+		data.ProgressDetail.Total = total
+		return data
+	}
+	deadline.observe(layer("layer1", pullStatusDownloading, 10000))
+	// progress messages of known layers and other statuses don't change the deadline
+	deadline.observe(layer("layer1", pullStatusDownloading, 10000))
+	deadline.observe(layer("layer2", "Extracting", 10000))
+	deadline.observe(layer("layer2", pullStatusDownloading, 20000))
+
+	assert.Equal(t, minimumAdaptivePullTimeout+30*time.Second, deadline.getTimeout())
+	assert.False(t, deadline.hasExpired())
+}
+
+func TestPullDeadlineExpired(t *testing.T) {
+	canceled := make(chan struct{})
+	deadline := &pullDeadline{layerSizes: make(map[string]int64)}
+	deadline.timer = time.AfterFunc(time.Millisecond, func() {
+		deadline.lock.Lock()
+		deadline.expired = true
+		deadline.lock.Unlock()
+		close(canceled)
+	})
+	<-canceled
+
+	// layers reported after the deadline expired are ignored
+	data := &ImagePullResponse{Id: "layer1", Status: pullStatusDownloading}
+	data.ProgressDetail.Total = 10000
+	deadline.observe(data)
+	assert.True(t, deadline.hasExpired())
+	assert.Empty(t, deadline.layerSizes)
+}
+
+func TestPullDeadlineNil(t *testing.T) {
+	var deadline *pullDeadline
+	deadline.observe(&ImagePullResponse{Id: "layer1", Status: pullStatusDownloading})
+	assert.False(t, deadline.hasExpired())
+}
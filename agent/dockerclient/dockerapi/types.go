@@ -48,6 +48,15 @@ type DockerContainerChangeEvent struct {
 	Type apicontainer.DockerEventType
 }
 
+// DockerImageEvent is a type for image events, such as an image being deleted
+// from the instance outside of the agent
+type DockerImageEvent struct {
+	// Action is the action performed on the image, e.g. delete or untag
+	Action string
+	// ImageID is the id of the image in the event
+	ImageID string
+}
+
 // DockerContainerMetadata is a type for metadata about Docker containers
 type DockerContainerMetadata struct {
 	// DockerID is the contianer's id generated by Docker
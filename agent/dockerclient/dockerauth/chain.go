@@ -0,0 +1,147 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package dockerauth
+
+import (
+	"fmt"
+	"strings"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/utils"
+	"github.com/cihub/seelog"
+	"github.com/docker/docker/api/types"
+	"github.com/pkg/errors"
+)
+
+// FindRegistryAuthConfig returns the auth config of the registry of the image.
+// A registry followed by a repository prefix takes precedence over the
+// registry alone, the longest matching prefix winning. A prefix matches whole
+// path components only, so that "team" matches "team/app" but not "teamb/app"
+func FindRegistryAuthConfig(registryAuth map[string]config.RegistryAuthConfig,
+	image string) (config.RegistryAuthConfig, bool) {
+	repository, _ := utils.ParseRepositoryTag(image)
+	indexName, remoteName := splitReposName(repository)
+
+	longestKey := ""
+	longestPrefix := -1
+	for key := range registryAuth {
+		nameParts := strings.SplitN(stripRegistrySchema(key), "/", 2)
+		if !sameRegistry(nameParts[0], indexName) {
+			continue
+		}
+		prefix := ""
+		if len(nameParts) == 2 {
+			prefix = strings.Trim(nameParts[1], "/")
+		}
+		if prefix != "" && remoteName != prefix && !strings.HasPrefix(remoteName, prefix+"/") {
+			continue
+		}
+		if len(prefix) > longestPrefix {
+			longestKey = key
+			longestPrefix = len(prefix)
+		}
+	}
+	if longestPrefix < 0 {
+		return config.RegistryAuthConfig{}, false
+	}
+	return registryAuth[longestKey], true
+}
+
+func sameRegistry(hostname, indexName string) bool {
+	if isDockerhubHostname(indexName) {
+		return isDockerhubHostname(hostname)
+	}
+	return hostname == indexName
+}
+
+// chainAuthProvider tries the auth mechanisms of a registry in order until one
+// of them provides credentials
+type chainAuthProvider struct {
+	registryAuth       config.RegistryAuthConfig
+	dockerAuthProvider DockerAuthProvider
+	ecrAuthProvider    DockerAuthProvider
+}
+
+// NewChainAuthProvider returns a DockerAuthProvider that tries the auth
+// mechanisms of the registry auth config in order. The docker and ECR
+// mechanisms are handled by the given providers
+func NewChainAuthProvider(registryAuth config.RegistryAuthConfig,
+	dockerAuthProvider DockerAuthProvider,
+	ecrAuthProvider DockerAuthProvider) DockerAuthProvider {
+	return &chainAuthProvider{
+		registryAuth:       registryAuth,
+		dockerAuthProvider: dockerAuthProvider,
+		ecrAuthProvider:    ecrAuthProvider,
+	}
+}
+
+// GetAuthconfig returns the credentials of the first auth mechanism that
+// provides them. If none of the mechanisms apply the image is pulled
+// anonymously, while if any of them fail the last error is returned
+func (authProvider *chainAuthProvider) GetAuthconfig(image string,
+	registryAuthData *apicontainer.RegistryAuthenticationData) (types.AuthConfig, error) {
+	var lastErr error
+	for _, authType := range authProvider.registryAuth.AuthTypes {
+		authConfig, ok, err := authProvider.getAuthconfig(authType, image, registryAuthData)
+		if err != nil {
+			seelog.Warnf("Unable to get %s credentials for image %s, trying the next auth type: %v",
+				authType, image, err)
+			lastErr = errors.Wrapf(err, "dockerauth: unable to get %s credentials", authType)
+			continue
+		}
+		if ok {
+			seelog.Debugf("Using %s credentials for image %s", authType, image)
+			return authConfig, nil
+		}
+	}
+	if lastErr != nil {
+		return types.AuthConfig{}, lastErr
+	}
+	return types.AuthConfig{}, nil
+}
+
+// getAuthconfig returns the credentials of an auth mechanism, or false if the
+// mechanism doesn't have credentials for the image
+func (authProvider *chainAuthProvider) getAuthconfig(authType string, image string,
+	registryAuthData *apicontainer.RegistryAuthenticationData) (types.AuthConfig, bool, error) {
+	switch authType {
+	case config.RegistryAuthTypeECR:
+		if registryAuthData == nil || registryAuthData.Type != apicontainer.AuthTypeECR ||
+			registryAuthData.ECRAuthData == nil {
+			return types.AuthConfig{}, false, nil
+		}
+		authConfig, err := authProvider.ecrAuthProvider.GetAuthconfig(image, registryAuthData)
+		return authConfig, err == nil, err
+
+	case config.RegistryAuthTypeASM:
+		if registryAuthData == nil || registryAuthData.Type != apicontainer.AuthTypeASM ||
+			registryAuthData.ASMAuthData == nil {
+			return types.AuthConfig{}, false, nil
+		}
+		return registryAuthData.ASMAuthData.GetDockerAuthConfig(), true, nil
+
+	case config.RegistryAuthTypeDocker:
+		authConfig, err := authProvider.dockerAuthProvider.GetAuthconfig(image, nil)
+		return authConfig, err == nil && authConfig != (types.AuthConfig{}), err
+
+	case config.RegistryAuthTypeCredentialHelper:
+		provider := NewCredentialHelperAuthProvider(authProvider.registryAuth.CredentialHelper)
+		authConfig, err := provider.GetAuthconfig(image, registryAuthData)
+		return authConfig, err == nil && authConfig != (types.AuthConfig{}), err
+
+	default:
+		return types.AuthConfig{}, false, fmt.Errorf("unknown auth type %s", authType)
+	}
+}
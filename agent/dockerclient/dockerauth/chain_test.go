@@ -0,0 +1,151 @@
+// +build unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package dockerauth
+
+import (
+	"errors"
+	"testing"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/docker/docker/api/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// staticAuthProvider is a DockerAuthProvider returning the same credentials
+// for every image
+type staticAuthProvider struct {
+	authConfig types.AuthConfig
+	err        error
+	calls      int
+}
+
+func (authProvider *staticAuthProvider) GetAuthconfig(image string,
+	registryAuthData *apicontainer.RegistryAuthenticationData) (types.AuthConfig, error) {
+	authProvider.calls++
+	return authProvider.authConfig, authProvider.err
+}
+
+func TestFindRegistryAuthConfig(t *testing.T) {
+	registryAuth := map[string]config.RegistryAuthConfig{
+		"registry.example.com":              {AuthTypes: []string{"docker"}},
+		"https://registry.example.com/team": {AuthTypes: []string{"asm"}},
+		"docker.io":                         {AuthTypes: []string{"credentialhelper"}, CredentialHelper: "hub"},
+	}
+	testCases := []struct {
+		image            string
+		expectedFound    bool
+		expectedAuthType string
+	}{
+		{"registry.example.com/app:latest", true, "docker"},
+		{"registry.example.com/team/app:latest", true, "asm"},
+		{"registry.example.com/team:latest", true, "asm"},
+		{"registry.example.com/teamb/app:latest", true, "docker"},
+		{"registry.example.com:5000/app", false, ""},
+		{"busybox", true, "credentialhelper"},
+		{"index.docker.io/library/busybox", true, "credentialhelper"},
+		{"other.example.com/app", false, ""},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.image, func(t *testing.T) {
+			auth, found := FindRegistryAuthConfig(registryAuth, tc.image)
+			require.Equal(t, tc.expectedFound, found)
+			if found {
+				assert.Equal(t, tc.expectedAuthType, auth.AuthTypes[0])
+			}
+		})
+	}
+}
+
+func TestChainAuthProvider(t *testing.T) {
+	dockerAuth := types.AuthConfig{Username: "docker", Password: "password"}
+	ecrAuth := types.AuthConfig{Username: "AWS", Password: "token"}
+	asmAuth := types.AuthConfig{Username: "asm", Password: "secret"}
+	asmAuthData := &apicontainer.RegistryAuthenticationData{
+		Type:        apicontainer.AuthTypeASM,
+		ASMAuthData: &apicontainer.ASMAuthData{},
+	}
+	asmAuthData.ASMAuthData.SetDockerAuthConfig(asmAuth)
+	ecrAuthData := &apicontainer.RegistryAuthenticationData{
+		Type:        apicontainer.AuthTypeECR,
+		ECRAuthData: &apicontainer.ECRAuthData{},
+	}
+
+	testCases := []struct {
+		name         string
+		authTypes    []string
+		authData     *apicontainer.RegistryAuthenticationData
+		dockerAuth   types.AuthConfig
+		ecrErr       error
+		expectedAuth types.AuthConfig
+		expectedErr  bool
+	}{
+		{
+			name:         "first mechanism applies",
+			authTypes:    []string{"asm", "docker"},
+			authData:     asmAuthData,
+			dockerAuth:   dockerAuth,
+			expectedAuth: asmAuth,
+		},
+		{
+			name:         "skips mechanisms that don't apply",
+			authTypes:    []string{"ecr", "asm", "docker"},
+			dockerAuth:   dockerAuth,
+			expectedAuth: dockerAuth,
+		},
+		{
+			name:         "skips docker without credentials for the registry",
+			authTypes:    []string{"docker", "ecr"},
+			authData:     ecrAuthData,
+			expectedAuth: ecrAuth,
+		},
+		{
+			name:         "falls back when a mechanism fails",
+			authTypes:    []string{"ecr", "docker"},
+			authData:     ecrAuthData,
+			dockerAuth:   dockerAuth,
+			ecrErr:       errors.New("access denied"),
+			expectedAuth: dockerAuth,
+		},
+		{
+			name:        "fails when no mechanism succeeds",
+			authTypes:   []string{"ecr", "docker"},
+			authData:    ecrAuthData,
+			ecrErr:      errors.New("access denied"),
+			expectedErr: true,
+		},
+		{
+			name:      "anonymous when no mechanism applies",
+			authTypes: []string{"asm", "docker"},
+			authData:  ecrAuthData,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			provider := NewChainAuthProvider(config.RegistryAuthConfig{AuthTypes: tc.authTypes},
+				&staticAuthProvider{authConfig: tc.dockerAuth},
+				&staticAuthProvider{authConfig: ecrAuth, err: tc.ecrErr})
+			authConfig, err := provider.GetAuthconfig("registry.example.com/app", tc.authData)
+			if tc.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedAuth, authConfig)
+		})
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package dockerauth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os/exec"
+	"strings"
+	"time"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	"github.com/aws/amazon-ecs-agent/agent/utils"
+	"github.com/docker/docker/api/types"
+	"github.com/pkg/errors"
+)
+
+const (
+	credentialHelperPrefix = "docker-credential-"
+	// credentialHelperTimeout is the time a credential helper is allowed to run
+	credentialHelperTimeout = 30 * time.Second
+	// credentialsNotFound is the message credential helpers fail with when they
+	// don't have credentials for a registry
+	credentialsNotFound = "credentials not found in native keychain"
+	// identityTokenUsername is the username credential helpers return along with
+	// an identity token rather than a password
+	identityTokenUsername = "<token>"
+)
+
+// credentialHelperResponse is the response of the get command of a credential
+// helper
+type credentialHelperResponse struct {
+	Username string `json:"Username"`
+	Secret   string `json:"Secret"`
+}
+
+type credentialHelperAuthProvider struct {
+	helper string
+}
+
+// NewCredentialHelperAuthProvider returns a DockerAuthProvider that retrieves
+// credentials from a docker credential helper, run as docker-credential-<helper>
+func NewCredentialHelperAuthProvider(helper string) DockerAuthProvider {
+	return &credentialHelperAuthProvider{
+		helper: helper,
+	}
+}
+
+// GetAuthconfig retrieves the credentials of the registry of the image from the
+// credential helper. Empty credentials are returned if the helper doesn't have
+// credentials for the registry
+func (authProvider *credentialHelperAuthProvider) GetAuthconfig(image string,
+	registryAuthData *apicontainer.RegistryAuthenticationData) (types.AuthConfig, error) {
+	repository, _ := utils.ParseRepositoryTag(image)
+	indexName, _ := splitReposName(repository)
+	serverURL := indexName
+	if isDockerhubHostname(indexName) {
+		serverURL = "https://" + dockerRegistryKey
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), credentialHelperTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, credentialHelperPrefix+authProvider.helper, "get")
+	cmd.Stdin = strings.NewReader(serverURL)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		output := strings.TrimSpace(stdout.String() + stderr.String())
+		if strings.Contains(output, credentialsNotFound) {
+			return types.AuthConfig{}, nil
+		}
+		if output != "" {
+			return types.AuthConfig{}, errors.Errorf("credential helper %s failed: %v: %s",
+				authProvider.helper, err, output)
+		}
+		return types.AuthConfig{}, errors.Wrapf(err, "credential helper %s failed", authProvider.helper)
+	}
+
+	var response credentialHelperResponse
+	if err := json.Unmarshal(stdout.Bytes(), &response); err != nil {
+		return types.AuthConfig{}, errors.Wrapf(err, "unable to parse the response of credential helper %s",
+			authProvider.helper)
+	}
+	if response.Username == identityTokenUsername {
+		return types.AuthConfig{
+			ServerAddress: serverURL,
+			IdentityToken: response.Secret,
+		}, nil
+	}
+	return types.AuthConfig{
+		ServerAddress: serverURL,
+		Username:      response.Username,
+		Password:      response.Secret,
+	}, nil
+}
@@ -0,0 +1,88 @@
+// +build !windows,unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package dockerauth
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupCredentialHelper installs a credential helper running the script in a
+// directory prepended to PATH
+func setupCredentialHelper(t *testing.T, helper string, script string) func() {
+	dir, err := ioutil.TempDir("", "credential-helper")
+	require.NoError(t, err)
+	err = ioutil.WriteFile(filepath.Join(dir, credentialHelperPrefix+helper),
+		[]byte("#!/bin/sh\n"+script), 0755)
+	require.NoError(t, err)
+	path := os.Getenv("PATH")
+	os.Setenv("PATH", dir+string(os.PathListSeparator)+path)
+	return func() {
+		os.Setenv("PATH", path)
+		os.RemoveAll(dir)
+	}
+}
+
+func TestCredentialHelperAuthProvider(t *testing.T) {
+	defer setupCredentialHelper(t, "test", `read server
+echo "{\"ServerURL\":\"$server\",\"Username\":\"user-$server\",\"Secret\":\"secret\"}"`)()
+
+	authConfig, err := NewCredentialHelperAuthProvider("test").GetAuthconfig("registry.example.com/app:latest", nil)
+	require.NoError(t, err)
+	assert.Equal(t, types.AuthConfig{
+		ServerAddress: "registry.example.com",
+		Username:      "user-registry.example.com",
+		Password:      "secret",
+	}, authConfig)
+}
+
+func TestCredentialHelperAuthProviderIdentityToken(t *testing.T) {
+	defer setupCredentialHelper(t, "test", `echo '{"Username":"<token>","Secret":"token"}'`)()
+
+	authConfig, err := NewCredentialHelperAuthProvider("test").GetAuthconfig("busybox", nil)
+	require.NoError(t, err)
+	assert.Equal(t, types.AuthConfig{
+		ServerAddress: "https://index.docker.io/v1/",
+		IdentityToken: "token",
+	}, authConfig)
+}
+
+func TestCredentialHelperAuthProviderNotFound(t *testing.T) {
+	defer setupCredentialHelper(t, "test", `echo "credentials not found in native keychain"; exit 1`)()
+
+	authConfig, err := NewCredentialHelperAuthProvider("test").GetAuthconfig("registry.example.com/app", nil)
+	require.NoError(t, err)
+	assert.Equal(t, types.AuthConfig{}, authConfig)
+}
+
+func TestCredentialHelperAuthProviderError(t *testing.T) {
+	defer setupCredentialHelper(t, "test", `echo "access denied" >&2; exit 1`)()
+
+	_, err := NewCredentialHelperAuthProvider("test").GetAuthconfig("registry.example.com/app", nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "access denied")
+}
+
+func TestCredentialHelperAuthProviderMissingHelper(t *testing.T) {
+	_, err := NewCredentialHelperAuthProvider("does-not-exist").GetAuthconfig("registry.example.com/app", nil)
+	assert.Error(t, err)
+}
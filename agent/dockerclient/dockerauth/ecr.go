@@ -80,6 +80,29 @@ func (authProvider *ecrAuthProvider) GetAuthconfig(image string,
 
 	// First try to get the token from cache, if the token does not exist,
 	// then call ECR api to get the new token
+	key := newCacheKey(authData)
+
+	// Try to get the auth config from cache
+	auth := authProvider.getAuthConfigFromCache(key)
+	if auth != nil {
+		return *auth, nil
+	}
+
+	// Get the auth config from ECR
+	return authProvider.getAuthConfigFromECR(image, key, authData)
+}
+
+// InvalidateECRAuthconfig removes the cached ECR token of the registry auth
+// data, so that the next pull with it retrieves a new token
+func InvalidateECRAuthconfig(cache async.Cache, registryAuthData *apicontainer.RegistryAuthenticationData) {
+	if registryAuthData == nil || registryAuthData.ECRAuthData == nil {
+		return
+	}
+	key := newCacheKey(registryAuthData.ECRAuthData)
+	cache.Delete(key.String())
+}
+
+func newCacheKey(authData *apicontainer.ECRAuthData) cacheKey {
 	key := cacheKey{
 		region:           authData.Region,
 		endpointOverride: authData.EndpointOverride,
@@ -92,15 +115,7 @@ func (authProvider *ecrAuthProvider) GetAuthconfig(image string,
 	if authData.GetPullCredentials() != (credentials.IAMRoleCredentials{}) {
 		key.roleARN = authData.GetPullCredentials().RoleArn
 	}
-
-	// Try to get the auth config from cache
-	auth := authProvider.getAuthConfigFromCache(key)
-	if auth != nil {
-		return *auth, nil
-	}
-
-	// Get the auth config from ECR
-	return authProvider.getAuthConfigFromECR(image, key, authData)
+	return key
 }
 
 // getAuthconfigFromCache retrieves the token from cache
@@ -517,3 +517,25 @@ func TestExtractECRTokenError(t *testing.T) {
 	assert.Equal(t, username, authconfig.Username)
 	assert.Equal(t, password, authconfig.Password)
 }
+
+func TestInvalidateECRAuthconfig(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockCache := mock_async.NewMockCache(ctrl)
+
+	authData := &apicontainer.ECRAuthData{
+		Region:     "us-west-2",
+		RegistryID: "0123456789012",
+	}
+	authData.SetPullCredentials(credentials.IAMRoleCredentials{RoleArn: "arn:aws:iam::123456789012:role/test"})
+	key := cacheKey{
+		region:     authData.Region,
+		registryID: authData.RegistryID,
+		roleARN:    "arn:aws:iam::123456789012:role/test",
+	}
+
+	mockCache.EXPECT().Delete(key.String())
+	InvalidateECRAuthconfig(mockCache, &apicontainer.RegistryAuthenticationData{ECRAuthData: authData})
+	InvalidateECRAuthconfig(mockCache, &apicontainer.RegistryAuthenticationData{})
+	InvalidateECRAuthconfig(mockCache, nil)
+}
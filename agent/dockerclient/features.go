@@ -0,0 +1,68 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package dockerclient
+
+// Feature is a Docker feature that is only available starting with a given
+// Docker API version
+type Feature string
+
+const (
+	// ECRAuthFeature is pulling images from ECR with auth tokens
+	ECRAuthFeature Feature = "ecr-auth"
+	// TaskIAMRoleFeature is serving task IAM role credentials to containers
+	TaskIAMRoleFeature Feature = "task-iam-role"
+	// TaskCPUMemLimitFeature is creating containers in a task level cgroup
+	TaskCPUMemLimitFeature Feature = "task-cpu-mem-limit"
+	// HealthCheckFeature is the container health check
+	HealthCheckFeature Feature = "container-health-check"
+	// InitProcessFeature is running an init process as PID 1 of containers
+	InitProcessFeature Feature = "init-process"
+)
+
+// FeatureMinimumVersion maps each feature to the minimum Docker API version
+// that supports it
+var FeatureMinimumVersion = map[Feature]DockerVersion{
+	ECRAuthFeature:         Version_1_19,
+	TaskIAMRoleFeature:     Version_1_19,
+	TaskCPUMemLimitFeature: Version_1_22,
+	HealthCheckFeature:     Version_1_24,
+	InitProcessFeature:     Version_1_25,
+}
+
+// NegotiateAPIVersion returns the highest of the given Docker API versions,
+// which are expected to be supported by both the agent and the Docker daemon.
+// An empty version is returned if there's no such version.
+func NegotiateAPIVersion(supportedVersions []DockerVersion) DockerVersion {
+	var negotiatedVersion DockerVersion
+	for _, version := range supportedVersions {
+		if negotiatedVersion == "" {
+			negotiatedVersion = version
+			continue
+		}
+		if isNewer, err := DockerAPIVersion(version).Matches(">" + string(negotiatedVersion)); err == nil && isNewer {
+			negotiatedVersion = version
+		}
+	}
+	return negotiatedVersion
+}
+
+// Supports returns true if the feature is available with the Docker API version
+func (d DockerVersion) Supports(feature Feature) bool {
+	minimumVersion, ok := FeatureMinimumVersion[feature]
+	if !ok || d == "" {
+		return false
+	}
+	supported, err := DockerAPIVersion(d).Matches(">=" + string(minimumVersion))
+	return err == nil && supported
+}
@@ -0,0 +1,50 @@
+// +build unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package dockerclient
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNegotiateAPIVersion(t *testing.T) {
+	assert.Equal(t, Version_1_32, NegotiateAPIVersion([]DockerVersion{Version_1_17, Version_1_32, Version_1_24}))
+	assert.Equal(t, Version_1_21, NegotiateAPIVersion([]DockerVersion{Version_1_21}))
+	assert.Equal(t, DockerVersion(""), NegotiateAPIVersion(nil))
+}
+
+func TestDockerVersionSupports(t *testing.T) {
+	testCases := []struct {
+		version   DockerVersion
+		feature   Feature
+		supported bool
+	}{
+		{Version_1_17, ECRAuthFeature, false},
+		{Version_1_19, ECRAuthFeature, true},
+		{Version_1_23, HealthCheckFeature, false},
+		{Version_1_24, HealthCheckFeature, true},
+		{Version_1_24, InitProcessFeature, false},
+		{Version_1_25, InitProcessFeature, true},
+		{"", TaskCPUMemLimitFeature, false},
+		{Version_1_32, Feature("unknown"), false},
+	}
+	for _, tc := range testCases {
+		t.Run(string(tc.version)+"-"+string(tc.feature), func(t *testing.T) {
+			assert.Equal(t, tc.supported, tc.version.Supports(tc.feature))
+		})
+	}
+}
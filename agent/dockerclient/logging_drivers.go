@@ -13,6 +13,12 @@
 
 package dockerclient
 
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
 type LoggingDriver string
 
 const (
@@ -26,6 +32,7 @@ const (
 	LogentriesDriver LoggingDriver = "logentries"
 	SumoLogicDriver  LoggingDriver = "sumologic"
 	NoneDriver       LoggingDriver = "none"
+	LocalDriver      LoggingDriver = "local"
 )
 
 var LoggingDriverMinimumVersion = map[LoggingDriver]DockerVersion{
@@ -40,3 +47,63 @@ var LoggingDriverMinimumVersion = map[LoggingDriver]DockerVersion{
 	SumoLogicDriver:  Version_1_29,
 	NoneDriver:       Version_1_19,
 }
+
+// loggingDriverCommonOptions are the log options accepted by docker for all
+// logging drivers
+var loggingDriverCommonOptions = []string{"mode", "max-buffer-size"}
+
+// loggingDriverValidOptions maps the logging drivers whose options are
+// validated before the container is created to the options they accept
+var loggingDriverValidOptions = map[LoggingDriver][]string{
+	JournaldDriver: {"tag", "labels", "labels-regex", "env", "env-regex"},
+}
+
+// readableLoggingDrivers are the logging drivers that keep the logs of the
+// containers on the instance, where docker can read them back from
+var readableLoggingDrivers = []LoggingDriver{JSONFileDriver, JournaldDriver, LocalDriver}
+
+// IsReadableLoggingDriver returns true if docker can read back the logs of
+// containers using the logging driver. An empty driver stands for the default
+// driver of the daemon, which is json-file unless the daemon is configured
+// otherwise.
+func IsReadableLoggingDriver(driver LoggingDriver) bool {
+	if driver == "" {
+		return true
+	}
+	for _, readable := range readableLoggingDrivers {
+		if driver == readable {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateLoggingDriverOptions returns an error if any of the options isn't
+// accepted by the logging driver. Options of drivers that are not validated
+// by the agent are left for docker to validate.
+func ValidateLoggingDriverOptions(driver LoggingDriver, options map[string]string) error {
+	validOptions, ok := loggingDriverValidOptions[driver]
+	if !ok {
+		return nil
+	}
+	var invalidOptions []string
+	for option := range options {
+		if !containsOption(validOptions, option) && !containsOption(loggingDriverCommonOptions, option) {
+			invalidOptions = append(invalidOptions, option)
+		}
+	}
+	if len(invalidOptions) > 0 {
+		sort.Strings(invalidOptions)
+		return fmt.Errorf("invalid options for logging driver %s: %s", driver, strings.Join(invalidOptions, ", "))
+	}
+	return nil
+}
+
+func containsOption(options []string, option string) bool {
+	for _, o := range options {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
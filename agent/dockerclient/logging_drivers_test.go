@@ -0,0 +1,44 @@
+// +build unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package dockerclient
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateJournaldLoggingDriverOptions(t *testing.T) {
+	assert.NoError(t, ValidateLoggingDriverOptions(JournaldDriver, map[string]string{
+		"tag":       "{{.Name}}",
+		"labels":    "app",
+		"env":       "ENVIRONMENT",
+		"env-regex": "^ECS_",
+		"mode":      "non-blocking",
+	}))
+	assert.NoError(t, ValidateLoggingDriverOptions(JournaldDriver, nil))
+
+	err := ValidateLoggingDriverOptions(JournaldDriver, map[string]string{
+		"tag":           "{{.Name}}",
+		"syslog-format": "rfc5424",
+		"awslogs-group": "group",
+	})
+	assert.EqualError(t, err, "invalid options for logging driver journald: awslogs-group, syslog-format")
+}
+
+func TestValidateLoggingDriverOptionsSkipsUnvalidatedDrivers(t *testing.T) {
+	assert.NoError(t, ValidateLoggingDriverOptions(AWSLogsDriver, map[string]string{"unknown": "value"}))
+}
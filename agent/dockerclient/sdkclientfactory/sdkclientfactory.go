@@ -36,7 +36,8 @@ const (
 // recommended client version as well as a set of alternative supported
 // docker clients.
 type Factory interface {
-	// GetDefaultClient returns a versioned client for the default version
+	// GetDefaultClient returns a versioned client for the version negotiated
+	// with the Docker daemon, the highest version supported by both
 	GetDefaultClient() (sdkclient.Client, error)
 
 	// GetClient returns a client with the specified version or an error
@@ -66,6 +67,8 @@ type Factory interface {
 type factory struct {
 	endpoint string
 	clients  map[dockerclient.DockerVersion]sdkclient.Client
+	// negotiatedVersion is the version of the default client
+	negotiatedVersion dockerclient.DockerVersion
 }
 
 // newVersionedClient is a variable such that the implementation can be
@@ -76,14 +79,27 @@ var newVersionedClient = func(endpoint, version string) (sdkclient.Client, error
 
 // NewFactory initializes a client factory using a specified endpoint.
 func NewFactory(ctx context.Context, endpoint string) Factory {
-	return &factory{
+	f := &factory{
 		endpoint: endpoint,
 		clients:  findDockerVersions(ctx, endpoint),
 	}
+	f.negotiatedVersion = f.negotiateAPIVersion()
+	return f
 }
 
 func (f *factory) GetDefaultClient() (sdkclient.Client, error) {
-	return f.GetClient(GetDefaultVersion())
+	return f.GetClient(f.negotiatedVersion)
+}
+
+// negotiateAPIVersion returns the highest Docker API version supported by both
+// the agent and the Docker daemon, the default version if there's none
+func (f *factory) negotiateAPIVersion() dockerclient.DockerVersion {
+	negotiatedVersion := dockerclient.NegotiateAPIVersion(f.FindSupportedAPIVersions())
+	if negotiatedVersion == "" {
+		return GetDefaultVersion()
+	}
+	log.Infof("Negotiated Docker API version %s with the Docker daemon", negotiatedVersion)
+	return negotiatedVersion
 }
 
 func (f *factory) FindSupportedAPIVersions() []dockerclient.DockerVersion {
@@ -33,10 +33,11 @@ func TestGetDefaultClientSuccess(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
+	agentVersions := getAgentSupportedDockerVersions()
 	expectedClient := mock_sdkclient.NewMockClient(ctrl)
 	newVersionedClient = func(endpoint, version string) (sdkclient.Client, error) {
 		mockClient := mock_sdkclient.NewMockClient(ctrl)
-		if version == string(GetDefaultVersion()) {
+		if version == string(agentVersions[len(agentVersions)-1]) {
 			mockClient = expectedClient
 		}
 		mockClient.EXPECT().ServerVersion(gomock.Any()).Return(docker.Version{}, nil).AnyTimes()
@@ -53,6 +54,33 @@ func TestGetDefaultClientSuccess(t *testing.T) {
 	assert.Equal(t, expectedClient, actualClient)
 }
 
+func TestGetDefaultClientNegotiatesVersion(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	expectedClient := mock_sdkclient.NewMockClient(ctrl)
+	newVersionedClient = func(endpoint, version string) (sdkclient.Client, error) {
+		mockClient := mock_sdkclient.NewMockClient(ctrl)
+		if version == string(dockerclient.Version_1_25) {
+			mockClient = expectedClient
+		}
+		mockClient.EXPECT().ServerVersion(gomock.Any()).Return(docker.Version{
+			APIVersion:    string(dockerclient.Version_1_25),
+			MinAPIVersion: string(dockerclient.Version_1_17),
+		}, nil).AnyTimes()
+		mockClient.EXPECT().Ping(gomock.Any()).AnyTimes()
+
+		return mockClient, nil
+	}
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+
+	factory := NewFactory(ctx, expectedEndpoint)
+	actualClient, err := factory.GetDefaultClient()
+	assert.Nil(t, err)
+	assert.Equal(t, expectedClient, actualClient, "the highest version supported by the daemon should be used")
+}
+
 func TestFindSupportedAPIVersions(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
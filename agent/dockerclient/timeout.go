@@ -33,6 +33,10 @@ const (
 	LoadImageTimeout = 2 * time.Minute
 	// RemoveImageTimeout is the timeout for the RemoveImage API.
 	RemoveImageTimeout = 3 * time.Minute
+	// TagImageTimeout is the timeout for the TagImage API.
+	TagImageTimeout = 30 * time.Second
+	// ResolveImageDigestTimeout is the timeout for the ResolveImageDigest API.
+	ResolveImageDigestTimeout = 1 * time.Minute
 
 	// CreateContainerTimeout is the timeout for the CreateContainer API.
 	CreateContainerTimeout = 4 * time.Minute
@@ -44,6 +48,19 @@ const (
 	StopContainerTimeout = 30 * time.Second
 	// RemoveContainerTimeout is the timeout for the RemoveContainer API.
 	RemoveContainerTimeout = 5 * time.Minute
+	// KillContainerTimeout is the timeout for the KillContainer API.
+	KillContainerTimeout = 30 * time.Second
+
+	// CreateNetworkTimeout is the timeout for the CreateNetwork API.
+	CreateNetworkTimeout = 1 * time.Minute
+	// RemoveNetworkTimeout is the timeout for the RemoveNetwork API.
+	RemoveNetworkTimeout = 1 * time.Minute
+	// ListNetworksTimeout is the timeout for the ListNetworks API.
+	ListNetworksTimeout = 1 * time.Minute
+
+	// ContainerExecTimeout is the timeout for the CreateContainerExec,
+	// StartContainerExec and InspectContainerExec APIs.
+	ContainerExecTimeout = 1 * time.Minute
 
 	// CreateVolumeTimeout is the timeout for CreateVolume API.
 	CreateVolumeTimeout = 5 * time.Minute
@@ -66,4 +83,7 @@ const (
 
 	// VersionTimeout is the timeout for the Version API
 	VersionTimeout = 10 * time.Second
+
+	// InfoTimeout is the timeout for the Info API
+	InfoTimeout = 10 * time.Second
 )
@@ -81,6 +81,10 @@ func (blackholeMetadataClient) SpotInstanceAction() (string, error) {
 	return "", errors.New("blackholed")
 }
 
+func (blackholeMetadataClient) TargetLifecycleState() (string, error) {
+	return "", errors.New("blackholed")
+}
+
 func (blackholeMetadataClient) OutpostARN() (string, error) {
 	return "", errors.New("blackholed")
 }
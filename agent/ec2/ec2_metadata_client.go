@@ -17,6 +17,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
 	"strings"
 	"time"
 
@@ -34,6 +35,7 @@ const (
 	VPCIDResourceFormat                       = "network/interfaces/macs/%s/vpc-id"
 	SubnetIDResourceFormat                    = "network/interfaces/macs/%s/subnet-id"
 	SpotInstanceActionResource                = "spot/instance-action"
+	TargetLifecycleStateResource              = "autoscaling/target-lifecycle-state"
 	InstanceIDResource                        = "instance-id"
 	PrivateIPv4Resource                       = "local-ipv4"
 	PublicIPv4Resource                        = "public-ipv4"
@@ -42,6 +44,9 @@ const (
 
 const (
 	metadataRetries = 5
+	// metadataTimeout is the timeout of requests to the instance metadata
+	// service. It matches the one the SDK uses when no http client is set
+	metadataTimeout = 5 * time.Second
 )
 
 // RoleCredentials contains the information associated with an IAM role
@@ -79,6 +84,7 @@ type EC2MetadataClient interface {
 	PrivateIPv4Address() (string, error)
 	PublicIPv4Address() (string, error)
 	SpotInstanceAction() (string, error)
+	TargetLifecycleState() (string, error)
 	OutpostARN() (string, error)
 }
 
@@ -98,6 +104,19 @@ func NewEC2MetadataClient(client HttpClient) EC2MetadataClient {
 	}
 }
 
+// NewEC2MetadataClientWithTransport creates an ec2metadata client that sends
+// requests to the instance metadata service over the given transport
+func NewEC2MetadataClientWithTransport(transport http.RoundTripper) EC2MetadataClient {
+	httpClient := &http.Client{
+		Timeout:   metadataTimeout,
+		Transport: transport,
+	}
+	return &ec2MetadataClientImpl{
+		client: ec2metadata.New(session.New(),
+			aws.NewConfig().WithMaxRetries(metadataRetries).WithHTTPClient(httpClient)),
+	}
+}
+
 // DefaultCredentials returns the credentials associated with the instance iam role
 func (c *ec2MetadataClientImpl) DefaultCredentials() (*RoleCredentials, error) {
 	securityCredential, err := c.client.GetMetadata(SecurityCrednetialsResource)
@@ -197,6 +216,14 @@ func (c *ec2MetadataClientImpl) SpotInstanceAction() (string, error) {
 	return c.client.GetMetadata(SpotInstanceActionResource)
 }
 
+// TargetLifecycleState returns the lifecycle state the auto scaling group of
+// the instance is transitioning it to, such as InService or Terminated. It
+// returns an error when the instance isn't in an auto scaling group.
+// see https://docs.aws.amazon.com/autoscaling/ec2/userguide/retrieving-target-lifecycle-state-through-imds.html
+func (c *ec2MetadataClientImpl) TargetLifecycleState() (string, error) {
+	return c.client.GetMetadata(TargetLifecycleStateResource)
+}
+
 func (c *ec2MetadataClientImpl) OutpostARN() (string, error) {
 	return c.client.GetMetadata(OutpostARN)
 }
@@ -252,3 +252,16 @@ func TestSpotInstanceActionError(t *testing.T) {
 	assert.Error(t, err)
 	assert.Equal(t, "", resp)
 }
+
+func TestTargetLifecycleState(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockGetter := mock_ec2.NewMockHttpClient(ctrl)
+	testClient := ec2.NewEC2MetadataClient(mockGetter)
+
+	mockGetter.EXPECT().GetMetadata(ec2.TargetLifecycleStateResource).Return("Terminated", nil)
+	resp, err := testClient.TargetLifecycleState()
+	assert.NoError(t, err)
+	assert.Equal(t, "Terminated", resp)
+}
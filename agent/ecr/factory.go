@@ -22,7 +22,9 @@ import (
 	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
 	"github.com/aws/amazon-ecs-agent/agent/credentials"
 	ecrapi "github.com/aws/amazon-ecs-agent/agent/ecr/model/ecr"
+	"github.com/aws/amazon-ecs-agent/agent/endpoints"
 	"github.com/aws/amazon-ecs-agent/agent/httpclient"
+	"github.com/aws/amazon-ecs-agent/agent/metrics"
 	"github.com/aws/aws-sdk-go/aws"
 	awscreds "github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
@@ -34,23 +36,28 @@ type ECRFactory interface {
 }
 
 type ecrFactory struct {
-	httpClient *http.Client
+	httpClient      *http.Client
+	endpointVariant endpoints.Variant
 }
 
 const (
 	roundtripTimeout = 5 * time.Second
 )
 
-// NewECRFactory returns an ECRFactory capable of producing ECRSDK clients
-func NewECRFactory(acceptInsecureCert bool) ECRFactory {
+// NewECRFactory returns an ECRFactory capable of producing ECRSDK clients,
+// which use the variant of the ECR endpoints
+func NewECRFactory(acceptInsecureCert bool, endpointVariant endpoints.Variant) ECRFactory {
+	httpClient := httpclient.New(roundtripTimeout, acceptInsecureCert)
+	httpClient.Transport = httpclient.WithLatencyMetrics(httpClient.Transport, metrics.ECRService)
 	return &ecrFactory{
-		httpClient: httpclient.New(roundtripTimeout, acceptInsecureCert),
+		httpClient:      httpClient,
+		endpointVariant: endpointVariant,
 	}
 }
 
 // GetClient creates the ECR SDK client based on the authdata
 func (factory *ecrFactory) GetClient(authData *apicontainer.ECRAuthData) (ECRClient, error) {
-	clientConfig, err := getClientConfig(factory.httpClient, authData)
+	clientConfig, err := getClientConfig(factory.httpClient, authData, factory.endpointVariant)
 	if err != nil {
 		return &ecrClient{}, err
 	}
@@ -59,10 +66,13 @@ func (factory *ecrFactory) GetClient(authData *apicontainer.ECRAuthData) (ECRCli
 }
 
 // getClientConfig returns the config for the ecr client based on authData
-func getClientConfig(httpClient *http.Client, authData *apicontainer.ECRAuthData) (*aws.Config, error) {
+func getClientConfig(httpClient *http.Client, authData *apicontainer.ECRAuthData,
+	endpointVariant endpoints.Variant) (*aws.Config, error) {
 	cfg := aws.NewConfig().WithRegion(authData.Region).WithHTTPClient(httpClient)
 	if authData.EndpointOverride != "" {
 		cfg.Endpoint = aws.String(authData.EndpointOverride)
+	} else if endpoint := endpointVariant.ServiceEndpoint(endpoints.ECR, authData.Region); endpoint != "" {
+		cfg.Endpoint = aws.String(endpoint)
 	}
 
 	if authData.UseExecutionRole {
@@ -19,6 +19,7 @@ import (
 	"testing"
 
 	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	"github.com/aws/amazon-ecs-agent/agent/endpoints"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -29,8 +30,33 @@ func TestGetClientConfigEndpointOverride(t *testing.T) {
 		UseExecutionRole: false,
 	}
 
-	cfg, err := getClientConfig(nil, testAuthData)
+	cfg, err := getClientConfig(nil, testAuthData, endpoints.Variant{FIPS: true})
 
 	assert.Nil(t, err)
 	assert.Equal(t, testAuthData.EndpointOverride, *cfg.Endpoint)
 }
+
+func TestGetClientConfigEndpointVariant(t *testing.T) {
+	testAuthData := &apicontainer.ECRAuthData{
+		Region:           "us-gov-west-1",
+		UseExecutionRole: false,
+	}
+
+	cfg, err := getClientConfig(nil, testAuthData, endpoints.Variant{FIPS: true})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "https://ecr-fips.us-gov-west-1.amazonaws.com", *cfg.Endpoint)
+	assert.Equal(t, "us-gov-west-1", *cfg.Region)
+}
+
+func TestGetClientConfigStandardEndpoint(t *testing.T) {
+	testAuthData := &apicontainer.ECRAuthData{
+		Region:           "us-west-2",
+		UseExecutionRole: false,
+	}
+
+	cfg, err := getClientConfig(nil, testAuthData, endpoints.Variant{})
+
+	assert.Nil(t, err)
+	assert.Nil(t, cfg.Endpoint)
+}
@@ -0,0 +1,92 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ecr
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	"github.com/docker/distribution/reference"
+)
+
+// ecrRegistryRegex matches the registry host of an ECR repository and captures
+// the registry id and the region, e.g. 012345678910.dkr.ecr.us-west-2.amazonaws.com
+var ecrRegistryRegex = regexp.MustCompile(`^([0-9]{12})\.dkr\.ecr(?:-fips)?\.([a-z0-9-]+)\.amazonaws\.com(?:\.cn)?$`)
+
+// PullThroughCacheResolver rewrites references to images in upstream registries
+// into references to the ECR pull through cache repositories configured on the
+// instance
+type PullThroughCacheResolver struct {
+	// rules maps the upstream registry to the ECR repository prefix that caches it
+	rules map[string]pullThroughCacheRule
+}
+
+type pullThroughCacheRule struct {
+	repositoryPrefix string
+	registryID       string
+	region           string
+}
+
+// NewPullThroughCacheResolver returns a resolver for the given rules, which map
+// an upstream registry (e.g. quay.io) to the ECR repository prefix of its pull
+// through cache (e.g. 012345678910.dkr.ecr.us-west-2.amazonaws.com/quay)
+func NewPullThroughCacheResolver(rules map[string]string) (*PullThroughCacheResolver, error) {
+	resolver := &PullThroughCacheResolver{
+		rules: make(map[string]pullThroughCacheRule),
+	}
+	for upstreamRegistry, repositoryPrefix := range rules {
+		repositoryPrefix = strings.TrimSuffix(repositoryPrefix, "/")
+		registry := strings.SplitN(repositoryPrefix, "/", 2)[0]
+		matches := ecrRegistryRegex.FindStringSubmatch(registry)
+		if matches == nil {
+			return nil, fmt.Errorf("ecr pull through cache: %s is not an ECR repository prefix", repositoryPrefix)
+		}
+		resolver.rules[upstreamRegistry] = pullThroughCacheRule{
+			repositoryPrefix: repositoryPrefix,
+			registryID:       matches[1],
+			region:           matches[2],
+		}
+	}
+	return resolver, nil
+}
+
+// Resolve returns the reference of the image in the pull through cache along
+// with the ECR auth data required to pull it. The last return value is false if
+// there's no pull through cache rule for the registry of the image.
+func (resolver *PullThroughCacheResolver) Resolve(image string) (string, *apicontainer.ECRAuthData, bool) {
+	if resolver == nil || len(resolver.rules) == 0 {
+		return "", nil, false
+	}
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return "", nil, false
+	}
+	rule, ok := resolver.rules[reference.Domain(named)]
+	if !ok {
+		return "", nil, false
+	}
+
+	cacheImage := rule.repositoryPrefix + "/" + reference.Path(named)
+	if digested, ok := named.(reference.Digested); ok {
+		cacheImage += "@" + digested.Digest().String()
+	} else if tagged, ok := named.(reference.Tagged); ok {
+		cacheImage += ":" + tagged.Tag()
+	}
+	return cacheImage, &apicontainer.ECRAuthData{
+		RegistryID: rule.registryID,
+		Region:     rule.region,
+	}, true
+}
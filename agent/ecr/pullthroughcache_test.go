@@ -0,0 +1,74 @@
+// +build unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ecr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testCachePrefix = "012345678910.dkr.ecr.us-west-2.amazonaws.com"
+
+func TestPullThroughCacheResolve(t *testing.T) {
+	resolver, err := NewPullThroughCacheResolver(map[string]string{
+		"quay.io":   testCachePrefix + "/quay/",
+		"docker.io": testCachePrefix + "/docker-hub",
+	})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		image      string
+		cacheImage string
+	}{
+		{"quay.io/coreos/etcd:v3.3", testCachePrefix + "/quay/coreos/etcd:v3.3"},
+		{"busybox", testCachePrefix + "/docker-hub/library/busybox"},
+		{"amazon/amazon-ecs-agent:latest", testCachePrefix + "/docker-hub/amazon/amazon-ecs-agent:latest"},
+		{"quay.io/coreos/etcd@sha256:1cd52e7b6d8c2b1d0e8a8b0c8c9e4ba1d7f4e0f37c7d3a0e2e2b0d4f3a2c1b0a",
+			testCachePrefix + "/quay/coreos/etcd@sha256:1cd52e7b6d8c2b1d0e8a8b0c8c9e4ba1d7f4e0f37c7d3a0e2e2b0d4f3a2c1b0a"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.image, func(t *testing.T) {
+			cacheImage, authData, ok := resolver.Resolve(tc.image)
+			require.True(t, ok)
+			assert.Equal(t, tc.cacheImage, cacheImage)
+			assert.Equal(t, "012345678910", authData.RegistryID)
+			assert.Equal(t, "us-west-2", authData.Region)
+		})
+	}
+}
+
+func TestPullThroughCacheResolveNoRule(t *testing.T) {
+	resolver, err := NewPullThroughCacheResolver(map[string]string{
+		"quay.io": testCachePrefix + "/quay",
+	})
+	require.NoError(t, err)
+
+	_, _, ok := resolver.Resolve("gcr.io/google-containers/pause:3.1")
+	assert.False(t, ok)
+
+	var nilResolver *PullThroughCacheResolver
+	_, _, ok = nilResolver.Resolve("quay.io/coreos/etcd")
+	assert.False(t, ok)
+}
+
+func TestNewPullThroughCacheResolverInvalidPrefix(t *testing.T) {
+	_, err := NewPullThroughCacheResolver(map[string]string{
+		"quay.io": "registry.example.com/quay",
+	})
+	assert.Error(t, err)
+}
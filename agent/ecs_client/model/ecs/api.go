@@ -5386,8 +5386,23 @@ type ContainerStateChange struct {
 	// exiting.
 	ExitCode *int64 `locationName:"exitCode" type:"integer"`
 
+	// The health status of the container, if it has a health check.
+	HealthStatus *string `locationName:"healthStatus" type:"string" enum:"HealthStatus"`
+
 	ImageDigest *string `locationName:"imageDigest" type:"string"`
 
+	// The time the agent started pulling the image of the container.
+	ImagePullStartedAt *time.Time `locationName:"imagePullStartedAt" type:"timestamp"`
+
+	// The time the agent finished pulling the image of the container.
+	ImagePullStoppedAt *time.Time `locationName:"imagePullStoppedAt" type:"timestamp"`
+
+	// The host of the registry the image of the container was pulled from.
+	ImageRegistry *string `locationName:"imageRegistry" type:"string"`
+
+	// The size of the image of the container in bytes.
+	ImageSize *int64 `locationName:"imageSize" type:"long"`
+
 	// Any network bindings associated with the container.
 	NetworkBindings []*NetworkBinding `locationName:"networkBindings" type:"list"`
 
@@ -5422,12 +5437,42 @@ func (s *ContainerStateChange) SetExitCode(v int64) *ContainerStateChange {
 	return s
 }
 
+// SetHealthStatus sets the HealthStatus field's value.
+func (s *ContainerStateChange) SetHealthStatus(v string) *ContainerStateChange {
+	s.HealthStatus = &v
+	return s
+}
+
 // SetImageDigest sets the ImageDigest field's value.
 func (s *ContainerStateChange) SetImageDigest(v string) *ContainerStateChange {
 	s.ImageDigest = &v
 	return s
 }
 
+// SetImagePullStartedAt sets the ImagePullStartedAt field's value.
+func (s *ContainerStateChange) SetImagePullStartedAt(v time.Time) *ContainerStateChange {
+	s.ImagePullStartedAt = &v
+	return s
+}
+
+// SetImagePullStoppedAt sets the ImagePullStoppedAt field's value.
+func (s *ContainerStateChange) SetImagePullStoppedAt(v time.Time) *ContainerStateChange {
+	s.ImagePullStoppedAt = &v
+	return s
+}
+
+// SetImageRegistry sets the ImageRegistry field's value.
+func (s *ContainerStateChange) SetImageRegistry(v string) *ContainerStateChange {
+	s.ImageRegistry = &v
+	return s
+}
+
+// SetImageSize sets the ImageSize field's value.
+func (s *ContainerStateChange) SetImageSize(v int64) *ContainerStateChange {
+	s.ImageSize = &v
+	return s
+}
+
 // SetNetworkBindings sets the NetworkBindings field's value.
 func (s *ContainerStateChange) SetNetworkBindings(v []*NetworkBinding) *ContainerStateChange {
 	s.NetworkBindings = v
@@ -8547,6 +8592,82 @@ func (s *LogConfiguration) SetSecretOptions(v []*Secret) *LogConfiguration {
 	return s
 }
 
+// An object representing a change in state for a managed agent.
+type ManagedAgentStateChange struct {
+	_ struct{} `type:"structure"`
+
+	// The name of the container associated with the managed agent.
+	//
+	// ContainerName is a required field
+	ContainerName *string `locationName:"containerName" type:"string" required:"true"`
+
+	// The name of the managed agent.
+	//
+	// ManagedAgentName is a required field
+	ManagedAgentName *string `locationName:"managedAgentName" type:"string" required:"true"`
+
+	// The reason for the status of the managed agent.
+	Reason *string `locationName:"reason" type:"string"`
+
+	// The status of the managed agent.
+	//
+	// Status is a required field
+	Status *string `locationName:"status" type:"string" required:"true"`
+}
+
+// String returns the string representation
+func (s ManagedAgentStateChange) String() string {
+	return awsutil.Prettify(s)
+}
+
+// GoString returns the string representation
+func (s ManagedAgentStateChange) GoString() string {
+	return s.String()
+}
+
+// Validate inspects the fields of the type to determine if they are valid.
+func (s *ManagedAgentStateChange) Validate() error {
+	invalidParams := request.ErrInvalidParams{Context: "ManagedAgentStateChange"}
+	if s.ContainerName == nil {
+		invalidParams.Add(request.NewErrParamRequired("ContainerName"))
+	}
+	if s.ManagedAgentName == nil {
+		invalidParams.Add(request.NewErrParamRequired("ManagedAgentName"))
+	}
+	if s.Status == nil {
+		invalidParams.Add(request.NewErrParamRequired("Status"))
+	}
+
+	if invalidParams.Len() > 0 {
+		return invalidParams
+	}
+	return nil
+}
+
+// SetContainerName sets the ContainerName field's value.
+func (s *ManagedAgentStateChange) SetContainerName(v string) *ManagedAgentStateChange {
+	s.ContainerName = &v
+	return s
+}
+
+// SetManagedAgentName sets the ManagedAgentName field's value.
+func (s *ManagedAgentStateChange) SetManagedAgentName(v string) *ManagedAgentStateChange {
+	s.ManagedAgentName = &v
+	return s
+}
+
+// SetReason sets the Reason field's value.
+func (s *ManagedAgentStateChange) SetReason(v string) *ManagedAgentStateChange {
+	s.Reason = &v
+	return s
+}
+
+// SetStatus sets the Status field's value.
+func (s *ManagedAgentStateChange) SetStatus(v string) *ManagedAgentStateChange {
+	s.Status = &v
+	return s
+}
+
 // Details on a volume mount point that is used in a container definition.
 type MountPoint struct {
 	_ struct{} `type:"structure"`
@@ -10743,6 +10864,21 @@ type SubmitContainerStateChangeInput struct {
 	// The exit code returned for the state change request.
 	ExitCode *int64 `locationName:"exitCode" type:"integer"`
 
+	// The health status of the container, if it has a health check.
+	HealthStatus *string `locationName:"healthStatus" type:"string" enum:"HealthStatus"`
+
+	// The time the agent started pulling the image of the container.
+	ImagePullStartedAt *time.Time `locationName:"imagePullStartedAt" type:"timestamp"`
+
+	// The time the agent finished pulling the image of the container.
+	ImagePullStoppedAt *time.Time `locationName:"imagePullStoppedAt" type:"timestamp"`
+
+	// The host of the registry the image of the container was pulled from.
+	ImageRegistry *string `locationName:"imageRegistry" type:"string"`
+
+	// The size of the image of the container in bytes.
+	ImageSize *int64 `locationName:"imageSize" type:"long"`
+
 	// The network bindings of the container.
 	NetworkBindings []*NetworkBinding `locationName:"networkBindings" type:"list"`
 
@@ -10787,6 +10923,36 @@ func (s *SubmitContainerStateChangeInput) SetExitCode(v int64) *SubmitContainerS
 	return s
 }
 
+// SetHealthStatus sets the HealthStatus field's value.
+func (s *SubmitContainerStateChangeInput) SetHealthStatus(v string) *SubmitContainerStateChangeInput {
+	s.HealthStatus = &v
+	return s
+}
+
+// SetImagePullStartedAt sets the ImagePullStartedAt field's value.
+func (s *SubmitContainerStateChangeInput) SetImagePullStartedAt(v time.Time) *SubmitContainerStateChangeInput {
+	s.ImagePullStartedAt = &v
+	return s
+}
+
+// SetImagePullStoppedAt sets the ImagePullStoppedAt field's value.
+func (s *SubmitContainerStateChangeInput) SetImagePullStoppedAt(v time.Time) *SubmitContainerStateChangeInput {
+	s.ImagePullStoppedAt = &v
+	return s
+}
+
+// SetImageRegistry sets the ImageRegistry field's value.
+func (s *SubmitContainerStateChangeInput) SetImageRegistry(v string) *SubmitContainerStateChangeInput {
+	s.ImageRegistry = &v
+	return s
+}
+
+// SetImageSize sets the ImageSize field's value.
+func (s *SubmitContainerStateChangeInput) SetImageSize(v int64) *SubmitContainerStateChangeInput {
+	s.ImageSize = &v
+	return s
+}
+
 // SetNetworkBindings sets the NetworkBindings field's value.
 func (s *SubmitContainerStateChangeInput) SetNetworkBindings(v []*NetworkBinding) *SubmitContainerStateChangeInput {
 	s.NetworkBindings = v
@@ -10856,6 +11022,9 @@ type SubmitTaskStateChangeInput struct {
 	// The Unix time stamp for when the task execution stopped.
 	ExecutionStoppedAt *time.Time `locationName:"executionStoppedAt" type:"timestamp"`
 
+	// The details for the managed agents associated with the task.
+	ManagedAgents []*ManagedAgentStateChange `locationName:"managedAgents" type:"list"`
+
 	// The Unix time stamp for when the container image pull began.
 	PullStartedAt *time.Time `locationName:"pullStartedAt" type:"timestamp"`
 
@@ -10895,6 +11064,16 @@ func (s *SubmitTaskStateChangeInput) Validate() error {
 			}
 		}
 	}
+	if s.ManagedAgents != nil {
+		for i, v := range s.ManagedAgents {
+			if v == nil {
+				continue
+			}
+			if err := v.Validate(); err != nil {
+				invalidParams.AddNested(fmt.Sprintf("%s[%v]", "ManagedAgents", i), err.(request.ErrInvalidParams))
+			}
+		}
+	}
 
 	if invalidParams.Len() > 0 {
 		return invalidParams
@@ -10926,6 +11105,12 @@ func (s *SubmitTaskStateChangeInput) SetExecutionStoppedAt(v time.Time) *SubmitT
 	return s
 }
 
+// SetManagedAgents sets the ManagedAgents field's value.
+func (s *SubmitTaskStateChangeInput) SetManagedAgents(v []*ManagedAgentStateChange) *SubmitTaskStateChangeInput {
+	s.ManagedAgents = v
+	return s
+}
+
 // SetPullStartedAt sets the PullStartedAt field's value.
 func (s *SubmitTaskStateChangeInput) SetPullStartedAt(v time.Time) *SubmitTaskStateChangeInput {
 	s.PullStartedAt = &v
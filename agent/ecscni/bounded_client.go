@@ -0,0 +1,99 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ecscni
+
+import (
+	"context"
+	"time"
+
+	"github.com/cihub/seelog"
+	"github.com/containernetworking/cni/pkg/types/current"
+	"github.com/pkg/errors"
+)
+
+// boundedClient is a CNIClient that limits the number of CNI plugin
+// invocations that run at the same time, so that a burst of awsvpc tasks
+// doesn't overwhelm the instance. The timeout of an invocation includes the
+// time it waits for its turn
+type boundedClient struct {
+	CNIClient
+	slots chan struct{}
+}
+
+// NewBoundedClient returns a CNIClient that runs at most maxConcurrency
+// invocations of the CNI plugins of client at the same time. A maxConcurrency
+// of 0 or less doesn't limit them
+func NewBoundedClient(client CNIClient, maxConcurrency int) CNIClient {
+	if maxConcurrency <= 0 {
+		return client
+	}
+	return &boundedClient{
+		CNIClient: client,
+		slots:     make(chan struct{}, maxConcurrency),
+	}
+}
+
+// SetupNS sets up the namespace of the container once there's a free slot
+func (client *boundedClient) SetupNS(ctx context.Context, cfg *Config, timeout time.Duration) (*current.Result, error) {
+	remaining, release, err := client.acquire(ctx, cfg, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return client.CNIClient.SetupNS(ctx, cfg, remaining)
+}
+
+// CleanupNS cleans up the namespace of the container once there's a free slot
+func (client *boundedClient) CleanupNS(ctx context.Context, cfg *Config, timeout time.Duration) error {
+	remaining, release, err := client.acquire(ctx, cfg, timeout)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return client.CNIClient.CleanupNS(ctx, cfg, remaining)
+}
+
+// ReleaseIPResource releases the ip of the container once there's a free slot
+func (client *boundedClient) ReleaseIPResource(ctx context.Context, cfg *Config, timeout time.Duration) error {
+	remaining, release, err := client.acquire(ctx, cfg, timeout)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return client.CNIClient.ReleaseIPResource(ctx, cfg, remaining)
+}
+
+// acquire waits for a free slot, for up to timeout. It returns the part of
+// the timeout that's left, and the function that frees the slot
+func (client *boundedClient) acquire(ctx context.Context, cfg *Config,
+	timeout time.Duration) (time.Duration, func(), error) {
+	start := time.Now()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case client.slots <- struct{}{}:
+	case <-timer.C:
+		return 0, nil, errors.Errorf("timed out after %s waiting to invoke the cni plugins for container %s",
+			timeout, cfg.ContainerID)
+	case <-ctx.Done():
+		return 0, nil, errors.Wrapf(ctx.Err(), "cancelled waiting to invoke the cni plugins for container %s",
+			cfg.ContainerID)
+	}
+	waited := time.Since(start)
+	if waited > time.Second {
+		seelog.Infof("[ECSCNI] Waited %s to invoke the cni plugins for container %s", waited, cfg.ContainerID)
+	}
+	return timeout - waited, func() { <-client.slots }, nil
+}
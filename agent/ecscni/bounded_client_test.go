@@ -0,0 +1,82 @@
+// +build unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ecscni
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/containernetworking/cni/pkg/types/current"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingClient is a CNIClient whose namespace setups block until they're
+// released, and that records the timeouts it's given
+type blockingClient struct {
+	CNIClient
+	started  chan time.Duration
+	released chan struct{}
+}
+
+func (client *blockingClient) SetupNS(ctx context.Context, cfg *Config, timeout time.Duration) (*current.Result, error) {
+	client.started <- timeout
+	<-client.released
+	return &current.Result{}, nil
+}
+
+func TestBoundedClient(t *testing.T) {
+	inner := &blockingClient{
+		started:  make(chan time.Duration, 2),
+		released: make(chan struct{}),
+	}
+	client := NewBoundedClient(inner, 1)
+	cfg := &Config{ContainerID: "container"}
+
+	done := make(chan error)
+	go func() {
+		_, err := client.SetupNS(context.Background(), cfg, time.Minute)
+		done <- err
+	}()
+	timeout := <-inner.started
+	assert.True(t, timeout <= time.Minute)
+
+	// The only slot is taken, the next setup times out waiting for it
+	_, err := client.SetupNS(context.Background(), cfg, 10*time.Millisecond)
+	assert.Error(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = client.SetupNS(ctx, cfg, time.Minute)
+	assert.Error(t, err)
+
+	inner.released <- struct{}{}
+	require.NoError(t, <-done)
+
+	// The slot is free again
+	go func() {
+		_, err := client.SetupNS(context.Background(), cfg, time.Minute)
+		done <- err
+	}()
+	<-inner.started
+	inner.released <- struct{}{}
+	assert.NoError(t, <-done)
+}
+
+func TestUnboundedClient(t *testing.T) {
+	inner := &blockingClient{}
+	assert.Equal(t, inner, NewBoundedClient(inner, 0))
+}
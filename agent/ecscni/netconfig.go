@@ -14,9 +14,12 @@
 package ecscni
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
 	"net"
 	"strings"
+	"text/template"
 
 	"github.com/aws/amazon-ecs-agent/agent/api/appmesh"
 	"github.com/aws/amazon-ecs-agent/agent/api/eni"
@@ -39,6 +42,7 @@ func NewBridgeNetworkConfig(cfg *Config, includeIPAM bool) (string, *libcni.Netw
 	bridgeConfig := BridgeConfig{
 		Type:       ECSBridgePluginName,
 		BridgeName: bridgeName,
+		MTU:        cfg.MTU,
 	}
 
 	// Create the IPAM config if requested.
@@ -128,18 +132,30 @@ func newIPAMConfig(cfg *Config) (IPAMConfig, error) {
 	return ipamConfig, nil
 }
 
-// NewENINetworkConfig creates a new ENI CNI network configuration.
-func NewENINetworkConfig(eni *eni.ENI, cfg *Config) (string, *libcni.NetworkConfig, error) {
+// ENIIfName returns the name of the interface of the ENI at the index in the
+// task's list of ENIs, in the task's namespace
+func ENIIfName(index int) string {
+	return fmt.Sprintf(eniIfNameFormat, index)
+}
+
+// NewENINetworkConfig creates a new ENI CNI network configuration for the ENI
+// at the index in the task's list of ENIs. Only the primary ENI, the first
+// one, carries the default routes and the route blocking instance metadata.
+func NewENINetworkConfig(eni *eni.ENI, index int, cfg *Config) (string, *libcni.NetworkConfig, error) {
 	ipv4Addr := eni.GetPrimaryIPv4Address()
+	primary := index == 0
 
 	eniConf := ENIConfig{
 		Type:                     ECSENIPluginName,
 		ENIID:                    eni.ID,
 		IPV4Address:              ipv4Addr,
-		IPV6Address:              "",
+		IPV6Address:              eni.GetPrimaryIPv6Address(),
 		MACAddress:               eni.MacAddress,
-		BlockInstanceMetadata:    cfg.BlockInstanceMetadata,
+		BlockInstanceMetadata:    cfg.BlockInstanceMetadata && primary,
 		SubnetGatewayIPV4Address: eni.SubnetGatewayIPV4Address,
+		SubnetGatewayIPV6Address: eni.SubnetGatewayIPV6Address,
+		SkipDefaultRoute:         !primary,
+		MTU:                      cfg.MTU,
 	}
 
 	networkConfig, err := newNetworkConfig(eniConf, ECSENIPluginName, cfg.MinSupportedCNIVersion)
@@ -147,14 +163,27 @@ func NewENINetworkConfig(eni *eni.ENI, cfg *Config) (string, *libcni.NetworkConf
 		return "", nil, errors.Wrap(err, "cni config: failed to create configuration")
 	}
 
-	return defaultENIName, networkConfig, nil
+	return ENIIfName(index), networkConfig, nil
 }
 
-// NewBranchENINetworkConfig creates a new branch ENI CNI network configuration.
-func NewBranchENINetworkConfig(eni *eni.ENI, cfg *Config) (string, *libcni.NetworkConfig, error) {
+// NewBranchENINetworkConfig creates a new branch ENI CNI network configuration
+// for the branch ENI at the index in the task's list of ENIs. Only the primary
+// ENI carries the default route and the route blocking instance metadata.
+func NewBranchENINetworkConfig(eni *eni.ENI, index int, cfg *Config) (string, *libcni.NetworkConfig, error) {
+	if eni.InterfaceVlanProperties == nil {
+		return "", nil, errors.Errorf("NewBranchENINetworkConfig: no vlan properties for branch eni %s", eni.ID)
+	}
+	// The branch eni plugin only configures an ipv4 address
+	if eni.IsIPv6Only() {
+		return "", nil, errors.Errorf("NewBranchENINetworkConfig: ipv6 only branch eni %s isn't supported", eni.ID)
+	}
 	// ENIIPAddress does not have a prefix length while BranchIPAddress expects a prefix length.
 	// SubnetGatewayIPV4Address has a prefix length while BranchGatewayIPAddress does not expect a prefix length.
 	s := strings.Split(eni.SubnetGatewayIPV4Address, "/")
+	if len(s) != 2 {
+		return "", nil, errors.Errorf("NewBranchENINetworkConfig: invalid subnet gateway address %q for branch eni %s",
+			eni.SubnetGatewayIPV4Address, eni.ID)
+	}
 	branchIPv4Address := eni.GetPrimaryIPv4Address() + "/" + s[1]
 	branchGatewayIPAddress := s[0]
 
@@ -166,7 +195,9 @@ func NewBranchENINetworkConfig(eni *eni.ENI, cfg *Config) (string, *libcni.Netwo
 		BranchIPAddress:        branchIPv4Address,
 		BranchGatewayIPAddress: branchGatewayIPAddress,
 		InterfaceType:          vpcCNIPluginInterfaceType,
-		BlockInstanceMetadata:  cfg.BlockInstanceMetadata,
+		BlockInstanceMetadata:  cfg.BlockInstanceMetadata && index == 0,
+		SkipDefaultRoute:       index != 0,
+		MTU:                    cfg.MTU,
 	}
 
 	networkConfig, err := newNetworkConfig(eniConf, ECSBranchENIPluginName, cfg.MinSupportedCNIVersion)
@@ -174,7 +205,7 @@ func NewBranchENINetworkConfig(eni *eni.ENI, cfg *Config) (string, *libcni.Netwo
 		return "", nil, errors.Wrap(err, "NewBranchENINetworkConfig: construct the eni network configuration failed")
 	}
 
-	return defaultENIName, networkConfig, nil
+	return ENIIfName(index), networkConfig, nil
 }
 
 // NewAppMeshConfig creates a new AppMesh CNI network configuration.
@@ -197,3 +228,52 @@ func NewAppMeshConfig(appMesh *appmesh.AppMesh, cfg *Config) (string, *libcni.Ne
 
 	return defaultAppMeshIfName, networkConfig, nil
 }
+
+// NewBandwidthNetworkConfig creates the CNI network configuration of the
+// bandwidth plugin, which shapes the traffic of the ENI of the task. The
+// plugin isn't chained to the eni plugin, so it's invoked without a previous
+// result and finds the ENI by its interface name in the task's namespace.
+func NewBandwidthNetworkConfig(bandwidth *NetworkBandwidth, cfg *Config) (string, *libcni.NetworkConfig, error) {
+	bandwidthConfig := BandwidthConfig{
+		Type:             ECSBandwidthPluginName,
+		NetworkBandwidth: *bandwidth,
+	}
+
+	networkConfig, err := newNetworkConfig(bandwidthConfig, ECSBandwidthPluginName, cfg.MinSupportedCNIVersion)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "NewBandwidthNetworkConfig: construct the bandwidth network configuration failed")
+	}
+
+	return defaultENIName, networkConfig, nil
+}
+
+// NewAdditionalPluginNetworkConfig creates the CNI network configuration of an
+// additional plugin invoked after the plugins of the agent, rendering its
+// config template with the information about the task. The plugin isn't
+// chained to the other plugins, so it's invoked without a previous result.
+func NewAdditionalPluginNetworkConfig(pluginType string, ifName string, configTemplate string,
+	data AdditionalPluginTemplateData, cfg *Config) (string, *libcni.NetworkConfig, error) {
+	tmpl, err := template.New(pluginType).Parse(configTemplate)
+	if err != nil {
+		return "", nil, errors.Wrapf(err, "NewAdditionalPluginNetworkConfig: invalid config template of plugin %s", pluginType)
+	}
+	var rendered bytes.Buffer
+	if err := tmpl.Execute(&rendered, data); err != nil {
+		return "", nil, errors.Wrapf(err, "NewAdditionalPluginNetworkConfig: unable to render config template of plugin %s", pluginType)
+	}
+
+	var pluginConf map[string]interface{}
+	if err := json.Unmarshal(rendered.Bytes(), &pluginConf); err != nil {
+		return "", nil, errors.Wrapf(err, "NewAdditionalPluginNetworkConfig: invalid config of plugin %s", pluginType)
+	}
+	pluginConf["type"] = pluginType
+
+	networkConfig, err := newNetworkConfig(pluginConf, pluginType, cfg.MinSupportedCNIVersion)
+	if err != nil {
+		return "", nil, errors.Wrapf(err, "NewAdditionalPluginNetworkConfig: construct the network configuration of plugin %s failed", pluginType)
+	}
+	if ifName == "" {
+		ifName = defaultENIName
+	}
+	return ifName, networkConfig, nil
+}
@@ -20,6 +20,8 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/aws/amazon-ecs-agent/agent/logger"
@@ -38,8 +40,20 @@ const (
 	currentVPCCNIGitHash      = "82e36ad32dbb94e3cc3de64c481f086417708944"
 	vpcCNIPluginPath          = "/log/vpc-branch-eni.log"
 	vpcCNIPluginInterfaceType = "vlan"
+	// minSupportedECSCNIVersion is the oldest version of the ECS CNI plugins
+	// the agent supports the awsvpc network mode with, the version it has
+	// bundled since 1.21.0
+	minSupportedECSCNIVersion = "2018.10.0"
 )
 
+// minSupportedPluginVersions are the oldest versions of the plugins the agent
+// supports, for the plugins with a version the agent can compare
+var minSupportedPluginVersions = map[string]string{
+	ECSENIPluginName:    minSupportedECSCNIVersion,
+	ECSBridgePluginName: minSupportedECSCNIVersion,
+	ECSIPAMPluginName:   minSupportedECSCNIVersion,
+}
+
 // CNIClient defines the method of setting/cleaning up container namespace
 type CNIClient interface {
 	// Version returns the version of the plugin
@@ -81,6 +95,55 @@ func (client *cniClient) init() {
 	os.Setenv("VPC_CNI_LOG_FILE", vpcCNIPluginPath)
 }
 
+// CheckPluginVersion returns an error if the version of the plugin, as
+// returned by Version, is older than the oldest version the agent supports.
+// Versions that can't be compared, such as the ones of development builds,
+// are only logged, as they're not known to be unsupported
+func CheckPluginVersion(name string, version string) error {
+	minVersion, ok := minSupportedPluginVersions[name]
+	if !ok {
+		return nil
+	}
+	// The version is of the format [@]hash-version
+	pluginVersion := version[strings.Index(version, "-")+1:]
+	older, err := isOlderVersion(pluginVersion, minVersion)
+	if err != nil {
+		seelog.Warnf("Unable to compare the version %s of CNI plugin %s with the oldest supported version %s: %v",
+			pluginVersion, name, minVersion, err)
+		return nil
+	}
+	if older {
+		return errors.Errorf("ecscni: version %s of plugin %s is older than the oldest supported version %s",
+			pluginVersion, name, minVersion)
+	}
+	return nil
+}
+
+// isOlderVersion returns true if the version, made of dot separated numbers,
+// is older than the other version
+func isOlderVersion(version string, other string) (bool, error) {
+	parts := strings.Split(version, ".")
+	otherParts := strings.Split(other, ".")
+	for i := 0; i < len(parts) || i < len(otherParts); i++ {
+		part, otherPart := 0, 0
+		var err error
+		if i < len(parts) {
+			if part, err = strconv.Atoi(parts[i]); err != nil {
+				return false, errors.Errorf("invalid version %q", version)
+			}
+		}
+		if i < len(otherParts) {
+			if otherPart, err = strconv.Atoi(otherParts[i]); err != nil {
+				return false, errors.Errorf("invalid version %q", other)
+			}
+		}
+		if part != otherPart {
+			return part < otherPart, nil
+		}
+	}
+	return false, nil
+}
+
 // SetupNS sets up the network namespace of a task by invoking the given CNI network configurations.
 // It returns the result of the bridge plugin invocation as that result is used to parse the IPv4
 // address allocated to the veth device attached to the task by the task engine.
@@ -48,6 +48,8 @@ const (
 	branchENIVLANID             = "42"
 	branchIPV4Address           = "172.31.21.40/20"
 	branchSubnetGatewayAddress  = "172.31.1.1"
+	eniIPV6Address              = "2600:1f14:abcd::10"
+	eniSubnetGatewayIPV6Address = "2600:1f14:abcd::1/64"
 )
 
 func TestSetupNS(t *testing.T) {
@@ -101,6 +103,7 @@ func eniNetworkConfig(config *Config) *NetworkConfig {
 			MacAddress:               eniMACAddress,
 			SubnetGatewayIPV4Address: eniSubnetGatewayIPV4Address,
 		},
+		0,
 		config,
 	)
 	return &NetworkConfig{CNINetworkConfig: eniNetworkConfig}
@@ -111,6 +114,61 @@ func bridgeConfigWithIPAM(config *Config) *NetworkConfig {
 	return &NetworkConfig{CNINetworkConfig: bridgeNetworkConfig}
 }
 
+// TestSetupNSAdditionalENI tests that only the primary ENI of a task with two
+// ENIs carries the default route and the route blocking instance metadata
+func TestSetupNSAdditionalENI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ecscniClient := NewClient("")
+	libcniClient := mock_libcni.NewMockCNI(ctrl)
+	ecscniClient.(*cniClient).libcni = libcniClient
+
+	expectENI := func(ifName string, primary bool) *gomock.Call {
+		return libcniClient.EXPECT().AddNetwork(gomock.Any(), gomock.Any(), gomock.Any()).Return(&current.Result{}, nil).Do(
+			func(ctx context.Context, net *libcni.NetworkConfig, rt *libcni.RuntimeConf) {
+				assert.Equal(t, ECSENIPluginName, net.Network.Type)
+				assert.Equal(t, ifName, rt.IfName)
+				var eniConfig ENIConfig
+				require.NoError(t, json.Unmarshal(net.Bytes, &eniConfig))
+				assert.Equal(t, primary, eniConfig.BlockInstanceMetadata)
+				assert.Equal(t, !primary, eniConfig.SkipDefaultRoute)
+			})
+	}
+	gomock.InOrder(
+		expectENI("eth0", true),
+		expectENI("eth1", false),
+		libcniClient.EXPECT().AddNetwork(gomock.Any(), gomock.Any(), gomock.Any()).Return(&current.Result{}, nil).Do(
+			func(ctx context.Context, net *libcni.NetworkConfig, rt *libcni.RuntimeConf) {
+				assert.Equal(t, ECSBridgePluginName, net.Network.Type)
+			}),
+	)
+
+	config := &Config{BlockInstanceMetadata: true}
+	for index, macAddress := range []string{eniMACAddress, "02:7b:64:49:b1:41"} {
+		ifName, eniNetworkConfig, err := NewENINetworkConfig(
+			&eni.ENI{
+				ID: eniID,
+				IPV4Addresses: []*eni.ENIIPV4Address{
+					{Address: eniIPV4Address, Primary: true},
+				},
+				MacAddress:               macAddress,
+				SubnetGatewayIPV4Address: eniSubnetGatewayIPV4Address,
+			},
+			index,
+			config)
+		require.NoError(t, err)
+		config.NetworkConfigs = append(config.NetworkConfigs, &NetworkConfig{
+			IfName:           ifName,
+			CNINetworkConfig: eniNetworkConfig,
+		})
+	}
+	config.NetworkConfigs = append(config.NetworkConfigs, bridgeConfigWithIPAM(config))
+
+	_, err := ecscniClient.SetupNS(context.TODO(), config, time.Second)
+	assert.NoError(t, err)
+}
+
 func TestSetupNSTrunk(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -165,6 +223,7 @@ func branchENINetworkConfig(config *Config) *NetworkConfig {
 				VlanID:                   branchENIVLANID,
 			},
 		},
+		0,
 		config)
 	return &NetworkConfig{CNINetworkConfig: eniNetworkConfig}
 }
@@ -409,6 +468,7 @@ func TestConstructENINetworkConfig(t *testing.T) {
 			MacAddress:               eniMACAddress,
 			SubnetGatewayIPV4Address: eniSubnetGatewayIPV4Address,
 		},
+		0,
 		config)
 	require.NoError(t, err, "Failed to construct eni network config")
 	assert.Equal(t, "eth0", eniName)
@@ -425,6 +485,67 @@ func TestConstructENINetworkConfig(t *testing.T) {
 	}, eniConfig)
 }
 
+// TestConstructDualStackENINetworkConfig tests createENINetworkConfig passes the ipv6
+// address and gateway of dual stack enis to the eni plugin
+func TestConstructDualStackENINetworkConfig(t *testing.T) {
+	_, eniNetworkConfig, err := NewENINetworkConfig(
+		&eni.ENI{
+			ID: eniID,
+			IPV4Addresses: []*eni.ENIIPV4Address{
+				{Address: eniIPV4Address, Primary: true},
+			},
+			IPV6Addresses: []*eni.ENIIPV6Address{
+				{Address: eniIPV6Address},
+			},
+			MacAddress:               eniMACAddress,
+			SubnetGatewayIPV4Address: eniSubnetGatewayIPV4Address,
+			SubnetGatewayIPV6Address: eniSubnetGatewayIPV6Address,
+		},
+		0,
+		&Config{})
+	require.NoError(t, err, "Failed to construct eni network config")
+	eniConfig := &ENIConfig{}
+	err = json.Unmarshal(eniNetworkConfig.Bytes, eniConfig)
+	require.NoError(t, err, "unmarshal config from bytes failed")
+	assert.Equal(t, &ENIConfig{
+		Type:                     "ecs-eni",
+		ENIID:                    eniID,
+		IPV4Address:              eniIPV4Address,
+		IPV6Address:              eniIPV6Address,
+		MACAddress:               eniMACAddress,
+		SubnetGatewayIPV4Address: eniSubnetGatewayIPV4Address,
+		SubnetGatewayIPV6Address: eniSubnetGatewayIPV6Address,
+	}, eniConfig)
+}
+
+// TestConstructNetworkConfigsWithMTU tests the MTU of the config is passed to
+// the eni and bridge plugins
+func TestConstructNetworkConfigsWithMTU(t *testing.T) {
+	config := &Config{MTU: 9001}
+
+	_, eniNetworkConfig, err := NewENINetworkConfig(
+		&eni.ENI{
+			ID: eniID,
+			IPV4Addresses: []*eni.ENIIPV4Address{
+				{Address: eniIPV4Address, Primary: true},
+			},
+			MacAddress:               eniMACAddress,
+			SubnetGatewayIPV4Address: eniSubnetGatewayIPV4Address,
+		},
+		0,
+		config)
+	require.NoError(t, err, "Failed to construct eni network config")
+	eniConfig := &ENIConfig{}
+	require.NoError(t, json.Unmarshal(eniNetworkConfig.Bytes, eniConfig))
+	assert.Equal(t, 9001, eniConfig.MTU)
+
+	_, bridgeNetworkConfig, err := NewBridgeNetworkConfig(config, false)
+	require.NoError(t, err, "Failed to construct bridge network config")
+	bridgeConfig := &BridgeConfig{}
+	require.NoError(t, json.Unmarshal(bridgeNetworkConfig.Bytes, bridgeConfig))
+	assert.Equal(t, 9001, bridgeConfig.MTU)
+}
+
 // TestConstructBranchENINetworkConfig tests createBranchENINetworkConfig creates the correct
 // configuration for eni plugin
 func TestConstructBranchENINetworkConfig(t *testing.T) {
@@ -447,6 +568,7 @@ func TestConstructBranchENINetworkConfig(t *testing.T) {
 				VlanID:                   branchENIVLANID,
 			},
 		},
+		0,
 		config)
 	require.NoError(t, err, "Failed to construct eni network config")
 	assert.Equal(t, "eth0", eniName)
@@ -465,6 +587,101 @@ func TestConstructBranchENINetworkConfig(t *testing.T) {
 	}, branchENIConfig)
 }
 
+// TestConstructBranchENINetworkConfigInvalid tests createBranchENINetworkConfig fails
+// for branch enis without vlan properties or a subnet gateway prefix length
+func TestConstructBranchENINetworkConfigInvalid(t *testing.T) {
+	_, _, err := NewBranchENINetworkConfig(
+		&eni.ENI{
+			ID:                       eniID,
+			MacAddress:               eniMACAddress,
+			SubnetGatewayIPV4Address: eniSubnetGatewayIPV4Address,
+		},
+		0,
+		&Config{})
+	assert.Error(t, err)
+
+	_, _, err = NewBranchENINetworkConfig(
+		&eni.ENI{
+			ID:                       eniID,
+			MacAddress:               eniMACAddress,
+			SubnetGatewayIPV4Address: branchSubnetGatewayAddress,
+			InterfaceVlanProperties: &eni.InterfaceVlanProperties{
+				TrunkInterfaceMacAddress: trunkENIMACAddress,
+				VlanID:                   branchENIVLANID,
+			},
+		},
+		0,
+		&Config{})
+	assert.Error(t, err)
+
+	// The branch eni plugin doesn't configure ipv6 addresses
+	_, _, err = NewBranchENINetworkConfig(
+		&eni.ENI{
+			ID:                       eniID,
+			IPV6Addresses:            []*eni.ENIIPV6Address{{Address: "2600:1f14:abcd::10"}},
+			MacAddress:               eniMACAddress,
+			SubnetGatewayIPV4Address: eniSubnetGatewayIPV4Address,
+			InterfaceVlanProperties: &eni.InterfaceVlanProperties{
+				TrunkInterfaceMacAddress: trunkENIMACAddress,
+				VlanID:                   branchENIVLANID,
+			},
+		},
+		0,
+		&Config{})
+	assert.Error(t, err)
+}
+
+// TestConstructBandwidthNetworkConfig tests NewBandwidthNetworkConfig creates
+// the correct configuration for the bandwidth plugin
+func TestConstructBandwidthNetworkConfig(t *testing.T) {
+	bandwidth := &NetworkBandwidth{
+		IngressRate:  1000000,
+		IngressBurst: 100000,
+	}
+	ifName, networkConfig, err := NewBandwidthNetworkConfig(bandwidth, &Config{MinSupportedCNIVersion: "0.3.0"})
+	require.NoError(t, err)
+	assert.Equal(t, defaultENIName, ifName)
+	assert.Equal(t, ECSBandwidthPluginName, networkConfig.Network.Type)
+	assert.JSONEq(t, `{"type": "ecs-bandwidth", "ingressRate": 1000000, "ingressBurst": 100000}`, string(networkConfig.Bytes))
+}
+
+// TestNetworkBandwidthValidate tests the limits the bandwidth plugin can't
+// shape the traffic with are rejected
+func TestNetworkBandwidthValidate(t *testing.T) {
+	assert.NoError(t, (&NetworkBandwidth{IngressRate: 1000000, IngressBurst: 100000}).Validate())
+	assert.NoError(t, (&NetworkBandwidth{EgressRate: 1000000, EgressBurst: 100000}).Validate())
+	assert.Error(t, (&NetworkBandwidth{}).Validate())
+	assert.Error(t, (&NetworkBandwidth{IngressRate: 1000000}).Validate())
+	assert.Error(t, (&NetworkBandwidth{EgressBurst: 100000}).Validate())
+	assert.Error(t, (&NetworkBandwidth{EgressRate: -1, EgressBurst: 100000}).Validate())
+}
+
+// TestConstructAdditionalPluginNetworkConfig tests NewAdditionalPluginNetworkConfig
+// renders the config template of the plugin with the task information
+func TestConstructAdditionalPluginNetworkConfig(t *testing.T) {
+	ifName, networkConfig, err := NewAdditionalPluginNetworkConfig("mirror", "",
+		`{"source": "{{.ENIIPv4Address}}", "task": "{{.TaskARN}}", "type": "other"}`,
+		AdditionalPluginTemplateData{
+			TaskARN:        "task-arn",
+			ENIIPv4Address: eniIPV4Address,
+		},
+		&Config{MinSupportedCNIVersion: "0.3.0"})
+	require.NoError(t, err)
+	assert.Equal(t, "eth0", ifName)
+	assert.Equal(t, "mirror", networkConfig.Network.Type)
+	assert.JSONEq(t, `{"source": "172.31.21.40", "task": "task-arn", "type": "mirror"}`, string(networkConfig.Bytes))
+
+	ifName, _, err = NewAdditionalPluginNetworkConfig("snat", "ecs-eth0", `{}`, AdditionalPluginTemplateData{}, &Config{})
+	require.NoError(t, err)
+	assert.Equal(t, "ecs-eth0", ifName)
+
+	_, _, err = NewAdditionalPluginNetworkConfig("mirror", "", `{"source": "{{.Unknown}}"}`, AdditionalPluginTemplateData{}, &Config{})
+	assert.Error(t, err)
+	_, _, err = NewAdditionalPluginNetworkConfig("mirror", "", `{"source": {{.TaskARN}}}`,
+		AdditionalPluginTemplateData{TaskARN: "task-arn"}, &Config{})
+	assert.Error(t, err)
+}
+
 // TestConstructBridgeNetworkConfigWithoutIPAM tests createBridgeNetworkConfigWithoutIPAM creates the right configuration for bridge plugin
 func TestConstructBridgeNetworkConfigWithoutIPAM(t *testing.T) {
 	config := &Config{
@@ -605,6 +822,18 @@ func TestCNIPluginVersion(t *testing.T) {
 	}
 }
 
+func TestCheckPluginVersion(t *testing.T) {
+	assert.NoError(t, CheckPluginVersion(ECSENIPluginName, "226db36-2019.10.0"))
+	assert.NoError(t, CheckPluginVersion(ECSBridgePluginName, "@226db36-2018.10.0"))
+	assert.NoError(t, CheckPluginVersion(ECSIPAMPluginName, "226db36-2018.10.1"))
+	assert.Error(t, CheckPluginVersion(ECSENIPluginName, "226db36-2018.08.0"))
+	assert.Error(t, CheckPluginVersion(ECSENIPluginName, "226db36-2017.10.1"))
+	// Versions that can't be compared aren't rejected
+	assert.NoError(t, CheckPluginVersion(ECSENIPluginName, "226db36-unknown"))
+	// The versions of the plugins without a minimum version aren't compared
+	assert.NoError(t, CheckPluginVersion(ECSAppMeshPluginName, "1e3ba1a-v1.0"))
+}
+
 // Asserts that CNI plugin version matches the expected version
 func TestCNIPluginVersionNumber(t *testing.T) {
 	versionStr := getCNIVersionString(t)
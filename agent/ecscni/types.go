@@ -16,6 +16,7 @@ package ecscni
 import (
 	"github.com/containernetworking/cni/libcni"
 	cnitypes "github.com/containernetworking/cni/pkg/types"
+	"github.com/pkg/errors"
 )
 
 const (
@@ -28,6 +29,9 @@ const (
 	defaultVethName = "ecs-eth0"
 	// defaultENIName is the name of eni interface name in the container namespace
 	defaultENIName = "eth0"
+	// eniIfNameFormat is the format of the names of the task's ENIs in the
+	// container namespace, by their index in the task's list of ENIs
+	eniIfNameFormat = "eth%d"
 	// defaultBridgeName is the default name of bridge created for container to
 	// communicate with ecs-agent
 	defaultBridgeName = "ecs-bridge"
@@ -49,6 +53,10 @@ const (
 	ECSAppMeshPluginName = "aws-appmesh"
 	// ECSBranchENIPluginName is the binary of the branch-eni plugin
 	ECSBranchENIPluginName = "vpc-branch-eni"
+	// ECSBandwidthPluginName is the binary of the bandwidth plugin. It isn't
+	// shipped with the agent, the README describes the plugin the agent
+	// expects
+	ECSBandwidthPluginName = "ecs-bandwidth"
 	// TaskIAMRoleEndpoint is the endpoint of ecs-agent exposes credentials for
 	// task IAM role
 	TaskIAMRoleEndpoint = "169.254.170.2/32"
@@ -56,6 +64,15 @@ const (
 	// present in the output of the '--capabilities' command of a CNI plugin
 	// indicates that the plugin can support the ECS "awsvpc" network mode
 	CapabilityAWSVPCNetworkingMode = "awsvpc-network-mode"
+	// CapabilityTaskBandwidth is the capability string, which when present in
+	// the output of the '--capabilities' command of the bandwidth plugin
+	// indicates that the plugin can shape the traffic of the task interface
+	CapabilityTaskBandwidth = "task-bandwidth"
+	// CapabilityAdditionalENIs is the capability string, which when present in
+	// the output of the '--capabilities' command of the eni plugins indicates
+	// that they can set up an ENI of a task other than its primary ENI,
+	// without routing the default traffic of the task through it
+	CapabilityAdditionalENIs = "awsvpc-additional-enis"
 )
 
 //IPAMNetworkConfig is the config format accepted by the plugin
@@ -129,6 +146,14 @@ type ENIConfig struct {
 	BlockInstanceMetadata bool `json:"block-instance-metadata"`
 	// SubnetGatewayIPV4Address specifies the IPv4 address of the subnet gateway for the ENI
 	SubnetGatewayIPV4Address string `json:"subnetgateway-ipv4-address"`
+	// SubnetGatewayIPV6Address specifies the IPv6 address of the subnet gateway for the ENI,
+	// the default IPv6 route of the container goes through it
+	SubnetGatewayIPV6Address string `json:"subnetgateway-ipv6-address,omitempty"`
+	// SkipDefaultRoute specifies that the default routes of the container
+	// don't go through the ENI, as it isn't the primary ENI of the task
+	SkipDefaultRoute bool `json:"skip-default-route,omitempty"`
+	// MTU sets MTU of the eni interface
+	MTU int `json:"mtu,omitempty"`
 }
 
 // AppMeshConfig contains all the information needed to invoke the app mesh plugin
@@ -176,6 +201,78 @@ type BranchENIConfig struct {
 	InterfaceType string `json:"interfaceType,omitempty"`
 	// BlockInstanceMetdata specifies if InstanceMetadata endpoint should be blocked.
 	BlockInstanceMetadata bool `json:"blockInstanceMetadata"`
+	// SkipDefaultRoute specifies that the default route of the container
+	// doesn't go through the branch ENI, as it isn't the primary ENI of the task
+	SkipDefaultRoute bool `json:"skipDefaultRoute,omitempty"`
+	// MTU sets MTU of the branch eni interface
+	MTU int `json:"mtu,omitempty"`
+}
+
+// NetworkBandwidth is the limit of the bandwidth of the traffic of a task
+type NetworkBandwidth struct {
+	// IngressRate is the rate of the traffic into the task, in bits per second
+	IngressRate int64 `json:"ingressRate,omitempty"`
+	// IngressBurst is the amount of traffic into the task above the rate
+	// allowed in a burst, in bits
+	IngressBurst int64 `json:"ingressBurst,omitempty"`
+	// EgressRate is the rate of the traffic out of the task, in bits per second
+	EgressRate int64 `json:"egressRate,omitempty"`
+	// EgressBurst is the amount of traffic out of the task above the rate
+	// allowed in a burst, in bits
+	EgressBurst int64 `json:"egressBurst,omitempty"`
+}
+
+// Validate returns an error if the bandwidth plugin can't shape the traffic
+// with the limit: the rates and bursts can't be negative, a direction is
+// shaped only with both a rate and a burst, and at least one is
+func (bandwidth *NetworkBandwidth) Validate() error {
+	if bandwidth.IngressRate < 0 || bandwidth.IngressBurst < 0 ||
+		bandwidth.EgressRate < 0 || bandwidth.EgressBurst < 0 {
+		return errors.Errorf("invalid network bandwidth: negative rate or burst: %+v", *bandwidth)
+	}
+	if (bandwidth.IngressRate == 0) != (bandwidth.IngressBurst == 0) {
+		return errors.Errorf("invalid network bandwidth: ingress needs both a rate and a burst: %+v", *bandwidth)
+	}
+	if (bandwidth.EgressRate == 0) != (bandwidth.EgressBurst == 0) {
+		return errors.Errorf("invalid network bandwidth: egress needs both a rate and a burst: %+v", *bandwidth)
+	}
+	if bandwidth.IngressRate == 0 && bandwidth.EgressRate == 0 {
+		return errors.New("invalid network bandwidth: no ingress or egress limit")
+	}
+	return nil
+}
+
+// BandwidthConfig contains all the information needed to invoke the bandwidth
+// plugin
+type BandwidthConfig struct {
+	// Type is the cni plugin name
+	Type string `json:"type,omitempty"`
+	// CNIVersion is the cni spec version to use
+	CNIVersion string `json:"cniVersion,omitempty"`
+	NetworkBandwidth
+}
+
+// AdditionalPluginTemplateData is the information about the task that the
+// config templates of the additional plugins invoked after the ones of the
+// agent can refer to
+type AdditionalPluginTemplateData struct {
+	// TaskARN is the ARN of the task
+	TaskARN string
+	// TaskFamily is the task definition family of the task
+	TaskFamily string
+	// ContainerID is the id of the pause container of the task
+	ContainerID string
+	// ENIID is the id of the ENI of the task
+	ENIID string
+	// ENIMACAddress is the mac address of the ENI of the task
+	ENIMACAddress string
+	// ENIIPv4Address is the primary ipv4 address of the ENI of the task
+	ENIIPv4Address string
+	// ENIIPv6Address is the primary ipv6 address of the ENI of the task
+	ENIIPv6Address string
+	// SubnetGatewayIPv4Address is the subnet gateway of the ENI of the task,
+	// with the prefix length of the subnet
+	SubnetGatewayIPv4Address string
 }
 
 // Config contains all the information to set up the container namespace using
@@ -199,6 +296,9 @@ type Config struct {
 	BlockInstanceMetadata bool
 	// AdditionalLocalRoutes specifies additional routes to be added to the task namespace
 	AdditionalLocalRoutes []cnitypes.IPNet
+	// MTU is the MTU of the interfaces of the task, 0 to keep the MTU the
+	// plugins set by default
+	MTU int
 	// NetworkConfigs is the list of CNI network configurations to be invoked
 	NetworkConfigs []*NetworkConfig
 }
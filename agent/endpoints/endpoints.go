@@ -0,0 +1,140 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package endpoints resolves the FIPS and dual-stack variants of the endpoints
+// of the services the agent talks to
+package endpoints
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/pkg/errors"
+)
+
+const (
+	// ECS is the endpoint prefix of ECS
+	ECS = "ecs"
+	// ECR is the endpoint prefix of the ECR API
+	ECR = "ecr"
+
+	fipsSuffix = "-fips"
+)
+
+// partition is the part of the DNS names of endpoints that depends on the
+// partition of the region
+type partition struct {
+	regionPrefix       string
+	dnsSuffix          string
+	dualStackDNSSuffix string
+}
+
+var (
+	defaultPartition = partition{
+		dnsSuffix:          "amazonaws.com",
+		dualStackDNSSuffix: "api.aws",
+	}
+	partitions = []partition{
+		{
+			regionPrefix:       "cn-",
+			dnsSuffix:          "amazonaws.com.cn",
+			dualStackDNSSuffix: "api.amazonwebservices.com.cn",
+		},
+	}
+)
+
+func partitionOf(region string) partition {
+	for _, p := range partitions {
+		if strings.HasPrefix(region, p.regionPrefix) {
+			return p
+		}
+	}
+	return defaultPartition
+}
+
+// Variant specifies the variant of the endpoints to use
+type Variant struct {
+	// FIPS specifies whether to use FIPS 140-2 validated endpoints
+	FIPS bool
+	// DualStack specifies whether to use endpoints that are reachable over
+	// both IPv4 and IPv6
+	DualStack bool
+}
+
+// NewVariant returns the variant of the endpoints enabled in the config
+func NewVariant(cfg *config.Config) Variant {
+	return Variant{
+		FIPS:      cfg.FIPSEndpointsEnabled,
+		DualStack: cfg.DualStackEndpointsEnabled,
+	}
+}
+
+// Enabled returns true if the variant differs from the standard endpoints
+func (variant Variant) Enabled() bool {
+	return variant.FIPS || variant.DualStack
+}
+
+// ServiceEndpoint returns the URL of the variant of the endpoint of the service
+// in the region. An empty string is returned if the variant isn't enabled, in
+// which case the standard endpoint resolved by the SDK should be used
+func (variant Variant) ServiceEndpoint(service, region string) string {
+	if !variant.Enabled() || region == "" {
+		return ""
+	}
+	return "https://" + variant.host(service, region)
+}
+
+// RewriteEndpoint rewrites the URL of a standard endpoint in the region, such as
+// the ACS and TCS endpoints discovered from ECS, to the variant. Endpoints that
+// aren't standard endpoints of the region are returned unchanged
+func (variant Variant) RewriteEndpoint(endpoint, region string) (string, error) {
+	if !variant.Enabled() || region == "" {
+		return endpoint, nil
+	}
+	endpointURL, err := url.Parse(endpoint)
+	if err != nil {
+		return "", errors.Wrapf(err, "unable to parse endpoint %s", endpoint)
+	}
+
+	host := endpointURL.Hostname()
+	standardSuffix := fmt.Sprintf(".%s.%s", region, partitionOf(region).dnsSuffix)
+	if !strings.HasSuffix(host, standardSuffix) {
+		return endpoint, nil
+	}
+	prefix := strings.TrimSuffix(host, standardSuffix)
+	if prefix == "" || strings.Contains(prefix, ".") {
+		return endpoint, nil
+	}
+
+	host = variant.host(strings.TrimSuffix(prefix, fipsSuffix), region)
+	if port := endpointURL.Port(); port != "" {
+		host = net.JoinHostPort(host, port)
+	}
+	endpointURL.Host = host
+	return endpointURL.String(), nil
+}
+
+func (variant Variant) host(prefix, region string) string {
+	if variant.FIPS {
+		prefix += fipsSuffix
+	}
+	p := partitionOf(region)
+	dnsSuffix := p.dnsSuffix
+	if variant.DualStack {
+		dnsSuffix = p.dualStackDNSSuffix
+	}
+	return fmt.Sprintf("%s.%s.%s", prefix, region, dnsSuffix)
+}
@@ -0,0 +1,82 @@
+// +build unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package endpoints
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServiceEndpoint(t *testing.T) {
+	testCases := []struct {
+		name     string
+		variant  Variant
+		service  string
+		region   string
+		expected string
+	}{
+		{"standard", Variant{}, ECS, "us-west-2", ""},
+		{"fips", Variant{FIPS: true}, ECS, "us-gov-west-1", "https://ecs-fips.us-gov-west-1.amazonaws.com"},
+		{"dual-stack", Variant{DualStack: true}, ECR, "us-west-2", "https://ecr.us-west-2.api.aws"},
+		{"fips dual-stack", Variant{FIPS: true, DualStack: true}, ECR, "us-east-1", "https://ecr-fips.us-east-1.api.aws"},
+		{"china dual-stack", Variant{DualStack: true}, ECS, "cn-north-1", "https://ecs.cn-north-1.api.amazonwebservices.com.cn"},
+		{"no region", Variant{FIPS: true}, ECS, "", ""},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.variant.ServiceEndpoint(tc.service, tc.region))
+		})
+	}
+}
+
+func TestRewriteEndpoint(t *testing.T) {
+	testCases := []struct {
+		name     string
+		variant  Variant
+		endpoint string
+		region   string
+		expected string
+	}{
+		{"standard", Variant{}, "https://ecs-a-1.us-west-2.amazonaws.com/", "us-west-2",
+			"https://ecs-a-1.us-west-2.amazonaws.com/"},
+		{"fips", Variant{FIPS: true}, "https://ecs-a-1.us-gov-west-1.amazonaws.com/", "us-gov-west-1",
+			"https://ecs-a-1-fips.us-gov-west-1.amazonaws.com/"},
+		{"already fips", Variant{FIPS: true}, "https://ecs-t-1-fips.us-gov-west-1.amazonaws.com/", "us-gov-west-1",
+			"https://ecs-t-1-fips.us-gov-west-1.amazonaws.com/"},
+		{"dual-stack", Variant{DualStack: true}, "https://ecs-t-1.us-west-2.amazonaws.com:443/ws", "us-west-2",
+			"https://ecs-t-1.us-west-2.api.aws:443/ws"},
+		{"china", Variant{DualStack: true}, "https://ecs-a-1.cn-north-1.amazonaws.com.cn/", "cn-north-1",
+			"https://ecs-a-1.cn-north-1.api.amazonwebservices.com.cn/"},
+		{"other region", Variant{FIPS: true}, "https://ecs-a-1.us-east-1.amazonaws.com/", "us-west-2",
+			"https://ecs-a-1.us-east-1.amazonaws.com/"},
+		{"custom", Variant{FIPS: true}, "https://acs.example.com/", "us-west-2",
+			"https://acs.example.com/"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			endpoint, err := tc.variant.RewriteEndpoint(tc.endpoint, tc.region)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, endpoint)
+		})
+	}
+}
+
+func TestRewriteEndpointInvalid(t *testing.T) {
+	_, err := Variant{FIPS: true}.RewriteEndpoint("://ecs-a-1", "us-west-2")
+	assert.Error(t, err)
+}
@@ -0,0 +1,148 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"sort"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	"github.com/aws/amazon-ecs-agent/agent/api/ecsclient"
+)
+
+// hostCPUAndMemory returns the CPU and memory of the host as registered with
+// ECS
+var hostCPUAndMemory = ecsclient.GetCPUAndMemory
+
+// HostCapacity is an amount of each of the resources of the container
+// instance, in the units ECS accounts them in
+type HostCapacity struct {
+	// CPU is in CPU units, 1024 for each vCPU
+	CPU int64 `json:"CPU"`
+	// Memory is in MiB
+	Memory int64 `json:"Memory"`
+	// UnavailablePortsTCP and UnavailablePortsUDP are the host ports tasks
+	// can't bind: the reserved ports of the instance, the ports bound by
+	// tasks, and both of those for the remaining capacity
+	UnavailablePortsTCP []uint16 `json:"UnavailablePortsTCP"`
+	UnavailablePortsUDP []uint16 `json:"UnavailablePortsUDP"`
+	// GPUIDs are the ids of the GPUs
+	GPUIDs []string `json:"GPUIDs"`
+}
+
+// CapacityReport is the capacity the instance registered with, the share of
+// it allocated to the tasks on the instance, and what's left of it
+type CapacityReport struct {
+	Registered HostCapacity `json:"Registered"`
+	Allocated  HostCapacity `json:"Allocated"`
+	Remaining  HostCapacity `json:"Remaining"`
+}
+
+// Capacity returns the capacity of the instance and the share of it
+// allocated to the tasks that haven't stopped yet. These are the resources
+// ECS accounts as used on the instance
+func (engine *DockerTaskEngine) Capacity() CapacityReport {
+	registered := engine.registeredCapacity()
+	allocated := HostCapacity{}
+	allocatedTCP := make(map[uint16]struct{})
+	allocatedUDP := make(map[uint16]struct{})
+	allocatedGPUs := make(map[string]struct{})
+	for _, task := range engine.state.AllTasks() {
+		// The resources of the static tasks are reserved, they aren't
+		// registered with ECS
+		if task.GetKnownStatus().Terminal() || task.IsStatic() {
+			continue
+		}
+		cpu, memory := task.CPUAndMemory()
+		allocated.CPU += cpu
+		allocated.Memory += memory
+		for _, container := range task.Containers {
+			for _, binding := range containerHostPorts(container) {
+				if binding.Protocol == apicontainer.TransportProtocolUDP {
+					allocatedUDP[binding.HostPort] = struct{}{}
+				} else {
+					allocatedTCP[binding.HostPort] = struct{}{}
+				}
+			}
+			for _, gpuID := range container.GPUIDs {
+				allocatedGPUs[gpuID] = struct{}{}
+			}
+		}
+	}
+	allocated.UnavailablePortsTCP = sortedPorts(allocatedTCP)
+	allocated.UnavailablePortsUDP = sortedPorts(allocatedUDP)
+	allocated.GPUIDs = make([]string, 0, len(allocatedGPUs))
+	for gpuID := range allocatedGPUs {
+		allocated.GPUIDs = append(allocated.GPUIDs, gpuID)
+	}
+	sort.Strings(allocated.GPUIDs)
+
+	remaining := HostCapacity{
+		CPU:    registered.CPU - allocated.CPU,
+		Memory: registered.Memory - allocated.Memory,
+		GPUIDs: []string{},
+	}
+	for _, port := range registered.UnavailablePortsTCP {
+		allocatedTCP[port] = struct{}{}
+	}
+	for _, port := range registered.UnavailablePortsUDP {
+		allocatedUDP[port] = struct{}{}
+	}
+	remaining.UnavailablePortsTCP = sortedPorts(allocatedTCP)
+	remaining.UnavailablePortsUDP = sortedPorts(allocatedUDP)
+	for _, gpuID := range registered.GPUIDs {
+		if _, ok := allocatedGPUs[gpuID]; !ok {
+			remaining.GPUIDs = append(remaining.GPUIDs, gpuID)
+		}
+	}
+
+	return CapacityReport{
+		Registered: registered,
+		Allocated:  allocated,
+		Remaining:  remaining,
+	}
+}
+
+// registeredCapacity returns the resources the instance registers with ECS
+func (engine *DockerTaskEngine) registeredCapacity() HostCapacity {
+	cpu, memory := hostCPUAndMemory()
+	return HostCapacity{
+		CPU:                 cpu - engine.cfg.ReservedCPU,
+		Memory:              memory - int64(engine.cfg.ReservedMemory),
+		UnavailablePortsTCP: append([]uint16{}, engine.cfg.ReservedPorts...),
+		UnavailablePortsUDP: append([]uint16{}, engine.cfg.ReservedPortsUDP...),
+		GPUIDs:              append([]string{}, engine.registeredGPUIDs()...),
+	}
+}
+
+// containerHostPorts returns the host ports taken by the container: the ports
+// docker bound it to, and the static host ports of its definition in case it
+// hasn't been created yet
+func containerHostPorts(container *apicontainer.Container) []apicontainer.PortBinding {
+	var bindings []apicontainer.PortBinding
+	for _, binding := range container.Ports {
+		if binding.HostPort != 0 {
+			bindings = append(bindings, binding)
+		}
+	}
+	return append(bindings, container.GetKnownPortBindings()...)
+}
+
+func sortedPorts(ports map[uint16]struct{}) []uint16 {
+	sorted := make([]uint16, 0, len(ports))
+	for port := range ports {
+		sorted = append(sorted, port)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted
+}
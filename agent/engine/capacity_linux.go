@@ -0,0 +1,24 @@
+// +build linux
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+// registeredGPUIDs returns the ids of the GPUs the instance registers with
+func (engine *DockerTaskEngine) registeredGPUIDs() []string {
+	if !engine.cfg.GPUSupportEnabled || engine.resourceFields == nil || engine.resourceFields.NvidiaGPUManager == nil {
+		return nil
+	}
+	return engine.resourceFields.NvidiaGPUManager.GetGPUIDsUnsafe()
+}
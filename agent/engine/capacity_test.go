@@ -0,0 +1,152 @@
+// +build unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"testing"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	apitaskstatus "github.com/aws/amazon-ecs-agent/agent/api/task/status"
+	mock_dockerstate "github.com/aws/amazon-ecs-agent/agent/engine/dockerstate/mocks"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCapacity(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	hostCPUAndMemoryFunc := hostCPUAndMemory
+	defer func() {
+		hostCPUAndMemory = hostCPUAndMemoryFunc
+	}()
+	hostCPUAndMemory = func() (int64, int64) {
+		return 4096, 8192
+	}
+
+	cfg := getTestConfig()
+	cfg.ReservedCPU = 256
+	cfg.ReservedMemory = 192
+	cfg.ReservedPorts = []uint16{22, 2375, 8125}
+	cfg.ReservedPortsUDP = []uint16{}
+	mockState := mock_dockerstate.NewMockTaskEngineState(ctrl)
+	taskEngine := &DockerTaskEngine{
+		cfg:   &cfg,
+		state: mockState,
+	}
+
+	web := &apitask.Task{
+		Arn:    "web",
+		CPU:    0.5,
+		Memory: 512,
+		Containers: []*apicontainer.Container{
+			{
+				Name:   "web",
+				CPU:    256,
+				Memory: 256,
+				Ports: []apicontainer.PortBinding{
+					{ContainerPort: 80, HostPort: 80},
+					{ContainerPort: 8080},
+				},
+				KnownPortBindingsUnsafe: []apicontainer.PortBinding{
+					{ContainerPort: 80, HostPort: 80},
+					{ContainerPort: 8080, HostPort: 32768},
+				},
+			},
+		},
+	}
+	web.SetKnownStatus(apitaskstatus.TaskRunning)
+	dns := &apitask.Task{
+		Arn: "dns",
+		Containers: []*apicontainer.Container{
+			{
+				Name:   "dns",
+				CPU:    128,
+				Memory: 64,
+				Ports: []apicontainer.PortBinding{
+					{ContainerPort: 53, HostPort: 53, Protocol: apicontainer.TransportProtocolUDP},
+				},
+			},
+			{
+				Name:   "train",
+				CPU:    512,
+				Memory: 1024,
+				GPUIDs: []string{"gpu-0"},
+			},
+			{
+				// The memory reservation is accounted for containers
+				// without a hard memory limit
+				Name: "cache",
+				DockerConfig: apicontainer.DockerConfig{
+					HostConfig: aws.String(`{"MemoryReservation": 134217728}`),
+				},
+			},
+		},
+	}
+	stopped := &apitask.Task{
+		Arn:    "stopped",
+		CPU:    1,
+		Memory: 1024,
+		Containers: []*apicontainer.Container{
+			{
+				Name:   "stopped",
+				Ports:  []apicontainer.PortBinding{{ContainerPort: 443, HostPort: 443}},
+				GPUIDs: []string{"gpu-1"},
+			},
+		},
+	}
+	stopped.SetKnownStatus(apitaskstatus.TaskStopped)
+	// The resources of the static tasks are reserved
+	static := &apitask.Task{
+		Arn:        "static",
+		StaticName: "statsd",
+		Containers: []*apicontainer.Container{
+			{
+				Name:   "statsd",
+				CPU:    256,
+				Memory: 128,
+				Ports:  []apicontainer.PortBinding{{ContainerPort: 8125, HostPort: 8125}},
+			},
+		},
+	}
+	static.SetKnownStatus(apitaskstatus.TaskRunning)
+	mockState.EXPECT().AllTasks().Return([]*apitask.Task{web, dns, stopped, static})
+
+	report := taskEngine.Capacity()
+	assert.Equal(t, HostCapacity{
+		CPU:                 3840,
+		Memory:              8000,
+		UnavailablePortsTCP: []uint16{22, 2375, 8125},
+		UnavailablePortsUDP: []uint16{},
+		GPUIDs:              []string{},
+	}, report.Registered)
+	assert.Equal(t, HostCapacity{
+		CPU:                 512 + 128 + 512,
+		Memory:              512 + 64 + 1024 + 128,
+		UnavailablePortsTCP: []uint16{80, 32768},
+		UnavailablePortsUDP: []uint16{53},
+		GPUIDs:              []string{"gpu-0"},
+	}, report.Allocated)
+	assert.Equal(t, HostCapacity{
+		CPU:                 3840 - 1152,
+		Memory:              8000 - 1728,
+		UnavailablePortsTCP: []uint16{22, 80, 2375, 8125, 32768},
+		UnavailablePortsUDP: []uint16{53},
+		GPUIDs:              []string{},
+	}, report.Remaining)
+}
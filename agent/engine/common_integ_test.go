@@ -146,7 +146,7 @@ func setup(cfg *config.Config, state dockerstate.TaskEngineState, t *testing.T)
 	}
 	imageManager := NewImageManager(cfg, dockerClient, state)
 	imageManager.SetSaver(statemanager.NewNoopStateManager())
-	metadataManager := containermetadata.NewManager(dockerClient, cfg)
+	metadataManager := containermetadata.NewManager(context.TODO(), dockerClient, cfg)
 
 	taskEngine := NewDockerTaskEngine(cfg, dockerClient, credentialsManager,
 		eventstream.NewEventStream("ENGINEINTEGTEST", context.Background()), imageManager, state, metadataManager, nil)
@@ -168,8 +168,8 @@ func validateContainerRunWorkflow(t *testing.T,
 	dockerConfig.Labels["com.amazonaws.ecs.task-definition-family"] = task.Family
 	dockerConfig.Labels["com.amazonaws.ecs.task-definition-version"] = task.Version
 	dockerConfig.Labels["com.amazonaws.ecs.cluster"] = ""
-	client.EXPECT().CreateContainer(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Do(
-		func(ctx interface{}, config *dockercontainer.Config, y interface{}, containerName string, z time.Duration) {
+	client.EXPECT().CreateContainer(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Do(
+		func(ctx interface{}, config *dockercontainer.Config, y interface{}, networkingConfig interface{}, containerName string, z time.Duration) {
 			checkDockerConfigsExceptEnv(t, dockerConfig, config)
 			checkDockerConfigsEnv(t, dockerConfig, config)
 			// sleep5 task contains only one container. Just assign
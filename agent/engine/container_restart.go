@@ -0,0 +1,192 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"fmt"
+	"time"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apicontainerstatus "github.com/aws/amazon-ecs-agent/agent/api/container/status"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	apitaskstatus "github.com/aws/amazon-ecs-agent/agent/api/task/status"
+	"github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi"
+
+	"github.com/cihub/seelog"
+	"github.com/pkg/errors"
+)
+
+const (
+	// maxContainerRestartBackoff caps the exponential delay between
+	// consecutive restarts of a container
+	maxContainerRestartBackoff = 5 * time.Minute
+	// taskCrashLoopReason is the stopped reason of tasks whose containers
+	// restart more often than the task restart limit allows
+	taskCrashLoopReason = "TaskCrashLoopError: containers restarted %d times within %s"
+)
+
+// handleContainerRestart decides whether a container that exited on its own
+// should be restarted under its restart policy rather than being marked as
+// stopped. It returns true if a restart has been scheduled, in which case the
+// container's known status is left as is.
+func (mtask *managedTask) handleContainerRestart(container *apicontainer.Container,
+	event dockerapi.DockerContainerChangeEvent) bool {
+	if !mtask.shouldRestartContainer(container, event) {
+		return false
+	}
+	policy := container.GetRestartPolicy()
+	if policy.ResetWindowSeconds > 0 {
+		runtime := event.FinishedAt.Sub(event.StartedAt)
+		if !event.StartedAt.IsZero() && runtime >= time.Duration(policy.ResetWindowSeconds)*time.Second {
+			container.ResetRestartCount()
+		}
+	}
+	if policy.MaximumAttempts > 0 && container.GetRestartCount() >= policy.MaximumAttempts {
+		seelog.Warnf("Managed task [%s]: container [%s] exhausted its %d restart attempts; leaving it stopped",
+			mtask.Arn, container.Name, policy.MaximumAttempts)
+		return false
+	}
+
+	// Restarts of all the containers of the task count towards the task's
+	// restart limit, so that a crash looping container stops the task
+	taskRestarts := mtask.RecordContainerRestart(mtask.time().Now(), mtask.cfg.TaskRestartLimitWindow)
+	if taskRestarts > mtask.cfg.TaskRestartLimit {
+		seelog.Warnf("Managed task [%s]: containers restarted %d times within %s; stopping the task",
+			mtask.Arn, taskRestarts-1, mtask.cfg.TaskRestartLimitWindow.String())
+		mtask.SetTerminalReason(fmt.Sprintf(taskCrashLoopReason, taskRestarts-1,
+			mtask.cfg.TaskRestartLimitWindow.String()))
+		mtask.SetDesiredStatus(apitaskstatus.TaskStopped)
+		mtask.engine.saver.Save()
+		return false
+	}
+
+	updateContainerMetadata(&event.DockerContainerMetadata, container, mtask.Task)
+	attempt := container.IncrementRestartCount()
+	mtask.engine.saver.Save()
+
+	// The backoff grows with the recent restarts of the whole task, so that
+	// containers restarting in turn don't keep each other's backoff short
+	backoff := containerRestartBackoff(policy, attempt)
+	if taskBackoff := containerRestartBackoff(policy, taskRestarts); taskBackoff > backoff {
+		backoff = taskBackoff
+	}
+	seelog.Infof("Managed task [%s]: container [%s] exited; restarting it in %s (attempt %d)",
+		mtask.Arn, container.Name, backoff.String(), attempt)
+	go mtask.restartContainer(container, event.DockerID, backoff)
+	return true
+}
+
+// shouldRestartContainer returns true if the container change is an
+// unrequested exit of a running container whose restart policy applies
+func (mtask *managedTask) shouldRestartContainer(container *apicontainer.Container,
+	event dockerapi.DockerContainerChangeEvent) bool {
+	if event.Status != apicontainerstatus.ContainerStopped || event.Error != nil || event.DockerID == "" {
+		return false
+	}
+	return containerRestartable(mtask.Task, container)
+}
+
+// containerRestartable returns true if the container has a restart policy and
+// both the container and its task are running and desired to keep running
+func containerRestartable(task *apitask.Task, container *apicontainer.Container) bool {
+	if container.GetRestartPolicy() == nil {
+		return false
+	}
+	if container.GetKnownStatus() != apicontainerstatus.ContainerRunning {
+		return false
+	}
+	return container.GetDesiredStatus() == apicontainerstatus.ContainerRunning &&
+		task.GetDesiredStatus() == apitaskstatus.TaskRunning
+}
+
+// restartContainer starts the container again once the backoff has elapsed.
+// If the container can't be started, a stopped event is emitted so that it's
+// handled like any other exit.
+func (mtask *managedTask) restartContainer(container *apicontainer.Container, dockerID string, backoff time.Duration) {
+	select {
+	case <-mtask.ctx.Done():
+		return
+	case <-mtask.time().After(backoff):
+	}
+	if container.GetDesiredStatus() != apicontainerstatus.ContainerRunning ||
+		mtask.GetDesiredStatus() != apitaskstatus.TaskRunning {
+		seelog.Infof("Managed task [%s]: not restarting container [%s] as it's no longer desired to be running",
+			mtask.Arn, container.Name)
+		return
+	}
+	// The container is started again as is, so it rejoins the network
+	// namespace of the pause container of an awsvpc task, keeping its ENI and
+	// addresses. That namespace is only there as long as the pause container
+	// is running.
+	if err := taskNetworkNamespaceError(mtask.Task, container); err != nil {
+		mtask.emitContainerRestartError(container, dockerapi.DockerContainerMetadata{DockerID: dockerID}, err)
+		return
+	}
+	metadata := mtask.engine.client.StartContainer(mtask.ctx, dockerID, mtask.cfg.ContainerStartTimeout)
+	if metadata.Error == nil {
+		mtask.engine.startExecuteCommandAgent(mtask.Task, container, dockerID)
+		mtask.engine.watchStdoutHealthEvents(mtask.Task, container, dockerID, metadata.StartedAt)
+		return
+	}
+	metadata.DockerID = dockerID
+	mtask.emitContainerRestartError(container, metadata, metadata.Error)
+}
+
+// emitContainerRestartError emits a stopped event for a container that
+// couldn't be restarted, so that it's handled like any other exit
+func (mtask *managedTask) emitContainerRestartError(container *apicontainer.Container,
+	metadata dockerapi.DockerContainerMetadata, err error) {
+	seelog.Warnf("Managed task [%s]: unable to restart container [%s]: %v",
+		mtask.Arn, container.Name, err)
+	metadata.Error = ContainerRestartError{container: container.Name, err: err}
+	mtask.emitDockerContainerChange(dockerContainerChange{
+		container: container,
+		event: dockerapi.DockerContainerChangeEvent{
+			Status:                  apicontainerstatus.ContainerStopped,
+			DockerContainerMetadata: metadata,
+		},
+	})
+}
+
+// taskNetworkNamespaceError returns an error if the container shares the
+// network namespace of the pause container of its task, and the pause container
+// isn't running anymore. The steady state of the pause container is
+// RESOURCES_PROVISIONED rather than RUNNING
+func taskNetworkNamespaceError(task *apitask.Task, container *apicontainer.Container) error {
+	if container.Type == apicontainer.ContainerCNIPause {
+		return nil
+	}
+	pauseContainer, ok := task.ContainerByName(apitask.NetworkPauseContainerName)
+	if !ok {
+		return nil
+	}
+	if status := pauseContainer.GetKnownStatus(); !status.IsRunning() {
+		return errors.Errorf("the network namespace of the task is gone, its pause container is %s",
+			status.String())
+	}
+	return nil
+}
+
+// containerRestartBackoff returns the delay before the given restart attempt,
+// doubling the policy's backoff with each consecutive attempt
+func containerRestartBackoff(policy *apicontainer.RestartPolicy, attempt int) time.Duration {
+	backoff := time.Duration(policy.BackoffSeconds) * time.Second
+	for i := 1; i < attempt && backoff < maxContainerRestartBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxContainerRestartBackoff {
+		return maxContainerRestartBackoff
+	}
+	return backoff
+}
@@ -0,0 +1,308 @@
+// +build unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apicontainerstatus "github.com/aws/amazon-ecs-agent/agent/api/container/status"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	apitaskstatus "github.com/aws/amazon-ecs-agent/agent/api/task/status"
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi"
+	mock_dockerapi "github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi/mocks"
+	"github.com/aws/amazon-ecs-agent/agent/statemanager"
+	mock_ttime "github.com/aws/amazon-ecs-agent/agent/utils/ttime/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRestartTestTask(t *testing.T, policy *apicontainer.RestartPolicy) (*managedTask,
+	*mock_dockerapi.MockDockerClient, *mock_ttime.MockTime, func()) {
+	ctrl := gomock.NewController(t)
+	client := mock_dockerapi.NewMockDockerClient(ctrl)
+	mockTime := mock_ttime.NewMockTime(ctrl)
+	mockTime.EXPECT().Now().Return(time.Now()).AnyTimes()
+	ctx, cancel := context.WithCancel(context.TODO())
+
+	container := &apicontainer.Container{
+		Name:                "sidecar",
+		RestartPolicy:       policy,
+		KnownStatusUnsafe:   apicontainerstatus.ContainerRunning,
+		DesiredStatusUnsafe: apicontainerstatus.ContainerRunning,
+	}
+	mtask := &managedTask{
+		Task: &apitask.Task{
+			Arn:                 "task",
+			Containers:          []*apicontainer.Container{container},
+			DesiredStatusUnsafe: apitaskstatus.TaskRunning,
+		},
+		ctx: ctx,
+		engine: &DockerTaskEngine{
+			client: client,
+			saver:  statemanager.NewNoopStateManager(),
+		},
+		cfg: &config.Config{
+			ContainerStartTimeout:  time.Minute,
+			TaskRestartLimit:       3,
+			TaskRestartLimitWindow: 10 * time.Minute,
+		},
+		dockerMessages: make(chan dockerContainerChange, 1),
+		_time:          mockTime,
+	}
+	return mtask, client, mockTime, func() {
+		cancel()
+		ctrl.Finish()
+	}
+}
+
+func containerExitedEvent(runtime time.Duration) dockerapi.DockerContainerChangeEvent {
+	startedAt := time.Now().Add(-runtime)
+	return dockerapi.DockerContainerChangeEvent{
+		Status: apicontainerstatus.ContainerStopped,
+		DockerContainerMetadata: dockerapi.DockerContainerMetadata{
+			DockerID:   "id",
+			StartedAt:  startedAt,
+			FinishedAt: startedAt.Add(runtime),
+		},
+	}
+}
+
+func TestHandleContainerRestart(t *testing.T) {
+	mtask, client, mockTime, done := newRestartTestTask(t, &apicontainer.RestartPolicy{
+		MaximumAttempts: 3,
+		BackoffSeconds:  10,
+	})
+	defer done()
+	container := mtask.Containers[0]
+	container.RestartCountUnsafe = 1
+
+	elapsed := make(chan time.Time)
+	close(elapsed)
+	started := make(chan struct{})
+	mockTime.EXPECT().After(20 * time.Second).Return(elapsed)
+	client.EXPECT().StartContainer(gomock.Any(), "id", time.Minute).Do(
+		func(ctx context.Context, id string, timeout time.Duration) {
+			close(started)
+		}).Return(dockerapi.DockerContainerMetadata{DockerID: "id"})
+
+	assert.True(t, mtask.handleContainerRestart(container, containerExitedEvent(time.Second)))
+	<-started
+	assert.Equal(t, 2, container.GetRestartCount())
+	assert.Equal(t, apicontainerstatus.ContainerRunning, container.GetKnownStatus())
+}
+
+func TestHandleContainerRestartResetWindow(t *testing.T) {
+	mtask, client, mockTime, done := newRestartTestTask(t, &apicontainer.RestartPolicy{
+		MaximumAttempts:    3,
+		BackoffSeconds:     10,
+		ResetWindowSeconds: 60,
+	})
+	defer done()
+	container := mtask.Containers[0]
+	container.RestartCountUnsafe = 3
+
+	elapsed := make(chan time.Time)
+	close(elapsed)
+	started := make(chan struct{})
+	mockTime.EXPECT().After(10 * time.Second).Return(elapsed)
+	client.EXPECT().StartContainer(gomock.Any(), "id", time.Minute).Do(
+		func(ctx context.Context, id string, timeout time.Duration) {
+			close(started)
+		}).Return(dockerapi.DockerContainerMetadata{DockerID: "id"})
+
+	assert.True(t, mtask.handleContainerRestart(container, containerExitedEvent(2*time.Minute)))
+	<-started
+	assert.Equal(t, 1, container.GetRestartCount())
+}
+
+func TestHandleContainerRestartAttemptsExhausted(t *testing.T) {
+	mtask, _, _, done := newRestartTestTask(t, &apicontainer.RestartPolicy{
+		MaximumAttempts:    3,
+		ResetWindowSeconds: 60,
+	})
+	defer done()
+	container := mtask.Containers[0]
+	container.RestartCountUnsafe = 3
+
+	assert.False(t, mtask.handleContainerRestart(container, containerExitedEvent(time.Second)))
+	assert.Equal(t, 3, container.GetRestartCount())
+}
+
+func TestHandleContainerRestartSharedBackoff(t *testing.T) {
+	mtask, client, mockTime, done := newRestartTestTask(t, &apicontainer.RestartPolicy{
+		BackoffSeconds: 10,
+	})
+	defer done()
+	container := mtask.Containers[0]
+	mtask.RestartTimesUnsafe = []time.Time{time.Now().Add(-time.Minute)}
+
+	elapsed := make(chan time.Time)
+	close(elapsed)
+	started := make(chan struct{})
+	mockTime.EXPECT().After(20 * time.Second).Return(elapsed)
+	client.EXPECT().StartContainer(gomock.Any(), "id", time.Minute).Do(
+		func(ctx context.Context, id string, timeout time.Duration) {
+			close(started)
+		}).Return(dockerapi.DockerContainerMetadata{DockerID: "id"})
+
+	assert.True(t, mtask.handleContainerRestart(container, containerExitedEvent(time.Second)))
+	<-started
+	assert.Equal(t, 1, container.GetRestartCount())
+}
+
+func TestHandleContainerRestartCrashLoop(t *testing.T) {
+	mtask, _, _, done := newRestartTestTask(t, &apicontainer.RestartPolicy{})
+	defer done()
+	container := mtask.Containers[0]
+	mtask.RestartTimesUnsafe = []time.Time{
+		time.Now().Add(-time.Hour),
+		time.Now().Add(-3 * time.Minute),
+		time.Now().Add(-2 * time.Minute),
+		time.Now().Add(-time.Minute),
+	}
+
+	assert.False(t, mtask.handleContainerRestart(container, containerExitedEvent(time.Second)))
+	assert.Equal(t, 0, container.GetRestartCount())
+	assert.Equal(t, apitaskstatus.TaskStopped, mtask.GetDesiredStatus())
+	assert.Equal(t, "TaskCrashLoopError: containers restarted 3 times within 10m0s", mtask.GetTerminalReason())
+}
+
+func TestHandleContainerRestartNotApplicable(t *testing.T) {
+	testCases := []struct {
+		name   string
+		policy *apicontainer.RestartPolicy
+		modify func(*managedTask, *dockerapi.DockerContainerChangeEvent)
+	}{
+		{
+			name: "no restart policy",
+		},
+		{
+			name:   "task desired to stop",
+			policy: &apicontainer.RestartPolicy{},
+			modify: func(mtask *managedTask, event *dockerapi.DockerContainerChangeEvent) {
+				mtask.SetDesiredStatus(apitaskstatus.TaskStopped)
+			},
+		},
+		{
+			name:   "container desired to stop",
+			policy: &apicontainer.RestartPolicy{},
+			modify: func(mtask *managedTask, event *dockerapi.DockerContainerChangeEvent) {
+				mtask.Containers[0].SetDesiredStatus(apicontainerstatus.ContainerStopped)
+			},
+		},
+		{
+			name:   "stop transition error",
+			policy: &apicontainer.RestartPolicy{},
+			modify: func(mtask *managedTask, event *dockerapi.DockerContainerChangeEvent) {
+				event.Error = ContainerRestartError{container: "sidecar", err: errors.New("error")}
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mtask, _, _, done := newRestartTestTask(t, tc.policy)
+			defer done()
+			event := containerExitedEvent(time.Second)
+			if tc.modify != nil {
+				tc.modify(mtask, &event)
+			}
+			assert.False(t, mtask.handleContainerRestart(mtask.Containers[0], event))
+			assert.Equal(t, 0, mtask.Containers[0].GetRestartCount())
+		})
+	}
+}
+
+func TestRestartContainerStartError(t *testing.T) {
+	mtask, client, mockTime, done := newRestartTestTask(t, &apicontainer.RestartPolicy{})
+	defer done()
+	container := mtask.Containers[0]
+
+	elapsed := make(chan time.Time)
+	close(elapsed)
+	mockTime.EXPECT().After(time.Duration(0)).Return(elapsed)
+	client.EXPECT().StartContainer(gomock.Any(), "id", time.Minute).Return(dockerapi.DockerContainerMetadata{
+		Error: dockerapi.CannotStartContainerError{FromError: errors.New("error")},
+	})
+
+	mtask.restartContainer(container, "id", 0)
+	change := <-mtask.dockerMessages
+	assert.Equal(t, container, change.container)
+	assert.Equal(t, apicontainerstatus.ContainerStopped, change.event.Status)
+	assert.Equal(t, "id", change.event.DockerID)
+	require.Error(t, change.event.Error)
+	assert.Equal(t, "ContainerRestartError", change.event.Error.ErrorName())
+}
+
+func TestRestartContainerRejoinsTaskNetworkNamespace(t *testing.T) {
+	mtask, client, mockTime, done := newRestartTestTask(t, &apicontainer.RestartPolicy{})
+	defer done()
+	container := mtask.Containers[0]
+	mtask.Containers = append(mtask.Containers, &apicontainer.Container{
+		Name:              apitask.NetworkPauseContainerName,
+		Type:              apicontainer.ContainerCNIPause,
+		KnownStatusUnsafe: apicontainerstatus.ContainerResourcesProvisioned,
+	})
+
+	elapsed := make(chan time.Time)
+	close(elapsed)
+	mockTime.EXPECT().After(time.Duration(0)).Return(elapsed)
+	// Only the container itself is started again, in the namespace of the
+	// running pause container
+	client.EXPECT().StartContainer(gomock.Any(), "id", time.Minute).Return(dockerapi.DockerContainerMetadata{DockerID: "id"})
+
+	mtask.restartContainer(container, "id", 0)
+	assert.Empty(t, mtask.dockerMessages)
+	assert.Equal(t, apicontainerstatus.ContainerResourcesProvisioned, mtask.Containers[1].GetKnownStatus())
+}
+
+func TestRestartContainerTaskNetworkNamespaceGone(t *testing.T) {
+	mtask, _, mockTime, done := newRestartTestTask(t, &apicontainer.RestartPolicy{})
+	defer done()
+	container := mtask.Containers[0]
+	mtask.Containers = append(mtask.Containers, &apicontainer.Container{
+		Name:              apitask.NetworkPauseContainerName,
+		Type:              apicontainer.ContainerCNIPause,
+		KnownStatusUnsafe: apicontainerstatus.ContainerStopped,
+	})
+
+	elapsed := make(chan time.Time)
+	close(elapsed)
+	mockTime.EXPECT().After(time.Duration(0)).Return(elapsed)
+
+	mtask.restartContainer(container, "id", 0)
+	change := <-mtask.dockerMessages
+	assert.Equal(t, container, change.container)
+	assert.Equal(t, apicontainerstatus.ContainerStopped, change.event.Status)
+	assert.Equal(t, "id", change.event.DockerID)
+	require.Error(t, change.event.Error)
+	assert.Equal(t, "ContainerRestartError", change.event.Error.ErrorName())
+}
+
+func TestContainerRestartBackoff(t *testing.T) {
+	policy := &apicontainer.RestartPolicy{BackoffSeconds: 10}
+	assert.Equal(t, 10*time.Second, containerRestartBackoff(policy, 1))
+	assert.Equal(t, 20*time.Second, containerRestartBackoff(policy, 2))
+	assert.Equal(t, 80*time.Second, containerRestartBackoff(policy, 4))
+	assert.Equal(t, maxContainerRestartBackoff, containerRestartBackoff(policy, 100))
+	assert.Equal(t, time.Duration(0), containerRestartBackoff(&apicontainer.RestartPolicy{}, 3))
+}
@@ -243,6 +243,11 @@ func verifyContainerOrderingStatusResolvable(target *apicontainer.Container, exi
 		}
 
 		if !resolves(target, dependencyContainer, dependency.Condition) {
+			// We want to fail fast if the dependency container has stopped without reaching the state the
+			// condition waits for, because target container can then never progress to its desired state
+			if targetKnown < target.GetSteadyStateStatus() && hasDependencyStoppedUnresolved(dependencyContainer, dependency.Condition) {
+				return nil, fmt.Errorf("dependency graph: failed to resolve container ordering dependency [%v] for target [%v] as dependency stopped before the %s condition was met.", dependencyContainer, target, dependency.Condition)
+			}
 			return &dependency, fmt.Errorf("dependency graph: failed to resolve the container ordering dependency [%v] for target [%v]", dependencyContainer, target)
 		}
 	}
@@ -285,7 +290,13 @@ func verifyResourceDependenciesResolved(target *apicontainer.Container, existing
 		if !exists {
 			return false
 		}
-		if dep.GetKnownStatus() < resourceDependency.GetRequiredStatus() {
+		if resolver, ok := dep.(taskresource.DependencyStatusResolver); ok {
+			if !resolver.DependencySatisfied(targetNext, resourceDependency.GetSatisfiedStatuses()) {
+				return false
+			}
+			continue
+		}
+		if !resourceDependency.SatisfiedBy(dep.GetKnownStatus()) {
 			return false
 		}
 	}
@@ -406,6 +417,17 @@ func hasDependencyStoppedSuccessfully(dependency *apicontainer.Container, condit
 	return isDependencyStoppedSuccessfully
 }
 
+// hasDependencyStoppedUnresolved returns true if the dependency container has stopped, so that it can no
+// longer reach the state the 'START' and 'HEALTHY' conditions wait for
+func hasDependencyStoppedUnresolved(dependency *apicontainer.Container, condition string) bool {
+	switch condition {
+	case startCondition, healthyCondition:
+		return dependency.GetKnownStatus() == apicontainerstatus.ContainerStopped
+	default:
+		return false
+	}
+}
+
 func verifyContainerOrderingStatus(dependsOnContainer *apicontainer.Container) bool {
 	dependsOnContainerDesiredStatus := dependsOnContainer.GetDesiredStatus()
 	// The 'target' container desires to be moved to 'Created' or the 'steady' state.
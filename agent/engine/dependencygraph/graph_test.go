@@ -547,6 +547,80 @@ func TestVerifyTransitionResourceDependenciesResolved(t *testing.T) {
 	}
 }
 
+func TestVerifyResourceDependenciesOnStatusesResolved(t *testing.T) {
+	testcases := []struct {
+		Name             string
+		DependencyKnown  resourcestatus.ResourceStatus
+		ExpectedResolved bool
+	}{
+		{
+			Name:             "resource none",
+			DependencyKnown:  resourcestatus.ResourceStatus(0),
+			ExpectedResolved: false,
+		},
+		{
+			Name:             "resource in satisfied status",
+			DependencyKnown:  resourcestatus.ResourceStatus(2),
+			ExpectedResolved: true,
+		},
+		{
+			Name:             "resource past satisfied status",
+			DependencyKnown:  resourcestatus.ResourceStatus(3),
+			ExpectedResolved: false,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.Name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockResource := mock_taskresource.NewMockTaskResource(ctrl)
+			mockResource.EXPECT().GetKnownStatus().Return(tc.DependencyKnown).AnyTimes()
+			target := &apicontainer.Container{
+				KnownStatusUnsafe:         apicontainerstatus.ContainerStatusNone,
+				TransitionDependenciesMap: make(map[apicontainerstatus.ContainerStatus]apicontainer.TransitionDependencySet),
+			}
+			target.BuildResourceDependencyOnStatuses("resource",
+				[]resourcestatus.ResourceStatus{resourcestatus.ResourceStatus(1), resourcestatus.ResourceStatus(2)},
+				apicontainerstatus.ContainerPulled)
+			resources := map[string]taskresource.TaskResource{"resource": mockResource}
+			assert.Equal(t, tc.ExpectedResolved, verifyResourceDependenciesResolved(target, resources))
+		})
+	}
+}
+
+// dependencyResolvingResource is a task resource that decides by itself
+// whether it satisfies dependencies on it
+type dependencyResolvingResource struct {
+	*mock_taskresource.MockTaskResource
+	satisfiedFor apicontainerstatus.ContainerStatus
+}
+
+func (resource *dependencyResolvingResource) DependencySatisfied(dependentStatus apicontainerstatus.ContainerStatus,
+	satisfiedStatuses []resourcestatus.ResourceStatus) bool {
+	return dependentStatus == resource.satisfiedFor
+}
+
+func TestVerifyResourceDependenciesResolvedByResource(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	resource := &dependencyResolvingResource{
+		MockTaskResource: mock_taskresource.NewMockTaskResource(ctrl),
+		satisfiedFor:     apicontainerstatus.ContainerPulled,
+	}
+	resources := map[string]taskresource.TaskResource{"resource": resource}
+
+	target := &apicontainer.Container{
+		KnownStatusUnsafe:         apicontainerstatus.ContainerStatusNone,
+		TransitionDependenciesMap: make(map[apicontainerstatus.ContainerStatus]apicontainer.TransitionDependencySet),
+	}
+	target.BuildResourceDependency("resource", resourcestatus.ResourceCreated, apicontainerstatus.ContainerPulled)
+	target.BuildResourceDependency("resource", resourcestatus.ResourceCreated, apicontainerstatus.ContainerCreated)
+	assert.True(t, verifyResourceDependenciesResolved(target, resources))
+
+	target.SetKnownStatus(apicontainerstatus.ContainerPulled)
+	assert.False(t, verifyResourceDependenciesResolved(target, resources))
+}
+
 func TestTransitionDependencyResourceNotFound(t *testing.T) {
 	// this test verifies if error is thrown when the resource dependency is not
 	// found in the list of task resources
@@ -885,6 +959,57 @@ func assertContainerOrderingHealthyConditionResolved(f func(target *apicontainer
 	}
 }
 
+func TestContainerOrderingDependencyStoppedUnresolved(t *testing.T) {
+	testcases := []struct {
+		DependencyKnown     apicontainerstatus.ContainerStatus
+		DependencyCondition string
+		ExpectedBlocked     bool
+	}{
+		{
+			DependencyKnown:     apicontainerstatus.ContainerStopped,
+			DependencyCondition: startCondition,
+			ExpectedBlocked:     false,
+		},
+		{
+			DependencyKnown:     apicontainerstatus.ContainerStopped,
+			DependencyCondition: healthyCondition,
+			ExpectedBlocked:     false,
+		},
+		{
+			DependencyKnown:     apicontainerstatus.ContainerCreated,
+			DependencyCondition: startCondition,
+			ExpectedBlocked:     true,
+		},
+		{
+			DependencyKnown:     apicontainerstatus.ContainerRunning,
+			DependencyCondition: healthyCondition,
+			ExpectedBlocked:     true,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(fmt.Sprintf("%s+%s", tc.DependencyCondition, tc.DependencyKnown.String()), func(t *testing.T) {
+			dependency := &apicontainer.Container{
+				Name:                "dependency",
+				DesiredStatusUnsafe: apicontainerstatus.ContainerRunning,
+				KnownStatusUnsafe:   tc.DependencyKnown,
+				HealthCheckType:     apicontainer.DockerHealthCheckType,
+			}
+			target := &apicontainer.Container{
+				Name:                "target",
+				DesiredStatusUnsafe: apicontainerstatus.ContainerRunning,
+				KnownStatusUnsafe:   apicontainerstatus.ContainerPulled,
+				DependsOnUnsafe: []apicontainer.DependsOn{
+					{ContainerName: "dependency", Condition: tc.DependencyCondition},
+				},
+			}
+			blocked, err := DependenciesAreResolved(target, []*apicontainer.Container{dependency, target},
+				"", nil, nil)
+			assert.Error(t, err)
+			assert.Equal(t, tc.ExpectedBlocked, blocked != nil)
+		})
+	}
+}
+
 func dependsOn(vals ...string) []apicontainer.DependsOn {
 	d := make([]apicontainer.DependsOn, len(vals))
 	for i, val := range vals {
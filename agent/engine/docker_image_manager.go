@@ -29,12 +29,22 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi"
 	"github.com/aws/amazon-ecs-agent/agent/engine/dockerstate"
 	"github.com/aws/amazon-ecs-agent/agent/engine/image"
+	"github.com/aws/amazon-ecs-agent/agent/metrics"
 	"github.com/aws/amazon-ecs-agent/agent/statemanager"
+	"github.com/aws/amazon-ecs-agent/agent/utils/ttime"
 	"github.com/cihub/seelog"
 )
 
 const (
 	imageNotFoundForDeletionError = "no such image"
+	imageDeleteEvent              = "delete"
+	imageUntagEvent               = "untag"
+	// imageDeletionFailuresBeforeQuarantine is the number of times in a row an
+	// image can fail to be removed before it's quarantined
+	imageDeletionFailuresBeforeQuarantine = 3
+	// imageQuarantineDuration is how long quarantined images aren't candidates
+	// for deletion, before the image cleanup tries to remove them again
+	imageQuarantineDuration = 24 * time.Hour
 )
 
 // ImageManager is responsible for saving the Image states,
@@ -45,7 +55,9 @@ type ImageManager interface {
 	AddAllImageStates(imageStates []*image.ImageState)
 	GetImageStateFromImageName(containerImageName string) (*image.ImageState, bool)
 	StartImageCleanupProcess(ctx context.Context)
+	StartImageEventsListener(ctx context.Context)
 	SetSaver(stateManager statemanager.Saver)
+	ExcludeImagesFromCleanup(imageNames []string)
 }
 
 // dockerImageManager accounts all the images and their states in the instance.
@@ -67,6 +79,25 @@ type dockerImageManager struct {
 	nonECSContainerCleanupWaitDuration time.Duration
 	numNonECSContainersToDelete        int
 	nonECSMinimumAgeBeforeDeletion     time.Duration
+	// clock, deletionNotifier and candidateFilter are hooks to test the image
+	// cleanup deterministically, see ImageCleanupHooks
+	clock            ttime.Time
+	deletionNotifier chan<- string
+	candidateFilter  func(*image.ImageState) bool
+}
+
+// ImageCleanupHooks is implemented by the image manager to let tests control
+// the image cleanup without waiting for images to age in real time
+type ImageCleanupHooks interface {
+	// SetClock sets the clock the age of images and containers is measured with
+	SetClock(clock ttime.Time)
+	// SetDeletionNotifier sets the channel the ids of the images are sent to
+	// once they're removed along with their state. Notifications are dropped
+	// when the channel isn't ready, so it should be buffered
+	SetDeletionNotifier(notifier chan<- string)
+	// SetCandidateFilter sets a filter the image states have to pass to be
+	// candidates for deletion, in addition to the cleanup policy
+	SetCandidateFilter(filter func(*image.ImageState) bool)
 }
 
 // ImageStatesForDeletion is used for implementing the sort interface
@@ -86,6 +117,7 @@ func NewImageManager(cfg *config.Config, client dockerapi.DockerClient, state do
 		nonECSContainerCleanupWaitDuration: cfg.TaskCleanupWaitDuration,
 		numNonECSContainersToDelete:        cfg.NumNonECSContainersToDeletePerCycle,
 		nonECSMinimumAgeBeforeDeletion:     cfg.NonECSMinimumImageDeletionAge,
+		clock:                              &ttime.DefaultTime{},
 	}
 }
 
@@ -93,6 +125,35 @@ func (imageManager *dockerImageManager) SetSaver(stateManager statemanager.Saver
 	imageManager.saver = stateManager
 }
 
+// SetClock sets the clock the age of images and containers is measured with
+func (imageManager *dockerImageManager) SetClock(clock ttime.Time) {
+	imageManager.updateLock.Lock()
+	defer imageManager.updateLock.Unlock()
+	imageManager.clock = clock
+}
+
+// SetDeletionNotifier sets the channel the ids of removed images are sent to
+func (imageManager *dockerImageManager) SetDeletionNotifier(notifier chan<- string) {
+	imageManager.updateLock.Lock()
+	defer imageManager.updateLock.Unlock()
+	imageManager.deletionNotifier = notifier
+}
+
+// SetCandidateFilter sets the filter the candidates for deletion have to pass
+func (imageManager *dockerImageManager) SetCandidateFilter(filter func(*image.ImageState) bool) {
+	imageManager.updateLock.Lock()
+	defer imageManager.updateLock.Unlock()
+	imageManager.candidateFilter = filter
+}
+
+// now returns the current time of the clock of the image manager
+func (imageManager *dockerImageManager) now() time.Time {
+	if imageManager.clock == nil {
+		return time.Now()
+	}
+	return imageManager.clock.Now()
+}
+
 func (imageManager *dockerImageManager) AddAllImageStates(imageStates []*image.ImageState) {
 	imageManager.updateLock.Lock()
 	defer imageManager.updateLock.Unlock()
@@ -195,8 +256,8 @@ func (imageManager *dockerImageManager) addContainerReferenceToNewImageState(con
 		}
 		sourceImageState := &image.ImageState{
 			Image:      sourceImage,
-			PulledAt:   time.Now(),
-			LastUsedAt: time.Now(),
+			PulledAt:   imageManager.now(),
+			LastUsedAt: imageManager.now(),
 		}
 		sourceImageState.UpdateImageState(container)
 		imageManager.addImageState(sourceImageState)
@@ -262,7 +323,9 @@ func (imageManager *dockerImageManager) getCandidateImagesForDeletion() []*image
 	}
 	var imagesForDeletion []*image.ImageState
 	for _, imageState := range imageManager.imageStatesConsideredForDeletion {
-		if imageManager.isImageOldEnough(imageState) && imageState.HasNoAssociatedContainers() {
+		if imageManager.isImageOldEnough(imageState) && imageState.HasNoAssociatedContainers() &&
+			!imageManager.isQuarantined(imageState) &&
+			(imageManager.candidateFilter == nil || imageManager.candidateFilter(imageState)) {
 			seelog.Infof("Candidate image for deletion: [%s]", imageState.String())
 			imagesForDeletion = append(imagesForDeletion, imageState)
 		}
@@ -271,13 +334,21 @@ func (imageManager *dockerImageManager) getCandidateImagesForDeletion() []*image
 }
 
 func (imageManager *dockerImageManager) isImageOldEnough(imageState *image.ImageState) bool {
-	ageOfImage := time.Now().Sub(imageState.PulledAt)
+	ageOfImage := imageManager.now().Sub(imageState.PulledAt)
 	return ageOfImage > imageManager.minimumAgeBeforeDeletion
 }
 
+// isQuarantined returns whether the image failed to be removed too many times
+// in a row recently, so that the image cleanup doesn't keep trying to remove it
+// every cycle
+func (imageManager *dockerImageManager) isQuarantined(imageState *image.ImageState) bool {
+	quarantinedAt := imageState.GetQuarantinedAt()
+	return !quarantinedAt.IsZero() && imageManager.now().Sub(quarantinedAt) < imageQuarantineDuration
+}
+
 //TODO: change image createdTime to image lastUsedTime when docker support it in the future
 func (imageManager *dockerImageManager) nonECSImageOldEnough(NonECSImage ImageWithSizeID) bool {
-	ageOfImage := time.Now().Sub(NonECSImage.createdTime)
+	ageOfImage := imageManager.now().Sub(NonECSImage.createdTime)
 	return ageOfImage > imageManager.nonECSMinimumAgeBeforeDeletion
 }
 
@@ -325,6 +396,77 @@ func (imageManager *dockerImageManager) StartImageCleanupProcess(ctx context.Con
 	imageManager.performPeriodicImageCleanup(ctx, imageManager.imageCleanupTimeInterval)
 }
 
+// StartImageEventsListener listens to the image events of the docker daemon and
+// prunes the image states of images removed outside of the agent, e.g. by
+// `docker system prune`, so that they are not considered by image cleanup or
+// reported until the agent restarts
+func (imageManager *dockerImageManager) StartImageEventsListener(ctx context.Context) {
+	imageEvents, err := imageManager.client.ImageEvents(ctx)
+	if err != nil {
+		seelog.Errorf("Image Manager: unable to listen to docker image events: %v", err)
+		return
+	}
+	for event := range imageEvents {
+		imageManager.handleImageEvent(event)
+	}
+}
+
+func (imageManager *dockerImageManager) handleImageEvent(event dockerapi.DockerImageEvent) {
+	imageManager.updateLock.Lock()
+	defer imageManager.updateLock.Unlock()
+
+	imageState, ok := imageManager.getImageState(event.ImageID)
+	if !ok {
+		return
+	}
+	switch event.Action {
+	case imageDeleteEvent:
+		seelog.Infof("Image Manager: image %s was removed from the instance", event.ImageID)
+		imageManager.pruneImageState(imageState)
+	case imageUntagEvent:
+		imageManager.reconcileImageNames(imageState)
+	}
+}
+
+// reconcileImageNames removes the names of the image state that no longer tag
+// the image, and prunes the image state if the image is gone
+func (imageManager *dockerImageManager) reconcileImageNames(imageState *image.ImageState) {
+	imageInspected, err := imageManager.client.InspectImage(imageState.Image.ImageID)
+	if err != nil {
+		if strings.Contains(strings.ToLower(err.Error()), imageNotFoundForDeletionError) {
+			seelog.Infof("Image Manager: image %s was removed from the instance", imageState.Image.ImageID)
+			imageManager.pruneImageState(imageState)
+			return
+		}
+		seelog.Warnf("Image Manager: unable to inspect untagged image %s: %v", imageState.Image.ImageID, err)
+		return
+	}
+	repoTags := make(map[string]struct{})
+	for _, repoTag := range imageInspected.RepoTags {
+		repoTags[repoTag] = struct{}{}
+	}
+	for _, imageName := range imageState.GetImageNames() {
+		if _, ok := repoTags[imageName]; ok {
+			continue
+		}
+		// image names without a tag are reported by docker with the latest tag
+		if _, ok := repoTags[imageName+":latest"]; ok {
+			continue
+		}
+		seelog.Infof("Image Manager: image name %s was removed from image %s", imageName, imageState.Image.ImageID)
+		imageState.RemoveImageName(imageName)
+	}
+	imageManager.saver.Save()
+}
+
+// pruneImageState removes all the tracking information of the image state
+func (imageManager *dockerImageManager) pruneImageState(imageState *image.ImageState) {
+	delete(imageManager.imageStatesConsideredForDeletion, imageState.Image.ImageID)
+	imageManager.removeImageState(imageState)
+	imageManager.state.RemoveImageState(imageState)
+	imageManager.saver.Save()
+}
+
 func (imageManager *dockerImageManager) performPeriodicImageCleanup(ctx context.Context, imageCleanupInterval time.Duration) {
 	imageManager.imageCleanupTicker = time.NewTicker(imageCleanupInterval)
 	for {
@@ -391,7 +533,7 @@ func (imageManager *dockerImageManager) removeNonECSContainers(ctx context.Conte
 		if (response.State.Status == "exited" ||
 			response.State.Status == "dead" ||
 			response.State.Status == "created") &&
-			time.Now().Sub(finishedTime) > imageManager.nonECSContainerCleanupWaitDuration {
+			imageManager.now().Sub(finishedTime) > imageManager.nonECSContainerCleanupWaitDuration {
 			nonECSContainerRemoveAvailableIDs = append(nonECSContainerRemoveAvailableIDs, id)
 		}
 	}
@@ -524,6 +666,21 @@ func (imageManager *dockerImageManager) getNonECSImages(ctx context.Context) []I
 	return nonECSImages
 }
 
+// ExcludeImagesFromCleanup pins the images so that they're never cleaned up,
+// for the images the agent loads itself and that can't be pulled once removed
+func (imageManager *dockerImageManager) ExcludeImagesFromCleanup(imageNames []string) {
+	imageManager.updateLock.Lock()
+	defer imageManager.updateLock.Unlock()
+
+	for _, imageName := range imageNames {
+		if isInExclusionList(imageName, imageManager.imageCleanupExclusionList) {
+			continue
+		}
+		seelog.Infof("Image excluded from cleanup: %s", imageName)
+		imageManager.imageCleanupExclusionList = append(imageManager.imageCleanupExclusionList, imageName)
+	}
+}
+
 func isInExclusionList(imageName string, imageExclusionList []string) bool {
 	for _, exclusionName := range imageExclusionList {
 		if imageName == exclusionName {
@@ -632,11 +789,13 @@ func (imageManager *dockerImageManager) deleteImage(ctx context.Context, imageID
 			seelog.Errorf("Image already removed from the instance: %v", err)
 		} else {
 			seelog.Errorf("Error removing Image %v - %v", imageID, err)
+			imageManager.recordDeletionError(imageState, err)
 			delete(imageManager.imageStatesConsideredForDeletion, imageState.Image.ImageID)
 			return
 		}
 	}
 	seelog.Infof("Image removed: %v", imageID)
+	imageState.ResetDeletionFailures()
 	imageState.RemoveImageName(imageID)
 	if len(imageState.Image.Names) == 0 {
 		seelog.Infof("Cleaning up all tracking information for image %s as it has zero references", imageID)
@@ -644,6 +803,35 @@ func (imageManager *dockerImageManager) deleteImage(ctx context.Context, imageID
 		imageManager.removeImageState(imageState)
 		imageManager.state.RemoveImageState(imageState)
 		imageManager.saver.Save()
+		metrics.MetricsEngineGlobal.RecordImageRemoval()
+		imageManager.notifyDeletion(imageState.Image.ImageID)
+	}
+}
+
+// recordDeletionError records an error removing the image, and quarantines the
+// image once it fails to be removed too many times in a row, e.g. because one
+// of its layers is used by a container the agent doesn't manage
+func (imageManager *dockerImageManager) recordDeletionError(imageState *image.ImageState, err error) {
+	now := imageManager.now()
+	failures := imageState.RecordDeletionError(err, now)
+	if failures >= imageDeletionFailuresBeforeQuarantine && !imageManager.isQuarantined(imageState) {
+		seelog.Warnf("Image Manager: quarantining image %s for %s after failing to remove it %d times in a row: %v",
+			imageState.Image.ImageID, imageQuarantineDuration, failures, err)
+		imageState.Quarantine(now)
+	}
+	imageManager.saver.Save()
+}
+
+// notifyDeletion sends the id of the removed image to the deletion notifier,
+// if any, without blocking the cleanup
+func (imageManager *dockerImageManager) notifyDeletion(imageID string) {
+	if imageManager.deletionNotifier == nil {
+		return
+	}
+	select {
+	case imageManager.deletionNotifier <- imageID:
+	default:
+		seelog.Warnf("Image Manager: dropped deletion notification of image %s", imageID)
 	}
 }
 
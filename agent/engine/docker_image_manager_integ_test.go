@@ -31,7 +31,9 @@ import (
 	apitaskstatus "github.com/aws/amazon-ecs-agent/agent/api/task/status"
 	"github.com/aws/amazon-ecs-agent/agent/dockerclient"
 	"github.com/aws/amazon-ecs-agent/agent/dockerclient/sdkclientfactory"
+	"github.com/aws/amazon-ecs-agent/agent/engine/image"
 	"github.com/aws/amazon-ecs-agent/agent/statemanager"
+	"github.com/aws/amazon-ecs-agent/agent/utils/ttime"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/client"
@@ -269,6 +271,57 @@ func TestIntegImageCleanupThreshold(t *testing.T) {
 	}
 }
 
+// TestIntegImageCleanupWithHooks tests that the image cleanup can be driven
+// through the image cleanup hooks, without waiting for the images to age and
+// without touching images other than the ones under test
+func TestIntegImageCleanupWithHooks(t *testing.T) {
+	cfg := defaultTestConfigIntegTest()
+	cfg.TaskCleanupWaitDuration = 1 * time.Second
+	// The images are only old enough on the clock of the fixture
+	cfg.MinimumImageDeletionAge = 1 * time.Hour
+	cfg.NumImagesToDeletePerCycle = 3
+	taskEngine, done, _ := setup(cfg, nil, t)
+
+	imageManager := taskEngine.(*DockerTaskEngine).imageManager.(*dockerImageManager)
+	imageManager.SetSaver(statemanager.NewNoopStateManager())
+
+	defer func() {
+		done()
+		cleanupImagesHappy(imageManager)
+	}()
+
+	stateChangeEvents := taskEngine.StateChangeEvents()
+	taskName := "imgCleanHooks"
+	testTask := createImageCleanupHappyTestTask(taskName)
+	go taskEngine.AddTask(testTask)
+	err := verifyTaskIsRunning(stateChangeEvents, testTask)
+	require.NoError(t, err)
+
+	var imageIDs []string
+	for _, imageName := range []string{test1Image1Name, test1Image2Name, test1Image3Name} {
+		imageState, ok := imageManager.GetImageStateFromImageName(imageName)
+		require.True(t, ok, "Could not find image state for %s", imageName)
+		imageIDs = append(imageIDs, imageState.Image.ImageID)
+	}
+
+	verifyTaskIsStopped(stateChangeEvents, testTask)
+	testTask.SetSentStatus(apitaskstatus.TaskStopped)
+	err = verifyTaskIsCleanedUp(taskName, taskEngine)
+	require.NoError(t, err)
+
+	// Only the first two images are candidates for deletion
+	deleted := useImageCleanupFixture(imageManager, 2*time.Hour, imageIDs[:2]...)
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	imageManager.removeUnusedImages(ctx)
+
+	deletedIDs, err := waitForImagesDeleted(deleted, 2, imageRemovalTimeout)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, imageIDs[:2], deletedIDs)
+	err = verifyImagesAreNotRemoved(imageManager, imageIDs[2])
+	assert.NoError(t, err)
+}
+
 // TestImageWithSameNameAndDifferentID tests image can be correctly removed when tasks
 // are running with the same image name, but different image id.
 func TestImageWithSameNameAndDifferentID(t *testing.T) {
@@ -617,6 +670,50 @@ func createImageCleanupThresholdTestTask(taskName string) *apitask.Task {
 	}
 }
 
+// offsetClock is a clock that's ahead of the real time by offset
+type offsetClock struct {
+	ttime.DefaultTime
+	offset time.Duration
+}
+
+func (clock *offsetClock) Now() time.Time {
+	return time.Now().Add(clock.offset)
+}
+
+// useImageCleanupFixture makes the images with the given ids the only
+// candidates for deletion of the image manager, and makes them age by the
+// given duration. It returns the channel the ids of removed images are sent to
+func useImageCleanupFixture(hooks ImageCleanupHooks, age time.Duration, imageIDs ...string) <-chan string {
+	candidates := make(map[string]struct{})
+	for _, imageID := range imageIDs {
+		candidates[imageID] = struct{}{}
+	}
+	deleted := make(chan string, len(imageIDs))
+	hooks.SetClock(&offsetClock{offset: age})
+	hooks.SetCandidateFilter(func(imageState *image.ImageState) bool {
+		_, ok := candidates[imageState.Image.ImageID]
+		return ok
+	})
+	hooks.SetDeletionNotifier(deleted)
+	return deleted
+}
+
+// waitForImagesDeleted waits for the ids of count removed images
+func waitForImagesDeleted(deleted <-chan string, count int, timeout time.Duration) ([]string, error) {
+	var imageIDs []string
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for len(imageIDs) < count {
+		select {
+		case imageID := <-deleted:
+			imageIDs = append(imageIDs, imageID)
+		case <-timer.C:
+			return imageIDs, fmt.Errorf("timed out waiting for images to be removed, removed: %v", imageIDs)
+		}
+	}
+	return imageIDs, nil
+}
+
 func verifyTaskIsCleanedUp(taskName string, taskEngine TaskEngine) error {
 	for i := 0; i < taskCleanupTimeoutSeconds; i++ {
 		_, ok := taskEngine.(*DockerTaskEngine).State().TaskByArn(taskName)
@@ -32,6 +32,7 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/engine/dockerstate"
 	"github.com/aws/amazon-ecs-agent/agent/engine/image"
 	"github.com/aws/amazon-ecs-agent/agent/statemanager"
+	mock_ttime "github.com/aws/amazon-ecs-agent/agent/utils/ttime/mocks"
 
 	"github.com/docker/docker/api/types"
 	"github.com/golang/mock/gomock"
@@ -444,6 +445,66 @@ func TestAddContainerReferenceToNewImageStateAddedState(t *testing.T) {
 	}
 }
 
+func TestHandleImageDeleteEvent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock_dockerapi.NewMockDockerClient(ctrl)
+
+	state := dockerstate.NewTaskEngineState()
+	imageManager := &dockerImageManager{client: client, state: state}
+	imageManager.SetSaver(statemanager.NewNoopStateManager())
+	imageState := &image.ImageState{
+		Image: &image.Image{ImageID: "sha256:qwerty", Names: []string{"myContainerImage"}},
+	}
+	imageManager.addImageState(imageState)
+	state.AddImageState(imageState)
+
+	imageManager.handleImageEvent(dockerapi.DockerImageEvent{Action: imageDeleteEvent, ImageID: "sha256:qwerty"})
+	assert.Empty(t, imageManager.getAllImageStates())
+	assert.Empty(t, state.AllImageStates())
+}
+
+func TestHandleImageUntagEvent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock_dockerapi.NewMockDockerClient(ctrl)
+
+	imageManager := &dockerImageManager{client: client, state: dockerstate.NewTaskEngineState()}
+	imageManager.SetSaver(statemanager.NewNoopStateManager())
+	imageState := &image.ImageState{
+		Image: &image.Image{ImageID: "sha256:qwerty", Names: []string{"image1", "image2:v1"}},
+	}
+	imageManager.addImageState(imageState)
+
+	client.EXPECT().InspectImage("sha256:qwerty").Return(&types.ImageInspect{
+		ID:       "sha256:qwerty",
+		RepoTags: []string{"image1:latest"},
+	}, nil)
+	imageManager.handleImageEvent(dockerapi.DockerImageEvent{Action: imageUntagEvent, ImageID: "sha256:qwerty"})
+	assert.Equal(t, []string{"image1"}, imageState.Image.Names)
+	assert.Len(t, imageManager.getAllImageStates(), 1)
+
+	client.EXPECT().InspectImage("sha256:qwerty").Return(nil, errors.New("Error: No such image: sha256:qwerty"))
+	imageManager.handleImageEvent(dockerapi.DockerImageEvent{Action: imageUntagEvent, ImageID: "sha256:qwerty"})
+	assert.Empty(t, imageManager.getAllImageStates())
+}
+
+func TestHandleImageEventUnknownImage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock_dockerapi.NewMockDockerClient(ctrl)
+
+	imageManager := &dockerImageManager{client: client, state: dockerstate.NewTaskEngineState()}
+	imageManager.SetSaver(statemanager.NewNoopStateManager())
+	imageState := &image.ImageState{
+		Image: &image.Image{ImageID: "sha256:qwerty", Names: []string{"myContainerImage"}},
+	}
+	imageManager.addImageState(imageState)
+
+	imageManager.handleImageEvent(dockerapi.DockerImageEvent{Action: imageDeleteEvent, ImageID: "sha256:other"})
+	assert.Len(t, imageManager.getAllImageStates(), 1)
+}
+
 func TestRemoveContainerReferenceFromInvalidImageState(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -728,6 +789,17 @@ func TestImageCleanupExclusionListWithSingleName(t *testing.T) {
 	}
 }
 
+func TestExcludeImagesFromCleanup(t *testing.T) {
+	imageManager := &dockerImageManager{
+		imageCleanupExclusionList: []string{"a"},
+	}
+	imageManager.ExcludeImagesFromCleanup([]string{"a", "pause:0.1.0"})
+	assert.Equal(t, []string{"a", "pause:0.1.0"}, imageManager.imageCleanupExclusionList)
+	assert.True(t, imageManager.isExcludedFromCleanup(&image.ImageState{
+		Image: &image.Image{ImageID: "sha256:qwerty1", Names: []string{"pause:0.1.0"}},
+	}))
+}
+
 func TestImageCleanupExclusionListWithMultipleNames(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -1025,6 +1097,76 @@ func TestImageCleanupCannotRemoveImage(t *testing.T) {
 	}
 }
 
+func TestImageCleanupQuarantinesImageAfterRepeatedFailures(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock_dockerapi.NewMockDockerClient(ctrl)
+
+	imageManager := &dockerImageManager{
+		client:                   client,
+		state:                    dockerstate.NewTaskEngineState(),
+		minimumAgeBeforeDeletion: config.DefaultImageDeletionAge,
+		numImagesToDelete:        config.DefaultNumImagesToDeletePerCycle,
+		imageCleanupTimeInterval: config.DefaultImageCleanupTimeInterval,
+	}
+	imageManager.SetSaver(statemanager.NewNoopStateManager())
+
+	imageState := &image.ImageState{
+		Image:      &image.Image{ImageID: "sha256:qwerty", Names: []string{"testContainerImage"}},
+		PulledAt:   time.Now().AddDate(0, -2, 0),
+		LastUsedAt: time.Now().AddDate(0, -2, 0),
+	}
+	imageManager.addImageState(imageState)
+
+	// The image is removed once per cleanup cycle until it's quarantined
+	client.EXPECT().RemoveImage(gomock.Any(), "testContainerImage", dockerclient.RemoveImageTimeout).Return(
+		errors.New("conflict: unable to delete image, image is being used by a running container")).Times(imageDeletionFailuresBeforeQuarantine)
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	for i := 0; i < imageDeletionFailuresBeforeQuarantine+2; i++ {
+		imageManager.removeUnusedImages(ctx)
+	}
+
+	assert.Equal(t, imageDeletionFailuresBeforeQuarantine, imageState.GetDeletionFailures())
+	assert.False(t, imageState.GetQuarantinedAt().IsZero())
+	errs := imageState.GetErrors()
+	require.Len(t, errs, imageDeletionFailuresBeforeQuarantine)
+	assert.Equal(t, image.DeleteOperation, errs[0].Operation)
+	assert.Contains(t, errs[0].Error, "being used by a running container")
+	assert.Len(t, imageManager.imageStates, 1)
+}
+
+func TestImageCleanupRetriesQuarantinedImageAfterQuarantine(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock_dockerapi.NewMockDockerClient(ctrl)
+
+	imageManager := &dockerImageManager{
+		client:                   client,
+		state:                    dockerstate.NewTaskEngineState(),
+		minimumAgeBeforeDeletion: config.DefaultImageDeletionAge,
+		numImagesToDelete:        config.DefaultNumImagesToDeletePerCycle,
+		imageCleanupTimeInterval: config.DefaultImageCleanupTimeInterval,
+	}
+	imageManager.SetSaver(statemanager.NewNoopStateManager())
+
+	imageState := &image.ImageState{
+		Image:            &image.Image{ImageID: "sha256:qwerty", Names: []string{"testContainerImage"}},
+		PulledAt:         time.Now().AddDate(0, -2, 0),
+		LastUsedAt:       time.Now().AddDate(0, -2, 0),
+		DeletionFailures: imageDeletionFailuresBeforeQuarantine,
+		QuarantinedAt:    time.Now().Add(-imageQuarantineDuration - time.Minute),
+	}
+	imageManager.addImageState(imageState)
+
+	client.EXPECT().RemoveImage(gomock.Any(), "testContainerImage", dockerclient.RemoveImageTimeout).Return(nil)
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	imageManager.removeUnusedImages(ctx)
+
+	assert.Empty(t, imageManager.imageStates)
+}
+
 func TestImageCleanupRemoveImageById(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -1652,6 +1794,54 @@ func TestNonECSImageAndContainersCleanup_DoNotRemoveNewlyCreatedContainer(t *tes
 	assert.Len(t, imageManager.imageStates, 0, "Error removing image state after the image is removed")
 }
 
+func TestImageCleanupHooks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock_dockerapi.NewMockDockerClient(ctrl)
+	mockTime := mock_ttime.NewMockTime(ctrl)
+
+	imageManager := &dockerImageManager{
+		client:                   client,
+		state:                    dockerstate.NewTaskEngineState(),
+		minimumAgeBeforeDeletion: time.Hour,
+		numImagesToDelete:        config.DefaultNumImagesToDeletePerCycle,
+	}
+	imageManager.SetSaver(statemanager.NewNoopStateManager())
+	now := time.Now()
+	for _, name := range []string{"image1", "image2"} {
+		imageState := &image.ImageState{
+			Image:      &image.Image{ImageID: "sha256:" + name, Names: []string{name}},
+			PulledAt:   now,
+			LastUsedAt: now,
+		}
+		imageManager.addImageState(imageState)
+		imageManager.state.AddImageState(imageState)
+	}
+
+	// The images are only old enough to be deleted on the clock of the image manager
+	mockTime.EXPECT().Now().Return(now.Add(2 * time.Hour)).AnyTimes()
+	imageManager.SetClock(mockTime)
+	imageManager.SetCandidateFilter(func(imageState *image.ImageState) bool {
+		return imageState.Image.ImageID == "sha256:image1"
+	})
+	deleted := make(chan string, 1)
+	imageManager.SetDeletionNotifier(deleted)
+
+	client.EXPECT().RemoveImage(gomock.Any(), "image1", dockerclient.RemoveImageTimeout).Return(nil)
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	imageManager.removeUnusedImages(ctx)
+
+	select {
+	case imageID := <-deleted:
+		assert.Equal(t, "sha256:image1", imageID)
+	default:
+		t.Fatal("Expected a deletion notification for the removed image")
+	}
+	_, ok := imageManager.getImageState("sha256:image2")
+	assert.True(t, ok, "Image filtered out of the candidates for deletion should not be removed")
+}
+
 func TestDeleteImage(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
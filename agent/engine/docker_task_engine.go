@@ -35,11 +35,21 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/credentials"
 	"github.com/aws/amazon-ecs-agent/agent/dockerclient"
 	"github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi"
+	"github.com/aws/amazon-ecs-agent/agent/ecr"
 	"github.com/aws/amazon-ecs-agent/agent/ecscni"
 	"github.com/aws/amazon-ecs-agent/agent/engine/dependencygraph"
 	"github.com/aws/amazon-ecs-agent/agent/engine/dockerstate"
+	"github.com/aws/amazon-ecs-agent/agent/engine/execcmd"
+	"github.com/aws/amazon-ecs-agent/agent/engine/image"
+	"github.com/aws/amazon-ecs-agent/agent/errorbudget"
 	"github.com/aws/amazon-ecs-agent/agent/eventstream"
+	"github.com/aws/amazon-ecs-agent/agent/experiments"
+	"github.com/aws/amazon-ecs-agent/agent/gpu"
+	"github.com/aws/amazon-ecs-agent/agent/hostport"
+	"github.com/aws/amazon-ecs-agent/agent/imageverifier"
+	"github.com/aws/amazon-ecs-agent/agent/localdns"
 	"github.com/aws/amazon-ecs-agent/agent/metrics"
+	"github.com/aws/amazon-ecs-agent/agent/portforward"
 	"github.com/aws/amazon-ecs-agent/agent/statechange"
 	"github.com/aws/amazon-ecs-agent/agent/statemanager"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource"
@@ -51,6 +61,7 @@ import (
 	dockercontainer "github.com/docker/docker/api/types/container"
 
 	"github.com/cihub/seelog"
+	"github.com/docker/distribution/reference"
 	"github.com/docker/docker/api/types"
 	"github.com/pkg/errors"
 )
@@ -95,6 +106,10 @@ const (
 	fluentNetworkPort      = "FLUENT_PORT"
 	FluentNetworkPortValue = "24224"
 	FluentAWSVPCHostValue  = "127.0.0.1"
+
+	// maxPullAuthRetries is the number of times an image pull rejected by the
+	// registry is retried with refreshed credentials
+	maxPullAuthRetries = 2
 )
 
 // DockerTaskEngine is a state machine for managing a task and its containers
@@ -155,9 +170,47 @@ type DockerTaskEngine struct {
 
 	resourceFields *taskresource.ResourceFields
 
+	// pullThroughCacheResolver rewrites images from upstream registries that
+	// have an ECR pull through cache rule configured on the instance
+	pullThroughCacheResolver *ecr.PullThroughCacheResolver
+	// imageVerifier verifies the images of task containers before they're
+	// created, if image verification is enabled
+	imageVerifier imageverifier.Verifier
+	// execCmdMgr runs the ExecuteCommandAgent in the containers that ask for it
+	execCmdMgr execcmd.Manager
+
+	// gpuAllocator assigns GPUs to the containers that require them
+	gpuAllocator     *gpu.Allocator
+	gpuAllocatorOnce sync.Once
+	// hostPortAllocator assigns the host ports of the dynamic host port range
+	// to the port mappings without a host port, when the range is configured
+	hostPortAllocator     *hostport.Allocator
+	hostPortAllocatorOnce sync.Once
+	// portForwarder publishes ports of awsvpc tasks on the IP address of the
+	// instance, see SetPortPublishingAddress
+	portForwarder portforward.Forwarder
+	// ephemeralStorageChecked and ephemeralStorageErr keep whether docker
+	// supports the ephemeral storage limits of tasks, see ephemeralStorageSupport
+	ephemeralStorageLock    sync.Mutex
+	ephemeralStorageChecked bool
+	ephemeralStorageErr     error
+
 	// handleDelay is a function used to delay cleanup. Implementation is
 	// swappable for testing
 	handleDelay func(duration time.Duration)
+
+	// restoredTasks are the tasks restored from the state file when the
+	// engine was initialized
+	restoredTasks []*apitask.Task
+	// drainingReason is why the instance is draining, set while the engine
+	// rejects new tasks. It's guarded by tasksLock
+	drainingReason string
+	// stoppingDrainedTasks is set once DrainInstanceWithGracePeriods stops
+	// the tasks of the engine. It's guarded by tasksLock
+	stoppingDrainedTasks bool
+	// launchLimiter throttles how many tasks are launched at the same time
+	// and how fast, nil if task launches aren't limited
+	launchLimiter *taskLaunchLimiter
 }
 
 // NewDockerTaskEngine returns a created, but uninitialized, DockerTaskEngine.
@@ -187,14 +240,25 @@ func NewDockerTaskEngine(cfg *config.Config,
 
 		containerChangeEventStream: containerChangeEventStream,
 		imageManager:               imageManager,
-		cniClient:                  ecscni.NewClient(cfg.CNIPluginsPath),
+		cniClient:                  ecscni.NewBoundedClient(ecscni.NewClient(cfg.CNIPluginsPath), cfg.CNIMaxConcurrency),
 
 		metadataManager:             metadataManager,
 		taskSteadyStatePollInterval: defaultTaskSteadyStatePollInterval,
 		resourceFields:              resourceFields,
 		handleDelay:                 time.Sleep,
+		execCmdMgr:                  execcmd.NewManager(),
+		launchLimiter:               newTaskLaunchLimiter(cfg),
+	}
+
+	pullThroughCacheResolver, err := ecr.NewPullThroughCacheResolver(cfg.PullThroughCacheRules)
+	if err != nil {
+		seelog.Errorf("Task engine: unable to use ECR pull through cache rules, images will be pulled from their upstream registries: %v", err)
+	} else {
+		dockerTaskEngine.pullThroughCacheResolver = pullThroughCacheResolver
 	}
 
+	dockerTaskEngine.imageVerifier = imageverifier.NewVerifier(cfg, client)
+
 	dockerTaskEngine.initializeContainerStatusToTransitionFunction()
 
 	return dockerTaskEngine
@@ -313,15 +377,18 @@ func (engine *DockerTaskEngine) synchronizeState() {
 	}
 	eniAttachments := engine.state.AllENIAttachments()
 	for _, eniAttachment := range eniAttachments {
+		// The timeout function looks the attachment up by its own mac address,
+		// not by the loop variable's, which is reused across iterations
+		mac := eniAttachment.MACAddress
 		timeoutFunc := func() {
-			eniAttachment, ok := engine.state.ENIByMac(eniAttachment.MACAddress)
+			eniAttachment, ok := engine.state.ENIByMac(mac)
 			if !ok {
-				seelog.Warnf("Ignoring unmanaged ENI attachment with MAC address: %s", eniAttachment.MACAddress)
+				seelog.Warnf("Ignoring unmanaged ENI attachment with MAC address: %s", mac)
 				return
 			}
 			if !eniAttachment.IsSent() {
-				seelog.Warnf("Timed out waiting for ENI ack; removing ENI attachment record with MAC address: %s", eniAttachment.MACAddress)
-				engine.state.RemoveENIAttachment(eniAttachment.MACAddress)
+				seelog.Warnf("Timed out waiting for ENI ack; removing ENI attachment record with MAC address: %s", mac)
+				engine.state.RemoveENIAttachment(mac)
 			}
 		}
 		err := eniAttachment.Initialize(timeoutFunc)
@@ -334,10 +401,18 @@ func (engine *DockerTaskEngine) synchronizeState() {
 	}
 
 	tasks := engine.state.AllTasks()
+	engine.restoredTasks = tasks
 	tasksToStart := engine.filterTasksToStartUnsafe(tasks)
+	engine.reserveRestoredGPUs(tasks)
+	engine.reserveRestoredHostPorts(tasks)
 	for _, task := range tasks {
 		task.InitializeResources(engine.resourceFields)
 	}
+	if engine.cfg.TaskBridgeNetworkEnabled {
+		engine.removeLeakedTaskNetworks(tasks)
+	}
+	engine.watchRestoredStdoutHealthEvents(tasks)
+	engine.republishRestoredTaskPorts(tasks)
 
 	for _, task := range tasksToStart {
 		engine.startTask(task)
@@ -404,7 +479,7 @@ func updateContainerMetadata(metadata *dockerapi.DockerContainerMetadata, contai
 		container.SetKnownPortBindings(metadata.PortBindings)
 	}
 	// update the container health information
-	if container.HealthStatusShouldBeReported() {
+	if container.HealthStatusFromDocker() {
 		container.SetHealthStatus(metadata.Health)
 	}
 	container.SetNetworkMode(metadata.NetworkMode)
@@ -461,8 +536,16 @@ func (engine *DockerTaskEngine) synchronizeContainerStatus(container *apicontain
 		}
 	}
 	if currentState > container.Container.GetKnownStatus() {
-		// update the container known status
-		container.Container.SetKnownStatus(currentState)
+		if currentState == apicontainerstatus.ContainerStopped && metadata.Error == nil &&
+			containerRestartable(task, container.Container) {
+			// Leave the container as running so that the task's steady state
+			// check restarts it under its restart policy
+			seelog.Infof("Task engine [%s]: container [%s] exited while the agent was down; it will be restarted",
+				task.Arn, container.Container.Name)
+		} else {
+			// update the container known status
+			container.Container.SetKnownStatus(currentState)
+		}
 	}
 	// Update task ExecutionStoppedAt timestamp
 	task.RecordExecutionStoppedAt(container.Container)
@@ -499,45 +582,30 @@ func (engine *DockerTaskEngine) checkTaskState(task *apitask.Task) {
 	}
 }
 
-// sweepTask deletes all the containers associated with a task
+// sweepTask deletes all the containers associated with a task. Containers are
+// removed concurrently, after which their image references, the metadata and
+// the runtime settings of the task are cleaned up. A failure in any of these
+// doesn't prevent the rest from being cleaned up
 func (engine *DockerTaskEngine) sweepTask(task *apitask.Task) {
-	for _, cont := range task.Containers {
-		err := engine.removeContainer(task, cont)
-		if err != nil {
-			seelog.Infof("Task engine [%s]: unable to remove old container [%s]: %v",
-				task.Arn, cont.Name, err)
-		}
-		// Internal container(created by ecs-agent) state isn't recorded
-		if cont.IsInternal() {
-			continue
-		}
-		err = engine.imageManager.RemoveContainerReferenceFromImageState(cont)
-		if err != nil {
-			seelog.Errorf("Task engine [%s]: Unable to remove container [%s] reference from image state: %v",
-				task.Arn, cont.Name, err)
-		}
-	}
-
-	// Clean metadata directory for task
-	if engine.cfg.ContainerMetadataEnabled {
-		err := engine.metadataManager.Clean(task.Arn)
-		if err != nil {
-			seelog.Warnf("Task engine [%s]: clean task metadata failed: %v", task.Arn, err)
-		}
+	var failed []string
+	failed = append(failed, engine.runCleanupSteps(task, engine.containerRemovalSteps(task), true)...)
+	failed = append(failed, engine.runCleanupSteps(task, engine.imageDereferenceSteps(task), false)...)
+	failed = append(failed, engine.runCleanupSteps(task, engine.metadataCleanupSteps(task), false)...)
+	failed = append(failed, engine.runCleanupSteps(task, engine.runtimeSettingsCleanupSteps(task), false)...)
+	if len(failed) > 0 {
+		seelog.Warnf("Task engine [%s]: unable to complete cleanup steps: [%s]",
+			task.Arn, strings.Join(failed, ", "))
 	}
 	engine.saver.Save()
 }
 
+// deleteTask cleans up the resources of the task, such as its volumes and
+// networks, and then removes the task from the engine state
 func (engine *DockerTaskEngine) deleteTask(task *apitask.Task) {
-	for _, resource := range task.GetResources() {
-		err := resource.Cleanup()
-		if err != nil {
-			seelog.Warnf("Task engine [%s]: unable to cleanup resource %s: %v",
-				task.Arn, resource.GetName(), err)
-		} else {
-			seelog.Infof("Task engine [%s]: resource %s cleanup complete", task.Arn,
-				resource.GetName())
-		}
+	failed := engine.runCleanupSteps(task, engine.resourceCleanupSteps(task), false)
+	if len(failed) > 0 {
+		seelog.Warnf("Task engine [%s]: unable to cleanup resources: [%s]",
+			task.Arn, strings.Join(failed, ", "))
 	}
 
 	// Now remove ourselves from the global state and cleanup channels
@@ -570,6 +638,11 @@ func (engine *DockerTaskEngine) emitTaskEvent(task *apitask.Task, reason string)
 		seelog.Infof("Task engine [%s]: unable to create task state change event: %v", task.Arn, err)
 		return
 	}
+	if task.IsStatic() {
+		// ECS doesn't know about static tasks, their changes are only recorded
+		task.SetSentStatus(event.Status)
+		return
+	}
 
 	seelog.Infof("Task engine [%s]: Task engine: sending change event [%s]", task.Arn, event.String())
 	engine.stateChangeEvents <- event
@@ -646,7 +719,7 @@ func (engine *DockerTaskEngine) handleDockerEvent(event dockerapi.DockerContaine
 	// Container health status change does not affect the container status
 	// no need to process this in task manager
 	if event.Type == apicontainer.ContainerHealthEvent {
-		if cont.Container.HealthStatusShouldBeReported() {
+		if cont.Container.HealthStatusFromDocker() {
 			seelog.Debugf("Task engine: updating container [%s(%s)] health status: %v",
 				cont.Container.Name, cont.DockerID, event.DockerContainerMetadata.Health)
 			cont.Container.SetHealthStatus(event.DockerContainerMetadata.Health)
@@ -680,15 +753,33 @@ func (engine *DockerTaskEngine) StateChangeEvents() chan statechange.Event {
 // AddTask starts tracking a task
 func (engine *DockerTaskEngine) AddTask(task *apitask.Task) {
 	defer metrics.MetricsEngineGlobal.RecordTaskEngineMetric("ADD_TASK")()
+	if err := engine.drainingError(task); err != nil {
+		seelog.Errorf("Task engine [%s]: unable to add task to the engine: %v", task.Arn, err)
+		task.SetKnownStatus(apitaskstatus.TaskStopped)
+		task.SetDesiredStatus(apitaskstatus.TaskStopped)
+		engine.emitTaskEvent(task, taskRejectedReason(err))
+		return
+	}
 	err := task.PostUnmarshalTask(engine.cfg, engine.credentialsManager,
 		engine.resourceFields, engine.client, engine.ctx)
 	if err != nil {
 		seelog.Errorf("Task engine [%s]: unable to add task to the engine: %v", task.Arn, err)
 		task.SetKnownStatus(apitaskstatus.TaskStopped)
 		task.SetDesiredStatus(apitaskstatus.TaskStopped)
-		engine.emitTaskEvent(task, err.Error())
+		engine.emitTaskEvent(task, taskRejectedReason(err))
 		return
 	}
+	// The digests are resolved before taking the tasks lock, as resolving them
+	// involves the registries of the images
+	if _, exists := engine.state.TaskByArn(task.Arn); !exists {
+		if err := engine.pinImageDigests(task); err != nil {
+			seelog.Errorf("Task engine [%s]: unable to add task to the engine: %v", task.Arn, err)
+			task.SetKnownStatus(apitaskstatus.TaskStopped)
+			task.SetDesiredStatus(apitaskstatus.TaskStopped)
+			engine.emitTaskEvent(task, taskRejectedReason(err))
+			return
+		}
+	}
 
 	engine.tasksLock.Lock()
 	defer engine.tasksLock.Unlock()
@@ -706,7 +797,7 @@ func (engine *DockerTaskEngine) AddTask(task *apitask.Task) {
 			task.SetKnownStatus(apitaskstatus.TaskStopped)
 			task.SetDesiredStatus(apitaskstatus.TaskStopped)
 			err := TaskDependencyError{task.Arn}
-			engine.emitTaskEvent(task, err.Error())
+			engine.emitTaskEvent(task, taskRejectedReason(err))
 		}
 		return
 	}
@@ -732,13 +823,14 @@ func (engine *DockerTaskEngine) pullContainer(task *apitask.Task, container *api
 		return dockerapi.DockerContainerMetadata{}
 	}
 
-	if engine.imagePullRequired(engine.cfg.ImagePullBehavior, container, task.Arn) {
-		// Record the pullStoppedAt timestamp
-		defer func() {
-			timestamp := engine.time().Now()
-			task.SetPullStoppedAt(timestamp)
-		}()
-
+	// Images are always pulled by digest, to make sure the pinned digests are present
+	pullByDigest := engine.cfg.ImagePullDigestMode != config.ImagePullDigestDisabled
+	imagePullBehavior := engine.cfg.ImagePullBehavior
+	if task.IsExperimentEnabled(engine.cfg, experiments.LazyPull) {
+		// Tasks in the lazy pull experiment use the cached images
+		imagePullBehavior = config.ImagePullPreferCachedBehavior
+	}
+	if pullByDigest || engine.imagePullRequired(imagePullBehavior, container, task.Arn) {
 		seelog.Infof("Task engine [%s]: pulling image %s for container %s concurrently", task.Arn, container.Image, container.Name)
 		return engine.concurrentPull(task, container)
 
@@ -802,9 +894,21 @@ func (engine *DockerTaskEngine) concurrentPull(task *apitask.Task, container *ap
 			task.Arn, pullStart)
 	}
 	metadata := engine.pullAndUpdateContainerReference(task, container)
+	// Credentials can expire during long pulls. Each pull retrieves the task's
+	// current credentials and docker keeps the layers that were already
+	// downloaded, so retrying resumes the pull with refreshed credentials
+	for i := 0; i < maxPullAuthRetries && dockerapi.IsPullAuthError(metadata.Error); i++ {
+		seelog.Warnf("Task engine [%s]: registry rejected the credentials pulling image %s for container %s, retrying with refreshed credentials: %v",
+			task.Arn, container.Image, container.Name, metadata.Error)
+		metadata = engine.pullAndUpdateContainerReference(task, container)
+	}
+	// Record the pullStoppedAt timestamp
+	pullStop := engine.time().Now()
+	task.SetPullStoppedAt(pullStop)
 	if metadata.Error == nil {
 		seelog.Infof("Task engine [%s]: finished pulling image %s for container %s in %s",
-			task.Arn, container.Image, container.Name, time.Since(pullStart).String())
+			task.Arn, container.Image, container.Name, pullStop.Sub(pullStart).String())
+		recordImagePullTimes(container, pullStart, pullStop)
 	} else {
 		seelog.Errorf("Task engine [%s]: failed to pull image %s for container %s: %v",
 			task.Arn, container.Image, container.Name, metadata.Error)
@@ -855,18 +959,305 @@ func (engine *DockerTaskEngine) pullAndUpdateContainerReference(task *apitask.Ta
 		defer container.SetASMDockerAuthConfig(types.AuthConfig{})
 	}
 
-	metadata := engine.client.PullImage(engine.ctx, container.Image, container.RegistryAuthentication, dockerclient.PullImageTimeout)
+	// Pin the image to a digest if pulling by digest
+	digest, metadata := engine.pinImageDigest(task, container)
+	if metadata.Error != nil {
+		return metadata
+	}
+
+	// Pull through the ECR cache if the upstream registry has a cache rule
+	cacheImage, cacheAuthData, pullThroughCache := engine.resolvePullThroughCache(task, container)
+	if pullThroughCache {
+		if digest != "" {
+			cacheImage = imageReferenceWithDigest(cacheImage, digest)
+		}
+		metadata := engine.pullThroughCache(task, container, cacheImage, cacheAuthData)
+		pullSucceeded := metadata.Error == nil
+		imageState := engine.updateContainerReference(pullSucceeded, container, task.Arn)
+		if pullSucceeded {
+			engine.recordPullThroughCacheImageName(container, cacheImage)
+			recordImagePullInfo(container, cacheImage, imageState)
+		} else {
+			engine.recordImagePullError(imageState, metadata.Error)
+		}
+		return metadata
+	}
+
+	if digest != "" {
+		metadata = engine.pullByDigest(task, container, digest)
+	} else {
+		metadata = engine.client.PullImage(engine.ctx, container.Image, container.RegistryAuthentication, dockerclient.PullImageTimeout)
+	}
 
 	// Don't add internal images(created by ecs-agent) into imagemanger state
 	if container.IsInternal() {
 		return metadata
 	}
 	pullSucceeded := metadata.Error == nil
-	engine.updateContainerReference(pullSucceeded, container, task.Arn)
+	imageState := engine.updateContainerReference(pullSucceeded, container, task.Arn)
+	if pullSucceeded {
+		recordImagePullInfo(container, container.Image, imageState)
+	} else {
+		engine.recordImagePullError(imageState, metadata.Error)
+	}
+	return metadata
+}
+
+// verifyImage verifies the image of the container with the image verifier, if
+// image verification is enabled
+func (engine *DockerTaskEngine) verifyImage(task *apitask.Task, container *apicontainer.Container) apierrors.NamedError {
+	if engine.imageVerifier == nil || container.IsInternal() {
+		return nil
+	}
+
+	image := imageverifier.Image{
+		TaskARN:       task.Arn,
+		ContainerName: container.Name,
+		Name:          container.Image,
+		Digest:        container.GetImageDigest(),
+		Reference:     container.Image,
+	}
+	if digest, ok := task.GetPinnedImageDigest(container.Image); ok {
+		image.Digest = digest
+	}
+	if image.Digest != "" {
+		image.Reference = imageReferenceWithDigest(container.Image, image.Digest)
+	}
+
+	if err := engine.imageVerifier.Verify(engine.ctx, image); err != nil {
+		seelog.Errorf("Task engine [%s]: image %s for container %s failed verification: %v",
+			task.Arn, image.Reference, container.Name, err)
+		if violation, ok := err.(*imageverifier.PolicyViolation); ok {
+			return ImagePolicyViolationError{image: image.Reference, violation: violation}
+		}
+		return ImageVerificationError{image: image.Reference, err: err}
+	}
+	seelog.Infof("Task engine [%s]: verified image %s for container %s",
+		task.Arn, image.Reference, container.Name)
+	return nil
+}
+
+// pinImageDigests pins the images of a new task to digests when pulling images
+// by digest, so that the containers of the task run the images the task
+// definition pointed to when the task was accepted, however late they start.
+// Images are pinned to the digest of their reference, if any, or to the digest
+// their tag resolves to in the registry. Images pulled with registry
+// credentials from Secrets Manager are pinned when a container first pulls
+// them, as the credentials are only retrieved once the task is started. An
+// error is returned if pulling images by digest is required and an image isn't
+// referenced by digest, or if a tag can't be resolved.
+func (engine *DockerTaskEngine) pinImageDigests(task *apitask.Task) error {
+	if engine.cfg.ImagePullDigestMode == config.ImagePullDigestDisabled {
+		return nil
+	}
+	for _, container := range task.Containers {
+		if container.IsInternal() {
+			continue
+		}
+		if digest, ok := imageReferenceDigest(container.Image); ok {
+			task.PinImageDigest(container.Image, digest)
+			continue
+		}
+		if engine.cfg.ImagePullDigestMode == config.ImagePullDigestRequire {
+			return ImageDigestRequiredError{taskArn: task.Arn, image: container.Image}
+		}
+		if _, ok := task.GetPinnedImageDigest(container.Image); ok || container.ShouldPullWithASMAuth() {
+			continue
+		}
+		if err := engine.pinResolvedImageDigest(task, container); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pinResolvedImageDigest pins the image of the container to the digest its tag
+// resolves to, authenticating with the execution role of the task if the image
+// is pulled with it
+func (engine *DockerTaskEngine) pinResolvedImageDigest(task *apitask.Task, container *apicontainer.Container) error {
+	if container.ShouldPullWithExecutionRole() {
+		executionCredentials, ok := engine.credentialsManager.GetTaskCredentials(task.GetExecutionCredentialsID())
+		if !ok {
+			return dockerapi.CannotPullECRContainerError{
+				FromError: errors.New("engine ecr credentials: not found"),
+			}
+		}
+		container.SetRegistryAuthCredentials(executionCredentials.GetIAMRoleCredentials())
+		defer container.SetRegistryAuthCredentials(credentials.IAMRoleCredentials{})
+	}
+	digest, err := engine.resolveImageDigest(task, container)
+	if err != nil {
+		return err
+	}
+	task.PinImageDigest(container.Image, digest)
+	seelog.Infof("Task engine [%s]: image %s for container %s is pinned to digest %s",
+		task.Arn, container.Image, container.Name, digest)
+	return nil
+}
+
+// pinImageDigest returns the digest the image of the container is pinned to
+// when pulling images by digest. Images are pinned when the task is accepted,
+// except for the images pulled with registry credentials from Secrets Manager
+// and the images of tasks restored from agents that didn't pin them, which are
+// pinned the first time a container of the task pulls them. The pinned digest
+// is saved with the task, so that the same image is used by all the containers
+// of the task, even across agent restarts.
+func (engine *DockerTaskEngine) pinImageDigest(task *apitask.Task,
+	container *apicontainer.Container) (string, dockerapi.DockerContainerMetadata) {
+	if engine.cfg.ImagePullDigestMode == config.ImagePullDigestDisabled || container.IsInternal() {
+		return "", dockerapi.DockerContainerMetadata{}
+	}
+	if digest, ok := task.GetPinnedImageDigest(container.Image); ok {
+		return digest, dockerapi.DockerContainerMetadata{}
+	}
+
+	digest, ok := imageReferenceDigest(container.Image)
+	if !ok {
+		var err apierrors.NamedError
+		digest, err = engine.resolveImageDigest(task, container)
+		if err != nil {
+			return "", dockerapi.DockerContainerMetadata{Error: err}
+		}
+	}
+	digest = task.PinImageDigest(container.Image, digest)
+	seelog.Infof("Task engine [%s]: image %s for container %s is pinned to digest %s",
+		task.Arn, container.Image, container.Name, digest)
+	engine.saver.Save()
+	return digest, dockerapi.DockerContainerMetadata{}
+}
+
+// resolveImageDigest returns the digest the tag of the image of the container
+// points to in the registry
+func (engine *DockerTaskEngine) resolveImageDigest(task *apitask.Task,
+	container *apicontainer.Container) (string, apierrors.NamedError) {
+	digest, err := engine.client.ResolveImageDigest(engine.ctx, container.Image,
+		container.RegistryAuthentication, dockerclient.ResolveImageDigestTimeout)
+	if err != nil {
+		seelog.Errorf("Task engine [%s]: unable to resolve the digest of image %s for container %s: %v",
+			task.Arn, container.Image, container.Name, err)
+		return "", dockerapi.CannotResolveImageDigestError{FromError: err}
+	}
+	return digest, nil
+}
+
+// pullByDigest pulls the image of the container by digest and tags it with the
+// image name of the container, so that the image manager can track it
+func (engine *DockerTaskEngine) pullByDigest(task *apitask.Task, container *apicontainer.Container,
+	digest string) dockerapi.DockerContainerMetadata {
+	image := imageReferenceWithDigest(container.Image, digest)
+	metadata := engine.client.PullImage(engine.ctx, image, container.RegistryAuthentication, dockerclient.PullImageTimeout)
+	// Images referenced by tag and digest are tagged with the tag, as docker
+	// can't tag an image with a digest
+	tag := imageReferenceWithoutDigest(container.Image)
+	if metadata.Error != nil || image == tag {
+		return metadata
+	}
+	if err := engine.client.TagImage(engine.ctx, image, tag, dockerclient.TagImageTimeout); err != nil {
+		seelog.Errorf("Task engine [%s]: unable to tag image %s as %s for container %s: %v",
+			task.Arn, image, tag, container.Name, err)
+		return dockerapi.DockerContainerMetadata{
+			Error: dockerapi.CannotPullContainerError{FromError: err},
+		}
+	}
 	return metadata
 }
 
-func (engine *DockerTaskEngine) updateContainerReference(pullSucceeded bool, container *apicontainer.Container, taskArn string) {
+// resolvePullThroughCache returns the image reference in the ECR pull through
+// cache and the ECR auth data to pull it with. Images with registry auth set by
+// the task definition and internal images are always pulled as is.
+func (engine *DockerTaskEngine) resolvePullThroughCache(task *apitask.Task,
+	container *apicontainer.Container) (string, *apicontainer.ECRAuthData, bool) {
+	if container.IsInternal() || container.RegistryAuthentication != nil {
+		return "", nil, false
+	}
+	cacheImage, authData, ok := engine.pullThroughCacheResolver.Resolve(container.Image)
+	if !ok {
+		return "", nil, false
+	}
+	// Reuse the task execution role to authenticate with ECR if there's one,
+	// otherwise the instance credentials are used
+	if executionCredentials, ok := engine.credentialsManager.GetTaskCredentials(task.GetExecutionCredentialsID()); ok {
+		authData.UseExecutionRole = true
+		authData.SetPullCredentials(executionCredentials.GetIAMRoleCredentials())
+	}
+	return cacheImage, authData, true
+}
+
+// pullThroughCache pulls the image from the ECR pull through cache and tags it
+// with the upstream image name, so that the container can be created from it
+func (engine *DockerTaskEngine) pullThroughCache(task *apitask.Task, container *apicontainer.Container,
+	cacheImage string, authData *apicontainer.ECRAuthData) dockerapi.DockerContainerMetadata {
+	seelog.Infof("Task engine [%s]: pulling image %s for container %s through ECR pull through cache as %s",
+		task.Arn, container.Image, container.Name, cacheImage)
+	registryAuth := &apicontainer.RegistryAuthenticationData{
+		Type:        apicontainer.AuthTypeECR,
+		ECRAuthData: authData,
+	}
+	metadata := engine.client.PullImage(engine.ctx, cacheImage, registryAuth, dockerclient.PullImageTimeout)
+	if metadata.Error != nil {
+		return metadata
+	}
+	if err := engine.client.TagImage(engine.ctx, cacheImage, container.Image, dockerclient.TagImageTimeout); err != nil {
+		seelog.Errorf("Task engine [%s]: unable to tag image %s as %s for container %s: %v",
+			task.Arn, cacheImage, container.Image, container.Name, err)
+		return dockerapi.DockerContainerMetadata{
+			Error: dockerapi.CannotPullContainerError{FromError: err},
+		}
+	}
+	return metadata
+}
+
+// recordPullThroughCacheImageName records the name of the image in the ECR pull
+// through cache along with its upstream name, so that image cleanup removes
+// both names when it removes the image
+func (engine *DockerTaskEngine) recordPullThroughCacheImageName(container *apicontainer.Container, cacheImage string) {
+	imageState, ok := engine.imageManager.GetImageStateFromImageName(container.Image)
+	if !ok {
+		return
+	}
+	imageState.AddImageName(cacheImage)
+	engine.saver.Save()
+}
+
+// recordImagePullInfo records the registry the image of the container was
+// pulled from and the size of the image, which are reported with the state
+// changes of the container
+func recordImagePullInfo(container *apicontainer.Container, pulledImage string, imageState *image.ImageState) {
+	info := &apicontainer.ImagePullInfo{}
+	if named, err := reference.ParseNormalizedNamed(pulledImage); err == nil {
+		info.Registry = reference.Domain(named)
+	}
+	if imageState != nil && imageState.Image != nil {
+		info.Size = imageState.Image.Size
+	}
+	container.SetImagePullInfo(info)
+}
+
+// recordImagePullError adds the error pulling an image to the error history of
+// its state, if the image is already on the instance
+func (engine *DockerTaskEngine) recordImagePullError(imageState *image.ImageState, err error) {
+	if imageState == nil {
+		return
+	}
+	imageState.RecordPullError(err, engine.time().Now())
+	engine.saver.Save()
+}
+
+// recordImagePullTimes records when the agent started and finished pulling the
+// image of the container
+func recordImagePullTimes(container *apicontainer.Container, pullStart, pullStop time.Time) {
+	info := container.GetImagePullInfo()
+	if info == nil {
+		return
+	}
+	updated := *info
+	updated.PullStartedAt = pullStart
+	updated.PullStoppedAt = pullStop
+	container.SetImagePullInfo(&updated)
+}
+
+func (engine *DockerTaskEngine) updateContainerReference(pullSucceeded bool, container *apicontainer.Container,
+	taskArn string) *image.ImageState {
 	err := engine.imageManager.RecordContainerReference(container)
 	if err != nil {
 		seelog.Errorf("Task engine [%s]: unable to add container reference to image state: %v",
@@ -878,10 +1269,58 @@ func (engine *DockerTaskEngine) updateContainerReference(pullSucceeded bool, con
 	}
 	engine.state.AddImageState(imageState)
 	engine.saver.Save()
+	return imageState
+}
+
+// applyAWSVPCDNSDefaults sets the DNS settings of the agent config on the host
+// config of the pause container of an awsvpc task, for the settings the task
+// doesn't override itself
+func (engine *DockerTaskEngine) applyAWSVPCDNSDefaults(task *apitask.Task, hostConfig *dockercontainer.HostConfig) {
+	dnsConfig := task.DNSConfig
+	if dnsConfig == nil {
+		dnsConfig = &apitask.DNSConfig{}
+	}
+	if len(dnsConfig.Nameservers) == 0 && len(engine.cfg.AWSVPCDNSServers) > 0 {
+		hostConfig.DNS = engine.cfg.AWSVPCDNSServers
+	}
+	if len(dnsConfig.SearchDomains) == 0 && len(engine.cfg.AWSVPCDNSSearchDomains) > 0 {
+		hostConfig.DNSSearch = engine.cfg.AWSVPCDNSSearchDomains
+	}
+	if len(dnsConfig.Options) == 0 && len(engine.cfg.AWSVPCDNSOptions) > 0 {
+		hostConfig.DNSOptions = engine.cfg.AWSVPCDNSOptions
+	}
+}
+
+// applyLocalDNS makes the container query the local DNS server first, so that
+// it resolves the names of the ecs.local zone. Only containers on a docker
+// network reach the server on the instance: the containers of awsvpc tasks
+// reach the VPC instead, and the containers sharing the network namespace of
+// another container or of the instance can't set their nameservers
+func (engine *DockerTaskEngine) applyLocalDNS(task *apitask.Task, container *apicontainer.Container,
+	hostConfig *dockercontainer.HostConfig) {
+	networkMode := hostConfig.NetworkMode
+	if container.IsInternal() || task.IsNetworkModeAWSVPC() ||
+		networkMode.IsHost() || networkMode.IsNone() || networkMode.IsContainer() {
+		return
+	}
+	nameservers, err := localdns.ContainerNameservers(engine.cfg.LocalDNSListenAddress, hostConfig.DNS)
+	if err != nil {
+		seelog.Warnf("Task engine [%s]: container %s can't query the local DNS server: %v",
+			task.Arn, container.Name, err)
+		return
+	}
+	hostConfig.DNS = nameservers
 }
 
 func (engine *DockerTaskEngine) createContainer(task *apitask.Task, container *apicontainer.Container) dockerapi.DockerContainerMetadata {
 	seelog.Infof("Task engine [%s]: creating container: %s", task.Arn, container.Name)
+	if err := engine.verifyImage(task, container); err != nil {
+		return dockerapi.DockerContainerMetadata{Error: err}
+	}
+	if err := engine.assignGPUs(task, container); err != nil {
+		return dockerapi.DockerContainerMetadata{Error: GPUAllocationError{container: container.Name, err: err}}
+	}
+
 	client := engine.client
 	if container.DockerConfig.Version != nil {
 		client = client.WithVersion(dockerclient.DockerVersion(*container.DockerConfig.Version))
@@ -913,6 +1352,19 @@ func (engine *DockerTaskEngine) createContainer(task *apitask.Task, container *a
 	if hcerr != nil {
 		return dockerapi.DockerContainerMetadata{Error: apierrors.NamedError(hcerr)}
 	}
+	if err := engine.assignDynamicHostPorts(task, container, hostConfig); err != nil {
+		return dockerapi.DockerContainerMetadata{Error: HostPortAllocationError{container: container.Name, err: err}}
+	}
+
+	// Other containers in an awsvpc task share the resolv.conf of the pause
+	// container, so the DNS settings only need to be set for it
+	if container.Type == apicontainer.ContainerCNIPause {
+		engine.applyAWSVPCDNSDefaults(task, hostConfig)
+	}
+
+	if engine.cfg.LocalDNSEnabled {
+		engine.applyLocalDNS(task, container, hostConfig)
+	}
 
 	if container.AWSLogAuthExecutionRole() {
 		err := task.ApplyExecutionRoleLogsAuth(hostConfig, engine.credentialsManager)
@@ -955,7 +1407,8 @@ func (engine *DockerTaskEngine) createContainer(task *apitask.Task, container *a
 				fluentNetworkPort: FluentNetworkPortValue,
 			})
 		} else if container.GetNetworkModeFromHostConfig() == "" || container.GetNetworkModeFromHostConfig() == apitask.BridgeNetworkMode {
-			ipAddress, ok := getContainerHostIP(task.GetFirelensContainer().GetNetworkSettings())
+			ipAddress, ok := getContainerHostIP(task.GetFirelensContainer().GetNetworkSettings(),
+				bridgeNetworkName(task))
 			if !ok {
 				err := apierrors.DockerClientConfigError{Msg: "unable to get BridgeIP for task in bridge  mode"}
 				return dockerapi.DockerContainerMetadata{Error: apierrors.NamedError(&err)}
@@ -967,6 +1420,13 @@ func (engine *DockerTaskEngine) createContainer(task *apitask.Task, container *a
 		}
 	}
 
+	// Validate the log options of the logging drivers known to the agent, such as journald,
+	// before creating the container
+	if err := dockerclient.ValidateLoggingDriverOptions(dockerclient.LoggingDriver(hostConfig.LogConfig.Type),
+		hostConfig.LogConfig.Config); err != nil {
+		return dockerapi.DockerContainerMetadata{Error: apierrors.NamedError(&apierrors.DockerClientConfigError{Msg: err.Error()})}
+	}
+
 	//Apply the log driver secret into container's LogConfig and Env secrets to container.Environment
 	hasSecretAsEnvOrLogDriver := func(s apicontainer.Secret) bool {
 		return s.Type == apicontainer.SecretTypeEnv || s.Target == apicontainer.SecretTargetLogDriver
@@ -984,6 +1444,12 @@ func (engine *DockerTaskEngine) createContainer(task *apitask.Task, container *a
 		return dockerapi.DockerContainerMetadata{Error: apierrors.NamedError(err)}
 	}
 
+	// Create the container from the pinned digest, as the image tag may have
+	// been moved by other pulls since
+	if digest, ok := task.GetPinnedImageDigest(container.Image); ok {
+		config.Image = imageReferenceWithDigest(container.Image, digest)
+	}
+
 	// Augment labels with some metadata from the agent. Explicitly do this last
 	// such that it will always override duplicates in the provided raw config
 	// data.
@@ -993,6 +1459,36 @@ func (engine *DockerTaskEngine) createContainer(task *apitask.Task, container *a
 	config.Labels[labelTaskDefinitionVersion] = task.Version
 	config.Labels[labelCluster] = engine.cfg.Cluster
 
+	applySecurityBaseline(engine.cfg, task, container, config, hostConfig)
+
+	if err := applyKernelCapabilities(engine.cfg, container, hostConfig); err != nil {
+		return dockerapi.DockerContainerMetadata{Error: apierrors.NamedError(&apierrors.DockerClientConfigError{Msg: err.Error()})}
+	}
+
+	applyDefaultUlimits(engine.cfg, hostConfig)
+
+	applyOOMScoreAdj(engine.cfg, task, container, hostConfig)
+
+	if err := checkHostSwap(container); err != nil {
+		return dockerapi.DockerContainerMetadata{Error: apierrors.NamedError(&apierrors.DockerClientConfigError{Msg: err.Error()})}
+	}
+
+	if err := engine.applyRuntimeSettingsMount(task, container, config, hostConfig); err != nil {
+		return dockerapi.DockerContainerMetadata{Error: apierrors.NamedError(&apierrors.DockerClientConfigError{Msg: err.Error()})}
+	}
+
+	if err := applyIsolationMode(engine.cfg, task, container, hostConfig); err != nil {
+		return dockerapi.DockerContainerMetadata{Error: apierrors.NamedError(&apierrors.DockerClientConfigError{Msg: err.Error()})}
+	}
+
+	if err := engine.applyGPUComputeMode(task, container); err != nil {
+		return dockerapi.DockerContainerMetadata{Error: apierrors.NamedError(&apierrors.DockerClientConfigError{Msg: err.Error()})}
+	}
+
+	if err := engine.applyEphemeralStorage(task, container, hostConfig); err != nil {
+		return dockerapi.DockerContainerMetadata{Error: apierrors.NamedError(&apierrors.DockerClientConfigError{Msg: err.Error()})}
+	}
+
 	if dockerContainerName == "" {
 		// only alphanumeric and hyphen characters are allowed
 		reInvalidChars := regexp.MustCompile("[^A-Za-z0-9-]+")
@@ -1023,8 +1519,26 @@ func (engine *DockerTaskEngine) createContainer(task *apitask.Task, container *a
 		}
 	}
 
+	// Failing to set up the ExecuteCommandAgent doesn't fail the container, the
+	// agent is reported as stopped instead
+	if err := engine.execCmdMgr.InitializeContainer(task, container, hostConfig); err != nil {
+		seelog.Warnf("Task engine [%s]: unable to set up %s for container %s: %v",
+			task.Arn, execcmd.ExecuteCommandAgentName, container.Name, err)
+	}
+
+	// Log the values of the task definition along with the ones the container
+	// runs with, so that one-off overrides can be audited
+	if container.HasCommandOverrides() {
+		var entryPoint []string
+		if container.EntryPoint != nil {
+			entryPoint = *container.EntryPoint
+		}
+		seelog.Infof("Task engine [%s]: container %s overridden at run time: command %q -> %q, entrypoint %q -> %q",
+			task.Arn, container.Name, container.Command, []string(config.Cmd), entryPoint, []string(config.Entrypoint))
+	}
+
 	createContainerBegin := time.Now()
-	metadata := client.CreateContainer(engine.ctx, config, hostConfig,
+	metadata := client.CreateContainer(engine.ctx, config, hostConfig, taskNetworkingConfig(task, container, hostConfig),
 		dockerContainerName, dockerclient.CreateContainerTimeout)
 	if metadata.DockerID != "" {
 		seelog.Infof("Task engine [%s]: created docker container for task: %s -> %s",
@@ -1079,6 +1593,10 @@ func (engine *DockerTaskEngine) startContainer(task *apitask.Task, container *ap
 			},
 		}
 	}
+	if engine.cfg.ContainerMetadataStartDependency && !container.IsInternal() {
+		engine.waitForStartDependencies(task, container)
+	}
+
 	startContainerBegin := time.Now()
 	dockerContainerMD := client.StartContainer(engine.ctx, dockerContainer.DockerID, engine.cfg.ContainerStartTimeout)
 
@@ -1104,12 +1622,17 @@ func (engine *DockerTaskEngine) startContainer(task *apitask.Task, container *ap
 	seelog.Infof("Task engine [%s]: started docker container for task: %s -> %s, took %s",
 		task.Arn, container.Name, dockerContainerMD.DockerID, time.Since(startContainerBegin))
 
+	if dockerContainerMD.Error == nil {
+		engine.startExecuteCommandAgent(task, container, dockerContainer.DockerID)
+		engine.watchStdoutHealthEvents(task, container, dockerContainer.DockerID, dockerContainerMD.StartedAt)
+	}
+
 	// If container is a firelens container, fluent host is needed to be added to the environment variable for the task.
 	// For the supported network mode - bridge and awsvpc, the awsvpc take the host 127.0.0.1 but in bridge mode,
 	// there is a need to wait for the IP to be present before the container using the firelens can be created.
 	if dockerContainerMD.Error == nil && container.GetFirelensConfig() != nil {
 		if !task.IsNetworkModeAWSVPC() && (container.GetNetworkModeFromHostConfig() == "" || container.GetNetworkModeFromHostConfig() == apitask.BridgeNetworkMode) {
-			_, gotContainerIP := getContainerHostIP(dockerContainerMD.NetworkSettings)
+			_, gotContainerIP := getContainerHostIP(dockerContainerMD.NetworkSettings, bridgeNetworkName(task))
 			if !gotContainerIP {
 				getIPBridgeBackoff := retry.NewExponentialBackoff(minGetIPBridgeTimeout, maxGetIPBridgeTimeout, getIPBridgeRetryJitterMultiplier, getIPBridgeRetryDelayMultiplier)
 				contextWithTimeout, cancel := context.WithTimeout(engine.ctx, time.Minute)
@@ -1120,7 +1643,7 @@ func (engine *DockerTaskEngine) startContainer(task *apitask.Task, container *ap
 					if err != nil {
 						return err
 					}
-					_, gotIPBridge := getContainerHostIP(inspectOutput.NetworkSettings)
+					_, gotIPBridge := getContainerHostIP(inspectOutput.NetworkSettings, bridgeNetworkName(task))
 					if gotIPBridge {
 						dockerContainerMD.NetworkSettings = inspectOutput.NetworkSettings
 						return nil
@@ -1140,6 +1663,21 @@ func (engine *DockerTaskEngine) startContainer(task *apitask.Task, container *ap
 	return dockerContainerMD
 }
 
+// startExecuteCommandAgent starts the ExecuteCommandAgent in the running
+// container if it asks for it. Failing to start the agent doesn't fail the
+// container, the agent is reported as stopped instead
+func (engine *DockerTaskEngine) startExecuteCommandAgent(task *apitask.Task, container *apicontainer.Container,
+	dockerID string) {
+	if _, ok := container.GetManagedAgentByName(execcmd.ExecuteCommandAgentName); !ok {
+		return
+	}
+	if err := engine.execCmdMgr.StartAgent(engine.ctx, engine.client, task, container, dockerID); err != nil {
+		seelog.Warnf("Task engine [%s]: unable to start %s in container %s: %v",
+			task.Arn, execcmd.ExecuteCommandAgentName, container.Name, err)
+	}
+	engine.saver.Save()
+}
+
 func (engine *DockerTaskEngine) provisionContainerResources(task *apitask.Task, container *apicontainer.Container) dockerapi.DockerContainerMetadata {
 	seelog.Infof("Task engine [%s]: setting up container resources for container [%s]",
 		task.Arn, container.Name)
@@ -1152,8 +1690,12 @@ func (engine *DockerTaskEngine) provisionContainerResources(task *apitask.Task,
 			},
 		}
 	}
+	setupTimeout := engine.cfg.CNISetupTimeout
+	if setupTimeout <= 0 {
+		setupTimeout = cniSetupTimeout
+	}
 	// Invoke the libcni to config the network namespace for the container
-	result, err := engine.cniClient.SetupNS(engine.ctx, cniConfig, cniSetupTimeout)
+	result, err := engine.cniClient.SetupNS(engine.ctx, cniConfig, setupTimeout)
 	if err != nil {
 		seelog.Errorf("Task engine [%s]: unable to configure pause container namespace: %v",
 			task.Arn, err)
@@ -1167,6 +1709,14 @@ func (engine *DockerTaskEngine) provisionContainerResources(task *apitask.Task,
 	taskIP := result.IPs[0].Address.IP.String()
 	seelog.Infof("Task engine [%s]: associated with ip address '%s'", task.Arn, taskIP)
 	engine.state.AddTaskIPAddress(taskIP, task.Arn)
+	if err := engine.publishTaskPorts(task, taskIP); err != nil {
+		seelog.Errorf("Task engine [%s]: %v", task.Arn, err)
+		return dockerapi.DockerContainerMetadata{
+			DockerID: cniConfig.ContainerID,
+			Error: ContainerNetworkingError{errors.Wrap(err,
+				"container resource provisioning")},
+		}
+	}
 	return dockerapi.DockerContainerMetadata{
 		DockerID: cniConfig.ContainerID,
 	}
@@ -1174,6 +1724,7 @@ func (engine *DockerTaskEngine) provisionContainerResources(task *apitask.Task,
 
 // cleanupPauseContainerNetwork will clean up the network namespace of pause container
 func (engine *DockerTaskEngine) cleanupPauseContainerNetwork(task *apitask.Task, container *apicontainer.Container) error {
+	engine.unpublishTaskPorts(task)
 	delay := time.Duration(engine.cfg.ENIPauseContainerCleanupDelaySeconds) * time.Second
 	if engine.handleDelay != nil && delay > 0 {
 		seelog.Infof("Task engine [%s]: waiting %s before cleaning up pause container.", task.Arn, delay)
@@ -1198,6 +1749,7 @@ func (engine *DockerTaskEngine) buildCNIConfigFromTaskContainer(
 	cniConfig := &ecscni.Config{
 		BlockInstanceMetadata:  engine.cfg.AWSVPCBlockInstanceMetdata,
 		MinSupportedCNIVersion: config.DefaultMinSupportedCNIVersion,
+		MTU:                    engine.cfg.AWSVPCTaskMTU,
 	}
 	if engine.cfg.OverrideAWSVPCLocalIPv4Address != nil &&
 		len(engine.cfg.OverrideAWSVPCLocalIPv4Address.IP) != 0 &&
@@ -1235,9 +1787,44 @@ func (engine *DockerTaskEngine) buildCNIConfigFromTaskContainer(
 		return nil, errors.Wrapf(err, "engine: failed to build cni configuration from task")
 	}
 
+	if err = engine.appendAdditionalCNIPlugins(task, cniConfig); err != nil {
+		return nil, err
+	}
 	return cniConfig, nil
 }
 
+// appendAdditionalCNIPlugins appends the additional CNI plugins of the agent
+// config to the plugins the agent invokes for the task
+func (engine *DockerTaskEngine) appendAdditionalCNIPlugins(task *apitask.Task, cniConfig *ecscni.Config) error {
+	if len(engine.cfg.CNIAdditionalPlugins) == 0 {
+		return nil
+	}
+	data := ecscni.AdditionalPluginTemplateData{
+		TaskARN:     task.Arn,
+		TaskFamily:  task.Family,
+		ContainerID: cniConfig.ContainerID,
+	}
+	if eni := task.GetPrimaryENI(); eni != nil {
+		data.ENIID = eni.ID
+		data.ENIMACAddress = eni.MacAddress
+		data.ENIIPv4Address = eni.GetPrimaryIPv4Address()
+		data.ENIIPv6Address = eni.GetPrimaryIPv6Address()
+		data.SubnetGatewayIPv4Address = eni.SubnetGatewayIPV4Address
+	}
+	for _, plugin := range engine.cfg.CNIAdditionalPlugins {
+		ifName, netconf, err := ecscni.NewAdditionalPluginNetworkConfig(plugin.Type, plugin.IfName,
+			string(plugin.ConfigTemplate), data, cniConfig)
+		if err != nil {
+			return errors.Wrapf(err, "engine: failed to build cni configuration of additional plugin %s", plugin.Type)
+		}
+		cniConfig.NetworkConfigs = append(cniConfig.NetworkConfigs, &ecscni.NetworkConfig{
+			IfName:           ifName,
+			CNINetworkConfig: netconf,
+		})
+	}
+	return nil
+}
+
 func (engine *DockerTaskEngine) stopContainer(task *apitask.Task, container *apicontainer.Container) dockerapi.DockerContainerMetadata {
 	seelog.Infof("Task engine [%s]: stopping container [%s]", task.Arn, container.Name)
 	containerMap, ok := engine.state.ContainerMapByArn(task.Arn)
@@ -1278,13 +1865,17 @@ func (engine *DockerTaskEngine) removeContainer(task *apitask.Task, container *a
 	seelog.Infof("Task engine [%s]: removing container: %s", task.Arn, container.Name)
 	containerMap, ok := engine.state.ContainerMapByArn(task.Arn)
 
+	// The container can't be removed if it was never created, retrying won't
+	// change that
 	if !ok {
-		return errors.New("No such task: " + task.Arn)
+		return apierrors.NewRetriableError(apierrors.NewRetriable(false),
+			errors.New("No such task: "+task.Arn))
 	}
 
 	dockerContainer, ok := containerMap[container.Name]
 	if !ok {
-		return errors.New("No container named '" + container.Name + "' created in " + task.Arn)
+		return apierrors.NewRetriableError(apierrors.NewRetriable(false),
+			errors.New("No container named '"+container.Name+"' created in "+task.Arn))
 	}
 
 	return engine.client.RemoveContainer(engine.ctx, dockerContainer.DockerName, dockerclient.RemoveContainerTimeout)
@@ -1350,6 +1941,9 @@ func (engine *DockerTaskEngine) applyContainerState(task *apitask.Task, containe
 	if metadata.Error != nil {
 		seelog.Infof("Task engine [%s]: error transitioning container [%s] to [%s]: %v",
 			task.Arn, container.Name, nextState.String(), metadata.Error)
+		if isDockerDaemonFailure(metadata.Error) {
+			errorbudget.Global.RecordFailure(errorbudget.SourceDocker, metadata.Error)
+		}
 	} else {
 		seelog.Debugf("Task engine [%s]: transitioned container [%s] to [%s]",
 			task.Arn, container.Name, nextState.String())
@@ -1391,14 +1985,23 @@ func (engine *DockerTaskEngine) updateMetadataFile(task *apitask.Task, cont *api
 	}
 }
 
-func getContainerHostIP(networkSettings *types.NetworkSettings) (string, bool) {
+// bridgeNetworkName returns the name of the network the containers of the task
+// in bridge network mode are attached to
+func bridgeNetworkName(task *apitask.Task) string {
+	if networkName, ok := task.GetTaskBridgeNetworkName(); ok {
+		return networkName
+	}
+	return apitask.BridgeNetworkMode
+}
+
+func getContainerHostIP(networkSettings *types.NetworkSettings, bridgeNetworkName string) (string, bool) {
 	if networkSettings == nil {
 		return "", false
 	} else if networkSettings.IPAddress != "" {
 		return networkSettings.IPAddress, true
 	} else if len(networkSettings.Networks) > 0 {
 		for mode, network := range networkSettings.Networks {
-			if mode == apitask.BridgeNetworkMode && network.IPAddress != "" {
+			if mode == bridgeNetworkName && network.IPAddress != "" {
 				return network.IPAddress, true
 			}
 		}
@@ -103,8 +103,8 @@ func TestResourceContainerProgression(t *testing.T) {
 		imageManager.EXPECT().RecordContainerReference(sleepContainer).Return(nil),
 		imageManager.EXPECT().GetImageStateFromImageName(sleepContainer.Image).Return(nil, false),
 		client.EXPECT().APIVersion().Return(defaultDockerClientAPIVersion, nil),
-		client.EXPECT().CreateContainer(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Do(
-			func(ctx interface{}, config *dockercontainer.Config, hostConfig *dockercontainer.HostConfig, containerName string, z time.Duration) {
+		client.EXPECT().CreateContainer(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Do(
+			func(ctx interface{}, config *dockercontainer.Config, hostConfig *dockercontainer.HostConfig, networkingConfig interface{}, containerName string, z time.Duration) {
 				assert.True(t, strings.Contains(containerName, sleepContainer.Name))
 				containerEventsWG.Add(1)
 				go func() {
@@ -269,6 +269,7 @@ func TestTaskCPULimitHappyPath(t *testing.T) {
 
 	for _, tc := range testcases {
 		t.Run(tc.name, func(t *testing.T) {
+			defer useFastCleanupStepBackoff()()
 			metadataConfig := defaultConfig
 			metadataConfig.TaskCPUMemLimit = tc.taskCPULimit
 			metadataConfig.ContainerMetadataEnabled = true
@@ -382,7 +383,11 @@ func TestTaskCPULimitHappyPath(t *testing.T) {
 				}).Return(nil)
 
 			imageManager.EXPECT().RemoveContainerReferenceFromImageState(gomock.Any())
-			metadataManager.EXPECT().Clean(gomock.Any()).Return(tc.metadataCleanError)
+			metadataCleanAttempts := 1
+			if tc.metadataCleanError != nil {
+				metadataCleanAttempts = cleanupStepAttempts
+			}
+			metadataManager.EXPECT().Clean(gomock.Any()).Return(tc.metadataCleanError).Times(metadataCleanAttempts)
 			// trigger cleanup
 			cleanup <- time.Now()
 			go func() { eventStream <- createDockerEvent(apicontainerstatus.ContainerStopped) }()
@@ -492,10 +497,11 @@ func TestCreateFirelensContainer(t *testing.T) {
 
 			mockTime.EXPECT().Now().AnyTimes()
 			client.EXPECT().APIVersion().Return(defaultDockerClientAPIVersion, nil).AnyTimes()
-			client.EXPECT().CreateContainer(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Do(
+			client.EXPECT().CreateContainer(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Do(
 				func(ctx context.Context,
 					config *dockercontainer.Config,
 					hostConfig *dockercontainer.HostConfig,
+					networkingConfig interface{},
 					name string,
 					timeout time.Duration) {
 					assert.Contains(t, hostConfig.Binds, tc.expectedGeneratedConfigBind)
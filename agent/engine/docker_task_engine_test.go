@@ -52,6 +52,9 @@ import (
 	mock_engine "github.com/aws/amazon-ecs-agent/agent/engine/mocks"
 	"github.com/aws/amazon-ecs-agent/agent/engine/testdata"
 	"github.com/aws/amazon-ecs-agent/agent/eventstream"
+	"github.com/aws/amazon-ecs-agent/agent/experiments"
+	"github.com/aws/amazon-ecs-agent/agent/imageverifier"
+	mock_imageverifier "github.com/aws/amazon-ecs-agent/agent/imageverifier/mocks"
 	mock_ssm_factory "github.com/aws/amazon-ecs-agent/agent/ssm/factory/mocks"
 	mock_ssmiface "github.com/aws/amazon-ecs-agent/agent/ssm/mocks"
 	mock_statemanager "github.com/aws/amazon-ecs-agent/agent/statemanager/mocks"
@@ -194,6 +197,7 @@ func TestBatchContainerHappyPath(t *testing.T) {
 
 	for _, tc := range testcases {
 		t.Run(tc.name, func(t *testing.T) {
+			defer useFastCleanupStepBackoff()()
 			metadataConfig := defaultConfig
 			metadataConfig.TaskCPUMemLimit = tc.taskCPULimit
 			metadataConfig.ContainerMetadataEnabled = true
@@ -274,7 +278,11 @@ func TestBatchContainerHappyPath(t *testing.T) {
 				}).Return(nil)
 
 			imageManager.EXPECT().RemoveContainerReferenceFromImageState(gomock.Any())
-			metadataManager.EXPECT().Clean(gomock.Any()).Return(tc.metadataCleanError)
+			metadataCleanAttempts := 1
+			if tc.metadataCleanError != nil {
+				metadataCleanAttempts = cleanupStepAttempts
+			}
+			metadataManager.EXPECT().Clean(gomock.Any()).Return(tc.metadataCleanError).Times(metadataCleanAttempts)
 			// trigger cleanup
 			cleanup <- time.Now()
 			go func() { eventStream <- createDockerEvent(apicontainerstatus.ContainerStopped) }()
@@ -334,8 +342,8 @@ func TestTaskWithSteadyStateResourcesProvisioned(t *testing.T) {
 	gomock.InOrder(
 		// Ensure that the pause container is created first
 		client.EXPECT().APIVersion().Return(defaultDockerClientAPIVersion, nil),
-		client.EXPECT().CreateContainer(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Do(
-			func(ctx interface{}, config *dockercontainer.Config, hostConfig *dockercontainer.HostConfig, containerName string, z time.Duration) {
+		client.EXPECT().CreateContainer(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Do(
+			func(ctx interface{}, config *dockercontainer.Config, hostConfig *dockercontainer.HostConfig, networkingConfig interface{}, containerName string, z time.Duration) {
 				sleepTask.AddTaskENI(&apieni.ENI{
 					ID: "TestTaskWithSteadyStateResourcesProvisioned",
 					IPV4Addresses: []*apieni.ENIIPV4Address{
@@ -390,8 +398,8 @@ func TestTaskWithSteadyStateResourcesProvisioned(t *testing.T) {
 
 		// Once the pause container is started, sleep container will be created
 		client.EXPECT().APIVersion().Return(defaultDockerClientAPIVersion, nil),
-		client.EXPECT().CreateContainer(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Do(
-			func(ctx interface{}, config *dockercontainer.Config, hostConfig *dockercontainer.HostConfig, containerName string, z time.Duration) {
+		client.EXPECT().CreateContainer(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Do(
+			func(ctx interface{}, config *dockercontainer.Config, hostConfig *dockercontainer.HostConfig, networkingConfig interface{}, containerName string, z time.Duration) {
 				assert.True(t, strings.Contains(containerName, sleepContainer.Name))
 				assert.Equal(t, "container:"+containerID+":"+pauseContainer.Name, string(hostConfig.NetworkMode))
 				containerEventsWG.Add(1)
@@ -535,8 +543,8 @@ func TestStartTimeoutThenStart(t *testing.T) {
 
 		imageManager.EXPECT().RecordContainerReference(container)
 		imageManager.EXPECT().GetImageStateFromImageName(gomock.Any()).Return(nil, false)
-		client.EXPECT().CreateContainer(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Do(
-			func(ctx interface{}, x, y, z, timeout interface{}) {
+		client.EXPECT().CreateContainer(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Do(
+			func(ctx interface{}, x, y, networkingConfig interface{}, z, timeout interface{}) {
 				go func() { eventStream <- createDockerEvent(apicontainerstatus.ContainerCreated) }()
 			}).Return(dockerapi.DockerContainerMetadata{DockerID: containerID})
 
@@ -716,7 +724,7 @@ func TestCreateContainerForceSave(t *testing.T) {
 			assert.True(t, ok, "Expected container sleep5")
 			return nil
 		}),
-		client.EXPECT().CreateContainer(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()),
+		client.EXPECT().CreateContainer(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()),
 	)
 
 	metadata := taskEngine.createContainer(sleepTask, sleepContainer)
@@ -757,10 +765,250 @@ func TestCreateContainerMergesLabels(t *testing.T) {
 		"key":                                       "value",
 	}
 	client.EXPECT().APIVersion().Return(defaultDockerClientAPIVersion, nil).AnyTimes()
-	client.EXPECT().CreateContainer(gomock.Any(), expectedConfig, gomock.Any(), gomock.Any(), gomock.Any())
+	client.EXPECT().CreateContainer(gomock.Any(), expectedConfig, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+	taskEngine.(*DockerTaskEngine).createContainer(testTask, testTask.Containers[0])
+}
+
+// TestCreateContainerInvalidJournaldLogOptions tests that the container isn't created
+// when the journald logging driver is configured with unsupported options
+func TestCreateContainerInvalidJournaldLogOptions(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	ctrl, client, _, taskEngine, _, _, _ := mocks(t, ctx, &defaultConfig)
+	defer ctrl.Finish()
+
+	testTask := &apitask.Task{
+		Arn:     "myTaskArn",
+		Family:  "myFamily",
+		Version: "1",
+		Containers: []*apicontainer.Container{
+			{
+				Name: "c1",
+				DockerConfig: apicontainer.DockerConfig{
+					HostConfig: aws.String(`{"LogConfig":{"Type":"journald","Config":{"tag":"{{.Name}}","awslogs-group":"group"}}}`),
+				},
+			},
+		},
+	}
+	client.EXPECT().APIVersion().Return(defaultDockerClientAPIVersion, nil).AnyTimes()
+	client.EXPECT().CreateContainer(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+	metadata := taskEngine.(*DockerTaskEngine).createContainer(testTask, testTask.Containers[0])
+	require.Error(t, metadata.Error)
+	assert.Equal(t, "DockerClientConfigError", metadata.Error.ErrorName())
+}
+
+// TestCreateContainerAWSVPCDNSOptions tests that the resolver options from the
+// config are only set for the pause container
+func TestCreateContainerAWSVPCDNSOptions(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	cfg := defaultConfig
+	cfg.AWSVPCDNSOptions = []string{"ndots:2", "rotate"}
+	ctrl, client, _, taskEngine, _, _, _ := mocks(t, ctx, &cfg)
+	defer ctrl.Finish()
+
+	testTask := &apitask.Task{
+		Arn:     "myTaskArn",
+		Family:  "myFamily",
+		Version: "1",
+		Containers: []*apicontainer.Container{
+			{
+				Name: "c1",
+			},
+			{
+				Name: "~internal~ecs~pause",
+				Type: apicontainer.ContainerCNIPause,
+			},
+		},
+	}
+	client.EXPECT().APIVersion().Return(defaultDockerClientAPIVersion, nil).AnyTimes()
+	gomock.InOrder(
+		client.EXPECT().CreateContainer(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Do(
+			func(ctx interface{}, config *dockercontainer.Config, hostConfig *dockercontainer.HostConfig, networkingConfig interface{}, name string, z time.Duration) {
+				assert.Empty(t, hostConfig.DNSOptions)
+			}),
+		client.EXPECT().CreateContainer(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Do(
+			func(ctx interface{}, config *dockercontainer.Config, hostConfig *dockercontainer.HostConfig, networkingConfig interface{}, name string, z time.Duration) {
+				assert.Equal(t, []string{"ndots:2", "rotate"}, hostConfig.DNSOptions)
+			}),
+	)
+	taskEngine.(*DockerTaskEngine).createContainer(testTask, testTask.Containers[0])
+	taskEngine.(*DockerTaskEngine).createContainer(testTask, testTask.Containers[1])
+}
+
+// TestCreateContainerAWSVPCDNSDefaults tests that the DNS settings from the
+// config are only set on the pause container for those the task doesn't set
+func TestCreateContainerAWSVPCDNSDefaults(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	cfg := defaultConfig
+	cfg.AWSVPCDNSServers = []string{"10.0.0.2"}
+	cfg.AWSVPCDNSSearchDomains = []string{"example.com"}
+	cfg.AWSVPCDNSOptions = []string{"ndots:2"}
+	ctrl, client, _, taskEngine, _, _, _ := mocks(t, ctx, &cfg)
+	defer ctrl.Finish()
+
+	testTask := &apitask.Task{
+		Arn:     "myTaskArn",
+		Family:  "myFamily",
+		Version: "1",
+		ENIs: []*apieni.ENI{
+			{
+				ID: "eniID",
+			},
+		},
+		Containers: []*apicontainer.Container{
+			{
+				Name: "~internal~ecs~pause",
+				Type: apicontainer.ContainerCNIPause,
+			},
+		},
+		DNSConfig: &apitask.DNSConfig{
+			SearchDomains: []string{"corp.example.com"},
+			Options:       []string{"ndots:5"},
+		},
+	}
+	client.EXPECT().APIVersion().Return(defaultDockerClientAPIVersion, nil).AnyTimes()
+	client.EXPECT().CreateContainer(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Do(
+		func(ctx interface{}, config *dockercontainer.Config, hostConfig *dockercontainer.HostConfig, networkingConfig interface{}, name string, z time.Duration) {
+			assert.Equal(t, []string{"10.0.0.2"}, hostConfig.DNS)
+			assert.Equal(t, []string{"corp.example.com"}, hostConfig.DNSSearch)
+			assert.Equal(t, []string{"ndots:5"}, hostConfig.DNSOptions)
+		})
 	taskEngine.(*DockerTaskEngine).createContainer(testTask, testTask.Containers[0])
 }
 
+// TestCreateContainerLocalDNS tests that the containers on a docker network
+// query the local DNS server before their own nameservers
+func TestCreateContainerLocalDNS(t *testing.T) {
+	testCases := []struct {
+		name        string
+		hostConfig  string
+		expectedDNS []string
+	}{
+		{
+			name:        "bridge network",
+			hostConfig:  `{"Dns":["10.0.0.2"]}`,
+			expectedDNS: []string{"172.17.0.1", "10.0.0.2"},
+		},
+		{
+			name:        "host network",
+			hostConfig:  `{"NetworkMode":"host"}`,
+			expectedDNS: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.TODO())
+			defer cancel()
+			cfg := defaultConfig
+			cfg.LocalDNSEnabled = true
+			cfg.LocalDNSListenAddress = "172.17.0.1:53"
+			ctrl, client, _, taskEngine, _, _, _ := mocks(t, ctx, &cfg)
+			defer ctrl.Finish()
+
+			testTask := &apitask.Task{
+				Arn:     "myTaskArn",
+				Family:  "myFamily",
+				Version: "1",
+				Containers: []*apicontainer.Container{
+					{
+						Name: "c1",
+						DockerConfig: apicontainer.DockerConfig{
+							HostConfig: aws.String(tc.hostConfig),
+						},
+					},
+				},
+			}
+			client.EXPECT().APIVersion().Return(defaultDockerClientAPIVersion, nil).AnyTimes()
+			client.EXPECT().CreateContainer(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Do(
+				func(ctx interface{}, config *dockercontainer.Config, hostConfig *dockercontainer.HostConfig, networkingConfig interface{}, name string, z time.Duration) {
+					assert.Equal(t, tc.expectedDNS, hostConfig.DNS)
+				})
+			taskEngine.(*DockerTaskEngine).createContainer(testTask, testTask.Containers[0])
+		})
+	}
+}
+
+func TestCreateContainerWithPinnedImageDigest(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	ctrl, client, _, taskEngine, _, _, _ := mocks(t, ctx, &defaultConfig)
+	defer ctrl.Finish()
+
+	testTask := &apitask.Task{
+		Arn:     "myTaskArn",
+		Family:  "myFamily",
+		Version: "1",
+		Containers: []*apicontainer.Container{
+			{
+				Name:  "c1",
+				Image: "registry.example.com:5000/image:tag",
+			},
+		},
+	}
+	testTask.PinImageDigest("registry.example.com:5000/image:tag", "sha256:abc")
+	client.EXPECT().APIVersion().Return(defaultDockerClientAPIVersion, nil).AnyTimes()
+	client.EXPECT().CreateContainer(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Do(
+		func(ctx interface{}, config *dockercontainer.Config, hostConfig *dockercontainer.HostConfig, networkingConfig interface{}, name string, z time.Duration) {
+			assert.Equal(t, "registry.example.com:5000/image@sha256:abc", config.Image)
+		})
+	taskEngine.(*DockerTaskEngine).createContainer(testTask, testTask.Containers[0])
+}
+
+func TestCreateContainerImageVerification(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	ctrl, client, _, taskEngine, _, _, _ := mocks(t, ctx, &defaultConfig)
+	defer ctrl.Finish()
+	imageVerifier := mock_imageverifier.NewMockVerifier(ctrl)
+	taskEngine.(*DockerTaskEngine).imageVerifier = imageVerifier
+
+	testTask := &apitask.Task{
+		Arn:     "myTaskArn",
+		Family:  "myFamily",
+		Version: "1",
+		Containers: []*apicontainer.Container{
+			{
+				Name:  "c1",
+				Image: "image:tag",
+			},
+		},
+	}
+	testTask.PinImageDigest("image:tag", "sha256:abc")
+	client.EXPECT().APIVersion().Return(defaultDockerClientAPIVersion, nil).AnyTimes()
+	gomock.InOrder(
+		imageVerifier.EXPECT().Verify(gomock.Any(), imageverifier.Image{
+			TaskARN:       "myTaskArn",
+			ContainerName: "c1",
+			Name:          "image:tag",
+			Digest:        "sha256:abc",
+			Reference:     "image@sha256:abc",
+		}).Return(nil),
+		client.EXPECT().CreateContainer(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()),
+		imageVerifier.EXPECT().Verify(gomock.Any(), gomock.Any()).Return(errors.New("no matching signatures")),
+		imageVerifier.EXPECT().Verify(gomock.Any(), gomock.Any()).Return(&imageverifier.PolicyViolation{
+			Rule:   imageverifier.PolicyRuleRequiredLabel,
+			Detail: "label org.example.team is missing",
+		}),
+	)
+	metadata := taskEngine.(*DockerTaskEngine).createContainer(testTask, testTask.Containers[0])
+	assert.NoError(t, metadata.Error)
+
+	// The container isn't created when its image fails verification
+	metadata = taskEngine.(*DockerTaskEngine).createContainer(testTask, testTask.Containers[0])
+	assert.IsType(t, ImageVerificationError{}, metadata.Error)
+	assert.Equal(t, "Image image@sha256:abc failed verification: no matching signatures", metadata.Error.Error())
+
+	// Nor when it doesn't comply with the image policy
+	metadata = taskEngine.(*DockerTaskEngine).createContainer(testTask, testTask.Containers[0])
+	assert.IsType(t, ImagePolicyViolationError{}, metadata.Error)
+	assert.Equal(t, "ImagePolicyViolationError", metadata.Error.ErrorName())
+	assert.Equal(t, "Image image@sha256:abc violates image policy required-label: label org.example.team is missing",
+		metadata.Error.Error())
+}
+
 // TestCreateContainerAddV3EndpointIDToState tests that in createContainer, when the
 // container's v3 endpoint id is set, we will add mappings to engine state
 func TestCreateContainerAddV3EndpointIDToState(t *testing.T) {
@@ -787,7 +1035,7 @@ func TestCreateContainerAddV3EndpointIDToState(t *testing.T) {
 
 	client.EXPECT().APIVersion().Return(defaultDockerClientAPIVersion, nil).AnyTimes()
 	// V3EndpointID mappings are only added to state when dockerID is available. So return one here.
-	client.EXPECT().CreateContainer(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(dockerapi.DockerContainerMetadata{
+	client.EXPECT().CreateContainer(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(dockerapi.DockerContainerMetadata{
 		DockerID: "dockerID",
 	})
 	taskEngine.createContainer(testTask, testContainer)
@@ -832,8 +1080,8 @@ func TestTaskTransitionWhenStopContainerTimesout(t *testing.T) {
 		imageManager.EXPECT().GetImageStateFromImageName(gomock.Any()).Return(nil, false)
 		client.EXPECT().APIVersion().Return(defaultDockerClientAPIVersion, nil)
 
-		client.EXPECT().CreateContainer(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Do(
-			func(ctx interface{}, x, y, z, timeout interface{}) {
+		client.EXPECT().CreateContainer(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Do(
+			func(ctx interface{}, x, y, networkingConfig interface{}, z, timeout interface{}) {
 				go func() { eventStream <- createDockerEvent(apicontainerstatus.ContainerCreated) }()
 			}).Return(dockerapi.DockerContainerMetadata{DockerID: containerID})
 
@@ -922,8 +1170,8 @@ func TestTaskTransitionWhenStopContainerReturnsUnretriableError(t *testing.T) {
 			imageManager.EXPECT().GetImageStateFromImageName(gomock.Any()).Return(nil, false),
 			client.EXPECT().APIVersion().Return(defaultDockerClientAPIVersion, nil),
 			// Simulate successful create container
-			client.EXPECT().CreateContainer(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Do(
-				func(ctx interface{}, x, y, z, timeout interface{}) {
+			client.EXPECT().CreateContainer(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Do(
+				func(ctx interface{}, x, y, networkingConfig interface{}, z, timeout interface{}) {
 					containerEventsWG.Add(1)
 					go func() {
 						eventStream <- createDockerEvent(apicontainerstatus.ContainerCreated)
@@ -994,7 +1242,7 @@ func TestTaskTransitionWhenStopContainerReturnsTransientErrorBeforeSucceeding(t
 			imageManager.EXPECT().GetImageStateFromImageName(gomock.Any()).Return(nil, false),
 			// Simulate successful create container
 			client.EXPECT().APIVersion().Return(defaultDockerClientAPIVersion, nil),
-			client.EXPECT().CreateContainer(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(
+			client.EXPECT().CreateContainer(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(
 				dockerapi.DockerContainerMetadata{DockerID: containerID}),
 			// Simulate successful start container
 			client.EXPECT().StartContainer(gomock.Any(), containerID, defaultConfig.ContainerStartTimeout).Return(
@@ -1099,8 +1347,8 @@ func TestPauseContainerHappyPath(t *testing.T) {
 	gomock.InOrder(
 		dockerClient.EXPECT().APIVersion().Return(defaultDockerClientAPIVersion, nil),
 		dockerClient.EXPECT().CreateContainer(
-			gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Do(
-			func(ctx interface{}, config *dockercontainer.Config, x, y, z interface{}) {
+			gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Do(
+			func(ctx interface{}, config *dockercontainer.Config, x, networkingConfig interface{}, y, z interface{}) {
 				name, ok := config.Labels[labelPrefix+"container-name"]
 				assert.True(t, ok)
 				assert.Equal(t, apitask.NetworkPauseContainerName, name)
@@ -1123,7 +1371,7 @@ func TestPauseContainerHappyPath(t *testing.T) {
 	imageManager.EXPECT().RecordContainerReference(gomock.Any()).Return(nil)
 	imageManager.EXPECT().GetImageStateFromImageName(gomock.Any()).Return(nil, false)
 	dockerClient.EXPECT().APIVersion().Return(defaultDockerClientAPIVersion, nil)
-	dockerClient.EXPECT().CreateContainer(gomock.Any(), gomock.Any(), gomock.Any(),
+	dockerClient.EXPECT().CreateContainer(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(),
 		gomock.Any(), gomock.Any()).Return(dockerapi.DockerContainerMetadata{DockerID: containerID})
 	dockerClient.EXPECT().StartContainer(gomock.Any(), containerID, defaultConfig.ContainerStartTimeout).Return(
 		dockerapi.DockerContainerMetadata{DockerID: containerID})
@@ -1239,6 +1487,57 @@ func TestBuildCNIConfigFromTaskContainer(t *testing.T) {
 	require.Len(t, cniConfig.NetworkConfigs, 3)
 }
 
+func TestBuildCNIConfigFromTaskContainerWithAdditionalPlugins(t *testing.T) {
+	cfg := defaultConfig
+	cfg.CNIAdditionalPlugins = []config.CNIPluginConfig{
+		{
+			Type:           "mirror",
+			ConfigTemplate: json.RawMessage(`{"source": "{{.ENIIPv4Address}}", "task": "{{.TaskARN}}"}`),
+		},
+	}
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	ctrl, dockerClient, _, taskEngine, _, _, _ := mocks(t, ctx, &cfg)
+	defer ctrl.Finish()
+
+	testTask := testdata.LoadTask("sleep5")
+	testTask.AddTaskENI(&apieni.ENI{
+		ID: "TestBuildCNIConfigFromTaskContainerWithAdditionalPlugins",
+		IPV4Addresses: []*apieni.ENIIPV4Address{
+			{
+				Primary: true,
+				Address: ipv4,
+			},
+		},
+		MacAddress: mac,
+	})
+	container := &apicontainer.Container{
+		Name: "container",
+	}
+	taskEngine.(*DockerTaskEngine).state.AddContainer(&apicontainer.DockerContainer{
+		Container:  container,
+		DockerName: dockerContainerName,
+	}, testTask)
+
+	dockerClient.EXPECT().InspectContainer(gomock.Any(), dockerContainerName, gomock.Any()).
+		Return(&types.ContainerJSON{
+			ContainerJSONBase: &types.ContainerJSONBase{
+				ID:    containerID,
+				State: &types.ContainerState{Pid: containerPid},
+			},
+		}, nil)
+
+	cniConfig, err := taskEngine.(*DockerTaskEngine).buildCNIConfigFromTaskContainer(testTask, container, true)
+	require.NoError(t, err)
+	// The additional plugin is chained after the ENI and Bridge plugins
+	require.Len(t, cniConfig.NetworkConfigs, 3)
+	additional := cniConfig.NetworkConfigs[2]
+	assert.Equal(t, "eth0", additional.IfName)
+	assert.Equal(t, "mirror", additional.CNINetworkConfig.Network.Type)
+	assert.JSONEq(t, `{"source": "10.0.0.1", "task": "`+testTask.Arn+`", "type": "mirror"}`,
+		string(additional.CNINetworkConfig.Bytes))
+}
+
 func TestBuildCNIConfigFromTaskContainerInspectError(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.TODO())
 	defer cancel()
@@ -1398,6 +1697,49 @@ func TestStopPauseContainerCleanupDelay(t *testing.T) {
 	}
 }
 
+// TestStopContainerTimeout tests that the stop timeout of a container takes
+// precedence over ECS_CONTAINER_STOP_TIMEOUT
+func TestStopContainerTimeout(t *testing.T) {
+	testCases := []struct {
+		name            string
+		stopTimeout     uint
+		expectedTimeout time.Duration
+	}{
+		{
+			name:            "container stop timeout",
+			stopTimeout:     120,
+			expectedTimeout: 120 * time.Second,
+		},
+		{
+			name:            "default stop timeout",
+			expectedTimeout: defaultConfig.DockerStopTimeout,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.TODO())
+			defer cancel()
+			ctrl, client, _, taskEngine, _, _, _ := mocks(t, ctx, &defaultConfig)
+			defer ctrl.Finish()
+
+			testTask := testdata.LoadTask("sleep5")
+			container := testTask.Containers[0]
+			container.StopTimeout = tc.stopTimeout
+			taskEngine.(*DockerTaskEngine).State().AddTask(testTask)
+			taskEngine.(*DockerTaskEngine).State().AddContainer(&apicontainer.DockerContainer{
+				DockerID:   "id",
+				DockerName: "name",
+				Container:  container,
+			}, testTask)
+
+			client.EXPECT().StopContainer(gomock.Any(), "id", tc.expectedTimeout).Return(
+				dockerapi.DockerContainerMetadata{})
+			metadata := taskEngine.(*DockerTaskEngine).stopContainer(testTask, container)
+			assert.NoError(t, metadata.Error)
+		})
+	}
+}
+
 // TestTaskWithCircularDependency tests the task with containers of which the
 // dependencies can't be resolved
 func TestTaskWithCircularDependency(t *testing.T) {
@@ -1443,7 +1785,7 @@ func TestCreateContainerOnAgentRestart(t *testing.T) {
 
 	gomock.InOrder(
 		client.EXPECT().APIVersion().Return(defaultDockerClientAPIVersion, nil),
-		client.EXPECT().CreateContainer(gomock.Any(), gomock.Any(), gomock.Any(), "docker_container_name", gomock.Any()),
+		client.EXPECT().CreateContainer(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), "docker_container_name", gomock.Any()),
 	)
 
 	metadata := taskEngine.createContainer(sleepTask, sleepContainer)
@@ -1498,6 +1840,46 @@ func TestPullNormalImage(t *testing.T) {
 	assert.Equal(t, dockerapi.DockerContainerMetadata{}, metadata, "expected empty metadata")
 }
 
+func TestPullImageRecordsImagePullInfo(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	ctrl, client, mockTime, privateTaskEngine, _, imageManager, _ := mocks(t, ctx, &config.Config{})
+	defer ctrl.Finish()
+	taskEngine, _ := privateTaskEngine.(*DockerTaskEngine)
+	saver := mock_statemanager.NewMockStateManager(ctrl)
+	taskEngine.SetSaver(saver)
+	imageName := "registry.example.com/repo/image:tag"
+	container := &apicontainer.Container{
+		Type:  apicontainer.ContainerNormal,
+		Image: imageName,
+	}
+	task := &apitask.Task{
+		Containers: []*apicontainer.Container{container},
+	}
+	imageState := &image.ImageState{
+		Image: &image.Image{ImageID: "id", Size: 1024},
+	}
+	pullStart := time.Now()
+	pullStop := pullStart.Add(time.Minute)
+
+	gomock.InOrder(
+		mockTime.EXPECT().Now().Return(pullStart),
+		mockTime.EXPECT().Now().Return(pullStop),
+	)
+	client.EXPECT().PullImage(gomock.Any(), imageName, nil, gomock.Any())
+	imageManager.EXPECT().RecordContainerReference(container)
+	imageManager.EXPECT().GetImageStateFromImageName(imageName).Return(imageState, true)
+	saver.EXPECT().Save()
+	metadata := taskEngine.pullContainer(task, container)
+	assert.NoError(t, metadata.Error)
+	assert.Equal(t, &apicontainer.ImagePullInfo{
+		Registry:      "registry.example.com",
+		Size:          1024,
+		PullStartedAt: pullStart,
+		PullStoppedAt: pullStop,
+	}, container.GetImagePullInfo())
+}
+
 func TestPullImageWithImagePullOnceBehavior(t *testing.T) {
 	testcases := []struct {
 		name          string
@@ -1547,6 +1929,261 @@ func TestPullImageWithImagePullOnceBehavior(t *testing.T) {
 	}
 }
 
+func TestPullImageThroughPullThroughCache(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	cacheRepository := "012345678910.dkr.ecr.us-west-2.amazonaws.com/quay"
+	ctrl, client, _, privateTaskEngine, credentialsManager, imageManager, _ := mocks(t, ctx, &config.Config{
+		PullThroughCacheRules: map[string]string{"quay.io": cacheRepository},
+	})
+	defer ctrl.Finish()
+	taskEngine, _ := privateTaskEngine.(*DockerTaskEngine)
+	saver := mock_statemanager.NewMockStateManager(ctrl)
+	taskEngine.SetSaver(saver)
+	taskEngine._time = nil
+	imageName := "quay.io/coreos/etcd:v3.3"
+	cacheImageName := cacheRepository + "/coreos/etcd:v3.3"
+	container := &apicontainer.Container{
+		Type:  apicontainer.ContainerNormal,
+		Image: imageName,
+	}
+	task := &apitask.Task{
+		Containers: []*apicontainer.Container{container},
+	}
+	imageState := &image.ImageState{
+		Image: &image.Image{ImageID: "id", Names: []string{imageName}},
+	}
+	credentialsManager.EXPECT().GetTaskCredentials(gomock.Any()).Return(credentials.TaskIAMRoleCredentials{}, false)
+	gomock.InOrder(
+		client.EXPECT().PullImage(gomock.Any(), cacheImageName, gomock.Any(), gomock.Any()).Do(
+			func(ctx interface{}, image string, auth *apicontainer.RegistryAuthenticationData, timeout interface{}) {
+				assert.Equal(t, apicontainer.AuthTypeECR, auth.Type)
+				assert.Equal(t, "012345678910", auth.ECRAuthData.RegistryID)
+				assert.Equal(t, "us-west-2", auth.ECRAuthData.Region)
+				assert.False(t, auth.ECRAuthData.UseExecutionRole)
+			}),
+		client.EXPECT().TagImage(gomock.Any(), cacheImageName, imageName, gomock.Any()).Return(nil),
+	)
+	imageManager.EXPECT().RecordContainerReference(container)
+	imageManager.EXPECT().GetImageStateFromImageName(imageName).Return(imageState, true).Times(2)
+	saver.EXPECT().Save().Times(2)
+	metadata := taskEngine.pullContainer(task, container)
+	assert.NoError(t, metadata.Error)
+	assert.True(t, imageState.GetPullSucceeded())
+	assert.ElementsMatch(t, []string{imageName, cacheImageName}, imageState.Image.Names)
+	assert.Equal(t, "012345678910.dkr.ecr.us-west-2.amazonaws.com", container.GetImagePullInfo().Registry)
+}
+
+func TestPullImageByDigest(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	ctrl, client, _, privateTaskEngine, _, imageManager, _ := mocks(t, ctx, &config.Config{
+		ImagePullBehavior:   config.ImagePullOnceBehavior,
+		ImagePullDigestMode: config.ImagePullDigestResolve,
+	})
+	defer ctrl.Finish()
+	taskEngine, _ := privateTaskEngine.(*DockerTaskEngine)
+	saver := mock_statemanager.NewMockStateManager(ctrl)
+	taskEngine.SetSaver(saver)
+	taskEngine._time = nil
+	imageName := "image:tag"
+	digestImageName := "image@sha256:abc"
+	container1 := &apicontainer.Container{
+		Type:  apicontainer.ContainerNormal,
+		Image: imageName,
+	}
+	container2 := &apicontainer.Container{
+		Type:  apicontainer.ContainerNormal,
+		Image: imageName,
+	}
+	task := &apitask.Task{
+		Containers: []*apicontainer.Container{container1, container2},
+	}
+	imageState := &image.ImageState{
+		Image:         &image.Image{ImageID: "id"},
+		PullSucceeded: true,
+	}
+	// The digest of an image that wasn't pinned when the task was accepted is
+	// resolved once for the task, and the image is pulled by digest
+	// regardless of the pull behavior
+	client.EXPECT().ResolveImageDigest(gomock.Any(), imageName, nil, gomock.Any()).Return("sha256:abc", nil)
+	client.EXPECT().PullImage(gomock.Any(), digestImageName, nil, gomock.Any()).Times(2)
+	client.EXPECT().TagImage(gomock.Any(), digestImageName, imageName, gomock.Any()).Return(nil).Times(2)
+	imageManager.EXPECT().RecordContainerReference(gomock.Any()).Times(2)
+	imageManager.EXPECT().GetImageStateFromImageName(imageName).Return(imageState, true).Times(2)
+	saver.EXPECT().Save().Times(3)
+
+	metadata := taskEngine.pullContainer(task, container1)
+	assert.NoError(t, metadata.Error)
+	metadata = taskEngine.pullContainer(task, container2)
+	assert.NoError(t, metadata.Error)
+	digest, ok := task.GetPinnedImageDigest(imageName)
+	assert.True(t, ok)
+	assert.Equal(t, "sha256:abc", digest)
+}
+
+func TestPullImageByDigestResolveError(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	ctrl, client, _, privateTaskEngine, _, _, _ := mocks(t, ctx, &config.Config{
+		ImagePullDigestMode: config.ImagePullDigestResolve,
+	})
+	defer ctrl.Finish()
+	taskEngine, _ := privateTaskEngine.(*DockerTaskEngine)
+	taskEngine._time = nil
+	container := &apicontainer.Container{
+		Type:  apicontainer.ContainerNormal,
+		Image: "image",
+	}
+	task := &apitask.Task{
+		Containers: []*apicontainer.Container{container},
+	}
+	client.EXPECT().ResolveImageDigest(gomock.Any(), "image", nil, gomock.Any()).Return("", errors.New("error"))
+
+	metadata := taskEngine.pullContainer(task, container)
+	assert.IsType(t, dockerapi.CannotResolveImageDigestError{}, metadata.Error)
+	_, ok := task.GetPinnedImageDigest("image")
+	assert.False(t, ok)
+}
+
+func TestPinImageDigests(t *testing.T) {
+	testCases := []struct {
+		name           string
+		mode           config.ImagePullDigestModeType
+		image          string
+		resolve        bool
+		expectedDigest string
+		expectError    bool
+	}{
+		{
+			name:  "disabled",
+			mode:  config.ImagePullDigestDisabled,
+			image: "image:tag",
+		},
+		{
+			name:           "resolve tag",
+			mode:           config.ImagePullDigestResolve,
+			image:          "image:tag",
+			resolve:        true,
+			expectedDigest: "sha256:resolved",
+		},
+		{
+			name:           "resolve with digest",
+			mode:           config.ImagePullDigestResolve,
+			image:          "image:tag@sha256:abc",
+			expectedDigest: "sha256:abc",
+		},
+		{
+			name:           "require with digest",
+			mode:           config.ImagePullDigestRequire,
+			image:          "image@sha256:abc",
+			expectedDigest: "sha256:abc",
+		},
+		{
+			name:        "require without digest",
+			mode:        config.ImagePullDigestRequire,
+			image:       "image:tag",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			client := mock_dockerapi.NewMockDockerClient(ctrl)
+			engine := &DockerTaskEngine{
+				ctx:    context.TODO(),
+				cfg:    &config.Config{ImagePullDigestMode: tc.mode},
+				client: client,
+			}
+			task := &apitask.Task{
+				Arn: "arn",
+				Containers: []*apicontainer.Container{
+					{Image: tc.image},
+					{Image: tc.image},
+					{Image: "pause", Type: apicontainer.ContainerCNIPause},
+				},
+			}
+			if tc.resolve {
+				// The image of both containers is resolved once
+				client.EXPECT().ResolveImageDigest(gomock.Any(), tc.image, nil, gomock.Any()).Return("sha256:resolved", nil)
+			}
+			err := engine.pinImageDigests(task)
+			if tc.expectError {
+				assert.IsType(t, ImageDigestRequiredError{}, err)
+				return
+			}
+			assert.NoError(t, err)
+			digest, ok := task.GetPinnedImageDigest(tc.image)
+			assert.Equal(t, tc.expectedDigest != "", ok)
+			assert.Equal(t, tc.expectedDigest, digest)
+		})
+	}
+}
+
+func TestPinImageDigestsResolveError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock_dockerapi.NewMockDockerClient(ctrl)
+	engine := &DockerTaskEngine{
+		ctx:    context.TODO(),
+		cfg:    &config.Config{ImagePullDigestMode: config.ImagePullDigestResolve},
+		client: client,
+	}
+	task := &apitask.Task{
+		Arn: "arn",
+		Containers: []*apicontainer.Container{
+			{Image: "image:tag"},
+			{
+				Image: "private:tag",
+				RegistryAuthentication: &apicontainer.RegistryAuthenticationData{
+					Type: apicontainer.AuthTypeASM,
+				},
+			},
+		},
+	}
+	client.EXPECT().ResolveImageDigest(gomock.Any(), "image:tag", nil, gomock.Any()).Return("", errors.New("error"))
+
+	// Images pulled with credentials from Secrets Manager are left to be
+	// pinned when they're pulled
+	assert.IsType(t, dockerapi.CannotResolveImageDigestError{}, engine.pinImageDigests(task))
+	_, ok := task.GetPinnedImageDigest("image:tag")
+	assert.False(t, ok)
+}
+
+func TestPullImageByTagAndDigest(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	ctrl, client, _, privateTaskEngine, _, imageManager, _ := mocks(t, ctx, &config.Config{
+		ImagePullDigestMode: config.ImagePullDigestResolve,
+	})
+	defer ctrl.Finish()
+	taskEngine, _ := privateTaskEngine.(*DockerTaskEngine)
+	taskEngine._time = nil
+	imageName := "image:tag@sha256:abc"
+	container := &apicontainer.Container{
+		Type:  apicontainer.ContainerNormal,
+		Image: imageName,
+	}
+	task := &apitask.Task{
+		Containers: []*apicontainer.Container{container},
+	}
+	require.NoError(t, taskEngine.pinImageDigests(task))
+	imageState := &image.ImageState{
+		Image:         &image.Image{ImageID: "id"},
+		PullSucceeded: true,
+	}
+	// The pulled image is tagged with the tag of the reference only
+	client.EXPECT().PullImage(gomock.Any(), "image@sha256:abc", nil, gomock.Any())
+	client.EXPECT().TagImage(gomock.Any(), "image@sha256:abc", "image:tag", gomock.Any()).Return(nil)
+	imageManager.EXPECT().RecordContainerReference(gomock.Any())
+	imageManager.EXPECT().GetImageStateFromImageName(imageName).Return(imageState, true)
+
+	metadata := taskEngine.pullContainer(task, container)
+	assert.NoError(t, metadata.Error)
+}
+
 func TestPullImageWithImagePullPreferCachedBehaviorWithCachedImage(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.TODO())
 	defer cancel()
@@ -1575,6 +2212,39 @@ func TestPullImageWithImagePullPreferCachedBehaviorWithCachedImage(t *testing.T)
 	assert.Equal(t, dockerapi.DockerContainerMetadata{}, metadata, "expected empty metadata")
 }
 
+func TestPullImageWithLazyPullExperimentWithCachedImage(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	ctrl, client, _, privateTaskEngine, _, imageManager, _ := mocks(t, ctx, &config.Config{
+		ImagePullBehavior:  config.ImagePullAlwaysBehavior,
+		EnabledExperiments: []string{experiments.LazyPull},
+	})
+	defer ctrl.Finish()
+	taskEngine, _ := privateTaskEngine.(*DockerTaskEngine)
+	saver := mock_statemanager.NewMockStateManager(ctrl)
+	taskEngine.SetSaver(saver)
+	taskEngine._time = nil
+	imageName := "image"
+	container := &apicontainer.Container{
+		Type:  apicontainer.ContainerNormal,
+		Image: imageName,
+	}
+	task := &apitask.Task{
+		Containers:  []*apicontainer.Container{container},
+		Experiments: []string{experiments.LazyPull},
+	}
+	imageState := &image.ImageState{
+		Image: &image.Image{ImageID: "id"},
+	}
+	// The cached image is used even though the image pull behavior is always
+	client.EXPECT().InspectImage(imageName).Return(nil, nil)
+	imageManager.EXPECT().RecordContainerReference(container)
+	imageManager.EXPECT().GetImageStateFromImageName(imageName).Return(imageState, true)
+	saver.EXPECT().Save()
+	metadata := taskEngine.pullContainer(task, container)
+	assert.Equal(t, dockerapi.DockerContainerMetadata{}, metadata, "expected empty metadata")
+}
+
 func TestPullImageWithImagePullPreferCachedBehaviorWithoutCachedImage(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.TODO())
 	defer cancel()
@@ -1733,6 +2403,59 @@ func TestTaskUseExecutionRolePullECRImage(t *testing.T) {
 	taskEngine.(*DockerTaskEngine).pullContainer(testTask, container)
 }
 
+// TestPullImageRetriesWithRefreshedCredentials tests the agent retries pulls
+// rejected by the registry with the task's refreshed execution role credentials
+func TestPullImageRetriesWithRefreshedCredentials(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	ctrl, client, mockTime, taskEngine, credentialsManager, imageManager, _ := mocks(
+		t, ctx, &defaultConfig)
+	defer ctrl.Finish()
+
+	credentialsID := "execution role"
+	expiredCredentials := credentials.IAMRoleCredentials{
+		CredentialsID: credentialsID,
+		AccessKeyID:   "expired",
+	}
+	refreshedCredentials := credentials.IAMRoleCredentials{
+		CredentialsID: credentialsID,
+		AccessKeyID:   "refreshed",
+	}
+
+	testTask := testdata.LoadTask("sleep5")
+	testTask.SetExecutionRoleCredentialsID(credentialsID)
+	testTask.Containers[0].RegistryAuthentication = &apicontainer.RegistryAuthenticationData{
+		Type: "ecr",
+		ECRAuthData: &apicontainer.ECRAuthData{
+			UseExecutionRole: true,
+		},
+	}
+	container := testTask.Containers[0]
+
+	mockTime.EXPECT().Now().AnyTimes()
+	gomock.InOrder(
+		credentialsManager.EXPECT().GetTaskCredentials(credentialsID).Return(credentials.TaskIAMRoleCredentials{
+			IAMRoleCredentials: expiredCredentials,
+		}, true),
+		client.EXPECT().PullImage(gomock.Any(), container.Image, gomock.Any(), gomock.Any()).Return(
+			dockerapi.DockerContainerMetadata{
+				Error: dockerapi.CannotPullContainerAuthError{FromError: errors.New("authorization token has expired")},
+			}),
+		credentialsManager.EXPECT().GetTaskCredentials(credentialsID).Return(credentials.TaskIAMRoleCredentials{
+			IAMRoleCredentials: refreshedCredentials,
+		}, true),
+		client.EXPECT().PullImage(gomock.Any(), container.Image, gomock.Any(), gomock.Any()).Do(
+			func(ctx interface{}, image string, auth *apicontainer.RegistryAuthenticationData, timeout interface{}) {
+				assert.Equal(t, refreshedCredentials, auth.ECRAuthData.GetPullCredentials())
+			}).Return(dockerapi.DockerContainerMetadata{}),
+	)
+	imageManager.EXPECT().RecordContainerReference(container).Return(nil).Times(2)
+	imageManager.EXPECT().GetImageStateFromImageName(container.Image).Times(2)
+
+	metadata := taskEngine.(*DockerTaskEngine).pullContainer(testTask, container)
+	assert.NoError(t, metadata.Error)
+}
+
 // TestTaskUseExecutionRolePullPrivateRegistryImage tests the agent will use the
 // execution role credentials to pull from a private repository
 func TestTaskUseExecutionRolePullPrivateRegistryImage(t *testing.T) {
@@ -2274,8 +2997,8 @@ func TestContainerProgressParallize(t *testing.T) {
 		func(ctx interface{}, image interface{}, auth interface{}, timeout interface{}) {
 			waitForFastPullContainer.Wait()
 		})
-	client.EXPECT().CreateContainer(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Do(
-		func(ctx interface{}, cfg interface{}, hostconfig interface{}, name string, duration interface{}) {
+	client.EXPECT().CreateContainer(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Do(
+		func(ctx interface{}, cfg interface{}, hostconfig interface{}, networkingConfig interface{}, name string, duration interface{}) {
 			if strings.Contains(name, slowPullImage) {
 				slowContainerDockerName = name
 				state.AddContainer(&apicontainer.DockerContainer{
@@ -2667,7 +3390,7 @@ func TestTaskSecretsEnvironmentVariables(t *testing.T) {
 
 			// test validates that the expectedConfig includes secrets are appended as
 			// environment varibles
-			client.EXPECT().CreateContainer(gomock.Any(), expectedConfig, gomock.Any(), gomock.Any(), gomock.Any())
+			client.EXPECT().CreateContainer(gomock.Any(), expectedConfig, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
 			ret := taskEngine.(*DockerTaskEngine).createContainer(testTask, testTask.Containers[0])
 			assert.Nil(t, ret.Error)
 
@@ -2837,10 +3560,11 @@ func TestCreateContainerAddFirelensLogDriverConfig(t *testing.T) {
 			defer ctrl.Finish()
 
 			client.EXPECT().APIVersion().Return(defaultDockerClientAPIVersion, nil).AnyTimes()
-			client.EXPECT().CreateContainer(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Do(
+			client.EXPECT().CreateContainer(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Do(
 				func(ctx context.Context,
 					config *dockercontainer.Config,
 					hostConfig *dockercontainer.HostConfig,
+					networkingConfig interface{},
 					name string,
 					timeout time.Duration) {
 					assert.Equal(t, tc.expectedLogConfigType, hostConfig.LogConfig.Type)
@@ -2876,10 +3600,11 @@ func TestCreateFirelensContainerSetFluentdUID(t *testing.T) {
 	defer ctrl.Finish()
 
 	client.EXPECT().APIVersion().Return(defaultDockerClientAPIVersion, nil).AnyTimes()
-	client.EXPECT().CreateContainer(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Do(
+	client.EXPECT().CreateContainer(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Do(
 		func(ctx context.Context,
 			config *dockercontainer.Config,
 			hostConfig *dockercontainer.HostConfig,
+			networkingConfig interface{},
 			name string,
 			timeout time.Duration) {
 			assert.Contains(t, config.Env, "FLUENT_UID=0")
@@ -2907,6 +3632,7 @@ func TestGetBridgeIP(t *testing.T) {
 		defaultIP         string
 		bridgeIP          string
 		networkMode       string
+		bridgeNetwork     string
 		expectedOk        bool
 		expectedIPAddress string
 	}{
@@ -2914,6 +3640,7 @@ func TestGetBridgeIP(t *testing.T) {
 			defaultIP:         networkDefaultIP,
 			bridgeIP:          networkBridgeIP,
 			networkMode:       networkModeBridge,
+			bridgeNetwork:     networkModeBridge,
 			expectedOk:        true,
 			expectedIPAddress: networkDefaultIP,
 		},
@@ -2921,6 +3648,7 @@ func TestGetBridgeIP(t *testing.T) {
 			defaultIP:         "",
 			bridgeIP:          networkBridgeIP,
 			networkMode:       networkModeBridge,
+			bridgeNetwork:     networkModeBridge,
 			expectedOk:        true,
 			expectedIPAddress: networkBridgeIP,
 		},
@@ -2928,6 +3656,7 @@ func TestGetBridgeIP(t *testing.T) {
 			defaultIP:         "",
 			bridgeIP:          networkBridgeIP,
 			networkMode:       networkModeAWSVPC,
+			bridgeNetwork:     networkModeBridge,
 			expectedOk:        false,
 			expectedIPAddress: "",
 		},
@@ -2935,13 +3664,30 @@ func TestGetBridgeIP(t *testing.T) {
 			defaultIP:         "",
 			bridgeIP:          "",
 			networkMode:       networkModeBridge,
+			bridgeNetwork:     networkModeBridge,
+			expectedOk:        false,
+			expectedIPAddress: "",
+		},
+		{
+			defaultIP:         "",
+			bridgeIP:          networkBridgeIP,
+			networkMode:       "ecs-task-task-id",
+			bridgeNetwork:     "ecs-task-task-id",
+			expectedOk:        true,
+			expectedIPAddress: networkBridgeIP,
+		},
+		{
+			defaultIP:         "",
+			bridgeIP:          networkBridgeIP,
+			networkMode:       networkModeBridge,
+			bridgeNetwork:     "ecs-task-task-id",
 			expectedOk:        false,
 			expectedIPAddress: "",
 		},
 	}
 
 	for _, tc := range testCases {
-		IPAddress, ok := getContainerHostIP(getNetwork(tc.defaultIP, tc.bridgeIP, tc.networkMode))
+		IPAddress, ok := getContainerHostIP(getNetwork(tc.defaultIP, tc.bridgeIP, tc.networkMode), tc.bridgeNetwork)
 		assert.Equal(t, tc.expectedOk, ok)
 		assert.Equal(t, tc.expectedIPAddress, IPAddress)
 	}
@@ -0,0 +1,96 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"strconv"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/dockerclient"
+	"github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi"
+	dockercontainer "github.com/docker/docker/api/types/container"
+	"github.com/pkg/errors"
+)
+
+// storageOptSize is the storage option of docker limiting the size of the
+// writable layer of a container
+const storageOptSize = "size"
+
+// CheckEphemeralStorageSupport returns an error when the storage of the docker
+// daemon can't limit the size of the writable layer of containers
+func CheckEphemeralStorageSupport(ctx context.Context, client dockerapi.DockerClient) error {
+	info, err := client.Info(ctx, dockerclient.InfoTimeout)
+	if err != nil {
+		return errors.Wrap(err, "unable to get the storage driver of docker")
+	}
+	return checkStorageQuotaSupport(info)
+}
+
+// applyEphemeralStorage limits the writable layer of the container to its
+// share of the ephemeral storage size of the task. Docker only enforces the
+// size per container, so the size is split evenly between the containers of
+// the task, for their writable layers not to add up to more than the size
+func (engine *DockerTaskEngine) applyEphemeralStorage(task *apitask.Task, container *apicontainer.Container,
+	hostConfig *dockercontainer.HostConfig) error {
+	if task.EphemeralStorage == nil || container.IsInternal() {
+		return nil
+	}
+	if !engine.cfg.TaskEphemeralStorageEnabled {
+		return errors.New("ephemeral storage size requested, but ephemeral storage limits aren't enabled")
+	}
+	if err := engine.ephemeralStorageSupport(); err != nil {
+		return errors.Wrap(err, "ephemeral storage size requested, but not supported on the instance")
+	}
+	if hostConfig.StorageOpt == nil {
+		hostConfig.StorageOpt = make(map[string]string)
+	}
+	hostConfig.StorageOpt[storageOptSize] = strconv.FormatInt(containerEphemeralStorageMiB(task), 10) + "M"
+	return nil
+}
+
+// containerEphemeralStorageMiB returns the share of the ephemeral storage size
+// of the task each of its containers gets, in MiB
+func containerEphemeralStorageMiB(task *apitask.Task) int64 {
+	containers := int64(0)
+	for _, container := range task.Containers {
+		if !container.IsInternal() {
+			containers++
+		}
+	}
+	if containers == 0 {
+		containers = 1
+	}
+	return task.EphemeralStorage.SizeInGiB * 1024 / containers
+}
+
+// ephemeralStorageSupport checks whether docker supports storage quotas. The
+// result is kept once docker could be asked, as the storage driver only
+// changes with a restart of docker
+func (engine *DockerTaskEngine) ephemeralStorageSupport() error {
+	engine.ephemeralStorageLock.Lock()
+	defer engine.ephemeralStorageLock.Unlock()
+
+	if engine.ephemeralStorageChecked {
+		return engine.ephemeralStorageErr
+	}
+	info, err := engine.client.Info(engine.ctx, dockerclient.InfoTimeout)
+	if err != nil {
+		return errors.Wrap(err, "unable to get the storage driver of docker")
+	}
+	engine.ephemeralStorageChecked = true
+	engine.ephemeralStorageErr = checkStorageQuotaSupport(info)
+	return engine.ephemeralStorageErr
+}
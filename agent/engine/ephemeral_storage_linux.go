@@ -0,0 +1,110 @@
+//go:build linux
+// +build linux
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/pkg/errors"
+)
+
+const (
+	storageDriverOverlay2 = "overlay2"
+	backingFilesystemKey  = "Backing Filesystem"
+	backingFilesystemXFS  = "xfs"
+	xfsProjectQuotaOption = "prjquota"
+	xfsPQuotaOption       = "pquota"
+)
+
+// mountsPath lists the mounts visible to the agent. It's a variable so that it
+// can be overridden in tests
+var mountsPath = "/proc/mounts"
+
+// checkStorageQuotaSupport returns an error unless docker uses overlay2 on xfs,
+// which supports the size storage option when xfs is mounted with project
+// quotas. The mount options are only checked when the mount of the docker root
+// directory is visible to the agent, which isn't the case when the agent runs
+// in a container, in which case docker rejects containers without quotas
+func checkStorageQuotaSupport(info types.Info) error {
+	if info.Driver != storageDriverOverlay2 {
+		return errors.Errorf("storage driver %q doesn't support quotas, %s is required",
+			info.Driver, storageDriverOverlay2)
+	}
+	var backingFilesystem string
+	for _, status := range info.DriverStatus {
+		if status[0] == backingFilesystemKey {
+			backingFilesystem = status[1]
+		}
+	}
+	if backingFilesystem != backingFilesystemXFS {
+		return errors.Errorf("backing filesystem %q of %s doesn't support quotas, %s is required",
+			backingFilesystem, storageDriverOverlay2, backingFilesystemXFS)
+	}
+
+	fsType, options, err := mountOf(info.DockerRootDir)
+	if err != nil || fsType != backingFilesystemXFS {
+		return nil
+	}
+	for _, option := range options {
+		if option == xfsProjectQuotaOption || option == xfsPQuotaOption {
+			return nil
+		}
+	}
+	return errors.Errorf("%s isn't mounted with project quotas, the %s mount option is required",
+		info.DockerRootDir, xfsPQuotaOption)
+}
+
+// mountOf returns the filesystem type and the mount options of the mount the
+// directory is on
+func mountOf(dir string) (string, []string, error) {
+	file, err := os.Open(mountsPath)
+	if err != nil {
+		return "", nil, err
+	}
+	defer file.Close()
+
+	dir = filepath.Clean(dir)
+	var mountPoint, fsType, options string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		// Format: device mount-point type options dump pass
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 {
+			continue
+		}
+		if !isPathUnder(dir, fields[1]) || len(fields[1]) < len(mountPoint) {
+			continue
+		}
+		mountPoint, fsType, options = fields[1], fields[2], fields[3]
+	}
+	if err := scanner.Err(); err != nil {
+		return "", nil, err
+	}
+	if mountPoint == "" {
+		return "", nil, errors.Errorf("no mount found for %s", dir)
+	}
+	return fsType, strings.Split(options, ","), nil
+}
+
+// isPathUnder returns whether the path is the directory or is under it
+func isPathUnder(path string, dir string) bool {
+	return dir == "/" || path == dir || strings.HasPrefix(path, dir+"/")
+}
@@ -0,0 +1,195 @@
+// +build linux,unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	mock_dockerapi "github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi/mocks"
+	"github.com/docker/docker/api/types"
+	dockercontainer "github.com/docker/docker/api/types/container"
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func overlayOnXFSInfo() types.Info {
+	return types.Info{
+		Driver:        "overlay2",
+		DriverStatus:  [][2]string{{"Backing Filesystem", "xfs"}, {"Supports d_type", "true"}},
+		DockerRootDir: "/var/lib/docker",
+	}
+}
+
+func useTestMounts(t *testing.T, mounts string) func() {
+	dir, err := ioutil.TempDir("", "mounts")
+	require.NoError(t, err)
+	path := filepath.Join(dir, "mounts")
+	require.NoError(t, ioutil.WriteFile(path, []byte(mounts), 0644))
+	mountsPath = path
+	return func() {
+		mountsPath = "/proc/mounts"
+		os.RemoveAll(dir)
+	}
+}
+
+func TestCheckStorageQuotaSupport(t *testing.T) {
+	testCases := []struct {
+		name        string
+		info        func(info *types.Info)
+		mounts      string
+		expectedErr bool
+	}{
+		{
+			name:   "project quotas",
+			mounts: "/dev/nvme0n1p1 / xfs rw,noatime 0 0\n/dev/nvme1n1 /var/lib/docker xfs rw,noatime,prjquota 0 0\n",
+		},
+		{
+			name:        "no project quotas",
+			mounts:      "/dev/nvme0n1p1 / xfs rw,noatime,prjquota 0 0\n/dev/nvme1n1 /var/lib/docker xfs rw,noatime 0 0\n",
+			expectedErr: true,
+		},
+		{
+			name:   "project quotas on the root filesystem",
+			mounts: "/dev/nvme0n1p1 / xfs rw,noatime,prjquota 0 0\n/dev/nvme1n1 /var/lib/dockerd xfs rw 0 0\n",
+		},
+		{
+			name:   "docker root not visible",
+			mounts: "overlay / overlay rw,lowerdir=/a,upperdir=/b 0 0\n",
+		},
+		{
+			name:        "devicemapper",
+			info:        func(info *types.Info) { info.Driver = "devicemapper" },
+			expectedErr: true,
+		},
+		{
+			name:        "overlay2 on ext4",
+			info:        func(info *types.Info) { info.DriverStatus = [][2]string{{"Backing Filesystem", "extfs"}} },
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			defer useTestMounts(t, tc.mounts)()
+			info := overlayOnXFSInfo()
+			if tc.info != nil {
+				tc.info(&info)
+			}
+			err := checkStorageQuotaSupport(info)
+			if tc.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestApplyEphemeralStorage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	defer useTestMounts(t, "/dev/nvme0n1p1 / xfs rw,prjquota 0 0\n")()
+	client := mock_dockerapi.NewMockDockerClient(ctrl)
+	engine := &DockerTaskEngine{
+		ctx:    context.TODO(),
+		cfg:    &config.Config{TaskEphemeralStorageEnabled: true},
+		client: client,
+	}
+	task := &apitask.Task{EphemeralStorage: &apitask.EphemeralStorage{SizeInGiB: 30}}
+
+	gomock.InOrder(
+		client.EXPECT().Info(gomock.Any(), gomock.Any()).Return(types.Info{}, errors.New("timeout")),
+		client.EXPECT().Info(gomock.Any(), gomock.Any()).Return(overlayOnXFSInfo(), nil),
+	)
+
+	hostConfig := &dockercontainer.HostConfig{}
+	assert.Error(t, engine.applyEphemeralStorage(task, &apicontainer.Container{Name: "app"}, hostConfig),
+		"docker couldn't be asked")
+
+	// Docker is only asked again until it answers
+	for i := 0; i < 2; i++ {
+		hostConfig = &dockercontainer.HostConfig{}
+		require.NoError(t, engine.applyEphemeralStorage(task, &apicontainer.Container{Name: "app"}, hostConfig))
+		assert.Equal(t, map[string]string{"size": "30720M"}, hostConfig.StorageOpt)
+	}
+
+	hostConfig = &dockercontainer.HostConfig{}
+	pause := &apicontainer.Container{Name: "pause", Type: apicontainer.ContainerCNIPause}
+	require.NoError(t, engine.applyEphemeralStorage(task, pause, hostConfig))
+	assert.Empty(t, hostConfig.StorageOpt)
+
+	require.NoError(t, engine.applyEphemeralStorage(&apitask.Task{}, &apicontainer.Container{Name: "app"}, hostConfig))
+	assert.Empty(t, hostConfig.StorageOpt)
+}
+
+func TestApplyEphemeralStorageSplit(t *testing.T) {
+	engine := &DockerTaskEngine{
+		ctx: context.TODO(),
+		cfg: &config.Config{TaskEphemeralStorageEnabled: true},
+		// Docker was already asked
+		ephemeralStorageChecked: true,
+	}
+	app := &apicontainer.Container{Name: "app"}
+	task := &apitask.Task{
+		EphemeralStorage: &apitask.EphemeralStorage{SizeInGiB: 30},
+		Containers: []*apicontainer.Container{
+			app,
+			{Name: "sidecar"},
+			{Name: "logs"},
+			{Name: "pause", Type: apicontainer.ContainerCNIPause},
+		},
+	}
+
+	// The size is split between the containers of the task, the pause
+	// container excluded
+	hostConfig := &dockercontainer.HostConfig{}
+	require.NoError(t, engine.applyEphemeralStorage(task, app, hostConfig))
+	assert.Equal(t, map[string]string{"size": "10240M"}, hostConfig.StorageOpt)
+}
+
+func TestApplyEphemeralStorageUnsupported(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock_dockerapi.NewMockDockerClient(ctrl)
+	engine := &DockerTaskEngine{
+		ctx:    context.TODO(),
+		cfg:    &config.Config{TaskEphemeralStorageEnabled: true},
+		client: client,
+	}
+	task := &apitask.Task{EphemeralStorage: &apitask.EphemeralStorage{SizeInGiB: 30}}
+
+	client.EXPECT().Info(gomock.Any(), gomock.Any()).Return(types.Info{Driver: "devicemapper"}, nil)
+	for i := 0; i < 2; i++ {
+		assert.Error(t, engine.applyEphemeralStorage(task, &apicontainer.Container{Name: "app"}, &dockercontainer.HostConfig{}))
+	}
+}
+
+func TestApplyEphemeralStorageDisabled(t *testing.T) {
+	engine := &DockerTaskEngine{ctx: context.TODO(), cfg: &config.Config{}}
+	task := &apitask.Task{EphemeralStorage: &apitask.EphemeralStorage{SizeInGiB: 30}}
+
+	// Docker isn't asked when ephemeral storage limits aren't enabled
+	assert.Error(t, engine.applyEphemeralStorage(task, &apicontainer.Container{Name: "app"}, &dockercontainer.HostConfig{}))
+}
@@ -16,6 +16,8 @@ package engine
 import (
 	apicontainerstatus "github.com/aws/amazon-ecs-agent/agent/api/container/status"
 	apierrors "github.com/aws/amazon-ecs-agent/agent/api/errors"
+	"github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi"
+	"github.com/aws/amazon-ecs-agent/agent/imageverifier"
 )
 
 type cannotStopContainerError interface {
@@ -57,6 +59,54 @@ func (err TaskDependencyError) ErrorName() string {
 	return "TaskDependencyError"
 }
 
+// ImageDigestRequiredError is the error for tasks with images not referenced
+// by digest when pulling images by digest is required
+type ImageDigestRequiredError struct {
+	taskArn string
+	image   string
+}
+
+func (err ImageDigestRequiredError) Error() string {
+	return "Image " + err.image + " is not referenced by digest, taskArn: " + err.taskArn
+}
+
+// ErrorName is the name of the error
+func (err ImageDigestRequiredError) ErrorName() string {
+	return "ImageDigestRequiredError"
+}
+
+// ImageVerificationError is the error for images of task containers that fail
+// verification before the containers are created
+type ImageVerificationError struct {
+	image string
+	err   error
+}
+
+func (err ImageVerificationError) Error() string {
+	return "Image " + err.image + " failed verification: " + err.err.Error()
+}
+
+// ErrorName is the name of the error
+func (err ImageVerificationError) ErrorName() string {
+	return "ImageVerificationError"
+}
+
+// ImagePolicyViolationError is the error for images of task containers that
+// don't comply with the image policy of the agent config
+type ImagePolicyViolationError struct {
+	image     string
+	violation *imageverifier.PolicyViolation
+}
+
+func (err ImagePolicyViolationError) Error() string {
+	return "Image " + err.image + " violates " + err.violation.Error()
+}
+
+// ErrorName is the name of the error
+func (err ImagePolicyViolationError) ErrorName() string {
+	return "ImagePolicyViolationError"
+}
+
 // TaskStoppedBeforePullBeginError is a type for task errors involving pull
 type TaskStoppedBeforePullBeginError struct {
 	taskArn string
@@ -97,3 +147,78 @@ func (err CannotGetDockerClientVersionError) ErrorName() string {
 func (err CannotGetDockerClientVersionError) Error() string {
 	return err.fromError.Error()
 }
+
+// ContainerRestartError is the error for containers that the agent failed to
+// restart under their restart policy
+type ContainerRestartError struct {
+	container string
+	err       error
+}
+
+func (err ContainerRestartError) Error() string {
+	return "Unable to restart container " + err.container + ": " + err.err.Error()
+}
+
+// ErrorName is the name of the error
+func (err ContainerRestartError) ErrorName() string {
+	return "ContainerRestartError"
+}
+
+// GPUAllocationError is the error for containers the agent failed to assign
+// the GPUs they require to
+type GPUAllocationError struct {
+	container string
+	err       error
+}
+
+func (err GPUAllocationError) Error() string {
+	return "Unable to assign GPUs to container " + err.container + ": " + err.err.Error()
+}
+
+// ErrorName is the name of the error
+func (err GPUAllocationError) ErrorName() string {
+	return "GPUAllocationError"
+}
+
+// HostPortAllocationError is the error for containers the agent failed to
+// assign host ports to from the dynamic host port range
+type HostPortAllocationError struct {
+	container string
+	err       error
+}
+
+func (err HostPortAllocationError) Error() string {
+	return "Unable to assign host ports to container " + err.container + ": " + err.err.Error()
+}
+
+// ErrorName is the name of the error
+func (err HostPortAllocationError) ErrorName() string {
+	return "HostPortAllocationError"
+}
+
+// InstanceDrainingError is the error for tasks received while the instance is
+// draining, ahead of its interruption or termination
+type InstanceDrainingError struct {
+	taskArn string
+	reason  string
+}
+
+func (err InstanceDrainingError) Error() string {
+	return "Instance is draining (" + err.reason + "), taskArn: " + err.taskArn
+}
+
+// ErrorName is the name of the error
+func (err InstanceDrainingError) ErrorName() string {
+	return "InstanceDrainingError"
+}
+
+// isDockerDaemonFailure returns true if the error of a docker call is a failure
+// of the daemon rather than of the container, i.e. the call timed out or the
+// agent couldn't get a client of the daemon
+func isDockerDaemonFailure(err error) bool {
+	switch err.(type) {
+	case *dockerapi.DockerTimeoutError, dockerapi.CannotGetDockerClientError, *dockerapi.CannotGetDockerClientError:
+		return true
+	}
+	return false
+}
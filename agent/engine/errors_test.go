@@ -0,0 +1,35 @@
+// +build unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsDockerDaemonFailure(t *testing.T) {
+	assert.False(t, isDockerDaemonFailure(nil))
+	assert.True(t, isDockerDaemonFailure(&dockerapi.DockerTimeoutError{Duration: time.Minute, Transition: "create"}))
+	assert.True(t, isDockerDaemonFailure(dockerapi.CannotGetDockerClientError{}))
+	assert.True(t, isDockerDaemonFailure(&dockerapi.CannotGetDockerClientError{}))
+	assert.False(t, isDockerDaemonFailure(dockerapi.CannotPullContainerError{
+		FromError: errors.New("repository does not exist"),
+	}))
+}
@@ -0,0 +1,265 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package execcmd runs the agent that serves ECS Exec sessions, the
+// ExecuteCommandAgent, inside of the task containers that ask for it
+package execcmd
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apicontainerstatus "github.com/aws/amazon-ecs-agent/agent/api/container/status"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/dockerclient"
+	"github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi"
+	"github.com/cihub/seelog"
+	"github.com/docker/docker/api/types"
+	dockercontainer "github.com/docker/docker/api/types/container"
+	"github.com/pborman/uuid"
+	"github.com/pkg/errors"
+)
+
+const (
+	// ExecuteCommandAgentName is the name of the managed agent serving ECS Exec sessions
+	ExecuteCommandAgentName = "ExecuteCommandAgent"
+
+	// HostBinDir is the directory on the host holding the binaries of the
+	// ExecuteCommandAgent, in one sub-directory per version
+	HostBinDir = "/var/lib/ecs/deps/execute-command/bin"
+	// HostCertFile is the certificate bundle used by the ExecuteCommandAgent
+	HostCertFile = "/var/lib/ecs/deps/execute-command/certs/tls-ca-bundle.pem"
+	// HostLogDir is the directory on the host the ExecuteCommandAgent logs to,
+	// in one sub-directory per task and container
+	HostLogDir = "/var/log/ecs/exec"
+
+	containerDepsDirPrefix = "/ecs-execute-command-"
+	containerCertFile      = "certs/tls-ca-bundle.pem"
+	containerLogDir        = "/var/log/amazon/ssm"
+	hostLogDirMode         = 0755
+
+	// AgentBinary is the binary of the ExecuteCommandAgent started in the container
+	AgentBinary = "amazon-ssm-agent"
+	// AgentWorkerBinary and SessionWorkerBinary are run by the ExecuteCommandAgent
+	AgentWorkerBinary   = "ssm-agent-worker"
+	SessionWorkerBinary = "ssm-session-worker"
+)
+
+// Binaries are the binaries of the ExecuteCommandAgent that are mounted into
+// the container
+var Binaries = []string{AgentBinary, AgentWorkerBinary, SessionWorkerBinary}
+
+// Manager sets up containers to run the ExecuteCommandAgent, and starts it in
+// them
+type Manager interface {
+	// InitializeContainer adds the mounts the ExecuteCommandAgent needs to the
+	// host config of the container. It's a no-op for containers that don't
+	// ask for the agent
+	InitializeContainer(task *apitask.Task, container *apicontainer.Container, hostConfig *dockercontainer.HostConfig) error
+	// StartAgent starts the ExecuteCommandAgent in the running container, and
+	// records the result in the managed agent state of the container. It's a
+	// no-op for containers that don't ask for the agent
+	StartAgent(ctx context.Context, client dockerapi.DockerClient, task *apitask.Task,
+		container *apicontainer.Container, containerID string) error
+}
+
+type manager struct {
+	hostBinDir   string
+	hostCertFile string
+	hostLogDir   string
+}
+
+// NewManager returns a Manager using the ExecuteCommandAgent installed on the host
+func NewManager() Manager {
+	return &manager{
+		hostBinDir:   HostBinDir,
+		hostCertFile: HostCertFile,
+		hostLogDir:   HostLogDir,
+	}
+}
+
+func (m *manager) InitializeContainer(task *apitask.Task, container *apicontainer.Container,
+	hostConfig *dockercontainer.HostConfig) error {
+	ma, ok := container.GetManagedAgentByName(ExecuteCommandAgentName)
+	if !ok {
+		return nil
+	}
+	state := ma.ManagedAgentState
+	if err := m.initializeContainer(task, container, hostConfig, &state); err != nil {
+		state.InitFailed = true
+		state.Status = apicontainerstatus.ManagedAgentStopped
+		state.Reason = err.Error()
+		container.UpdateManagedAgentByName(ExecuteCommandAgentName, state)
+		return err
+	}
+	container.UpdateManagedAgentByName(ExecuteCommandAgentName, state)
+	return nil
+}
+
+func (m *manager) initializeContainer(task *apitask.Task, container *apicontainer.Container,
+	hostConfig *dockercontainer.HostConfig, state *apicontainer.ManagedAgentState) error {
+	taskID, err := task.GetID()
+	if err != nil {
+		return err
+	}
+	version, err := InstalledVersion(m.hostBinDir)
+	if err != nil {
+		return err
+	}
+	hostBinDir := filepath.Join(m.hostBinDir, version)
+	if _, err := os.Stat(m.hostCertFile); err != nil {
+		return errors.Wrap(err, "execute command agent: missing certificates")
+	}
+	hostLogDir := filepath.Join(m.hostLogDir, taskID, container.Name)
+	if err := os.MkdirAll(hostLogDir, hostLogDirMode); err != nil {
+		return errors.Wrap(err, "execute command agent: unable to create log directory")
+	}
+
+	// Keep the id of the mounts if the container is created again, e.g. after
+	// the agent restarted while creating it
+	if state.ID == "" {
+		state.ID = uuid.New()
+	}
+	containerDepsDir := containerDepsDirPrefix + state.ID
+	for _, binary := range Binaries {
+		hostConfig.Binds = append(hostConfig.Binds, readOnlyBind(filepath.Join(hostBinDir, binary),
+			filepath.Join(containerDepsDir, binary)))
+	}
+	hostConfig.Binds = append(hostConfig.Binds,
+		readOnlyBind(m.hostCertFile, filepath.Join(containerDepsDir, containerCertFile)),
+		hostLogDir+":"+containerLogDir)
+	return nil
+}
+
+func (m *manager) StartAgent(ctx context.Context, client dockerapi.DockerClient, task *apitask.Task,
+	container *apicontainer.Container, containerID string) error {
+	ma, ok := container.GetManagedAgentByName(ExecuteCommandAgentName)
+	if !ok || ma.InitFailed {
+		return nil
+	}
+	state := ma.ManagedAgentState
+	if err := startAgent(ctx, client, containerID, containerDepsDirPrefix+state.ID); err != nil {
+		state.Status = apicontainerstatus.ManagedAgentStopped
+		state.Reason = err.Error()
+		container.UpdateManagedAgentByName(ExecuteCommandAgentName, state)
+		return err
+	}
+	seelog.Infof("Task [%s]: started %s in container %s", task.Arn, ExecuteCommandAgentName, container.Name)
+	state.Status = apicontainerstatus.ManagedAgentRunning
+	state.Reason = ""
+	state.LastStartedAt = time.Now()
+	container.UpdateManagedAgentByName(ExecuteCommandAgentName, state)
+	return nil
+}
+
+func startAgent(ctx context.Context, client dockerapi.DockerClient, containerID, containerDepsDir string) error {
+	execConfig := types.ExecConfig{
+		User:   "0",
+		Detach: true,
+		Cmd:    []string{filepath.Join(containerDepsDir, AgentBinary)},
+	}
+	execID, err := client.CreateContainerExec(ctx, containerID, execConfig, dockerclient.ContainerExecTimeout)
+	if err != nil {
+		return errors.Wrap(err, "execute command agent: unable to create exec")
+	}
+	if err := client.StartContainerExec(ctx, execID.ID, dockerclient.ContainerExecTimeout); err != nil {
+		return errors.Wrap(err, "execute command agent: unable to start exec")
+	}
+	inspect, err := client.InspectContainerExec(ctx, execID.ID, dockerclient.ContainerExecTimeout)
+	if err != nil {
+		return errors.Wrap(err, "execute command agent: unable to inspect exec")
+	}
+	if !inspect.Running && inspect.ExitCode != 0 {
+		return fmt.Errorf("execute command agent: exited with code %d", inspect.ExitCode)
+	}
+	return nil
+}
+
+func readOnlyBind(hostPath, containerPath string) string {
+	return hostPath + ":" + containerPath + ":ro"
+}
+
+// InstalledVersion returns the latest version of the ExecuteCommandAgent in
+// binDir, making sure all of its binaries are there
+func InstalledVersion(binDir string) (string, error) {
+	version, err := LatestVersion(binDir)
+	if err != nil {
+		return "", err
+	}
+	for _, binary := range Binaries {
+		if _, err := os.Stat(filepath.Join(binDir, version, binary)); err != nil {
+			return "", errors.Wrapf(err, "execute command agent: missing binary %s", binary)
+		}
+	}
+	return version, nil
+}
+
+// LatestVersion returns the name of the sub-directory of binDir holding the
+// latest version of the ExecuteCommandAgent
+func LatestVersion(binDir string) (string, error) {
+	files, err := ioutil.ReadDir(binDir)
+	if err != nil {
+		return "", errors.Wrap(err, "execute command agent: unable to list versions")
+	}
+	latest := ""
+	for _, f := range files {
+		if !f.IsDir() || !isVersion(f.Name()) {
+			continue
+		}
+		if latest == "" || compareVersions(f.Name(), latest) > 0 {
+			latest = f.Name()
+		}
+	}
+	if latest == "" {
+		return "", errors.Errorf("execute command agent: no versions found in %s", binDir)
+	}
+	return latest, nil
+}
+
+func isVersion(name string) bool {
+	for _, part := range strings.Split(name, ".") {
+		if _, err := strconv.Atoi(part); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// compareVersions compares two dotted versions of any number of parts, and
+// returns -1, 0 or 1 if lhs is less than, equal to or greater than rhs
+func compareVersions(lhs, rhs string) int {
+	lhsParts, rhsParts := strings.Split(lhs, "."), strings.Split(rhs, ".")
+	for i := 0; i < len(lhsParts) || i < len(rhsParts); i++ {
+		var l, r int
+		if i < len(lhsParts) {
+			l, _ = strconv.Atoi(lhsParts[i])
+		}
+		if i < len(rhsParts) {
+			r, _ = strconv.Atoi(rhsParts[i])
+		}
+		if l != r {
+			if l < r {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,201 @@
+// +build unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package execcmd
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apicontainerstatus "github.com/aws/amazon-ecs-agent/agent/api/container/status"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	mock_dockerapi "github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi/mocks"
+	"github.com/docker/docker/api/types"
+	dockercontainer "github.com/docker/docker/api/types/container"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testTaskARN = "arn:aws:ecs:us-west-2:123456789012:task/1234567890abcdef"
+
+func newTestManager(t *testing.T, versions ...string) (*manager, func()) {
+	dir, err := ioutil.TempDir("", "execcmd")
+	require.NoError(t, err)
+	m := &manager{
+		hostBinDir:   filepath.Join(dir, "bin"),
+		hostCertFile: filepath.Join(dir, "certs", "tls-ca-bundle.pem"),
+		hostLogDir:   filepath.Join(dir, "log"),
+	}
+	for _, version := range versions {
+		require.NoError(t, os.MkdirAll(filepath.Join(m.hostBinDir, version), 0755))
+		for _, binary := range Binaries {
+			require.NoError(t, ioutil.WriteFile(filepath.Join(m.hostBinDir, version, binary), nil, 0755))
+		}
+	}
+	require.NoError(t, os.MkdirAll(filepath.Dir(m.hostCertFile), 0755))
+	require.NoError(t, ioutil.WriteFile(m.hostCertFile, nil, 0644))
+	return m, func() { os.RemoveAll(dir) }
+}
+
+func newTestContainer() *apicontainer.Container {
+	return &apicontainer.Container{
+		Name:                "web",
+		ManagedAgentsUnsafe: []apicontainer.ManagedAgent{{Name: ExecuteCommandAgentName}},
+	}
+}
+
+func TestLatestVersion(t *testing.T) {
+	m, cleanup := newTestManager(t, "2.9.0", "3.0.236.0", "3.0.1000.0")
+	defer cleanup()
+	require.NoError(t, os.MkdirAll(filepath.Join(m.hostBinDir, "latest"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(m.hostBinDir, "4.0.0"), nil, 0644))
+
+	version, err := LatestVersion(m.hostBinDir)
+	assert.NoError(t, err)
+	assert.Equal(t, "3.0.1000.0", version)
+}
+
+func TestLatestVersionNotInstalled(t *testing.T) {
+	m, cleanup := newTestManager(t)
+	defer cleanup()
+
+	_, err := LatestVersion(m.hostBinDir)
+	assert.Error(t, err)
+}
+
+func TestInstalledVersionMissingBinary(t *testing.T) {
+	m, cleanup := newTestManager(t, "3.0.236.0")
+	defer cleanup()
+	require.NoError(t, os.Remove(filepath.Join(m.hostBinDir, "3.0.236.0", SessionWorkerBinary)))
+
+	_, err := InstalledVersion(m.hostBinDir)
+	assert.Error(t, err)
+}
+
+func TestInitializeContainer(t *testing.T) {
+	m, cleanup := newTestManager(t, "3.0.236.0")
+	defer cleanup()
+	container := newTestContainer()
+	hostConfig := &dockercontainer.HostConfig{}
+
+	assert.NoError(t, m.InitializeContainer(&apitask.Task{Arn: testTaskARN}, container, hostConfig))
+
+	ma, ok := container.GetManagedAgentByName(ExecuteCommandAgentName)
+	require.True(t, ok)
+	assert.NotEmpty(t, ma.ID)
+	assert.False(t, ma.InitFailed)
+	depsDir := containerDepsDirPrefix + ma.ID
+	hostLogDir := filepath.Join(m.hostLogDir, "1234567890abcdef", "web")
+	assert.Equal(t, []string{
+		filepath.Join(m.hostBinDir, "3.0.236.0", AgentBinary) + ":" + filepath.Join(depsDir, AgentBinary) + ":ro",
+		filepath.Join(m.hostBinDir, "3.0.236.0", AgentWorkerBinary) + ":" + filepath.Join(depsDir, AgentWorkerBinary) + ":ro",
+		filepath.Join(m.hostBinDir, "3.0.236.0", SessionWorkerBinary) + ":" + filepath.Join(depsDir, SessionWorkerBinary) + ":ro",
+		m.hostCertFile + ":" + filepath.Join(depsDir, containerCertFile) + ":ro",
+		hostLogDir + ":" + containerLogDir,
+	}, hostConfig.Binds)
+	_, err := os.Stat(hostLogDir)
+	assert.NoError(t, err)
+
+	// The id of the mounts is kept when the container is created again
+	assert.NoError(t, m.InitializeContainer(&apitask.Task{Arn: testTaskARN}, container, &dockercontainer.HostConfig{}))
+	again, _ := container.GetManagedAgentByName(ExecuteCommandAgentName)
+	assert.Equal(t, ma.ID, again.ID)
+}
+
+func TestInitializeContainerNotInstalled(t *testing.T) {
+	m, cleanup := newTestManager(t)
+	defer cleanup()
+	container := newTestContainer()
+	hostConfig := &dockercontainer.HostConfig{}
+
+	assert.Error(t, m.InitializeContainer(&apitask.Task{Arn: testTaskARN}, container, hostConfig))
+
+	ma, _ := container.GetManagedAgentByName(ExecuteCommandAgentName)
+	assert.True(t, ma.InitFailed)
+	assert.Equal(t, apicontainerstatus.ManagedAgentStopped, ma.Status)
+	assert.NotEmpty(t, ma.Reason)
+	assert.Empty(t, hostConfig.Binds)
+}
+
+func TestInitializeContainerWithoutManagedAgent(t *testing.T) {
+	m, cleanup := newTestManager(t, "3.0.236.0")
+	defer cleanup()
+	hostConfig := &dockercontainer.HostConfig{}
+
+	assert.NoError(t, m.InitializeContainer(&apitask.Task{Arn: testTaskARN}, &apicontainer.Container{}, hostConfig))
+	assert.Empty(t, hostConfig.Binds)
+}
+
+func TestStartAgent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock_dockerapi.NewMockDockerClient(ctrl)
+	container := newTestContainer()
+	container.UpdateManagedAgentByName(ExecuteCommandAgentName, apicontainer.ManagedAgentState{ID: "id"})
+
+	gomock.InOrder(
+		client.EXPECT().CreateContainerExec(gomock.Any(), "docker-id", types.ExecConfig{
+			User:   "0",
+			Detach: true,
+			Cmd:    []string{"/ecs-execute-command-id/" + AgentBinary},
+		}, gomock.Any()).Return(&types.IDResponse{ID: "exec-id"}, nil),
+		client.EXPECT().StartContainerExec(gomock.Any(), "exec-id", gomock.Any()).Return(nil),
+		client.EXPECT().InspectContainerExec(gomock.Any(), "exec-id", gomock.Any()).Return(
+			&types.ContainerExecInspect{Running: true}, nil),
+	)
+
+	m := NewManager()
+	assert.NoError(t, m.StartAgent(context.TODO(), client, &apitask.Task{Arn: testTaskARN}, container, "docker-id"))
+	ma, _ := container.GetManagedAgentByName(ExecuteCommandAgentName)
+	assert.Equal(t, apicontainerstatus.ManagedAgentRunning, ma.Status)
+	assert.False(t, ma.LastStartedAt.IsZero())
+}
+
+func TestStartAgentError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock_dockerapi.NewMockDockerClient(ctrl)
+	container := newTestContainer()
+
+	client.EXPECT().CreateContainerExec(gomock.Any(), "docker-id", gomock.Any(), gomock.Any()).Return(
+		&types.IDResponse{ID: "exec-id"}, nil)
+	client.EXPECT().StartContainerExec(gomock.Any(), "exec-id", gomock.Any()).Return(errors.New("no such container"))
+
+	m := NewManager()
+	assert.Error(t, m.StartAgent(context.TODO(), client, &apitask.Task{Arn: testTaskARN}, container, "docker-id"))
+	ma, _ := container.GetManagedAgentByName(ExecuteCommandAgentName)
+	assert.Equal(t, apicontainerstatus.ManagedAgentStopped, ma.Status)
+	assert.Contains(t, ma.Reason, "no such container")
+}
+
+func TestStartAgentInitFailed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock_dockerapi.NewMockDockerClient(ctrl)
+	container := newTestContainer()
+	container.UpdateManagedAgentByName(ExecuteCommandAgentName, apicontainer.ManagedAgentState{
+		InitFailed: true,
+		Status:     apicontainerstatus.ManagedAgentStopped,
+	})
+
+	m := NewManager()
+	assert.NoError(t, m.StartAgent(context.TODO(), client, &apitask.Task{Arn: testTaskARN}, container, "docker-id"))
+}
@@ -0,0 +1,110 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"strings"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/gpu"
+	"github.com/cihub/seelog"
+)
+
+// getGPUAllocator returns the allocator of the GPUs the instance registers
+// with, creating it on first use as the GPU manager is initialized after the
+// engine is created
+func (engine *DockerTaskEngine) getGPUAllocator() *gpu.Allocator {
+	engine.gpuAllocatorOnce.Do(func() {
+		engine.gpuAllocator = gpu.NewAllocator(engine.registeredGPUIDs())
+	})
+	return engine.gpuAllocator
+}
+
+// gpuOwner is the key the GPUs of a container are assigned under
+func gpuOwner(task *apitask.Task, container *apicontainer.Container) string {
+	return task.Arn + "/" + container.Name
+}
+
+// assignGPUs assigns the GPUs the container requires and makes them visible
+// to the container. The GPUs the backend already associated with the
+// container are only reserved, so that they aren't assigned to another
+// container, and the container isn't started if one of them already is
+func (engine *DockerTaskEngine) assignGPUs(task *apitask.Task, container *apicontainer.Container) error {
+	allocator := engine.getGPUAllocator()
+	owner := gpuOwner(task, container)
+	if len(container.GPUIDs) > 0 {
+		return allocator.Reserve(owner, container.GPUIDs)
+	}
+
+	count, err := container.RequiredGPUs()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return nil
+	}
+	gpuIDs, err := allocator.Allocate(owner, count)
+	if err != nil {
+		return err
+	}
+	seelog.Infof("Task engine [%s]: assigned GPUs %v to container %s", task.Arn, gpuIDs, container.Name)
+	container.GPUIDs = gpuIDs
+	container.MergeEnvironmentVariables(map[string]string{
+		apitask.NvidiaVisibleDevicesEnvVar: strings.Join(gpuIDs, ","),
+	})
+	engine.saver.Save()
+	return nil
+}
+
+// reserveRestoredGPUs reserves the GPUs of the containers of the tasks loaded
+// from the state file that may still be using them
+func (engine *DockerTaskEngine) reserveRestoredGPUs(tasks []*apitask.Task) {
+	for _, task := range tasks {
+		if task.GetKnownStatus().Terminal() {
+			continue
+		}
+		for _, container := range task.Containers {
+			if len(container.GPUIDs) == 0 || container.GetKnownStatus().Terminal() {
+				continue
+			}
+			if err := engine.getGPUAllocator().Reserve(gpuOwner(task, container), container.GPUIDs); err != nil {
+				seelog.Warnf("Task engine [%s]: %v", task.Arn, err)
+			}
+		}
+	}
+}
+
+// releaseContainerGPUs frees the GPUs of the stopped container. When the task
+// sets a compute mode the GPUs are only freed once the task stops and their
+// compute mode is reset
+func (engine *DockerTaskEngine) releaseContainerGPUs(task *apitask.Task, container *apicontainer.Container) {
+	if len(container.GPUIDs) == 0 {
+		return
+	}
+	if task.GPUComputeMode != "" && task.GPUComputeMode != gpu.ComputeModeDefault {
+		return
+	}
+	engine.getGPUAllocator().Release(gpuOwner(task, container))
+}
+
+// releaseTaskGPUs frees the GPUs of all of the containers of the stopped task
+func (engine *DockerTaskEngine) releaseTaskGPUs(task *apitask.Task) {
+	for _, container := range task.Containers {
+		if len(container.GPUIDs) == 0 {
+			continue
+		}
+		engine.getGPUAllocator().Release(gpuOwner(task, container))
+	}
+}
@@ -0,0 +1,169 @@
+// +build linux,unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"testing"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/gpu"
+	mock_gpu "github.com/aws/amazon-ecs-agent/agent/gpu/mocks"
+	"github.com/aws/amazon-ecs-agent/agent/statemanager"
+	"github.com/aws/amazon-ecs-agent/agent/taskresource"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newGPUAllocationTestEngine(ctrl *gomock.Controller, gpuIDs []string) *DockerTaskEngine {
+	gpuManager := mock_gpu.NewMockGPUManager(ctrl)
+	gpuManager.EXPECT().GetGPUIDsUnsafe().Return(gpuIDs).AnyTimes()
+	return &DockerTaskEngine{
+		cfg:            &config.Config{GPUSupportEnabled: true},
+		saver:          statemanager.NewNoopStateManager(),
+		resourceFields: &taskresource.ResourceFields{NvidiaGPUManager: gpuManager},
+	}
+}
+
+func gpuRequiringContainer(name string, count string) *apicontainer.Container {
+	return &apicontainer.Container{
+		Name: name,
+		ResourceRequirements: []apicontainer.ResourceRequirement{
+			{Type: apicontainer.ResourceTypeGPU, Value: count},
+		},
+	}
+}
+
+func TestAssignGPUs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	engine := newGPUAllocationTestEngine(ctrl, []string{"gpu-0", "gpu-1", "gpu-2"})
+	task := &apitask.Task{
+		Arn: "arn:aws:ecs:us-west-2:123456789012:task/task-id",
+		Containers: []*apicontainer.Container{
+			gpuRequiringContainer("trainer", "2"),
+			gpuRequiringContainer("evaluator", "2"),
+			{Name: "sidecar"},
+		},
+	}
+
+	require.NoError(t, engine.assignGPUs(task, task.Containers[0]))
+	assert.Equal(t, []string{"gpu-0", "gpu-1"}, task.Containers[0].GPUIDs)
+	assert.Equal(t, "gpu-0,gpu-1", task.Containers[0].Environment[apitask.NvidiaVisibleDevicesEnvVar])
+
+	assert.Error(t, engine.assignGPUs(task, task.Containers[1]), "only one GPU is free")
+	assert.Empty(t, task.Containers[1].GPUIDs)
+
+	require.NoError(t, engine.assignGPUs(task, task.Containers[2]))
+	assert.Empty(t, task.Containers[2].GPUIDs)
+
+	engine.releaseContainerGPUs(task, task.Containers[0])
+	require.NoError(t, engine.assignGPUs(task, task.Containers[1]))
+	assert.Equal(t, []string{"gpu-0", "gpu-1"}, task.Containers[1].GPUIDs)
+}
+
+func TestAssignGPUsReservesAssociatedGPUs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	engine := newGPUAllocationTestEngine(ctrl, []string{"gpu-0", "gpu-1"})
+	task := &apitask.Task{
+		Arn: "arn:aws:ecs:us-west-2:123456789012:task/task-id",
+		Containers: []*apicontainer.Container{
+			{Name: "associated", GPUIDs: []string{"gpu-0"}},
+			gpuRequiringContainer("trainer", "1"),
+		},
+	}
+
+	require.NoError(t, engine.assignGPUs(task, task.Containers[0]))
+	assert.Equal(t, []string{"gpu-0"}, task.Containers[0].GPUIDs)
+
+	require.NoError(t, engine.assignGPUs(task, task.Containers[1]))
+	assert.Equal(t, []string{"gpu-1"}, task.Containers[1].GPUIDs)
+}
+
+func TestAssignGPUsAssociatedGPUAlreadyAssigned(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	engine := newGPUAllocationTestEngine(ctrl, []string{"gpu-0"})
+	task := &apitask.Task{
+		Arn: "arn:aws:ecs:us-west-2:123456789012:task/task-id",
+		Containers: []*apicontainer.Container{
+			gpuRequiringContainer("trainer", "1"),
+			{Name: "associated", GPUIDs: []string{"gpu-0"}},
+		},
+	}
+
+	require.NoError(t, engine.assignGPUs(task, task.Containers[0]))
+	assert.Error(t, engine.assignGPUs(task, task.Containers[1]))
+
+	engine.releaseContainerGPUs(task, task.Containers[1])
+	assert.Error(t, engine.assignGPUs(task, task.Containers[1]),
+		"releasing the rejected container shouldn't release the GPU of the other container")
+}
+
+func TestAssignGPUsInvalidRequirement(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	engine := newGPUAllocationTestEngine(ctrl, []string{"gpu-0"})
+	task := &apitask.Task{
+		Arn:        "arn:aws:ecs:us-west-2:123456789012:task/task-id",
+		Containers: []*apicontainer.Container{gpuRequiringContainer("trainer", "one")},
+	}
+
+	assert.Error(t, engine.assignGPUs(task, task.Containers[0]))
+}
+
+func TestReleaseContainerGPUsWithComputeMode(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	engine := newGPUAllocationTestEngine(ctrl, []string{"gpu-0"})
+	task := &apitask.Task{
+		Arn:            "arn:aws:ecs:us-west-2:123456789012:task/task-id",
+		GPUComputeMode: gpu.ComputeModeExclusiveProcess,
+		Containers:     []*apicontainer.Container{gpuRequiringContainer("trainer", "1")},
+	}
+	other := &apitask.Task{
+		Arn:        "arn:aws:ecs:us-west-2:123456789012:task/other-task-id",
+		Containers: []*apicontainer.Container{gpuRequiringContainer("trainer", "1")},
+	}
+
+	require.NoError(t, engine.assignGPUs(task, task.Containers[0]))
+	engine.releaseContainerGPUs(task, task.Containers[0])
+	assert.Error(t, engine.assignGPUs(other, other.Containers[0]),
+		"the GPU shouldn't be released before the compute mode is reset")
+
+	engine.releaseTaskGPUs(task)
+	assert.NoError(t, engine.assignGPUs(other, other.Containers[0]))
+}
+
+func TestReserveRestoredGPUs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	engine := newGPUAllocationTestEngine(ctrl, []string{"gpu-0"})
+	restored := &apitask.Task{
+		Arn:        "arn:aws:ecs:us-west-2:123456789012:task/task-id",
+		Containers: []*apicontainer.Container{{Name: "trainer", GPUIDs: []string{"gpu-0"}}},
+	}
+	task := &apitask.Task{
+		Arn:        "arn:aws:ecs:us-west-2:123456789012:task/other-task-id",
+		Containers: []*apicontainer.Container{gpuRequiringContainer("trainer", "1")},
+	}
+
+	engine.reserveRestoredGPUs([]*apitask.Task{restored})
+	assert.Error(t, engine.assignGPUs(task, task.Containers[0]))
+}
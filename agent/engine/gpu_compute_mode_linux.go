@@ -0,0 +1,56 @@
+// +build linux
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/gpu"
+	"github.com/cihub/seelog"
+	"github.com/pkg/errors"
+)
+
+// applyGPUComputeMode sets the GPUs assigned to the container to the compute
+// mode the task requests, if any
+func (engine *DockerTaskEngine) applyGPUComputeMode(task *apitask.Task, container *apicontainer.Container) error {
+	if task.GPUComputeMode == "" || len(container.GPUIDs) == 0 {
+		return nil
+	}
+	if engine.resourceFields == nil || engine.resourceFields.NvidiaGPUManager == nil {
+		return errors.New("GPU compute mode requested, but GPU support isn't enabled")
+	}
+	return engine.resourceFields.NvidiaGPUManager.SetComputeMode(container.GPUIDs, task.GPUComputeMode)
+}
+
+// resetGPUComputeMode sets the GPUs of the stopped task back to the default
+// compute mode, as they can be assigned to other tasks from then on
+func (engine *DockerTaskEngine) resetGPUComputeMode(task *apitask.Task) {
+	if task.GPUComputeMode == "" || task.GPUComputeMode == gpu.ComputeModeDefault {
+		return
+	}
+	if engine.resourceFields == nil || engine.resourceFields.NvidiaGPUManager == nil {
+		return
+	}
+	for _, container := range task.Containers {
+		if len(container.GPUIDs) == 0 {
+			continue
+		}
+		if err := engine.resourceFields.NvidiaGPUManager.SetComputeMode(container.GPUIDs, gpu.ComputeModeDefault); err != nil {
+			seelog.Errorf("Task engine [%s]: unable to reset compute mode of GPUs of container %s: %v",
+				task.Arn, container.Name, err)
+		}
+	}
+}
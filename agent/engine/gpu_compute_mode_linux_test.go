@@ -0,0 +1,88 @@
+// +build linux,unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"errors"
+	"testing"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/gpu"
+	mock_gpu "github.com/aws/amazon-ecs-agent/agent/gpu/mocks"
+	"github.com/aws/amazon-ecs-agent/agent/taskresource"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func gpuComputeModeTask(mode string) *apitask.Task {
+	return &apitask.Task{
+		Arn:            "arn:aws:ecs:us-west-2:123456789012:task/task-id",
+		GPUComputeMode: mode,
+		Containers: []*apicontainer.Container{
+			{Name: "trainer", GPUIDs: []string{"gpu-0", "gpu-1"}},
+			{Name: "sidecar"},
+		},
+	}
+}
+
+func TestApplyGPUComputeMode(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	gpuManager := mock_gpu.NewMockGPUManager(ctrl)
+	engine := &DockerTaskEngine{
+		resourceFields: &taskresource.ResourceFields{NvidiaGPUManager: gpuManager},
+	}
+	task := gpuComputeModeTask(gpu.ComputeModeExclusiveProcess)
+
+	gpuManager.EXPECT().SetComputeMode([]string{"gpu-0", "gpu-1"}, gpu.ComputeModeExclusiveProcess).Return(nil)
+	assert.NoError(t, engine.applyGPUComputeMode(task, task.Containers[0]))
+	assert.NoError(t, engine.applyGPUComputeMode(task, task.Containers[1]))
+}
+
+func TestApplyGPUComputeModeError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	gpuManager := mock_gpu.NewMockGPUManager(ctrl)
+	engine := &DockerTaskEngine{
+		resourceFields: &taskresource.ResourceFields{NvidiaGPUManager: gpuManager},
+	}
+	task := gpuComputeModeTask(gpu.ComputeModeExclusiveProcess)
+
+	gpuManager.EXPECT().SetComputeMode(gomock.Any(), gomock.Any()).Return(errors.New("error"))
+	assert.Error(t, engine.applyGPUComputeMode(task, task.Containers[0]))
+}
+
+func TestApplyGPUComputeModeWithoutGPUSupport(t *testing.T) {
+	engine := &DockerTaskEngine{resourceFields: &taskresource.ResourceFields{}}
+	task := gpuComputeModeTask(gpu.ComputeModeExclusiveProcess)
+
+	assert.Error(t, engine.applyGPUComputeMode(task, task.Containers[0]))
+}
+
+func TestResetGPUComputeMode(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	gpuManager := mock_gpu.NewMockGPUManager(ctrl)
+	engine := &DockerTaskEngine{
+		resourceFields: &taskresource.ResourceFields{NvidiaGPUManager: gpuManager},
+	}
+
+	gpuManager.EXPECT().SetComputeMode([]string{"gpu-0", "gpu-1"}, gpu.ComputeModeDefault).Return(nil)
+	engine.resetGPUComputeMode(gpuComputeModeTask(gpu.ComputeModeExclusiveProcess))
+	// GPUs of tasks that didn't change their compute mode are left alone
+	engine.resetGPUComputeMode(gpuComputeModeTask(""))
+}
@@ -0,0 +1,34 @@
+// +build !linux
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/pkg/errors"
+)
+
+// applyGPUComputeMode returns an error when the task requests a compute mode,
+// GPUs are only supported on linux
+func (engine *DockerTaskEngine) applyGPUComputeMode(task *apitask.Task, container *apicontainer.Container) error {
+	if task.GPUComputeMode == "" || len(container.GPUIDs) == 0 {
+		return nil
+	}
+	return errors.New("GPU compute mode is only supported on linux")
+}
+
+// resetGPUComputeMode is a no-op, GPUs are only supported on linux
+func (engine *DockerTaskEngine) resetGPUComputeMode(task *apitask.Task) {}
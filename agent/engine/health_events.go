@@ -0,0 +1,112 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apicontainerstatus "github.com/aws/amazon-ecs-agent/agent/api/container/status"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/cihub/seelog"
+)
+
+const (
+	healthEventStatusHealthy   = "HEALTHY"
+	healthEventStatusUnhealthy = "UNHEALTHY"
+)
+
+// healthEvent is a health event an application writes to its stdout, after
+// the health event marker
+type healthEvent struct {
+	Status   string `json:"status"`
+	Output   string `json:"output,omitempty"`
+	ExitCode int    `json:"exitCode,omitempty"`
+}
+
+// watchStdoutHealthEvents sets the health status of the container from the
+// health events the application writes to its stdout, if the container has a
+// stdout health check. Only the output since the container was last started is
+// read, as the health events of earlier runs no longer apply. The watch ends
+// when the container stops
+func (engine *DockerTaskEngine) watchStdoutHealthEvents(task *apitask.Task, container *apicontainer.Container,
+	dockerID string, startedAt time.Time) {
+	if container.HealthCheckType != apicontainer.StdoutHealthCheckType {
+		return
+	}
+	lines, err := engine.client.ContainerStdoutLines(engine.ctx, dockerID, container.PseudoTerminal, startedAt)
+	if err != nil {
+		seelog.Warnf("Task engine [%s]: unable to watch the health events of container %s: %v",
+			task.Arn, container.Name, err)
+		return
+	}
+	go func() {
+		for line := range lines {
+			health, ok := parseHealthEvent(line, engine.cfg.ContainerHealthEventMarker)
+			if !ok {
+				continue
+			}
+			seelog.Debugf("Task engine [%s]: updating container [%s(%s)] health status from its stdout: %v",
+				task.Arn, container.Name, dockerID, health.Status)
+			container.SetHealthStatus(health)
+		}
+	}()
+}
+
+// watchRestoredStdoutHealthEvents watches the health events of the running
+// containers restored from the state file
+func (engine *DockerTaskEngine) watchRestoredStdoutHealthEvents(tasks []*apitask.Task) {
+	for _, task := range tasks {
+		containerMap, ok := engine.state.ContainerMapByArn(task.Arn)
+		if !ok {
+			continue
+		}
+		for _, dockerContainer := range containerMap {
+			if dockerContainer.DockerID == "" || !dockerContainer.Container.IsRunning() {
+				continue
+			}
+			engine.watchStdoutHealthEvents(task, dockerContainer.Container, dockerContainer.DockerID,
+				dockerContainer.Container.GetStartedAt())
+		}
+	}
+}
+
+// parseHealthEvent returns the health status of the health event the line
+// carries, if any
+func parseHealthEvent(line string, marker string) (apicontainer.HealthStatus, bool) {
+	if !strings.HasPrefix(line, marker) {
+		return apicontainer.HealthStatus{}, false
+	}
+	var event healthEvent
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(line, marker)), &event); err != nil {
+		seelog.Debugf("Task engine: ignoring invalid health event %q: %v", line, err)
+		return apicontainer.HealthStatus{}, false
+	}
+	health := apicontainer.HealthStatus{
+		Output:   event.Output,
+		ExitCode: event.ExitCode,
+	}
+	switch strings.ToUpper(event.Status) {
+	case healthEventStatusHealthy:
+		health.Status = apicontainerstatus.ContainerHealthy
+	case healthEventStatusUnhealthy:
+		health.Status = apicontainerstatus.ContainerUnhealthy
+	default:
+		seelog.Debugf("Task engine: ignoring health event with unknown status %q", event.Status)
+		return apicontainer.HealthStatus{}, false
+	}
+	return health, true
+}
@@ -0,0 +1,120 @@
+// +build unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apicontainerstatus "github.com/aws/amazon-ecs-agent/agent/api/container/status"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	mock_dockerapi "github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseHealthEvent(t *testing.T) {
+	testCases := []struct {
+		name           string
+		line           string
+		expectedOK     bool
+		expectedHealth apicontainer.HealthStatus
+	}{
+		{
+			name: "no marker",
+			line: `{"status": "HEALTHY"}`,
+		},
+		{
+			name:           "healthy",
+			line:           `ECS_HEALTH_EVENT {"status": "HEALTHY"}`,
+			expectedOK:     true,
+			expectedHealth: apicontainer.HealthStatus{Status: apicontainerstatus.ContainerHealthy},
+		},
+		{
+			name:       "unhealthy",
+			line:       `ECS_HEALTH_EVENT {"status": "unhealthy", "output": "db unreachable", "exitCode": 2}`,
+			expectedOK: true,
+			expectedHealth: apicontainer.HealthStatus{
+				Status:   apicontainerstatus.ContainerUnhealthy,
+				Output:   "db unreachable",
+				ExitCode: 2,
+			},
+		},
+		{
+			name: "unknown status",
+			line: `ECS_HEALTH_EVENT {"status": "STARTING"}`,
+		},
+		{
+			name: "invalid event",
+			line: `ECS_HEALTH_EVENT HEALTHY`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			health, ok := parseHealthEvent(tc.line, config.DefaultContainerHealthEventMarker)
+			assert.Equal(t, tc.expectedOK, ok)
+			assert.Equal(t, tc.expectedHealth, health)
+		})
+	}
+}
+
+func TestWatchStdoutHealthEvents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock_dockerapi.NewMockDockerClient(ctrl)
+	engine := &DockerTaskEngine{
+		ctx:    context.TODO(),
+		cfg:    &config.Config{ContainerHealthEventMarker: config.DefaultContainerHealthEventMarker},
+		client: client,
+	}
+	task := &apitask.Task{Arn: "arn:aws:ecs:us-west-2:123456789012:task/task-id"}
+	container := &apicontainer.Container{
+		Name:            "app",
+		HealthCheckType: apicontainer.StdoutHealthCheckType,
+		PseudoTerminal:  true,
+	}
+
+	startedAt := time.Now()
+	lines := make(chan string)
+	client.EXPECT().ContainerStdoutLines(gomock.Any(), "id", true, startedAt).Return((<-chan string)(lines), nil)
+	engine.watchStdoutHealthEvents(task, container, "id", startedAt)
+
+	lines <- "starting"
+	lines <- `ECS_HEALTH_EVENT {"status": "HEALTHY"}`
+	lines <- `ECS_HEALTH_EVENT {"status": "UNHEALTHY", "output": "db unreachable"}`
+	close(lines)
+	for i := 0; i < 100 && container.GetHealthStatus().Status != apicontainerstatus.ContainerUnhealthy; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, apicontainerstatus.ContainerUnhealthy, container.GetHealthStatus().Status)
+	assert.Equal(t, "db unreachable", container.GetHealthStatus().Output)
+}
+
+func TestWatchStdoutHealthEventsOtherHealthCheck(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock_dockerapi.NewMockDockerClient(ctrl)
+	engine := &DockerTaskEngine{client: client}
+	task := &apitask.Task{Arn: "arn:aws:ecs:us-west-2:123456789012:task/task-id"}
+	container := &apicontainer.Container{Name: "app", HealthCheckType: apicontainer.DockerHealthCheckType}
+
+	// No call to the docker client is expected
+	engine.watchStdoutHealthEvents(task, container, "id", time.Now())
+}
@@ -0,0 +1,158 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"strconv"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/hostport"
+	"github.com/cihub/seelog"
+	dockercontainer "github.com/docker/docker/api/types/container"
+	"github.com/docker/go-connections/nat"
+)
+
+// getHostPortAllocator returns the allocator of the dynamic host port range,
+// nil if the range isn't configured
+func (engine *DockerTaskEngine) getHostPortAllocator() *hostport.Allocator {
+	engine.hostPortAllocatorOnce.Do(func() {
+		if engine.cfg.DynamicHostPortRangeStart == 0 {
+			return
+		}
+		engine.hostPortAllocator = hostport.NewAllocator(engine.cfg.DynamicHostPortRangeStart,
+			engine.cfg.DynamicHostPortRangeEnd, engine.cfg.ReservedPorts, engine.cfg.ReservedPortsUDP)
+	})
+	return engine.hostPortAllocator
+}
+
+// hostPortOwner is the key the host ports of a container are assigned under
+func hostPortOwner(task *apitask.Task, container *apicontainer.Container) string {
+	return task.Arn + "/" + container.Name
+}
+
+// assignDynamicHostPorts binds the port mappings of the container without a
+// host port to host ports of the dynamic host port range, instead of letting
+// docker pick them. The ports assigned to the container before, such as when
+// it's created again after the agent restarted, are kept
+func (engine *DockerTaskEngine) assignDynamicHostPorts(task *apitask.Task, container *apicontainer.Container,
+	hostConfig *dockercontainer.HostConfig) error {
+	allocator := engine.getHostPortAllocator()
+	if allocator == nil || container.IsInternal() || task.IsNetworkModeAWSVPC() ||
+		!usesBridgeNetwork(task, hostConfig) {
+		return nil
+	}
+
+	owner := hostPortOwner(task, container)
+	previous := append([]apicontainer.PortBinding{}, container.GetDynamicHostPorts()...)
+	var assigned []apicontainer.PortBinding
+	for _, binding := range container.Ports {
+		if binding.HostPort != 0 {
+			continue
+		}
+		hostPort, ok := takeDynamicHostPort(&previous, binding)
+		if ok {
+			if err := allocator.Reserve(owner, hostPort, binding.Protocol); err != nil {
+				seelog.Warnf("Task engine [%s]: %v", task.Arn, err)
+			}
+		} else {
+			var err error
+			hostPort, err = allocator.Allocate(owner, binding.Protocol)
+			if err != nil {
+				return err
+			}
+		}
+		bindDynamicHostPort(hostConfig, binding, hostPort)
+		assigned = append(assigned, apicontainer.PortBinding{
+			ContainerPort: binding.ContainerPort,
+			HostPort:      hostPort,
+			Protocol:      binding.Protocol,
+		})
+	}
+	if len(assigned) == 0 {
+		return nil
+	}
+	seelog.Infof("Task engine [%s]: assigned host ports %v to container %s", task.Arn, assigned, container.Name)
+	container.SetDynamicHostPorts(assigned)
+	engine.saver.Save()
+	return nil
+}
+
+// usesBridgeNetwork returns true if the container is attached to the default
+// docker bridge or to the bridge network of its task
+func usesBridgeNetwork(task *apitask.Task, hostConfig *dockercontainer.HostConfig) bool {
+	networkMode := string(hostConfig.NetworkMode)
+	return networkMode == "" || networkMode == apitask.BridgeNetworkMode || networkMode == "default" ||
+		networkMode == bridgeNetworkName(task)
+}
+
+// takeDynamicHostPort removes the host port assigned before to the port
+// mapping from the list and returns it
+func takeDynamicHostPort(previous *[]apicontainer.PortBinding, binding apicontainer.PortBinding) (uint16, bool) {
+	for i, assigned := range *previous {
+		if assigned.ContainerPort == binding.ContainerPort && assigned.Protocol == binding.Protocol {
+			*previous = append((*previous)[:i], (*previous)[i+1:]...)
+			return assigned.HostPort, true
+		}
+	}
+	return 0, false
+}
+
+// bindDynamicHostPort sets the host port of the first binding of the port
+// mapping in the host config that doesn't have one yet
+func bindDynamicHostPort(hostConfig *dockercontainer.HostConfig, binding apicontainer.PortBinding, hostPort uint16) {
+	dockerPort := nat.Port(strconv.Itoa(int(binding.ContainerPort)) + "/" + binding.Protocol.String())
+	for i, portBinding := range hostConfig.PortBindings[dockerPort] {
+		if portBinding.HostPort == "" || portBinding.HostPort == "0" {
+			hostConfig.PortBindings[dockerPort][i].HostPort = strconv.Itoa(int(hostPort))
+			return
+		}
+	}
+}
+
+// reserveRestoredHostPorts reserves the host ports assigned to the containers
+// of the tasks loaded from the state file that may still be using them
+func (engine *DockerTaskEngine) reserveRestoredHostPorts(tasks []*apitask.Task) {
+	allocator := engine.getHostPortAllocator()
+	if allocator == nil {
+		return
+	}
+	for _, task := range tasks {
+		if task.GetKnownStatus().Terminal() {
+			continue
+		}
+		for _, container := range task.Containers {
+			for _, binding := range container.GetDynamicHostPorts() {
+				if err := allocator.Reserve(hostPortOwner(task, container), binding.HostPort, binding.Protocol); err != nil {
+					seelog.Warnf("Task engine [%s]: %v", task.Arn, err)
+				}
+			}
+		}
+	}
+}
+
+// releaseTaskHostPorts frees the host ports assigned to the containers of the
+// stopped task
+func (engine *DockerTaskEngine) releaseTaskHostPorts(task *apitask.Task) {
+	allocator := engine.getHostPortAllocator()
+	if allocator == nil {
+		return
+	}
+	for _, container := range task.Containers {
+		if len(container.GetDynamicHostPorts()) == 0 {
+			continue
+		}
+		allocator.Release(hostPortOwner(task, container))
+	}
+}
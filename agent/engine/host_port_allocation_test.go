@@ -0,0 +1,142 @@
+// +build unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"testing"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/statemanager"
+	dockercontainer "github.com/docker/docker/api/types/container"
+	"github.com/docker/go-connections/nat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testDynamicHostPortRangeStart = 61000
+	testDynamicHostPortRangeEnd   = 61099
+)
+
+func newHostPortAllocationTestEngine() *DockerTaskEngine {
+	return &DockerTaskEngine{
+		cfg: &config.Config{
+			DynamicHostPortRangeStart: testDynamicHostPortRangeStart,
+			DynamicHostPortRangeEnd:   testDynamicHostPortRangeEnd,
+		},
+		saver: statemanager.NewNoopStateManager(),
+	}
+}
+
+func hostPortTestTask() *apitask.Task {
+	return &apitask.Task{
+		Arn: "arn:aws:ecs:us-west-2:123456789012:task/task-id",
+		Containers: []*apicontainer.Container{
+			{
+				Name: "web",
+				Ports: []apicontainer.PortBinding{
+					{ContainerPort: 80},
+					{ContainerPort: 443, HostPort: 8443},
+					{ContainerPort: 53, Protocol: apicontainer.TransportProtocolUDP},
+				},
+			},
+		},
+	}
+}
+
+func hostPortTestHostConfig() *dockercontainer.HostConfig {
+	return &dockercontainer.HostConfig{
+		PortBindings: nat.PortMap{
+			"80/tcp":  {{HostPort: "0"}},
+			"443/tcp": {{HostPort: "8443"}},
+			"53/udp":  {{HostPort: "0"}},
+		},
+	}
+}
+
+func inDynamicHostPortRange(t *testing.T, hostPort string) {
+	port, err := nat.ParsePort(hostPort)
+	require.NoError(t, err)
+	assert.True(t, port >= testDynamicHostPortRangeStart && port <= testDynamicHostPortRangeEnd,
+		"host port %d should be in the dynamic host port range", port)
+}
+
+func TestAssignDynamicHostPorts(t *testing.T) {
+	engine := newHostPortAllocationTestEngine()
+	task := hostPortTestTask()
+	container := task.Containers[0]
+	hostConfig := hostPortTestHostConfig()
+
+	require.NoError(t, engine.assignDynamicHostPorts(task, container, hostConfig))
+	inDynamicHostPortRange(t, hostConfig.PortBindings["80/tcp"][0].HostPort)
+	inDynamicHostPortRange(t, hostConfig.PortBindings["53/udp"][0].HostPort)
+	assert.Equal(t, "8443", hostConfig.PortBindings["443/tcp"][0].HostPort)
+	assigned := container.GetDynamicHostPorts()
+	require.Len(t, assigned, 2)
+
+	// Creating the container again keeps its ports
+	hostConfig = hostPortTestHostConfig()
+	require.NoError(t, engine.assignDynamicHostPorts(task, container, hostConfig))
+	assert.Equal(t, assigned, container.GetDynamicHostPorts())
+
+	// The ports aren't assigned to another container until the task stops
+	other := hostPortTestTask()
+	other.Arn = "arn:aws:ecs:us-west-2:123456789012:task/other-task-id"
+	require.NoError(t, engine.assignDynamicHostPorts(other, other.Containers[0], hostPortTestHostConfig()))
+	assert.NotEqual(t, assigned[0].HostPort, other.Containers[0].GetDynamicHostPorts()[0].HostPort)
+}
+
+func TestAssignDynamicHostPortsNotConfigured(t *testing.T) {
+	engine := &DockerTaskEngine{cfg: &config.Config{}}
+	task := hostPortTestTask()
+	hostConfig := hostPortTestHostConfig()
+
+	require.NoError(t, engine.assignDynamicHostPorts(task, task.Containers[0], hostConfig))
+	assert.Equal(t, "0", hostConfig.PortBindings["80/tcp"][0].HostPort)
+	assert.Empty(t, task.Containers[0].GetDynamicHostPorts())
+}
+
+func TestAssignDynamicHostPortsHostNetworkMode(t *testing.T) {
+	engine := newHostPortAllocationTestEngine()
+	task := hostPortTestTask()
+	hostConfig := hostPortTestHostConfig()
+	hostConfig.NetworkMode = "host"
+
+	require.NoError(t, engine.assignDynamicHostPorts(task, task.Containers[0], hostConfig))
+	assert.Equal(t, "0", hostConfig.PortBindings["80/tcp"][0].HostPort)
+}
+
+func TestReserveRestoredHostPorts(t *testing.T) {
+	engine := newHostPortAllocationTestEngine()
+	engine.cfg.DynamicHostPortRangeEnd = testDynamicHostPortRangeStart
+	task := hostPortTestTask()
+	task.Containers[0].Ports = task.Containers[0].Ports[:1]
+	task.Containers[0].SetDynamicHostPorts([]apicontainer.PortBinding{
+		{ContainerPort: 80, HostPort: testDynamicHostPortRangeStart},
+	})
+	engine.reserveRestoredHostPorts([]*apitask.Task{task})
+
+	other := hostPortTestTask()
+	other.Arn = "arn:aws:ecs:us-west-2:123456789012:task/other-task-id"
+	assert.Error(t, engine.assignDynamicHostPorts(other, other.Containers[0], hostPortTestHostConfig()),
+		"the only port of the range is reserved for the restored task")
+
+	engine.releaseTaskHostPorts(task)
+	other.Containers[0].Ports = other.Containers[0].Ports[:1]
+	require.NoError(t, engine.assignDynamicHostPorts(other, other.Containers[0], hostPortTestHostConfig()))
+}
@@ -24,6 +24,15 @@ import (
 	"github.com/cihub/seelog"
 )
 
+const (
+	// PullOperation is the operation of the errors pulling an image
+	PullOperation = "pull"
+	// DeleteOperation is the operation of the errors removing an image
+	DeleteOperation = "delete"
+	// maxErrorHistory is the number of errors kept in the history of an image
+	maxErrorHistory = 10
+)
+
 type Image struct {
 	ImageID string
 	Names   []string
@@ -34,6 +43,16 @@ func (image *Image) String() string {
 	return fmt.Sprintf("ImageID: %s; Names: %s", image.ImageID, strings.Join(image.Names, ", "))
 }
 
+// ImageError is an error the agent ran into pulling or removing an image
+type ImageError struct {
+	// Operation is either "pull" or "delete"
+	Operation string
+	// Error is the message of the error
+	Error string
+	// Time is when the error happened
+	Time time.Time
+}
+
 // ImageState represents a docker image
 // and its state information such as containers associated with it
 type ImageState struct {
@@ -48,6 +67,14 @@ type ImageState struct {
 	// PullSucceeded defines whether this image has been pulled successfully before,
 	// this should be set to true when one of the pull image call succeeds.
 	PullSucceeded bool
+	// Errors are the latest errors pulling or removing this image, oldest first
+	Errors []ImageError
+	// DeletionFailures is the number of times in a row this image couldn't be
+	// removed
+	DeletionFailures int
+	// QuarantinedAt is the time this image was quarantined, after too many
+	// failures to remove it in a row, zero if it isn't quarantined
+	QuarantinedAt time.Time
 	lock          sync.RWMutex
 }
 
@@ -76,6 +103,13 @@ func (imageState *ImageState) GetImageNamesCount() int {
 	return len(imageState.Image.Names)
 }
 
+// GetImageNames returns a copy of the names of the image
+func (imageState *ImageState) GetImageNames() []string {
+	imageState.lock.RLock()
+	defer imageState.lock.RUnlock()
+	return append([]string{}, imageState.Image.Names...)
+}
+
 // HasNoAssociatedContainers returns true if image has no associated containers, false otherwise
 func (imageState *ImageState) HasNoAssociatedContainers() bool {
 	return len(imageState.Containers) == 0
@@ -142,21 +176,101 @@ func (imageState *ImageState) GetPullSucceeded() bool {
 	return imageState.PullSucceeded
 }
 
+// RecordPullError adds an error pulling the image to its error history
+func (imageState *ImageState) RecordPullError(err error, at time.Time) {
+	imageState.lock.Lock()
+	defer imageState.lock.Unlock()
+
+	imageState.recordErrorUnsafe(PullOperation, err, at)
+}
+
+// RecordDeletionError adds an error removing the image to its error history
+// and returns the number of times in a row it couldn't be removed
+func (imageState *ImageState) RecordDeletionError(err error, at time.Time) int {
+	imageState.lock.Lock()
+	defer imageState.lock.Unlock()
+
+	imageState.recordErrorUnsafe(DeleteOperation, err, at)
+	imageState.DeletionFailures++
+	return imageState.DeletionFailures
+}
+
+func (imageState *ImageState) recordErrorUnsafe(operation string, err error, at time.Time) {
+	imageState.Errors = append(imageState.Errors, ImageError{
+		Operation: operation,
+		Error:     err.Error(),
+		Time:      at,
+	})
+	if len(imageState.Errors) > maxErrorHistory {
+		imageState.Errors = imageState.Errors[len(imageState.Errors)-maxErrorHistory:]
+	}
+}
+
+// ResetDeletionFailures resets the number of times in a row the image
+// couldn't be removed, once one of its names is removed
+func (imageState *ImageState) ResetDeletionFailures() {
+	imageState.lock.Lock()
+	defer imageState.lock.Unlock()
+
+	imageState.DeletionFailures = 0
+	imageState.QuarantinedAt = time.Time{}
+}
+
+// GetErrors returns a copy of the error history of the image
+func (imageState *ImageState) GetErrors() []ImageError {
+	imageState.lock.RLock()
+	defer imageState.lock.RUnlock()
+
+	return append([]ImageError(nil), imageState.Errors...)
+}
+
+// GetDeletionFailures returns the number of times in a row the image couldn't
+// be removed
+func (imageState *ImageState) GetDeletionFailures() int {
+	imageState.lock.RLock()
+	defer imageState.lock.RUnlock()
+
+	return imageState.DeletionFailures
+}
+
+// Quarantine sets the time the image was quarantined
+func (imageState *ImageState) Quarantine(at time.Time) {
+	imageState.lock.Lock()
+	defer imageState.lock.Unlock()
+
+	imageState.QuarantinedAt = at
+}
+
+// GetQuarantinedAt returns the time the image was quarantined, zero if it
+// isn't quarantined
+func (imageState *ImageState) GetQuarantinedAt() time.Time {
+	imageState.lock.RLock()
+	defer imageState.lock.RUnlock()
+
+	return imageState.QuarantinedAt
+}
+
 // MarshalJSON marshals image state
 func (imageState *ImageState) MarshalJSON() ([]byte, error) {
 	imageState.lock.Lock()
 	defer imageState.lock.Unlock()
 
 	return json.Marshal(&struct {
-		Image         *Image
-		PulledAt      time.Time
-		LastUsedAt    time.Time
-		PullSucceeded bool
+		Image            *Image
+		PulledAt         time.Time
+		LastUsedAt       time.Time
+		PullSucceeded    bool
+		Errors           []ImageError `json:",omitempty"`
+		DeletionFailures int          `json:",omitempty"`
+		QuarantinedAt    time.Time
 	}{
-		Image:         imageState.Image,
-		PulledAt:      imageState.PulledAt,
-		LastUsedAt:    imageState.LastUsedAt,
-		PullSucceeded: imageState.PullSucceeded,
+		Image:            imageState.Image,
+		PulledAt:         imageState.PulledAt,
+		LastUsedAt:       imageState.LastUsedAt,
+		PullSucceeded:    imageState.PullSucceeded,
+		Errors:           imageState.Errors,
+		DeletionFailures: imageState.DeletionFailures,
+		QuarantinedAt:    imageState.QuarantinedAt,
 	})
 }
 
@@ -0,0 +1,46 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"strings"
+
+	"github.com/aws/amazon-ecs-agent/agent/utils"
+)
+
+// imageReferenceDigest returns the digest of an image reference of the form
+// name[:tag]@digest
+func imageReferenceDigest(image string) (string, bool) {
+	idx := strings.LastIndex(image, "@")
+	if idx < 0 {
+		return "", false
+	}
+	return image[idx+1:], true
+}
+
+// imageReferenceWithDigest returns the image reference to the digest in the
+// repository of the image
+func imageReferenceWithDigest(image string, digest string) string {
+	repository, _ := utils.ParseRepositoryTag(imageReferenceWithoutDigest(image))
+	return repository + "@" + digest
+}
+
+// imageReferenceWithoutDigest returns the image reference of the form
+// name[:tag] without its digest, if any
+func imageReferenceWithoutDigest(image string) string {
+	if idx := strings.LastIndex(image, "@"); idx >= 0 {
+		return image[:idx]
+	}
+	return image
+}
@@ -0,0 +1,56 @@
+// +build unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImageReferenceDigest(t *testing.T) {
+	digest, ok := imageReferenceDigest("image@sha256:abc")
+	assert.True(t, ok)
+	assert.Equal(t, "sha256:abc", digest)
+
+	_, ok = imageReferenceDigest("registry.example.com:5000/image:tag")
+	assert.False(t, ok)
+}
+
+func TestImageReferenceWithDigest(t *testing.T) {
+	testCases := []struct {
+		image    string
+		expected string
+	}{
+		{"image", "image@sha256:abc"},
+		{"image:tag", "image@sha256:abc"},
+		{"registry.example.com:5000/image", "registry.example.com:5000/image@sha256:abc"},
+		{"registry.example.com:5000/image:tag", "registry.example.com:5000/image@sha256:abc"},
+		{"image:tag@sha256:def", "image@sha256:abc"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.image, func(t *testing.T) {
+			assert.Equal(t, tc.expected, imageReferenceWithDigest(tc.image, "sha256:abc"))
+		})
+	}
+}
+
+func TestImageReferenceWithoutDigest(t *testing.T) {
+	assert.Equal(t, "image:tag", imageReferenceWithoutDigest("image:tag@sha256:abc"))
+	assert.Equal(t, "registry.example.com:5000/image", imageReferenceWithoutDigest("registry.example.com:5000/image@sha256:abc"))
+	assert.Equal(t, "image:tag", imageReferenceWithoutDigest("image:tag"))
+}
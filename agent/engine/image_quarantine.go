@@ -0,0 +1,52 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/engine/image"
+)
+
+// QuarantinedImage is an image the image cleanup stopped trying to remove
+// after failing to remove it too many times in a row. It's reported so that
+// operators can fix what keeps the image from being removed, such as a
+// container the agent doesn't manage using one of its layers
+type QuarantinedImage struct {
+	ImageID          string             `json:"ImageID"`
+	Names            []string           `json:"Names"`
+	QuarantinedAt    time.Time          `json:"QuarantinedAt"`
+	DeletionFailures int                `json:"DeletionFailures"`
+	Errors           []image.ImageError `json:"Errors"`
+}
+
+// QuarantinedImages returns the images in quarantine, along with their error
+// history
+func (engine *DockerTaskEngine) QuarantinedImages() []QuarantinedImage {
+	quarantinedImages := []QuarantinedImage{}
+	for _, imageState := range engine.state.AllImageStates() {
+		quarantinedAt := imageState.GetQuarantinedAt()
+		if quarantinedAt.IsZero() {
+			continue
+		}
+		quarantinedImages = append(quarantinedImages, QuarantinedImage{
+			ImageID:          imageState.Image.ImageID,
+			Names:            append([]string(nil), imageState.Image.Names...),
+			QuarantinedAt:    quarantinedAt,
+			DeletionFailures: imageState.GetDeletionFailures(),
+			Errors:           imageState.GetErrors(),
+		})
+	}
+	return quarantinedImages
+}
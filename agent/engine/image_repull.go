@@ -0,0 +1,139 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/dockerclient"
+	"github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi"
+	"github.com/cihub/seelog"
+)
+
+// ImageRepuller periodically resolves the tags of the configured images in the
+// registry, to detect that they point to a new digest before tasks using them
+// are deployed, and optionally pulls the new images so that instances keep
+// warm copies of them
+type ImageRepuller struct {
+	client   dockerapi.DockerClient
+	images   []string
+	interval time.Duration
+	action   config.ImageRepullActionType
+	// digests maps the images to the digests their tags pointed to the last
+	// time they were resolved, or the last time they were pulled when the
+	// action is to pull them
+	digests map[string]string
+}
+
+// NewImageRepuller returns an ImageRepuller of the images of the config
+func NewImageRepuller(cfg *config.Config, client dockerapi.DockerClient) *ImageRepuller {
+	return &ImageRepuller{
+		client:   client,
+		images:   cfg.ImageRepullImages,
+		interval: cfg.ImageRepullInterval,
+		action:   cfg.ImageRepullAction,
+		digests:  make(map[string]string),
+	}
+}
+
+// Start resolves the tags of the images every interval until the context is
+// canceled
+func (repuller *ImageRepuller) Start(ctx context.Context) {
+	seelog.Infof("Image repuller: resolving the tags of %d images every %s", len(repuller.images),
+		repuller.interval.String())
+	repuller.checkImages(ctx)
+
+	ticker := time.NewTicker(repuller.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			repuller.checkImages(ctx)
+		}
+	}
+}
+
+func (repuller *ImageRepuller) checkImages(ctx context.Context) {
+	for _, image := range repuller.images {
+		repuller.checkImage(ctx, image)
+	}
+}
+
+// checkImage resolves the tag of the image and takes the configured action
+// when it points to a new digest. The first time the image is checked, the
+// digest is compared to the ones of the copy of the image on the instance, if
+// any, so that changes since the image was last pulled are detected too
+func (repuller *ImageRepuller) checkImage(ctx context.Context, image string) {
+	digest, err := repuller.client.ResolveImageDigest(ctx, image, nil, dockerclient.ResolveImageDigestTimeout)
+	if err != nil {
+		seelog.Warnf("Image repuller: unable to resolve the digest of image %s: %v", image, err)
+		return
+	}
+
+	previousDigest, known := repuller.digests[image]
+	if !known {
+		localDigests := repuller.localImageDigests(image)
+		for _, localDigest := range localDigests {
+			if localDigest == digest {
+				repuller.digests[image] = digest
+				return
+			}
+		}
+		if len(localDigests) > 0 {
+			previousDigest, known = localDigests[0], true
+		}
+	}
+
+	if known {
+		if previousDigest == digest {
+			return
+		}
+		seelog.Infof("Image repuller: image %s now points to digest %s, previously %s", image, digest, previousDigest)
+	} else if repuller.action == config.ImageRepullNotify {
+		// There's no previous digest to compare to until the next check
+		repuller.digests[image] = digest
+		return
+	}
+
+	if repuller.action == config.ImageRepullPull {
+		seelog.Infof("Image repuller: pulling image %s at digest %s", image, digest)
+		metadata := repuller.client.PullImage(ctx, image, nil, dockerclient.PullImageTimeout)
+		if metadata.Error != nil {
+			// The digest isn't recorded, so that the pull is retried next time
+			seelog.Errorf("Image repuller: unable to pull image %s: %v", image, metadata.Error)
+			return
+		}
+	}
+	repuller.digests[image] = digest
+}
+
+// localImageDigests returns the digests of the copy of the image on the
+// instance, if any
+func (repuller *ImageRepuller) localImageDigests(image string) []string {
+	imageInspected, err := repuller.client.InspectImage(image)
+	if err != nil {
+		return nil
+	}
+	var digests []string
+	for _, repoDigest := range imageInspected.RepoDigests {
+		if digest, ok := imageReferenceDigest(repoDigest); ok {
+			digests = append(digests, digest)
+		}
+	}
+	return digests
+}
@@ -0,0 +1,138 @@
+// +build unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi"
+	mock_dockerapi "github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi/mocks"
+	"github.com/docker/docker/api/types"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+const repullImage = "nginx:latest"
+
+func newTestImageRepuller(client dockerapi.DockerClient, action config.ImageRepullActionType) *ImageRepuller {
+	return NewImageRepuller(&config.Config{
+		ImageRepullImages: []string{repullImage},
+		ImageRepullAction: action,
+	}, client)
+}
+
+func TestImageRepullNotify(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock_dockerapi.NewMockDockerClient(ctrl)
+
+	gomock.InOrder(
+		client.EXPECT().ResolveImageDigest(gomock.Any(), repullImage, nil, gomock.Any()).Return("sha256:new", nil),
+		client.EXPECT().InspectImage(repullImage).Return(&types.ImageInspect{
+			RepoDigests: []string{"nginx@sha256:old"},
+		}, nil),
+		client.EXPECT().ResolveImageDigest(gomock.Any(), repullImage, nil, gomock.Any()).Return("sha256:new", nil),
+	)
+
+	repuller := newTestImageRepuller(client, config.ImageRepullNotify)
+	repuller.checkImages(context.TODO())
+	assert.Equal(t, "sha256:new", repuller.digests[repullImage])
+	repuller.checkImages(context.TODO())
+	assert.Equal(t, "sha256:new", repuller.digests[repullImage])
+}
+
+func TestImageRepullNotifyImageNotOnInstance(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock_dockerapi.NewMockDockerClient(ctrl)
+
+	gomock.InOrder(
+		client.EXPECT().ResolveImageDigest(gomock.Any(), repullImage, nil, gomock.Any()).Return("sha256:old", nil),
+		client.EXPECT().InspectImage(repullImage).Return(nil, errors.New("no such image")),
+		client.EXPECT().ResolveImageDigest(gomock.Any(), repullImage, nil, gomock.Any()).Return("sha256:new", nil),
+	)
+
+	repuller := newTestImageRepuller(client, config.ImageRepullNotify)
+	repuller.checkImages(context.TODO())
+	assert.Equal(t, "sha256:old", repuller.digests[repullImage])
+	repuller.checkImages(context.TODO())
+	assert.Equal(t, "sha256:new", repuller.digests[repullImage])
+}
+
+func TestImageRepullPull(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock_dockerapi.NewMockDockerClient(ctrl)
+
+	gomock.InOrder(
+		// Up to date copy on the instance
+		client.EXPECT().ResolveImageDigest(gomock.Any(), repullImage, nil, gomock.Any()).Return("sha256:old", nil),
+		client.EXPECT().InspectImage(repullImage).Return(&types.ImageInspect{
+			RepoDigests: []string{"nginx@sha256:old"},
+		}, nil),
+		// The tag moved, the pull fails and is retried next time
+		client.EXPECT().ResolveImageDigest(gomock.Any(), repullImage, nil, gomock.Any()).Return("sha256:new", nil),
+		client.EXPECT().PullImage(gomock.Any(), repullImage, nil, gomock.Any()).Return(
+			dockerapi.DockerContainerMetadata{Error: dockerapi.CannotPullContainerError{FromError: errors.New("error")}}),
+		client.EXPECT().ResolveImageDigest(gomock.Any(), repullImage, nil, gomock.Any()).Return("sha256:new", nil),
+		client.EXPECT().PullImage(gomock.Any(), repullImage, nil, gomock.Any()).Return(
+			dockerapi.DockerContainerMetadata{}),
+		// Nothing to pull until the tag moves again
+		client.EXPECT().ResolveImageDigest(gomock.Any(), repullImage, nil, gomock.Any()).Return("sha256:new", nil),
+	)
+
+	repuller := newTestImageRepuller(client, config.ImageRepullPull)
+	repuller.checkImages(context.TODO())
+	assert.Equal(t, "sha256:old", repuller.digests[repullImage])
+	repuller.checkImages(context.TODO())
+	assert.Equal(t, "sha256:old", repuller.digests[repullImage])
+	repuller.checkImages(context.TODO())
+	assert.Equal(t, "sha256:new", repuller.digests[repullImage])
+	repuller.checkImages(context.TODO())
+}
+
+func TestImageRepullPullImageNotOnInstance(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock_dockerapi.NewMockDockerClient(ctrl)
+
+	gomock.InOrder(
+		client.EXPECT().ResolveImageDigest(gomock.Any(), repullImage, nil, gomock.Any()).Return("sha256:new", nil),
+		client.EXPECT().InspectImage(repullImage).Return(nil, errors.New("no such image")),
+		client.EXPECT().PullImage(gomock.Any(), repullImage, nil, gomock.Any()).Return(
+			dockerapi.DockerContainerMetadata{}),
+	)
+
+	repuller := newTestImageRepuller(client, config.ImageRepullPull)
+	repuller.checkImages(context.TODO())
+	assert.Equal(t, "sha256:new", repuller.digests[repullImage])
+}
+
+func TestImageRepullResolveError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock_dockerapi.NewMockDockerClient(ctrl)
+
+	client.EXPECT().ResolveImageDigest(gomock.Any(), repullImage, nil, gomock.Any()).Return("",
+		errors.New("unauthorized"))
+
+	repuller := newTestImageRepuller(client, config.ImageRepullPull)
+	repuller.checkImages(context.TODO())
+	assert.Empty(t, repuller.digests)
+}
@@ -0,0 +1,139 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"time"
+
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	apitaskstatus "github.com/aws/amazon-ecs-agent/agent/api/task/status"
+	"github.com/cihub/seelog"
+)
+
+// RejectNewTasks makes the engine reject the tasks it receives from then on,
+// because the instance is about to be interrupted or terminated. The tasks the
+// engine already runs are left running, for ECS to stop and replace them once
+// the container instance is DRAINING
+func (engine *DockerTaskEngine) RejectNewTasks(reason string) {
+	engine.tasksLock.Lock()
+	defer engine.tasksLock.Unlock()
+
+	if engine.drainingReason != "" {
+		return
+	}
+	seelog.Infof("Task engine: rejecting new tasks: %s", reason)
+	engine.drainingReason = reason
+}
+
+// AcceptNewTasks makes the engine accept new tasks again, once the
+// interruption or the termination of the instance is called off
+func (engine *DockerTaskEngine) AcceptNewTasks() {
+	engine.tasksLock.Lock()
+	defer engine.tasksLock.Unlock()
+
+	if engine.drainingReason == "" {
+		return
+	}
+	seelog.Infof("Task engine: accepting new tasks again, the instance isn't draining anymore")
+	engine.drainingReason = ""
+	engine.stoppingDrainedTasks = false
+}
+
+// DrainInstanceWithGracePeriods stops the tasks of the engine and makes it
+// reject the tasks it receives from then on. Each task is given a grace period
+// to stop on its own before the engine stops it, and reports the reason in
+// its stopped reason. taskGracePeriods overrides the grace period of the tasks
+// it has, by ARN. New tasks are rejected as soon as the drain starts
+func (engine *DockerTaskEngine) DrainInstanceWithGracePeriods(reason string, gracePeriod time.Duration,
+	taskGracePeriods map[string]time.Duration) {
+	engine.tasksLock.Lock()
+	defer engine.tasksLock.Unlock()
+
+	if engine.stoppingDrainedTasks {
+		return
+	}
+	seelog.Infof("Task engine: draining the instance: %s", reason)
+	if engine.drainingReason == "" {
+		engine.drainingReason = reason
+	}
+	engine.stoppingDrainedTasks = true
+	for _, mtask := range engine.managedTasks {
+		if mtask.GetDesiredStatus().Terminal() {
+			continue
+		}
+		taskGracePeriod := gracePeriod
+		if period, ok := taskGracePeriods[mtask.Arn]; ok {
+			taskGracePeriod = period
+		}
+		if taskGracePeriod <= 0 {
+			stopDrainingTask(mtask, reason)
+			continue
+		}
+		seelog.Infof("Managed task [%s]: stopping the task in %s, the instance is draining", mtask.Arn, taskGracePeriod)
+		go stopDrainingTaskAfter(mtask, reason, taskGracePeriod)
+	}
+}
+
+// stopDrainingTaskAfter stops the task once its grace period is over, unless
+// it's already stopping by then
+func stopDrainingTaskAfter(mtask *managedTask, reason string, gracePeriod time.Duration) {
+	timer := time.NewTimer(gracePeriod)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		if mtask.GetDesiredStatus().Terminal() {
+			return
+		}
+		stopDrainingTask(mtask, reason)
+	case <-mtask.ctx.Done():
+	}
+}
+
+func stopDrainingTask(mtask *managedTask, reason string) {
+	seelog.Infof("Managed task [%s]: stopping the task, the instance is draining", mtask.Arn)
+	mtask.SetTerminalReason(stopReason(instanceDrainingReasonCode, "%s", reason))
+	mtask.emitACSTransition(acsTransition{desiredStatus: apitaskstatus.TaskStopped})
+}
+
+// DrainingTasks returns the number of tasks of the engine that aren't stopped
+// yet, which is the progress of the drain of the instance
+func (engine *DockerTaskEngine) DrainingTasks() int {
+	engine.tasksLock.RLock()
+	defer engine.tasksLock.RUnlock()
+
+	remaining := 0
+	for _, mtask := range engine.managedTasks {
+		if !mtask.GetKnownStatus().Terminal() {
+			remaining++
+		}
+	}
+	return remaining
+}
+
+// drainingError returns an error if the task is a new task the engine
+// receives while the instance is draining. Updates of the tasks the engine
+// already runs, such as stopping them, are still accepted
+func (engine *DockerTaskEngine) drainingError(task *apitask.Task) error {
+	engine.tasksLock.RLock()
+	defer engine.tasksLock.RUnlock()
+
+	if engine.drainingReason == "" || task.GetDesiredStatus().Terminal() {
+		return nil
+	}
+	if _, exists := engine.state.TaskByArn(task.Arn); exists {
+		return nil
+	}
+	return InstanceDrainingError{taskArn: task.Arn, reason: engine.drainingReason}
+}
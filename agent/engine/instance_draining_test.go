@@ -0,0 +1,112 @@
+// +build unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	apitaskstatus "github.com/aws/amazon-ecs-agent/agent/api/task/status"
+	"github.com/aws/amazon-ecs-agent/agent/engine/dockerstate"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRejectNewTasks(t *testing.T) {
+	runningTask := &managedTask{
+		Task: &apitask.Task{
+			Arn:                 "running",
+			DesiredStatusUnsafe: apitaskstatus.TaskRunning,
+		},
+		ctx:         context.Background(),
+		acsMessages: make(chan acsTransition, 1),
+	}
+	taskEngine := &DockerTaskEngine{
+		state:        dockerstate.NewTaskEngineState(),
+		managedTasks: map[string]*managedTask{runningTask.Arn: runningTask},
+	}
+	taskEngine.state.AddTask(runningTask.Task)
+	newTask := &apitask.Task{Arn: "new", DesiredStatusUnsafe: apitaskstatus.TaskRunning}
+	assert.NoError(t, taskEngine.drainingError(newTask))
+
+	taskEngine.RejectNewTasks("spot interruption")
+	taskEngine.RejectNewTasks("auto scaling termination")
+
+	// The running tasks are left for ECS to replace
+	assert.Empty(t, runningTask.acsMessages)
+	assert.Empty(t, runningTask.GetTerminalReason())
+
+	assert.Equal(t, InstanceDrainingError{taskArn: "new", reason: "spot interruption"},
+		taskEngine.drainingError(newTask))
+	// Tasks the engine already runs can still be updated
+	assert.NoError(t, taskEngine.drainingError(&apitask.Task{
+		Arn:                 "running",
+		DesiredStatusUnsafe: apitaskstatus.TaskStopped,
+	}))
+	assert.NoError(t, taskEngine.drainingError(&apitask.Task{
+		Arn:                 "stopped",
+		DesiredStatusUnsafe: apitaskstatus.TaskStopped,
+	}))
+
+	taskEngine.AcceptNewTasks()
+	assert.NoError(t, taskEngine.drainingError(newTask))
+}
+
+func TestDrainInstanceWithGracePeriods(t *testing.T) {
+	newManagedTask := func(arn string) *managedTask {
+		return &managedTask{
+			Task: &apitask.Task{
+				Arn:                 arn,
+				KnownStatusUnsafe:   apitaskstatus.TaskRunning,
+				DesiredStatusUnsafe: apitaskstatus.TaskRunning,
+			},
+			ctx:         context.Background(),
+			acsMessages: make(chan acsTransition, 1),
+		}
+	}
+	immediateTask := newManagedTask("immediate")
+	graceTask := newManagedTask("grace")
+	longGraceTask := newManagedTask("long-grace")
+	taskEngine := &DockerTaskEngine{
+		state: dockerstate.NewTaskEngineState(),
+		managedTasks: map[string]*managedTask{
+			immediateTask.Arn: immediateTask,
+			graceTask.Arn:     graceTask,
+			longGraceTask.Arn: longGraceTask,
+		},
+	}
+	assert.Equal(t, 3, taskEngine.DrainingTasks())
+
+	// Rejecting new tasks first doesn't prevent the tasks from being stopped
+	taskEngine.RejectNewTasks("spot interruption")
+	taskEngine.DrainInstanceWithGracePeriods("scale in", 10*time.Millisecond, map[string]time.Duration{
+		immediateTask.Arn: 0,
+		longGraceTask.Arn: time.Hour,
+	})
+	assert.Len(t, immediateTask.acsMessages, 1)
+	assert.Equal(t, "InstanceDraining: scale in", immediateTask.GetTerminalReason())
+	assert.Empty(t, longGraceTask.acsMessages)
+
+	transition := <-graceTask.acsMessages
+	assert.Equal(t, apitaskstatus.TaskStopped, transition.desiredStatus)
+	assert.Equal(t, "InstanceDraining: scale in", graceTask.GetTerminalReason())
+	assert.Empty(t, longGraceTask.acsMessages)
+
+	immediateTask.SetKnownStatus(apitaskstatus.TaskStopped)
+	graceTask.SetKnownStatus(apitaskstatus.TaskStopped)
+	assert.Equal(t, 1, taskEngine.DrainingTasks())
+}
@@ -0,0 +1,106 @@
+// +build linux
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+
+package engine
+
+import (
+	"path/filepath"
+	"strings"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	taskresourcevolume "github.com/aws/amazon-ecs-agent/agent/taskresource/volume"
+	dockercontainer "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/pkg/errors"
+)
+
+// applyIsolationMode enforces the multi tenant isolation mode on the host config
+// of task containers: the root filesystem is made read-only, and containers
+// that are privileged, share a namespace of the host, use host devices or mount
+// host paths other than the ones managed by the agent are rejected
+func applyIsolationMode(cfg *config.Config, task *apitask.Task, container *apicontainer.Container,
+	hostConfig *dockercontainer.HostConfig) error {
+	if !cfg.MultiTenantIsolationEnabled || container.IsInternal() {
+		return nil
+	}
+
+	if hostConfig.Privileged {
+		return errors.New("privileged containers aren't allowed in multi tenant isolation mode")
+	}
+	hostNamespaces := []struct {
+		name   string
+		isHost bool
+	}{
+		{"network", hostConfig.NetworkMode.IsHost()},
+		{"pid", hostConfig.PidMode.IsHost()},
+		{"ipc", hostConfig.IpcMode.IsHost()},
+		{"user", hostConfig.UsernsMode.IsHost()},
+		{"uts", hostConfig.UTSMode.IsHost()},
+	}
+	for _, namespace := range hostNamespaces {
+		if namespace.isHost {
+			return errors.Errorf("the host %s namespace can't be shared in multi tenant isolation mode", namespace.name)
+		}
+	}
+	if len(hostConfig.Devices) > 0 {
+		return errors.New("host devices aren't allowed in multi tenant isolation mode")
+	}
+
+	allowedSources := isolationModeAllowedSources(task)
+	for _, bind := range hostConfig.Binds {
+		source := strings.SplitN(bind, ":", 2)[0]
+		if !isolationModeAllowedSource(cfg, allowedSources, source) {
+			return errors.Errorf("host path %s can't be mounted in multi tenant isolation mode", source)
+		}
+	}
+	for _, m := range hostConfig.Mounts {
+		if m.Type == mount.TypeBind && !isolationModeAllowedSource(cfg, allowedSources, m.Source) {
+			return errors.Errorf("host path %s can't be mounted in multi tenant isolation mode", m.Source)
+		}
+	}
+
+	hostConfig.ReadonlyRootfs = true
+	return nil
+}
+
+// isolationModeAllowedSources returns the sources of the task volumes managed by
+// the agent or docker, that is all of them but the host volumes with a source path
+func isolationModeAllowedSources(task *apitask.Task) map[string]struct{} {
+	sources := make(map[string]struct{})
+	for _, vol := range task.Volumes {
+		if _, ok := vol.Volume.(*taskresourcevolume.FSHostVolume); ok {
+			continue
+		}
+		sources[vol.Volume.Source()] = struct{}{}
+	}
+	return sources
+}
+
+// isolationModeAllowedSource returns true if the source of a mount is a docker
+// volume name, the source of a volume managed by the agent or docker, or a path
+// under the data directory of the agent, such as the firelens config files
+func isolationModeAllowedSource(cfg *config.Config, allowedSources map[string]struct{}, source string) bool {
+	if !filepath.IsAbs(source) {
+		return true
+	}
+	if _, ok := allowedSources[source]; ok {
+		return true
+	}
+	if cfg.DataDirOnHost == "" {
+		return false
+	}
+	return strings.HasPrefix(filepath.Clean(source), filepath.Clean(cfg.DataDirOnHost)+string(filepath.Separator))
+}
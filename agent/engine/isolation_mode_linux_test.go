@@ -0,0 +1,147 @@
+// +build linux,unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"testing"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	taskresourcevolume "github.com/aws/amazon-ecs-agent/agent/taskresource/volume"
+	dockercontainer "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyIsolationMode(t *testing.T) {
+	task := &apitask.Task{
+		Arn: "arn:aws:ecs:us-west-2:123456789012:task/task-id",
+		Volumes: []apitask.TaskVolume{
+			{Name: "host", Type: apitask.HostVolumeType,
+				Volume: &taskresourcevolume.FSHostVolume{FSSourcePath: "/etc"}},
+			{Name: "empty", Type: apitask.HostVolumeType,
+				Volume: &taskresourcevolume.LocalDockerVolume{HostPath: "/var/lib/docker/volumes/empty/_data"}},
+			{Name: "docker", Type: apitask.DockerVolumeType,
+				Volume: &taskresourcevolume.DockerVolumeConfig{Scope: "task", DockerVolumeName: "docker"}},
+		},
+	}
+	cfg := &config.Config{
+		MultiTenantIsolationEnabled: true,
+		DataDirOnHost:               "/var/lib/ecs",
+	}
+
+	testCases := []struct {
+		name          string
+		cfg           *config.Config
+		containerType apicontainer.ContainerType
+		hostConfig    dockercontainer.HostConfig
+		expectedError bool
+		readonlyRoot  bool
+	}{
+		{
+			name:       "disabled",
+			cfg:        &config.Config{},
+			hostConfig: dockercontainer.HostConfig{Binds: []string{"/etc:/etc"}},
+		},
+		{
+			name:          "internal container",
+			cfg:           cfg,
+			containerType: apicontainer.ContainerCNIPause,
+			hostConfig:    dockercontainer.HostConfig{NetworkMode: "host"},
+		},
+		{
+			name: "allowed mounts",
+			cfg:  cfg,
+			hostConfig: dockercontainer.HostConfig{
+				Binds: []string{
+					"/var/lib/docker/volumes/empty/_data:/empty",
+					"docker:/docker:ro",
+					"/var/lib/ecs/data/firelens/task-id/config/fluent.conf:/fluent-bit/etc/fluent-bit.conf",
+				},
+			},
+			readonlyRoot: true,
+		},
+		{
+			name:          "host volume",
+			cfg:           cfg,
+			hostConfig:    dockercontainer.HostConfig{Binds: []string{"/etc:/etc"}},
+			expectedError: true,
+		},
+		{
+			name:          "bind outside of the data directory",
+			cfg:           cfg,
+			hostConfig:    dockercontainer.HostConfig{Binds: []string{"/var/lib/ecs/../../../root:/root"}},
+			expectedError: true,
+		},
+		{
+			name: "bind mount",
+			cfg:  cfg,
+			hostConfig: dockercontainer.HostConfig{
+				Mounts: []mount.Mount{{Type: mount.TypeBind, Source: "/", Target: "/host"}},
+			},
+			expectedError: true,
+		},
+		{
+			name:          "privileged",
+			cfg:           cfg,
+			hostConfig:    dockercontainer.HostConfig{Privileged: true},
+			expectedError: true,
+		},
+		{
+			name:          "host network",
+			cfg:           cfg,
+			hostConfig:    dockercontainer.HostConfig{NetworkMode: "host"},
+			expectedError: true,
+		},
+		{
+			name:          "host pid namespace",
+			cfg:           cfg,
+			hostConfig:    dockercontainer.HostConfig{PidMode: "host"},
+			expectedError: true,
+		},
+		{
+			name:          "host user namespace",
+			cfg:           cfg,
+			hostConfig:    dockercontainer.HostConfig{UsernsMode: "host"},
+			expectedError: true,
+		},
+		{
+			name: "host devices",
+			cfg:  cfg,
+			hostConfig: dockercontainer.HostConfig{
+				Resources: dockercontainer.Resources{
+					Devices: []dockercontainer.DeviceMapping{{PathOnHost: "/dev/sda", PathInContainer: "/dev/sda"}},
+				},
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			container := &apicontainer.Container{Name: "container", Type: tc.containerType}
+			hostConfig := tc.hostConfig
+			err := applyIsolationMode(tc.cfg, task, container, &hostConfig)
+			if tc.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.readonlyRoot, hostConfig.ReadonlyRootfs)
+		})
+	}
+}
@@ -0,0 +1,29 @@
+// +build !linux
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	dockercontainer "github.com/docker/docker/api/types/container"
+)
+
+// applyIsolationMode is a no-op, the multi tenant isolation mode is only supported on linux
+func applyIsolationMode(cfg *config.Config, task *apitask.Task, container *apicontainer.Container,
+	hostConfig *dockercontainer.HostConfig) error {
+	return nil
+}
@@ -0,0 +1,64 @@
+// +build linux
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"strings"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	dockercontainer "github.com/docker/docker/api/types/container"
+	"github.com/pkg/errors"
+)
+
+const kernelCapabilityPrefix = "CAP_"
+
+// applyKernelCapabilities adds and drops the kernel capabilities of a container.
+// Only the capabilities allowed by the agent config can be added, any of them
+// can be dropped
+func applyKernelCapabilities(cfg *config.Config, container *apicontainer.Container,
+	hostConfig *dockercontainer.HostConfig) error {
+	if container.KernelCapabilities == nil {
+		return nil
+	}
+
+	allowed := make(map[string]struct{})
+	for _, capability := range cfg.AllowedKernelCapabilities {
+		allowed[normalizeKernelCapability(capability)] = struct{}{}
+	}
+	var capAdd []string
+	for _, capability := range container.KernelCapabilities.Add {
+		capability = normalizeKernelCapability(capability)
+		if _, ok := allowed[capability]; !ok {
+			return errors.Errorf("kernel capability %s isn't allowed to be added by the agent config", capability)
+		}
+		capAdd = append(capAdd, capability)
+	}
+	var capDrop []string
+	for _, capability := range container.KernelCapabilities.Drop {
+		capDrop = append(capDrop, normalizeKernelCapability(capability))
+	}
+
+	hostConfig.CapAdd = append(hostConfig.CapAdd, capAdd...)
+	hostConfig.CapDrop = append(hostConfig.CapDrop, capDrop...)
+	return nil
+}
+
+// normalizeKernelCapability returns the name docker expects for a capability,
+// e.g. NET_ADMIN for cap_net_admin
+func normalizeKernelCapability(capability string) string {
+	return strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(capability)), kernelCapabilityPrefix)
+}
@@ -0,0 +1,80 @@
+// +build linux,unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"testing"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	dockercontainer "github.com/docker/docker/api/types/container"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyKernelCapabilities(t *testing.T) {
+	cfg := &config.Config{AllowedKernelCapabilities: []string{"SYS_NICE", "CAP_NET_ADMIN"}}
+
+	testCases := []struct {
+		name          string
+		capabilities  *apicontainer.KernelCapabilities
+		expectedAdd   []string
+		expectedDrop  []string
+		expectedError bool
+	}{
+		{
+			name: "no capabilities",
+		},
+		{
+			name: "allowed capabilities",
+			capabilities: &apicontainer.KernelCapabilities{
+				Add:  []string{"sys_nice", "CAP_NET_ADMIN"},
+				Drop: []string{"CAP_MKNOD", "net_raw"},
+			},
+			expectedAdd:  []string{"SYS_NICE", "NET_ADMIN"},
+			expectedDrop: []string{"MKNOD", "NET_RAW"},
+		},
+		{
+			name: "capability not allowed",
+			capabilities: &apicontainer.KernelCapabilities{
+				Add: []string{"SYS_ADMIN"},
+			},
+			expectedError: true,
+		},
+		{
+			name: "any capability dropped",
+			capabilities: &apicontainer.KernelCapabilities{
+				Drop: []string{"ALL"},
+			},
+			expectedDrop: []string{"ALL"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			container := &apicontainer.Container{Name: "c", KernelCapabilities: tc.capabilities}
+			hostConfig := &dockercontainer.HostConfig{}
+			err := applyKernelCapabilities(cfg, container, hostConfig)
+			if tc.expectedError {
+				assert.Error(t, err)
+				assert.Empty(t, hostConfig.CapAdd)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedAdd, []string(hostConfig.CapAdd))
+			assert.Equal(t, tc.expectedDrop, []string(hostConfig.CapDrop))
+		})
+	}
+}
@@ -0,0 +1,36 @@
+// +build !linux
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	dockercontainer "github.com/docker/docker/api/types/container"
+	"github.com/pkg/errors"
+)
+
+// applyKernelCapabilities rejects containers with kernel capabilities, they're
+// only supported on linux
+func applyKernelCapabilities(cfg *config.Config, container *apicontainer.Container,
+	hostConfig *dockercontainer.HostConfig) error {
+	if container.KernelCapabilities == nil {
+		return nil
+	}
+	if len(container.KernelCapabilities.Add) > 0 || len(container.KernelCapabilities.Drop) > 0 {
+		return errors.New("kernel capabilities are only supported on linux")
+	}
+	return nil
+}
@@ -238,6 +238,18 @@ func (mr *MockImageManagerMockRecorder) AddAllImageStates(arg0 interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAllImageStates", reflect.TypeOf((*MockImageManager)(nil).AddAllImageStates), arg0)
 }
 
+// ExcludeImagesFromCleanup mocks base method
+func (m *MockImageManager) ExcludeImagesFromCleanup(arg0 []string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ExcludeImagesFromCleanup", arg0)
+}
+
+// ExcludeImagesFromCleanup indicates an expected call of ExcludeImagesFromCleanup
+func (mr *MockImageManagerMockRecorder) ExcludeImagesFromCleanup(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExcludeImagesFromCleanup", reflect.TypeOf((*MockImageManager)(nil).ExcludeImagesFromCleanup), arg0)
+}
+
 // GetImageStateFromImageName mocks base method
 func (m *MockImageManager) GetImageStateFromImageName(arg0 string) (*image.ImageState, bool) {
 	m.ctrl.T.Helper()
@@ -304,3 +316,15 @@ func (mr *MockImageManagerMockRecorder) StartImageCleanupProcess(arg0 interface{
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartImageCleanupProcess", reflect.TypeOf((*MockImageManager)(nil).StartImageCleanupProcess), arg0)
 }
+
+// StartImageEventsListener mocks base method
+func (m *MockImageManager) StartImageEventsListener(arg0 context.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "StartImageEventsListener", arg0)
+}
+
+// StartImageEventsListener indicates an expected call of StartImageEventsListener
+func (mr *MockImageManagerMockRecorder) StartImageEventsListener(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartImageEventsListener", reflect.TypeOf((*MockImageManager)(nil).StartImageEventsListener), arg0)
+}
@@ -0,0 +1,56 @@
+// +build linux
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	dockercontainer "github.com/docker/docker/api/types/container"
+)
+
+// applyOOMScoreAdj sets the oom_score_adj of a container, so that the kernel
+// kills the sidecars of tasks before their essential containers when the
+// instance runs out of memory. The containers the agent runs for a task, such
+// as the pause container, are protected like essential containers, as the task
+// can't run without them. A score set in the docker host config of the
+// container takes precedence, then the OOM score policy of the task
+func applyOOMScoreAdj(cfg *config.Config, task *apitask.Task, container *apicontainer.Container,
+	hostConfig *dockercontainer.HostConfig) {
+	if hostConfig.OomScoreAdj != 0 {
+		return
+	}
+	hostConfig.OomScoreAdj = containerOOMScoreAdj(cfg, task, container)
+}
+
+// containerOOMScoreAdj returns the oom_score_adj the OOM score policy of the
+// task, or else the agent config, sets for a container of the task
+func containerOOMScoreAdj(cfg *config.Config, task *apitask.Task, container *apicontainer.Container) int {
+	policy := task.OOMScorePolicy
+	if policy == nil {
+		policy = &apitask.OOMScorePolicy{}
+	}
+	if container.IsInternal() || container.IsEssential() {
+		if policy.EssentialContainerOOMScoreAdj != nil {
+			return *policy.EssentialContainerOOMScoreAdj
+		}
+		return cfg.EssentialContainerOOMScoreAdj
+	}
+	if policy.NonEssentialContainerOOMScoreAdj != nil {
+		return *policy.NonEssentialContainerOOMScoreAdj
+	}
+	return cfg.NonEssentialContainerOOMScoreAdj
+}
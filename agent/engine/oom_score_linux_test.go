@@ -0,0 +1,95 @@
+// +build linux,unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"testing"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/aws-sdk-go/aws"
+	dockercontainer "github.com/docker/docker/api/types/container"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyOOMScoreAdj(t *testing.T) {
+	cfg := &config.Config{
+		EssentialContainerOOMScoreAdj:    -500,
+		NonEssentialContainerOOMScoreAdj: 500,
+	}
+
+	testCases := []struct {
+		name                string
+		container           *apicontainer.Container
+		hostConfigScore     int
+		expectedOOMScoreAdj int
+	}{
+		{
+			name:                "essential container",
+			container:           &apicontainer.Container{Name: "app", Essential: true},
+			expectedOOMScoreAdj: -500,
+		},
+		{
+			name:                "non-essential container",
+			container:           &apicontainer.Container{Name: "sidecar"},
+			expectedOOMScoreAdj: 500,
+		},
+		{
+			name:                "pause container",
+			container:           &apicontainer.Container{Name: "~internal~ecs~pause", Type: apicontainer.ContainerCNIPause},
+			expectedOOMScoreAdj: -500,
+		},
+		{
+			name:                "score set in the host config",
+			container:           &apicontainer.Container{Name: "sidecar"},
+			hostConfigScore:     100,
+			expectedOOMScoreAdj: 100,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			hostConfig := &dockercontainer.HostConfig{OomScoreAdj: tc.hostConfigScore}
+			applyOOMScoreAdj(cfg, &apitask.Task{}, tc.container, hostConfig)
+			assert.Equal(t, tc.expectedOOMScoreAdj, hostConfig.OomScoreAdj)
+		})
+	}
+}
+
+func TestApplyOOMScoreAdjNotConfigured(t *testing.T) {
+	hostConfig := &dockercontainer.HostConfig{}
+	applyOOMScoreAdj(&config.Config{}, &apitask.Task{}, &apicontainer.Container{Name: "app", Essential: true}, hostConfig)
+	assert.Zero(t, hostConfig.OomScoreAdj)
+}
+
+func TestApplyOOMScoreAdjTaskPolicy(t *testing.T) {
+	cfg := &config.Config{
+		EssentialContainerOOMScoreAdj:    -500,
+		NonEssentialContainerOOMScoreAdj: 500,
+	}
+	task := &apitask.Task{
+		OOMScorePolicy: &apitask.OOMScorePolicy{NonEssentialContainerOOMScoreAdj: aws.Int(1000)},
+	}
+
+	hostConfig := &dockercontainer.HostConfig{}
+	applyOOMScoreAdj(cfg, task, &apicontainer.Container{Name: "sidecar"}, hostConfig)
+	assert.Equal(t, 1000, hostConfig.OomScoreAdj)
+
+	hostConfig = &dockercontainer.HostConfig{}
+	applyOOMScoreAdj(cfg, task, &apicontainer.Container{Name: "app", Essential: true}, hostConfig)
+	assert.Equal(t, -500, hostConfig.OomScoreAdj, "the scores the policy doesn't set fall back to the agent config")
+}
@@ -0,0 +1,140 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apicontainerstatus "github.com/aws/amazon-ecs-agent/agent/api/container/status"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/portforward"
+	"github.com/cihub/seelog"
+	"github.com/pkg/errors"
+)
+
+// PortPublisher is implemented by task engines that can publish ports of
+// awsvpc tasks on the IP address of the instance
+type PortPublisher interface {
+	// SetPortPublishingAddress sets the IP address of the instance the ports
+	// in ECS_AWSVPC_PUBLISHED_PORTS are published on. It has to be called
+	// before the engine is initialized for the ports of restored tasks to be
+	// published again
+	SetPortPublishingAddress(hostIP string)
+}
+
+// SetPortPublishingAddress sets the IP address the ports of awsvpc tasks are
+// published on
+func (engine *DockerTaskEngine) SetPortPublishingAddress(hostIP string) {
+	if len(engine.cfg.AWSVPCPublishedPorts) == 0 {
+		return
+	}
+	if hostIP == "" {
+		seelog.Errorf("Task engine: unable to publish the ports of awsvpc tasks without the IP address of the instance")
+		return
+	}
+	engine.portForwarder = portforward.NewForwarder(hostIP)
+}
+
+// publishedPorts returns the port mappings of the task that are published on
+// the IP address of the instance
+func (engine *DockerTaskEngine) publishedPorts(task *apitask.Task) []portforward.Mapping {
+	if engine.portForwarder == nil || !task.IsNetworkModeAWSVPC() {
+		return nil
+	}
+	published := make(map[uint16]struct{})
+	for _, port := range engine.cfg.AWSVPCPublishedPorts {
+		published[port] = struct{}{}
+	}
+
+	var mappings []portforward.Mapping
+	seen := make(map[portforward.Mapping]struct{})
+	for _, container := range task.Containers {
+		for _, port := range container.Ports {
+			if _, ok := published[port.ContainerPort]; !ok {
+				continue
+			}
+			mapping := portforward.Mapping{Protocol: port.Protocol.String(), Port: port.ContainerPort}
+			if _, ok := seen[mapping]; ok {
+				continue
+			}
+			seen[mapping] = struct{}{}
+			mappings = append(mappings, mapping)
+		}
+	}
+	return mappings
+}
+
+// publishTaskPorts publishes the ports of the task IP on the IP address of the
+// instance. The ports of tasks with an ipv6 only ENI can't be published, as
+// they're forwarded from the ipv4 address of the instance
+func (engine *DockerTaskEngine) publishTaskPorts(task *apitask.Task, taskIP string) error {
+	mappings := engine.publishedPorts(task)
+	if len(mappings) == 0 {
+		return nil
+	}
+	if eni := task.GetPrimaryENI(); eni != nil && eni.IsIPv6Only() {
+		return errors.Errorf("unable to publish the task ports on the instance, eni %s of the task has no ipv4 address", eni.ID)
+	}
+	if err := engine.portForwarder.Forward(engine.ctx, task.Arn, taskIP, mappings); err != nil {
+		return errors.Wrap(err, "unable to publish the task ports on the instance")
+	}
+	seelog.Infof("Task engine [%s]: published ports %v of %s on the instance", task.Arn, mappings, taskIP)
+	return nil
+}
+
+// unpublishTaskPorts removes the rules publishing the ports of the task, and
+// frees its ports for other tasks
+func (engine *DockerTaskEngine) unpublishTaskPorts(task *apitask.Task) {
+	if engine.portForwarder == nil {
+		return
+	}
+	if err := engine.portForwarder.Remove(engine.ctx, task.Arn); err != nil {
+		seelog.Warnf("Task engine [%s]: unable to remove the rules publishing the task ports: %v", task.Arn, err)
+	}
+}
+
+// republishRestoredTaskPorts publishes the ports of the restored tasks whose
+// network is still set up, so that their ports are tracked again and the rules
+// removed while the agent was down are added back. The rules of the other
+// tasks, whose network was cleaned up while the agent was down, are removed
+func (engine *DockerTaskEngine) republishRestoredTaskPorts(tasks []*apitask.Task) {
+	if engine.portForwarder == nil {
+		return
+	}
+	for _, task := range tasks {
+		eni := task.GetPrimaryENI()
+		if eni == nil || !hasProvisionedNetwork(task) {
+			continue
+		}
+		if err := engine.publishTaskPorts(task, eni.GetPrimaryIPv4Address()); err != nil {
+			seelog.Warnf("Task engine [%s]: %v", task.Arn, err)
+		}
+	}
+	if err := engine.portForwarder.RemoveStale(engine.ctx); err != nil {
+		seelog.Warnf("Task engine: unable to remove the stale rules publishing task ports: %v", err)
+	}
+}
+
+// hasProvisionedNetwork returns whether the network of the pause container of
+// the task is set up and not cleaned up yet
+func hasProvisionedNetwork(task *apitask.Task) bool {
+	for _, container := range task.Containers {
+		if container.Type != apicontainer.ContainerCNIPause {
+			continue
+		}
+		status := container.GetKnownStatus()
+		return status >= apicontainerstatus.ContainerResourcesProvisioned &&
+			status < apicontainerstatus.ContainerStopped
+	}
+	return false
+}
@@ -0,0 +1,175 @@
+// +build unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"testing"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apicontainerstatus "github.com/aws/amazon-ecs-agent/agent/api/container/status"
+	apieni "github.com/aws/amazon-ecs-agent/agent/api/eni"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/portforward"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakePortForwarder records the ports forwarded for every owner
+type fakePortForwarder struct {
+	forwarded map[string][]portforward.Mapping
+	taskIPs   map[string]string
+	// removedStale is set once the stale rules are removed
+	removedStale bool
+}
+
+func newFakePortForwarder() *fakePortForwarder {
+	return &fakePortForwarder{
+		forwarded: make(map[string][]portforward.Mapping),
+		taskIPs:   make(map[string]string),
+	}
+}
+
+func (f *fakePortForwarder) Forward(ctx context.Context, owner string, taskIP string, mappings []portforward.Mapping) error {
+	f.forwarded[owner] = mappings
+	f.taskIPs[owner] = taskIP
+	return nil
+}
+
+func (f *fakePortForwarder) Remove(ctx context.Context, owner string) error {
+	delete(f.forwarded, owner)
+	delete(f.taskIPs, owner)
+	return nil
+}
+
+func (f *fakePortForwarder) RemoveStale(ctx context.Context) error {
+	f.removedStale = true
+	return nil
+}
+
+func awsvpcTaskWithPorts(pauseStatus apicontainerstatus.ContainerStatus) *apitask.Task {
+	udp := apicontainer.TransportProtocolUDP
+	task := &apitask.Task{
+		Arn: "arn:aws:ecs:us-west-2:123456789012:task/task-id",
+		ENIs: []*apieni.ENI{{
+			IPV4Addresses: []*apieni.ENIIPV4Address{{Primary: true, Address: "10.0.0.20"}},
+		}},
+		Containers: []*apicontainer.Container{
+			{
+				Name: "web",
+				Ports: []apicontainer.PortBinding{
+					{ContainerPort: 80, HostPort: 80},
+					{ContainerPort: 8080, HostPort: 8080},
+				},
+			},
+			{
+				Name:  "dns",
+				Ports: []apicontainer.PortBinding{{ContainerPort: 80, HostPort: 80, Protocol: udp}},
+			},
+			{
+				Name:  "sidecar",
+				Ports: []apicontainer.PortBinding{{ContainerPort: 80, HostPort: 80}},
+			},
+		},
+	}
+	pause := apicontainer.NewContainerWithSteadyState(apicontainerstatus.ContainerResourcesProvisioned)
+	pause.Name = "~internal~ecs~pause"
+	pause.Type = apicontainer.ContainerCNIPause
+	pause.SetKnownStatus(pauseStatus)
+	task.Containers = append(task.Containers, pause)
+	return task
+}
+
+func TestPublishTaskPorts(t *testing.T) {
+	forwarder := newFakePortForwarder()
+	engine := &DockerTaskEngine{
+		ctx:           context.TODO(),
+		cfg:           &config.Config{AWSVPCPublishedPorts: []uint16{80}},
+		portForwarder: forwarder,
+	}
+	task := awsvpcTaskWithPorts(apicontainerstatus.ContainerStatusNone)
+
+	assert.NoError(t, engine.publishTaskPorts(task, "10.0.0.20"))
+	assert.Equal(t, []portforward.Mapping{{Protocol: "tcp", Port: 80}, {Protocol: "udp", Port: 80}},
+		forwarder.forwarded[task.Arn])
+	assert.Equal(t, "10.0.0.20", forwarder.taskIPs[task.Arn])
+
+	engine.unpublishTaskPorts(task)
+	assert.Empty(t, forwarder.forwarded)
+}
+
+func TestPublishTaskPortsIPv6OnlyENI(t *testing.T) {
+	forwarder := newFakePortForwarder()
+	engine := &DockerTaskEngine{
+		ctx:           context.TODO(),
+		cfg:           &config.Config{AWSVPCPublishedPorts: []uint16{80}},
+		portForwarder: forwarder,
+	}
+	task := awsvpcTaskWithPorts(apicontainerstatus.ContainerStatusNone)
+	task.ENIs[0].IPV4Addresses = nil
+	task.ENIs[0].IPV6Addresses = []*apieni.ENIIPV6Address{{Address: "2600:1f14:abcd::10"}}
+
+	// The ports are published on the ipv4 address of the instance
+	assert.Error(t, engine.publishTaskPorts(task, "2600:1f14:abcd::10"))
+	assert.Empty(t, forwarder.forwarded)
+}
+
+func TestPublishTaskPortsNonAWSVPCTask(t *testing.T) {
+	forwarder := newFakePortForwarder()
+	engine := &DockerTaskEngine{
+		ctx:           context.TODO(),
+		cfg:           &config.Config{AWSVPCPublishedPorts: []uint16{80}},
+		portForwarder: forwarder,
+	}
+	task := awsvpcTaskWithPorts(apicontainerstatus.ContainerStatusNone)
+	task.ENIs = nil
+
+	assert.NoError(t, engine.publishTaskPorts(task, "10.0.0.20"))
+	assert.Empty(t, forwarder.forwarded)
+}
+
+func TestSetPortPublishingAddress(t *testing.T) {
+	engine := &DockerTaskEngine{cfg: &config.Config{}}
+	engine.SetPortPublishingAddress("10.0.0.10")
+	assert.Nil(t, engine.portForwarder, "no ports to publish")
+
+	engine.cfg.AWSVPCPublishedPorts = []uint16{80}
+	engine.SetPortPublishingAddress("")
+	assert.Nil(t, engine.portForwarder, "no instance IP")
+
+	engine.SetPortPublishingAddress("10.0.0.10")
+	assert.NotNil(t, engine.portForwarder)
+}
+
+func TestRepublishRestoredTaskPorts(t *testing.T) {
+	forwarder := newFakePortForwarder()
+	engine := &DockerTaskEngine{
+		ctx:           context.TODO(),
+		cfg:           &config.Config{AWSVPCPublishedPorts: []uint16{8080}},
+		portForwarder: forwarder,
+	}
+	running := awsvpcTaskWithPorts(apicontainerstatus.ContainerResourcesProvisioned)
+	stopped := awsvpcTaskWithPorts(apicontainerstatus.ContainerStopped)
+	stopped.Arn = "arn:aws:ecs:us-west-2:123456789012:task/stopped"
+	pending := awsvpcTaskWithPorts(apicontainerstatus.ContainerRunning)
+	pending.Arn = "arn:aws:ecs:us-west-2:123456789012:task/pending"
+
+	engine.republishRestoredTaskPorts([]*apitask.Task{running, stopped, pending})
+	assert.Len(t, forwarder.forwarded, 1)
+	assert.Equal(t, []portforward.Mapping{{Protocol: "tcp", Port: 8080}}, forwarder.forwarded[running.Arn])
+	assert.Equal(t, "10.0.0.20", forwarder.taskIPs[running.Arn])
+	assert.True(t, forwarder.removedStale, "the rules of the stopped task should be removed")
+}
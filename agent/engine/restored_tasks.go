@@ -0,0 +1,107 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/cihub/seelog"
+)
+
+// restoredTasksPollInterval is the interval at which the reporting of the
+// restored tasks is checked
+var restoredTasksPollInterval = time.Second
+
+// WaitForRestoredTasksReported waits for the states of the tasks restored from
+// the state file, which were reconciled with docker when the engine was
+// initialized, to be reported to ECS. It gives up after the timeout and
+// returns whether all of them were reported
+func (engine *DockerTaskEngine) WaitForRestoredTasksReported(ctx context.Context, timeout time.Duration) bool {
+	engine.tasksLock.RLock()
+	restoredTasks := engine.restoredTasks
+	engine.tasksLock.RUnlock()
+
+	unreported := unreportedTasks(restoredTasks)
+	if len(unreported) == 0 {
+		return true
+	}
+	seelog.Infof("Task engine: waiting up to %s for the states of %d restored tasks to be reported",
+		timeout.String(), len(unreported))
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	ticker := time.NewTicker(restoredTasksPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-timer.C:
+			seelog.Warnf("Task engine: timed out waiting for the states of restored tasks to be reported: [%s]",
+				strings.Join(taskARNs(unreported), ", "))
+			return false
+		case <-ticker.C:
+			unreported = unreportedTasks(unreported)
+			if len(unreported) == 0 {
+				seelog.Info("Task engine: the states of all restored tasks were reported")
+				return true
+			}
+		}
+	}
+}
+
+// unreportedTasks returns the tasks with a state ECS hasn't been told about
+// yet, either their own or the one of one of their containers
+func unreportedTasks(tasks []*apitask.Task) []*apitask.Task {
+	var unreported []*apitask.Task
+	for _, task := range tasks {
+		// The states of static tasks are only recorded on the instance
+		if task.IsStatic() {
+			continue
+		}
+		if taskStateUnreported(task) {
+			unreported = append(unreported, task)
+		}
+	}
+	return unreported
+}
+
+func taskStateUnreported(task *apitask.Task) bool {
+	taskKnownStatus := task.GetKnownStatus()
+	if taskKnownStatus.BackendRecognized() && task.GetSentStatus() < taskKnownStatus {
+		return true
+	}
+	for _, container := range task.Containers {
+		if container.IsInternal() {
+			continue
+		}
+		containerKnownStatus := container.GetKnownStatus()
+		if containerKnownStatus.ShouldReportToBackend(container.GetSteadyStateStatus()) &&
+			container.GetSentStatus() < containerKnownStatus {
+			return true
+		}
+	}
+	return false
+}
+
+func taskARNs(tasks []*apitask.Task) []string {
+	arns := make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		arns = append(arns, task.Arn)
+	}
+	return arns
+}
@@ -0,0 +1,97 @@
+// +build unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apicontainerstatus "github.com/aws/amazon-ecs-agent/agent/api/container/status"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	apitaskstatus "github.com/aws/amazon-ecs-agent/agent/api/task/status"
+	"github.com/stretchr/testify/assert"
+)
+
+func newRestoredTask(arn string) *apitask.Task {
+	task := &apitask.Task{
+		Arn: arn,
+		Containers: []*apicontainer.Container{
+			{Name: "c1"},
+		},
+	}
+	task.SetKnownStatus(apitaskstatus.TaskRunning)
+	task.SetSentStatus(apitaskstatus.TaskRunning)
+	task.Containers[0].SetKnownStatus(apicontainerstatus.ContainerRunning)
+	task.Containers[0].SetSentStatus(apicontainerstatus.ContainerRunning)
+	return task
+}
+
+func useFastRestoredTasksPollInterval() func() {
+	pollInterval := restoredTasksPollInterval
+	restoredTasksPollInterval = time.Millisecond
+	return func() {
+		restoredTasksPollInterval = pollInterval
+	}
+}
+
+func TestWaitForRestoredTasksReported(t *testing.T) {
+	defer useFastRestoredTasksPollInterval()()
+
+	reported := newRestoredTask("reported")
+	// The container of this task stopped while the agent was down
+	stopped := newRestoredTask("stopped")
+	stopped.Containers[0].SetKnownStatus(apicontainerstatus.ContainerStopped)
+	taskEngine := &DockerTaskEngine{
+		restoredTasks: []*apitask.Task{reported, stopped},
+	}
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		stopped.Containers[0].SetSentStatus(apicontainerstatus.ContainerStopped)
+	}()
+	assert.True(t, taskEngine.WaitForRestoredTasksReported(context.TODO(), time.Minute))
+}
+
+func TestWaitForRestoredTasksReportedTimeout(t *testing.T) {
+	defer useFastRestoredTasksPollInterval()()
+
+	stopped := newRestoredTask("stopped")
+	stopped.SetKnownStatus(apitaskstatus.TaskStopped)
+	taskEngine := &DockerTaskEngine{
+		restoredTasks: []*apitask.Task{stopped},
+	}
+
+	assert.False(t, taskEngine.WaitForRestoredTasksReported(context.TODO(), 10*time.Millisecond))
+}
+
+func TestWaitForRestoredTasksReportedIgnoresUnreportableStates(t *testing.T) {
+	// Neither the created container nor the static task are reported to ECS
+	created := newRestoredTask("created")
+	created.SetKnownStatus(apitaskstatus.TaskCreated)
+	created.SetSentStatus(apitaskstatus.TaskStatusNone)
+	created.Containers[0].SetKnownStatus(apicontainerstatus.ContainerCreated)
+	created.Containers[0].SetSentStatus(apicontainerstatus.ContainerStatusNone)
+	static := newRestoredTask("static")
+	static.StaticName = "static"
+	static.SetKnownStatus(apitaskstatus.TaskStopped)
+	taskEngine := &DockerTaskEngine{
+		restoredTasks: []*apitask.Task{created, static},
+	}
+
+	assert.True(t, taskEngine.WaitForRestoredTasksReported(context.TODO(), time.Nanosecond))
+}
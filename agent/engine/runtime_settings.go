@@ -0,0 +1,161 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	apicontainerstatus "github.com/aws/amazon-ecs-agent/agent/api/container/status"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/dockerclient"
+	"github.com/cihub/seelog"
+	"github.com/pkg/errors"
+)
+
+const (
+	// runtimeSettingsDirectory is the directory, under the data directory of
+	// the agent, of the runtime settings of each task
+	runtimeSettingsDirectory = "runtime-settings"
+	// runtimeSettingsFile is the name of the file the runtime settings of a
+	// task are written to, as a JSON object
+	runtimeSettingsFile = "settings.json"
+	// runtimeSettingsFileEnv is the environment variable with the path of the
+	// runtime settings file in the containers of a task
+	runtimeSettingsFileEnv = "ECS_RUNTIME_SETTINGS_FILE"
+	// labelRuntimeSettingsSignal is the docker label of the containers that
+	// are sent a signal, such as SIGHUP, after their runtime settings change
+	labelRuntimeSettingsSignal = labelPrefix + "runtime-settings-signal"
+)
+
+// UpdateTaskRuntimeSettings replaces the runtime settings of a running task,
+// writes them to its runtime settings file and signals the containers that
+// ask for it. Settings older than the ones the task has are ignored
+func (engine *DockerTaskEngine) UpdateTaskRuntimeSettings(taskArn string, settings map[string]string, version int64) error {
+	if !runtimeSettingsSupported {
+		return errors.New("task runtime settings are only supported on linux")
+	}
+	if !engine.cfg.TaskRuntimeSettingsEnabled {
+		return errors.New("task runtime settings aren't enabled")
+	}
+	task, ok := engine.state.TaskByArn(taskArn)
+	if !ok {
+		return errors.Errorf("task %s not found", taskArn)
+	}
+	if task.GetKnownStatus().Terminal() {
+		return errors.Errorf("task %s is stopped", taskArn)
+	}
+	if !task.SetRuntimeSettings(settings, version) {
+		seelog.Infof("Task engine [%s]: ignoring runtime settings version %d, older than version %d",
+			taskArn, version, task.RuntimeSettingsVersion)
+		return nil
+	}
+	engine.saver.Save()
+
+	if err := engine.writeRuntimeSettings(task); err != nil {
+		return err
+	}
+	seelog.Infof("Task engine [%s]: updated runtime settings to version %d", taskArn, version)
+	engine.signalRuntimeSettings(task)
+	return nil
+}
+
+// runtimeSettingsDir returns the directory the agent writes the runtime
+// settings of the task to
+func (engine *DockerTaskEngine) runtimeSettingsDir(task *apitask.Task) (string, error) {
+	taskID, err := task.GetID()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(engine.cfg.DataDir, runtimeSettingsDirectory, taskID), nil
+}
+
+// writeRuntimeSettings writes the runtime settings of the task to its runtime
+// settings file. The file is replaced with a rename, so that containers never
+// read a partially written file
+func (engine *DockerTaskEngine) writeRuntimeSettings(task *apitask.Task) error {
+	dir, err := engine.runtimeSettingsDir(task)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return errors.Wrap(err, "unable to create the runtime settings directory")
+	}
+	data, err := json.Marshal(task.GetRuntimeSettings())
+	if err != nil {
+		return errors.Wrap(err, "unable to marshal the runtime settings")
+	}
+
+	temp, err := ioutil.TempFile(dir, runtimeSettingsFile)
+	if err != nil {
+		return errors.Wrap(err, "unable to create the runtime settings file")
+	}
+	defer os.Remove(temp.Name())
+	if _, err := temp.Write(data); err != nil {
+		temp.Close()
+		return errors.Wrap(err, "unable to write the runtime settings file")
+	}
+	if err := temp.Chmod(0644); err != nil {
+		temp.Close()
+		return errors.Wrap(err, "unable to write the runtime settings file")
+	}
+	if err := temp.Close(); err != nil {
+		return errors.Wrap(err, "unable to write the runtime settings file")
+	}
+	if err := os.Rename(temp.Name(), filepath.Join(dir, runtimeSettingsFile)); err != nil {
+		return errors.Wrap(err, "unable to replace the runtime settings file")
+	}
+	return nil
+}
+
+// signalRuntimeSettings sends the signal of their runtime settings signal
+// label to the running containers of the task that have one. Other containers
+// are expected to watch the runtime settings file
+func (engine *DockerTaskEngine) signalRuntimeSettings(task *apitask.Task) {
+	for _, container := range task.Containers {
+		signal := container.GetLabels()[labelRuntimeSettingsSignal]
+		if signal == "" || container.GetKnownStatus() != apicontainerstatus.ContainerRunning {
+			continue
+		}
+		dockerID := container.GetRuntimeID()
+		if dockerID == "" {
+			continue
+		}
+		if err := engine.client.KillContainer(engine.ctx, dockerID, signal, dockerclient.KillContainerTimeout); err != nil {
+			seelog.Warnf("Task engine [%s]: unable to send %s to container %s after updating its runtime settings: %v",
+				task.Arn, signal, container.Name, err)
+		}
+	}
+}
+
+// runtimeSettingsCleanupSteps returns the step removing the runtime settings
+// directory of the task, if task runtime settings are enabled
+func (engine *DockerTaskEngine) runtimeSettingsCleanupSteps(task *apitask.Task) []cleanupStep {
+	if !runtimeSettingsSupported || !engine.cfg.TaskRuntimeSettingsEnabled {
+		return nil
+	}
+	return []cleanupStep{{
+		name:     "clean task runtime settings",
+		attempts: cleanupStepAttempts,
+		run: func() error {
+			dir, err := engine.runtimeSettingsDir(task)
+			if err != nil {
+				return err
+			}
+			return os.RemoveAll(dir)
+		},
+	}}
+}
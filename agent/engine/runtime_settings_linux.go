@@ -0,0 +1,55 @@
+// +build linux
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"fmt"
+	"path/filepath"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	dockercontainer "github.com/docker/docker/api/types/container"
+)
+
+const (
+	runtimeSettingsSupported = true
+	// runtimeSettingsContainerDir is the directory the runtime settings of a
+	// task are mounted at in its containers. The directory is mounted rather
+	// than the file, so that containers see the file replaced on updates
+	runtimeSettingsContainerDir = "/etc/ecs/runtime-settings"
+)
+
+// applyRuntimeSettingsMount writes the current runtime settings of the task
+// and mounts them read-only in the container, with the path of the file in
+// the ECS_RUNTIME_SETTINGS_FILE environment variable
+func (engine *DockerTaskEngine) applyRuntimeSettingsMount(task *apitask.Task, container *apicontainer.Container,
+	config *dockercontainer.Config, hostConfig *dockercontainer.HostConfig) error {
+	if !engine.cfg.TaskRuntimeSettingsEnabled || container.IsInternal() {
+		return nil
+	}
+	if err := engine.writeRuntimeSettings(task); err != nil {
+		return err
+	}
+	taskID, err := task.GetID()
+	if err != nil {
+		return err
+	}
+	source := filepath.Join(engine.cfg.DataDirOnHost, "data", runtimeSettingsDirectory, taskID)
+	hostConfig.Binds = append(hostConfig.Binds, fmt.Sprintf("%s:%s:ro", source, runtimeSettingsContainerDir))
+	config.Env = append(config.Env, fmt.Sprintf("%s=%s", runtimeSettingsFileEnv,
+		filepath.Join(runtimeSettingsContainerDir, runtimeSettingsFile)))
+	return nil
+}
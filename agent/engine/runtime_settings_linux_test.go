@@ -0,0 +1,140 @@
+// +build linux,unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apicontainerstatus "github.com/aws/amazon-ecs-agent/agent/api/container/status"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	apitaskstatus "github.com/aws/amazon-ecs-agent/agent/api/task/status"
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/dockerclient"
+	mock_dockerapi "github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi/mocks"
+	"github.com/aws/amazon-ecs-agent/agent/engine/dockerstate"
+	"github.com/aws/amazon-ecs-agent/agent/statemanager"
+	dockercontainer "github.com/docker/docker/api/types/container"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const runtimeSettingsTaskArn = "arn:aws:ecs:us-west-2:1234567890:task/task-id"
+
+func newRuntimeSettingsTaskEngine(t *testing.T, ctrl *gomock.Controller) (*DockerTaskEngine, *mock_dockerapi.MockDockerClient, string) {
+	dataDir, err := ioutil.TempDir("", "runtime-settings")
+	require.NoError(t, err)
+	client := mock_dockerapi.NewMockDockerClient(ctrl)
+	return &DockerTaskEngine{
+		ctx: context.Background(),
+		cfg: &config.Config{
+			DataDir:                    dataDir,
+			DataDirOnHost:              "/var/lib/ecs",
+			TaskRuntimeSettingsEnabled: true,
+		},
+		client: client,
+		state:  dockerstate.NewTaskEngineState(),
+		saver:  statemanager.NewNoopStateManager(),
+	}, client, dataDir
+}
+
+func readRuntimeSettings(t *testing.T, dataDir string) string {
+	data, err := ioutil.ReadFile(filepath.Join(dataDir, "runtime-settings", "task-id", "settings.json"))
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestApplyRuntimeSettingsMount(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	taskEngine, _, dataDir := newRuntimeSettingsTaskEngine(t, ctrl)
+	defer os.RemoveAll(dataDir)
+
+	task := &apitask.Task{Arn: runtimeSettingsTaskArn}
+	config := &dockercontainer.Config{}
+	hostConfig := &dockercontainer.HostConfig{}
+	require.NoError(t, taskEngine.applyRuntimeSettingsMount(task, &apicontainer.Container{Name: "app"}, config, hostConfig))
+	assert.Equal(t, []string{"/var/lib/ecs/data/runtime-settings/task-id:/etc/ecs/runtime-settings:ro"}, hostConfig.Binds)
+	assert.Equal(t, []string{"ECS_RUNTIME_SETTINGS_FILE=/etc/ecs/runtime-settings/settings.json"}, config.Env)
+	assert.Equal(t, "{}", readRuntimeSettings(t, dataDir))
+
+	// Containers the agent runs for the task don't get the settings
+	config = &dockercontainer.Config{}
+	hostConfig = &dockercontainer.HostConfig{}
+	require.NoError(t, taskEngine.applyRuntimeSettingsMount(task, &apicontainer.Container{
+		Name: "pause",
+		Type: apicontainer.ContainerCNIPause,
+	}, config, hostConfig))
+	assert.Empty(t, hostConfig.Binds)
+	assert.Empty(t, config.Env)
+}
+
+func TestUpdateTaskRuntimeSettings(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	taskEngine, client, dataDir := newRuntimeSettingsTaskEngine(t, ctrl)
+	defer os.RemoveAll(dataDir)
+
+	signalled := &apicontainer.Container{Name: "signalled", KnownStatusUnsafe: apicontainerstatus.ContainerRunning}
+	signalled.SetRuntimeID("signalled-id")
+	signalled.SetLabels(map[string]string{labelRuntimeSettingsSignal: "SIGHUP"})
+	stopped := &apicontainer.Container{Name: "stopped", KnownStatusUnsafe: apicontainerstatus.ContainerStopped}
+	stopped.SetRuntimeID("stopped-id")
+	stopped.SetLabels(map[string]string{labelRuntimeSettingsSignal: "SIGHUP"})
+	watching := &apicontainer.Container{Name: "watching", KnownStatusUnsafe: apicontainerstatus.ContainerRunning}
+	watching.SetRuntimeID("watching-id")
+	task := &apitask.Task{
+		Arn:               runtimeSettingsTaskArn,
+		KnownStatusUnsafe: apitaskstatus.TaskRunning,
+		Containers:        []*apicontainer.Container{signalled, stopped, watching},
+	}
+	taskEngine.state.AddTask(task)
+
+	client.EXPECT().KillContainer(gomock.Any(), "signalled-id", "SIGHUP", dockerclient.KillContainerTimeout).Return(nil)
+	require.NoError(t, taskEngine.UpdateTaskRuntimeSettings(task.Arn, map[string]string{"feature": "on"}, 2))
+	assert.Equal(t, `{"feature":"on"}`, readRuntimeSettings(t, dataDir))
+
+	// Older settings are ignored
+	require.NoError(t, taskEngine.UpdateTaskRuntimeSettings(task.Arn, map[string]string{"feature": "off"}, 1))
+	assert.Equal(t, `{"feature":"on"}`, readRuntimeSettings(t, dataDir))
+
+	assert.Error(t, taskEngine.UpdateTaskRuntimeSettings("unknown", map[string]string{"feature": "off"}, 3))
+
+	steps := taskEngine.runtimeSettingsCleanupSteps(task)
+	require.Len(t, steps, 1)
+	require.NoError(t, steps[0].run())
+	_, err := os.Stat(filepath.Join(dataDir, "runtime-settings", "task-id"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestUpdateTaskRuntimeSettingsDisabled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	taskEngine, _, dataDir := newRuntimeSettingsTaskEngine(t, ctrl)
+	defer os.RemoveAll(dataDir)
+	taskEngine.cfg.TaskRuntimeSettingsEnabled = false
+
+	task := &apitask.Task{Arn: runtimeSettingsTaskArn, KnownStatusUnsafe: apitaskstatus.TaskRunning}
+	taskEngine.state.AddTask(task)
+	assert.Error(t, taskEngine.UpdateTaskRuntimeSettings(task.Arn, map[string]string{"feature": "on"}, 1))
+	assert.Empty(t, task.GetRuntimeSettings())
+	assert.Empty(t, taskEngine.runtimeSettingsCleanupSteps(task))
+}
@@ -0,0 +1,111 @@
+// +build linux
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"strings"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/cihub/seelog"
+	dockercontainer "github.com/docker/docker/api/types/container"
+)
+
+const (
+	// labelSecurityBaseline is the docker label used by containers to opt out
+	// of the security baseline, when allowed by the agent config
+	labelSecurityBaseline = labelPrefix + "security-baseline"
+	// securityBaselineOptOut is the value of labelSecurityBaseline to opt out
+	securityBaselineOptOut = "disabled"
+	// securityOptNoNewPrivileges prevents the container processes from gaining
+	// new privileges, such as with setuid binaries
+	securityOptNoNewPrivileges = "no-new-privileges"
+	// securityOptSeccomp is the security option to set the seccomp profile
+	securityOptSeccomp = "seccomp"
+	// securityOptSystemPaths is the security option to unmask the paths
+	// docker masks in containers
+	securityOptSystemPaths = "systempaths"
+	// securityOptUnconfined disables the seccomp profile or the masked paths
+	securityOptUnconfined = "unconfined"
+)
+
+// applySecurityBaseline hardens the host config of task containers when the
+// security baseline is enabled: no-new-privileges is set, and the default
+// seccomp profile, the paths masked by the docker daemon and the user namespace
+// the daemon remaps containers to can't be opted out of. Containers can only
+// opt out of the baseline with the security baseline label if the config allows
+// it.
+func applySecurityBaseline(cfg *config.Config, task *apitask.Task, container *apicontainer.Container,
+	dockerConfig *dockercontainer.Config, hostConfig *dockercontainer.HostConfig) {
+	if !cfg.SecurityBaselineEnabled || container.IsInternal() {
+		return
+	}
+
+	if dockerConfig.Labels[labelSecurityBaseline] == securityBaselineOptOut {
+		if cfg.SecurityBaselineAllowOptOut {
+			seelog.Infof("Task engine [%s]: container %s opted out of the security baseline",
+				task.Arn, container.Name)
+			return
+		}
+		seelog.Warnf("Task engine [%s]: container %s can't opt out of the security baseline, applying it",
+			task.Arn, container.Name)
+	}
+
+	var securityOpts []string
+	for _, opt := range hostConfig.SecurityOpt {
+		name, value := parseSecurityOpt(opt)
+		if name == securityOptNoNewPrivileges {
+			// Replaced by no-new-privileges below
+			continue
+		}
+		if (name == securityOptSeccomp || name == securityOptSystemPaths) && value == securityOptUnconfined {
+			seelog.Warnf("Task engine [%s]: removing security option %s of container %s, not allowed by the security baseline",
+				task.Arn, opt, container.Name)
+			continue
+		}
+		securityOpts = append(securityOpts, opt)
+	}
+	hostConfig.SecurityOpt = append(securityOpts, securityOptNoNewPrivileges)
+
+	// The masked paths of a container replace the ones of the daemon, which
+	// are kept up to date with the paths of the kernel to mask
+	if hostConfig.MaskedPaths != nil {
+		seelog.Warnf("Task engine [%s]: removing the masked paths of container %s, not allowed by the security baseline",
+			task.Arn, container.Name)
+		hostConfig.MaskedPaths = nil
+	}
+
+	// Docker only runs privileged containers, and the ones sharing the network
+	// or pid namespace of the host, in the user namespace of the host when the
+	// daemon remaps the other containers to their own user namespace
+	if hostConfig.UsernsMode.IsHost() && !hostConfig.Privileged &&
+		!hostConfig.NetworkMode.IsHost() && !hostConfig.PidMode.IsHost() {
+		seelog.Warnf("Task engine [%s]: removing the host user namespace mode of container %s, not allowed by the security baseline",
+			task.Arn, container.Name)
+		hostConfig.UsernsMode = ""
+	}
+}
+
+// parseSecurityOpt returns the name and value of a docker security option,
+// which can be separated by either '=' or ':'
+func parseSecurityOpt(opt string) (string, string) {
+	idx := strings.IndexAny(opt, "=:")
+	if idx < 0 {
+		return opt, ""
+	}
+	return opt[:idx], opt[idx+1:]
+}
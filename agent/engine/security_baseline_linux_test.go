@@ -0,0 +1,128 @@
+// +build linux,unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"testing"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	dockercontainer "github.com/docker/docker/api/types/container"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplySecurityBaseline(t *testing.T) {
+	testCases := []struct {
+		name                string
+		cfg                 config.Config
+		containerType       apicontainer.ContainerType
+		labels              map[string]string
+		privileged          bool
+		networkMode         dockercontainer.NetworkMode
+		usernsMode          dockercontainer.UsernsMode
+		securityOpt         []string
+		maskedPaths         []string
+		expectedSecurityOpt []string
+		expectedMaskedPaths []string
+		expectedUsernsMode  dockercontainer.UsernsMode
+	}{
+		{
+			name:                "disabled",
+			cfg:                 config.Config{},
+			usernsMode:          "host",
+			securityOpt:         []string{"seccomp=unconfined"},
+			maskedPaths:         []string{},
+			expectedSecurityOpt: []string{"seccomp=unconfined"},
+			expectedMaskedPaths: []string{},
+			expectedUsernsMode:  "host",
+		},
+		{
+			name:                "enabled",
+			cfg:                 config.Config{SecurityBaselineEnabled: true},
+			securityOpt:         []string{"label:disable", "seccomp:unconfined", "no-new-privileges:false", "systempaths=unconfined"},
+			expectedSecurityOpt: []string{"label:disable", "no-new-privileges"},
+		},
+		{
+			name:                "internal container",
+			cfg:                 config.Config{SecurityBaselineEnabled: true},
+			containerType:       apicontainer.ContainerCNIPause,
+			securityOpt:         []string{"seccomp=unconfined"},
+			expectedSecurityOpt: []string{"seccomp=unconfined"},
+		},
+		{
+			name:                "opt out allowed",
+			cfg:                 config.Config{SecurityBaselineEnabled: true, SecurityBaselineAllowOptOut: true},
+			labels:              map[string]string{labelSecurityBaseline: securityBaselineOptOut},
+			securityOpt:         []string{"seccomp=unconfined"},
+			expectedSecurityOpt: []string{"seccomp=unconfined"},
+		},
+		{
+			name:                "opt out not allowed",
+			cfg:                 config.Config{SecurityBaselineEnabled: true},
+			labels:              map[string]string{labelSecurityBaseline: securityBaselineOptOut},
+			securityOpt:         []string{"seccomp=unconfined"},
+			expectedSecurityOpt: []string{"no-new-privileges"},
+		},
+		{
+			name:                "masked paths",
+			cfg:                 config.Config{SecurityBaselineEnabled: true},
+			maskedPaths:         []string{"/proc/kcore"},
+			expectedSecurityOpt: []string{"no-new-privileges"},
+		},
+		{
+			name:                "host user namespace",
+			cfg:                 config.Config{SecurityBaselineEnabled: true},
+			usernsMode:          "host",
+			expectedSecurityOpt: []string{"no-new-privileges"},
+		},
+		{
+			name:                "host user namespace privileged",
+			cfg:                 config.Config{SecurityBaselineEnabled: true},
+			privileged:          true,
+			usernsMode:          "host",
+			expectedSecurityOpt: []string{"no-new-privileges"},
+			expectedUsernsMode:  "host",
+		},
+		{
+			name:                "host user namespace host network",
+			cfg:                 config.Config{SecurityBaselineEnabled: true},
+			networkMode:         "host",
+			usernsMode:          "host",
+			expectedSecurityOpt: []string{"no-new-privileges"},
+			expectedUsernsMode:  "host",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			task := &apitask.Task{Arn: testTaskARN}
+			container := &apicontainer.Container{Name: "c1", Type: tc.containerType}
+			dockerConfig := &dockercontainer.Config{Labels: tc.labels}
+			hostConfig := &dockercontainer.HostConfig{
+				Privileged:  tc.privileged,
+				NetworkMode: tc.networkMode,
+				UsernsMode:  tc.usernsMode,
+				SecurityOpt: tc.securityOpt,
+				MaskedPaths: tc.maskedPaths,
+			}
+			applySecurityBaseline(&tc.cfg, task, container, dockerConfig, hostConfig)
+			assert.Equal(t, tc.expectedSecurityOpt, hostConfig.SecurityOpt)
+			assert.Equal(t, tc.expectedMaskedPaths, hostConfig.MaskedPaths)
+			assert.Equal(t, tc.expectedUsernsMode, hostConfig.UsernsMode)
+		})
+	}
+}
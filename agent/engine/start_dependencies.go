@@ -0,0 +1,84 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"time"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/credentials"
+	"github.com/cihub/seelog"
+)
+
+const (
+	// credentialsEndpointDialTimeout is the timeout of the connections made to
+	// check the credentials endpoint is reachable
+	credentialsEndpointDialTimeout = time.Second
+	// startDependencyPollInterval is the interval at which the credentials of
+	// a task are checked while its containers wait for them to start
+	startDependencyPollInterval = 100 * time.Millisecond
+)
+
+// credentialsEndpointAddress is the address the credentials of tasks are
+// served on
+var credentialsEndpointAddress = "127.0.0.1:" + strconv.Itoa(config.AgentCredentialsPort)
+
+// waitForStartDependencies waits until the metadata file of the container has
+// been written and the credentials endpoint serves the credentials of its task,
+// so that applications reading them at boot don't race their writes. The
+// container is started anyway once the configured delay has passed
+func (engine *DockerTaskEngine) waitForStartDependencies(task *apitask.Task, container *apicontainer.Container) {
+	ctx, cancel := context.WithTimeout(engine.ctx, engine.cfg.ContainerMetadataMaxStartDelay)
+	defer cancel()
+
+	if engine.cfg.ContainerMetadataEnabled {
+		if err := engine.metadataManager.WaitWritten(ctx, task.Arn, container.Name); err != nil {
+			seelog.Warnf("Task engine [%s]: starting container %s before its metadata file was written: %v",
+				task.Arn, container.Name, err)
+			return
+		}
+	}
+	if credentialsID := task.GetCredentialsID(); credentialsID != "" {
+		if err := waitForCredentialsEndpoint(ctx, engine.credentialsManager, credentialsID); err != nil {
+			seelog.Warnf("Task engine [%s]: starting container %s before the credentials endpoint serves its credentials: %v",
+				task.Arn, container.Name, err)
+		}
+	}
+}
+
+// waitForCredentialsEndpoint waits until the credentials are registered and
+// the credentials endpoint accepts connections
+func waitForCredentialsEndpoint(ctx context.Context, manager credentials.Manager, credentialsID string) error {
+	ticker := time.NewTicker(startDependencyPollInterval)
+	defer ticker.Stop()
+	for {
+		if _, ok := manager.GetTaskCredentials(credentialsID); ok {
+			conn, err := net.DialTimeout("tcp", credentialsEndpointAddress, credentialsEndpointDialTimeout)
+			if err == nil {
+				conn.Close()
+				return nil
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
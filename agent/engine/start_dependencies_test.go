@@ -0,0 +1,86 @@
+// +build unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/credentials"
+	mock_credentials "github.com/aws/amazon-ecs-agent/agent/credentials/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWaitForStartDependenciesMetadataFile(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	cfg := defaultConfig
+	cfg.ContainerMetadataEnabled = true
+	cfg.ContainerMetadataMaxStartDelay = time.Second
+	ctrl, _, _, taskEngine, _, _, metadataManager := mocks(t, ctx, &cfg)
+	defer ctrl.Finish()
+
+	task := &apitask.Task{Arn: "arn:aws:ecs:us-west-2:123456789012:task/task-id"}
+	container := &apicontainer.Container{Name: "app"}
+	metadataManager.EXPECT().WaitWritten(gomock.Any(), task.Arn, "app").Return(nil)
+
+	taskEngine.(*DockerTaskEngine).waitForStartDependencies(task, container)
+}
+
+func TestWaitForStartDependenciesCredentials(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	defer func(address string) {
+		credentialsEndpointAddress = address
+	}(credentialsEndpointAddress)
+	credentialsEndpointAddress = listener.Addr().String()
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	cfg := defaultConfig
+	cfg.ContainerMetadataMaxStartDelay = time.Second
+	ctrl, _, _, taskEngine, credentialsManager, _, _ := mocks(t, ctx, &cfg)
+	defer ctrl.Finish()
+
+	task := &apitask.Task{Arn: "arn:aws:ecs:us-west-2:123456789012:task/task-id"}
+	task.SetCredentialsID("credentials-id")
+	gomock.InOrder(
+		credentialsManager.EXPECT().GetTaskCredentials("credentials-id").Return(credentials.TaskIAMRoleCredentials{}, false),
+		credentialsManager.EXPECT().GetTaskCredentials("credentials-id").Return(credentials.TaskIAMRoleCredentials{}, true),
+	)
+
+	taskEngine.(*DockerTaskEngine).waitForStartDependencies(task, &apicontainer.Container{Name: "app"})
+}
+
+func TestWaitForCredentialsEndpointTimeout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	credentialsManager := mock_credentials.NewMockManager(ctrl)
+	credentialsManager.EXPECT().GetTaskCredentials("credentials-id").Return(
+		credentials.TaskIAMRoleCredentials{}, false).AnyTimes()
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Millisecond)
+	defer cancel()
+	assert.Error(t, waitForCredentialsEndpoint(ctx, credentialsManager, "credentials-id"))
+}
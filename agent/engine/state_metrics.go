@@ -0,0 +1,46 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"github.com/aws/amazon-ecs-agent/agent/engine/dockerstate"
+	"github.com/aws/amazon-ecs-agent/agent/metrics"
+)
+
+// NewStateCounter returns a function that counts the tasks, containers and
+// images of the state, for the metrics of the state of the agent
+func NewStateCounter(state dockerstate.TaskEngineState) func() metrics.StateCounts {
+	return func() metrics.StateCounts {
+		counts := metrics.StateCounts{
+			Tasks:      make(map[string]int),
+			Containers: make(map[string]int),
+		}
+		for _, task := range state.AllTasks() {
+			counts.Tasks[task.GetKnownStatus().String()]++
+			for _, container := range task.Containers {
+				counts.Containers[container.GetKnownStatus().String()]++
+			}
+		}
+		for _, imageState := range state.AllImageStates() {
+			counts.Images++
+			if imageState.Image != nil {
+				counts.ImagesSizeBytes += imageState.Image.Size
+			}
+			if !imageState.GetQuarantinedAt().IsZero() {
+				counts.QuarantinedImages++
+			}
+		}
+		return counts
+	}
+}
@@ -0,0 +1,61 @@
+// +build unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"testing"
+	"time"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apicontainerstatus "github.com/aws/amazon-ecs-agent/agent/api/container/status"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	apitaskstatus "github.com/aws/amazon-ecs-agent/agent/api/task/status"
+	"github.com/aws/amazon-ecs-agent/agent/engine/dockerstate"
+	"github.com/aws/amazon-ecs-agent/agent/engine/image"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewStateCounter(t *testing.T) {
+	state := dockerstate.NewTaskEngineState()
+	running := &apitask.Task{
+		Arn:               "running",
+		KnownStatusUnsafe: apitaskstatus.TaskRunning,
+		Containers: []*apicontainer.Container{
+			{Name: "app", KnownStatusUnsafe: apicontainerstatus.ContainerRunning},
+			{Name: "sidecar", KnownStatusUnsafe: apicontainerstatus.ContainerStopped},
+		},
+	}
+	stopped := &apitask.Task{
+		Arn:               "stopped",
+		KnownStatusUnsafe: apitaskstatus.TaskStopped,
+		Containers: []*apicontainer.Container{
+			{Name: "app", KnownStatusUnsafe: apicontainerstatus.ContainerStopped},
+		},
+	}
+	state.AddTask(running)
+	state.AddTask(stopped)
+	state.AddImageState(&image.ImageState{Image: &image.Image{ImageID: "sha256:a", Size: 100}})
+	quarantined := &image.ImageState{Image: &image.Image{ImageID: "sha256:b", Size: 50}}
+	quarantined.Quarantine(time.Now())
+	state.AddImageState(quarantined)
+
+	counts := NewStateCounter(state)()
+	assert.Equal(t, map[string]int{"RUNNING": 1, "STOPPED": 1}, counts.Tasks)
+	assert.Equal(t, map[string]int{"RUNNING": 1, "STOPPED": 2}, counts.Containers)
+	assert.Equal(t, 2, counts.Images)
+	assert.Equal(t, int64(150), counts.ImagesSizeBytes)
+	assert.Equal(t, 1, counts.QuarantinedImages)
+}
@@ -0,0 +1,92 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"fmt"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apierrors "github.com/aws/amazon-ecs-agent/agent/api/errors"
+	"github.com/aws/amazon-ecs-agent/agent/engine/dependencygraph"
+)
+
+// Codes of the reasons the agent reports when it stops a task. Reasons are
+// reported as "<code>: <message>", so that they can be told apart in the
+// stopped reason of DescribeTasks. Containers that fail report the name of
+// their error as the code, such as CannotPullContainerError or
+// OutOfMemoryError
+const (
+	// essentialContainerExitedReasonCode is the code of tasks stopped because
+	// an essential container exited on its own
+	essentialContainerExitedReasonCode = "EssentialContainerExited"
+	// containerDependencyReasonCode is the code of tasks stopped because the
+	// dependencies of their containers can't be resolved
+	containerDependencyReasonCode = "ContainerDependencyError"
+	// resourceInitializationReasonCode is the code of tasks stopped because
+	// one of their resources couldn't be created
+	resourceInitializationReasonCode = "ResourceInitializationError"
+	// instanceDrainingReasonCode is the code of tasks stopped because the
+	// instance is draining, ahead of its interruption or termination
+	instanceDrainingReasonCode = "InstanceDraining"
+	// unknownReasonCode is the code of errors without a name
+	unknownReasonCode = "UnknownError"
+)
+
+// stopReason returns a reason with its code
+func stopReason(code string, format string, args ...interface{}) string {
+	return code + ": " + fmt.Sprintf(format, args...)
+}
+
+// containerStopReason returns the reason a task stops because one of its
+// containers stopped: the error of the container if it failed, or its exit
+// code if it was essential and exited on its own
+func containerStopReason(container *apicontainer.Container) string {
+	description := "container " + container.Name
+	if container.Essential {
+		description = "essential " + description
+	}
+	if err := container.ApplyingError; err != nil {
+		code := err.ErrorName()
+		if code == "" {
+			code = unknownReasonCode
+		}
+		return stopReason(code, "%s: %s", description, err.Err)
+	}
+	if exitCode := container.GetKnownExitCode(); exitCode != nil {
+		return stopReason(essentialContainerExitedReasonCode, "%s exited with code %d", description, *exitCode)
+	}
+	return stopReason(essentialContainerExitedReasonCode, "%s stopped", description)
+}
+
+// taskRejectedReason returns the reason a task stops when it can't be added
+// to the engine, with the name of the error as its code
+func taskRejectedReason(err error) string {
+	if namedErr, ok := err.(apierrors.NamedError); ok && namedErr.ErrorName() != "" {
+		return stopReason(namedErr.ErrorName(), "%s", namedErr.Error())
+	}
+	return err.Error()
+}
+
+// dependencyErrorReason returns the first of the reasons containers can't
+// transition that is caused by their dependencies, if any
+func dependencyErrorReason(reasons []error) error {
+	for _, reason := range reasons {
+		if reason == dependencygraph.ContainerPastDesiredStatusErr ||
+			reason == dependencygraph.CredentialsNotResolvedErr {
+			continue
+		}
+		return reason
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+//go:build unit
+// +build unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apicontainerstatus "github.com/aws/amazon-ecs-agent/agent/api/container/status"
+	apierrors "github.com/aws/amazon-ecs-agent/agent/api/errors"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	apitaskstatus "github.com/aws/amazon-ecs-agent/agent/api/task/status"
+	"github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi"
+	"github.com/aws/amazon-ecs-agent/agent/engine/dependencygraph"
+	"github.com/aws/amazon-ecs-agent/agent/engine/testdata"
+	"github.com/aws/amazon-ecs-agent/agent/eventstream"
+	"github.com/aws/amazon-ecs-agent/agent/statechange"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContainerStopReason(t *testing.T) {
+	exitCode := 1
+	testCases := []struct {
+		name           string
+		container      *apicontainer.Container
+		expectedReason string
+	}{
+		{
+			name: "essential container out of memory",
+			container: &apicontainer.Container{
+				Name:          "app",
+				Essential:     true,
+				ApplyingError: apierrors.NewNamedError(dockerapi.OutOfMemoryError{}),
+			},
+			expectedReason: "OutOfMemoryError: essential container app: Container killed due to memory usage",
+		},
+		{
+			name: "essential container exited",
+			container: &apicontainer.Container{
+				Name:                "app",
+				Essential:           true,
+				KnownExitCodeUnsafe: &exitCode,
+			},
+			expectedReason: "EssentialContainerExited: essential container app exited with code 1",
+		},
+		{
+			name: "container failed without a named error",
+			container: &apicontainer.Container{
+				Name:          "sidecar",
+				ApplyingError: &apierrors.DefaultNamedError{Err: "failed"},
+			},
+			expectedReason: "UnknownError: container sidecar: failed",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedReason, containerStopReason(tc.container))
+		})
+	}
+}
+
+func TestTaskRejectedReason(t *testing.T) {
+	assert.Equal(t, "NetworkModeNotAllowedError: network mode host of container app is not allowed on this instance",
+		taskRejectedReason(&apierrors.NetworkModeNotAllowedError{NetworkMode: "host", ContainerName: "app"}))
+	assert.Equal(t, "failed", taskRejectedReason(errors.New("failed")))
+}
+
+func TestDependencyErrorReason(t *testing.T) {
+	dependencyErr := errors.New("dependency graph: dependency did not exit successfully")
+	assert.Equal(t, dependencyErr, dependencyErrorReason([]error{
+		dependencygraph.ContainerPastDesiredStatusErr,
+		dependencyErr,
+	}))
+	assert.Nil(t, dependencyErrorReason([]error{dependencygraph.ContainerPastDesiredStatusErr}))
+}
+
+func TestHandleContainerChangeSetsEssentialContainerStopReason(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	containerChangeEventStream := eventstream.NewEventStream("TestHandleContainerChangeSetsEssentialContainerStopReason", ctx)
+	containerChangeEventStream.StartListening()
+
+	mTask := &managedTask{
+		Task:                       testdata.LoadTask("sleep5TaskCgroup"),
+		engine:                     &DockerTaskEngine{},
+		containerChangeEventStream: containerChangeEventStream,
+		stateChangeEvents:          make(chan statechange.Event),
+	}
+	defer discardEvents(mTask.stateChangeEvents)()
+
+	mTask.SetKnownStatus(apitaskstatus.TaskRunning)
+	mTask.SetSentStatus(apitaskstatus.TaskRunning)
+	container := mTask.Containers[0]
+	container.SetKnownStatus(apicontainerstatus.ContainerRunning)
+
+	exitCode := 137
+	mTask.handleContainerChange(dockerContainerChange{
+		container: container,
+		event: dockerapi.DockerContainerChangeEvent{
+			Status: apicontainerstatus.ContainerStopped,
+			DockerContainerMetadata: dockerapi.DockerContainerMetadata{
+				DockerID: "dockerID",
+				ExitCode: &exitCode,
+				Error:    dockerapi.OutOfMemoryError{},
+			},
+		},
+	})
+
+	assert.Equal(t, "OutOfMemoryError: essential container sleep5: Container killed due to memory usage",
+		mTask.GetTerminalReason())
+}
+
+func TestHandleContainerChangeKeepsReasonOfStoppingTask(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	containerChangeEventStream := eventstream.NewEventStream("TestHandleContainerChangeKeepsReasonOfStoppingTask", ctx)
+	containerChangeEventStream.StartListening()
+
+	mTask := &managedTask{
+		Task:                       testdata.LoadTask("sleep5TaskCgroup"),
+		engine:                     &DockerTaskEngine{},
+		containerChangeEventStream: containerChangeEventStream,
+		stateChangeEvents:          make(chan statechange.Event),
+	}
+	defer discardEvents(mTask.stateChangeEvents)()
+
+	mTask.SetKnownStatus(apitaskstatus.TaskRunning)
+	mTask.SetSentStatus(apitaskstatus.TaskRunning)
+	mTask.SetDesiredStatus(apitaskstatus.TaskStopped)
+	container := mTask.Containers[0]
+	container.SetKnownStatus(apicontainerstatus.ContainerRunning)
+
+	exitCode := 143
+	mTask.handleContainerChange(dockerContainerChange{
+		container: container,
+		event: dockerapi.DockerContainerChangeEvent{
+			Status: apicontainerstatus.ContainerStopped,
+			DockerContainerMetadata: dockerapi.DockerContainerMetadata{
+				DockerID: "dockerID",
+				ExitCode: &exitCode,
+			},
+		},
+	})
+
+	assert.Empty(t, mTask.GetTerminalReason())
+}
+
+func TestOnContainersUnableToTransitionStateSetsDependencyReason(t *testing.T) {
+	mTask := &managedTask{
+		Task: &apitask.Task{
+			Containers: []*apicontainer.Container{
+				{
+					Name:                "app",
+					KnownStatusUnsafe:   apicontainerstatus.ContainerCreated,
+					DesiredStatusUnsafe: apicontainerstatus.ContainerRunning,
+				},
+			},
+			DesiredStatusUnsafe: apitaskstatus.TaskRunning,
+		},
+	}
+
+	mTask.handleContainersUnableToTransitionState([]error{
+		errors.New("dependency graph: failed to resolve container ordering dependency [init] for target [app]"),
+	})
+	assert.Equal(t, apitaskstatus.TaskStopped, mTask.GetDesiredStatus())
+	assert.Equal(t, "ContainerDependencyError: dependency graph: failed to resolve container ordering dependency [init] for target [app]",
+		mTask.GetTerminalReason())
+}
@@ -0,0 +1,41 @@
+// +build linux
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	"github.com/docker/docker/pkg/system"
+	"github.com/pkg/errors"
+)
+
+// readMemInfo reads the memory info of the host, swappable for testing
+var readMemInfo = system.ReadMemInfo
+
+// checkHostSwap returns an error if the container can use swap but the host
+// has none
+func checkHostSwap(container *apicontainer.Container) error {
+	if container.MaxSwap == nil || *container.MaxSwap == 0 {
+		return nil
+	}
+	memInfo, err := readMemInfo()
+	if err != nil {
+		return errors.Wrap(err, "unable to read the swap of the host")
+	}
+	if memInfo.SwapTotal == 0 {
+		return errors.Errorf("container %s requires swap but the host has none", container.Name)
+	}
+	return nil
+}
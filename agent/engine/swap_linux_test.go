@@ -0,0 +1,42 @@
+// +build linux,unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"testing"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/docker/docker/pkg/system"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckHostSwap(t *testing.T) {
+	defer func() {
+		readMemInfo = system.ReadMemInfo
+	}()
+	var swapTotal int64
+	readMemInfo = func() (*system.MemInfo, error) {
+		return &system.MemInfo{SwapTotal: swapTotal}, nil
+	}
+
+	assert.NoError(t, checkHostSwap(&apicontainer.Container{Name: "c1"}))
+	assert.NoError(t, checkHostSwap(&apicontainer.Container{Name: "c1", MaxSwap: aws.Int64(0)}))
+	assert.Error(t, checkHostSwap(&apicontainer.Container{Name: "c1", MaxSwap: aws.Int64(512)}))
+
+	swapTotal = 1024 * 1024 * 1024
+	assert.NoError(t, checkHostSwap(&apicontainer.Container{Name: "c1", MaxSwap: aws.Int64(512)}))
+}
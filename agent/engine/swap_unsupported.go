@@ -0,0 +1,30 @@
+// +build !linux
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	"github.com/pkg/errors"
+)
+
+// checkHostSwap rejects containers setting their swap, it's only supported on
+// linux
+func checkHostSwap(container *apicontainer.Container) error {
+	if container.UsesSwap() {
+		return errors.New("container swap is only supported on linux")
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/utils/retry"
+	"github.com/cihub/seelog"
+)
+
+const (
+	// cleanupStepAttempts is the number of times a task cleanup step is tried
+	// before it's given up on
+	cleanupStepAttempts              = 3
+	minCleanupStepRetryDelay         = 500 * time.Millisecond
+	maxCleanupStepRetryDelay         = 5 * time.Second
+	cleanupStepRetryJitterMultiplier = 0.2
+	cleanupStepRetryDelayMultiplier  = 2
+)
+
+// newCleanupStepBackoff creates the backoff used between the attempts of a
+// cleanup step
+var newCleanupStepBackoff = func() retry.Backoff {
+	return retry.NewExponentialBackoff(minCleanupStepRetryDelay, maxCleanupStepRetryDelay,
+		cleanupStepRetryJitterMultiplier, cleanupStepRetryDelayMultiplier)
+}
+
+// cleanupStep is a single unit of work of the task cleanup pipeline, such as
+// removing one container or cleaning up one task resource. Steps are isolated
+// from each other: a failing step is retried and then logged, but never
+// prevents the steps after it from running
+type cleanupStep struct {
+	name     string
+	attempts int
+	run      func() error
+}
+
+// runCleanupSteps runs the given steps, concurrently if parallel is set and in
+// order otherwise. It returns the names of the steps that still failed after
+// all of their attempts
+func (engine *DockerTaskEngine) runCleanupSteps(task *apitask.Task, steps []cleanupStep, parallel bool) []string {
+	var (
+		failed []string
+		lock   sync.Mutex
+		wg     sync.WaitGroup
+	)
+	run := func(step cleanupStep) {
+		defer wg.Done()
+		if err := engine.runCleanupStep(task, step); err != nil {
+			lock.Lock()
+			failed = append(failed, step.name)
+			lock.Unlock()
+		}
+	}
+	for _, step := range steps {
+		wg.Add(1)
+		if parallel {
+			go run(step)
+		} else {
+			run(step)
+		}
+	}
+	wg.Wait()
+	return failed
+}
+
+func (engine *DockerTaskEngine) runCleanupStep(task *apitask.Task, step cleanupStep) error {
+	attempts := step.attempts
+	if attempts < 1 {
+		attempts = 1
+	}
+	attempt := 0
+	err := retry.RetryNWithBackoff(newCleanupStepBackoff(), attempts, func() error {
+		attempt++
+		err := step.run()
+		if err != nil {
+			seelog.Warnf("Task engine [%s]: cleanup step %s failed (%d/%d): %v",
+				task.Arn, step.name, attempt, attempts, err)
+		}
+		return err
+	})
+	if err != nil {
+		seelog.Errorf("Task engine [%s]: giving up on cleanup step %s: %v", task.Arn, step.name, err)
+		return err
+	}
+	seelog.Debugf("Task engine [%s]: cleanup step %s complete", task.Arn, step.name)
+	return nil
+}
+
+// containerRemovalSteps returns a step removing each of the containers of the
+// task
+func (engine *DockerTaskEngine) containerRemovalSteps(task *apitask.Task) []cleanupStep {
+	var steps []cleanupStep
+	for _, cont := range task.Containers {
+		cont := cont
+		steps = append(steps, cleanupStep{
+			name:     fmt.Sprintf("remove container %s", cont.Name),
+			attempts: cleanupStepAttempts,
+			run: func() error {
+				return engine.removeContainer(task, cont)
+			},
+		})
+	}
+	return steps
+}
+
+// imageDereferenceSteps returns a step removing the reference each container
+// of the task holds on its image, so that the image can be cleaned up even if
+// removing the container failed
+func (engine *DockerTaskEngine) imageDereferenceSteps(task *apitask.Task) []cleanupStep {
+	var steps []cleanupStep
+	for _, cont := range task.Containers {
+		// Internal container(created by ecs-agent) state isn't recorded
+		if cont.IsInternal() {
+			continue
+		}
+		cont := cont
+		steps = append(steps, cleanupStep{
+			name:     fmt.Sprintf("dereference image of container %s", cont.Name),
+			attempts: 1,
+			run: func() error {
+				return engine.imageManager.RemoveContainerReferenceFromImageState(cont)
+			},
+		})
+	}
+	return steps
+}
+
+// metadataCleanupSteps returns the step removing the metadata directory of
+// the task, if container metadata is enabled
+func (engine *DockerTaskEngine) metadataCleanupSteps(task *apitask.Task) []cleanupStep {
+	if !engine.cfg.ContainerMetadataEnabled {
+		return nil
+	}
+	return []cleanupStep{{
+		name:     "clean task metadata",
+		attempts: cleanupStepAttempts,
+		run: func() error {
+			return engine.metadataManager.Clean(task.Arn)
+		},
+	}}
+}
+
+// resourceCleanupSteps returns a step cleaning up each of the resources of the
+// task, such as its volumes, networks and cgroup
+func (engine *DockerTaskEngine) resourceCleanupSteps(task *apitask.Task) []cleanupStep {
+	var steps []cleanupStep
+	for _, resource := range task.GetResources() {
+		resource := resource
+		steps = append(steps, cleanupStep{
+			name:     fmt.Sprintf("clean up resource %s", resource.GetName()),
+			attempts: cleanupStepAttempts,
+			run:      resource.Cleanup,
+		})
+	}
+	return steps
+}
@@ -0,0 +1,195 @@
+// +build unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	mock_containermetadata "github.com/aws/amazon-ecs-agent/agent/containermetadata/mocks"
+	"github.com/aws/amazon-ecs-agent/agent/dockerclient"
+	mock_dockerapi "github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi/mocks"
+	mock_dockerstate "github.com/aws/amazon-ecs-agent/agent/engine/dockerstate/mocks"
+	mock_engine "github.com/aws/amazon-ecs-agent/agent/engine/mocks"
+	"github.com/aws/amazon-ecs-agent/agent/statemanager"
+	"github.com/aws/amazon-ecs-agent/agent/taskresource"
+	mock_taskresource "github.com/aws/amazon-ecs-agent/agent/taskresource/mocks"
+	"github.com/aws/amazon-ecs-agent/agent/utils/retry"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+// useFastCleanupStepBackoff makes cleanup steps retry without waiting and
+// returns a function restoring the default backoff
+func useFastCleanupStepBackoff() func() {
+	newBackoff := newCleanupStepBackoff
+	newCleanupStepBackoff = func() retry.Backoff {
+		return retry.NewExponentialBackoff(time.Nanosecond, time.Nanosecond, 0, 1)
+	}
+	return func() {
+		newCleanupStepBackoff = newBackoff
+	}
+}
+
+func newCleanupTestEngine(t *testing.T) (*DockerTaskEngine, *gomock.Controller, *mock_dockerstate.MockTaskEngineState,
+	*mock_dockerapi.MockDockerClient, *mock_engine.MockImageManager, *mock_containermetadata.MockManager) {
+	ctrl := gomock.NewController(t)
+	mockState := mock_dockerstate.NewMockTaskEngineState(ctrl)
+	mockClient := mock_dockerapi.NewMockDockerClient(ctrl)
+	mockImageManager := mock_engine.NewMockImageManager(ctrl)
+	mockMetadataManager := mock_containermetadata.NewMockManager(ctrl)
+
+	cfg := getTestConfig()
+	cfg.ContainerMetadataEnabled = true
+	return &DockerTaskEngine{
+		ctx:             context.TODO(),
+		cfg:             &cfg,
+		saver:           statemanager.NewNoopStateManager(),
+		state:           mockState,
+		client:          mockClient,
+		imageManager:    mockImageManager,
+		metadataManager: mockMetadataManager,
+		managedTasks:    make(map[string]*managedTask),
+	}, ctrl, mockState, mockClient, mockImageManager, mockMetadataManager
+}
+
+func TestSweepTaskIsolatesFailures(t *testing.T) {
+	defer useFastCleanupStepBackoff()()
+	taskEngine, ctrl, mockState, mockClient, mockImageManager, mockMetadataManager := newCleanupTestEngine(t)
+	defer ctrl.Finish()
+
+	c1 := &apicontainer.Container{Name: "c1"}
+	c2 := &apicontainer.Container{Name: "c2"}
+	task := &apitask.Task{
+		Arn:        "arn",
+		Containers: []*apicontainer.Container{c1, c2},
+	}
+	containerMap := map[string]*apicontainer.DockerContainer{
+		"c1": {DockerName: "docker-c1"},
+		"c2": {DockerName: "docker-c2"},
+	}
+
+	mockState.EXPECT().ContainerMapByArn(task.Arn).Return(containerMap, true).Times(cleanupStepAttempts + 1)
+	mockClient.EXPECT().RemoveContainer(gomock.Any(), "docker-c1", dockerclient.RemoveContainerTimeout).Return(
+		errors.New("device or resource busy")).Times(cleanupStepAttempts)
+	mockClient.EXPECT().RemoveContainer(gomock.Any(), "docker-c2", dockerclient.RemoveContainerTimeout).Return(nil)
+	mockImageManager.EXPECT().RemoveContainerReferenceFromImageState(c1).Return(errors.New("no image state"))
+	mockImageManager.EXPECT().RemoveContainerReferenceFromImageState(c2).Return(nil)
+	mockMetadataManager.EXPECT().Clean(task.Arn).Return(nil)
+
+	taskEngine.sweepTask(task)
+}
+
+func TestSweepTaskDoesNotRetryContainersNeverCreated(t *testing.T) {
+	defer useFastCleanupStepBackoff()()
+	taskEngine, ctrl, mockState, _, mockImageManager, mockMetadataManager := newCleanupTestEngine(t)
+	defer ctrl.Finish()
+
+	c1 := &apicontainer.Container{Name: "c1"}
+	task := &apitask.Task{
+		Arn:        "arn",
+		Containers: []*apicontainer.Container{c1},
+	}
+
+	mockState.EXPECT().ContainerMapByArn(task.Arn).Return(map[string]*apicontainer.DockerContainer{}, true)
+	mockImageManager.EXPECT().RemoveContainerReferenceFromImageState(c1).Return(nil)
+	mockMetadataManager.EXPECT().Clean(task.Arn).Return(nil)
+
+	taskEngine.sweepTask(task)
+}
+
+func TestSweepTaskRetriesMetadataCleanup(t *testing.T) {
+	defer useFastCleanupStepBackoff()()
+	taskEngine, ctrl, mockState, mockClient, mockImageManager, mockMetadataManager := newCleanupTestEngine(t)
+	defer ctrl.Finish()
+
+	c1 := &apicontainer.Container{Name: "c1"}
+	task := &apitask.Task{
+		Arn:        "arn",
+		Containers: []*apicontainer.Container{c1},
+	}
+
+	mockState.EXPECT().ContainerMapByArn(task.Arn).Return(map[string]*apicontainer.DockerContainer{
+		"c1": {DockerName: "docker-c1"},
+	}, true)
+	mockClient.EXPECT().RemoveContainer(gomock.Any(), "docker-c1", dockerclient.RemoveContainerTimeout).Return(nil)
+	mockImageManager.EXPECT().RemoveContainerReferenceFromImageState(c1).Return(nil)
+	gomock.InOrder(
+		mockMetadataManager.EXPECT().Clean(task.Arn).Return(errors.New("busy")),
+		mockMetadataManager.EXPECT().Clean(task.Arn).Return(nil),
+	)
+
+	taskEngine.sweepTask(task)
+}
+
+func TestDeleteTaskIsolatesResourceFailures(t *testing.T) {
+	defer useFastCleanupStepBackoff()()
+	taskEngine, ctrl, mockState, _, _, _ := newCleanupTestEngine(t)
+	defer ctrl.Finish()
+
+	volumeResource := mock_taskresource.NewMockTaskResource(ctrl)
+	networkResource := mock_taskresource.NewMockTaskResource(ctrl)
+	task := &apitask.Task{
+		Arn:                "arn",
+		ResourcesMapUnsafe: make(map[string][]taskresource.TaskResource),
+	}
+	task.AddResource("volume", volumeResource)
+	task.AddResource("network", networkResource)
+
+	volumeResource.EXPECT().GetName().Return("volume").AnyTimes()
+	networkResource.EXPECT().GetName().Return("network").AnyTimes()
+	volumeCleanup := volumeResource.EXPECT().Cleanup().Return(errors.New("volume in use")).Times(cleanupStepAttempts)
+	networkCleanup := networkResource.EXPECT().Cleanup().Return(nil)
+	mockState.EXPECT().RemoveTask(task).After(volumeCleanup).After(networkCleanup)
+
+	taskEngine.deleteTask(task)
+}
+
+func TestRunCleanupStepsParallel(t *testing.T) {
+	defer useFastCleanupStepBackoff()()
+	cfg := config.DefaultConfig()
+	taskEngine := &DockerTaskEngine{ctx: context.TODO(), cfg: &cfg}
+
+	release := make(chan struct{})
+	started := make(chan struct{}, 2)
+	step := func(name string, err error) cleanupStep {
+		return cleanupStep{
+			name:     name,
+			attempts: 1,
+			run: func() error {
+				started <- struct{}{}
+				<-release
+				return err
+			},
+		}
+	}
+
+	done := make(chan []string)
+	go func() {
+		done <- taskEngine.runCleanupSteps(&apitask.Task{Arn: "arn"},
+			[]cleanupStep{step("s1", errors.New("failed")), step("s2", nil)}, true)
+	}()
+	// Both steps have to be running at the same time for this to make progress
+	<-started
+	<-started
+	close(release)
+	assert.Equal(t, []string{"s1"}, <-done)
+}
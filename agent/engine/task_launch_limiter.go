@@ -0,0 +1,69 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"context"
+
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"golang.org/x/time/rate"
+)
+
+// taskLaunchLimiter limits how many tasks the engine launches, that is pulls
+// the images of and creates the containers of, at the same time and how fast
+// it starts launching them, so that a large payload doesn't stampede docker
+// and the registries
+type taskLaunchLimiter struct {
+	slots   chan struct{}
+	limiter *rate.Limiter
+}
+
+// newTaskLaunchLimiter returns the task launch limiter of the agent config, or
+// nil if the config doesn't limit task launches
+func newTaskLaunchLimiter(cfg *config.Config) *taskLaunchLimiter {
+	if cfg.TaskLaunchMaxConcurrency <= 0 && cfg.TaskLaunchRate <= 0 {
+		return nil
+	}
+	launchLimiter := &taskLaunchLimiter{}
+	if cfg.TaskLaunchMaxConcurrency > 0 {
+		launchLimiter.slots = make(chan struct{}, cfg.TaskLaunchMaxConcurrency)
+	}
+	if cfg.TaskLaunchRate > 0 {
+		burst := cfg.TaskLaunchBurst
+		if burst <= 0 {
+			burst = 1
+		}
+		launchLimiter.limiter = rate.NewLimiter(rate.Limit(cfg.TaskLaunchRate), burst)
+	}
+	return launchLimiter
+}
+
+// acquire waits for the turn of a task to be launched. It returns the function
+// to call once the task is launched, to let the next one in
+func (launchLimiter *taskLaunchLimiter) acquire(ctx context.Context) (func(), error) {
+	if launchLimiter.limiter != nil {
+		if err := launchLimiter.limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if launchLimiter.slots == nil {
+		return func() {}, nil
+	}
+	select {
+	case launchLimiter.slots <- struct{}{}:
+		return func() { <-launchLimiter.slots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
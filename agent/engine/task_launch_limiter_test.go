@@ -0,0 +1,101 @@
+// +build unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTaskLaunchLimiterUnlimited(t *testing.T) {
+	assert.Nil(t, newTaskLaunchLimiter(&config.Config{}))
+}
+
+func TestTaskLaunchLimiterConcurrency(t *testing.T) {
+	launchLimiter := newTaskLaunchLimiter(&config.Config{TaskLaunchMaxConcurrency: 1})
+	require.NotNil(t, launchLimiter)
+
+	release, err := launchLimiter.acquire(context.TODO())
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Millisecond)
+	defer cancel()
+	_, err = launchLimiter.acquire(ctx)
+	assert.Error(t, err, "expected the second launch to wait for the first one")
+
+	release()
+	release, err = launchLimiter.acquire(context.TODO())
+	assert.NoError(t, err)
+	release()
+}
+
+func TestTaskLaunchLimiterRate(t *testing.T) {
+	launchLimiter := newTaskLaunchLimiter(&config.Config{TaskLaunchRate: 1, TaskLaunchBurst: 2})
+	require.NotNil(t, launchLimiter)
+
+	for i := 0; i < 2; i++ {
+		release, err := launchLimiter.acquire(context.TODO())
+		require.NoError(t, err)
+		release()
+	}
+	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Millisecond)
+	defer cancel()
+	_, err := launchLimiter.acquire(ctx)
+	assert.Error(t, err, "expected launches past the burst to be throttled")
+}
+
+func TestWaitForLaunchAdmission(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	launchLimiter := newTaskLaunchLimiter(&config.Config{TaskLaunchMaxConcurrency: 1})
+	mtask := &managedTask{
+		Task:        &apitask.Task{Arn: "arn:aws:ecs:us-west-2:123456789012:task/task-id"},
+		ctx:         ctx,
+		engine:      &DockerTaskEngine{launchLimiter: launchLimiter},
+		acsMessages: make(chan acsTransition),
+	}
+
+	releaseOther, err := launchLimiter.acquire(context.TODO())
+	require.NoError(t, err)
+
+	admitted := make(chan struct{})
+	go func() {
+		mtask.waitForLaunchAdmission()
+		close(admitted)
+	}()
+
+	select {
+	case <-admitted:
+		t.Fatal("expected the task to wait for the other launch to finish")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	releaseOther()
+	<-admitted
+	assert.NotNil(t, mtask.releaseLaunch)
+
+	mtask.finishLaunch()
+	assert.Nil(t, mtask.releaseLaunch)
+	release, err := launchLimiter.acquire(context.TODO())
+	assert.NoError(t, err, "expected the task to release its launch slot")
+	release()
+}
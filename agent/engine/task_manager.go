@@ -32,10 +32,12 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/ecscni"
 	"github.com/aws/amazon-ecs-agent/agent/engine/dependencygraph"
 	"github.com/aws/amazon-ecs-agent/agent/eventstream"
+	"github.com/aws/amazon-ecs-agent/agent/metrics"
 	"github.com/aws/amazon-ecs-agent/agent/statechange"
 	"github.com/aws/amazon-ecs-agent/agent/statemanager"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource"
 	resourcestatus "github.com/aws/amazon-ecs-agent/agent/taskresource/status"
+	"github.com/aws/amazon-ecs-agent/agent/utils/retry"
 	utilsync "github.com/aws/amazon-ecs-agent/agent/utils/sync"
 	"github.com/aws/amazon-ecs-agent/agent/utils/ttime"
 
@@ -159,6 +161,10 @@ type managedTask struct {
 	// This can be used by tests that are looking to ensure that the steady state
 	// verification logic gets executed to set it to a low interval
 	steadyStatePollInterval time.Duration
+
+	// releaseLaunch lets the next task be launched once this task is
+	// launched. It's only set while the task holds a launch slot
+	releaseLaunch func()
 }
 
 // newManagedTask is a method on DockerTaskEngine to create a new managedTask.
@@ -201,6 +207,9 @@ func (mtask *managedTask) overseeTask() {
 	// Wait for host resources required by this task to become available
 	mtask.waitForHostResources()
 
+	// Wait for the turn of the task to be launched
+	mtask.waitForLaunchAdmission()
+
 	// Main infinite loop. This is where we receive messages and dispatch work.
 	for {
 		select {
@@ -224,6 +233,10 @@ func (mtask *managedTask) overseeTask() {
 			mtask.progressTask()
 		}
 
+		if mtask.GetKnownStatus() >= apitaskstatus.TaskCreated {
+			mtask.finishLaunch()
+		}
+
 		// If we reach this point, we've changed the task in some way.
 		// Conversely, for it to spin in steady state it will have to have been
 		// loaded in steady state or progressed through here, so saving here should
@@ -241,6 +254,7 @@ func (mtask *managedTask) overseeTask() {
 	// We only break out of the above if this task is known to be stopped. Do
 	// onetime cleanup here, including removing the task after a timeout
 	seelog.Infof("Managed task [%s]: task has reached stopped. Waiting for container cleanup", mtask.Arn)
+	mtask.finishLaunch()
 	mtask.cleanupCredentials()
 	if mtask.StopSequenceNumber != 0 {
 		seelog.Debugf("Managed task [%s]: marking done for this sequence: %d",
@@ -249,7 +263,18 @@ func (mtask *managedTask) overseeTask() {
 	}
 	// TODO: make this idempotent on agent restart
 	go mtask.releaseIPInIPAM()
-	mtask.cleanupTask(mtask.cfg.TaskCleanupWaitDuration)
+	mtask.engine.resetGPUComputeMode(mtask.Task)
+	mtask.engine.releaseTaskGPUs(mtask.Task)
+	mtask.engine.releaseTaskHostPorts(mtask.Task)
+	mtask.cleanupTask(mtask.cleanupWaitDuration())
+}
+
+// cleanupWaitDuration returns the time to wait after the task stops before
+// cleaning it up, with jitter so that tasks stopped together aren't cleaned up
+// all at once
+func (mtask *managedTask) cleanupWaitDuration() time.Duration {
+	return retry.AddJitter(mtask.GetCleanupWaitDuration(mtask.cfg.TaskCleanupWaitDuration),
+		mtask.cfg.TaskCleanupWaitJitter)
 }
 
 // emitCurrentStatus emits a container event for every container and a task
@@ -297,6 +322,64 @@ func (mtask *managedTask) waitForHostResources() {
 		mtask.Arn, mtask.GetDesiredStatus().String())
 }
 
+// waitForLaunchAdmission waits for the task launch limiter of the engine to
+// let the task be launched, for tasks that haven't started launching yet
+func (mtask *managedTask) waitForLaunchAdmission() {
+	launchLimiter := mtask.engine.launchLimiter
+	if launchLimiter == nil {
+		return
+	}
+	if mtask.GetKnownStatus() != apitaskstatus.TaskStatusNone || mtask.GetDesiredStatus().Terminal() {
+		// The task was already launched before a restart, or it's meant to be
+		// stopped. No need to wait
+		return
+	}
+
+	seelog.Infof("Managed task [%s]: waiting for its turn to be launched", mtask.Arn)
+
+	acquireCtx, cancelAcquire := context.WithCancel(mtask.ctx)
+	admittedCtx, admitted := context.WithCancel(mtask.ctx)
+	defer admitted()
+
+	releases := make(chan func(), 1)
+	go func() {
+		release, err := launchLimiter.acquire(acquireCtx)
+		if err != nil {
+			release = nil
+		}
+		releases <- release
+		admitted()
+	}()
+
+	for !mtask.waitEvent(admittedCtx.Done()) {
+		if mtask.GetDesiredStatus().Terminal() {
+			// The task was stopped before it could be launched
+			break
+		}
+	}
+	cancelAcquire()
+	release := <-releases
+	if release == nil {
+		return
+	}
+	if mtask.GetDesiredStatus().Terminal() {
+		release()
+		return
+	}
+	mtask.releaseLaunch = release
+	seelog.Infof("Managed task [%s]: wait over; launching the task", mtask.Arn)
+}
+
+// finishLaunch lets the next task be launched, if this task holds a launch
+// slot
+func (mtask *managedTask) finishLaunch() {
+	if mtask.releaseLaunch == nil {
+		return
+	}
+	mtask.releaseLaunch()
+	mtask.releaseLaunch = nil
+}
+
 // waitSteady waits for a task to leave steady-state by waiting for a new
 // event, or a timeout.
 func (mtask *managedTask) waitSteady() {
@@ -385,6 +468,20 @@ func (mtask *managedTask) handleDesiredStatusChange(desiredStatus apitaskstatus.
 	mtask.UpdateDesiredStatus()
 }
 
+// stopManagedAgents marks the managed agents of the container that aren't
+// already stopped as stopped for the given reason
+func stopManagedAgents(container *apicontainer.Container, reason string) {
+	for _, ma := range container.GetManagedAgents() {
+		if ma.Status.Terminal() {
+			continue
+		}
+		state := ma.ManagedAgentState
+		state.Status = apicontainerstatus.ManagedAgentStopped
+		state.Reason = reason
+		container.UpdateManagedAgentByName(ma.Name, state)
+	}
+}
+
 // handleContainerChange updates a container's known status. If the message
 // contains any interesting information (like exit codes or ports), they are
 // propagated.
@@ -401,6 +498,7 @@ func (mtask *managedTask) handleContainerChange(containerChange dockerContainerC
 	event := containerChange.event
 	seelog.Infof("Managed task [%s]: handling container change [%v] for container [%s]",
 		mtask.Arn, event, container.Name)
+	taskStopping := mtask.GetDesiredStatus().Terminal()
 
 	// If this is a backwards transition stopped->running, the first time set it
 	// to be known running so it will be stopped. Subsequently ignore these backward transitions
@@ -417,19 +515,37 @@ func (mtask *managedTask) handleContainerChange(containerChange dockerContainerC
 		return
 	}
 
+	// Containers with a restart policy that exit on their own are restarted
+	// in place instead of being marked as stopped
+	if mtask.handleContainerRestart(container, event) {
+		return
+	}
+
 	// Update the container to be known
 	currentKnownStatus := containerKnownStatus
 	container.SetKnownStatus(event.Status)
 	updateContainerMetadata(&event.DockerContainerMetadata, container, mtask.Task)
 
 	if event.Error != nil {
+		if event.Error.ErrorName() == dockerapi.OutOfMemoryErrorName {
+			metrics.MetricsEngineGlobal.RecordContainerOOMKill(mtask.Family, container.Name)
+		}
 		proceedAnyway := mtask.handleEventError(containerChange, currentKnownStatus)
+		if !taskStopping {
+			mtask.setEssentialContainerStopReason(container)
+		}
 		if !proceedAnyway {
 			return
 		}
+	} else if !taskStopping {
+		mtask.setEssentialContainerStopReason(container)
 	}
 
 	mtask.RecordExecutionStoppedAt(container)
+	if container.GetKnownStatus().Terminal() {
+		stopManagedAgents(container, "container stopped")
+		mtask.engine.releaseContainerGPUs(mtask.Task, container)
+	}
 	seelog.Debugf("Managed task [%s]: sending container change event to tcs, container: [%s(%s)], status: %s",
 		mtask.Arn, container.Name, event.DockerID, event.Status.String())
 	err := mtask.containerChangeEventStream.WriteToEventStream(event)
@@ -451,6 +567,17 @@ func (mtask *managedTask) handleContainerChange(containerChange dockerContainerC
 	}
 }
 
+// setEssentialContainerStopReason sets the reason the task stops when an
+// essential container stops, or is going to be stopped because it failed
+func (mtask *managedTask) setEssentialContainerStopReason(container *apicontainer.Container) {
+	if !container.Essential {
+		return
+	}
+	if container.KnownTerminal() || container.DesiredTerminal() {
+		mtask.Task.SetTerminalReason(containerStopReason(container))
+	}
+}
+
 // handleResourceStateChange attempts to update resource's known status depending on
 // the current status and errors during transition
 func (mtask *managedTask) handleResourceStateChange(resChange resourceStateChange) {
@@ -483,7 +610,8 @@ func (mtask *managedTask) handleResourceStateChange(resChange resourceStateChang
 		seelog.Errorf("Managed task [%s]: error while creating resource %s, setting the task's desired status to STOPPED",
 			mtask.Arn, res.GetName())
 		mtask.SetDesiredStatus(apitaskstatus.TaskStopped)
-		mtask.Task.SetTerminalReason(res.GetTerminalReason())
+		mtask.Task.SetTerminalReason(stopReason(resourceInitializationReasonCode, "failed to create resource %s: %s",
+			res.GetName(), res.GetTerminalReason()))
 		mtask.engine.saver.Save()
 	}
 }
@@ -503,6 +631,11 @@ func (mtask *managedTask) emitTaskEvent(task *apitask.Task, reason string) {
 			task.Arn, reason, err)
 		return
 	}
+	if task.IsStatic() {
+		// ECS doesn't know about static tasks, their changes are only recorded
+		task.SetSentStatus(event.Status)
+		return
+	}
 	seelog.Infof("Managed task [%s]: sending task change event [%s]", mtask.Arn, event.String())
 	mtask.stateChangeEvents <- event
 	seelog.Infof("Managed task [%s]: sent task change event [%s]", mtask.Arn, event.String())
@@ -517,6 +650,10 @@ func (mtask *managedTask) emitContainerEvent(task *apitask.Task, cont *apicontai
 			task.Arn, cont.Name, err)
 		return
 	}
+	if task.IsStatic() {
+		cont.SetSentStatus(event.Status)
+		return
+	}
 
 	seelog.Infof("Managed task [%s]: sending container change event [%s]: %s",
 		mtask.Arn, cont.Name, event.String())
@@ -629,14 +766,17 @@ func (mtask *managedTask) handleEventError(containerChange dockerContainerChange
 		// If the agent pull behavior is always or once, we receive the error because
 		// the image pull fails, the task should fail. If we don't fail task here,
 		// then the cached image will probably be used for creating container, and we
-		// don't want to use cached image for both cases.
+		// don't want to use cached image for both cases. The same goes when pulling
+		// images by digest, as the cached image may not be the pinned one.
 		if mtask.cfg.ImagePullBehavior == config.ImagePullAlwaysBehavior ||
-			mtask.cfg.ImagePullBehavior == config.ImagePullOnceBehavior {
+			mtask.cfg.ImagePullBehavior == config.ImagePullOnceBehavior ||
+			mtask.cfg.ImagePullDigestMode != config.ImagePullDigestDisabled {
 			seelog.Errorf("Managed task [%s]: error while pulling image %s for container %s , moving task to STOPPED: %v",
 				mtask.Arn, container.Image, container.Name, event.Error)
 			// The task should be stopped regardless of whether this container is
 			// essential or non-essential.
 			mtask.SetDesiredStatus(apitaskstatus.TaskStopped)
+			mtask.Task.SetTerminalReason(containerStopReason(container))
 			return false
 		}
 		// If the agent pull behavior is prefer-cached, we receive the error because
@@ -776,7 +916,7 @@ func (mtask *managedTask) progressTask() {
 	// execution credentials. If not, then we will abort the task progression.
 	if !atLeastOneTransitionStarted && !blockedByOrderingDependencies {
 		if !mtask.isWaitingForACSExecutionCredentials(reasons) {
-			mtask.handleContainersUnableToTransitionState()
+			mtask.handleContainersUnableToTransitionState(reasons)
 		}
 		return
 	}
@@ -1062,7 +1202,7 @@ func (mtask *managedTask) resourceNextState(resource taskresource.TaskResource)
 	}
 }
 
-func (mtask *managedTask) handleContainersUnableToTransitionState() {
+func (mtask *managedTask) handleContainersUnableToTransitionState(reasons []error) {
 	seelog.Criticalf("Managed task [%s]: task in a bad state; it's not steadystate but no containers want to transition",
 		mtask.Arn)
 	if mtask.GetDesiredStatus().Terminal() {
@@ -1074,6 +1214,9 @@ func (mtask *managedTask) handleContainersUnableToTransitionState() {
 		// TODO we should probably panic here
 	} else {
 		seelog.Criticalf("Managed task [%s]: moving task to stopped due to bad state", mtask.Arn)
+		if reason := dependencyErrorReason(reasons); reason != nil {
+			mtask.Task.SetTerminalReason(stopReason(containerDependencyReasonCode, "%v", reason))
+		}
 		mtask.handleDesiredStatusChange(apitaskstatus.TaskStopped, 0)
 	}
 }
@@ -61,6 +61,7 @@ func TestHandleEventError(t *testing.T) {
 		EventStatus                           apicontainerstatus.ContainerStatus
 		CurrentContainerKnownStatus           apicontainerstatus.ContainerStatus
 		ImagePullBehavior                     config.ImagePullBehaviorType
+		ImagePullDigestMode                   config.ImagePullDigestModeType
 		Error                                 apierrors.NamedError
 		ExpectedContainerKnownStatusSet       bool
 		ExpectedContainerKnownStatus          apicontainerstatus.ContainerStatus
@@ -184,6 +185,17 @@ func TestHandleEventError(t *testing.T) {
 			ExpectedTaskDesiredStatusStopped: true,
 			ExpectedOK:                       false,
 		},
+		{
+			Name:        "Pull image by digest fails and task fails",
+			EventStatus: apicontainerstatus.ContainerPulled,
+			Error: &dockerapi.CannotResolveImageDigestError{
+				FromError: errors.New("error"),
+			},
+			ImagePullDigestMode:              config.ImagePullDigestResolve,
+			ExpectedContainerKnownStatusSet:  false,
+			ExpectedTaskDesiredStatusStopped: true,
+			ExpectedOK:                       false,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -205,7 +217,10 @@ func TestHandleEventError(t *testing.T) {
 					Arn: "task1",
 				},
 				engine: &DockerTaskEngine{},
-				cfg:    &config.Config{ImagePullBehavior: tc.ImagePullBehavior},
+				cfg: &config.Config{
+					ImagePullBehavior:   tc.ImagePullBehavior,
+					ImagePullDigestMode: tc.ImagePullDigestMode,
+				},
 			}
 			ok := mtask.handleEventError(containerChange, tc.CurrentContainerKnownStatus)
 			assert.Equal(t, tc.ExpectedOK, ok, "to proceed")
@@ -888,7 +903,7 @@ func TestOnContainersUnableToTransitionStateForDesiredStoppedTask(t *testing.T)
 		eventsGenerated.Done()
 	}()
 
-	task.handleContainersUnableToTransitionState()
+	task.handleContainersUnableToTransitionState(nil)
 	eventsGenerated.Wait()
 
 	assert.Equal(t, task.GetDesiredStatus(), apitaskstatus.TaskStopped)
@@ -910,7 +925,7 @@ func TestOnContainersUnableToTransitionStateForDesiredRunningTask(t *testing.T)
 		},
 	}
 
-	task.handleContainersUnableToTransitionState()
+	task.handleContainersUnableToTransitionState(nil)
 	assert.Equal(t, task.GetDesiredStatus(), apitaskstatus.TaskStopped)
 	assert.Equal(t, task.Containers[0].GetDesiredStatus(), apicontainerstatus.ContainerStopped)
 }
@@ -1452,6 +1467,7 @@ func TestCleanupTaskWithResourceHappyPath(t *testing.T) {
 }
 
 func TestCleanupTaskWithResourceErrorPath(t *testing.T) {
+	defer useFastCleanupStepBackoff()()
 	ctrl := gomock.NewController(t)
 	mockTime := mock_ttime.NewMockTime(ctrl)
 	mockState := mock_dockerstate.NewMockTaskEngineState(ctrl)
@@ -1509,7 +1525,7 @@ func TestCleanupTaskWithResourceErrorPath(t *testing.T) {
 	mockImageManager.EXPECT().RemoveContainerReferenceFromImageState(container).Return(nil)
 	mockState.EXPECT().RemoveTask(mTask.Task)
 	mockResource.EXPECT().GetName()
-	mockResource.EXPECT().Cleanup().Return(errors.New("cleanup error"))
+	mockResource.EXPECT().Cleanup().Return(errors.New("cleanup error")).Times(cleanupStepAttempts)
 	mTask.cleanupTask(taskStoppedDuration)
 }
 
@@ -2020,3 +2036,47 @@ func TestContainerNextStateDependsStoppedContainer(t *testing.T) {
 		})
 	}
 }
+
+func TestEmitStaticTaskEvents(t *testing.T) {
+	container := &apicontainer.Container{
+		Name:              "daemon",
+		KnownStatusUnsafe: apicontainerstatus.ContainerRunning,
+	}
+	task := &apitask.Task{
+		Arn:               "arn:aws:ecs:us-west-2:123456789012:task/static-daemon-1",
+		StaticName:        "daemon",
+		KnownStatusUnsafe: apitaskstatus.TaskRunning,
+		Containers:        []*apicontainer.Container{container},
+	}
+	// The channel isn't read from, emitting the events would block if they
+	// weren't skipped
+	mtask := &managedTask{
+		Task:              task,
+		stateChangeEvents: make(chan statechange.Event),
+	}
+
+	mtask.emitContainerEvent(task, container, "")
+	mtask.emitTaskEvent(task, "")
+	assert.Equal(t, apicontainerstatus.ContainerRunning, container.GetSentStatus())
+	assert.Equal(t, apitaskstatus.TaskRunning, task.GetSentStatus())
+}
+
+func TestCleanupWaitDuration(t *testing.T) {
+	cfg := &config.Config{
+		TaskCleanupWaitDuration: 3 * time.Hour,
+		TaskCleanupWaitJitter:   10 * time.Minute,
+	}
+	mtask := &managedTask{
+		Task: &apitask.Task{CleanupWaitDurationSeconds: 60},
+		cfg:  cfg,
+	}
+	for i := 0; i < 10; i++ {
+		duration := mtask.cleanupWaitDuration()
+		assert.True(t, duration >= time.Minute && duration < 11*time.Minute,
+			"unexpected cleanup wait duration: %s", duration)
+	}
+
+	mtask.Task = &apitask.Task{}
+	cfg.TaskCleanupWaitJitter = 0
+	assert.Equal(t, 3*time.Hour, mtask.cleanupWaitDuration())
+}
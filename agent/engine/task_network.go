@@ -0,0 +1,73 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/dockerclient"
+	taskresourcenetwork "github.com/aws/amazon-ecs-agent/agent/taskresource/network"
+	"github.com/cihub/seelog"
+	dockercontainer "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/network"
+)
+
+// removeLeakedTaskNetworks removes the docker networks the agent created for
+// tasks it no longer knows about, such as networks whose removal failed
+// before the agent restarted
+func (engine *DockerTaskEngine) removeLeakedTaskNetworks(tasks []*apitask.Task) {
+	networks, err := engine.client.ListNetworks(engine.ctx,
+		filters.NewArgs(filters.Arg("label", taskresourcenetwork.LabelTaskARN)), dockerclient.ListNetworksTimeout)
+	if err != nil {
+		seelog.Warnf("Task engine: unable to list task networks to remove leaked ones: %v", err)
+		return
+	}
+
+	knownNetworks := make(map[string]struct{})
+	for _, task := range tasks {
+		if networkName, ok := task.GetTaskBridgeNetworkName(); ok {
+			knownNetworks[networkName] = struct{}{}
+		}
+	}
+	for _, network := range networks {
+		if _, ok := knownNetworks[network.Name]; ok {
+			continue
+		}
+		seelog.Warnf("Task engine: removing leaked network %s of task %s",
+			network.Name, network.Labels[taskresourcenetwork.LabelTaskARN])
+		if err := engine.client.RemoveNetwork(engine.ctx, network.ID, dockerclient.RemoveNetworkTimeout); err != nil {
+			seelog.Errorf("Task engine: unable to remove leaked network %s: %v", network.Name, err)
+		}
+	}
+}
+
+// taskNetworkingConfig returns the networking config of a container attached
+// to the docker network of its task, which makes the container reachable by
+// its name from the other containers of the task through the DNS server of
+// docker. It returns nil for the other containers
+func taskNetworkingConfig(task *apitask.Task, container *apicontainer.Container,
+	hostConfig *dockercontainer.HostConfig) *network.NetworkingConfig {
+	networkName, ok := task.GetTaskBridgeNetworkName()
+	if !ok || container.IsInternal() || string(hostConfig.NetworkMode) != networkName {
+		return nil
+	}
+	return &network.NetworkingConfig{
+		EndpointsConfig: map[string]*network.EndpointSettings{
+			networkName: {
+				Aliases: []string{container.Name},
+			},
+		},
+	}
+}
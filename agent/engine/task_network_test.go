@@ -0,0 +1,90 @@
+// +build unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/dockerclient"
+	"github.com/aws/amazon-ecs-agent/agent/taskresource"
+	taskresourcenetwork "github.com/aws/amazon-ecs-agent/agent/taskresource/network"
+
+	"github.com/docker/docker/api/types"
+	dockercontainer "github.com/docker/docker/api/types/container"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemoveLeakedTaskNetworks(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	ctrl, client, _, taskEngine, _, _, _ := mocks(t, ctx, &defaultConfig)
+	defer ctrl.Finish()
+
+	task := &apitask.Task{
+		Arn: "arn:aws:ecs:us-west-2:123456789012:task/known",
+		ResourcesMapUnsafe: map[string][]taskresource.TaskResource{
+			taskresourcenetwork.ResourceName: {
+				taskresourcenetwork.NewNetworkResource(ctx, "known-arn", "ecs-task-known", 1, client),
+			},
+		},
+	}
+	client.EXPECT().ListNetworks(gomock.Any(), gomock.Any(), dockerclient.ListNetworksTimeout).Return(
+		[]types.NetworkResource{
+			{Name: "ecs-task-known", ID: "known-id"},
+			{Name: "ecs-task-leaked", ID: "leaked-id"},
+		}, nil)
+	client.EXPECT().RemoveNetwork(gomock.Any(), "leaked-id", dockerclient.RemoveNetworkTimeout).Return(nil)
+
+	taskEngine.(*DockerTaskEngine).removeLeakedTaskNetworks([]*apitask.Task{task})
+}
+
+func TestRemoveLeakedTaskNetworksListError(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	ctrl, client, _, taskEngine, _, _, _ := mocks(t, ctx, &defaultConfig)
+	defer ctrl.Finish()
+
+	client.EXPECT().ListNetworks(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("error"))
+
+	taskEngine.(*DockerTaskEngine).removeLeakedTaskNetworks(nil)
+}
+
+func TestTaskNetworkingConfig(t *testing.T) {
+	task := &apitask.Task{
+		Arn: "arn:aws:ecs:us-west-2:123456789012:task/task-id",
+		ResourcesMapUnsafe: map[string][]taskresource.TaskResource{
+			taskresourcenetwork.ResourceName: {
+				taskresourcenetwork.NewNetworkResource(context.TODO(), "task-arn", "ecs-task-backend-task-id", 1, nil),
+			},
+		},
+	}
+	container := &apicontainer.Container{Name: "web"}
+
+	networkingConfig := taskNetworkingConfig(task, container,
+		&dockercontainer.HostConfig{NetworkMode: "ecs-task-backend-task-id"})
+	require.NotNil(t, networkingConfig)
+	require.Contains(t, networkingConfig.EndpointsConfig, "ecs-task-backend-task-id")
+	assert.Equal(t, []string{"web"}, networkingConfig.EndpointsConfig["ecs-task-backend-task-id"].Aliases)
+
+	assert.Nil(t, taskNetworkingConfig(task, container, &dockercontainer.HostConfig{NetworkMode: "host"}))
+	assert.Nil(t, taskNetworkingConfig(&apitask.Task{}, container, &dockercontainer.HostConfig{NetworkMode: "bridge"}))
+}
@@ -0,0 +1,38 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	dockercontainer "github.com/docker/docker/api/types/container"
+)
+
+// applyDefaultUlimits sets the ulimits of the agent config on the container
+// when neither its task definition nor its docker config sets them
+func applyDefaultUlimits(cfg *config.Config, hostConfig *dockercontainer.HostConfig) {
+	if len(cfg.DefaultUlimits) == 0 {
+		return
+	}
+	set := make(map[string]struct{}, len(hostConfig.Ulimits))
+	for _, ulimit := range hostConfig.Ulimits {
+		set[ulimit.Name] = struct{}{}
+	}
+	for _, ulimit := range cfg.DefaultUlimits {
+		if _, ok := set[ulimit.Name]; ok {
+			continue
+		}
+		defaultUlimit := *ulimit
+		hostConfig.Ulimits = append(hostConfig.Ulimits, &defaultUlimit)
+	}
+}
@@ -0,0 +1,48 @@
+// +build unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"testing"
+
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	dockercontainer "github.com/docker/docker/api/types/container"
+	"github.com/docker/go-units"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyDefaultUlimits(t *testing.T) {
+	cfg := &config.Config{
+		DefaultUlimits: []*units.Ulimit{
+			{Name: "nofile", Soft: 1024, Hard: 4096},
+			{Name: "nproc", Soft: 2048, Hard: 2048},
+		},
+	}
+	hostConfig := &dockercontainer.HostConfig{}
+	hostConfig.Ulimits = []*units.Ulimit{{Name: "nofile", Soft: 65536, Hard: 65536}}
+
+	applyDefaultUlimits(cfg, hostConfig)
+	assert.Equal(t, []*units.Ulimit{
+		{Name: "nofile", Soft: 65536, Hard: 65536},
+		{Name: "nproc", Soft: 2048, Hard: 2048},
+	}, hostConfig.Ulimits)
+}
+
+func TestApplyDefaultUlimitsNotConfigured(t *testing.T) {
+	hostConfig := &dockercontainer.HostConfig{}
+	applyDefaultUlimits(&config.Config{}, hostConfig)
+	assert.Empty(t, hostConfig.Ulimits)
+}
@@ -0,0 +1,118 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package errorbudget
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/httpclient"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	awscreds "github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sns"
+	"github.com/pkg/errors"
+)
+
+// alarmTimeout is the time raising an alarm is allowed to take
+const alarmTimeout = 5 * time.Second
+
+// Alarm is notified when an error budget is exhausted
+type Alarm interface {
+	// Raise notifies the alarm of the exhausted budget
+	Raise(event Event) error
+}
+
+// webhookAlarm posts the events as JSON to a URL, usually of a local service
+type webhookAlarm struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookAlarm creates an Alarm posting the events to the URL
+func NewWebhookAlarm(url string) Alarm {
+	return &webhookAlarm{
+		url:    url,
+		client: httpclient.New(alarmTimeout, false),
+	}
+}
+
+// Raise posts the event to the URL of the webhook
+func (alarm *webhookAlarm) Raise(event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	resp, err := alarm.client.Post(alarm.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return errors.Errorf("webhook %s responded with status %d", alarm.url, resp.StatusCode)
+	}
+	return nil
+}
+
+// snsAlarm publishes the events as JSON to an SNS topic
+type snsAlarm struct {
+	topicARN string
+	client   *sns.SNS
+}
+
+// NewSNSAlarm creates an Alarm publishing the events to the SNS topic, in the
+// region of the topic
+func NewSNSAlarm(topicARN string, credentialProvider *awscreds.Credentials) (Alarm, error) {
+	cfg := aws.NewConfig().
+		WithHTTPClient(httpclient.New(alarmTimeout, false)).
+		WithCredentials(credentialProvider)
+	return newSNSAlarm(topicARN, cfg)
+}
+
+func newSNSAlarm(topicARN string, cfg *aws.Config) (Alarm, error) {
+	parsedARN, err := arn.Parse(topicARN)
+	if err != nil {
+		return nil, err
+	}
+	if parsedARN.Service != sns.ServiceName {
+		return nil, errors.Errorf("%s is not the ARN of an SNS topic", topicARN)
+	}
+
+	sess, err := session.NewSession(cfg.WithRegion(parsedARN.Region))
+	if err != nil {
+		return nil, err
+	}
+	return &snsAlarm{
+		topicARN: topicARN,
+		client:   sns.New(sess),
+	}, nil
+}
+
+// Raise publishes the event to the SNS topic
+func (alarm *snsAlarm) Raise(event Event) error {
+	message, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = alarm.client.Publish(&sns.PublishInput{
+		Message:  aws.String(string(message)),
+		Subject:  aws.String(fmt.Sprintf("ECS agent error budget exhausted: %s", event.Source)),
+		TopicArn: aws.String(alarm.topicARN),
+	})
+	return err
+}
@@ -0,0 +1,95 @@
+// +build unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package errorbudget
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awscreds "github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testEvent = Event{
+	ContainerInstanceARN: "arn:aws:ecs:us-west-2:123456789012:container-instance/instance-id",
+	Source:               SourceDocker,
+	Failures:             10,
+	Threshold:            10,
+	Window:               "10m0s",
+	LastError:            "timed out",
+	Time:                 time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC),
+}
+
+func TestWebhookAlarm(t *testing.T) {
+	var received Event
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+	}))
+	defer server.Close()
+
+	assert.NoError(t, NewWebhookAlarm(server.URL).Raise(testEvent))
+	assert.Equal(t, testEvent, received)
+}
+
+func TestWebhookAlarmErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	assert.Error(t, NewWebhookAlarm(server.URL).Raise(testEvent))
+}
+
+func TestSNSAlarm(t *testing.T) {
+	var form url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		assert.NoError(t, err)
+		form, err = url.ParseQuery(string(body))
+		assert.NoError(t, err)
+		w.Write([]byte(`<PublishResponse><PublishResult><MessageId>id</MessageId></PublishResult></PublishResponse>`))
+	}))
+	defer server.Close()
+
+	topicARN := "arn:aws:sns:us-west-2:123456789012:agent-alarms"
+	alarm, err := newSNSAlarm(topicARN, aws.NewConfig().
+		WithEndpoint(server.URL).
+		WithCredentials(awscreds.NewStaticCredentials("id", "secret", "")))
+	require.NoError(t, err)
+	require.NoError(t, alarm.Raise(testEvent))
+
+	assert.Equal(t, "Publish", form.Get("Action"))
+	assert.Equal(t, topicARN, form.Get("TopicArn"))
+	assert.Equal(t, "ECS agent error budget exhausted: docker", form.Get("Subject"))
+	var published Event
+	require.NoError(t, json.Unmarshal([]byte(form.Get("Message")), &published))
+	assert.Equal(t, testEvent, published)
+}
+
+func TestSNSAlarmInvalidARN(t *testing.T) {
+	_, err := newSNSAlarm("arn:aws:s3:::bucket", aws.NewConfig())
+	assert.Error(t, err)
+	_, err = newSNSAlarm("topic", aws.NewConfig())
+	assert.Error(t, err)
+}
@@ -0,0 +1,241 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package errorbudget tracks the failures internal to the agent, such as docker
+// calls timing out or the state failing to save, against budgets configured
+// for the instance. When a budget is exhausted the agent reports itself
+// unhealthy and raises an alarm, so that a degraded agent gets noticed before
+// the tasks on its instance start failing
+package errorbudget
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	awscreds "github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/cihub/seelog"
+)
+
+// Source is the kind of agent internal failure a budget applies to
+type Source string
+
+const (
+	// SourceDocker are the docker calls that time out or can't reach the daemon
+	SourceDocker Source = "docker"
+	// SourceStateSave are the failures to save the state of the agent
+	SourceStateSave Source = "state-save"
+	// SourceACSDisconnect are the unexpected disconnections from ACS
+	SourceACSDisconnect Source = "acs-disconnect"
+)
+
+var knownSources = map[Source]struct{}{
+	SourceDocker:        {},
+	SourceStateSave:     {},
+	SourceACSDisconnect: {},
+}
+
+// Global is the tracker the agent records its failures with. It tracks
+// nothing until Init configures the budgets of the instance
+var Global = NewTracker(0, nil)
+
+// Event describes an exhausted budget to the alarms
+type Event struct {
+	ContainerInstanceARN string    `json:"containerInstanceArn,omitempty"`
+	Source               Source    `json:"source"`
+	Failures             int       `json:"failures"`
+	Threshold            int       `json:"threshold"`
+	Window               string    `json:"window"`
+	LastError            string    `json:"lastError,omitempty"`
+	Time                 time.Time `json:"time"`
+}
+
+// BudgetReport is the state of the budget of a source
+type BudgetReport struct {
+	Source    Source `json:"source"`
+	Failures  int    `json:"failures"`
+	Threshold int    `json:"threshold"`
+	Exhausted bool   `json:"exhausted"`
+	LastError string `json:"lastError,omitempty"`
+}
+
+// Report is the state of all the budgets. The agent is healthy as long as none
+// of them is exhausted
+type Report struct {
+	Healthy bool           `json:"healthy"`
+	Window  string         `json:"window,omitempty"`
+	Budgets []BudgetReport `json:"budgets,omitempty"`
+}
+
+// Tracker counts the failures of each source within a sliding window, and
+// raises its alarms when the count of a source reaches the threshold of its
+// budget
+type Tracker struct {
+	window               time.Duration
+	thresholds           map[Source]int
+	alarms               []Alarm
+	containerInstanceARN string
+	failures             map[Source][]time.Time
+	lastErrors           map[Source]string
+	exhausted            map[Source]bool
+	now                  func() time.Time
+	lock                 sync.Mutex
+}
+
+// NewTracker creates a Tracker with the thresholds of the given sources.
+// Unknown sources and thresholds that aren't positive are ignored
+func NewTracker(window time.Duration, thresholds map[string]int, alarms ...Alarm) *Tracker {
+	tracker := &Tracker{now: time.Now}
+	tracker.configure(window, thresholds, alarms)
+	return tracker
+}
+
+// Init configures Global with the budgets and the alarms of the config
+func Init(cfg *config.Config, containerInstanceARN string, credentialProvider *awscreds.Credentials) {
+	if len(cfg.ErrorBudgetThresholds) == 0 {
+		return
+	}
+
+	var alarms []Alarm
+	if cfg.ErrorBudgetWebhookURL != "" {
+		alarms = append(alarms, NewWebhookAlarm(cfg.ErrorBudgetWebhookURL))
+	}
+	if cfg.ErrorBudgetSNSTopicARN != "" {
+		alarm, err := NewSNSAlarm(cfg.ErrorBudgetSNSTopicARN, credentialProvider)
+		if err != nil {
+			seelog.Warnf("Error budget: unable to set up the alarm of SNS topic %s: %v", cfg.ErrorBudgetSNSTopicARN, err)
+		} else {
+			alarms = append(alarms, alarm)
+		}
+	}
+
+	Global.lock.Lock()
+	defer Global.lock.Unlock()
+	Global.configure(cfg.ErrorBudgetWindow, cfg.ErrorBudgetThresholds, alarms)
+	Global.containerInstanceARN = containerInstanceARN
+}
+
+func (tracker *Tracker) configure(window time.Duration, thresholds map[string]int, alarms []Alarm) {
+	tracker.window = window
+	tracker.alarms = alarms
+	tracker.thresholds = make(map[Source]int)
+	tracker.failures = make(map[Source][]time.Time)
+	tracker.lastErrors = make(map[Source]string)
+	tracker.exhausted = make(map[Source]bool)
+	for name, threshold := range thresholds {
+		source := Source(name)
+		if _, ok := knownSources[source]; !ok {
+			seelog.Warnf("Error budget: ignoring the threshold of unknown failure source %s", name)
+			continue
+		}
+		if threshold <= 0 {
+			seelog.Warnf("Error budget: ignoring the threshold of %s failures, it should be positive: %d",
+				name, threshold)
+			continue
+		}
+		tracker.thresholds[source] = threshold
+	}
+}
+
+// RecordFailure records a failure of the source. The alarms are raised when the
+// failures of the source within the window reach its threshold, and aren't
+// raised again until the failures drop back below the threshold
+func (tracker *Tracker) RecordFailure(source Source, err error) {
+	tracker.lock.Lock()
+	defer tracker.lock.Unlock()
+
+	threshold, ok := tracker.thresholds[source]
+	if !ok {
+		return
+	}
+	now := tracker.now()
+	failures := append(tracker.pruneUnsafe(source, now), now)
+	tracker.failures[source] = failures
+	if err != nil {
+		tracker.lastErrors[source] = err.Error()
+	}
+	if len(failures) < threshold || tracker.exhausted[source] {
+		return
+	}
+
+	tracker.exhausted[source] = true
+	event := Event{
+		ContainerInstanceARN: tracker.containerInstanceARN,
+		Source:               source,
+		Failures:             len(failures),
+		Threshold:            threshold,
+		Window:               tracker.window.String(),
+		LastError:            tracker.lastErrors[source],
+		Time:                 now,
+	}
+	seelog.Criticalf("Error budget of %s failures exhausted: %d failures within %s, last error: %s",
+		source, event.Failures, event.Window, event.LastError)
+	for _, alarm := range tracker.alarms {
+		go raise(alarm, event)
+	}
+}
+
+// Report returns the state of the budgets
+func (tracker *Tracker) Report() *Report {
+	tracker.lock.Lock()
+	defer tracker.lock.Unlock()
+
+	report := &Report{Healthy: true}
+	if len(tracker.thresholds) == 0 {
+		return report
+	}
+	report.Window = tracker.window.String()
+	now := tracker.now()
+	for source, threshold := range tracker.thresholds {
+		failures := tracker.pruneUnsafe(source, now)
+		budget := BudgetReport{
+			Source:    source,
+			Failures:  len(failures),
+			Threshold: threshold,
+			Exhausted: len(failures) >= threshold,
+			LastError: tracker.lastErrors[source],
+		}
+		if budget.Exhausted {
+			report.Healthy = false
+		}
+		report.Budgets = append(report.Budgets, budget)
+	}
+	sort.Slice(report.Budgets, func(i, j int) bool {
+		return report.Budgets[i].Source < report.Budgets[j].Source
+	})
+	return report
+}
+
+// pruneUnsafe drops the failures of the source that are out of the window,
+// and rearms the alarms of the source once its failures are back below its
+// threshold
+func (tracker *Tracker) pruneUnsafe(source Source, now time.Time) []time.Time {
+	failures := tracker.failures[source]
+	i := 0
+	for i < len(failures) && now.Sub(failures[i]) >= tracker.window {
+		i++
+	}
+	failures = failures[i:]
+	tracker.failures[source] = failures
+	if len(failures) < tracker.thresholds[source] {
+		tracker.exhausted[source] = false
+	}
+	return failures
+}
+
+func raise(alarm Alarm, event Event) {
+	if err := alarm.Raise(event); err != nil {
+		seelog.Errorf("Error budget: unable to raise the alarm of %s failures: %v", event.Source, err)
+	}
+}
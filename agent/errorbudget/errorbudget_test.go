@@ -0,0 +1,127 @@
+// +build unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package errorbudget
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testAlarm struct {
+	events chan Event
+}
+
+func newTestAlarm() *testAlarm {
+	return &testAlarm{events: make(chan Event, 10)}
+}
+
+func (alarm *testAlarm) Raise(event Event) error {
+	alarm.events <- event
+	return nil
+}
+
+func newTestTracker(alarm Alarm) (*Tracker, *time.Time) {
+	now := time.Now()
+	tracker := NewTracker(time.Minute, map[string]int{
+		string(SourceDocker):        2,
+		string(SourceStateSave):     1,
+		"unknown":                   1,
+		string(SourceACSDisconnect): 0,
+	}, alarm)
+	tracker.now = func() time.Time { return now }
+	return tracker, &now
+}
+
+func TestNewTrackerIgnoresInvalidThresholds(t *testing.T) {
+	tracker, _ := newTestTracker(newTestAlarm())
+	assert.Equal(t, map[Source]int{SourceDocker: 2, SourceStateSave: 1}, tracker.thresholds)
+}
+
+func TestRecordFailureRaisesAlarm(t *testing.T) {
+	alarm := newTestAlarm()
+	tracker, _ := newTestTracker(alarm)
+
+	tracker.RecordFailure(SourceDocker, errors.New("timed out"))
+	assert.True(t, tracker.Report().Healthy)
+
+	tracker.RecordFailure(SourceDocker, errors.New("timed out again"))
+	select {
+	case event := <-alarm.events:
+		assert.Equal(t, SourceDocker, event.Source)
+		assert.Equal(t, 2, event.Failures)
+		assert.Equal(t, 2, event.Threshold)
+		assert.Equal(t, "1m0s", event.Window)
+		assert.Equal(t, "timed out again", event.LastError)
+	case <-time.After(time.Second):
+		t.Fatal("Alarm wasn't raised")
+	}
+	report := tracker.Report()
+	assert.False(t, report.Healthy)
+	require.Len(t, report.Budgets, 2)
+	assert.Equal(t, BudgetReport{
+		Source:    SourceDocker,
+		Failures:  2,
+		Threshold: 2,
+		Exhausted: true,
+		LastError: "timed out again",
+	}, report.Budgets[0])
+
+	// The alarm isn't raised again while the budget stays exhausted
+	tracker.RecordFailure(SourceDocker, errors.New("timed out"))
+	select {
+	case <-alarm.events:
+		t.Fatal("Alarm was raised again")
+	case <-time.After(10 * time.Millisecond):
+	}
+}
+
+func TestFailuresOutOfWindowAreDropped(t *testing.T) {
+	alarm := newTestAlarm()
+	tracker, now := newTestTracker(alarm)
+
+	tracker.RecordFailure(SourceStateSave, errors.New("disk full"))
+	<-alarm.events
+	assert.False(t, tracker.Report().Healthy)
+
+	*now = now.Add(time.Minute)
+	report := tracker.Report()
+	assert.True(t, report.Healthy)
+	assert.Equal(t, 0, report.Budgets[1].Failures)
+
+	// The alarm is raised again once the budget is exhausted again
+	tracker.RecordFailure(SourceStateSave, errors.New("disk full"))
+	select {
+	case event := <-alarm.events:
+		assert.Equal(t, SourceStateSave, event.Source)
+	case <-time.After(time.Second):
+		t.Fatal("Alarm wasn't raised again")
+	}
+}
+
+func TestRecordFailureWithoutBudget(t *testing.T) {
+	alarm := newTestAlarm()
+	tracker := NewTracker(time.Minute, nil, alarm)
+
+	tracker.RecordFailure(SourceDocker, errors.New("timed out"))
+	report := tracker.Report()
+	assert.True(t, report.Healthy)
+	assert.Empty(t, report.Budgets)
+	assert.Empty(t, alarm.events)
+}
@@ -59,12 +59,22 @@ type TaskHandler struct {
 	// tasksToContainerStates is used to collect container events
 	// between task transitions
 	tasksToContainerStates map[string][]api.ContainerStateChange
+	// pendingTaskChanges holds the task state changes waiting for the end of
+	// the aggregation window before being submitted
+	pendingTaskChanges map[string]*pendingTaskChange
 
 	//  taskHandlerLock is used to safely access the following maps:
 	// * taskToEvents
 	// * tasksToContainerStates
+	// * pendingTaskChanges
 	lock sync.RWMutex
 
+	// aggregationWindow is how long task state changes are held before being
+	// submitted, so that the changes of a task and its containers happening
+	// within the window are submitted in a single call. Changes are submitted
+	// right away when it's zero
+	aggregationWindow time.Duration
+
 	// stateSaver is a statemanager which may be used to save any
 	// changes to a task or container's SentStatus
 	stateSaver statemanager.Saver
@@ -98,6 +108,12 @@ type taskSendableEvents struct {
 	taskARN string
 }
 
+// pendingTaskChange is a task state change held for the aggregation window
+type pendingTaskChange struct {
+	change api.TaskStateChange
+	timer  *time.Timer
+}
+
 // NewTaskHandler returns a pointer to TaskHandler
 func NewTaskHandler(ctx context.Context,
 	stateManager statemanager.Saver,
@@ -109,6 +125,7 @@ func NewTaskHandler(ctx context.Context,
 		tasksToEvents:           make(map[string]*taskSendableEvents),
 		submitSemaphore:         utils.NewSemaphore(concurrentEventCalls),
 		tasksToContainerStates:  make(map[string][]api.ContainerStateChange),
+		pendingTaskChanges:      make(map[string]*pendingTaskChange),
 		stateSaver:              stateManager,
 		state:                   state,
 		client:                  client,
@@ -120,6 +137,15 @@ func NewTaskHandler(ctx context.Context,
 	return taskHandler
 }
 
+// SetAggregationWindow sets how long task state changes are held before being
+// submitted
+func (handler *TaskHandler) SetAggregationWindow(window time.Duration) {
+	handler.lock.Lock()
+	defer handler.lock.Unlock()
+
+	handler.aggregationWindow = window
+}
+
 // AddStateChangeEvent queues up the state change event to be sent to ECS.
 // If the event is for a container state change, it just gets added to the
 // handler.tasksToContainerStates map.
@@ -225,9 +251,95 @@ func (handler *TaskHandler) batchContainerEventUnsafe(event api.ContainerStateCh
 	handler.tasksToContainerStates[event.TaskArn] = append(handler.tasksToContainerStates[event.TaskArn], event)
 }
 
-// flushBatchUnsafe attaches the task arn's container events to TaskStateChange event
-// by creating the sendable event list. It then submits this event to ECS asynchronously
+// flushBatchUnsafe submits the task state change, once the aggregation window
+// ends if there's one. Changes of the task attachment aren't held, but the
+// pending change of the task is submitted first to keep the changes in order
 func (handler *TaskHandler) flushBatchUnsafe(taskStateChange *api.TaskStateChange, client api.ECSClient) {
+	if handler.aggregationWindow <= 0 {
+		handler.submitBatchUnsafe(taskStateChange, client)
+		return
+	}
+	if taskStateChange.Attachment != nil {
+		handler.flushPendingTaskChangeUnsafe(taskStateChange.TaskARN, client)
+		handler.submitBatchUnsafe(taskStateChange, client)
+		return
+	}
+
+	pending, ok := handler.pendingTaskChanges[taskStateChange.TaskARN]
+	if ok {
+		seelog.Infof("TaskHandler: aggregating task event with the pending one: %s", taskStateChange.String())
+		pending.change = mergeTaskStateChanges(pending.change, *taskStateChange)
+		return
+	}
+	taskARN := taskStateChange.TaskARN
+	handler.pendingTaskChanges[taskARN] = &pendingTaskChange{
+		change: *taskStateChange,
+		timer: time.AfterFunc(handler.aggregationWindow, func() {
+			handler.lock.Lock()
+			defer handler.lock.Unlock()
+			handler.flushPendingTaskChangeUnsafe(taskARN, client)
+		}),
+	}
+}
+
+// flushPendingTaskChangeUnsafe submits the pending task state change of the
+// task, if any
+func (handler *TaskHandler) flushPendingTaskChangeUnsafe(taskARN string, client api.ECSClient) {
+	pending, ok := handler.pendingTaskChanges[taskARN]
+	if !ok {
+		return
+	}
+	pending.timer.Stop()
+	delete(handler.pendingTaskChanges, taskARN)
+	// The container changes batched during the aggregation window are merged
+	// as well, so that only the latest change of every container is submitted
+	pending.change.Containers = mergeContainerStateChanges(pending.change.Containers,
+		handler.tasksToContainerStates[taskARN])
+	delete(handler.tasksToContainerStates, taskARN)
+	handler.submitBatchUnsafe(&pending.change, client)
+}
+
+// mergeTaskStateChanges returns the state change of the task with the status
+// of the latest change and the latest change of every container of both. The
+// task timestamps of the latest change are kept, as they only get set over time
+func mergeTaskStateChanges(previous api.TaskStateChange, latest api.TaskStateChange) api.TaskStateChange {
+	merged := latest
+	merged.Containers = mergeContainerStateChanges(previous.Containers, latest.Containers)
+	if merged.Reason == "" {
+		merged.Reason = previous.Reason
+	}
+	if merged.PullStartedAt == nil {
+		merged.PullStartedAt = previous.PullStartedAt
+	}
+	if merged.PullStoppedAt == nil {
+		merged.PullStoppedAt = previous.PullStoppedAt
+	}
+	if merged.ExecutionStoppedAt == nil {
+		merged.ExecutionStoppedAt = previous.ExecutionStoppedAt
+	}
+	return merged
+}
+
+// mergeContainerStateChanges returns the container changes of both lists,
+// keeping only the latest change of every container, in the order the
+// containers first changed
+func mergeContainerStateChanges(previous []api.ContainerStateChange, latest []api.ContainerStateChange) []api.ContainerStateChange {
+	var merged []api.ContainerStateChange
+	indexes := make(map[string]int)
+	for _, change := range append(append([]api.ContainerStateChange{}, previous...), latest...) {
+		if index, ok := indexes[change.ContainerName]; ok {
+			merged[index] = change
+			continue
+		}
+		indexes[change.ContainerName] = len(merged)
+		merged = append(merged, change)
+	}
+	return merged
+}
+
+// submitBatchUnsafe attaches the task arn's container events to TaskStateChange event
+// by creating the sendable event list. It then submits this event to ECS asynchronously
+func (handler *TaskHandler) submitBatchUnsafe(taskStateChange *api.TaskStateChange, client api.ECSClient) {
 	taskStateChange.Containers = append(taskStateChange.Containers,
 		handler.tasksToContainerStates[taskStateChange.TaskARN]...)
 	// All container events for the task have now been copied to the
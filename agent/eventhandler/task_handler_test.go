@@ -42,6 +42,7 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 const taskARN = "taskarn"
@@ -576,3 +577,107 @@ func TestSubmitTaskEventsWhenSubmittingTaskStoppedAfterRunning(t *testing.T) {
 	assert.NoError(t, err)
 	wg.Wait()
 }
+
+func TestSendsEventsAggregationWindow(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock_api.NewMockECSClient(ctrl)
+	stateManager := statemanager.NewNoopStateManager()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	handler := NewTaskHandler(ctx, stateManager, nil, client)
+	handler.SetAggregationWindow(100 * time.Millisecond)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+
+	client.EXPECT().SubmitTaskStateChange(gomock.Any()).Do(func(change api.TaskStateChange) {
+		assert.Equal(t, apitaskstatus.TaskStopped, change.Status)
+		require.Len(t, change.Containers, 1, "only the latest change of the container should be submitted")
+		assert.Equal(t, apicontainerstatus.ContainerStopped, change.Containers[0].Status)
+		wg.Done()
+	})
+
+	handler.AddStateChangeEvent(containerEvent(taskARN), client)
+	handler.AddStateChangeEvent(taskEvent(taskARN), client)
+	handler.AddStateChangeEvent(containerEventStopped(taskARN), client)
+	handler.AddStateChangeEvent(taskEventStopped(taskARN), client)
+
+	wg.Wait()
+}
+
+func TestMergeTaskStateChanges(t *testing.T) {
+	pullStartedAt := time.Now()
+	previous := api.TaskStateChange{
+		TaskARN:       taskARN,
+		Status:        apitaskstatus.TaskRunning,
+		Reason:        "reason",
+		PullStartedAt: &pullStartedAt,
+		Containers: []api.ContainerStateChange{
+			{ContainerName: "c1", Status: apicontainerstatus.ContainerRunning},
+			{ContainerName: "c2", Status: apicontainerstatus.ContainerRunning},
+		},
+	}
+	latest := api.TaskStateChange{
+		TaskARN: taskARN,
+		Status:  apitaskstatus.TaskStopped,
+		Containers: []api.ContainerStateChange{
+			{ContainerName: "c2", Status: apicontainerstatus.ContainerStopped},
+			{ContainerName: "c3", Status: apicontainerstatus.ContainerStopped},
+		},
+	}
+
+	merged := mergeTaskStateChanges(previous, latest)
+	assert.Equal(t, apitaskstatus.TaskStopped, merged.Status)
+	assert.Equal(t, "reason", merged.Reason)
+	assert.Equal(t, &pullStartedAt, merged.PullStartedAt)
+	assert.Equal(t, []api.ContainerStateChange{
+		{ContainerName: "c1", Status: apicontainerstatus.ContainerRunning},
+		{ContainerName: "c2", Status: apicontainerstatus.ContainerStopped},
+		{ContainerName: "c3", Status: apicontainerstatus.ContainerStopped},
+	}, merged.Containers)
+}
+
+func TestSendsEventsAggregationWindowAttachment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock_api.NewMockECSClient(ctrl)
+	stateManager := statemanager.NewNoopStateManager()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	handler := NewTaskHandler(ctx, stateManager, nil, client)
+	handler.SetAggregationWindow(time.Hour)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	gomock.InOrder(
+		client.EXPECT().SubmitTaskStateChange(gomock.Any()).Do(func(change api.TaskStateChange) {
+			assert.Nil(t, change.Attachment)
+			assert.Equal(t, apitaskstatus.TaskRunning, change.Status)
+			wg.Done()
+		}),
+		client.EXPECT().SubmitTaskStateChange(gomock.Any()).Do(func(change api.TaskStateChange) {
+			assert.NotNil(t, change.Attachment)
+			wg.Done()
+		}),
+	)
+
+	eniAttachment := &apieni.ENIAttachment{
+		TaskARN:   taskARN,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	assert.NoError(t, eniAttachment.StartTimer(func() {}))
+
+	handler.AddStateChangeEvent(taskEvent(taskARN), client)
+	handler.AddStateChangeEvent(api.TaskStateChange{
+		TaskARN:    taskARN,
+		Status:     apitaskstatus.TaskStatusNone,
+		Task:       &apitask.Task{},
+		Attachment: eniAttachment,
+	}, client)
+
+	wg.Wait()
+}
@@ -0,0 +1,51 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package experiments defines the experimental features of the agent. An
+// experiment is enabled on an instance with the ECS_ENABLED_EXPERIMENTS
+// environment variable, which registers it as a capability, and applies to a
+// task only when the task opts into it as well
+package experiments
+
+const (
+	// LazyPull only pulls the images of the containers of a task that aren't
+	// cached on the instance, regardless of the image pull behavior
+	LazyPull = "lazy-pull"
+)
+
+// knownExperiments are the experiments that gate a behavior of the agent. An
+// experiment is only added here along with the code path it gates
+var knownExperiments = map[string]struct{}{
+	LazyPull: {},
+}
+
+// IsKnown returns true if the agent knows about the experiment
+func IsKnown(experiment string) bool {
+	_, ok := knownExperiments[experiment]
+	return ok
+}
+
+// Enabled returns true if the experiment is both enabled on the instance and
+// requested by the task
+func Enabled(instanceExperiments []string, taskExperiments []string, experiment string) bool {
+	return contains(instanceExperiments, experiment) && contains(taskExperiments, experiment)
+}
+
+func contains(experiments []string, experiment string) bool {
+	for _, e := range experiments {
+		if e == experiment {
+			return true
+		}
+	}
+	return false
+}
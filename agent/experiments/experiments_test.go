@@ -0,0 +1,60 @@
+// +build unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package experiments
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsKnown(t *testing.T) {
+	assert.True(t, IsKnown(LazyPull))
+	assert.False(t, IsKnown("cgroup-v2"))
+	assert.False(t, IsKnown("time-travel"))
+}
+
+func TestEnabled(t *testing.T) {
+	testCases := []struct {
+		name                string
+		instanceExperiments []string
+		taskExperiments     []string
+		expected            bool
+	}{
+		{
+			name:                "enabled on instance and requested by task",
+			instanceExperiments: []string{LazyPull},
+			taskExperiments:     []string{LazyPull},
+			expected:            true,
+		},
+		{
+			name:                "enabled on instance only",
+			instanceExperiments: []string{LazyPull},
+			expected:            false,
+		},
+		{
+			name:            "requested by task only",
+			taskExperiments: []string{LazyPull},
+			expected:        false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, Enabled(tc.instanceExperiments, tc.taskExperiments, LazyPull))
+		})
+	}
+}
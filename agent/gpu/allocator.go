@@ -0,0 +1,115 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package gpu
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Allocator assigns the GPUs of the instance to the containers that require
+// them, so that no GPU is assigned to two containers at once
+type Allocator struct {
+	lock   sync.Mutex
+	gpuIDs []string
+	// owners maps the ids of the assigned GPUs to the containers they're
+	// assigned to
+	owners map[string]string
+}
+
+// NewAllocator creates an allocator of the GPUs with the given ids
+func NewAllocator(gpuIDs []string) *Allocator {
+	return &Allocator{
+		gpuIDs: append([]string{}, gpuIDs...),
+		owners: make(map[string]string),
+	}
+}
+
+// Allocate assigns count free GPUs to the owner and returns their ids. The
+// GPUs already assigned to the owner are returned as is, so that allocating
+// for the same owner again doesn't use up more GPUs
+func (a *Allocator) Allocate(owner string, count int) ([]string, error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if owned := a.ownedUnsafe(owner); len(owned) > 0 {
+		if len(owned) != count {
+			return nil, fmt.Errorf("gpu allocator: %s already has %d GPUs assigned, %d requested",
+				owner, len(owned), count)
+		}
+		return owned, nil
+	}
+	var free []string
+	for _, gpuID := range a.gpuIDs {
+		if _, ok := a.owners[gpuID]; !ok {
+			free = append(free, gpuID)
+		}
+	}
+	if len(free) < count {
+		return nil, fmt.Errorf("gpu allocator: %d GPUs requested by %s, but only %d of %d are free",
+			count, owner, len(free), len(a.gpuIDs))
+	}
+	for _, gpuID := range free[:count] {
+		a.owners[gpuID] = owner
+	}
+	return free[:count], nil
+}
+
+// Reserve assigns the GPUs with the given ids to the owner. It's used for the
+// GPUs assigned outside of the allocator, i.e. by the backend or before the
+// agent restarted. Unknown GPUs are reserved too. None of the GPUs are
+// reserved if one of them is assigned to another owner, in which case an
+// error is returned
+func (a *Allocator) Reserve(owner string, gpuIDs []string) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	var conflicts []string
+	for _, gpuID := range gpuIDs {
+		if previous, ok := a.owners[gpuID]; ok && previous != owner {
+			conflicts = append(conflicts, fmt.Sprintf("%s (assigned to %s)", gpuID, previous))
+		}
+	}
+	if len(conflicts) > 0 {
+		return fmt.Errorf("gpu allocator: GPUs reserved for %s are already assigned: %v", owner, conflicts)
+	}
+	for _, gpuID := range gpuIDs {
+		a.owners[gpuID] = owner
+	}
+	return nil
+}
+
+// Release frees the GPUs assigned to the owner
+func (a *Allocator) Release(owner string) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	for gpuID, gpuOwner := range a.owners {
+		if gpuOwner == owner {
+			delete(a.owners, gpuID)
+		}
+	}
+}
+
+// ownedUnsafe returns the ids of the GPUs assigned to the owner, in the order
+// the allocator knows them
+func (a *Allocator) ownedUnsafe(owner string) []string {
+	var owned []string
+	for _, gpuID := range a.gpuIDs {
+		if a.owners[gpuID] == owner {
+			owned = append(owned, gpuID)
+		}
+	}
+	return owned
+}
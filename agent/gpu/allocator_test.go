@@ -0,0 +1,83 @@
+// +build unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package gpu
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllocatorAllocate(t *testing.T) {
+	allocator := NewAllocator([]string{"gpu0", "gpu1", "gpu2"})
+
+	gpuIDs, err := allocator.Allocate("task/c1", 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"gpu0", "gpu1"}, gpuIDs)
+
+	gpuIDs, err = allocator.Allocate("task/c1", 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"gpu0", "gpu1"}, gpuIDs, "allocating again for the same owner should return the same GPUs")
+
+	_, err = allocator.Allocate("task/c2", 2)
+	assert.Error(t, err, "only one GPU is free")
+
+	gpuIDs, err = allocator.Allocate("task/c2", 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"gpu2"}, gpuIDs)
+}
+
+func TestAllocatorAllocateDifferentCount(t *testing.T) {
+	allocator := NewAllocator([]string{"gpu0", "gpu1"})
+
+	_, err := allocator.Allocate("task/c1", 1)
+	require.NoError(t, err)
+	_, err = allocator.Allocate("task/c1", 2)
+	assert.Error(t, err)
+}
+
+func TestAllocatorRelease(t *testing.T) {
+	allocator := NewAllocator([]string{"gpu0"})
+
+	_, err := allocator.Allocate("task/c1", 1)
+	require.NoError(t, err)
+	allocator.Release("task/c1")
+
+	gpuIDs, err := allocator.Allocate("task/c2", 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"gpu0"}, gpuIDs)
+}
+
+func TestAllocatorReserve(t *testing.T) {
+	allocator := NewAllocator([]string{"gpu0", "gpu1"})
+
+	require.NoError(t, allocator.Reserve("task/c1", []string{"gpu0"}))
+	require.NoError(t, allocator.Reserve("task/c1", []string{"gpu0"}), "reserving again for the same owner should succeed")
+
+	gpuIDs, err := allocator.Allocate("task/c2", 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"gpu1"}, gpuIDs)
+
+	assert.Error(t, allocator.Reserve("task/c3", []string{"gpu1"}))
+	allocator.Release("task/c3")
+	_, err = allocator.Allocate("task/c4", 1)
+	assert.Error(t, err, "the GPU of c2 shouldn't be taken over by c3")
+	allocator.Release("task/c2")
+	gpuIDs, err = allocator.Allocate("task/c4", 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"gpu1"}, gpuIDs)
+}
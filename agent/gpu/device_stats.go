@@ -0,0 +1,104 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package gpu
+
+import (
+	"bufio"
+	"bytes"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// deviceStatsQuery is the list of the properties of the GPUs that are
+	// sampled, in the order of the fields of the nvidia-smi output
+	deviceStatsQuery = "uuid,utilization.gpu,memory.used,memory.total,temperature.gpu"
+	// deviceStatsFields is the number of fields of each line of the
+	// nvidia-smi output
+	deviceStatsFields = 5
+)
+
+// DeviceStats is a sample of the usage of a GPU
+type DeviceStats struct {
+	// Utilization is the percentage of time the GPU was busy over the last
+	// sample period of the driver
+	Utilization float64
+	// MemoryUsedMiB is the memory of the GPU in use
+	MemoryUsedMiB uint64
+	// MemoryTotalMiB is the memory of the GPU
+	MemoryTotalMiB uint64
+	// Temperature is the temperature of the GPU in degrees Celsius
+	Temperature float64
+	// Timestamp is when the GPU was sampled
+	Timestamp time.Time
+}
+
+// MemoryUtilization returns the percentage of the memory of the GPU in use
+func (stats *DeviceStats) MemoryUtilization() float64 {
+	if stats.MemoryTotalMiB == 0 {
+		return 0
+	}
+	return 100 * float64(stats.MemoryUsedMiB) / float64(stats.MemoryTotalMiB)
+}
+
+// parseDeviceStats parses the csv output of nvidia-smi queried for the
+// deviceStatsQuery properties without header or units, into the samples of
+// the GPUs by id
+func parseDeviceStats(output []byte, timestamp time.Time) (map[string]*DeviceStats, error) {
+	deviceStats := make(map[string]*DeviceStats)
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		fields := strings.Split(line, ",")
+		if len(fields) != deviceStatsFields {
+			return nil, errors.Errorf("invalid GPU stats: %q", line)
+		}
+		for i := range fields {
+			fields[i] = strings.TrimSpace(fields[i])
+		}
+		utilization, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid utilization of GPU %s", fields[0])
+		}
+		memoryUsed, err := strconv.ParseUint(fields[2], 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid memory used of GPU %s", fields[0])
+		}
+		memoryTotal, err := strconv.ParseUint(fields[3], 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid total memory of GPU %s", fields[0])
+		}
+		temperature, err := strconv.ParseFloat(fields[4], 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid temperature of GPU %s", fields[0])
+		}
+		deviceStats[fields[0]] = &DeviceStats{
+			Utilization:    utilization,
+			MemoryUsedMiB:  memoryUsed,
+			MemoryTotalMiB: memoryTotal,
+			Temperature:    temperature,
+			Timestamp:      timestamp,
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return deviceStats, nil
+}
@@ -0,0 +1,62 @@
+// +build unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package gpu
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDeviceStats(t *testing.T) {
+	now := time.Now()
+	deviceStats, err := parseDeviceStats([]byte(
+		"GPU-0f2f5c5e, 87, 10240, 16160, 71\nGPU-4a1b9d3c, 3, 0, 16160, 38\n"), now)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]*DeviceStats{
+		"GPU-0f2f5c5e": {
+			Utilization:    87,
+			MemoryUsedMiB:  10240,
+			MemoryTotalMiB: 16160,
+			Temperature:    71,
+			Timestamp:      now,
+		},
+		"GPU-4a1b9d3c": {
+			Utilization:    3,
+			MemoryUsedMiB:  0,
+			MemoryTotalMiB: 16160,
+			Temperature:    38,
+			Timestamp:      now,
+		},
+	}, deviceStats)
+}
+
+func TestParseDeviceStatsInvalid(t *testing.T) {
+	for _, output := range []string{
+		"GPU-0f2f5c5e, 87, 10240, 16160\n",
+		"GPU-0f2f5c5e, [Not Supported], 10240, 16160, 71\n",
+	} {
+		_, err := parseDeviceStats([]byte(output), time.Now())
+		assert.Error(t, err, output)
+	}
+}
+
+func TestDeviceStatsMemoryUtilization(t *testing.T) {
+	assert.Equal(t, float64(50), (&DeviceStats{MemoryUsedMiB: 512, MemoryTotalMiB: 1024}).MemoryUtilization())
+	assert.Equal(t, float64(0), (&DeviceStats{MemoryUsedMiB: 512}).MemoryUtilization())
+}
@@ -19,9 +19,12 @@
 package mock_gpu
 
 import (
+	context "context"
 	reflect "reflect"
+	time "time"
 
 	ecs "github.com/aws/amazon-ecs-agent/agent/ecs_client/model/ecs"
+	gpu "github.com/aws/amazon-ecs-agent/agent/gpu"
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -48,6 +51,20 @@ func (m *MockGPUManager) EXPECT() *MockGPUManagerMockRecorder {
 	return m.recorder
 }
 
+// GetDeviceStats mocks base method
+func (m *MockGPUManager) GetDeviceStats(arg0 string) []*gpu.DeviceStats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeviceStats", arg0)
+	ret0, _ := ret[0].([]*gpu.DeviceStats)
+	return ret0
+}
+
+// GetDeviceStats indicates an expected call of GetDeviceStats
+func (mr *MockGPUManagerMockRecorder) GetDeviceStats(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeviceStats", reflect.TypeOf((*MockGPUManager)(nil).GetDeviceStats), arg0)
+}
+
 // GetDevices mocks base method
 func (m *MockGPUManager) GetDevices() []*ecs.PlatformDevice {
 	m.ctrl.T.Helper()
@@ -104,6 +121,20 @@ func (mr *MockGPUManagerMockRecorder) Initialize() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Initialize", reflect.TypeOf((*MockGPUManager)(nil).Initialize))
 }
 
+// SetComputeMode mocks base method
+func (m *MockGPUManager) SetComputeMode(arg0 []string, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetComputeMode", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetComputeMode indicates an expected call of SetComputeMode
+func (mr *MockGPUManagerMockRecorder) SetComputeMode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetComputeMode", reflect.TypeOf((*MockGPUManager)(nil).SetComputeMode), arg0, arg1)
+}
+
 // SetDevices mocks base method
 func (m *MockGPUManager) SetDevices() {
 	m.ctrl.T.Helper()
@@ -139,3 +170,15 @@ func (mr *MockGPUManagerMockRecorder) SetGPUIDs(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetGPUIDs", reflect.TypeOf((*MockGPUManager)(nil).SetGPUIDs), arg0)
 }
+
+// StartSampling mocks base method
+func (m *MockGPUManager) StartSampling(arg0 context.Context, arg1 time.Duration) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "StartSampling", arg0, arg1)
+}
+
+// StartSampling indicates an expected call of StartSampling
+func (mr *MockGPUManagerMockRecorder) StartSampling(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartSampling", reflect.TypeOf((*MockGPUManager)(nil).StartSampling), arg0, arg1)
+}
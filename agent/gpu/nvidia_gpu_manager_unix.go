@@ -16,10 +16,14 @@
 package gpu
 
 import (
+	"context"
 	"encoding/json"
 	"io/ioutil"
 	"os"
+	"os/exec"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/aws/amazon-ecs-agent/agent/ecs_client/model/ecs"
 	"github.com/aws/aws-sdk-go/aws"
@@ -36,6 +40,9 @@ type GPUManager interface {
 	GetDevices() []*ecs.PlatformDevice
 	SetDriverVersion(string)
 	GetDriverVersion() string
+	SetComputeMode([]string, string) error
+	StartSampling(context.Context, time.Duration)
+	GetDeviceStats(string) []*DeviceStats
 }
 
 // NvidiaGPUManager is used as a wrapper for NVML APIs and implements GPUManager
@@ -45,6 +52,10 @@ type NvidiaGPUManager struct {
 	GPUIDs        []string              `json:"GPUIDs"`
 	GPUDevices    []*ecs.PlatformDevice `json:"-"`
 	lock          sync.RWMutex
+	// deviceStats are the latest samples of the usage of each GPU, oldest
+	// first
+	deviceStats     map[string][]*DeviceStats
+	deviceStatsLock sync.RWMutex
 }
 
 const (
@@ -52,6 +63,12 @@ const (
 	GPUInfoDirPath = "/var/lib/ecs/gpu"
 	// NvidiaGPUInfoFilePath is the file path where gpus and driver info are saved
 	NvidiaGPUInfoFilePath = GPUInfoDirPath + "/nvidia-gpu-info.json"
+	// nvidiaSMIPath is the path of nvidia-smi, which sets the compute mode of
+	// the GPUs and samples their usage
+	nvidiaSMIPath = "/usr/bin/nvidia-smi"
+	// DeviceStatsSamples is the number of samples of the usage of each GPU
+	// that are kept
+	DeviceStatsSamples = 12
 )
 
 // NewNvidiaGPUManager is used to obtain NvidiaGPUManager handle
@@ -153,3 +170,76 @@ func (n *NvidiaGPUManager) GetDevices() []*ecs.PlatformDevice {
 	defer n.lock.RUnlock()
 	return n.GPUDevices
 }
+
+// SetComputeMode sets the compute mode of the GPUs
+func (n *NvidiaGPUManager) SetComputeMode(gpuIDs []string, mode string) error {
+	for _, gpuID := range gpuIDs {
+		output, err := runNvidiaSMI("-i", gpuID, "-c", mode)
+		if err != nil {
+			return errors.Wrapf(err, "could not set compute mode of GPU %s to %s: %s", gpuID, mode, string(output))
+		}
+	}
+	return nil
+}
+
+var runNvidiaSMI = func(args ...string) ([]byte, error) {
+	return exec.Command(nvidiaSMIPath, args...).CombinedOutput()
+}
+
+// StartSampling samples the usage of the GPUs every interval until the context
+// is cancelled
+func (n *NvidiaGPUManager) StartSampling(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		n.sampleDeviceStats()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// sampleDeviceStats records a sample of the usage of each GPU
+func (n *NvidiaGPUManager) sampleDeviceStats() {
+	n.lock.RLock()
+	gpuIDs := n.GetGPUIDsUnsafe()
+	n.lock.RUnlock()
+	if len(gpuIDs) == 0 {
+		return
+	}
+
+	output, err := runNvidiaSMI("-i", strings.Join(gpuIDs, ","), "--query-gpu="+deviceStatsQuery,
+		"--format=csv,noheader,nounits")
+	if err != nil {
+		seelog.Warnf("Nvidia GPU Manager: unable to sample the usage of the GPUs: %v: %s", err, string(output))
+		return
+	}
+	deviceStats, err := parseDeviceStats(output, time.Now())
+	if err != nil {
+		seelog.Warnf("Nvidia GPU Manager: unable to parse the usage of the GPUs: %v", err)
+		return
+	}
+
+	n.deviceStatsLock.Lock()
+	defer n.deviceStatsLock.Unlock()
+	if n.deviceStats == nil {
+		n.deviceStats = make(map[string][]*DeviceStats)
+	}
+	for gpuID, stats := range deviceStats {
+		samples := append(n.deviceStats[gpuID], stats)
+		if len(samples) > DeviceStatsSamples {
+			samples = samples[len(samples)-DeviceStatsSamples:]
+		}
+		n.deviceStats[gpuID] = samples
+	}
+}
+
+// GetDeviceStats returns the latest samples of the usage of the GPU, oldest
+// first
+func (n *NvidiaGPUManager) GetDeviceStats(gpuID string) []*DeviceStats {
+	n.deviceStatsLock.RLock()
+	defer n.deviceStatsLock.RUnlock()
+	return append([]*DeviceStats{}, n.deviceStats[gpuID]...)
+}
@@ -83,3 +83,65 @@ func TestSetGPUDevices(t *testing.T) {
 	nvidiaGPUManager.SetDevices()
 	assert.True(t, reflect.DeepEqual(devices, nvidiaGPUManager.GetDevices()))
 }
+
+func TestSetComputeMode(t *testing.T) {
+	defer func(run func(args ...string) ([]byte, error)) { runNvidiaSMI = run }(runNvidiaSMI)
+	var calls [][]string
+	runNvidiaSMI = func(args ...string) ([]byte, error) {
+		calls = append(calls, args)
+		return nil, nil
+	}
+
+	nvidiaGPUManager := NewNvidiaGPUManager()
+	assert.NoError(t, nvidiaGPUManager.SetComputeMode([]string{"id1", "id2"}, ComputeModeExclusiveProcess))
+	assert.Equal(t, [][]string{
+		{"-i", "id1", "-c", "EXCLUSIVE_PROCESS"},
+		{"-i", "id2", "-c", "EXCLUSIVE_PROCESS"},
+	}, calls)
+}
+
+func TestSetComputeModeError(t *testing.T) {
+	defer func(run func(args ...string) ([]byte, error)) { runNvidiaSMI = run }(runNvidiaSMI)
+	runNvidiaSMI = func(args ...string) ([]byte, error) {
+		return []byte("Insufficient Permissions"), errors.New("exit status 4")
+	}
+
+	nvidiaGPUManager := NewNvidiaGPUManager()
+	assert.Error(t, nvidiaGPUManager.SetComputeMode([]string{"id1"}, ComputeModeExclusiveProcess))
+}
+
+func TestSampleDeviceStats(t *testing.T) {
+	defer func(run func(args ...string) ([]byte, error)) { runNvidiaSMI = run }(runNvidiaSMI)
+	var calls [][]string
+	runNvidiaSMI = func(args ...string) ([]byte, error) {
+		calls = append(calls, args)
+		return []byte("id1, 50, 1024, 4096, 60\nid2, 0, 0, 4096, 40\n"), nil
+	}
+
+	nvidiaGPUManager := &NvidiaGPUManager{}
+	nvidiaGPUManager.SetGPUIDs([]string{"id1", "id2"})
+	for i := 0; i < DeviceStatsSamples+1; i++ {
+		nvidiaGPUManager.sampleDeviceStats()
+	}
+	assert.Equal(t, []string{"-i", "id1,id2", "--query-gpu=" + deviceStatsQuery, "--format=csv,noheader,nounits"},
+		calls[0])
+
+	samples := nvidiaGPUManager.GetDeviceStats("id1")
+	assert.Len(t, samples, DeviceStatsSamples, "only the latest samples should be kept")
+	assert.Equal(t, float64(50), samples[0].Utilization)
+	assert.Equal(t, float64(25), samples[0].MemoryUtilization())
+	assert.Equal(t, float64(60), samples[0].Temperature)
+	assert.Empty(t, nvidiaGPUManager.GetDeviceStats("id3"))
+}
+
+func TestSampleDeviceStatsError(t *testing.T) {
+	defer func(run func(args ...string) ([]byte, error)) { runNvidiaSMI = run }(runNvidiaSMI)
+	runNvidiaSMI = func(args ...string) ([]byte, error) {
+		return []byte("No devices were found"), errors.New("exit status 6")
+	}
+
+	nvidiaGPUManager := &NvidiaGPUManager{}
+	nvidiaGPUManager.SetGPUIDs([]string{"id1"})
+	nvidiaGPUManager.sampleDeviceStats()
+	assert.Empty(t, nvidiaGPUManager.GetDeviceStats("id1"))
+}
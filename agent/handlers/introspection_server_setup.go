@@ -23,6 +23,7 @@ import (
 
 	"github.com/aws/amazon-ecs-agent/agent/config"
 	"github.com/aws/amazon-ecs-agent/agent/engine"
+	"github.com/aws/amazon-ecs-agent/agent/errorbudget"
 	handlersutils "github.com/aws/amazon-ecs-agent/agent/handlers/utils"
 	v1 "github.com/aws/amazon-ecs-agent/agent/handlers/v1"
 	"github.com/aws/amazon-ecs-agent/agent/utils/retry"
@@ -34,7 +35,13 @@ type rootResponse struct {
 }
 
 func introspectionServerSetup(containerInstanceArn *string, taskEngine handlersutils.DockerStateResolver, cfg *config.Config) *http.Server {
-	paths := []string{v1.AgentMetadataPath, v1.TaskContainerMetadataPath, v1.LicensePath}
+	paths := []string{v1.AgentMetadataPath, v1.TaskContainerMetadataPath, v1.LicensePath, v1.HealthPath}
+	if _, ok := taskEngine.(v1.CapacityResolver); ok {
+		paths = append(paths, v1.CapacityPath)
+	}
+	if _, ok := taskEngine.(v1.QuarantinedImagesResolver); ok {
+		paths = append(paths, v1.QuarantinedImagesPath)
+	}
 	availableCommands := &rootResponse{paths}
 	// Autogenerated list of the above serverFunctions paths
 	availableCommandResponse, _ := json.Marshal(&availableCommands)
@@ -52,8 +59,15 @@ func introspectionServerSetup(containerInstanceArn *string, taskEngine handlersu
 	loggingServeMux := http.NewServeMux()
 	loggingServeMux.Handle("/", LoggingHandler{serverMux})
 
+	// The introspection API lists the tasks of all tenants, so it's only
+	// served to the host in multi tenant isolation mode
+	addr := ":" + strconv.Itoa(config.AgentIntrospectionPort)
+	if cfg.MultiTenantIsolationEnabled {
+		addr = "127.0.0.1" + addr
+	}
+
 	server := &http.Server{
-		Addr:         ":" + strconv.Itoa(config.AgentIntrospectionPort),
+		Addr:         addr,
 		Handler:      loggingServeMux,
 		ReadTimeout:  readTimeout,
 		WriteTimeout: writeTimeout,
@@ -68,8 +82,15 @@ func v1HandlersSetup(serverMux *http.ServeMux,
 	taskEngine handlersutils.DockerStateResolver,
 	cfg *config.Config) {
 	serverMux.HandleFunc(v1.AgentMetadataPath, v1.AgentMetadataHandler(containerInstanceArn, cfg))
-	serverMux.HandleFunc(v1.TaskContainerMetadataPath, v1.TaskContainerMetadataHandler(taskEngine))
+	serverMux.HandleFunc(v1.TaskContainerMetadataPath, v1.TaskContainerMetadataHandler(taskEngine, !cfg.DeprecatedFieldsDisabled))
 	serverMux.HandleFunc(v1.LicensePath, v1.LicenseHandler)
+	serverMux.HandleFunc(v1.HealthPath, v1.HealthHandler(errorbudget.Global))
+	if capacityResolver, ok := taskEngine.(v1.CapacityResolver); ok {
+		serverMux.HandleFunc(v1.CapacityPath, v1.CapacityHandler(capacityResolver))
+	}
+	if quarantinedImagesResolver, ok := taskEngine.(v1.QuarantinedImagesResolver); ok {
+		serverMux.HandleFunc(v1.QuarantinedImagesPath, v1.QuarantinedImagesHandler(quarantinedImagesResolver))
+	}
 }
 
 // ServeIntrospectionHTTPEndpoint serves information about this agent/containerInstance and tasks
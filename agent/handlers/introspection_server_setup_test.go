@@ -60,6 +60,21 @@ func TestMetadataHandler(t *testing.T) {
 	}
 }
 
+func TestMetadataHandlerCNIPluginVersions(t *testing.T) {
+	metadataHandler := v1.AgentMetadataHandler(utils.Strptr(testContainerInstanceArn), &config.Config{
+		Cluster:           testClusterArn,
+		CNIPluginVersions: map[string]string{"ecs-eni": "226db36-2019.10.0"},
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "http://localhost:"+strconv.Itoa(config.AgentIntrospectionPort), nil)
+	metadataHandler(w, req)
+
+	var resp v1.MetadataResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, map[string]string{"ecs-eni": "226db36-2019.10.0"}, resp.CNIPluginVersions)
+}
+
 func TestListMultipleTasks(t *testing.T) {
 	recorder := performMockRequest(t, "/v1/tasks")
 
@@ -220,7 +235,7 @@ func TestBackendMismatchMapping(t *testing.T) {
 	stateSetupHelper(state, []*apitask.Task{testTask})
 
 	mockStateResolver.EXPECT().State().Return(state)
-	requestHandler := v1.TaskContainerMetadataHandler(mockStateResolver)
+	requestHandler := v1.TaskContainerMetadataHandler(mockStateResolver, true)
 
 	recorder := httptest.NewRecorder()
 	req, _ := http.NewRequest("GET", "/v1/tasks", nil)
@@ -239,6 +254,36 @@ func TestBackendMismatchMapping(t *testing.T) {
 	}
 }
 
+func TestDeprecatedTaskFieldsDisabled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStateResolver := mock_utils.NewMockDockerStateResolver(ctrl)
+	testTask := &apitask.Task{
+		Arn:                 "task1",
+		DesiredStatusUnsafe: apitaskstatus.TaskRunning,
+		KnownStatusUnsafe:   apitaskstatus.TaskRunning,
+		Family:              "test",
+		Version:             "1",
+	}
+
+	state := dockerstate.NewTaskEngineState()
+	stateSetupHelper(state, []*apitask.Task{testTask})
+
+	mockStateResolver.EXPECT().State().Return(state)
+	requestHandler := v1.TaskContainerMetadataHandler(mockStateResolver, false)
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/v1/tasks?taskarn=task1", nil)
+	requestHandler(recorder, req)
+
+	var taskResponse map[string]interface{}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &taskResponse))
+	assert.Equal(t, "", taskResponse["Family"])
+	assert.Equal(t, "", taskResponse["Version"])
+	assert.Equal(t, map[string]interface{}{"Family": "test", "Revision": "1"}, taskResponse["TaskDefinition"])
+}
+
 func taskDiffHelper(t *testing.T, expected []*apitask.Task, actual v1.TasksResponse) {
 	if len(expected) != len(actual.Tasks) {
 		t.Errorf("Expected %v tasks, had %v tasks", len(expected), len(actual.Tasks))
@@ -418,3 +463,18 @@ func performMockRequest(t *testing.T, path string) *httptest.ResponseRecorder {
 
 	return recorder
 }
+
+func TestIntrospectionServerAddress(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStateResolver := mock_utils.NewMockDockerStateResolver(ctrl)
+
+	server := introspectionServerSetup(utils.Strptr(testContainerInstanceArn), mockStateResolver,
+		&config.Config{Cluster: testClusterArn})
+	assert.Equal(t, ":51678", server.Addr)
+
+	server = introspectionServerSetup(utils.Strptr(testContainerInstanceArn), mockStateResolver,
+		&config.Config{Cluster: testClusterArn, MultiTenantIsolationEnabled: true})
+	assert.Equal(t, "127.0.0.1:51678", server.Addr)
+}
@@ -42,12 +42,15 @@ import (
 	v1 "github.com/aws/amazon-ecs-agent/agent/handlers/v1"
 	v2 "github.com/aws/amazon-ecs-agent/agent/handlers/v2"
 	v3 "github.com/aws/amazon-ecs-agent/agent/handlers/v3"
+	v4 "github.com/aws/amazon-ecs-agent/agent/handlers/v4"
 	mock_audit "github.com/aws/amazon-ecs-agent/agent/logger/audit/mocks"
+	"github.com/aws/amazon-ecs-agent/agent/stats"
 	mock_stats "github.com/aws/amazon-ecs-agent/agent/stats/mock"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/docker/docker/api/types"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 const (
@@ -76,6 +79,8 @@ const (
 	v2BaseMetadataPath         = "/v2/metadata"
 	v2BaseMetadataWithTagsPath = "/v2/metadataWithTags"
 	v3BasePath                 = "/v3/"
+	v4BaseStatsPath            = "/v4/stats"
+	v4BaseMetadataPath         = "/v4/metadata"
 	v3EndpointID               = "v3eid"
 	availabilityzone           = "us-west-2b"
 	containerInstanceArn       = "containerInstanceArn-test"
@@ -972,6 +977,45 @@ func TestV3TaskStats(t *testing.T) {
 	assert.Equal(t, dockerStats.NumProcs, containerStats.NumProcs)
 }
 
+func TestV3TaskOnlyStats(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	state := mock_dockerstate.NewMockTaskEngineState(ctrl)
+	auditLog := mock_audit.NewMockAuditLogger(ctrl)
+	statsEngine := mock_stats.NewMockEngine(ctrl)
+	ecsClient := mock_api.NewMockECSClient(ctrl)
+
+	dockerStats := &types.StatsJSON{}
+	dockerStats.NumProcs = 2
+
+	containerMap := map[string]*apicontainer.DockerContainer{
+		containerName: {
+			DockerID: containerID,
+		},
+	}
+
+	gomock.InOrder(
+		state.EXPECT().TaskARNByV3EndpointID(v3EndpointID).Return(taskARN, true),
+		state.EXPECT().ContainerMapByArn(taskARN).Return(containerMap, true),
+		statsEngine.EXPECT().ContainerDockerStats(taskARN, containerID).Return(dockerStats, nil),
+	)
+	server := taskServerSetup(credentials.NewManager(), auditLog, state, ecsClient, clusterName, statsEngine,
+		config.DefaultTaskMetadataSteadyStateRate, config.DefaultTaskMetadataBurstRate, "", containerInstanceArn)
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", v3BasePath+v3EndpointID+"/taskstats", nil)
+	server.Handler.ServeHTTP(recorder, req)
+	res, err := ioutil.ReadAll(recorder.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var statsFromResult map[string]*types.StatsJSON
+	err = json.Unmarshal(res, &statsFromResult)
+	assert.NoError(t, err)
+	containerStats, ok := statsFromResult[containerID]
+	assert.True(t, ok)
+	assert.Equal(t, dockerStats.NumProcs, containerStats.NumProcs)
+}
+
 func TestV3ContainerStats(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -1058,6 +1102,141 @@ func TestV3ContainerAssociation(t *testing.T) {
 	assert.Equal(t, expectedAssociationResponse, string(res))
 }
 
+func TestV4BridgeContainerMetadata(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	state := mock_dockerstate.NewMockTaskEngineState(ctrl)
+	auditLog := mock_audit.NewMockAuditLogger(ctrl)
+	statsEngine := mock_stats.NewMockEngine(ctrl)
+	ecsClient := mock_api.NewMockECSClient(ctrl)
+
+	gomock.InOrder(
+		state.EXPECT().GetTaskByIPAddress(remoteIP).Return(taskARN, true),
+		state.EXPECT().ContainerByID(containerID).Return(bridgeContainer, true),
+		state.EXPECT().TaskByID(containerID).Return(bridgeTask, true),
+		state.EXPECT().ContainerByID(containerID).Return(bridgeContainer, true),
+		state.EXPECT().TaskByID(containerID).Return(bridgeTask, true),
+	)
+	server := taskServerSetup(credentials.NewManager(), auditLog, state, ecsClient, clusterName, statsEngine,
+		config.DefaultTaskMetadataSteadyStateRate, config.DefaultTaskMetadataBurstRate, "", containerInstanceArn)
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", v4BaseMetadataPath+"/"+containerID, nil)
+	req.RemoteAddr = remoteIP + ":" + remotePort
+	server.Handler.ServeHTTP(recorder, req)
+	res, err := ioutil.ReadAll(recorder.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var containerResponse v4.ContainerResponse
+	err = json.Unmarshal(res, &containerResponse)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedBridgeContainerResponse.ID, containerResponse.ID)
+	require.Len(t, containerResponse.Networks, 1)
+	assert.Equal(t, bridgeMode, containerResponse.Networks[0].NetworkMode)
+	assert.Equal(t, []string{bridgeIPAddr}, containerResponse.Networks[0].IPv4Addresses)
+	assert.Nil(t, containerResponse.RestartCount)
+}
+
+func TestV4ContainerStats(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	state := mock_dockerstate.NewMockTaskEngineState(ctrl)
+	auditLog := mock_audit.NewMockAuditLogger(ctrl)
+	statsEngine := mock_stats.NewMockEngine(ctrl)
+	ecsClient := mock_api.NewMockECSClient(ctrl)
+
+	dockerStats := &types.StatsJSON{}
+	dockerStats.NumProcs = 2
+	networkRateStats := &stats.NetworkStatsPerSec{
+		RxBytesPerSecond: 10,
+		TxBytesPerSecond: 20,
+	}
+	gomock.InOrder(
+		state.EXPECT().GetTaskByIPAddress(remoteIP).Return(taskARN, true),
+		statsEngine.EXPECT().ContainerDockerStats(taskARN, containerID).Return(dockerStats, nil),
+		statsEngine.EXPECT().ContainerNetworkRateStats(taskARN, containerID).Return(networkRateStats, nil),
+	)
+	server := taskServerSetup(credentials.NewManager(), auditLog, state, ecsClient, clusterName, statsEngine,
+		config.DefaultTaskMetadataSteadyStateRate, config.DefaultTaskMetadataBurstRate, "", containerInstanceArn)
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", v4BaseStatsPath+"/"+containerID, nil)
+	req.RemoteAddr = remoteIP + ":" + remotePort
+	server.Handler.ServeHTTP(recorder, req)
+	res, err := ioutil.ReadAll(recorder.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var statsFromResult v4.StatsResponse
+	err = json.Unmarshal(res, &statsFromResult)
+	assert.NoError(t, err)
+	assert.Equal(t, dockerStats.NumProcs, statsFromResult.NumProcs)
+	assert.Equal(t, networkRateStats, statsFromResult.NetworkRateStats)
+	assert.NotNil(t, statsFromResult.StorageStats)
+}
+
+func TestV4TaskStats(t *testing.T) {
+	testCases := []struct {
+		path string
+	}{
+		{
+			v4BaseStatsPath,
+		},
+		{
+			v4BaseStatsPath + "/",
+		},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing path: %s", tc.path), func(t *testing.T) {
+			state := mock_dockerstate.NewMockTaskEngineState(ctrl)
+			auditLog := mock_audit.NewMockAuditLogger(ctrl)
+			statsEngine := mock_stats.NewMockEngine(ctrl)
+			ecsClient := mock_api.NewMockECSClient(ctrl)
+
+			dockerStats := &types.StatsJSON{}
+			dockerStats.NumProcs = 2
+			containerMap := map[string]*apicontainer.DockerContainer{
+				containerName: {
+					DockerID: containerID,
+				},
+			}
+			taskNetworkStats := &stats.NetworkStats{
+				RxBytes:   100,
+				RxPackets: 2,
+				TxBytes:   200,
+				TxPackets: 3,
+			}
+			gomock.InOrder(
+				state.EXPECT().GetTaskByIPAddress(remoteIP).Return(taskARN, true),
+				state.EXPECT().ContainerMapByArn(taskARN).Return(containerMap, true),
+				statsEngine.EXPECT().TaskNetworkStats(taskARN).Return(taskNetworkStats, nil),
+				statsEngine.EXPECT().ContainerDockerStats(taskARN, containerID).Return(dockerStats, nil),
+				statsEngine.EXPECT().ContainerNetworkRateStats(taskARN, containerID).Return(nil, nil),
+			)
+			server := taskServerSetup(credentials.NewManager(), auditLog, state, ecsClient, clusterName, statsEngine,
+				config.DefaultTaskMetadataSteadyStateRate, config.DefaultTaskMetadataBurstRate, "", containerInstanceArn)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", tc.path, nil)
+			req.RemoteAddr = remoteIP + ":" + remotePort
+			server.Handler.ServeHTTP(recorder, req)
+			res, err := ioutil.ReadAll(recorder.Body)
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusOK, recorder.Code)
+			var statsFromResult map[string]*v4.StatsResponse
+			err = json.Unmarshal(res, &statsFromResult)
+			assert.NoError(t, err)
+			containerStats, ok := statsFromResult[containerID]
+			assert.True(t, ok)
+			assert.Equal(t, dockerStats.NumProcs, containerStats.NumProcs)
+			assert.Nil(t, containerStats.NetworkRateStats)
+			assert.Equal(t, taskNetworkStats, containerStats.TaskNetworkStats)
+		})
+	}
+}
+
 func TestTaskHTTPEndpoint301Redirect(t *testing.T) {
 	testPathsMap := map[string]string{
 		"http://127.0.0.1/v3///task/":           "http://127.0.0.1/v3/task/",
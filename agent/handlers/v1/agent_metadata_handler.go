@@ -32,6 +32,8 @@ func AgentMetadataHandler(containerInstanceArn *string, cfg *config.Config) func
 			Cluster:              cfg.Cluster,
 			ContainerInstanceArn: containerInstanceArn,
 			Version:              agentversion.String(),
+			Experiments:          cfg.EnabledExperiments,
+			CNIPluginVersions:    cfg.CNIPluginVersions,
 		}
 		responseJSON, _ := json.Marshal(resp)
 		utils.WriteJSONToResponse(w, http.StatusOK, responseJSON, utils.RequestTypeAgentMetadata)
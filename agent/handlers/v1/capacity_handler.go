@@ -0,0 +1,44 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/aws/amazon-ecs-agent/agent/engine"
+	"github.com/aws/amazon-ecs-agent/agent/handlers/utils"
+)
+
+// CapacityPath is the remaining capacity path for v1 handler.
+const CapacityPath = "/v1/capacity"
+
+// requestTypeCapacity is the request type of CapacityHandler.
+const requestTypeCapacity = "capacity"
+
+// CapacityResolver is implemented by task engines able to report the
+// capacity of the instance left to tasks.
+type CapacityResolver interface {
+	Capacity() engine.CapacityReport
+}
+
+// CapacityHandler creates response for 'v1/capacity' API. It responds with
+// the registered capacity of the instance, the share of it allocated to tasks
+// and what's left of it.
+func CapacityHandler(resolver CapacityResolver) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		responseJSON, _ := json.Marshal(resolver.Capacity())
+		utils.WriteJSONToResponse(w, http.StatusOK, responseJSON, requestTypeCapacity)
+	}
+}
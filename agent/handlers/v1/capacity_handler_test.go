@@ -0,0 +1,53 @@
+// +build unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/amazon-ecs-agent/agent/engine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type capacityResolver struct {
+	report engine.CapacityReport
+}
+
+func (resolver *capacityResolver) Capacity() engine.CapacityReport {
+	return resolver.report
+}
+
+func TestCapacityHandler(t *testing.T) {
+	resolver := &capacityResolver{
+		report: engine.CapacityReport{
+			Registered: engine.HostCapacity{CPU: 2048, Memory: 3800, UnavailablePortsTCP: []uint16{22}, GPUIDs: []string{"gpu-0"}},
+			Allocated:  engine.HostCapacity{CPU: 512, Memory: 1024, UnavailablePortsTCP: []uint16{80}, GPUIDs: []string{"gpu-0"}},
+			Remaining:  engine.HostCapacity{CPU: 1536, Memory: 2776, UnavailablePortsTCP: []uint16{22, 80}, GPUIDs: []string{}},
+		},
+	}
+
+	recorder := httptest.NewRecorder()
+	CapacityHandler(resolver)(recorder, httptest.NewRequest(http.MethodGet, CapacityPath, nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	var report engine.CapacityReport
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &report))
+	assert.Equal(t, resolver.report, report)
+}
@@ -0,0 +1,31 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package v1
+
+// applyDeprecatedTaskFields clears the fields of the task responses replaced
+// by TaskDefinition, Family and Version, when they aren't served anymore. The
+// fields are still served by default so that the clients reading them don't
+// break until they're turned off with
+// ECS_DISABLE_INTROSPECTION_DEPRECATED_FIELDS.
+// The fields are cleared rather than omitted to keep the schema of the task
+// response unchanged
+func applyDeprecatedTaskFields(tasks []*TaskResponse, serveDeprecatedFields bool) {
+	if serveDeprecatedFields {
+		return
+	}
+	for _, task := range tasks {
+		task.Family = ""
+		task.Version = ""
+	}
+}
@@ -0,0 +1,42 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/aws/amazon-ecs-agent/agent/errorbudget"
+	"github.com/aws/amazon-ecs-agent/agent/handlers/utils"
+)
+
+// HealthPath is the agent health path for v1 handler.
+const HealthPath = "/v1/health"
+
+// requestTypeHealth is the request type of HealthHandler.
+const requestTypeHealth = "agent health"
+
+// HealthHandler creates response for 'v1/health' API. It responds with
+// http.StatusServiceUnavailable when an error budget of the agent is exhausted.
+func HealthHandler(tracker *errorbudget.Tracker) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		report := tracker.Report()
+		status := http.StatusOK
+		if !report.Healthy {
+			status = http.StatusServiceUnavailable
+		}
+		responseJSON, _ := json.Marshal(report)
+		utils.WriteJSONToResponse(w, status, responseJSON, requestTypeHealth)
+	}
+}
@@ -0,0 +1,48 @@
+// +build unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/errorbudget"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthHandler(t *testing.T) {
+	tracker := errorbudget.NewTracker(time.Minute, map[string]int{string(errorbudget.SourceStateSave): 1})
+
+	recorder := httptest.NewRecorder()
+	HealthHandler(tracker)(recorder, httptest.NewRequest(http.MethodGet, HealthPath, nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	tracker.RecordFailure(errorbudget.SourceStateSave, errors.New("disk full"))
+	recorder = httptest.NewRecorder()
+	HealthHandler(tracker)(recorder, httptest.NewRequest(http.MethodGet, HealthPath, nil))
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+
+	var report errorbudget.Report
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &report))
+	assert.False(t, report.Healthy)
+	require.Len(t, report.Budgets, 1)
+	assert.Equal(t, "disk full", report.Budgets[0].LastError)
+}
@@ -0,0 +1,49 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/aws/amazon-ecs-agent/agent/engine"
+	"github.com/aws/amazon-ecs-agent/agent/handlers/utils"
+)
+
+// QuarantinedImagesPath is the quarantined images path for v1 handler.
+const QuarantinedImagesPath = "/v1/images/quarantined"
+
+// requestTypeQuarantinedImages is the request type of QuarantinedImagesHandler.
+const requestTypeQuarantinedImages = "quarantined images"
+
+// QuarantinedImagesResolver is implemented by task engines able to report the
+// images the image cleanup stopped trying to remove.
+type QuarantinedImagesResolver interface {
+	QuarantinedImages() []engine.QuarantinedImage
+}
+
+// QuarantinedImagesResponse is the response of the 'v1/images/quarantined' API.
+type QuarantinedImagesResponse struct {
+	Images []engine.QuarantinedImage `json:"Images"`
+}
+
+// QuarantinedImagesHandler creates response for 'v1/images/quarantined' API.
+// It responds with the images that failed to be removed too many times in a
+// row, and the errors removing them.
+func QuarantinedImagesHandler(resolver QuarantinedImagesResolver) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		responseJSON, _ := json.Marshal(QuarantinedImagesResponse{Images: resolver.QuarantinedImages()})
+		utils.WriteJSONToResponse(w, http.StatusOK, responseJSON, requestTypeQuarantinedImages)
+	}
+}
@@ -0,0 +1,63 @@
+// +build unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/engine"
+	"github.com/aws/amazon-ecs-agent/agent/engine/image"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type quarantinedImagesResolver struct {
+	images []engine.QuarantinedImage
+}
+
+func (resolver *quarantinedImagesResolver) QuarantinedImages() []engine.QuarantinedImage {
+	return resolver.images
+}
+
+func TestQuarantinedImagesHandler(t *testing.T) {
+	quarantinedAt := time.Date(2019, time.March, 1, 12, 0, 0, 0, time.UTC)
+	resolver := &quarantinedImagesResolver{
+		images: []engine.QuarantinedImage{
+			{
+				ImageID:          "sha256:qwerty",
+				Names:            []string{"busybox:latest"},
+				QuarantinedAt:    quarantinedAt,
+				DeletionFailures: 3,
+				Errors: []image.ImageError{
+					{Operation: image.DeleteOperation, Error: "image is being used", Time: quarantinedAt},
+				},
+			},
+		},
+	}
+
+	recorder := httptest.NewRecorder()
+	QuarantinedImagesHandler(resolver)(recorder, httptest.NewRequest(http.MethodGet, QuarantinedImagesPath, nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	var response QuarantinedImagesResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	assert.Equal(t, resolver.images, response.Images)
+}
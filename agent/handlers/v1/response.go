@@ -24,19 +24,29 @@ import (
 
 // MetadataResponse is the schema for the metadata response JSON object
 type MetadataResponse struct {
-	Cluster              string  `json:"Cluster"`
-	ContainerInstanceArn *string `json:"ContainerInstanceArn"`
-	Version              string  `json:"Version"`
+	Cluster              string            `json:"Cluster"`
+	ContainerInstanceArn *string           `json:"ContainerInstanceArn"`
+	Version              string            `json:"Version"`
+	Experiments          []string          `json:"Experiments,omitempty"`
+	CNIPluginVersions    map[string]string `json:"CNIPluginVersions,omitempty"`
 }
 
 // TaskResponse is the schema for the task response JSON object
 type TaskResponse struct {
-	Arn           string              `json:"Arn"`
-	DesiredStatus string              `json:"DesiredStatus,omitempty"`
-	KnownStatus   string              `json:"KnownStatus"`
-	Family        string              `json:"Family"`
-	Version       string              `json:"Version"`
-	Containers    []ContainerResponse `json:"Containers"`
+	Arn            string                  `json:"Arn"`
+	DesiredStatus  string                  `json:"DesiredStatus,omitempty"`
+	KnownStatus    string                  `json:"KnownStatus"`
+	Family         string                  `json:"Family"`
+	Version        string                  `json:"Version"`
+	TaskDefinition *TaskDefinitionResponse `json:"TaskDefinition,omitempty"`
+	Containers     []ContainerResponse     `json:"Containers"`
+}
+
+// TaskDefinitionResponse is the schema for the task definition of the task
+// response JSON object
+type TaskDefinitionResponse struct {
+	Family   string `json:"Family"`
+	Revision string `json:"Revision"`
 }
 
 // TasksResponse is the schema for the tasks response JSON object
@@ -95,7 +105,11 @@ func NewTaskResponse(task *apitask.Task, containerMap map[string]*apicontainer.D
 		KnownStatus:   knownBackendStatus,
 		Family:        task.Family,
 		Version:       task.Version,
-		Containers:    containers,
+		TaskDefinition: &TaskDefinitionResponse{
+			Family:   task.Family,
+			Revision: task.Version,
+		},
+		Containers: containers,
 	}
 }
 
@@ -44,8 +44,12 @@ func TestTaskResponse(t *testing.T) {
 		"Arn":           "t1",
 		"DesiredStatus": "RUNNING",
 		"KnownStatus":   "RUNNING",
-		"Family":        "sleep",
-		"Version":       "1",
+		"TaskDefinition": map[string]interface{}{
+			"Family":   "sleep",
+			"Revision": "1",
+		},
+		"Family":  "sleep",
+		"Version": "1",
 		"Containers": []interface{}{
 			map[string]interface{}{
 				"DockerId":   "cid",
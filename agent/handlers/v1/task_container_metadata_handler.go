@@ -32,12 +32,15 @@ const (
 )
 
 // createTaskResponse creates JSON response and sets the http status code for the task queried.
-func createTaskResponse(task *apitask.Task, found bool, resourceID string, state dockerstate.TaskEngineState) ([]byte, int) {
+func createTaskResponse(task *apitask.Task, found bool, resourceID string, state dockerstate.TaskEngineState,
+	serveDeprecatedFields bool) ([]byte, int) {
 	var responseJSON []byte
 	status := http.StatusOK
 	if found {
 		containerMap, _ := state.ContainerMapByArn(task.Arn)
-		responseJSON, _ = json.Marshal(NewTaskResponse(task, containerMap))
+		taskResponse := NewTaskResponse(task, containerMap)
+		applyDeprecatedTaskFields([]*TaskResponse{taskResponse}, serveDeprecatedFields)
+		responseJSON, _ = json.Marshal(taskResponse)
 	} else {
 		seelog.Warn("Could not find requested resource: " + resourceID)
 		responseJSON, _ = json.Marshal(&TaskResponse{})
@@ -48,8 +51,9 @@ func createTaskResponse(task *apitask.Task, found bool, resourceID string, state
 
 // TaskContainerMetadataHandler creates response for the 'v1/tasks' API. Lists all tasks if the request
 // doesn't contain any fields. Returns a Task if either of 'dockerid' or
-// 'taskarn' are specified in the request.
-func TaskContainerMetadataHandler(taskEngine utils.DockerStateResolver) func(http.ResponseWriter, *http.Request) {
+// 'taskarn' are specified in the request. The deprecated fields of the tasks
+// are only served if serveDeprecatedFields is set.
+func TaskContainerMetadataHandler(taskEngine utils.DockerStateResolver, serveDeprecatedFields bool) func(http.ResponseWriter, *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var responseJSON []byte
 		dockerTaskEngineState := taskEngine.State()
@@ -83,16 +87,18 @@ func TaskContainerMetadataHandler(taskEngine utils.DockerStateResolver) func(htt
 					return
 				}
 			}
-			responseJSON, status = createTaskResponse(task, found, dockerID, dockerTaskEngineState)
+			responseJSON, status = createTaskResponse(task, found, dockerID, dockerTaskEngineState, serveDeprecatedFields)
 			w.WriteHeader(status)
 		} else if taskARNExists {
 			// Create TaskResponse for the task arn in the query.
 			task, found := dockerTaskEngineState.TaskByArn(taskArn)
-			responseJSON, status = createTaskResponse(task, found, taskArn, dockerTaskEngineState)
+			responseJSON, status = createTaskResponse(task, found, taskArn, dockerTaskEngineState, serveDeprecatedFields)
 			w.WriteHeader(status)
 		} else {
 			// List all tasks.
-			responseJSON, _ = json.Marshal(NewTasksResponse(dockerTaskEngineState))
+			tasksResponse := NewTasksResponse(dockerTaskEngineState)
+			applyDeprecatedTaskFields(tasksResponse.Tasks, serveDeprecatedFields)
+			responseJSON, _ = json.Marshal(tasksResponse)
 		}
 		w.Write(responseJSON)
 	}
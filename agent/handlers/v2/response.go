@@ -54,6 +54,7 @@ type ContainerResponse struct {
 	DockerName    string                      `json:"DockerName"`
 	Image         string                      `json:"Image"`
 	ImageID       string                      `json:"ImageID"`
+	ImageDigest   string                      `json:"ImageDigest,omitempty"`
 	Ports         []v1.PortResponse           `json:"Ports,omitempty"`
 	Labels        map[string]string           `json:"Labels,omitempty"`
 	DesiredStatus string                      `json:"DesiredStatus"`
@@ -67,6 +68,17 @@ type ContainerResponse struct {
 	Networks      []containermetadata.Network `json:"Networks,omitempty"`
 	Health        *apicontainer.HealthStatus  `json:"Health,omitempty"`
 	Volumes       []v1.VolumeResponse         `json:"Volumes,omitempty"`
+	Overrides     *OverridesResponse          `json:"Overrides,omitempty"`
+}
+
+// OverridesResponse defines the schema for the command and entrypoint a
+// container runs with when they're overridden at run time, along with the
+// values of its task definition
+type OverridesResponse struct {
+	Command            []string `json:"Command,omitempty"`
+	OriginalCommand    []string `json:"OriginalCommand,omitempty"`
+	EntryPoint         []string `json:"EntryPoint,omitempty"`
+	OriginalEntryPoint []string `json:"OriginalEntryPoint,omitempty"`
 }
 
 // LimitsResponse defines the schema for task/cpu limits response
@@ -129,7 +141,7 @@ func NewTaskResponse(taskARN string,
 	}
 
 	for _, dockerContainer := range containerNameToDockerContainer {
-		containerResponse := newContainerResponse(dockerContainer, task.GetPrimaryENI(), state)
+		containerResponse := newContainerResponse(dockerContainer, task.GetTaskENIs(), state)
 		resp.Containers = append(resp.Containers, containerResponse)
 	}
 
@@ -176,12 +188,12 @@ func NewContainerResponse(containerID string,
 			"v2 container response: unable to find task for container '%s'", containerID)
 	}
 
-	resp := newContainerResponse(dockerContainer, task.GetPrimaryENI(), state)
+	resp := newContainerResponse(dockerContainer, task.GetTaskENIs(), state)
 	return &resp, nil
 }
 
 func newContainerResponse(dockerContainer *apicontainer.DockerContainer,
-	eni *apieni.ENI,
+	enis []*apieni.ENI,
 	state dockerstate.TaskEngineState) ContainerResponse {
 	container := dockerContainer.Container
 	resp := ContainerResponse{
@@ -190,6 +202,7 @@ func newContainerResponse(dockerContainer *apicontainer.DockerContainer,
 		DockerName:    dockerContainer.DockerName,
 		Image:         container.Image,
 		ImageID:       container.ImageID,
+		ImageDigest:   container.GetImageDigest(),
 		DesiredStatus: container.GetDesiredStatus().String(),
 		KnownStatus:   container.GetKnownStatus().String(),
 		Limits: LimitsResponse{
@@ -207,6 +220,10 @@ func newContainerResponse(dockerContainer *apicontainer.DockerContainer,
 		resp.Health = &health
 	}
 
+	if container.HasCommandOverrides() {
+		resp.Overrides = newOverridesResponse(container)
+	}
+
 	if createdAt := container.GetCreatedAt(); !createdAt.IsZero() {
 		createdAt = createdAt.UTC()
 		resp.CreatedAt = &createdAt
@@ -225,7 +242,7 @@ func newContainerResponse(dockerContainer *apicontainer.DockerContainer,
 			ContainerPort: binding.ContainerPort,
 			Protocol:      binding.Protocol.String(),
 		}
-		if eni == nil {
+		if len(enis) == 0 {
 			port.HostPort = binding.HostPort
 		} else {
 			port.HostPort = port.ContainerPort
@@ -234,16 +251,30 @@ func newContainerResponse(dockerContainer *apicontainer.DockerContainer,
 		resp.Ports = append(resp.Ports, port)
 	}
 
-	if eni != nil {
-		resp.Networks = []containermetadata.Network{
-			{
-				NetworkMode:   utils.NetworkModeAWSVPC,
-				IPv4Addresses: eni.GetIPV4Addresses(),
-				IPv6Addresses: eni.GetIPV6Addresses(),
-			},
-		}
+	// The task's ENIs are listed in order, starting with the primary ENI
+	for _, eni := range enis {
+		resp.Networks = append(resp.Networks, containermetadata.Network{
+			NetworkMode:   utils.NetworkModeAWSVPC,
+			IPv4Addresses: eni.GetIPV4Addresses(),
+			IPv6Addresses: eni.GetIPV6Addresses(),
+		})
 	}
 
 	resp.Volumes = v1.NewVolumesResponse(dockerContainer)
 	return resp
 }
+
+func newOverridesResponse(container *apicontainer.Container) *OverridesResponse {
+	resp := &OverridesResponse{}
+	if container.Overrides.Command != nil {
+		resp.Command = *container.Overrides.Command
+		resp.OriginalCommand = container.Command
+	}
+	if container.Overrides.EntryPoint != nil {
+		resp.EntryPoint = *container.Overrides.EntryPoint
+		if container.EntryPoint != nil {
+			resp.OriginalEntryPoint = *container.EntryPoint
+		}
+	}
+	return resp
+}
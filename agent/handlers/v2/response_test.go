@@ -216,6 +216,37 @@ func TestContainerResponse(t *testing.T) {
 	}
 }
 
+func TestContainerResponseOverrides(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	state := mock_dockerstate.NewMockTaskEngineState(ctrl)
+	container := &apicontainer.Container{
+		Name:       containerName,
+		Command:    []string{"run", "--all"},
+		EntryPoint: &[]string{"/bin/job"},
+		Overrides: apicontainer.ContainerOverrides{
+			Command: &[]string{"run", "--day", "2019-01-01"},
+		},
+	}
+	dockerContainer := &apicontainer.DockerContainer{
+		DockerID:   containerID,
+		DockerName: containerName,
+		Container:  container,
+	}
+	gomock.InOrder(
+		state.EXPECT().ContainerByID(containerID).Return(dockerContainer, true),
+		state.EXPECT().TaskByID(containerID).Return(&apitask.Task{}, true),
+	)
+
+	containerResponse, err := NewContainerResponse(containerID, state)
+	assert.NoError(t, err)
+	assert.Equal(t, &OverridesResponse{
+		Command:         []string{"run", "--day", "2019-01-01"},
+		OriginalCommand: []string{"run", "--all"},
+	}, containerResponse.Overrides)
+}
+
 func TestTaskResponseMarshal(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
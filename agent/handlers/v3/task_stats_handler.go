@@ -28,6 +28,10 @@ import (
 // TaskStatsPath specifies the relative URI path for serving task stats.
 var TaskStatsPath = "/v3/" + utils.ConstructMuxVar(v3EndpointIDMuxName, utils.AnythingButSlashRegEx) + "/task/stats"
 
+// TaskStatsPathWithoutSlash specifies an alternate relative URI path for serving
+// task stats, "/v3/<id>/taskstats".
+var TaskStatsPathWithoutSlash = "/v3/" + utils.ConstructMuxVar(v3EndpointIDMuxName, utils.AnythingButSlashRegEx) + "/taskstats"
+
 // TaskStatsHandler returns the handler method for handling task stats requests.
 func TaskStatsHandler(state dockerstate.TaskEngineState, statsEngine stats.Engine) func(http.ResponseWriter, *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
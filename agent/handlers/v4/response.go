@@ -15,13 +15,17 @@ package v4
 
 import (
 	"net"
+	"time"
 
 	"github.com/aws/amazon-ecs-agent/agent/api"
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apieni "github.com/aws/amazon-ecs-agent/agent/api/eni"
 	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
 	"github.com/aws/amazon-ecs-agent/agent/containermetadata"
 	"github.com/aws/amazon-ecs-agent/agent/engine/dockerstate"
 	"github.com/aws/amazon-ecs-agent/agent/handlers/utils"
 	v2 "github.com/aws/amazon-ecs-agent/agent/handlers/v2"
+	v3 "github.com/aws/amazon-ecs-agent/agent/handlers/v3"
 	"github.com/pkg/errors"
 )
 
@@ -33,10 +37,15 @@ type TaskResponse struct {
 }
 
 // ContainerResponse is the v4 Container response. It augments the v4 Network response
-// with the v2 container response object.
+// with the v2 container response object, along with when the agent pulled the
+// container's image and how many times the agent has restarted the container.
 type ContainerResponse struct {
 	*v2.ContainerResponse
-	Networks []Network `json:"Networks,omitempty"`
+	Networks           []Network  `json:"Networks,omitempty"`
+	ImagePullStartedAt *time.Time `json:"ImagePullStartedAt,omitempty"`
+	ImagePullStoppedAt *time.Time `json:"ImagePullStoppedAt,omitempty"`
+	// RestartCount is only populated for containers with a restart policy.
+	RestartCount *int `json:"RestartCount,omitempty"`
 }
 
 // Network is the v4 Network response. It adds a bunch of information about network
@@ -70,6 +79,12 @@ type NetworkInterfaceProperties struct {
 	PrivateDNSName string `json:"PrivateDNSName,omitempty"`
 	// SubnetGatewayIPV4Address is the gateway address for the network interface.
 	SubnetGatewayIPV4Address string `json:"SubnetGatewayIpv4Address,ommitempty"`
+	// IPV6SubnetCIDRBlock is the IPv6 subnet CIDR block associated with the
+	// network interface, for network interfaces with IPv6 addresses.
+	IPV6SubnetCIDRBlock string `json:"IPv6SubnetCIDRBlock,omitempty"`
+	// SubnetGatewayIPV6Address is the IPv6 gateway address for the network
+	// interface, for network interfaces with IPv6 addresses.
+	SubnetGatewayIPV6Address string `json:"SubnetGatewayIpv6Address,omitempty"`
 }
 
 // NewTaskResponse creates a new v4 response object for the task. It augments v2 task response
@@ -89,19 +104,34 @@ func NewTaskResponse(
 	if err != nil {
 		return nil, err
 	}
+	task, ok := state.TaskByArn(taskARN)
+	if !ok {
+		return nil, errors.Errorf("v4 task response: unable to find task '%s'", taskARN)
+	}
 	var containers []ContainerResponse
 	// Convert each container response into v4 container response.
-	for _, container := range v2Resp.Containers {
+	for i := range v2Resp.Containers {
+		container := &v2Resp.Containers[i]
+		// Fill in non-awsvpc network details the same way v3 does.
+		if !task.IsNetworkModeAWSVPC() {
+			if container.Networks, err = v3.GetContainerNetworkMetadata(container.ID, state); err != nil {
+				return nil, err
+			}
+		}
 		networks, err := toV4NetworkResponse(container.Networks, func() (*apitask.Task, bool) {
-			return state.TaskByArn(taskARN)
+			return task, true
 		})
 		if err != nil {
 			return nil, err
 		}
-		containers = append(containers, ContainerResponse{
-			ContainerResponse: &container,
+		resp := ContainerResponse{
+			ContainerResponse: container,
 			Networks:          networks,
-		})
+		}
+		if apiContainer, ok := task.ContainerByName(container.Name); ok {
+			resp.setContainerFields(apiContainer)
+		}
+		containers = append(containers, resp)
 	}
 
 	return &TaskResponse{
@@ -121,17 +151,50 @@ func NewContainerResponse(
 	if err != nil {
 		return nil, err
 	}
+	// Fill in non-awsvpc network details the same way v3 does.
+	if container.Networks == nil {
+		if container.Networks, err = v3.GetContainerNetworkMetadata(containerID, state); err != nil {
+			return nil, err
+		}
+	}
+	task, ok := state.TaskByID(containerID)
+	if !ok {
+		return nil, errors.Errorf("v4 container response: unable to find task for container '%s'", containerID)
+	}
 	// Convert v2 network responses into v4 network responses.
 	networks, err := toV4NetworkResponse(container.Networks, func() (*apitask.Task, bool) {
-		return state.TaskByID(containerID)
+		return task, true
 	})
 	if err != nil {
 		return nil, err
 	}
-	return &ContainerResponse{
+	resp := &ContainerResponse{
 		ContainerResponse: container,
 		Networks:          networks,
-	}, nil
+	}
+	if apiContainer, ok := task.ContainerByName(container.Name); ok {
+		resp.setContainerFields(apiContainer)
+	}
+	return resp, nil
+}
+
+// setContainerFields populates the fields that v4 adds on top of the v2
+// container response from the container in the agent's state.
+func (resp *ContainerResponse) setContainerFields(container *apicontainer.Container) {
+	if pullInfo := container.GetImagePullInfo(); pullInfo != nil {
+		if !pullInfo.PullStartedAt.IsZero() {
+			startedAt := pullInfo.PullStartedAt.UTC()
+			resp.ImagePullStartedAt = &startedAt
+		}
+		if !pullInfo.PullStoppedAt.IsZero() {
+			stoppedAt := pullInfo.PullStoppedAt.UTC()
+			resp.ImagePullStoppedAt = &stoppedAt
+		}
+	}
+	if container.GetRestartPolicy() != nil {
+		restartCount := container.GetRestartCount()
+		resp.RestartCount = &restartCount
+	}
 }
 
 // toV4NetworkResponse converts v2 network response to v4. Additional fields are only
@@ -143,18 +206,29 @@ func toV4NetworkResponse(
 	lookup func() (*apitask.Task, bool),
 ) ([]Network, error) {
 	var resp []Network
+	// awsvpcIndex is the index of the task ENI of the next awsvpc network, these
+	// are listed in the order of the task's ENIs
+	awsvpcIndex := 0
+	var enis []*apieni.ENI
 	for _, network := range networks {
 		respNetwork := Network{Network: network}
 		if network.NetworkMode == utils.NetworkModeAWSVPC {
-			task, ok := lookup()
-			if !ok {
-				return nil, errors.New("v4 task response: unable to find task")
+			if enis == nil {
+				task, ok := lookup()
+				if !ok {
+					return nil, errors.New("v4 task response: unable to find task")
+				}
+				enis = task.GetTaskENIs()
+			}
+			if awsvpcIndex >= len(enis) {
+				return nil, errors.New("v4 task response: unable to find task eni")
 			}
-			props, err := newNetworkInterfaceProperties(task)
+			props, err := newNetworkInterfaceProperties(enis[awsvpcIndex], awsvpcIndex)
 			if err != nil {
 				return nil, err
 			}
 			respNetwork.NetworkInterfaceProperties = props
+			awsvpcIndex++
 		}
 		resp = append(resp, respNetwork)
 	}
@@ -162,26 +236,36 @@ func toV4NetworkResponse(
 	return resp, nil
 }
 
-// newNetworkInterfaceProperties creates the NetworkInterfaceProperties object for a given
-// task.
-func newNetworkInterfaceProperties(task *apitask.Task) (NetworkInterfaceProperties, error) {
-	eni := task.GetPrimaryENI()
-	_, ipv4Net, err := net.ParseCIDR(eni.SubnetGatewayIPV4Address)
-	if err != nil {
-		return NetworkInterfaceProperties{}, errors.Wrapf(err,
-			"v4 metadata response: unable to parse subnet ipv4 address '%s'",
-			eni.SubnetGatewayIPV4Address)
-	}
-	return NetworkInterfaceProperties{
-		// TODO this is hard-coded to `0` for now. Once backend starts populating
-		// `Index` field for an ENI, we should set it as per that. Since we
-		// only support 1 ENI per task anyway, setting it to `0` is acceptable.
-		AttachmentIndex:          0,
-		IPV4SubnetCIDRBlock:      ipv4Net.String(),
+// newNetworkInterfaceProperties creates the NetworkInterfaceProperties object for
+// the ENI at the given index in the task's list of ENIs.
+func newNetworkInterfaceProperties(eni *apieni.ENI, attachmentIndex int) (NetworkInterfaceProperties, error) {
+	props := NetworkInterfaceProperties{
+		AttachmentIndex:          attachmentIndex,
 		MACAddress:               eni.MacAddress,
-		DomainNameServers:        eni.DomainNameServers,
+		DomainNameServers:        eni.GetDomainNameServers(),
 		DomainNameSearchList:     eni.DomainNameSearchList,
 		PrivateDNSName:           eni.PrivateDNSName,
 		SubnetGatewayIPV4Address: eni.SubnetGatewayIPV4Address,
-	}, nil
+		SubnetGatewayIPV6Address: eni.SubnetGatewayIPV6Address,
+	}
+	// The ENIs of ipv6 only subnets don't have an ipv4 subnet
+	if !eni.IsIPv6Only() {
+		_, ipv4Net, err := net.ParseCIDR(eni.SubnetGatewayIPV4Address)
+		if err != nil {
+			return NetworkInterfaceProperties{}, errors.Wrapf(err,
+				"v4 metadata response: unable to parse subnet ipv4 address '%s'",
+				eni.SubnetGatewayIPV4Address)
+		}
+		props.IPV4SubnetCIDRBlock = ipv4Net.String()
+	}
+	if eni.SubnetGatewayIPV6Address != "" {
+		_, ipv6Net, err := net.ParseCIDR(eni.SubnetGatewayIPV6Address)
+		if err != nil {
+			return NetworkInterfaceProperties{}, errors.Wrapf(err,
+				"v4 metadata response: unable to parse subnet ipv6 address '%s'",
+				eni.SubnetGatewayIPV6Address)
+		}
+		props.IPV6SubnetCIDRBlock = ipv6Net.String()
+	}
+	return props, nil
 }
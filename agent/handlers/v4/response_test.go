@@ -1,3 +1,4 @@
+//go:build unit
 // +build unit
 
 // Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
@@ -145,3 +146,132 @@ func TestNewTaskContainerResponses(t *testing.T) {
 	assert.Equal(t, "192.168.0.0/24", containerResponse.Networks[0].IPV4SubnetCIDRBlock)
 	assert.Equal(t, subnetGatewayIPV4Address, containerResponse.Networks[0].SubnetGatewayIPV4Address)
 }
+
+func TestNewContainerResponsePullTimesAndRestartCount(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	state := mock_dockerstate.NewMockTaskEngineState(ctrl)
+	pullStartedAt := time.Now()
+	pullStoppedAt := pullStartedAt.Add(time.Second)
+	container := &apicontainer.Container{
+		Name:              containerName,
+		Image:             imageName,
+		RestartPolicy:     &apicontainer.RestartPolicy{},
+		NetworkModeUnsafe: "bridge",
+		NetworkSettingsUnsafe: &types.NetworkSettings{
+			DefaultNetworkSettings: types.DefaultNetworkSettings{
+				IPAddress: "172.17.0.2",
+			},
+		},
+	}
+	container.SetImagePullInfo(&apicontainer.ImagePullInfo{
+		PullStartedAt: pullStartedAt,
+		PullStoppedAt: pullStoppedAt,
+	})
+	container.IncrementRestartCount()
+	task := &apitask.Task{
+		Arn:        taskARN,
+		Containers: []*apicontainer.Container{container},
+	}
+	dockerContainer := &apicontainer.DockerContainer{
+		DockerID:   containerID,
+		DockerName: containerName,
+		Container:  container,
+	}
+
+	gomock.InOrder(
+		state.EXPECT().ContainerByID(containerID).Return(dockerContainer, true),
+		state.EXPECT().TaskByID(containerID).Return(task, true),
+		state.EXPECT().ContainerByID(containerID).Return(dockerContainer, true),
+		state.EXPECT().TaskByID(containerID).Return(task, true),
+	)
+	containerResponse, err := NewContainerResponse(containerID, state)
+	require.NoError(t, err)
+	require.Len(t, containerResponse.Networks, 1)
+	assert.Equal(t, []string{"172.17.0.2"}, containerResponse.Networks[0].IPv4Addresses)
+	require.NotNil(t, containerResponse.ImagePullStartedAt)
+	require.NotNil(t, containerResponse.ImagePullStoppedAt)
+	require.NotNil(t, containerResponse.RestartCount)
+	assert.Equal(t, pullStartedAt.UTC().String(), containerResponse.ImagePullStartedAt.String())
+	assert.Equal(t, pullStoppedAt.UTC().String(), containerResponse.ImagePullStoppedAt.String())
+	assert.Equal(t, 1, *containerResponse.RestartCount)
+}
+
+func TestNetworkInterfacePropertiesIPv6Only(t *testing.T) {
+	task := &apitask.Task{
+		Arn: taskARN,
+		ENIs: []*apieni.ENI{
+			{
+				IPV6Addresses: []*apieni.ENIIPV6Address{
+					{
+						Address: "2600:1f14:abcd::10",
+					},
+				},
+				MacAddress:               "02:7b:64:49:b1:40",
+				SubnetGatewayIPV6Address: "2600:1f14:abcd::1/64",
+			},
+		},
+	}
+
+	props, err := newNetworkInterfaceProperties(task.GetPrimaryENI(), 0)
+	require.NoError(t, err)
+	assert.Empty(t, props.IPV4SubnetCIDRBlock)
+	assert.Equal(t, "2600:1f14:abcd::/64", props.IPV6SubnetCIDRBlock)
+	assert.Equal(t, "2600:1f14:abcd::1/64", props.SubnetGatewayIPV6Address)
+	assert.Equal(t, []string{apieni.AmazonIPV6DNSServer}, props.DomainNameServers)
+}
+
+func TestNewContainerResponseMultipleENIs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	state := mock_dockerstate.NewMockTaskEngineState(ctrl)
+	container := &apicontainer.Container{
+		Name:              containerName,
+		Image:             imageName,
+		NetworkModeUnsafe: "awsvpc",
+	}
+	task := &apitask.Task{
+		Arn:        taskARN,
+		Containers: []*apicontainer.Container{container},
+		ENIs: []*apieni.ENI{
+			{
+				IPV4Addresses: []*apieni.ENIIPV4Address{
+					{
+						Address: eniIPv4Address,
+					},
+				},
+				MacAddress:               "02:7b:64:49:b1:40",
+				SubnetGatewayIPV4Address: subnetGatewayIPV4Address,
+			},
+			{
+				IPV4Addresses: []*apieni.ENIIPV4Address{
+					{
+						Address: "10.0.0.5",
+					},
+				},
+				MacAddress:               "02:7b:64:49:b1:41",
+				SubnetGatewayIPV4Address: "10.0.0.1/16",
+			},
+		},
+	}
+	dockerContainer := &apicontainer.DockerContainer{
+		DockerID:   containerID,
+		DockerName: containerName,
+		Container:  container,
+	}
+
+	state.EXPECT().ContainerByID(containerID).Return(dockerContainer, true).AnyTimes()
+	state.EXPECT().TaskByID(containerID).Return(task, true).AnyTimes()
+	containerResponse, err := NewContainerResponse(containerID, state)
+	require.NoError(t, err)
+	require.Len(t, containerResponse.Networks, 2)
+	assert.Equal(t, []string{eniIPv4Address}, containerResponse.Networks[0].IPv4Addresses)
+	assert.Equal(t, 0, containerResponse.Networks[0].AttachmentIndex)
+	assert.Equal(t, "192.168.0.0/24", containerResponse.Networks[0].IPV4SubnetCIDRBlock)
+	assert.Equal(t, []string{"10.0.0.5"}, containerResponse.Networks[1].IPv4Addresses)
+	assert.Equal(t, 1, containerResponse.Networks[1].AttachmentIndex)
+	assert.Equal(t, "02:7b:64:49:b1:41", containerResponse.Networks[1].MACAddress)
+	assert.Equal(t, "10.0.0.0/16", containerResponse.Networks[1].IPV4SubnetCIDRBlock)
+}
@@ -0,0 +1,151 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package hostport assigns host ports from a range to the port mappings of
+// containers that don't specify one
+package hostport
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+)
+
+// hostPort is a host port of a protocol
+type hostPort struct {
+	port     uint16
+	protocol apicontainer.TransportProtocol
+}
+
+// Allocator assigns the host ports of a range to the port mappings of
+// containers, so that no port is assigned to two containers at once. Ports
+// that are reserved or in use on the instance are skipped
+type Allocator struct {
+	lock  sync.Mutex
+	start uint16
+	end   uint16
+	// reserved are the ports never assigned, such as the reserved ports of
+	// the instance
+	reserved map[hostPort]struct{}
+	// owners maps the assigned ports to the containers they're assigned to
+	owners map[hostPort]string
+	// next is the port the search for a free port starts from, so that the
+	// ports of the range are used in turn rather than reusing the ports just
+	// released
+	next uint16
+	// inUse returns true if the port is in use on the instance. It's
+	// swappable for testing
+	inUse func(port uint16, protocol apicontainer.TransportProtocol) bool
+}
+
+// NewAllocator creates an allocator of the ports from start to end, inclusive,
+// that never assigns the reserved TCP and UDP ports
+func NewAllocator(start, end uint16, reservedTCP, reservedUDP []uint16) *Allocator {
+	reserved := make(map[hostPort]struct{})
+	for _, port := range reservedTCP {
+		reserved[hostPort{port, apicontainer.TransportProtocolTCP}] = struct{}{}
+	}
+	for _, port := range reservedUDP {
+		reserved[hostPort{port, apicontainer.TransportProtocolUDP}] = struct{}{}
+	}
+	return &Allocator{
+		start:    start,
+		end:      end,
+		reserved: reserved,
+		owners:   make(map[hostPort]string),
+		next:     start,
+		inUse:    portInUse,
+	}
+}
+
+// Allocate assigns a free port of the protocol to the owner and returns it
+func (a *Allocator) Allocate(owner string, protocol apicontainer.TransportProtocol) (uint16, error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	size := int(a.end) - int(a.start) + 1
+	for i := 0; i < size; i++ {
+		port := a.next
+		if a.next == a.end {
+			a.next = a.start
+		} else {
+			a.next++
+		}
+		key := hostPort{port, protocol}
+		if _, ok := a.reserved[key]; ok {
+			continue
+		}
+		if _, ok := a.owners[key]; ok {
+			continue
+		}
+		if a.inUse(port, protocol) {
+			continue
+		}
+		a.owners[key] = owner
+		return port, nil
+	}
+	return 0, fmt.Errorf("host port allocator: no free %s port left in range %d-%d for %s",
+		protocol.String(), a.start, a.end, owner)
+}
+
+// Reserve assigns the port to the owner. It's used for the ports assigned
+// before the agent restarted. The port is reserved even if it's assigned to
+// another owner, in which case an error is returned
+func (a *Allocator) Reserve(owner string, port uint16, protocol apicontainer.TransportProtocol) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	key := hostPort{port, protocol}
+	previous, ok := a.owners[key]
+	a.owners[key] = owner
+	if ok && previous != owner {
+		return fmt.Errorf("host port allocator: %s port %d reserved for %s was already assigned to %s",
+			protocol.String(), port, owner, previous)
+	}
+	return nil
+}
+
+// Release frees the ports assigned to the owner
+func (a *Allocator) Release(owner string) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	for key, portOwner := range a.owners {
+		if portOwner == owner {
+			delete(a.owners, key)
+		}
+	}
+}
+
+// portInUse returns true if the port can't be listened on, such as when it's
+// taken by another daemon of the instance
+func portInUse(port uint16, protocol apicontainer.TransportProtocol) bool {
+	address := net.JoinHostPort("", strconv.Itoa(int(port)))
+	if protocol == apicontainer.TransportProtocolUDP {
+		conn, err := net.ListenPacket("udp", address)
+		if err != nil {
+			return true
+		}
+		conn.Close()
+		return false
+	}
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return true
+	}
+	listener.Close()
+	return false
+}
@@ -0,0 +1,99 @@
+// +build unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package hostport
+
+import (
+	"net"
+	"testing"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAllocator(start, end uint16, reservedTCP []uint16, inUse ...uint16) *Allocator {
+	allocator := NewAllocator(start, end, reservedTCP, nil)
+	allocator.inUse = func(port uint16, protocol apicontainer.TransportProtocol) bool {
+		for _, p := range inUse {
+			if p == port {
+				return true
+			}
+		}
+		return false
+	}
+	return allocator
+}
+
+func TestAllocatorAllocate(t *testing.T) {
+	allocator := newTestAllocator(40000, 40003, []uint16{40001}, 40002)
+
+	port, err := allocator.Allocate("task/c1", apicontainer.TransportProtocolTCP)
+	require.NoError(t, err)
+	assert.Equal(t, uint16(40000), port)
+
+	port, err = allocator.Allocate("task/c2", apicontainer.TransportProtocolTCP)
+	require.NoError(t, err)
+	assert.Equal(t, uint16(40003), port, "reserved and in use ports should be skipped")
+
+	_, err = allocator.Allocate("task/c3", apicontainer.TransportProtocolTCP)
+	assert.Error(t, err, "no TCP port should be left")
+
+	port, err = allocator.Allocate("task/c3", apicontainer.TransportProtocolUDP)
+	require.NoError(t, err)
+	assert.Equal(t, uint16(40000), port, "ports of each protocol should be assigned separately")
+}
+
+func TestAllocatorRelease(t *testing.T) {
+	allocator := newTestAllocator(40000, 40001, nil)
+
+	port, err := allocator.Allocate("task/c1", apicontainer.TransportProtocolTCP)
+	require.NoError(t, err)
+	assert.Equal(t, uint16(40000), port)
+	allocator.Release("task/c1")
+
+	port, err = allocator.Allocate("task/c2", apicontainer.TransportProtocolTCP)
+	require.NoError(t, err)
+	assert.Equal(t, uint16(40001), port, "the next port of the range should be used first")
+	port, err = allocator.Allocate("task/c3", apicontainer.TransportProtocolTCP)
+	require.NoError(t, err)
+	assert.Equal(t, uint16(40000), port, "the released port should be assigned again")
+}
+
+func TestAllocatorReserve(t *testing.T) {
+	allocator := newTestAllocator(40000, 40001, nil)
+
+	require.NoError(t, allocator.Reserve("task/c1", 40000, apicontainer.TransportProtocolTCP))
+	require.NoError(t, allocator.Reserve("task/c1", 40000, apicontainer.TransportProtocolTCP),
+		"reserving again for the same owner should succeed")
+
+	port, err := allocator.Allocate("task/c2", apicontainer.TransportProtocolTCP)
+	require.NoError(t, err)
+	assert.Equal(t, uint16(40001), port)
+
+	assert.Error(t, allocator.Reserve("task/c3", 40001, apicontainer.TransportProtocolTCP))
+	allocator.Release("task/c2")
+	_, err = allocator.Allocate("task/c4", apicontainer.TransportProtocolTCP)
+	assert.Error(t, err, "the port reserved for c3 shouldn't be released with c2")
+}
+
+func TestPortInUse(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	port := uint16(listener.Addr().(*net.TCPAddr).Port)
+	assert.True(t, portInUse(port, apicontainer.TransportProtocolTCP))
+}
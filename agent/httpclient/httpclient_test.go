@@ -17,12 +17,17 @@ package httpclient
 
 import (
 	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/metrics"
 	"github.com/aws/amazon-ecs-agent/agent/utils/cipher"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -46,3 +51,44 @@ func TestNewHttpClientProxy(t *testing.T) {
 	// Error message should contain the proxy url which shows that client tried to use the proxy url to connect
 	assert.True(t, strings.Contains(err.Error(), proxy_url), "proxy url not found in: %s", err.Error())
 }
+
+func TestLatencyRoundTripper(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.PrometheusMetricsEnabled = true
+	registry := prometheus.NewRegistry()
+	metrics.MustInit(&cfg, registry)
+	defer func() {
+		metrics.MetricsEngineGlobal = &metrics.MetricsEngine{}
+	}()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	serverURL, err := url.Parse(server.URL)
+	assert.NoError(t, err)
+
+	client := &http.Client{Transport: WithLatencyMetrics(&http.Transport{}, metrics.ECRService)}
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(server.URL)
+		assert.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	metricFamilies, err := registry.Gather()
+	assert.NoError(t, err)
+	counts := make(map[string]uint64)
+	for _, metricFamily := range metricFamilies {
+		for _, metric := range metricFamily.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "Endpoint" {
+					assert.Equal(t, serverURL.Host, label.GetValue())
+				}
+			}
+			counts[metricFamily.GetName()] += metric.GetSummary().GetSampleCount()
+		}
+	}
+	// The second request reuses the connection of the first one
+	assert.Equal(t, uint64(1), counts["AgentMetrics_Connection_connect_duration_seconds"])
+	assert.Equal(t, uint64(2), counts["AgentMetrics_Connection_round_trip_duration_seconds"])
+}
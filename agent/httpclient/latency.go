@@ -0,0 +1,70 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package httpclient
+
+import (
+	"net/http"
+	"net/http/httptrace"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/metrics"
+)
+
+// latencyRoundTripper records connection establishment and round trip latencies
+// of the requests it makes in the global metrics engine
+type latencyRoundTripper struct {
+	service   string
+	transport http.RoundTripper
+}
+
+// WithLatencyMetrics wraps the transport so that the latencies of requests made
+// over it are recorded per endpoint for the service. The time taken to set up a
+// new connection (DNS, TCP and TLS) is recorded as the connect latency, and the
+// time from sending a request over a connection to receiving the response
+// headers is recorded as the round trip latency.
+func WithLatencyMetrics(transport http.RoundTripper, service string) http.RoundTripper {
+	return &latencyRoundTripper{
+		service:   service,
+		transport: transport,
+	}
+}
+
+func (client *latencyRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	endpoint := req.URL.Host
+	var getConn, gotConn time.Time
+	trace := &httptrace.ClientTrace{
+		GetConn: func(string) {
+			getConn = time.Now()
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			gotConn = time.Now()
+			if !info.Reused && !getConn.IsZero() {
+				metrics.MetricsEngineGlobal.RecordConnectLatency(client.service, endpoint, gotConn.Sub(getConn))
+			}
+		},
+	}
+	resp, err := client.transport.RoundTrip(req.WithContext(httptrace.WithClientTrace(req.Context(), trace)))
+	if err == nil && !gotConn.IsZero() {
+		metrics.MetricsEngineGlobal.RecordRoundTripLatency(client.service, endpoint, time.Since(gotConn))
+	}
+	return resp, err
+}
+
+func (client *latencyRoundTripper) CancelRequest(req *http.Request) {
+	if canceler, ok := client.transport.(interface {
+		CancelRequest(*http.Request)
+	}); ok {
+		canceler.CancelRequest(req)
+	}
+}
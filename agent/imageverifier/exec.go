@@ -0,0 +1,103 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package imageverifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+const (
+	// maxReasonLength is the maximum length of the output of the hook included
+	// in the verification error, so that it fits in the container stop reason
+	maxReasonLength = 256
+
+	imageEnvVar         = "ECS_IMAGE"
+	imageDigestEnvVar   = "ECS_IMAGE_DIGEST"
+	taskARNEnvVar       = "ECS_TASK_ARN"
+	containerNameEnvVar = "ECS_CONTAINER_NAME"
+)
+
+// execVerifier verifies images by running an executable, such as a script
+// calling cosign or notation, with the image reference as its argument. The
+// image is verified when the executable exits with a zero status.
+type execVerifier struct {
+	hook    string
+	timeout time.Duration
+}
+
+// NewExecVerifier returns a verifier running the hook executable to verify
+// images, for up to the timeout
+func NewExecVerifier(hook string, timeout time.Duration) Verifier {
+	return &execVerifier{
+		hook:    hook,
+		timeout: timeout,
+	}
+}
+
+// Verify runs the hook with the image reference as its argument, and the image,
+// task and container details in its environment
+func (verifier *execVerifier) Verify(ctx context.Context, image Image) error {
+	ctx, cancel := context.WithTimeout(ctx, verifier.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, verifier.hook, image.Reference)
+	cmd.Env = append(os.Environ(),
+		imageEnvVar+"="+image.Name,
+		imageDigestEnvVar+"="+image.Digest,
+		taskARNEnvVar+"="+image.TaskARN,
+		containerNameEnvVar+"="+image.ContainerName)
+	// The output is written to a file rather than a pipe, so that processes
+	// started by the hook can't keep it running past the timeout
+	output, err := ioutil.TempFile("", "image-verification")
+	if err != nil {
+		return fmt.Errorf("unable to create verification hook output file: %v", err)
+	}
+	defer os.Remove(output.Name())
+	defer output.Close()
+	cmd.Stdout = output
+	cmd.Stderr = output
+
+	err = cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("verification hook timed out after %s", verifier.timeout)
+	}
+	if err != nil {
+		// The output of the hook is expected to explain why verification failed
+		outputBytes, _ := ioutil.ReadFile(output.Name())
+		reason := strings.TrimSpace(string(outputBytes))
+		if reason == "" {
+			return fmt.Errorf("verification hook failed: %v", err)
+		}
+		return errors.New(truncate(reason))
+	}
+	return nil
+}
+
+// truncate returns the last line of the output, truncated to maxReasonLength
+func truncate(output string) string {
+	if idx := strings.LastIndex(output, "\n"); idx >= 0 {
+		output = output[idx+1:]
+	}
+	if len(output) > maxReasonLength {
+		output = output[:maxReasonLength]
+	}
+	return output
+}
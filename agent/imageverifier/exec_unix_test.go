@@ -0,0 +1,88 @@
+// +build !windows,unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package imageverifier
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeHook(t *testing.T, script string) (string, func()) {
+	dir, err := ioutil.TempDir("", "imageverifier")
+	require.NoError(t, err)
+	hook := filepath.Join(dir, "hook.sh")
+	require.NoError(t, ioutil.WriteFile(hook, []byte("#!/bin/sh\n"+script), 0755))
+	return hook, func() { os.RemoveAll(dir) }
+}
+
+func TestExecVerifierVerified(t *testing.T) {
+	hook, cleanup := writeHook(t, `
+[ "$1" = "image@sha256:abc" ] || exit 1
+[ "$ECS_IMAGE" = "image:tag" ] || exit 1
+[ "$ECS_IMAGE_DIGEST" = "sha256:abc" ] || exit 1
+[ "$ECS_TASK_ARN" = "arn" ] || exit 1
+[ "$ECS_CONTAINER_NAME" = "c1" ] || exit 1
+`)
+	defer cleanup()
+
+	verifier := NewExecVerifier(hook, time.Minute)
+	err := verifier.Verify(context.TODO(), Image{
+		TaskARN:       "arn",
+		ContainerName: "c1",
+		Name:          "image:tag",
+		Digest:        "sha256:abc",
+		Reference:     "image@sha256:abc",
+	})
+	assert.NoError(t, err)
+}
+
+func TestExecVerifierFailed(t *testing.T) {
+	hook, cleanup := writeHook(t, `
+echo "verifying $1"
+echo "no matching signatures" >&2
+exit 1
+`)
+	defer cleanup()
+
+	verifier := NewExecVerifier(hook, time.Minute)
+	err := verifier.Verify(context.TODO(), Image{Name: "image", Reference: "image"})
+	assert.EqualError(t, err, "no matching signatures")
+}
+
+func TestExecVerifierFailedWithoutOutput(t *testing.T) {
+	hook, cleanup := writeHook(t, "exit 2\n")
+	defer cleanup()
+
+	verifier := NewExecVerifier(hook, time.Minute)
+	err := verifier.Verify(context.TODO(), Image{Name: "image", Reference: "image"})
+	assert.EqualError(t, err, "verification hook failed: exit status 2")
+}
+
+func TestExecVerifierTimeout(t *testing.T) {
+	hook, cleanup := writeHook(t, "sleep 5\n")
+	defer cleanup()
+
+	verifier := NewExecVerifier(hook, 100*time.Millisecond)
+	err := verifier.Verify(context.TODO(), Image{Name: "image", Reference: "image"})
+	assert.EqualError(t, err, "verification hook timed out after 100ms")
+}
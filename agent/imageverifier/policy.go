@@ -0,0 +1,128 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package imageverifier
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+)
+
+const (
+	// baseImageDigestLabel is the OCI annotation of the digest of the image
+	// an image is built from
+	baseImageDigestLabel = "org.opencontainers.image.base.digest"
+
+	// PolicyRuleRequiredLabel is the rule of images missing a required label
+	PolicyRuleRequiredLabel = "required-label"
+	// PolicyRuleDisallowedDigest is the rule of images, or images built from
+	// base images, with a disallowed digest
+	PolicyRuleDisallowedDigest = "disallowed-digest"
+)
+
+// ImageInspector inspects the images pulled by the docker daemon
+type ImageInspector interface {
+	InspectImage(string) (*types.ImageInspect, error)
+}
+
+// PolicyViolation is the reason an image doesn't comply with the image policy
+type PolicyViolation struct {
+	// Rule is the rule of the policy the image breaks
+	Rule string
+	// Detail describes how the image breaks the rule
+	Detail string
+}
+
+func (violation *PolicyViolation) Error() string {
+	return fmt.Sprintf("image policy %s: %s", violation.Rule, violation.Detail)
+}
+
+// policyVerifier verifies the labels and digests of images against the image
+// policy of the config, after they're pulled
+type policyVerifier struct {
+	inspector         ImageInspector
+	requiredLabels    map[string]string
+	disallowedDigests map[string]struct{}
+}
+
+// NewPolicyVerifier returns a verifier rejecting the images that don't have
+// the required labels, or whose digest or base image digest is disallowed
+func NewPolicyVerifier(inspector ImageInspector, requiredLabels map[string]string,
+	disallowedDigests []string) Verifier {
+	digests := make(map[string]struct{}, len(disallowedDigests))
+	for _, digest := range disallowedDigests {
+		digests[digest] = struct{}{}
+	}
+	return &policyVerifier{
+		inspector:         inspector,
+		requiredLabels:    requiredLabels,
+		disallowedDigests: digests,
+	}
+}
+
+// Verify inspects the image and returns a *PolicyViolation when it doesn't
+// comply with the policy
+func (verifier *policyVerifier) Verify(ctx context.Context, image Image) error {
+	inspect, err := verifier.inspector.InspectImage(image.Reference)
+	if err != nil {
+		return fmt.Errorf("unable to inspect image to verify its policy: %v", err)
+	}
+	var labels map[string]string
+	if inspect.Config != nil {
+		labels = inspect.Config.Labels
+	}
+
+	for _, digest := range imageDigests(inspect) {
+		if _, ok := verifier.disallowedDigests[digest]; ok {
+			return &PolicyViolation{Rule: PolicyRuleDisallowedDigest, Detail: "image digest " + digest + " is disallowed"}
+		}
+	}
+	if digest, ok := labels[baseImageDigestLabel]; ok {
+		if _, ok := verifier.disallowedDigests[digest]; ok {
+			return &PolicyViolation{Rule: PolicyRuleDisallowedDigest, Detail: "base image digest " + digest + " is disallowed"}
+		}
+	}
+
+	names := make([]string, 0, len(verifier.requiredLabels))
+	for name := range verifier.requiredLabels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value, ok := labels[name]
+		if !ok {
+			return &PolicyViolation{Rule: PolicyRuleRequiredLabel, Detail: "label " + name + " is missing"}
+		}
+		if required := verifier.requiredLabels[name]; required != "" && value != required {
+			return &PolicyViolation{Rule: PolicyRuleRequiredLabel,
+				Detail: fmt.Sprintf("label %s is %q, expected %q", name, value, required)}
+		}
+	}
+	return nil
+}
+
+// imageDigests returns the ID of the image and the digests of its repository
+// references
+func imageDigests(inspect *types.ImageInspect) []string {
+	digests := []string{inspect.ID}
+	for _, repoDigest := range inspect.RepoDigests {
+		if idx := strings.LastIndex(repoDigest, "@"); idx >= 0 {
+			digests = append(digests, repoDigest[idx+1:])
+		}
+	}
+	return digests
+}
@@ -0,0 +1,112 @@
+// +build unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package imageverifier
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	dockercontainer "github.com/docker/docker/api/types/container"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeImageInspector struct {
+	inspect *types.ImageInspect
+	err     error
+}
+
+func (inspector *fakeImageInspector) InspectImage(string) (*types.ImageInspect, error) {
+	return inspector.inspect, inspector.err
+}
+
+func newTestImageInspect(labels map[string]string) *types.ImageInspect {
+	return &types.ImageInspect{
+		ID:          "sha256:image-id",
+		RepoDigests: []string{"registry.example.com/app@sha256:repo-digest"},
+		Config:      &dockercontainer.Config{Labels: labels},
+	}
+}
+
+func TestPolicyVerifier(t *testing.T) {
+	testCases := []struct {
+		name              string
+		labels            map[string]string
+		requiredLabels    map[string]string
+		disallowedDigests []string
+		rule              string
+	}{
+		{
+			name:           "compliant",
+			labels:         map[string]string{"org.example.team": "payments", "org.example.approved": "true"},
+			requiredLabels: map[string]string{"org.example.team": "", "org.example.approved": "true"},
+		},
+		{
+			name:           "missing label",
+			labels:         map[string]string{"org.example.approved": "true"},
+			requiredLabels: map[string]string{"org.example.team": ""},
+			rule:           PolicyRuleRequiredLabel,
+		},
+		{
+			name:           "wrong label value",
+			labels:         map[string]string{"org.example.approved": "false"},
+			requiredLabels: map[string]string{"org.example.approved": "true"},
+			rule:           PolicyRuleRequiredLabel,
+		},
+		{
+			name:              "disallowed image id",
+			disallowedDigests: []string{"sha256:image-id"},
+			rule:              PolicyRuleDisallowedDigest,
+		},
+		{
+			name:              "disallowed repo digest",
+			disallowedDigests: []string{"sha256:repo-digest"},
+			rule:              PolicyRuleDisallowedDigest,
+		},
+		{
+			name:              "disallowed base image",
+			labels:            map[string]string{baseImageDigestLabel: "sha256:base"},
+			disallowedDigests: []string{"sha256:base"},
+			rule:              PolicyRuleDisallowedDigest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			verifier := NewPolicyVerifier(&fakeImageInspector{inspect: newTestImageInspect(tc.labels)},
+				tc.requiredLabels, tc.disallowedDigests)
+			err := verifier.Verify(context.TODO(), Image{Reference: "registry.example.com/app:latest"})
+			if tc.rule == "" {
+				assert.NoError(t, err)
+				return
+			}
+			violation, ok := err.(*PolicyViolation)
+			require.True(t, ok, "expected a policy violation, got %v", err)
+			assert.Equal(t, tc.rule, violation.Rule)
+		})
+	}
+}
+
+func TestPolicyVerifierInspectError(t *testing.T) {
+	verifier := NewPolicyVerifier(&fakeImageInspector{err: errors.New("no such image")},
+		map[string]string{"org.example.team": ""}, nil)
+	err := verifier.Verify(context.TODO(), Image{Reference: "registry.example.com/app:latest"})
+	assert.Error(t, err)
+	_, ok := err.(*PolicyViolation)
+	assert.False(t, ok)
+}
@@ -0,0 +1,79 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package imageverifier verifies the images of task containers before the
+// containers are created from them
+package imageverifier
+
+import (
+	"context"
+
+	"github.com/aws/amazon-ecs-agent/agent/config"
+)
+
+// Image describes the image of a task container to verify
+type Image struct {
+	// TaskARN is the ARN of the task of the container
+	TaskARN string
+	// ContainerName is the name of the container
+	ContainerName string
+	// Name is the image of the container
+	Name string
+	// Digest is the digest of the image, if known
+	Digest string
+	// Reference is the reference to the image by digest, if known, or the
+	// image of the container otherwise
+	Reference string
+}
+
+// Verifier verifies images before containers are created from them
+type Verifier interface {
+	// Verify returns an error explaining why the image can't be used when it
+	// fails verification
+	Verify(ctx context.Context, image Image) error
+}
+
+// NewVerifier returns the image verifiers enabled by the config, or nil if
+// image verification is disabled. The image policy is verified before the
+// verification hook is run
+func NewVerifier(cfg *config.Config, inspector ImageInspector) Verifier {
+	var verifiers chainVerifier
+	if len(cfg.ImagePolicyRequiredLabels) > 0 || len(cfg.ImagePolicyDisallowedDigests) > 0 {
+		verifiers = append(verifiers, NewPolicyVerifier(inspector,
+			cfg.ImagePolicyRequiredLabels, cfg.ImagePolicyDisallowedDigests))
+	}
+	if cfg.ImageVerificationHook != "" {
+		verifiers = append(verifiers, NewExecVerifier(cfg.ImageVerificationHook, cfg.ImageVerificationTimeout))
+	}
+	switch len(verifiers) {
+	case 0:
+		return nil
+	case 1:
+		return verifiers[0]
+	default:
+		return verifiers
+	}
+}
+
+// chainVerifier verifies images with each of its verifiers in turn
+type chainVerifier []Verifier
+
+// Verify returns the error of the first verifier the image fails
+func (verifiers chainVerifier) Verify(ctx context.Context, image Image) error {
+	for _, verifier := range verifiers {
+		if err := verifier.Verify(ctx, image); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+// +build unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package imageverifier
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewVerifier(t *testing.T) {
+	assert.Nil(t, NewVerifier(&config.Config{}, nil))
+	assert.NotNil(t, NewVerifier(&config.Config{ImageVerificationHook: "/usr/bin/verify-image"}, nil))
+	assert.IsType(t, &policyVerifier{}, NewVerifier(&config.Config{
+		ImagePolicyDisallowedDigests: []string{"sha256:abc"},
+	}, nil))
+	assert.IsType(t, chainVerifier{}, NewVerifier(&config.Config{
+		ImageVerificationHook:     "/usr/bin/verify-image",
+		ImagePolicyRequiredLabels: map[string]string{"org.example.team": ""},
+	}, nil))
+}
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "last line", truncate("first line\nlast line"))
+	assert.Len(t, truncate(strings.Repeat("a", 2*maxReasonLength)), maxReasonLength)
+}
@@ -0,0 +1,157 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package localdns
+
+import (
+	"encoding/binary"
+	"net"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	headerLength = 12
+	// maxNameLength is the maximum length of a domain name in its wire format
+	maxNameLength = 255
+	// maxMessageLength is the maximum length of a DNS message over UDP without
+	// EDNS
+	maxMessageLength = 512
+
+	typeA     = 1
+	classINET = 1
+
+	opcodeQuery = 0
+
+	flagResponse           = 1 << 15
+	flagAuthoritative      = 1 << 10
+	flagRecursionDesired   = 1 << 8
+	opcodeShift            = 11
+	opcodeMask             = 0xF
+	rcodeSuccess           = 0
+	rcodeFormatError       = 1
+	rcodeNameError         = 3
+	rcodeNotImplemented    = 4
+	rcodeRefused           = 5
+	compressionPointerMask = 0xC0
+	// questionNamePointer points to the name of the question, which always
+	// directly follows the header
+	questionNamePointer = compressionPointerMask<<8 | headerLength
+)
+
+// query is a DNS query with a single question
+type query struct {
+	id     uint16
+	flags  uint16
+	name   string
+	qtype  uint16
+	qclass uint16
+	// question is the question section in its wire format, which is copied
+	// into the response
+	question []byte
+}
+
+func (q *query) opcode() uint16 {
+	return (q.flags >> opcodeShift) & opcodeMask
+}
+
+// parseQuery parses a DNS query. Queries that are too short to contain a
+// header return an error, as they can't be responded to. Other malformed
+// queries return a query along with the error, so that they can be responded
+// to with a format error
+func parseQuery(msg []byte) (*query, error) {
+	if len(msg) < headerLength {
+		return nil, errors.New("dns message is too short")
+	}
+	q := &query{
+		id:    binary.BigEndian.Uint16(msg[0:2]),
+		flags: binary.BigEndian.Uint16(msg[2:4]),
+	}
+	if q.flags&flagResponse != 0 {
+		return nil, errors.New("dns message is not a query")
+	}
+	if q.opcode() != opcodeQuery {
+		return q, nil
+	}
+	if questions := binary.BigEndian.Uint16(msg[4:6]); questions != 1 {
+		return q, errors.Errorf("dns query has %d questions, expected 1", questions)
+	}
+
+	offset := headerLength
+	var labels []string
+	for {
+		if offset >= len(msg) {
+			return q, errors.New("dns query name is truncated")
+		}
+		length := int(msg[offset])
+		offset++
+		if length == 0 {
+			break
+		}
+		if length&compressionPointerMask != 0 {
+			return q, errors.New("dns query name is compressed")
+		}
+		if offset+length > len(msg) {
+			return q, errors.New("dns query name is truncated")
+		}
+		labels = append(labels, string(msg[offset:offset+length]))
+		offset += length
+		if offset-headerLength > maxNameLength {
+			return q, errors.New("dns query name is too long")
+		}
+	}
+	if offset+4 > len(msg) {
+		return q, errors.New("dns query question is truncated")
+	}
+	q.name = strings.Join(labels, ".")
+	q.qtype = binary.BigEndian.Uint16(msg[offset : offset+2])
+	q.qclass = binary.BigEndian.Uint16(msg[offset+2 : offset+4])
+	q.question = msg[headerLength : offset+4]
+	return q, nil
+}
+
+// buildResponse builds an authoritative response to the query with A records
+// for the ips. The number of records is capped so that the response fits in a
+// single UDP message
+func buildResponse(q *query, rcode uint16, ips []net.IP, ttl uint32) []byte {
+	const answerLength = 16
+	maxAnswers := (maxMessageLength - headerLength - len(q.question)) / answerLength
+	if len(ips) > maxAnswers {
+		ips = ips[:maxAnswers]
+	}
+
+	msg := make([]byte, headerLength, headerLength+len(q.question)+len(ips)*answerLength)
+	binary.BigEndian.PutUint16(msg[0:2], q.id)
+	flags := flagResponse | flagAuthoritative | q.opcode()<<opcodeShift |
+		q.flags&flagRecursionDesired | rcode
+	binary.BigEndian.PutUint16(msg[2:4], flags)
+	if q.question == nil {
+		return msg
+	}
+	binary.BigEndian.PutUint16(msg[4:6], 1)
+	binary.BigEndian.PutUint16(msg[6:8], uint16(len(ips)))
+	msg = append(msg, q.question...)
+
+	for _, ip := range ips {
+		answer := make([]byte, answerLength)
+		binary.BigEndian.PutUint16(answer[0:2], questionNamePointer)
+		binary.BigEndian.PutUint16(answer[2:4], typeA)
+		binary.BigEndian.PutUint16(answer[4:6], classINET)
+		binary.BigEndian.PutUint32(answer[6:10], ttl)
+		binary.BigEndian.PutUint16(answer[10:12], net.IPv4len)
+		copy(answer[12:16], ip.To4())
+		msg = append(msg, answer...)
+	}
+	return msg
+}
@@ -0,0 +1,118 @@
+// +build unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package localdns
+
+import (
+	"encoding/binary"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newQuery returns a DNS query for the name in its wire format
+func newQuery(id uint16, name string, qtype uint16) []byte {
+	msg := make([]byte, headerLength)
+	binary.BigEndian.PutUint16(msg[0:2], id)
+	binary.BigEndian.PutUint16(msg[2:4], flagRecursionDesired)
+	binary.BigEndian.PutUint16(msg[4:6], 1)
+	for _, label := range strings.Split(name, ".") {
+		msg = append(msg, byte(len(label)))
+		msg = append(msg, label...)
+	}
+	msg = append(msg, 0, 0, 0, 0, 0)
+	binary.BigEndian.PutUint16(msg[len(msg)-4:], qtype)
+	binary.BigEndian.PutUint16(msg[len(msg)-2:], classINET)
+	return msg
+}
+
+func TestParseQuery(t *testing.T) {
+	q, err := parseQuery(newQuery(42, "web.myapp.ecs.local", typeA))
+	require.NoError(t, err)
+	assert.Equal(t, uint16(42), q.id)
+	assert.Equal(t, "web.myapp.ecs.local", q.name)
+	assert.Equal(t, uint16(typeA), q.qtype)
+	assert.Equal(t, uint16(classINET), q.qclass)
+}
+
+func TestParseQueryInvalid(t *testing.T) {
+	valid := newQuery(42, "web.myapp.ecs.local", typeA)
+	response := make([]byte, len(valid))
+	copy(response, valid)
+	response[2] |= flagResponse >> 8
+	compressed := append(newQuery(42, "", typeA)[:headerLength], compressionPointerMask, headerLength)
+
+	testCases := []struct {
+		name          string
+		msg           []byte
+		expectedQuery bool
+	}{
+		{"too short", valid[:headerLength-1], false},
+		{"response", response, false},
+		{"truncated name", valid[:headerLength+5], true},
+		{"truncated question", valid[:len(valid)-2], true},
+		{"compressed name", compressed, true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			q, err := parseQuery(tc.msg)
+			assert.Error(t, err)
+			assert.Equal(t, tc.expectedQuery, q != nil)
+		})
+	}
+}
+
+func TestBuildResponse(t *testing.T) {
+	q, err := parseQuery(newQuery(42, "web.myapp.ecs.local", typeA))
+	require.NoError(t, err)
+	ips := []net.IP{net.ParseIP("10.0.0.1"), net.ParseIP("10.0.0.2")}
+
+	msg := buildResponse(q, rcodeSuccess, ips, recordTTL)
+	assert.Equal(t, uint16(42), binary.BigEndian.Uint16(msg[0:2]))
+	flags := binary.BigEndian.Uint16(msg[2:4])
+	assert.NotZero(t, flags&flagResponse)
+	assert.NotZero(t, flags&flagAuthoritative)
+	assert.NotZero(t, flags&flagRecursionDesired)
+	assert.Equal(t, uint16(rcodeSuccess), flags&0xF)
+	assert.Equal(t, uint16(1), binary.BigEndian.Uint16(msg[4:6]))
+	assert.Equal(t, uint16(2), binary.BigEndian.Uint16(msg[6:8]))
+
+	answers := msg[headerLength+len(q.question):]
+	require.Len(t, answers, 32)
+	for i, ip := range ips {
+		answer := answers[i*16 : (i+1)*16]
+		assert.Equal(t, uint16(questionNamePointer), binary.BigEndian.Uint16(answer[0:2]))
+		assert.Equal(t, uint16(typeA), binary.BigEndian.Uint16(answer[2:4]))
+		assert.Equal(t, uint32(recordTTL), binary.BigEndian.Uint32(answer[6:10]))
+		assert.Equal(t, ip.To4(), net.IP(answer[12:16]))
+	}
+}
+
+func TestBuildResponseTruncatesAnswers(t *testing.T) {
+	q, err := parseQuery(newQuery(42, "web.myapp.ecs.local", typeA))
+	require.NoError(t, err)
+	ips := make([]net.IP, 100)
+	for i := range ips {
+		ips[i] = net.IPv4(10, 0, 0, byte(i))
+	}
+
+	msg := buildResponse(q, rcodeSuccess, ips, recordTTL)
+	assert.True(t, len(msg) <= maxMessageLength)
+	answers := int(binary.BigEndian.Uint16(msg[6:8]))
+	assert.Equal(t, headerLength+len(q.question)+answers*16, len(msg))
+}
@@ -0,0 +1,85 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package localdns
+
+import (
+	"bufio"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// dnsPort is the only port resolvers query nameservers on
+const dnsPort = "53"
+
+// resolvConfPath is the resolver config of the instance, whose nameservers are
+// used by the containers that don't set their own
+var resolvConfPath = "/etc/resolv.conf"
+
+// ContainerNameservers returns the nameservers of a container that queries the
+// server listening on listenAddress first. The server refuses the queries for
+// names outside of the ecs.local zone, so that the resolver of the container
+// sends them to the next nameservers: the nameservers of the container, or the
+// ones of the instance if the container doesn't set any
+func ContainerNameservers(listenAddress string, nameservers []string) ([]string, error) {
+	host, port, err := net.SplitHostPort(listenAddress)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid listen address %s", listenAddress)
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsUnspecified() || ip.IsLoopback() || port != dnsPort {
+		return nil, errors.Errorf("listen address %s isn't reachable from containers on port %s",
+			listenAddress, dnsPort)
+	}
+
+	if len(nameservers) == 0 {
+		nameservers, err = instanceNameservers()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return append([]string{ip.String()}, nameservers...), nil
+}
+
+// instanceNameservers returns the nameservers of the instance that containers
+// can reach, which excludes the loopback addresses, as docker does when
+// copying the resolver config of the instance to containers
+func instanceNameservers() ([]string, error) {
+	file, err := os.Open(resolvConfPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read the nameservers of the instance")
+	}
+	defer file.Close()
+
+	var nameservers []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || fields[0] != "nameserver" {
+			continue
+		}
+		if ip := net.ParseIP(fields[1]); ip != nil && !ip.IsLoopback() {
+			nameservers = append(nameservers, fields[1])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "unable to read the nameservers of the instance")
+	}
+	if len(nameservers) == 0 {
+		return nil, errors.Errorf("no nameserver reachable from containers in %s", resolvConfPath)
+	}
+	return nameservers, nil
+}
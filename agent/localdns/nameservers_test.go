@@ -0,0 +1,71 @@
+// +build unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package localdns
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setResolvConf(t *testing.T, content string) func() {
+	file, err := ioutil.TempFile("", "resolv.conf")
+	require.NoError(t, err)
+	_, err = file.WriteString(content)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	original := resolvConfPath
+	resolvConfPath = file.Name()
+	return func() {
+		resolvConfPath = original
+		os.Remove(file.Name())
+	}
+}
+
+func TestContainerNameservers(t *testing.T) {
+	defer setResolvConf(t, "# comment\nsearch ec2.internal\nnameserver 127.0.0.53\nnameserver 10.0.0.2\nnameserver 10.0.0.3\n")()
+
+	nameservers, err := ContainerNameservers("172.17.0.1:53", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"172.17.0.1", "10.0.0.2", "10.0.0.3"}, nameservers,
+		"the loopback nameservers of the instance should be skipped")
+
+	nameservers, err = ContainerNameservers("172.17.0.1:53", []string{"8.8.8.8"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"172.17.0.1", "8.8.8.8"}, nameservers)
+}
+
+func TestContainerNameserversUnreachableListenAddress(t *testing.T) {
+	defer setResolvConf(t, "nameserver 10.0.0.2\n")()
+
+	for _, listenAddress := range []string{"127.0.0.1:53", "0.0.0.0:53", ":53", "172.17.0.1:5353", "172.17.0.1"} {
+		t.Run(listenAddress, func(t *testing.T) {
+			_, err := ContainerNameservers(listenAddress, nil)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestContainerNameserversWithoutInstanceNameservers(t *testing.T) {
+	defer setResolvConf(t, "nameserver 127.0.0.53\n")()
+
+	_, err := ContainerNameservers("172.17.0.1:53", nil)
+	assert.Error(t, err)
+}
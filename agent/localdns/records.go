@@ -0,0 +1,91 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package localdns
+
+import (
+	"net"
+	"strings"
+
+	apicontainerstatus "github.com/aws/amazon-ecs-agent/agent/api/container/status"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	apitaskstatus "github.com/aws/amazon-ecs-agent/agent/api/task/status"
+	"github.com/aws/amazon-ecs-agent/agent/engine/dockerstate"
+	"github.com/docker/docker/api/types"
+)
+
+// Zone is the DNS zone that names of task containers are published in. A
+// container is resolvable as <container>.<task-family>.ecs.local
+const Zone = "ecs.local"
+
+// inZone returns true if the name is in the ecs.local zone
+func inZone(name string) bool {
+	name = strings.ToLower(name)
+	return name == Zone || strings.HasSuffix(name, "."+Zone)
+}
+
+// lookup returns the IPv4 addresses of the running containers that the name
+// resolves to. The name resolves to the container of every running task of the
+// family, so that the tasks of a family can be discovered together
+func lookup(state dockerstate.TaskEngineState, name string) []net.IP {
+	labels := strings.Split(strings.TrimSuffix(strings.ToLower(name), "."+Zone), ".")
+	if !inZone(name) || len(labels) != 2 {
+		return nil
+	}
+	containerName, family := labels[0], labels[1]
+
+	var ips []net.IP
+	for _, task := range state.AllTasks() {
+		if strings.ToLower(task.Family) != family ||
+			task.GetKnownStatus() >= apitaskstatus.TaskStopped ||
+			task.GetDesiredStatus() >= apitaskstatus.TaskStopped {
+			continue
+		}
+		for _, container := range task.Containers {
+			if strings.ToLower(container.Name) != containerName ||
+				container.GetKnownStatus() != apicontainerstatus.ContainerRunning {
+				continue
+			}
+			ips = append(ips, containerIPs(task, container.GetNetworkSettings())...)
+		}
+	}
+	return ips
+}
+
+// containerIPs returns the IPv4 addresses of a container of a task. Containers
+// of awsvpc tasks share the addresses of the task ENI, while other containers
+// use the addresses of their docker networks. Containers in host network mode
+// don't have addresses of their own
+func containerIPs(task *apitask.Task, settings *types.NetworkSettings) []net.IP {
+	var addresses []string
+	if eni := task.GetPrimaryENI(); eni != nil {
+		addresses = eni.GetIPV4Addresses()
+	} else if settings != nil {
+		if settings.IPAddress != "" {
+			addresses = append(addresses, settings.IPAddress)
+		}
+		for _, network := range settings.Networks {
+			if network != nil && network.IPAddress != "" && network.IPAddress != settings.IPAddress {
+				addresses = append(addresses, network.IPAddress)
+			}
+		}
+	}
+
+	var ips []net.IP
+	for _, address := range addresses {
+		if ip := net.ParseIP(address).To4(); ip != nil {
+			ips = append(ips, ip)
+		}
+	}
+	return ips
+}
@@ -0,0 +1,123 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package localdns
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/engine/dockerstate"
+	"github.com/aws/amazon-ecs-agent/agent/utils/retry"
+	"github.com/cihub/seelog"
+)
+
+const (
+	// recordTTL is the TTL of the records in responses, in seconds. It's kept
+	// short as the addresses change with the tasks running on the instance
+	recordTTL = 5
+
+	listenRetryMinDelay   = time.Second
+	listenRetryMaxDelay   = time.Minute
+	listenRetryJitter     = 0.2
+	listenRetryMultiplier = 2
+)
+
+// Server is a DNS server that answers queries for the names of task containers
+// in the ecs.local zone with their current addresses. Queries for names outside
+// the zone are refused, as the server doesn't do recursive resolution
+type Server struct {
+	address string
+	state   dockerstate.TaskEngineState
+}
+
+// NewServer creates a new Server that listens on the UDP address
+func NewServer(address string, state dockerstate.TaskEngineState) *Server {
+	return &Server{
+		address: address,
+		state:   state,
+	}
+}
+
+// Serve listens for and answers queries until the context is cancelled
+func (server *Server) Serve(ctx context.Context) {
+	backoff := retry.NewExponentialBackoff(listenRetryMinDelay, listenRetryMaxDelay,
+		listenRetryJitter, listenRetryMultiplier)
+	retry.RetryWithBackoffCtx(ctx, backoff, func() error {
+		conn, err := net.ListenPacket("udp", server.address)
+		if err != nil {
+			seelog.Errorf("Local DNS: unable to listen on %s: %v", server.address, err)
+			return err
+		}
+		seelog.Infof("Local DNS: answering queries for %s on %s", Zone, server.address)
+		server.serve(ctx, conn)
+		return nil
+	})
+}
+
+// serve answers queries received on the connection until the context is
+// cancelled
+func (server *Server) serve(ctx context.Context, conn net.PacketConn) {
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	buf := make([]byte, maxMessageLength)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			seelog.Warnf("Local DNS: unable to read query: %v", err)
+			continue
+		}
+		response := server.handle(buf[:n])
+		if response == nil {
+			continue
+		}
+		if _, err := conn.WriteTo(response, addr); err != nil {
+			seelog.Warnf("Local DNS: unable to write response to %s: %v", addr, err)
+		}
+	}
+}
+
+// handle returns the response to a query, or nil if it can't be responded to
+func (server *Server) handle(msg []byte) []byte {
+	q, err := parseQuery(msg)
+	if err != nil {
+		seelog.Debugf("Local DNS: invalid query: %v", err)
+		if q == nil {
+			return nil
+		}
+		return buildResponse(q, rcodeFormatError, nil, 0)
+	}
+	if q.opcode() != opcodeQuery {
+		return buildResponse(q, rcodeNotImplemented, nil, 0)
+	}
+	if q.qclass != classINET || !inZone(q.name) {
+		return buildResponse(q, rcodeRefused, nil, 0)
+	}
+
+	ips := lookup(server.state, q.name)
+	if len(ips) == 0 {
+		return buildResponse(q, rcodeNameError, nil, 0)
+	}
+	if q.qtype != typeA {
+		// The name exists, but only has A records
+		return buildResponse(q, rcodeSuccess, nil, 0)
+	}
+	return buildResponse(q, rcodeSuccess, ips, recordTTL)
+}
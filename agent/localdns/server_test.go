@@ -0,0 +1,170 @@
+// +build unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package localdns
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apicontainerstatus "github.com/aws/amazon-ecs-agent/agent/api/container/status"
+	apieni "github.com/aws/amazon-ecs-agent/agent/api/eni"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	apitaskstatus "github.com/aws/amazon-ecs-agent/agent/api/task/status"
+	"github.com/aws/amazon-ecs-agent/agent/engine/dockerstate"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/network"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTask(arn, family string, status apitaskstatus.TaskStatus, containers ...*apicontainer.Container) *apitask.Task {
+	task := &apitask.Task{
+		Arn:        arn,
+		Family:     family,
+		Containers: containers,
+	}
+	task.SetKnownStatus(status)
+	task.SetDesiredStatus(apitaskstatus.TaskRunning)
+	return task
+}
+
+func newContainer(name string, status apicontainerstatus.ContainerStatus, settings *types.NetworkSettings) *apicontainer.Container {
+	container := &apicontainer.Container{Name: name}
+	container.SetKnownStatus(status)
+	container.SetNetworkSettings(settings)
+	return container
+}
+
+func bridgeSettings(ip string) *types.NetworkSettings {
+	return &types.NetworkSettings{
+		Networks: map[string]*network.EndpointSettings{
+			"bridge": {IPAddress: ip},
+		},
+	}
+}
+
+func newTestState() dockerstate.TaskEngineState {
+	state := dockerstate.NewTaskEngineState()
+	state.AddTask(newTask("task1", "myapp", apitaskstatus.TaskRunning,
+		newContainer("web", apicontainerstatus.ContainerRunning, bridgeSettings("172.17.0.2")),
+		newContainer("sidecar", apicontainerstatus.ContainerRunning, &types.NetworkSettings{
+			DefaultNetworkSettings: types.DefaultNetworkSettings{IPAddress: "172.17.0.3"},
+		})))
+	state.AddTask(newTask("task2", "myapp", apitaskstatus.TaskRunning,
+		newContainer("web", apicontainerstatus.ContainerRunning, bridgeSettings("172.17.0.4"))))
+	state.AddTask(newTask("task3", "myapp", apitaskstatus.TaskStopped,
+		newContainer("web", apicontainerstatus.ContainerStopped, bridgeSettings("172.17.0.5"))))
+	state.AddTask(newTask("task4", "myapp", apitaskstatus.TaskCreated,
+		newContainer("web", apicontainerstatus.ContainerCreated, bridgeSettings("172.17.0.6"))))
+	state.AddTask(newTask("task5", "host", apitaskstatus.TaskRunning,
+		newContainer("web", apicontainerstatus.ContainerRunning, &types.NetworkSettings{})))
+
+	awsvpcTask := newTask("task6", "vpcapp", apitaskstatus.TaskRunning,
+		newContainer("~internal~ecs~pause", apicontainerstatus.ContainerRunning, nil),
+		newContainer("web", apicontainerstatus.ContainerRunning, nil))
+	awsvpcTask.AddTaskENI(&apieni.ENI{
+		IPV4Addresses: []*apieni.ENIIPV4Address{{Primary: true, Address: "10.0.0.10"}},
+	})
+	state.AddTask(awsvpcTask)
+	return state
+}
+
+func TestLookup(t *testing.T) {
+	state := newTestState()
+	testCases := []struct {
+		name        string
+		expectedIPs []string
+	}{
+		{"web.myapp.ecs.local", []string{"172.17.0.2", "172.17.0.4"}},
+		{"WEB.MyApp.ECS.local", []string{"172.17.0.2", "172.17.0.4"}},
+		{"sidecar.myapp.ecs.local", []string{"172.17.0.3"}},
+		{"web.vpcapp.ecs.local", []string{"10.0.0.10"}},
+		{"web.host.ecs.local", nil},
+		{"db.myapp.ecs.local", nil},
+		{"myapp.ecs.local", nil},
+		{"a.web.myapp.ecs.local", nil},
+		{"web.myapp.example.com", nil},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var ips []string
+			for _, ip := range lookup(state, tc.name) {
+				ips = append(ips, ip.String())
+			}
+			assert.ElementsMatch(t, tc.expectedIPs, ips)
+		})
+	}
+}
+
+func TestHandle(t *testing.T) {
+	server := NewServer("127.0.0.1:0", newTestState())
+	testCases := []struct {
+		name            string
+		qtype           uint16
+		expectedRcode   uint16
+		expectedAnswers uint16
+	}{
+		{"web.myapp.ecs.local", typeA, rcodeSuccess, 2},
+		{"web.myapp.ecs.local", 28, rcodeSuccess, 0},
+		{"db.myapp.ecs.local", typeA, rcodeNameError, 0},
+		{"www.example.com", typeA, rcodeRefused, 0},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			response := server.handle(newQuery(42, tc.name, tc.qtype))
+			require.True(t, len(response) >= headerLength)
+			assert.Equal(t, tc.expectedRcode, binary.BigEndian.Uint16(response[2:4])&0xF)
+			assert.Equal(t, tc.expectedAnswers, binary.BigEndian.Uint16(response[6:8]))
+		})
+	}
+}
+
+func TestHandleNotImplementedOpcode(t *testing.T) {
+	server := NewServer("127.0.0.1:0", newTestState())
+	msg := newQuery(42, "web.myapp.ecs.local", typeA)
+	msg[2] |= 2 << (opcodeShift - 8)
+
+	response := server.handle(msg)
+	require.Len(t, response, headerLength)
+	assert.Equal(t, uint16(rcodeNotImplemented), binary.BigEndian.Uint16(response[2:4])&0xF)
+}
+
+func TestServe(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	server := NewServer(conn.LocalAddr().String(), newTestState())
+	go server.serve(ctx, conn)
+
+	client, err := net.Dial("udp", conn.LocalAddr().String())
+	require.NoError(t, err)
+	defer client.Close()
+	_, err = client.Write(newQuery(42, "sidecar.myapp.ecs.local", typeA))
+	require.NoError(t, err)
+
+	client.SetReadDeadline(time.Now().Add(5 * time.Second))
+	response := make([]byte, maxMessageLength)
+	n, err := client.Read(response)
+	require.NoError(t, err)
+	response = response[:n]
+	assert.Equal(t, uint16(1), binary.BigEndian.Uint16(response[6:8]))
+	assert.Equal(t, net.ParseIP("172.17.0.3").To4(), net.IP(response[n-4:]))
+}
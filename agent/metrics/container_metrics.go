@@ -0,0 +1,51 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ContainerSubsystem is the subsystem of the metrics about the containers of
+// tasks
+const ContainerSubsystem = "Container"
+
+// ContainerMetrics counts the events of the containers of tasks that users
+// should know about, such as containers killed for running out of memory.
+// Metrics are labeled with the task definition family and the name of the
+// container, so that they're comparable across the tasks of a service.
+type ContainerMetrics struct {
+	oomKills *prometheus.CounterVec
+}
+
+// NewContainerMetrics creates the container metrics and registers them with
+// the registry
+func NewContainerMetrics(registry *prometheus.Registry) *ContainerMetrics {
+	oomKills := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: AgentNamespace,
+		Subsystem: ContainerSubsystem,
+		Name:      "oom_kills_total",
+		Help:      "Number of containers killed by the kernel OOM killer",
+	}, []string{"TaskDefinitionFamily", "ContainerName"})
+	registry.MustRegister(oomKills)
+
+	return &ContainerMetrics{
+		oomKills: oomKills,
+	}
+}
+
+// IncrementOOMKills counts a container killed by the kernel OOM killer
+func (cm *ContainerMetrics) IncrementOOMKills(taskDefinitionFamily, containerName string) {
+	cm.oomKills.WithLabelValues(taskDefinitionFamily, containerName).Inc()
+}
@@ -0,0 +1,126 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// ACSService, TCSService, ECRService and IMDSService are the services for
+	// which connection latencies are recorded
+	ACSService  = "ACS"
+	TCSService  = "TCS"
+	ECRService  = "ECR"
+	IMDSService = "IMDS"
+
+	ConnectionSubsystem = "Connection"
+)
+
+// LatencyMetrics records how long it takes to establish connections to, and
+// to get responses from, the endpoints of the services the Agent talks to.
+// Metrics are labeled with the service, the endpoint and the availability zone
+// of the instance so that degradations can be narrowed down to a region, an
+// AZ or a single endpoint. Whether the Agent is connected to the websocket
+// services (ACS and TCS) is recorded as well.
+type LatencyMetrics struct {
+	connectVec       *prometheus.SummaryVec
+	roundTripVec     *prometheus.SummaryVec
+	lastConnect      *prometheus.GaugeVec
+	connected        *prometheus.GaugeVec
+	lock             sync.RWMutex
+	availabilityZone string
+}
+
+// NewLatencyMetrics creates the latency metrics and registers them with the
+// registry
+func NewLatencyMetrics(registry *prometheus.Registry) *LatencyMetrics {
+	labels := []string{"Service", "Endpoint", "AvailabilityZone"}
+	connectVec := prometheus.NewSummaryVec(prometheus.SummaryOpts{
+		Namespace:  AgentNamespace,
+		Subsystem:  ConnectionSubsystem,
+		Name:       "connect_duration_seconds",
+		Help:       "Time taken to establish a connection to an endpoint in seconds",
+		Objectives: map[float64]float64{0.5: 0.05, 0.9: 0.01, 0.99: 0.001},
+	}, labels)
+	registry.MustRegister(connectVec)
+
+	roundTripVec := prometheus.NewSummaryVec(prometheus.SummaryOpts{
+		Namespace:  AgentNamespace,
+		Subsystem:  ConnectionSubsystem,
+		Name:       "round_trip_duration_seconds",
+		Help:       "Round trip time to an endpoint over an established connection in seconds",
+		Objectives: map[float64]float64{0.5: 0.05, 0.9: 0.01, 0.99: 0.001},
+	}, labels)
+	registry.MustRegister(roundTripVec)
+
+	lastConnect := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: AgentNamespace,
+		Subsystem: ConnectionSubsystem,
+		Name:      "last_connect_duration",
+		Help:      "Time taken to establish the most recent connection to an endpoint in seconds",
+	}, labels)
+	registry.MustRegister(lastConnect)
+
+	connected := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: AgentNamespace,
+		Subsystem: ConnectionSubsystem,
+		Name:      "connected",
+		Help:      "Whether the Agent is connected to the service, 1 if it is and 0 otherwise",
+	}, []string{"Service"})
+	registry.MustRegister(connected)
+
+	return &LatencyMetrics{
+		connectVec:   connectVec,
+		roundTripVec: roundTripVec,
+		lastConnect:  lastConnect,
+		connected:    connected,
+	}
+}
+
+// SetAvailabilityZone sets the availability zone label of metrics recorded
+// from now on
+func (lm *LatencyMetrics) SetAvailabilityZone(availabilityZone string) {
+	lm.lock.Lock()
+	defer lm.lock.Unlock()
+	lm.availabilityZone = availabilityZone
+}
+
+// ObserveConnect records the time taken to establish a connection to the
+// endpoint of the service
+func (lm *LatencyMetrics) ObserveConnect(service, endpoint string, duration time.Duration) {
+	lm.lock.RLock()
+	defer lm.lock.RUnlock()
+	lm.connectVec.WithLabelValues(service, endpoint, lm.availabilityZone).Observe(duration.Seconds())
+	lm.lastConnect.WithLabelValues(service, endpoint, lm.availabilityZone).Set(duration.Seconds())
+}
+
+// ObserveRoundTrip records a round trip time to the endpoint of the service
+func (lm *LatencyMetrics) ObserveRoundTrip(service, endpoint string, duration time.Duration) {
+	lm.lock.RLock()
+	defer lm.lock.RUnlock()
+	lm.roundTripVec.WithLabelValues(service, endpoint, lm.availabilityZone).Observe(duration.Seconds())
+}
+
+// SetConnected records whether the Agent is connected to the service
+func (lm *LatencyMetrics) SetConnected(service string, connected bool) {
+	value := 0.0
+	if connected {
+		value = 1
+	}
+	lm.connected.WithLabelValues(service).Set(value)
+}
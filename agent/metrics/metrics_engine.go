@@ -32,6 +32,9 @@ type MetricsEngine struct {
 	ctx            context.Context
 	Registry       *prometheus.Registry
 	managedMetrics map[APIType]MetricsClient
+	latency        *LatencyMetrics
+	containers     *ContainerMetrics
+	state          *StateMetrics
 }
 
 const (
@@ -39,16 +42,18 @@ const (
 	TaskEngine
 	StateManager
 	ECSClient
+	ContainerMetadata
 )
 
 // Maintained list of APIs for which we collect metrics. MetricsClients will be
 // initialized using Factory method when a MetricsEngine is created.
 var (
 	managedAPIs = map[APIType]string{
-		DockerAPI:    "Docker_API",
-		TaskEngine:   "Task_Engine",
-		StateManager: "State_Manager",
-		ECSClient:    "ECS_Client",
+		DockerAPI:         "Docker_API",
+		TaskEngine:        "Task_Engine",
+		StateManager:      "State_Manager",
+		ECSClient:         "ECS_Client",
+		ContainerMetadata: "Container_Metadata",
 	}
 	MetricsEngineGlobal *MetricsEngine = &MetricsEngine{
 		collection: false,
@@ -88,6 +93,9 @@ func NewMetricsEngine(cfg *config.Config, registry *prometheus.Registry) *Metric
 		cfg:            cfg,
 		Registry:       registry,
 		managedMetrics: make(map[APIType]MetricsClient),
+		latency:        NewLatencyMetrics(registry),
+		containers:     NewContainerMetrics(registry),
+		state:          NewStateMetrics(registry),
 	}
 	for managedAPI, _ := range managedAPIs {
 		aClient := NewMetricsClient(managedAPI, metricsEngine.Registry)
@@ -116,6 +124,73 @@ func (engine *MetricsEngine) RecordECSClientMetric(callName string) func() {
 	return engine.recordGenericMetric(ECSClient, callName)
 }
 
+// Wrapper function that allows APIs to call a single function
+func (engine *MetricsEngine) RecordContainerMetadataMetric(callName string) func() {
+	return engine.recordGenericMetric(ContainerMetadata, callName)
+}
+
+// SetAvailabilityZone sets the availability zone with which connection
+// latencies are labeled
+func (engine *MetricsEngine) SetAvailabilityZone(availabilityZone string) {
+	if engine == nil || !engine.collection {
+		return
+	}
+	engine.latency.SetAvailabilityZone(availabilityZone)
+}
+
+// RecordConnectLatency records the time taken to establish a connection to an
+// endpoint of the service (ACS, TCS, ECR or IMDS)
+func (engine *MetricsEngine) RecordConnectLatency(service, endpoint string, duration time.Duration) {
+	if engine == nil || !engine.collection {
+		return
+	}
+	engine.latency.ObserveConnect(service, endpoint, duration)
+}
+
+// RecordRoundTripLatency records a round trip time to an endpoint of the
+// service (ACS, TCS, ECR or IMDS)
+func (engine *MetricsEngine) RecordRoundTripLatency(service, endpoint string, duration time.Duration) {
+	if engine == nil || !engine.collection {
+		return
+	}
+	engine.latency.ObserveRoundTrip(service, endpoint, duration)
+}
+
+// RecordConnectionState records whether the Agent is connected to the service
+// (ACS or TCS)
+func (engine *MetricsEngine) RecordConnectionState(service string, connected bool) {
+	if engine == nil || !engine.collection {
+		return
+	}
+	engine.latency.SetConnected(service, connected)
+}
+
+// SetStateCounter sets the function that counts the tasks, containers and
+// images of the Agent when the metrics are scraped
+func (engine *MetricsEngine) SetStateCounter(counter func() StateCounts) {
+	if engine == nil || !engine.collection {
+		return
+	}
+	engine.state.SetCounter(counter)
+}
+
+// RecordImageRemoval counts an image removed by the image cleanup
+func (engine *MetricsEngine) RecordImageRemoval() {
+	if engine == nil || !engine.collection {
+		return
+	}
+	engine.state.IncrementImagesRemoved()
+}
+
+// RecordContainerOOMKill counts a container of a task killed by the kernel OOM
+// killer
+func (engine *MetricsEngine) RecordContainerOOMKill(taskDefinitionFamily, containerName string) {
+	if engine == nil || !engine.collection {
+		return
+	}
+	engine.containers.IncrementOOMKills(taskDefinitionFamily, containerName)
+}
+
 // Records a call's start and returns a function to be deferred.
 // Wrapper functions will use this function for GenericMetricsClients.
 // If Metrics collection is enabled from the cfg, we record a metric with callID
@@ -21,11 +21,12 @@ import (
 )
 
 const (
-	AgentNamespace        = "AgentMetrics"
-	DockerSubsystem       = "DockerAPI"
-	TaskEngineSubsystem   = "TaskEngine"
-	StateManagerSubsystem = "StateManager"
-	ECSClientSubsystem    = "ECSClient"
+	AgentNamespace             = "AgentMetrics"
+	DockerSubsystem            = "DockerAPI"
+	TaskEngineSubsystem        = "TaskEngine"
+	StateManagerSubsystem      = "StateManager"
+	ECSClientSubsystem         = "ECSClient"
+	ContainerMetadataSubsystem = "ContainerMetadata"
 )
 
 // A factory method that enables various MetricsClients to be created.
@@ -39,6 +40,8 @@ func NewMetricsClient(api APIType, registry *prometheus.Registry) MetricsClient
 		return NewGenericMetricsClient(StateManagerSubsystem, registry)
 	case ECSClient:
 		return NewGenericMetricsClient(ECSClientSubsystem, registry)
+	case ContainerMetadata:
+		return NewGenericMetricsClient(ContainerMetadataSubsystem, registry)
 	default:
 		seelog.Error("Unmanaged MetricsClient cannot be created.")
 		return nil
@@ -158,6 +158,94 @@ func TestMetricCollection(t *testing.T) {
 	assert.True(t, verifyStats(metricFamilies, expected), "Metrics are not accurate")
 }
 
+// Tests that connection latencies are recorded per service, endpoint and
+// availability zone
+func TestLatencyMetricCollection(t *testing.T) {
+	defer func() {
+		MetricsEngineGlobal = &MetricsEngine{
+			collection: false,
+		}
+	}()
+	cfg := getTestConfig()
+	MustInit(&cfg, prometheus.NewRegistry())
+
+	MetricsEngineGlobal.SetAvailabilityZone("us-west-2a")
+	MetricsEngineGlobal.RecordConnectLatency(ACSService, "ecs-a-1.us-west-2.amazonaws.com", 100*time.Millisecond)
+	MetricsEngineGlobal.RecordConnectLatency(ACSService, "ecs-a-1.us-west-2.amazonaws.com", 300*time.Millisecond)
+	MetricsEngineGlobal.RecordRoundTripLatency(ECRService, "api.ecr.us-west-2.amazonaws.com", 50*time.Millisecond)
+
+	metricFamilies, err := MetricsEngineGlobal.Registry.Gather()
+	assert.NoError(t, err)
+
+	found := make(map[string]*dto.Metric)
+	for _, metricFamily := range metricFamilies {
+		for _, metric := range metricFamily.GetMetric() {
+			labels := make(map[string]string)
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			assert.Equal(t, "us-west-2a", labels["AvailabilityZone"])
+			found[metricFamily.GetName()+"/"+labels["Service"]+"/"+labels["Endpoint"]] = metric
+		}
+	}
+	connect, ok := found["AgentMetrics_Connection_connect_duration_seconds/ACS/ecs-a-1.us-west-2.amazonaws.com"]
+	assert.True(t, ok)
+	assert.Equal(t, uint64(2), connect.GetSummary().GetSampleCount())
+	assert.InDelta(t, 0.4, connect.GetSummary().GetSampleSum(), 0.001)
+	lastConnect, ok := found["AgentMetrics_Connection_last_connect_duration/ACS/ecs-a-1.us-west-2.amazonaws.com"]
+	assert.True(t, ok)
+	assert.InDelta(t, 0.3, lastConnect.GetGauge().GetValue(), 0.001)
+	roundTrip, ok := found["AgentMetrics_Connection_round_trip_duration_seconds/ECR/api.ecr.us-west-2.amazonaws.com"]
+	assert.True(t, ok)
+	assert.Equal(t, uint64(1), roundTrip.GetSummary().GetSampleCount())
+}
+
+// Tests that recording latencies is a no-op when metrics are disabled
+func TestLatencyMetricsDisabled(t *testing.T) {
+	engine := &MetricsEngine{collection: false}
+	engine.SetAvailabilityZone("us-west-2a")
+	engine.RecordConnectLatency(TCSService, "ecs-t-1.us-west-2.amazonaws.com", time.Second)
+	engine.RecordRoundTripLatency(IMDSService, "169.254.169.254", time.Second)
+}
+
+func TestContainerOOMKillCollection(t *testing.T) {
+	defer func() {
+		MetricsEngineGlobal = &MetricsEngine{
+			collection: false,
+		}
+	}()
+	cfg := getTestConfig()
+	MustInit(&cfg, prometheus.NewRegistry())
+
+	MetricsEngineGlobal.RecordContainerOOMKill("web", "app")
+	MetricsEngineGlobal.RecordContainerOOMKill("web", "app")
+	MetricsEngineGlobal.RecordContainerOOMKill("web", "sidecar")
+
+	metricFamilies, err := MetricsEngineGlobal.Registry.Gather()
+	assert.NoError(t, err)
+
+	oomKills := make(map[string]float64)
+	for _, metricFamily := range metricFamilies {
+		if metricFamily.GetName() != "AgentMetrics_Container_oom_kills_total" {
+			continue
+		}
+		for _, metric := range metricFamily.GetMetric() {
+			labels := make(map[string]string)
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			oomKills[labels["TaskDefinitionFamily"]+"/"+labels["ContainerName"]] = metric.GetCounter().GetValue()
+		}
+	}
+	assert.Equal(t, map[string]float64{"web/app": 2, "web/sidecar": 1}, oomKills)
+}
+
+// Tests that counting OOM kills is a no-op when metrics are disabled
+func TestContainerOOMKillDisabled(t *testing.T) {
+	engine := &MetricsEngine{collection: false}
+	engine.RecordContainerOOMKill("web", "app")
+}
+
 // A type for storing a Tree-based map. We map the MetricName to a map of metrics
 // under that name. This second map indexes by MetricLabelName+MetricLabelValue to
 // a slice MetricType and MetricValue.
@@ -0,0 +1,133 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metrics
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// TaskSubsystem is the subsystem of the metrics about the tasks the
+	// Agent manages
+	TaskSubsystem = "Task"
+	// ImageSubsystem is the subsystem of the metrics about the images the
+	// image manager tracks
+	ImageSubsystem = "Image"
+)
+
+// StateCounts is a snapshot of the state of the Agent
+type StateCounts struct {
+	// Tasks is the number of tasks by known status
+	Tasks map[string]int
+	// Containers is the number of containers of tasks by known status
+	Containers map[string]int
+	// Images is the number of images the image manager tracks
+	Images int
+	// ImagesSizeBytes is the size of the images the image manager tracks
+	ImagesSizeBytes int64
+	// QuarantinedImages is the number of images the image cleanup gave up
+	// removing for a while
+	QuarantinedImages int
+}
+
+// StateMetrics exposes the number of tasks and containers by status and the
+// images the Agent tracks. The counts are taken from the state of the Agent
+// when the metrics are scraped, rather than updated on every state change,
+// so that they can't drift from the state. Nothing is exposed until the
+// function counting the state is set
+type StateMetrics struct {
+	lock    sync.RWMutex
+	counter func() StateCounts
+
+	tasks             *prometheus.Desc
+	containers        *prometheus.Desc
+	images            *prometheus.Desc
+	imagesSizeBytes   *prometheus.Desc
+	quarantinedImages *prometheus.Desc
+	imagesRemoved     *prometheus.Desc
+	// imagesRemovedCount is the number of images removed by the image
+	// cleanup, accessed atomically
+	imagesRemovedCount uint64
+}
+
+// NewStateMetrics creates the state metrics and registers them with the
+// registry
+func NewStateMetrics(registry *prometheus.Registry) *StateMetrics {
+	sm := &StateMetrics{
+		tasks: prometheus.NewDesc(prometheus.BuildFQName(AgentNamespace, TaskSubsystem, "count"),
+			"Number of tasks by known status", []string{"KnownStatus"}, nil),
+		containers: prometheus.NewDesc(prometheus.BuildFQName(AgentNamespace, ContainerSubsystem, "count"),
+			"Number of containers of tasks by known status", []string{"KnownStatus"}, nil),
+		images: prometheus.NewDesc(prometheus.BuildFQName(AgentNamespace, ImageSubsystem, "count"),
+			"Number of images tracked by the image manager", nil, nil),
+		imagesSizeBytes: prometheus.NewDesc(prometheus.BuildFQName(AgentNamespace, ImageSubsystem, "size_bytes"),
+			"Size of the images tracked by the image manager in bytes", nil, nil),
+		quarantinedImages: prometheus.NewDesc(prometheus.BuildFQName(AgentNamespace, ImageSubsystem, "quarantined_count"),
+			"Number of images the image cleanup stopped trying to remove for a while", nil, nil),
+		imagesRemoved: prometheus.NewDesc(prometheus.BuildFQName(AgentNamespace, ImageSubsystem, "removed_total"),
+			"Number of images removed by the image cleanup", nil, nil),
+	}
+	registry.MustRegister(sm)
+	return sm
+}
+
+// SetCounter sets the function that takes the snapshot of the state of the
+// Agent when the metrics are scraped
+func (sm *StateMetrics) SetCounter(counter func() StateCounts) {
+	sm.lock.Lock()
+	defer sm.lock.Unlock()
+	sm.counter = counter
+}
+
+// IncrementImagesRemoved counts an image removed by the image cleanup
+func (sm *StateMetrics) IncrementImagesRemoved() {
+	atomic.AddUint64(&sm.imagesRemovedCount, 1)
+}
+
+// Describe implements prometheus.Collector
+func (sm *StateMetrics) Describe(ch chan<- *prometheus.Desc) {
+	ch <- sm.tasks
+	ch <- sm.containers
+	ch <- sm.images
+	ch <- sm.imagesSizeBytes
+	ch <- sm.quarantinedImages
+	ch <- sm.imagesRemoved
+}
+
+// Collect implements prometheus.Collector
+func (sm *StateMetrics) Collect(ch chan<- prometheus.Metric) {
+	sm.lock.RLock()
+	counter := sm.counter
+	sm.lock.RUnlock()
+	if counter == nil {
+		return
+	}
+
+	counts := counter()
+	for status, count := range counts.Tasks {
+		ch <- prometheus.MustNewConstMetric(sm.tasks, prometheus.GaugeValue, float64(count), status)
+	}
+	for status, count := range counts.Containers {
+		ch <- prometheus.MustNewConstMetric(sm.containers, prometheus.GaugeValue, float64(count), status)
+	}
+	ch <- prometheus.MustNewConstMetric(sm.images, prometheus.GaugeValue, float64(counts.Images))
+	ch <- prometheus.MustNewConstMetric(sm.imagesSizeBytes, prometheus.GaugeValue, float64(counts.ImagesSizeBytes))
+	ch <- prometheus.MustNewConstMetric(sm.quarantinedImages, prometheus.GaugeValue,
+		float64(counts.QuarantinedImages))
+	ch <- prometheus.MustNewConstMetric(sm.imagesRemoved, prometheus.CounterValue,
+		float64(atomic.LoadUint64(&sm.imagesRemovedCount)))
+}
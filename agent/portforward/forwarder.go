@@ -0,0 +1,335 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package portforward publishes ports of awsvpc tasks on the primary IP
+// address of the instance, with iptables DNAT rules managed by the agent. It's
+// meant for clusters whose load balancers can only target instance IPs
+package portforward
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cihub/seelog"
+	"github.com/pkg/errors"
+)
+
+const (
+	iptablesCommand = "iptables"
+	// iptablesTimeout bounds every iptables command, which waits for the
+	// xtables lock held by other users of iptables such as docker
+	iptablesTimeout = 30 * time.Second
+
+	tableNAT    = "nat"
+	tableFilter = "filter"
+
+	opAppend = "-A"
+	opCheck  = "-C"
+	opDelete = "-D"
+	opList   = "-S"
+
+	// commentPrefix starts the comment of the rules added by the agent, which
+	// is followed by the owner of the rule
+	commentPrefix = "ecs-agent "
+)
+
+// ruleChains are the chains the rules are added to
+var ruleChains = []struct{ table, chain string }{
+	{tableNAT, "PREROUTING"},
+	{tableNAT, "OUTPUT"},
+	{tableNAT, "POSTROUTING"},
+	{tableFilter, "FORWARD"},
+}
+
+// Mapping is a port published on the instance IP, which is forwarded to the
+// same port of the task IP
+type Mapping struct {
+	// Protocol is either "tcp" or "udp"
+	Protocol string
+	Port     uint16
+}
+
+func (mapping Mapping) String() string {
+	return strconv.Itoa(int(mapping.Port)) + "/" + mapping.Protocol
+}
+
+// PortConflictError is returned when a port is already published for another
+// owner
+type PortConflictError struct {
+	Mapping Mapping
+	Owner   string
+}
+
+func (err *PortConflictError) Error() string {
+	return fmt.Sprintf("port forwarder: port %s is already published for %s", err.Mapping, err.Owner)
+}
+
+// Forwarder publishes ports of task IPs on the host IP
+type Forwarder interface {
+	// Forward publishes the ports of the task IP for the owner. Nothing is
+	// published when one of the ports is already published for another owner.
+	// Forwarding the same ports for the same owner again is a no-op, so that
+	// the rules can be restored after a restart of the agent
+	Forward(ctx context.Context, owner string, taskIP string, mappings []Mapping) error
+	// Remove unpublishes the ports published for the owner, and frees them
+	// even if their rules couldn't all be removed
+	Remove(ctx context.Context, owner string) error
+	// RemoveStale removes the rules of the owners no port is published for,
+	// such as the rules of the tasks whose network was cleaned up while the
+	// agent was down. It's meant to be called once the ports of the restored
+	// tasks are forwarded again
+	RemoveStale(ctx context.Context) error
+}
+
+// commandRunner runs iptables with the given arguments and returns its output
+type commandRunner func(ctx context.Context, args ...string) (string, error)
+
+type forwarding struct {
+	taskIP   string
+	mappings []Mapping
+}
+
+type forwarder struct {
+	hostIP      string
+	runIPTables commandRunner
+
+	lock sync.Mutex
+	// owners maps the published ports to the owners they're published for
+	owners      map[Mapping]string
+	forwardings map[string]forwarding
+}
+
+// NewForwarder creates a Forwarder publishing ports on the host IP
+func NewForwarder(hostIP string) Forwarder {
+	return newForwarder(hostIP, runIPTables)
+}
+
+func newForwarder(hostIP string, runner commandRunner) *forwarder {
+	return &forwarder{
+		hostIP:      hostIP,
+		runIPTables: runner,
+		owners:      make(map[Mapping]string),
+		forwardings: make(map[string]forwarding),
+	}
+}
+
+// Forward publishes the ports of the task IP for the owner
+func (f *forwarder) Forward(ctx context.Context, owner string, taskIP string, mappings []Mapping) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	for _, mapping := range mappings {
+		if current, ok := f.owners[mapping]; ok && current != owner {
+			return &PortConflictError{Mapping: mapping, Owner: current}
+		}
+	}
+	if current, ok := f.forwardings[owner]; ok && current.taskIP != taskIP {
+		return errors.Errorf("port forwarder: ports of %s are already forwarded to %s", owner, current.taskIP)
+	}
+
+	var added []rule
+	for _, mapping := range mappings {
+		for _, r := range f.rules(owner, taskIP, mapping) {
+			ok, err := f.ensureRule(ctx, r)
+			if err != nil {
+				f.deleteRules(ctx, added)
+				return err
+			}
+			if ok {
+				added = append(added, r)
+			}
+		}
+	}
+
+	current := f.forwardings[owner]
+	current.taskIP = taskIP
+	for _, mapping := range mappings {
+		if _, ok := f.owners[mapping]; !ok {
+			f.owners[mapping] = owner
+			current.mappings = append(current.mappings, mapping)
+		}
+	}
+	f.forwardings[owner] = current
+	return nil
+}
+
+// Remove unpublishes the ports published for the owner
+func (f *forwarder) Remove(ctx context.Context, owner string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	current, ok := f.forwardings[owner]
+	if !ok {
+		return nil
+	}
+	var rules []rule
+	for _, mapping := range current.mappings {
+		rules = append(rules, f.rules(owner, current.taskIP, mapping)...)
+		delete(f.owners, mapping)
+	}
+	delete(f.forwardings, owner)
+	return f.deleteRules(ctx, rules)
+}
+
+// RemoveStale removes the rules of the owners no port is published for
+func (f *forwarder) RemoveStale(ctx context.Context) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	var stale []rule
+	for _, chain := range ruleChains {
+		out, err := f.runIPTables(ctx, "-w", "-t", chain.table, opList, chain.chain)
+		if err != nil {
+			return err
+		}
+		for _, line := range strings.Split(out, "\n") {
+			args := splitRuleArgs(line)
+			if len(args) < 2 || args[0] != opAppend || args[1] != chain.chain {
+				continue
+			}
+			owner, ok := ruleOwner(args[2:])
+			if !ok {
+				continue
+			}
+			if _, ok := f.forwardings[owner]; ok {
+				continue
+			}
+			seelog.Infof("Port forwarder: removing stale iptables rule of %s: %s", owner, line)
+			stale = append(stale, rule{table: chain.table, chain: chain.chain, spec: args[2:]})
+		}
+	}
+	return f.deleteRules(ctx, stale)
+}
+
+// ruleOwner returns the owner in the comment of a rule added by the agent
+func ruleOwner(spec []string) (string, bool) {
+	for i := 0; i < len(spec)-1; i++ {
+		if spec[i] == "--comment" && strings.HasPrefix(spec[i+1], commentPrefix) {
+			return strings.TrimPrefix(spec[i+1], commentPrefix), true
+		}
+	}
+	return "", false
+}
+
+// splitRuleArgs splits a rule listed by iptables into its arguments, which
+// are quoted when they contain spaces, like the comments of the rules
+func splitRuleArgs(line string) []string {
+	var args []string
+	var arg strings.Builder
+	inArg, quoted, escaped := false, false, false
+	for _, r := range line {
+		switch {
+		case escaped:
+			arg.WriteRune(r)
+			escaped = false
+		case quoted && r == '\\':
+			escaped = true
+		case r == '"':
+			quoted = !quoted
+			inArg = true
+		case !quoted && (r == ' ' || r == '\t'):
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			arg.WriteRune(r)
+			inArg = true
+		}
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+	return args
+}
+
+// rule is an iptables rule, in the given table and chain
+type rule struct {
+	table string
+	chain string
+	spec  []string
+}
+
+func (r rule) args(op string) []string {
+	return append([]string{"-w", "-t", r.table, op, r.chain}, r.spec...)
+}
+
+// rules returns the rules forwarding the port of the host IP to the task IP.
+// Traffic from other hosts is translated in PREROUTING and traffic from the
+// instance itself in OUTPUT. The source is masqueraded so that the replies of
+// the task, which would otherwise leave through its own ENI, come back through
+// the instance. The FORWARD rules let the traffic through when the policy of
+// the chain is DROP, as set by docker
+func (f *forwarder) rules(owner string, taskIP string, mapping Mapping) []rule {
+	port := strconv.Itoa(int(mapping.Port))
+	comment := []string{"-m", "comment", "--comment", commentPrefix + owner}
+	dnat := append([]string{
+		"-d", f.hostIP + "/32", "-p", mapping.Protocol, "-m", mapping.Protocol, "--dport", port},
+		append(comment, "-j", "DNAT", "--to-destination", taskIP+":"+port)...)
+	toTask := []string{"-d", taskIP + "/32", "-p", mapping.Protocol, "-m", mapping.Protocol, "--dport", port}
+	fromTask := []string{"-s", taskIP + "/32", "-p", mapping.Protocol, "-m", mapping.Protocol, "--sport", port}
+
+	return []rule{
+		{table: tableNAT, chain: "PREROUTING", spec: dnat},
+		{table: tableNAT, chain: "OUTPUT", spec: dnat},
+		{table: tableNAT, chain: "POSTROUTING", spec: append(append(toTask, comment...), "-j", "MASQUERADE")},
+		{table: tableFilter, chain: "FORWARD", spec: append(append(toTask, comment...), "-j", "ACCEPT")},
+		{table: tableFilter, chain: "FORWARD", spec: append(append(fromTask, comment...), "-j", "ACCEPT")},
+	}
+}
+
+// ensureRule appends the rule unless it already exists, and returns whether it
+// was appended
+func (f *forwarder) ensureRule(ctx context.Context, r rule) (bool, error) {
+	if _, err := f.runIPTables(ctx, r.args(opCheck)...); err == nil {
+		return false, nil
+	}
+	if _, err := f.runIPTables(ctx, r.args(opAppend)...); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// deleteRules deletes the rules that exist, and returns the first error
+func (f *forwarder) deleteRules(ctx context.Context, rules []rule) error {
+	var firstErr error
+	for _, r := range rules {
+		if _, err := f.runIPTables(ctx, r.args(opCheck)...); err != nil {
+			continue
+		}
+		if _, err := f.runIPTables(ctx, r.args(opDelete)...); err != nil {
+			seelog.Warnf("Port forwarder: unable to delete iptables rule: %v", err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+func runIPTables(ctx context.Context, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, iptablesTimeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, iptablesCommand, args...).CombinedOutput()
+	if err != nil {
+		return "", errors.Wrapf(err, "%s %s: %s", iptablesCommand, strings.Join(args, " "), strings.TrimSpace(string(out)))
+	}
+	return string(out), nil
+}
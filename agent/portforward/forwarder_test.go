@@ -0,0 +1,187 @@
+// +build unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package portforward
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	hostIP    = "10.0.0.10"
+	taskIP    = "10.0.0.20"
+	otherIP   = "10.0.0.30"
+	taskARN   = "arn:aws:ecs:us-west-2:123456789012:task/task"
+	otherARN  = "arn:aws:ecs:us-west-2:123456789012:task/other"
+	rulesEach = 5
+)
+
+// fakeIPTables keeps the rules added with iptables in memory
+type fakeIPTables struct {
+	// rules maps the joined arguments of the rules to their arguments
+	rules map[string][]string
+	// failAppend fails the appends of rules containing it
+	failAppend string
+}
+
+func newFakeIPTables() *fakeIPTables {
+	return &fakeIPTables{rules: make(map[string][]string)}
+}
+
+func (fake *fakeIPTables) run(ctx context.Context, args ...string) (string, error) {
+	op := args[3]
+	ruleArgs := append(args[:3:3], args[4:]...)
+	key := strings.Join(ruleArgs, " ")
+	_, exists := fake.rules[key]
+	switch op {
+	case opList:
+		return fake.list(args[2], args[4]), nil
+	case opCheck:
+		if !exists {
+			return "", errors.New("no such rule")
+		}
+	case opAppend:
+		if fake.failAppend != "" && strings.Contains(key, fake.failAppend) {
+			return "", errors.New("append failed")
+		}
+		fake.rules[key] = ruleArgs
+	case opDelete:
+		if !exists {
+			return "", errors.New("no such rule")
+		}
+		delete(fake.rules, key)
+	}
+	return "", nil
+}
+
+// list prints the rules of the chain as iptables -S does, quoting the
+// arguments with spaces
+func (fake *fakeIPTables) list(table string, chain string) string {
+	lines := []string{"-P " + chain + " ACCEPT"}
+	for _, args := range fake.rules {
+		if args[2] != table || args[3] != chain {
+			continue
+		}
+		line := []string{opAppend, chain}
+		for _, arg := range args[4:] {
+			if strings.Contains(arg, " ") {
+				arg = `"` + arg + `"`
+			}
+			line = append(line, arg)
+		}
+		lines = append(lines, strings.Join(line, " "))
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func (fake *fakeIPTables) count(substr string) int {
+	count := 0
+	for key := range fake.rules {
+		if strings.Contains(key, substr) {
+			count++
+		}
+	}
+	return count
+}
+
+func TestForwardAndRemove(t *testing.T) {
+	fake := newFakeIPTables()
+	f := newForwarder(hostIP, fake.run)
+	ctx := context.TODO()
+
+	mappings := []Mapping{{Protocol: "tcp", Port: 80}, {Protocol: "udp", Port: 53}}
+	require.NoError(t, f.Forward(ctx, taskARN, taskIP, mappings))
+	assert.Len(t, fake.rules, 2*rulesEach)
+	assert.Equal(t, 1, fake.count("-t nat PREROUTING -d 10.0.0.10/32 -p tcp -m tcp --dport 80"))
+	assert.Equal(t, 2, fake.count("--to-destination 10.0.0.20:80"))
+	assert.Equal(t, 2, fake.count("--to-destination 10.0.0.20:53"))
+
+	// Forwarding again, as done after a restart, doesn't duplicate the rules
+	require.NoError(t, f.Forward(ctx, taskARN, taskIP, mappings))
+	assert.Len(t, fake.rules, 2*rulesEach)
+
+	require.NoError(t, f.Remove(ctx, taskARN))
+	assert.Empty(t, fake.rules)
+	assert.Empty(t, f.owners)
+	assert.NoError(t, f.Remove(ctx, taskARN), "removing twice is a no-op")
+}
+
+func TestForwardConflict(t *testing.T) {
+	fake := newFakeIPTables()
+	f := newForwarder(hostIP, fake.run)
+	ctx := context.TODO()
+
+	require.NoError(t, f.Forward(ctx, taskARN, taskIP, []Mapping{{Protocol: "tcp", Port: 80}}))
+
+	err := f.Forward(ctx, otherARN, otherIP, []Mapping{{Protocol: "tcp", Port: 8080}, {Protocol: "tcp", Port: 80}})
+	require.Error(t, err)
+	conflict, ok := err.(*PortConflictError)
+	require.True(t, ok)
+	assert.Equal(t, Mapping{Protocol: "tcp", Port: 80}, conflict.Mapping)
+	assert.Equal(t, taskARN, conflict.Owner)
+	assert.Zero(t, fake.count(otherIP), "nothing is published on conflicts")
+
+	// The same port with another protocol doesn't conflict
+	assert.NoError(t, f.Forward(ctx, otherARN, otherIP, []Mapping{{Protocol: "udp", Port: 80}}))
+
+	// The port can be published again once it's removed
+	require.NoError(t, f.Remove(ctx, taskARN))
+	assert.NoError(t, f.Forward(ctx, otherARN, otherIP, []Mapping{{Protocol: "tcp", Port: 80}}))
+	assert.Equal(t, rulesEach*2, fake.count(otherIP))
+}
+
+func TestForwardRollsBackOnError(t *testing.T) {
+	fake := newFakeIPTables()
+	f := newForwarder(hostIP, fake.run)
+	fake.failAppend = "--dport 443"
+
+	err := f.Forward(context.TODO(), taskARN, taskIP, []Mapping{{Protocol: "tcp", Port: 80}, {Protocol: "tcp", Port: 443}})
+	assert.Error(t, err)
+	assert.Empty(t, fake.rules)
+	assert.Empty(t, f.owners)
+}
+
+func TestRemoveStale(t *testing.T) {
+	fake := newFakeIPTables()
+	ctx := context.TODO()
+	mappings := []Mapping{{Protocol: "tcp", Port: 80}}
+
+	// The rules of both tasks are left by a previous run of the agent
+	previous := newForwarder(hostIP, fake.run)
+	require.NoError(t, previous.Forward(ctx, taskARN, taskIP, mappings))
+	require.NoError(t, previous.Forward(ctx, otherARN, otherIP, []Mapping{{Protocol: "udp", Port: 53}}))
+	fake.rules["-w -t filter FORWARD -j DOCKER-USER"] = []string{"-w", "-t", "filter", "FORWARD", "-j", "DOCKER-USER"}
+
+	// Only the ports of the first task are published again after the restart
+	f := newForwarder(hostIP, fake.run)
+	require.NoError(t, f.Forward(ctx, taskARN, taskIP, mappings))
+	require.NoError(t, f.RemoveStale(ctx))
+	assert.Equal(t, rulesEach, fake.count(taskIP))
+	assert.Zero(t, fake.count(otherIP))
+	assert.Equal(t, 1, fake.count("DOCKER-USER"), "rules not added by the agent are kept")
+}
+
+func TestSplitRuleArgs(t *testing.T) {
+	assert.Equal(t, []string{"-A", "FORWARD", "-m", "comment", "--comment", "ecs-agent owner", "-j", "ACCEPT"},
+		splitRuleArgs(`-A FORWARD -m comment --comment "ecs-agent owner" -j ACCEPT`))
+	assert.Equal(t, []string{"-A", "FORWARD", `a "b`, ""}, splitRuleArgs(`-A  FORWARD "a \"b" ""`))
+	assert.Empty(t, splitRuleArgs(""))
+}
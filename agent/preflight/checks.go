@@ -0,0 +1,84 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package preflight
+
+import (
+	"context"
+
+	"github.com/aws/amazon-ecs-agent/agent/dockerclient"
+	"github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/docker/docker/api/types"
+	"github.com/pkg/errors"
+)
+
+// DockerCheck checks the docker daemon answers requests
+func DockerCheck(ctx context.Context, client dockerapi.DockerClient) Check {
+	return Check{
+		Name:  "docker daemon is reachable",
+		Class: ClassDocker,
+		Run: func() error {
+			if _, err := client.Version(ctx, dockerclient.VersionTimeout); err != nil {
+				return errors.Wrap(err, "unable to reach the docker daemon, check that it's running "+
+					"and that its socket is mounted in the agent container")
+			}
+			return nil
+		},
+	}
+}
+
+// UsernsRemapCheck checks the docker daemon runs with user namespace remapping,
+// so that root in task containers isn't root on the host
+func UsernsRemapCheck(ctx context.Context, client dockerapi.DockerClient) Check {
+	return Check{
+		Name:  "docker daemon remaps user namespaces",
+		Class: ClassDocker,
+		Run: func() error {
+			info, err := client.Info(ctx, dockerclient.InfoTimeout)
+			if err != nil {
+				return errors.Wrap(err, "unable to get the security options of the docker daemon")
+			}
+			securityOpts, err := types.DecodeSecurityOptions(info.SecurityOptions)
+			if err != nil {
+				return errors.Wrap(err, "unable to decode the security options of the docker daemon")
+			}
+			for _, opt := range securityOpts {
+				if opt.Name == "userns" {
+					return nil
+				}
+			}
+			return errors.New("the docker daemon doesn't remap user namespaces, start it with " +
+				"'--userns-remap=default' or disable ECS_ENABLE_MULTI_TENANT_ISOLATION")
+		},
+	}
+}
+
+// CredentialsCheck checks the credential provider returns credentials
+func CredentialsCheck(credentialProvider *credentials.Credentials) Check {
+	return Check{
+		Name:  "credentials are available",
+		Class: ClassCredentials,
+		Run: func() error {
+			creds, err := credentialProvider.Get()
+			if err != nil {
+				return errors.Wrap(err, "unable to get credentials, check the instance profile of the instance "+
+					"or the AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY of the agent")
+			}
+			if creds.AccessKeyID == "" {
+				return errors.New("the credentials have no access key id")
+			}
+			return nil
+		},
+	}
+}
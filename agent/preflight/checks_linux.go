@@ -0,0 +1,124 @@
+// +build linux
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package preflight
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/pkg/errors"
+)
+
+// cgroupSubsystems are the cgroup subsystems task limits are enforced with
+var cgroupSubsystems = []string{"cpu", "memory"}
+
+// CgroupCheck checks the cgroup subsystems task limits are enforced with are
+// mounted under cgroupPath
+func CgroupCheck(cgroupPath string) Check {
+	return Check{
+		Name:  "cgroup hierarchy is available",
+		Class: ClassCgroup,
+		Run: func() error {
+			for _, subsystem := range cgroupSubsystems {
+				subsystemPath := filepath.Join(cgroupPath, subsystem)
+				if _, err := os.Stat(subsystemPath); err != nil {
+					return errors.Wrapf(err, "cgroup subsystem %s isn't mounted at %s, check that %s is mounted "+
+						"in the agent container or set ECS_CGROUP_PATH", subsystem, subsystemPath, cgroupPath)
+				}
+			}
+			return nil
+		},
+	}
+}
+
+// CNIPluginsCheck checks the CNI plugins are executables in pluginsPath
+func CNIPluginsCheck(pluginsPath string, plugins []string) Check {
+	return Check{
+		Name:  "CNI plugins are installed",
+		Class: ClassNetwork,
+		Run: func() error {
+			for _, plugin := range plugins {
+				pluginPath := filepath.Join(pluginsPath, plugin)
+				info, err := os.Stat(pluginPath)
+				if err != nil {
+					return errors.Wrapf(err, "CNI plugin %s not found, check ECS_CNI_PLUGINS_PATH", plugin)
+				}
+				if info.IsDir() || info.Mode()&0111 == 0 {
+					return errors.Errorf("CNI plugin %s isn't executable", pluginPath)
+				}
+			}
+			return nil
+		},
+	}
+}
+
+// IPForwardingCheck checks IP forwarding is enabled on the instance, as the
+// network namespaces of awsvpc tasks can't be reached without it
+func IPForwardingCheck(ipForwardPath string) Check {
+	return Check{
+		Name:  "IP forwarding is enabled",
+		Class: ClassNetwork,
+		Run: func() error {
+			value, err := ioutil.ReadFile(ipForwardPath)
+			if err != nil {
+				return errors.Wrapf(err, "unable to read %s", ipForwardPath)
+			}
+			if strings.TrimSpace(string(value)) != "1" {
+				return errors.Errorf("IP forwarding is disabled, enable it with 'sysctl -w net.ipv4.ip_forward=1'")
+			}
+			return nil
+		},
+	}
+}
+
+// DiskSpaceCheck checks the file system of dir has at least minimumFreeBytes
+// available
+func DiskSpaceCheck(dir string, minimumFreeBytes uint64) Check {
+	return Check{
+		Name:  "disk space is available",
+		Class: ClassDisk,
+		Run: func() error {
+			var stat syscall.Statfs_t
+			if err := syscall.Statfs(existingParent(dir), &stat); err != nil {
+				return errors.Wrapf(err, "unable to get the disk space available to %s", dir)
+			}
+			freeBytes := stat.Bavail * uint64(stat.Bsize)
+			if freeBytes < minimumFreeBytes {
+				return errors.Errorf("%d bytes available to %s, at least %d bytes are needed",
+					freeBytes, dir, minimumFreeBytes)
+			}
+			return nil
+		},
+	}
+}
+
+// existingParent returns dir, or its closest parent that exists when dir
+// hasn't been created yet
+func existingParent(dir string) string {
+	for {
+		if _, err := os.Stat(dir); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return dir
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,75 @@
+// +build linux,unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package preflight
+
+import (
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCgroupCheck(t *testing.T) {
+	cgroupPath, err := ioutil.TempDir("", "cgroup")
+	require.NoError(t, err)
+	defer os.RemoveAll(cgroupPath)
+
+	require.NoError(t, os.Mkdir(filepath.Join(cgroupPath, "cpu"), 0755))
+	assert.Error(t, CgroupCheck(cgroupPath).Run(), "memory subsystem is missing")
+	require.NoError(t, os.Mkdir(filepath.Join(cgroupPath, "memory"), 0755))
+	assert.NoError(t, CgroupCheck(cgroupPath).Run())
+}
+
+func TestCNIPluginsCheck(t *testing.T) {
+	pluginsPath, err := ioutil.TempDir("", "cni")
+	require.NoError(t, err)
+	defer os.RemoveAll(pluginsPath)
+
+	plugins := []string{"ecs-eni", "ecs-bridge"}
+	assert.Error(t, CNIPluginsCheck(pluginsPath, plugins).Run(), "plugins are missing")
+	require.NoError(t, ioutil.WriteFile(filepath.Join(pluginsPath, "ecs-eni"), nil, 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(pluginsPath, "ecs-bridge"), nil, 0644))
+	assert.Error(t, CNIPluginsCheck(pluginsPath, plugins).Run(), "ecs-bridge isn't executable")
+	require.NoError(t, os.Chmod(filepath.Join(pluginsPath, "ecs-bridge"), 0755))
+	assert.NoError(t, CNIPluginsCheck(pluginsPath, plugins).Run())
+}
+
+func TestIPForwardingCheck(t *testing.T) {
+	ipForward, err := ioutil.TempFile("", "ip_forward")
+	require.NoError(t, err)
+	defer os.Remove(ipForward.Name())
+	ipForward.Close()
+
+	require.NoError(t, ioutil.WriteFile(ipForward.Name(), []byte("0\n"), 0644))
+	assert.Error(t, IPForwardingCheck(ipForward.Name()).Run())
+	require.NoError(t, ioutil.WriteFile(ipForward.Name(), []byte("1\n"), 0644))
+	assert.NoError(t, IPForwardingCheck(ipForward.Name()).Run())
+	assert.Error(t, IPForwardingCheck(filepath.Join(ipForward.Name(), "missing")).Run())
+}
+
+func TestDiskSpaceCheck(t *testing.T) {
+	dataDir, err := ioutil.TempDir("", "data")
+	require.NoError(t, err)
+	defer os.RemoveAll(dataDir)
+
+	assert.NoError(t, DiskSpaceCheck(dataDir, 1).Run())
+	assert.NoError(t, DiskSpaceCheck(filepath.Join(dataDir, "not", "created"), 1).Run())
+	assert.Error(t, DiskSpaceCheck(dataDir, math.MaxUint64).Run())
+}
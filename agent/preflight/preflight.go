@@ -0,0 +1,117 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package preflight validates the prerequisites of the agent at startup, so
+// that a misconfigured instance fails with a report of everything that's
+// wrong with it rather than with the first runtime error it runs into
+package preflight
+
+import (
+	"encoding/json"
+
+	"github.com/aws/amazon-ecs-agent/agent/sighandlers/exitcodes"
+)
+
+// Class is the class of prerequisite a check validates. Each class has its own
+// exit code
+type Class string
+
+const (
+	// ClassDocker checks the docker daemon is reachable
+	ClassDocker Class = "docker"
+	// ClassCgroup checks the cgroup hierarchy needed for task limits exists
+	ClassCgroup Class = "cgroup"
+	// ClassNetwork checks the prerequisites of task networking
+	ClassNetwork Class = "network"
+	// ClassDisk checks there's enough disk space for the agent data
+	ClassDisk Class = "disk"
+	// ClassCredentials checks the agent has credentials to call ECS with
+	ClassCredentials Class = "credentials"
+)
+
+var classExitCodes = map[Class]int{
+	ClassDocker:      exitcodes.ExitPreflightDocker,
+	ClassCgroup:      exitcodes.ExitPreflightCgroup,
+	ClassNetwork:     exitcodes.ExitPreflightNetwork,
+	ClassDisk:        exitcodes.ExitPreflightDisk,
+	ClassCredentials: exitcodes.ExitPreflightCredentials,
+}
+
+// Check is a prerequisite of the agent
+type Check struct {
+	// Name describes what the check validates
+	Name string
+	// Class is the class of the prerequisite
+	Class Class
+	// Run returns an error describing how to fix the prerequisite when it
+	// isn't met
+	Run func() error
+}
+
+// Result is the outcome of a check
+type Result struct {
+	Name   string `json:"name"`
+	Class  Class  `json:"class"`
+	Passed bool   `json:"passed"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Report is the outcome of all the checks
+type Report struct {
+	Passed  bool     `json:"passed"`
+	Results []Result `json:"checks"`
+}
+
+// Run runs all the checks, even once one of them failed, so that the report
+// lists all the prerequisites that aren't met
+func Run(checks []Check) *Report {
+	report := &Report{Passed: true}
+	for _, check := range checks {
+		result := Result{
+			Name:   check.Name,
+			Class:  check.Class,
+			Passed: true,
+		}
+		if err := check.Run(); err != nil {
+			result.Passed = false
+			result.Error = err.Error()
+			report.Passed = false
+		}
+		report.Results = append(report.Results, result)
+	}
+	return report
+}
+
+// ExitCode returns the exit code of the class of the first failed check, or
+// exitcodes.ExitSuccess when all the checks passed
+func (report *Report) ExitCode() int {
+	for _, result := range report.Results {
+		if result.Passed {
+			continue
+		}
+		if exitCode, ok := classExitCodes[result.Class]; ok {
+			return exitCode
+		}
+		return exitcodes.ExitError
+	}
+	return exitcodes.ExitSuccess
+}
+
+// String returns the report as JSON
+func (report *Report) String() string {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return err.Error()
+	}
+	return string(data)
+}
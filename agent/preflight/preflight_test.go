@@ -0,0 +1,112 @@
+// +build unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package preflight
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	mock_dockerapi "github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi/mocks"
+	"github.com/aws/amazon-ecs-agent/agent/sighandlers/exitcodes"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/docker/docker/api/types"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func passingCheck(class Class) Check {
+	return Check{Name: "passing", Class: class, Run: func() error { return nil }}
+}
+
+func failingCheck(class Class) Check {
+	return Check{Name: "failing", Class: class, Run: func() error { return errors.New("failed") }}
+}
+
+func TestRunAllChecksPass(t *testing.T) {
+	report := Run([]Check{passingCheck(ClassDocker), passingCheck(ClassDisk)})
+	assert.True(t, report.Passed)
+	assert.Len(t, report.Results, 2)
+	assert.Equal(t, exitcodes.ExitSuccess, report.ExitCode())
+}
+
+func TestRunReportsAllFailures(t *testing.T) {
+	report := Run([]Check{
+		passingCheck(ClassDocker),
+		failingCheck(ClassCgroup),
+		failingCheck(ClassCredentials),
+	})
+	assert.False(t, report.Passed)
+	require.Len(t, report.Results, 3)
+	assert.True(t, report.Results[0].Passed)
+	assert.False(t, report.Results[1].Passed)
+	assert.Equal(t, "failed", report.Results[1].Error)
+	assert.False(t, report.Results[2].Passed)
+	assert.Equal(t, exitcodes.ExitPreflightCgroup, report.ExitCode())
+}
+
+func TestReportString(t *testing.T) {
+	report := Run([]Check{failingCheck(ClassNetwork)})
+
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(report.String()), &decoded))
+	assert.Equal(t, false, decoded["passed"])
+	checks := decoded["checks"].([]interface{})
+	require.Len(t, checks, 1)
+	assert.Equal(t, "network", checks[0].(map[string]interface{})["class"])
+	assert.Equal(t, "failed", checks[0].(map[string]interface{})["error"])
+}
+
+func TestDockerCheck(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock_dockerapi.NewMockDockerClient(ctrl)
+
+	gomock.InOrder(
+		client.EXPECT().Version(gomock.Any(), gomock.Any()).Return("18.09", nil),
+		client.EXPECT().Version(gomock.Any(), gomock.Any()).Return("", errors.New("connection refused")),
+	)
+	check := DockerCheck(context.TODO(), client)
+	assert.NoError(t, check.Run())
+	assert.Error(t, check.Run())
+}
+
+func TestUsernsRemapCheck(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock_dockerapi.NewMockDockerClient(ctrl)
+
+	gomock.InOrder(
+		client.EXPECT().Info(gomock.Any(), gomock.Any()).Return(types.Info{
+			SecurityOptions: []string{"name=seccomp,profile=default", "name=userns"},
+		}, nil),
+		client.EXPECT().Info(gomock.Any(), gomock.Any()).Return(types.Info{
+			SecurityOptions: []string{"name=seccomp,profile=default"},
+		}, nil),
+		client.EXPECT().Info(gomock.Any(), gomock.Any()).Return(types.Info{}, errors.New("connection refused")),
+	)
+	check := UsernsRemapCheck(context.TODO(), client)
+	assert.NoError(t, check.Run())
+	assert.Error(t, check.Run())
+	assert.Error(t, check.Run())
+}
+
+func TestCredentialsCheck(t *testing.T) {
+	assert.NoError(t, CredentialsCheck(credentials.NewStaticCredentials("id", "secret", "")).Run())
+	assert.Error(t, CredentialsCheck(credentials.NewCredentials(&credentials.StaticProvider{})).Run())
+}
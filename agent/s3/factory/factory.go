@@ -33,6 +33,7 @@ const (
 
 type S3ClientCreator interface {
 	NewS3ClientForBucket(bucket, region string, creds credentials.IAMRoleCredentials) (s3client.S3Client, error)
+	NewS3UploaderForBucket(bucket, region string, credentialProvider *awscreds.Credentials) (s3client.S3Uploader, error)
 }
 
 func NewS3ClientCreator() S3ClientCreator {
@@ -49,6 +50,27 @@ func (*s3ClientCreator) NewS3ClientForBucket(bucket, region string,
 		WithCredentials(
 			awscreds.NewStaticCredentials(creds.AccessKeyID, creds.SecretAccessKey,
 				creds.SessionToken)).WithRegion(region)
+	svc, err := newS3ClientForBucket(bucket, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return s3manager.NewDownloaderWithClient(svc), nil
+}
+
+func (*s3ClientCreator) NewS3UploaderForBucket(bucket, region string,
+	credentialProvider *awscreds.Credentials) (s3client.S3Uploader, error) {
+	cfg := aws.NewConfig().
+		WithHTTPClient(httpclient.New(roundtripTimeout, false)).
+		WithCredentials(credentialProvider).WithRegion(region)
+	svc, err := newS3ClientForBucket(bucket, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return s3manager.NewUploaderWithClient(svc), nil
+}
+
+// newS3ClientForBucket returns an S3 client for the region the bucket is in
+func newS3ClientForBucket(bucket string, cfg *aws.Config) (*s3.S3, error) {
 	sess := session.Must(session.NewSession(cfg))
 
 	svc := s3.New(sess)
@@ -58,7 +80,7 @@ func (*s3ClientCreator) NewS3ClientForBucket(bucket, region string,
 	}
 
 	sessWithRegion := session.Must(session.NewSession(cfg.WithRegion(bucketRegion)))
-	return s3manager.NewDownloaderWithClient(s3.New(sessWithRegion)), nil
+	return s3.New(sessWithRegion), nil
 }
 
 func getRegionFromBucket(svc *s3.S3, bucket string) (string, error) {
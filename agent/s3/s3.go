@@ -21,6 +21,7 @@ import (
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
 	"github.com/pkg/errors"
 )
 
@@ -42,6 +43,19 @@ func DownloadFile(bucket, key string, timeout time.Duration, w io.WriterAt, clie
 }
 
 // ParseS3ARN parses an s3 ARN.
+func UploadFile(bucket, key string, timeout time.Duration, r io.Reader, client S3Uploader) error {
+	input := &s3manager.UploadInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+		Body:   r,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	_, err := client.UploadWithContext(ctx, input)
+	return err
+}
+
 func ParseS3ARN(s3ARN string) (bucket string, key string, err error) {
 	exp := regexp.MustCompile(s3ARNRegex)
 	match := exp.FindStringSubmatch(s3ARN)
@@ -18,11 +18,13 @@ package s3
 import (
 	"errors"
 	"io"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	s3sdk "github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 
@@ -66,6 +68,36 @@ func TestDownloadFileError(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestUploadFile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockS3Uploader := mock_s3.NewMockS3Uploader(ctrl)
+	body := strings.NewReader("data")
+
+	mockS3Uploader.EXPECT().UploadWithContext(gomock.Any(), gomock.Any()).Do(func(ctx aws.Context,
+		input *s3manager.UploadInput) {
+		assert.Equal(t, testBucket, aws.StringValue(input.Bucket))
+		assert.Equal(t, testKey, aws.StringValue(input.Key))
+		assert.Equal(t, body, input.Body)
+	})
+
+	err := UploadFile(testBucket, testKey, testTimeout, body, mockS3Uploader)
+	assert.NoError(t, err)
+}
+
+func TestUploadFileError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockS3Uploader := mock_s3.NewMockS3Uploader(ctrl)
+
+	mockS3Uploader.EXPECT().UploadWithContext(gomock.Any(), gomock.Any()).Return(nil, errors.New("test error"))
+
+	err := UploadFile(testBucket, testKey, testTimeout, strings.NewReader("data"), mockS3Uploader)
+	assert.Error(t, err)
+}
+
 func TestParseS3ARN(t *testing.T) {
 	bucket, key, err := ParseS3ARN("arn:aws:s3:::bucket/key")
 	assert.NoError(t, err)
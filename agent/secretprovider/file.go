@@ -0,0 +1,61 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package secretprovider
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	"github.com/pkg/errors"
+)
+
+// fileScheme is the scheme of the secrets stored in files on the instance,
+// such as file:///etc/ecs/secrets/myapp/password
+const fileScheme = "file"
+
+// fileProvider retrieves secrets from files on the instance. It's meant for
+// development environments, where no secret store is available.
+type fileProvider struct {
+	dir string
+}
+
+// NewFileProvider returns a Provider for the secrets stored in files under dir.
+// Files outside of dir can't be used as secrets.
+func NewFileProvider(dir string) Provider {
+	return &fileProvider{
+		dir: filepath.Clean(dir),
+	}
+}
+
+// GetSecretValue returns the content of the file referenced by the valueFrom of
+// the secret, in the format file://<absolute path>, without its trailing newline
+func (provider *fileProvider) GetSecretValue(apiSecret apicontainer.Secret) (string, error) {
+	valueFrom := apiSecret.ValueFrom
+	secretURL, err := parseValueFrom(valueFrom, fileScheme)
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Clean(secretURL.Path)
+	if secretURL.Host != "" || !strings.HasPrefix(path, provider.dir+string(filepath.Separator)) {
+		return "", errors.Errorf("file secret %s: the file must be under %s", valueFrom, provider.dir)
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", errors.Wrapf(err, "file secret %s", valueFrom)
+	}
+	return strings.TrimSuffix(string(b), "\n"), nil
+}
@@ -0,0 +1,74 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package secretprovider implements the retrieval of secrets from secret
+// stores other than the AWS services, such as HashiCorp Vault or local files.
+// The provider of a secret is selected from the scheme of its valueFrom.
+package secretprovider
+
+import (
+	"net/url"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/httpclient"
+	"github.com/pkg/errors"
+)
+
+// Provider retrieves the value of secrets. It's implemented by the secret
+// stores, and by the task resources that retrieve the secrets of a task, so
+// that the secrets of every provider are resolved the same way
+type Provider interface {
+	// GetSecretValue returns the value of the secret
+	GetSecretValue(secret apicontainer.Secret) (string, error)
+}
+
+// Providers maps the names of the secret providers to their implementation
+type Providers map[string]Provider
+
+// NewProviders returns the secret providers configured on the instance
+func NewProviders(cfg *config.Config) Providers {
+	providers := make(Providers)
+	if cfg.VaultAddress != "" {
+		providers[apicontainer.SecretProviderVault] = NewVaultProvider(cfg.VaultAddress, cfg.VaultTokenFile,
+			httpclient.New(vaultRequestTimeout, cfg.AcceptInsecureCert))
+	}
+	if cfg.SecretsFileProviderDir != "" {
+		providers[apicontainer.SecretProviderFile] = NewFileProvider(cfg.SecretsFileProviderDir)
+	}
+	return providers
+}
+
+// GetSecretValue returns the value of the secret from the provider selected
+// by the scheme of its valueFrom
+func (providers Providers) GetSecretValue(secret apicontainer.Secret) (string, error) {
+	providerName := secret.GetProvider()
+	provider, ok := providers[providerName]
+	if !ok {
+		return "", errors.Errorf("secret provider %s is not configured on this instance", providerName)
+	}
+	return provider.GetSecretValue(secret)
+}
+
+// parseValueFrom returns the URL of the secret referenced by valueFrom, and
+// checks that its scheme is the expected one
+func parseValueFrom(valueFrom string, scheme string) (*url.URL, error) {
+	secretURL, err := url.Parse(valueFrom)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid secret reference %s", valueFrom)
+	}
+	if secretURL.Scheme != scheme {
+		return nil, errors.Errorf("invalid secret reference %s: expected scheme %s", valueFrom, scheme)
+	}
+	return secretURL, nil
+}
@@ -0,0 +1,150 @@
+// +build unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package secretprovider
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func vaultTestServer(t *testing.T, body string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(vaultTokenHeader) != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/myapp":
+			w.Write([]byte(body))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func vaultTokenFile(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "vault")
+	require.NoError(t, err)
+	tokenFile := filepath.Join(dir, "token")
+	require.NoError(t, ioutil.WriteFile(tokenFile, []byte("token\n"), 0600))
+	return tokenFile, func() { os.RemoveAll(dir) }
+}
+
+func TestVaultProviderKVVersion2(t *testing.T) {
+	server := vaultTestServer(t, `{"data":{"data":{"password":"s3cr3t","port":5432},"metadata":{"version":1}}}`)
+	defer server.Close()
+	tokenFile, cleanup := vaultTokenFile(t)
+	defer cleanup()
+
+	provider := NewVaultProvider(server.URL+"/", tokenFile, &http.Client{Timeout: time.Second})
+	value, err := provider.GetSecretValue(apicontainer.Secret{ValueFrom: "vault://secret/data/myapp#password"})
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t", value)
+
+	value, err = provider.GetSecretValue(apicontainer.Secret{ValueFrom: "vault://secret/data/myapp#port"})
+	require.NoError(t, err)
+	assert.Equal(t, "5432", value)
+}
+
+func TestVaultProviderKVVersion1(t *testing.T) {
+	server := vaultTestServer(t, `{"data":{"password":"s3cr3t"}}`)
+	defer server.Close()
+	tokenFile, cleanup := vaultTokenFile(t)
+	defer cleanup()
+
+	provider := NewVaultProvider(server.URL, tokenFile, &http.Client{Timeout: time.Second})
+	value, err := provider.GetSecretValue(apicontainer.Secret{ValueFrom: "vault://secret/data/myapp#password"})
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t", value)
+}
+
+func TestVaultProviderErrors(t *testing.T) {
+	server := vaultTestServer(t, `{"data":{"password":"s3cr3t"}}`)
+	defer server.Close()
+	tokenFile, cleanup := vaultTokenFile(t)
+	defer cleanup()
+
+	provider := NewVaultProvider(server.URL, tokenFile, &http.Client{Timeout: time.Second})
+	for _, valueFrom := range []string{
+		"vault://secret/data/myapp",
+		"vault://secret/data/myapp#user",
+		"vault://secret/data/other#password",
+		"file:///secret/data/myapp#password",
+	} {
+		t.Run(valueFrom, func(t *testing.T) {
+			_, err := provider.GetSecretValue(apicontainer.Secret{ValueFrom: valueFrom})
+			assert.Error(t, err)
+		})
+	}
+
+	provider = NewVaultProvider(server.URL, "/nonexistent/token", &http.Client{Timeout: time.Second})
+	_, err := provider.GetSecretValue(apicontainer.Secret{ValueFrom: "vault://secret/data/myapp#password"})
+	assert.Error(t, err)
+}
+
+func TestFileProvider(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrets")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "password"), []byte("s3cr3t\n"), 0600))
+
+	provider := NewFileProvider(dir)
+	value, err := provider.GetSecretValue(apicontainer.Secret{ValueFrom: "file://" + filepath.Join(dir, "password")})
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t", value)
+
+	for _, valueFrom := range []string{
+		"file://" + filepath.Join(dir, "missing"),
+		"file://" + filepath.Join(dir, "..", "password"),
+		"file://" + dir,
+		"file:///etc/passwd",
+		"vault://" + filepath.Join(dir, "password"),
+	} {
+		t.Run(valueFrom, func(t *testing.T) {
+			_, err := provider.GetSecretValue(apicontainer.Secret{ValueFrom: valueFrom})
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestProvidersGetSecretValue(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrets")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "password"), []byte("s3cr3t"), 0600))
+
+	providers := NewProviders(&config.Config{SecretsFileProviderDir: dir})
+	value, err := providers.GetSecretValue(apicontainer.Secret{
+		ValueFrom: "file://" + filepath.Join(dir, "password"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t", value)
+
+	_, err = providers.GetSecretValue(apicontainer.Secret{
+		ValueFrom: "vault://secret/data/myapp#password",
+	})
+	assert.Error(t, err, "vault isn't configured")
+}
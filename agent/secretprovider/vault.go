@@ -0,0 +1,128 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package secretprovider
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	"github.com/pkg/errors"
+)
+
+const (
+	// vaultScheme is the scheme of the secrets stored in HashiCorp Vault, such
+	// as vault://secret/data/myapp#password
+	vaultScheme = "vault"
+	// vaultRequestTimeout is the timeout of the requests to the Vault server
+	vaultRequestTimeout = 30 * time.Second
+	// vaultTokenHeader is the header used to authenticate with the Vault server
+	vaultTokenHeader = "X-Vault-Token"
+	// maxVaultResponseSize is the maximum size of a secret read from Vault
+	maxVaultResponseSize = 1024 * 1024
+)
+
+// vaultSecretResponse is the response of the Vault read secret API
+type vaultSecretResponse struct {
+	Data map[string]interface{} `json:"data"`
+}
+
+// vaultProvider retrieves secrets from a HashiCorp Vault server
+type vaultProvider struct {
+	address   string
+	tokenFile string
+	client    *http.Client
+}
+
+// NewVaultProvider returns a Provider for the secrets stored in the Vault
+// server at address. The Vault token is read from tokenFile for every request,
+// so that it can be renewed by an external process such as the Vault agent.
+func NewVaultProvider(address string, tokenFile string, client *http.Client) Provider {
+	return &vaultProvider{
+		address:   strings.TrimSuffix(address, "/"),
+		tokenFile: tokenFile,
+		client:    client,
+	}
+}
+
+// GetSecretValue returns the value of the key of the Vault secret referenced
+// by the valueFrom of the secret, in the format vault://<secret path>#<key>
+func (provider *vaultProvider) GetSecretValue(apiSecret apicontainer.Secret) (string, error) {
+	valueFrom := apiSecret.ValueFrom
+	secretURL, err := parseValueFrom(valueFrom, vaultScheme)
+	if err != nil {
+		return "", err
+	}
+	key := secretURL.Fragment
+	if key == "" {
+		return "", errors.Errorf("vault secret %s: missing key", valueFrom)
+	}
+
+	token, err := ioutil.ReadFile(provider.tokenFile)
+	if err != nil {
+		return "", errors.Wrapf(err, "vault secret %s: unable to read vault token", valueFrom)
+	}
+	req, err := http.NewRequest(http.MethodGet,
+		fmt.Sprintf("%s/v1/%s%s", provider.address, secretURL.Host, secretURL.Path), nil)
+	if err != nil {
+		return "", errors.Wrapf(err, "vault secret %s", valueFrom)
+	}
+	req.Header.Set(vaultTokenHeader, strings.TrimSpace(string(token)))
+
+	resp, err := provider.client.Do(req)
+	if err != nil {
+		return "", errors.Wrapf(err, "vault secret %s", valueFrom)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.Errorf("vault secret %s: unexpected status code %d", valueFrom, resp.StatusCode)
+	}
+
+	var secret vaultSecretResponse
+	err = json.NewDecoder(io.LimitReader(resp.Body, maxVaultResponseSize)).Decode(&secret)
+	if err != nil {
+		return "", errors.Wrapf(err, "vault secret %s: unable to decode response", valueFrom)
+	}
+	return getVaultSecretKey(secret.Data, key, valueFrom)
+}
+
+// getVaultSecretKey returns the value of key in the data of a Vault secret.
+// Secrets of the version 2 of the key/value secrets engine nest their data in
+// another data field, next to their metadata.
+func getVaultSecretKey(data map[string]interface{}, key string, valueFrom string) (string, error) {
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		if _, ok := data["metadata"]; ok {
+			data = nested
+		}
+	}
+
+	value, ok := data[key]
+	if !ok {
+		return "", errors.Errorf("vault secret %s: key not found", valueFrom)
+	}
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	// Values that aren't strings are passed as their JSON representation
+	b, err := json.Marshal(value)
+	if err != nil {
+		return "", errors.Wrapf(err, "vault secret %s", valueFrom)
+	}
+	return string(b), nil
+}
@@ -24,6 +24,15 @@ const (
 	// ExitTerminal indicates the agent has exited unsuccessfully, but should
 	// not be restarted
 	ExitTerminal = 5
+	// ExitPreflightDocker, ExitPreflightCgroup, ExitPreflightNetwork,
+	// ExitPreflightDisk and ExitPreflightCredentials indicate the agent failed
+	// the startup preflight check of the corresponding class. Like ExitError,
+	// the agent should be restarted
+	ExitPreflightDocker      = 10
+	ExitPreflightCgroup      = 11
+	ExitPreflightNetwork     = 12
+	ExitPreflightDisk        = 13
+	ExitPreflightCredentials = 14
 	// ExitUpdate indicates that the agent has written an update file to the
 	// configured location and this file should be used instead when restarting
 	// the agent
@@ -37,3 +37,8 @@ func (nsm *NoopStateManager) ForceSave() error {
 func (nsm *NoopStateManager) Load() error {
 	return nil
 }
+
+// Snapshot does nothing, successfully
+func (nsm *NoopStateManager) Snapshot() ([]byte, error) {
+	return nil, nil
+}
@@ -26,6 +26,7 @@ import (
 	"time"
 
 	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/errorbudget"
 	"github.com/aws/amazon-ecs-agent/agent/logger"
 	"github.com/aws/amazon-ecs-agent/agent/metrics"
 )
@@ -96,8 +97,43 @@ const (
 	//	 b) Add 'Region', 'ExecutionCredentialsID', 'ExternalConfigType', 'ExternalConfigValue' and 'NetworkMode' to
 	//     firelens task resource.
 	// 25) Add `seqNumTaskManifest` int field
-
-	ECSDataVersion = 25
+	// 26) Add 'providersecret' field to 'resources'
+	// 27) Add 'PinnedImageDigests' field to 'apitask.Task'
+	// 28) Add 'RestartPolicy' and 'RestartCount' fields to 'apicontainer.Container'
+	// 29) Add 'RestartTimes' field to 'apitask.Task'
+	// 30) Add 'ImagePullInfo' field to 'apicontainer.Container'
+	// 31) Add 'ManagedAgents' field to 'apicontainer.Container'
+	// 32) Add 'dockerNetwork' task resource
+	// 33) Add 'Experiments' field to 'apitask.Task'
+	// 34) Add 'efs' task resource and 'efs' volume type
+	// 35) Add 'StaticName' field to 'apitask.Task'
+	// 36) Add 'Tmpfs' and 'SharedMemorySize' fields to 'apicontainer.Container'
+	// 37) Add 'gpuComputeMode' field to 'apitask.Task'
+	// 38) Add 'Devices' field to 'apicontainer.Container'
+	// 39) Add 'KernelCapabilities' field to 'apicontainer.Container'
+	// 40)
+	//   a) Add 'CPUBurst' field to 'apitask.Task'
+	//   b) Add 'cpuBurst' field to the cgroup resource
+	// 41) Add 'InitProcessEnabled' field to 'apicontainer.Container'
+	// 42) Add 'Ulimits' field to 'apicontainer.Container'
+	// 43) Add 'EntryPoint' field to 'apicontainer.ContainerOverrides'
+	// 44) Add 'MaxSwap' and 'Swappiness' fields to 'apicontainer.Container'
+	// 45) Add 'PseudoTerminal' and 'Interactive' fields to 'apicontainer.Container'
+	// 46) Add 'SystemControls' field to 'apicontainer.Container'
+	// 47) Add 'ResourceRequirements' field to 'apicontainer.Container'
+	// 48) Add 'SatisfiedStatuses' field to 'apicontainer.ResourceDependency'
+	// 49) Add 'EphemeralStorage' field to 'apitask.Task'
+	// 50) Add 'Errors', 'DeletionFailures' and 'QuarantinedAt' fields to 'image.ImageState'
+	// 51) Add 'RuntimeSettings' and 'RuntimeSettingsVersion' fields to 'apitask.Task'
+	// 52) Add 'CleanupWaitDurationSeconds' field to 'apitask.Task'
+	// 53) Add 'SubnetGatewayIPV6Address' field to 'apieni.ENI'
+	// 54) Add 'NetworkBandwidth' field to 'apitask.Task'
+	// 55) Add 'DNSConfig' field to 'apitask.Task'
+	// 56) Add 'BridgeNetworkName' field to 'apitask.Task'
+	// 57) Add 'DynamicHostPorts' field to 'apicontainer.Container'
+	// 58) Add 'OOMScorePolicy' field to 'apitask.Task'
+
+	ECSDataVersion = 58
 
 	// ecsDataFile specifies the filename in the ECS_DATADIR
 	ecsDataFile = "ecs_agent_data.json"
@@ -154,6 +190,9 @@ type platformDependencies interface{}
 type StateManager interface {
 	Saver
 	Load() error
+	// Snapshot returns the state as it would be saved to disk, without
+	// saving it
+	Snapshot() ([]byte, error)
 }
 
 type basicStateManager struct {
@@ -259,9 +298,25 @@ func (manager *basicStateManager) ForceSave() error {
 	data, err := json.Marshal(s)
 	if err != nil {
 		log.Error("Error saving state; could not marshal data; this is odd", "err", err)
+		errorbudget.Global.RecordFailure(errorbudget.SourceStateSave, err)
+		return err
+	}
+	if err := manager.writeFile(data); err != nil {
+		errorbudget.Global.RecordFailure(errorbudget.SourceStateSave, err)
 		return err
 	}
-	return manager.writeFile(data)
+	return nil
+}
+
+// Snapshot returns the JSON encoding of the state, as ForceSave would write it
+// to disk
+func (manager *basicStateManager) Snapshot() ([]byte, error) {
+	manager.savingLock.Lock()
+	defer manager.savingLock.Unlock()
+	s := manager.state
+	s.Version = ECSDataVersion
+
+	return json.Marshal(s)
 }
 
 // Load reads state off the disk from the well-known filepath and loads it into
@@ -16,6 +16,7 @@
 package statemanager_test
 
 import (
+	"encoding/json"
 	"path/filepath"
 	"testing"
 	"time"
@@ -37,6 +38,23 @@ func TestStateManagerNonexistantDirectory(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestStateManagerSnapshot(t *testing.T) {
+	cfg := &config.Config{DataDir: "."}
+	cluster := "test"
+	stateManager, err := statemanager.NewStateManager(cfg, statemanager.AddSaveable("Cluster", &cluster))
+	require.NoError(t, err)
+
+	data, err := stateManager.Snapshot()
+	assert.NoError(t, err)
+	var snapshot struct {
+		Data    map[string]string
+		Version int
+	}
+	assert.NoError(t, json.Unmarshal(data, &snapshot))
+	assert.Equal(t, "test", snapshot.Data["Cluster"])
+	assert.Equal(t, statemanager.ECSDataVersion, snapshot.Version)
+}
+
 func TestLoadsV1DataCorrectly(t *testing.T) {
 	cleanup, err := setupWindowsTest(filepath.Join(".", "testdata", "v1", "1", "ecs_agent_data.json"))
 	require.Nil(t, err, "Failed to set up test")
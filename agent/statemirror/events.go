@@ -0,0 +1,106 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package statemirror
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi"
+	"github.com/aws/amazon-ecs-agent/agent/eventstream"
+)
+
+const (
+	// maxTraceEvents is the number of recent container events mirrored
+	maxTraceEvents = 200
+
+	containerChangeHandler = "StateMirrorContainerChangeHandler"
+)
+
+// traceEvent is a container event received by the agent
+type traceEvent struct {
+	Time     time.Time `json:"time"`
+	DockerID string    `json:"dockerId"`
+	Status   string    `json:"status"`
+	Type     string    `json:"type"`
+	ExitCode *int      `json:"exitCode,omitempty"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// eventTrace keeps the most recent container events
+type eventTrace struct {
+	lock   sync.Mutex
+	size   int
+	buffer []traceEvent
+	next   int
+}
+
+func newEventTrace(size int) *eventTrace {
+	return &eventTrace{
+		size:   size,
+		buffer: make([]traceEvent, 0, size),
+	}
+}
+
+// record adds the event to the trace, replacing the oldest event once the
+// trace is full
+func (trace *eventTrace) record(event traceEvent) {
+	trace.lock.Lock()
+	defer trace.lock.Unlock()
+
+	if len(trace.buffer) < trace.size {
+		trace.buffer = append(trace.buffer, event)
+		return
+	}
+	trace.buffer[trace.next] = event
+	trace.next = (trace.next + 1) % trace.size
+}
+
+// events returns the events of the trace, oldest first
+func (trace *eventTrace) events() []traceEvent {
+	trace.lock.Lock()
+	defer trace.lock.Unlock()
+
+	events := make([]traceEvent, 0, len(trace.buffer))
+	events = append(events, trace.buffer[trace.next:]...)
+	return append(events, trace.buffer[:trace.next]...)
+}
+
+// Subscribe records the container events of the event stream in the trace
+func (mirror *Mirror) Subscribe(containerChangeEventStream *eventstream.EventStream) error {
+	return containerChangeEventStream.Subscribe(containerChangeHandler, mirror.handleDockerEvents)
+}
+
+func (mirror *Mirror) handleDockerEvents(events ...interface{}) error {
+	for _, event := range events {
+		dockerContainerChangeEvent, ok := event.(dockerapi.DockerContainerChangeEvent)
+		if !ok {
+			return fmt.Errorf("Unexpected event received, expected docker container change event")
+		}
+
+		traced := traceEvent{
+			Time:     time.Now(),
+			DockerID: dockerContainerChangeEvent.DockerID,
+			Status:   dockerContainerChangeEvent.Status.String(),
+			Type:     dockerContainerChangeEvent.Type.String(),
+			ExitCode: dockerContainerChangeEvent.ExitCode,
+		}
+		if dockerContainerChangeEvent.Error != nil {
+			traced.Error = dockerContainerChangeEvent.Error.Error()
+		}
+		mirror.trace.record(traced)
+	}
+	return nil
+}
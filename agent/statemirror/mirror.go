@@ -0,0 +1,156 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package statemirror mirrors the state of the agent and the container events
+// it recently received to S3, so that when an instance dies unexpectedly the
+// last state of its agent can still be examined
+package statemirror
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"path"
+	"strings"
+	"time"
+
+	apierrors "github.com/aws/amazon-ecs-agent/agent/api/errors"
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	s3client "github.com/aws/amazon-ecs-agent/agent/s3"
+	s3factory "github.com/aws/amazon-ecs-agent/agent/s3/factory"
+	"github.com/aws/amazon-ecs-agent/agent/statemanager"
+	awscreds "github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/cihub/seelog"
+)
+
+const (
+	// StateObjectName and EventsObjectName are the names of the objects the
+	// state and the recent container events are mirrored to, under the
+	// prefix of the container instance
+	StateObjectName  = "state.json"
+	EventsObjectName = "events.json"
+
+	// uploadTimeout is the time an upload is allowed to take while the agent
+	// is running
+	uploadTimeout = 1 * time.Minute
+	// finalUploadTimeout is the time an upload is allowed to take at
+	// shutdown. It's kept under the time the final save is allowed to take
+	finalUploadTimeout = 2 * time.Second
+)
+
+// Mirror periodically uploads snapshots of the state of the agent and of the
+// container events it recently received to S3
+type Mirror struct {
+	bucket       string
+	prefix       string
+	interval     time.Duration
+	stateManager statemanager.StateManager
+	uploader     s3client.S3Uploader
+	trace        *eventTrace
+}
+
+// NewMirror creates a Mirror uploading to the prefix of cfg.StateMirrorS3ARN
+// for the container instance, using the credentials of the instance
+func NewMirror(cfg *config.Config, containerInstanceARN string, stateManager statemanager.StateManager,
+	s3ClientCreator s3factory.S3ClientCreator, credentialProvider *awscreds.Credentials) (*Mirror, error) {
+	bucket, prefix, err := s3client.ParseS3ARN(cfg.StateMirrorS3ARN)
+	if err != nil {
+		return nil, err
+	}
+	uploader, err := s3ClientCreator.NewS3UploaderForBucket(bucket, cfg.AWSRegion, credentialProvider)
+	if err != nil {
+		return nil, err
+	}
+	return &Mirror{
+		bucket:       bucket,
+		prefix:       path.Join(prefix, containerInstanceID(containerInstanceARN)),
+		interval:     cfg.StateMirrorInterval,
+		stateManager: stateManager,
+		uploader:     uploader,
+		trace:        newEventTrace(maxTraceEvents),
+	}, nil
+}
+
+// containerInstanceID returns the id at the end of the container instance ARN
+func containerInstanceID(containerInstanceARN string) string {
+	return containerInstanceARN[strings.LastIndex(containerInstanceARN, "/")+1:]
+}
+
+// Start mirrors the state every interval until the context is cancelled
+func (mirror *Mirror) Start(ctx context.Context) {
+	ticker := time.NewTicker(mirror.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := mirror.mirror(uploadTimeout); err != nil {
+				seelog.Warnf("State mirror: unable to mirror state to s3://%s/%s: %v",
+					mirror.bucket, mirror.prefix, err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// mirror uploads a snapshot of the state and of the recent container events
+func (mirror *Mirror) mirror(timeout time.Duration) error {
+	state, err := mirror.stateManager.Snapshot()
+	if err != nil {
+		return err
+	}
+	events, err := json.Marshal(mirror.trace.events())
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for name, data := range map[string][]byte{StateObjectName: state, EventsObjectName: events} {
+		if err := s3client.UploadFile(mirror.bucket, path.Join(mirror.prefix, name), timeout,
+			bytes.NewReader(data), mirror.uploader); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return apierrors.NewMultiError(errs...)
+	}
+	seelog.Debugf("State mirror: mirrored state to s3://%s/%s", mirror.bucket, mirror.prefix)
+	return nil
+}
+
+// Saver returns a Saver that mirrors the state after each ForceSave of the
+// saver, so that the final state of the agent is mirrored at shutdown
+func (mirror *Mirror) Saver(saver statemanager.Saver) statemanager.Saver {
+	return &mirroringSaver{
+		Saver:  saver,
+		mirror: mirror,
+	}
+}
+
+type mirroringSaver struct {
+	statemanager.Saver
+	mirror *Mirror
+}
+
+// ForceSave saves the state, then mirrors it. Failing to mirror the state
+// doesn't fail the save
+func (saver *mirroringSaver) ForceSave() error {
+	if err := saver.Saver.ForceSave(); err != nil {
+		return err
+	}
+	if err := saver.mirror.mirror(finalUploadTimeout); err != nil {
+		seelog.Warnf("State mirror: unable to mirror final state to s3://%s/%s: %v",
+			saver.mirror.bucket, saver.mirror.prefix, err)
+	}
+	return nil
+}
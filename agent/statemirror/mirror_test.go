@@ -0,0 +1,128 @@
+// +build unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package statemirror
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apicontainerstatus "github.com/aws/amazon-ecs-agent/agent/api/container/status"
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi"
+	mock_factory "github.com/aws/amazon-ecs-agent/agent/s3/factory/mocks"
+	mock_s3 "github.com/aws/amazon-ecs-agent/agent/s3/mocks"
+	mock_statemanager "github.com/aws/amazon-ecs-agent/agent/statemanager/mocks"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testContainerInstanceARN = "arn:aws:ecs:us-west-2:123456789012:container-instance/cluster/abcdef"
+
+func newTestMirror(t *testing.T, ctrl *gomock.Controller) (*Mirror, *mock_statemanager.MockStateManager,
+	*mock_s3.MockS3Uploader) {
+	stateManager := mock_statemanager.NewMockStateManager(ctrl)
+	uploader := mock_s3.NewMockS3Uploader(ctrl)
+	s3ClientCreator := mock_factory.NewMockS3ClientCreator(ctrl)
+	s3ClientCreator.EXPECT().NewS3UploaderForBucket("bucket", "us-west-2", nil).Return(uploader, nil)
+
+	cfg := &config.Config{
+		AWSRegion:           "us-west-2",
+		StateMirrorS3ARN:    "arn:aws:s3:::bucket/mirror",
+		StateMirrorInterval: time.Minute,
+	}
+	mirror, err := NewMirror(cfg, testContainerInstanceARN, stateManager, s3ClientCreator, nil)
+	require.NoError(t, err)
+	return mirror, stateManager, uploader
+}
+
+func TestMirror(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mirror, stateManager, uploader := newTestMirror(t, ctrl)
+
+	exitCode := 1
+	assert.NoError(t, mirror.handleDockerEvents(dockerapi.DockerContainerChangeEvent{
+		Status: apicontainerstatus.ContainerStopped,
+		DockerContainerMetadata: dockerapi.DockerContainerMetadata{
+			DockerID: "docker-id",
+			ExitCode: &exitCode,
+		},
+		Type: apicontainer.ContainerStatusEvent,
+	}))
+
+	uploaded := make(map[string][]byte)
+	stateManager.EXPECT().Snapshot().Return([]byte(`{"Version":31}`), nil)
+	uploader.EXPECT().UploadWithContext(gomock.Any(), gomock.Any()).Do(func(ctx aws.Context,
+		input *s3manager.UploadInput) {
+		assert.Equal(t, "bucket", aws.StringValue(input.Bucket))
+		data, err := ioutil.ReadAll(input.Body)
+		assert.NoError(t, err)
+		uploaded[aws.StringValue(input.Key)] = data
+	}).Times(2)
+
+	assert.NoError(t, mirror.mirror(uploadTimeout))
+	assert.Equal(t, `{"Version":31}`, string(uploaded["mirror/abcdef/"+StateObjectName]))
+	var events []traceEvent
+	require.NoError(t, json.Unmarshal(uploaded["mirror/abcdef/"+EventsObjectName], &events))
+	require.Len(t, events, 1)
+	assert.Equal(t, "docker-id", events[0].DockerID)
+	assert.Equal(t, apicontainerstatus.ContainerStopped.String(), events[0].Status)
+	assert.Equal(t, 1, aws.IntValue(events[0].ExitCode))
+}
+
+func TestMirrorSaverIgnoresUploadErrors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mirror, stateManager, uploader := newTestMirror(t, ctrl)
+
+	gomock.InOrder(
+		stateManager.EXPECT().ForceSave().Return(nil),
+		stateManager.EXPECT().Snapshot().Return([]byte(`{}`), nil),
+	)
+	uploader.EXPECT().UploadWithContext(gomock.Any(), gomock.Any()).Return(nil, errors.New("access denied")).Times(2)
+
+	assert.NoError(t, mirror.Saver(stateManager).ForceSave())
+}
+
+func TestMirrorSaverSaveError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mirror, stateManager, _ := newTestMirror(t, ctrl)
+
+	stateManager.EXPECT().ForceSave().Return(errors.New("disk full"))
+
+	assert.Error(t, mirror.Saver(stateManager).ForceSave())
+}
+
+func TestEventTrace(t *testing.T) {
+	trace := newEventTrace(3)
+	for _, id := range []string{"1", "2", "3", "4", "5"} {
+		trace.record(traceEvent{DockerID: id})
+	}
+
+	var ids []string
+	for _, event := range trace.events() {
+		ids = append(ids, event.DockerID)
+	}
+	assert.Equal(t, []string{"3", "4", "5"}, ids)
+}
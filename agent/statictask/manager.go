@@ -0,0 +1,235 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package statictask runs the static tasks of the instance, the tasks defined
+// in a local directory that the agent runs independently of ECS and starts
+// again when they stop, e.g. to bootstrap observability daemons
+package statictask
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/acs/model/ecsacs"
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	apitaskstatus "github.com/aws/amazon-ecs-agent/agent/api/task/status"
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/engine"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/private/protocol/json/jsonutil"
+	"github.com/cihub/seelog"
+	"github.com/pkg/errors"
+)
+
+const (
+	// taskFileExtension is the extension of the files of the static tasks
+	taskFileExtension = ".json"
+	// taskIDPrefix prefixes the ids of the static tasks, to tell them apart
+	// from the tasks of ECS
+	taskIDPrefix = "static-"
+	// supervisionInterval is how often the static tasks that stopped are
+	// started again
+	supervisionInterval = 30 * time.Second
+)
+
+// validTaskName matches the names of the static tasks, which are part of the
+// ids of their tasks
+var validTaskName = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// Manager starts the static tasks and starts them again when they stop
+type Manager struct {
+	taskEngine engine.TaskEngine
+	// arnPrefix is the prefix of the ARNs of the static tasks, which are in
+	// the region and account of the container instance
+	arnPrefix string
+	// tasks are the definitions of the static tasks, by name
+	tasks    map[string]*ecsacs.Task
+	interval time.Duration
+}
+
+// NewManager creates a Manager for the static tasks of the directory. Each
+// <name>.json file of the directory holds a task, in the format of the tasks
+// of the ACS payload messages
+func NewManager(dir string, containerInstanceARN string, taskEngine engine.TaskEngine) (*Manager, error) {
+	parsedARN, err := arn.Parse(containerInstanceARN)
+	if err != nil {
+		return nil, errors.Wrapf(err, "static tasks: malformed container instance arn: %s", containerInstanceARN)
+	}
+	tasks, err := loadTasks(dir)
+	if err != nil {
+		return nil, err
+	}
+	return &Manager{
+		taskEngine: taskEngine,
+		arnPrefix: fmt.Sprintf("arn:%s:ecs:%s:%s:task/%s",
+			parsedARN.Partition, parsedARN.Region, parsedARN.AccountID, taskIDPrefix),
+		tasks:    tasks,
+		interval: supervisionInterval,
+	}, nil
+}
+
+// ReserveResources adds the CPU, memory and host ports of the static tasks of
+// the configured directory to the resources the agent reserves, so that ECS
+// doesn't place its tasks on the share of the instance the static tasks use
+func ReserveResources(cfg *config.Config) error {
+	tasks, err := loadTasks(cfg.StaticTasksDir)
+	if err != nil {
+		return err
+	}
+	var cpu, memory int64
+	var tcpPorts, udpPorts []uint16
+	for name, definition := range tasks {
+		task, err := apitask.TaskFromACS(definition, &ecsacs.PayloadMessage{})
+		if err != nil {
+			return errors.Wrapf(err, "static tasks: invalid task %s", name)
+		}
+		taskCPU, taskMemory := task.CPUAndMemory()
+		cpu += taskCPU
+		memory += taskMemory
+		for _, container := range task.Containers {
+			for _, binding := range container.Ports {
+				if binding.HostPort == 0 {
+					continue
+				}
+				if binding.Protocol == apicontainer.TransportProtocolUDP {
+					udpPorts = append(udpPorts, binding.HostPort)
+				} else {
+					tcpPorts = append(tcpPorts, binding.HostPort)
+				}
+			}
+		}
+	}
+
+	reservedMemory := int64(cfg.ReservedMemory) + memory
+	if reservedMemory > math.MaxUint16 {
+		return errors.Errorf("static tasks: reserved memory of %d MiB, including %d MiB of the static tasks, "+
+			"is higher than the maximum of %d MiB", reservedMemory, memory, math.MaxUint16)
+	}
+	cfg.ReservedCPU += cpu
+	cfg.ReservedMemory = uint16(reservedMemory)
+	cfg.ReservedPorts = appendMissingPorts(cfg.ReservedPorts, tcpPorts)
+	cfg.ReservedPortsUDP = appendMissingPorts(cfg.ReservedPortsUDP, udpPorts)
+	return nil
+}
+
+// appendMissingPorts appends the ports that aren't in the reserved ports yet
+func appendMissingPorts(reserved []uint16, ports []uint16) []uint16 {
+	for _, port := range ports {
+		found := false
+		for _, reservedPort := range reserved {
+			if port == reservedPort {
+				found = true
+				break
+			}
+		}
+		if !found {
+			reserved = append(reserved, port)
+		}
+	}
+	return reserved
+}
+
+// loadTasks reads the definitions of the static tasks of the directory
+func loadTasks(dir string) (map[string]*ecsacs.Task, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "static tasks: unable to read directory %s", dir)
+	}
+	tasks := make(map[string]*ecsacs.Task)
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != taskFileExtension {
+			continue
+		}
+		name := strings.TrimSuffix(file.Name(), taskFileExtension)
+		if !validTaskName.MatchString(name) {
+			return nil, errors.Errorf("static tasks: invalid name of task file %s, only letters, digits, "+
+				"'-' and '_' are allowed", file.Name())
+		}
+		data, err := ioutil.ReadFile(filepath.Join(dir, file.Name()))
+		if err != nil {
+			return nil, errors.Wrapf(err, "static tasks: unable to read task file %s", file.Name())
+		}
+		task := &ecsacs.Task{}
+		if err := jsonutil.UnmarshalJSON(task, bytes.NewReader(data)); err != nil {
+			return nil, errors.Wrapf(err, "static tasks: unable to parse task file %s", file.Name())
+		}
+		tasks[name] = task
+	}
+	return tasks, nil
+}
+
+// Start starts the static tasks, then starts again the ones that stopped
+// every interval until the context is cancelled
+func (manager *Manager) Start(ctx context.Context) {
+	manager.startStoppedTasks()
+	ticker := time.NewTicker(manager.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			manager.startStoppedTasks()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// startStoppedTasks adds a new task to the engine for each of the static tasks
+// the engine isn't running, including the ones it ran before the agent
+// restarted
+func (manager *Manager) startStoppedTasks() {
+	tasks, err := manager.taskEngine.ListTasks()
+	if err != nil {
+		seelog.Errorf("Static tasks: unable to list the tasks of the engine: %v", err)
+		return
+	}
+	running := make(map[string]struct{})
+	for _, task := range tasks {
+		if task.IsStatic() && task.GetDesiredStatus() != apitaskstatus.TaskStopped {
+			running[task.StaticName] = struct{}{}
+		}
+	}
+
+	for name, definition := range manager.tasks {
+		if _, ok := running[name]; ok {
+			continue
+		}
+		task, err := manager.newTask(name, definition)
+		if err != nil {
+			seelog.Errorf("Static tasks: unable to create task %s: %v", name, err)
+			continue
+		}
+		seelog.Infof("Static tasks: starting task %s as %s", name, task.Arn)
+		manager.taskEngine.AddTask(task)
+	}
+}
+
+// newTask creates a task running the static task, with an ARN of its own
+func (manager *Manager) newTask(name string, definition *ecsacs.Task) (*apitask.Task, error) {
+	task, err := apitask.TaskFromACS(definition, &ecsacs.PayloadMessage{})
+	if err != nil {
+		return nil, err
+	}
+	task.Arn = fmt.Sprintf("%s%s-%d", manager.arnPrefix, name, time.Now().UnixNano())
+	task.StaticName = name
+	task.SetDesiredStatus(apitaskstatus.TaskRunning)
+	return task, nil
+}
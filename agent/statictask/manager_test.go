@@ -0,0 +1,175 @@
+// +build unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package statictask
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	apitaskstatus "github.com/aws/amazon-ecs-agent/agent/api/task/status"
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	mock_engine "github.com/aws/amazon-ecs-agent/agent/engine/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testContainerInstanceARN = "arn:aws:ecs:us-west-2:123456789012:container-instance/cluster/abcdef"
+	testTaskJSON             = `{
+	"family": "daemon",
+	"version": "1",
+	"desiredStatus": "RUNNING",
+	"containers": [{"name": "agent", "image": "amazon/cloudwatch-agent:latest", "essential": true}]
+}`
+)
+
+func writeTaskFiles(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "static-tasks")
+	require.NoError(t, err)
+	for name, content := range files {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+	return dir
+}
+
+func TestNewManager(t *testing.T) {
+	dir := writeTaskFiles(t, map[string]string{
+		"daemon.json": testTaskJSON,
+		"README":      "not a task",
+	})
+	defer os.RemoveAll(dir)
+
+	manager, err := NewManager(dir, testContainerInstanceARN, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "arn:aws:ecs:us-west-2:123456789012:task/static-", manager.arnPrefix)
+	require.Len(t, manager.tasks, 1)
+	require.Len(t, manager.tasks["daemon"].Containers, 1)
+	assert.Equal(t, "amazon/cloudwatch-agent:latest", *manager.tasks["daemon"].Containers[0].Image)
+}
+
+func TestNewManagerInvalidTaskName(t *testing.T) {
+	dir := writeTaskFiles(t, map[string]string{"my daemon.json": testTaskJSON})
+	defer os.RemoveAll(dir)
+
+	_, err := NewManager(dir, testContainerInstanceARN, nil)
+	assert.Error(t, err)
+}
+
+func TestNewManagerInvalidTaskFile(t *testing.T) {
+	dir := writeTaskFiles(t, map[string]string{"daemon.json": "{"})
+	defer os.RemoveAll(dir)
+
+	_, err := NewManager(dir, testContainerInstanceARN, nil)
+	assert.Error(t, err)
+}
+
+func TestNewManagerMissingDirectory(t *testing.T) {
+	_, err := NewManager("/does/not/exist", testContainerInstanceARN, nil)
+	assert.Error(t, err)
+}
+
+func TestStartStoppedTasks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	taskEngine := mock_engine.NewMockTaskEngine(ctrl)
+
+	dir := writeTaskFiles(t, map[string]string{
+		"running.json": testTaskJSON,
+		"stopped.json": testTaskJSON,
+		"new.json":     testTaskJSON,
+	})
+	defer os.RemoveAll(dir)
+	manager, err := NewManager(dir, testContainerInstanceARN, taskEngine)
+	require.NoError(t, err)
+
+	taskEngine.EXPECT().ListTasks().Return([]*apitask.Task{
+		{Arn: "running", StaticName: "running", DesiredStatusUnsafe: apitaskstatus.TaskRunning},
+		{Arn: "stopped", StaticName: "stopped", DesiredStatusUnsafe: apitaskstatus.TaskStopped},
+		{Arn: "ecs", DesiredStatusUnsafe: apitaskstatus.TaskRunning},
+	}, nil)
+	var started []string
+	taskEngine.EXPECT().AddTask(gomock.Any()).Do(func(task *apitask.Task) {
+		assert.True(t, strings.HasPrefix(task.Arn, "arn:aws:ecs:us-west-2:123456789012:task/static-"+task.StaticName+"-"))
+		assert.Equal(t, apitaskstatus.TaskRunning, task.GetDesiredStatus())
+		assert.Equal(t, "daemon", task.Family)
+		started = append(started, task.StaticName)
+	}).Times(2)
+
+	manager.startStoppedTasks()
+	assert.ElementsMatch(t, []string{"stopped", "new"}, started)
+}
+
+func TestReserveResources(t *testing.T) {
+	dir := writeTaskFiles(t, map[string]string{
+		"daemon.json": testTaskJSON,
+		"proxy.json": `{
+	"family": "proxy",
+	"version": "1",
+	"desiredStatus": "RUNNING",
+	"containers": [{"name": "envoy", "image": "envoy:latest", "cpu": 256, "memory": 512, "portMappings": [
+		{"containerPort": 8080, "hostPort": 80},
+		{"containerPort": 8053, "hostPort": 53, "protocol": "udp"},
+		{"containerPort": 9901}
+	]}]
+}`,
+		"collector.json": `{
+	"family": "collector",
+	"version": "1",
+	"desiredStatus": "RUNNING",
+	"cpu": 0.5,
+	"memory": 1024,
+	"containers": [{"name": "collector", "image": "collector:latest", "cpu": 128, "memory": 256, "portMappings": [
+		{"containerPort": 22, "hostPort": 22}
+	]}]
+}`,
+	})
+	defer os.RemoveAll(dir)
+
+	cfg := &config.Config{
+		StaticTasksDir:   dir,
+		ReservedMemory:   100,
+		ReservedPorts:    []uint16{22, 2375},
+		ReservedPortsUDP: []uint16{},
+	}
+	require.NoError(t, ReserveResources(cfg))
+	// The task level limits of the collector task apply over the limits of
+	// its containers
+	assert.Equal(t, int64(256+512), cfg.ReservedCPU)
+	assert.Equal(t, uint16(100+512+1024), cfg.ReservedMemory)
+	assert.Equal(t, []uint16{22, 2375, 80}, cfg.ReservedPorts)
+	assert.Equal(t, []uint16{53}, cfg.ReservedPortsUDP)
+}
+
+func TestReserveResourcesTooMuchMemory(t *testing.T) {
+	dir := writeTaskFiles(t, map[string]string{"daemon.json": `{
+	"family": "daemon",
+	"version": "1",
+	"desiredStatus": "RUNNING",
+	"memory": 65536,
+	"containers": [{"name": "daemon", "image": "daemon:latest"}]
+}`})
+	defer os.RemoveAll(dir)
+
+	cfg := &config.Config{StaticTasksDir: dir, ReservedMemory: 100}
+	assert.Error(t, ReserveResources(cfg))
+	assert.Equal(t, uint16(100), cfg.ReservedMemory)
+	assert.Zero(t, cfg.ReservedCPU)
+}
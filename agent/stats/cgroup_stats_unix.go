@@ -0,0 +1,550 @@
+// +build !windows
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stats
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/dockerclient"
+	"github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi"
+	"github.com/cihub/seelog"
+	"github.com/docker/docker/api/types"
+	"github.com/pkg/errors"
+)
+
+const (
+	// hostProcPath is the path of the proc filesystem of the instance, as seen
+	// from the agent container
+	hostProcPath = "/host/proc"
+	// unifiedControllersFile is the file listing the controllers at the root
+	// of the cgroup v2 unified hierarchy, which doesn't exist with cgroup v1
+	unifiedControllersFile = "cgroup.controllers"
+	// unifiedCgroupController is the key of the cgroup of a process in the
+	// cgroup v2 unified hierarchy
+	unifiedCgroupController = ""
+	// clockTicksPerSecond is the USER_HZ unit of the cpu times of /proc/stat
+	clockTicksPerSecond = 100
+	// procStatCPUFields is the number of fields of the cpu line of /proc/stat
+	// that add up to the cpu time of the instance: user, nice, system, idle,
+	// iowait, irq and softirq
+	procStatCPUFields = 7
+	// unlimitedCgroupValue is the value of the cgroup v2 limits that aren't set
+	unlimitedCgroupValue = "max"
+	// containerNetworkModePrefix is the prefix of the network mode of the
+	// containers joining the network namespace of another container
+	containerNetworkModePrefix = "container:"
+)
+
+// cgroupStats reads the usage of containers from the files of their cgroups,
+// instead of holding a docker stats stream for each container
+type cgroupStats struct {
+	client dockerapi.DockerClient
+	// cgroupPath is where the cgroup hierarchies are mounted
+	cgroupPath string
+	// procPath is where the proc filesystem of the instance is mounted
+	procPath string
+	// interval is how often the usage of each container is read
+	interval time.Duration
+}
+
+// containerCgroups are the cgroups of a container
+type containerCgroups struct {
+	dockerID string
+	pid      int
+	// paths maps the controllers to the cgroup of the container, relative to
+	// the root of their hierarchy
+	paths map[string]string
+	// unified is true if the cgroups are in the cgroup v2 unified hierarchy
+	unified bool
+	// readNetwork is true if the container has its own network namespace,
+	// whose interfaces are reported
+	readNetwork bool
+}
+
+// newCgroupStats creates the reader of the usage of the containers from their
+// cgroups. The usage is read every second, as often as docker streams it,
+// unless the metrics are polled
+func newCgroupStats(cfg *config.Config, client dockerapi.DockerClient) *cgroupStats {
+	interval := SleepBetweenUsageDataCollection
+	if cfg.PollMetrics {
+		interval = cfg.PollingMetricsWaitDuration
+	}
+	return &cgroupStats{
+		client:     client,
+		cgroupPath: cfg.CgroupPath,
+		procPath:   hostProcPath,
+		interval:   interval,
+	}
+}
+
+// Stats returns a channel of the usage of the container read from its cgroups,
+// in the docker stats format. The channel is closed when the usage can't be
+// read anymore, such as when the container stops, or the context is cancelled
+func (c *cgroupStats) Stats(ctx context.Context, metadata *ContainerMetadata) (<-chan *types.StatsJSON, error) {
+	statsChnl := make(chan *types.StatsJSON)
+	go func() {
+		defer close(statsChnl)
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+
+		var cgroups *containerCgroups
+		var previous *types.StatsJSON
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			if cgroups == nil {
+				var err error
+				cgroups, err = c.containerCgroups(ctx, metadata)
+				if err != nil {
+					seelog.Warnf("Unable to find the cgroups of container %s: %v", metadata.DockerID, err)
+					return
+				}
+			}
+			stats, err := c.read(cgroups, previous)
+			if err != nil {
+				seelog.Debugf("Unable to read the cgroup stats of container %s: %v", metadata.DockerID, err)
+				return
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case statsChnl <- stats:
+			}
+			previous = stats
+		}
+	}()
+	return statsChnl, nil
+}
+
+// containerCgroups returns the cgroups of the init process of the container
+func (c *cgroupStats) containerCgroups(ctx context.Context, metadata *ContainerMetadata) (*containerCgroups, error) {
+	dockerContainer, err := c.client.InspectContainer(ctx, metadata.DockerID, dockerclient.InspectContainerTimeout)
+	if err != nil {
+		return nil, err
+	}
+	if dockerContainer.State == nil || dockerContainer.State.Pid == 0 {
+		return nil, errors.New("container isn't running")
+	}
+	pid := dockerContainer.State.Pid
+
+	file, err := os.Open(filepath.Join(c.procPath, strconv.Itoa(pid), "cgroup"))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	paths, err := parseProcCgroup(file)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = os.Stat(filepath.Join(c.cgroupPath, unifiedControllersFile))
+	networkMode := metadata.NetworkMode
+	return &containerCgroups{
+		dockerID: metadata.DockerID,
+		pid:      pid,
+		paths:    paths,
+		unified:  err == nil,
+		readNetwork: networkMode != hostNetworkMode && networkMode != noneNetworkMode &&
+			!strings.HasPrefix(networkMode, containerNetworkModePrefix),
+	}, nil
+}
+
+// read returns the current usage of the container, with the previous usage
+// as the pre cpu stats like docker reports them
+func (c *cgroupStats) read(cgroups *containerCgroups, previous *types.StatsJSON) (*types.StatsJSON, error) {
+	stats := &types.StatsJSON{ID: cgroups.dockerID}
+	stats.Read = time.Now()
+	if previous != nil {
+		stats.PreRead = previous.Read
+		stats.PreCPUStats = previous.CPUStats
+	}
+
+	var err error
+	if cgroups.unified {
+		err = c.readUnified(cgroups, stats)
+	} else {
+		err = c.readV1(cgroups, stats)
+	}
+	if err != nil {
+		return nil, err
+	}
+	stats.CPUStats.OnlineCPUs = uint32(numCores)
+	stats.CPUStats.SystemUsage, err = c.systemCPUUsage()
+	if err != nil {
+		return nil, err
+	}
+
+	if cgroups.readNetwork {
+		stats.Networks, err = c.networks(cgroups.pid)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return stats, nil
+}
+
+// readV1 reads the usage of the container from its cgroup v1 cpuacct, memory
+// and blkio controllers
+func (c *cgroupStats) readV1(cgroups *containerCgroups, stats *types.StatsJSON) error {
+	cpuacct, err := c.controllerPath(cgroups, "cpuacct")
+	if err != nil {
+		return err
+	}
+	if stats.CPUStats.CPUUsage.TotalUsage, err = readCgroupUint(filepath.Join(cpuacct, "cpuacct.usage")); err != nil {
+		return err
+	}
+	if stats.CPUStats.CPUUsage.PercpuUsage, err = readCgroupUints(filepath.Join(cpuacct, "cpuacct.usage_percpu")); err != nil {
+		return err
+	}
+
+	memory, err := c.controllerPath(cgroups, "memory")
+	if err != nil {
+		return err
+	}
+	if stats.MemoryStats.Usage, err = readCgroupUint(filepath.Join(memory, "memory.usage_in_bytes")); err != nil {
+		return err
+	}
+	if stats.MemoryStats.MaxUsage, err = readCgroupUint(filepath.Join(memory, "memory.max_usage_in_bytes")); err != nil {
+		return err
+	}
+	if stats.MemoryStats.Limit, err = readCgroupUint(filepath.Join(memory, "memory.limit_in_bytes")); err != nil {
+		return err
+	}
+	if stats.MemoryStats.Stats, err = readCgroupKeyValues(filepath.Join(memory, "memory.stat")); err != nil {
+		return err
+	}
+
+	blkio, err := c.controllerPath(cgroups, "blkio")
+	if err != nil {
+		return err
+	}
+	if stats.BlkioStats.IoServiceBytesRecursive, err = readBlkioStats(
+		filepath.Join(blkio, "blkio.throttle.io_service_bytes_recursive")); err != nil {
+		return err
+	}
+	stats.BlkioStats.IoServicedRecursive, err = readBlkioStats(filepath.Join(blkio, "blkio.throttle.io_serviced_recursive"))
+	return err
+}
+
+// readUnified reads the usage of the container from its cgroup in the cgroup
+// v2 unified hierarchy
+func (c *cgroupStats) readUnified(cgroups *containerCgroups, stats *types.StatsJSON) error {
+	cgroup, err := c.controllerPath(cgroups, unifiedCgroupController)
+	if err != nil {
+		return err
+	}
+
+	cpuStat, err := readCgroupKeyValues(filepath.Join(cgroup, "cpu.stat"))
+	if err != nil {
+		return err
+	}
+	stats.CPUStats.CPUUsage.TotalUsage = cpuStat["usage_usec"] * uint64(time.Microsecond)
+	stats.CPUStats.CPUUsage.UsageInUsermode = cpuStat["user_usec"] * uint64(time.Microsecond)
+	stats.CPUStats.CPUUsage.UsageInKernelmode = cpuStat["system_usec"] * uint64(time.Microsecond)
+
+	if stats.MemoryStats.Usage, err = readCgroupUint(filepath.Join(cgroup, "memory.current")); err != nil {
+		return err
+	}
+	if stats.MemoryStats.Limit, err = readCgroupUint(filepath.Join(cgroup, "memory.max")); err != nil {
+		return err
+	}
+	if stats.MemoryStats.Stats, err = readCgroupKeyValues(filepath.Join(cgroup, "memory.stat")); err != nil {
+		return err
+	}
+	// The page cache is reported as file rather than cache with cgroup v2,
+	// it's copied so that it's excluded from the memory usage the same way
+	if _, ok := stats.MemoryStats.Stats["cache"]; !ok {
+		stats.MemoryStats.Stats["cache"] = stats.MemoryStats.Stats["file"]
+	}
+
+	file, err := os.Open(filepath.Join(cgroup, "io.stat"))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	stats.BlkioStats.IoServiceBytesRecursive, stats.BlkioStats.IoServicedRecursive, err = parseIOStat(file)
+	return err
+}
+
+// controllerPath returns the path of the cgroup of the container of the
+// controller
+func (c *cgroupStats) controllerPath(cgroups *containerCgroups, controller string) (string, error) {
+	path, ok := cgroups.paths[controller]
+	if !ok {
+		return "", errors.Errorf("no cgroup of controller %q", controller)
+	}
+	if controller == unifiedCgroupController {
+		return filepath.Join(c.cgroupPath, path), nil
+	}
+	return filepath.Join(c.cgroupPath, controller, path), nil
+}
+
+// systemCPUUsage returns the cpu time of the instance in nanoseconds, from the
+// cpu line of /proc/stat like docker computes it
+func (c *cgroupStats) systemCPUUsage() (uint64, error) {
+	file, err := os.Open(filepath.Join(c.procPath, "stat"))
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || fields[0] != "cpu" {
+			continue
+		}
+		if len(fields) < procStatCPUFields+1 {
+			return 0, errors.Errorf("invalid cpu stats: %q", scanner.Text())
+		}
+		ticks := uint64(0)
+		for _, field := range fields[1 : procStatCPUFields+1] {
+			value, err := strconv.ParseUint(field, 10, 64)
+			if err != nil {
+				return 0, errors.Wrapf(err, "invalid cpu stats: %q", scanner.Text())
+			}
+			ticks += value
+		}
+		return ticks * uint64(time.Second) / clockTicksPerSecond, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	return 0, errors.New("no cpu stats")
+}
+
+// networks returns the stats of the interfaces of the network namespace of
+// the process in the docker stats format
+func (c *cgroupStats) networks(pid int) (map[string]types.NetworkStats, error) {
+	file, err := os.Open(filepath.Join(c.procPath, strconv.Itoa(pid), "net", "dev"))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	interfaces, err := parseNetDevInterfaces(file)
+	if err != nil {
+		return nil, err
+	}
+	networks := make(map[string]types.NetworkStats, len(interfaces))
+	for name, interfaceStats := range interfaces {
+		networks[name] = types.NetworkStats{
+			RxBytes:   interfaceStats.RxBytes,
+			RxPackets: interfaceStats.RxPackets,
+			RxErrors:  interfaceStats.RxErrors,
+			RxDropped: interfaceStats.RxDropped,
+			TxBytes:   interfaceStats.TxBytes,
+			TxPackets: interfaceStats.TxPackets,
+			TxErrors:  interfaceStats.TxErrors,
+			TxDropped: interfaceStats.TxDropped,
+		}
+	}
+	return networks, nil
+}
+
+// parseProcCgroup returns the cgroups of a process listed in the
+// /proc/<pid>/cgroup format by controller. The cgroup of the cgroup v2
+// unified hierarchy is keyed by unifiedCgroupController
+func parseProcCgroup(r io.Reader) (map[string]string, error) {
+	paths := make(map[string]string)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		parts := strings.SplitN(scanner.Text(), ":", 3)
+		if len(parts) != 3 {
+			return nil, errors.Errorf("invalid cgroup: %q", scanner.Text())
+		}
+		if parts[1] == "" {
+			paths[unifiedCgroupController] = parts[2]
+			continue
+		}
+		for _, controller := range strings.Split(parts[1], ",") {
+			paths[controller] = parts[2]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return paths, nil
+}
+
+// readCgroupUint reads a cgroup file holding a single value. Limits that
+// aren't set are read as 0
+func readCgroupUint(path string) (uint64, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	value := strings.TrimSpace(string(content))
+	if value == unlimitedCgroupValue {
+		return 0, nil
+	}
+	return strconv.ParseUint(value, 10, 64)
+}
+
+// readCgroupUints reads a cgroup file holding a list of values, such as the
+// usage of each cpu
+func readCgroupUints(path string) ([]uint64, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var values []uint64
+	for _, field := range strings.Fields(string(content)) {
+		value, err := strconv.ParseUint(field, 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid value in %s", path)
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// readCgroupKeyValues reads a cgroup file holding a value for each key, such
+// as memory.stat
+func readCgroupKeyValues(path string) (map[string]uint64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	values := make(map[string]uint64)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			return nil, errors.Errorf("invalid line in %s: %q", path, scanner.Text())
+		}
+		value, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid line in %s: %q", path, scanner.Text())
+		}
+		values[fields[0]] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// readBlkioStats reads a cgroup v1 blkio file, holding a value for each
+// operation of each device
+func readBlkioStats(path string) ([]types.BlkioStatEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return parseBlkioStats(file)
+}
+
+// parseBlkioStats parses the values of each operation of each device listed
+// in the cgroup v1 blkio format, skipping the total of all devices
+func parseBlkioStats(r io.Reader) ([]types.BlkioStatEntry, error) {
+	var entries []types.BlkioStatEntry
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 {
+			continue
+		}
+		major, minor, err := parseDevice(fields[0])
+		if err != nil {
+			return nil, err
+		}
+		value, err := strconv.ParseUint(fields[2], 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid blkio stats: %q", scanner.Text())
+		}
+		entries = append(entries, types.BlkioStatEntry{
+			Major: major,
+			Minor: minor,
+			Op:    fields[1],
+			Value: value,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// parseIOStat parses the bytes and the number of operations read and written
+// by each device listed in the cgroup v2 io.stat format, into the blkio
+// entries docker reports
+func parseIOStat(r io.Reader) ([]types.BlkioStatEntry, []types.BlkioStatEntry, error) {
+	var bytes, ops []types.BlkioStatEntry
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		major, minor, err := parseDevice(fields[0])
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, field := range fields[1:] {
+			parts := strings.SplitN(field, "=", 2)
+			if len(parts) != 2 {
+				return nil, nil, errors.Errorf("invalid io stats: %q", scanner.Text())
+			}
+			value, err := strconv.ParseUint(parts[1], 10, 64)
+			if err != nil {
+				return nil, nil, errors.Wrapf(err, "invalid io stats: %q", scanner.Text())
+			}
+			entry := types.BlkioStatEntry{Major: major, Minor: minor, Value: value}
+			switch parts[0] {
+			case "rbytes":
+				entry.Op = "Read"
+				bytes = append(bytes, entry)
+			case "wbytes":
+				entry.Op = "Write"
+				bytes = append(bytes, entry)
+			case "rios":
+				entry.Op = "Read"
+				ops = append(ops, entry)
+			case "wios":
+				entry.Op = "Write"
+				ops = append(ops, entry)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+	return bytes, ops, nil
+}
+
+// parseDevice parses a device in the major:minor format
+func parseDevice(device string) (uint64, uint64, error) {
+	var major, minor uint64
+	if _, err := fmt.Sscanf(device, "%d:%d", &major, &minor); err != nil {
+		return 0, 0, errors.Wrapf(err, "invalid device %q", device)
+	}
+	return major, minor, nil
+}
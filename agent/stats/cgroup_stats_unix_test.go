@@ -0,0 +1,213 @@
+// +build !windows,unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stats
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	mock_dockerapi "github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi/mocks"
+	"github.com/docker/docker/api/types"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testCgroupPid  = 4242
+	testProcStat   = "cpu  100 0 50 800 50 0 0 0 0 0\ncpu0 50 0 25 400 25 0 0 0 0 0\n"
+	testV1Cgroup   = "12:memory:/ecs/task/c1\n11:cpu,cpuacct:/ecs/task/c1\n4:blkio:/ecs/task/c1\n1:name=systemd:/ecs/task/c1\n"
+	testV2Cgroup   = "0::/ecs/task/c1\n"
+	testMemoryStat = "cache 1024\nrss 4096\n"
+)
+
+// writeTestFiles writes the files relative to the root directory
+func writeTestFiles(t *testing.T, root string, files map[string]string) {
+	for path, content := range files {
+		path = filepath.Join(root, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, ioutil.WriteFile(path, []byte(content), 0644))
+	}
+}
+
+func newTestCgroupStats(t *testing.T, ctrl *gomock.Controller, files map[string]string) (*cgroupStats, func()) {
+	root, err := ioutil.TempDir("", "cgroup-stats")
+	require.NoError(t, err)
+	writeTestFiles(t, root, files)
+
+	mockDockerClient := mock_dockerapi.NewMockDockerClient(ctrl)
+	mockDockerClient.EXPECT().InspectContainer(gomock.Any(), "c1", gomock.Any()).Return(&types.ContainerJSON{
+		ContainerJSONBase: &types.ContainerJSONBase{
+			State: &types.ContainerState{Pid: testCgroupPid},
+		},
+	}, nil).AnyTimes()
+	return &cgroupStats{
+		client:     mockDockerClient,
+		cgroupPath: filepath.Join(root, "cgroup"),
+		procPath:   filepath.Join(root, "proc"),
+		interval:   time.Millisecond,
+	}, func() { os.RemoveAll(root) }
+}
+
+func TestCgroupStatsV1(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cgroupStats, cleanup := newTestCgroupStats(t, ctrl, map[string]string{
+		"proc/stat":         testProcStat,
+		"proc/4242/cgroup":  testV1Cgroup,
+		"proc/4242/net/dev": testNetDev,
+		"cgroup/cpuacct/ecs/task/c1/cpuacct.usage":                           "3000\n",
+		"cgroup/cpuacct/ecs/task/c1/cpuacct.usage_percpu":                    "1000 2000 \n",
+		"cgroup/memory/ecs/task/c1/memory.usage_in_bytes":                    "8192\n",
+		"cgroup/memory/ecs/task/c1/memory.max_usage_in_bytes":                "16384\n",
+		"cgroup/memory/ecs/task/c1/memory.limit_in_bytes":                    "1048576\n",
+		"cgroup/memory/ecs/task/c1/memory.stat":                              testMemoryStat,
+		"cgroup/blkio/ecs/task/c1/blkio.throttle.io_service_bytes_recursive": "8:0 Read 100\n8:0 Write 200\n8:0 Total 300\nTotal 300\n",
+		"cgroup/blkio/ecs/task/c1/blkio.throttle.io_serviced_recursive":      "8:0 Read 1\n8:0 Write 2\n8:0 Total 3\nTotal 3\n",
+	})
+	defer cleanup()
+
+	cgroups, err := cgroupStats.containerCgroups(context.TODO(), &ContainerMetadata{DockerID: "c1"})
+	require.NoError(t, err)
+	assert.False(t, cgroups.unified)
+	assert.True(t, cgroups.readNetwork)
+
+	previous := &types.StatsJSON{}
+	previous.Read = time.Now()
+	previous.CPUStats.CPUUsage.TotalUsage = 1000
+	stats, err := cgroupStats.read(cgroups, previous)
+	require.NoError(t, err)
+	assert.Equal(t, "c1", stats.ID)
+	assert.Equal(t, previous.Read, stats.PreRead)
+	assert.Equal(t, uint64(1000), stats.PreCPUStats.CPUUsage.TotalUsage)
+	assert.Equal(t, uint64(3000), stats.CPUStats.CPUUsage.TotalUsage)
+	assert.Equal(t, []uint64{1000, 2000}, stats.CPUStats.CPUUsage.PercpuUsage)
+	assert.Equal(t, uint64(1000*time.Second/clockTicksPerSecond), stats.CPUStats.SystemUsage)
+	assert.Equal(t, uint64(8192), stats.MemoryStats.Usage)
+	assert.Equal(t, uint64(16384), stats.MemoryStats.MaxUsage)
+	assert.Equal(t, uint64(1048576), stats.MemoryStats.Limit)
+	assert.Equal(t, map[string]uint64{"cache": 1024, "rss": 4096}, stats.MemoryStats.Stats)
+	assert.Equal(t, []types.BlkioStatEntry{
+		{Major: 8, Minor: 0, Op: "Read", Value: 100},
+		{Major: 8, Minor: 0, Op: "Write", Value: 200},
+		{Major: 8, Minor: 0, Op: "Total", Value: 300},
+	}, stats.BlkioStats.IoServiceBytesRecursive)
+	assert.Len(t, stats.Networks, 2, "the loopback interface should be skipped")
+	assert.Equal(t, uint64(2000), stats.Networks["eth0"].RxBytes)
+
+	containerStats, err := dockerStatsToContainerStats(stats)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(8192-1024), containerStats.memoryUsage)
+	assert.Equal(t, uint64(100), containerStats.storageReadBytes)
+	assert.Equal(t, uint64(2), containerStats.storageWriteOps)
+}
+
+func TestCgroupStatsUnified(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cgroupStats, cleanup := newTestCgroupStats(t, ctrl, map[string]string{
+		"proc/stat":                         testProcStat,
+		"proc/4242/cgroup":                  testV2Cgroup,
+		"cgroup/cgroup.controllers":         "cpuset cpu io memory pids\n",
+		"cgroup/ecs/task/c1/cpu.stat":       "usage_usec 3\nuser_usec 2\nsystem_usec 1\n",
+		"cgroup/ecs/task/c1/memory.current": "8192\n",
+		"cgroup/ecs/task/c1/memory.max":     "max\n",
+		"cgroup/ecs/task/c1/memory.stat":    "anon 4096\nfile 1024\n",
+		"cgroup/ecs/task/c1/io.stat":        "8:0 rbytes=100 wbytes=200 rios=1 wios=2 dbytes=0 dios=0\n",
+	})
+	defer cleanup()
+
+	cgroups, err := cgroupStats.containerCgroups(context.TODO(),
+		&ContainerMetadata{DockerID: "c1", NetworkMode: "container:pause"})
+	require.NoError(t, err)
+	assert.True(t, cgroups.unified)
+	assert.False(t, cgroups.readNetwork, "containers sharing the network namespace of another container have no network stats")
+
+	stats, err := cgroupStats.read(cgroups, nil)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3000), stats.CPUStats.CPUUsage.TotalUsage)
+	assert.Equal(t, uint64(2000), stats.CPUStats.CPUUsage.UsageInUsermode)
+	assert.Empty(t, stats.CPUStats.CPUUsage.PercpuUsage)
+	assert.Equal(t, uint32(numCores), stats.CPUStats.OnlineCPUs)
+	assert.Equal(t, uint64(0), stats.MemoryStats.Limit, "unlimited memory should be read as 0")
+	assert.Equal(t, uint64(1024), stats.MemoryStats.Stats["cache"])
+	assert.Nil(t, stats.Networks)
+
+	containerStats, err := dockerStatsToContainerStats(stats)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3000)/numCores, containerStats.cpuUsage)
+	assert.Equal(t, uint64(8192-1024), containerStats.memoryUsage)
+	assert.Equal(t, uint64(200), containerStats.storageWriteBytes)
+	assert.Equal(t, uint64(1), containerStats.storageReadOps)
+}
+
+func TestCgroupStatsStream(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cgroupStats, cleanup := newTestCgroupStats(t, ctrl, map[string]string{
+		"proc/stat":                         testProcStat,
+		"proc/4242/cgroup":                  testV2Cgroup,
+		"cgroup/cgroup.controllers":         "cpu io memory\n",
+		"cgroup/ecs/task/c1/cpu.stat":       "usage_usec 3\n",
+		"cgroup/ecs/task/c1/memory.current": "8192\n",
+		"cgroup/ecs/task/c1/memory.max":     "max\n",
+		"cgroup/ecs/task/c1/memory.stat":    "file 1024\n",
+		"cgroup/ecs/task/c1/io.stat":        "",
+	})
+	defer cleanup()
+
+	statsChnl, err := cgroupStats.Stats(context.TODO(), &ContainerMetadata{DockerID: "c1", NetworkMode: "none"})
+	require.NoError(t, err)
+	first := <-statsChnl
+	require.NotNil(t, first)
+	second := <-statsChnl
+	require.NotNil(t, second)
+	assert.Equal(t, first.Read, second.PreRead)
+	assert.Equal(t, first.CPUStats, second.PreCPUStats)
+
+	// The stream ends once the cgroup of the container is removed
+	require.NoError(t, os.RemoveAll(filepath.Join(cgroupStats.cgroupPath, "ecs")))
+	for range statsChnl {
+	}
+}
+
+func TestParseProcCgroup(t *testing.T) {
+	paths, err := parseProcCgroup(strings.NewReader(testV1Cgroup + testV2Cgroup))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"memory":       "/ecs/task/c1",
+		"cpu":          "/ecs/task/c1",
+		"cpuacct":      "/ecs/task/c1",
+		"blkio":        "/ecs/task/c1",
+		"name=systemd": "/ecs/task/c1",
+		"":             "/ecs/task/c1",
+	}, paths)
+
+	_, err = parseProcCgroup(strings.NewReader("invalid\n"))
+	assert.Error(t, err)
+}
+
+func TestParseIOStatInvalid(t *testing.T) {
+	_, _, err := parseIOStat(strings.NewReader("8:0 rbytes\n"))
+	assert.Error(t, err)
+	_, _, err = parseIOStat(strings.NewReader("sda rbytes=1\n"))
+	assert.Error(t, err)
+}
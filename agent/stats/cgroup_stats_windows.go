@@ -0,0 +1,35 @@
+// +build windows
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stats
+
+import (
+	"context"
+
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi"
+	"github.com/docker/docker/api/types"
+	"github.com/pkg/errors"
+)
+
+// cgroupStats isn't supported on windows, where containers don't have cgroups
+type cgroupStats struct{}
+
+func newCgroupStats(cfg *config.Config, client dockerapi.DockerClient) *cgroupStats {
+	return nil
+}
+
+func (c *cgroupStats) Stats(ctx context.Context, metadata *ContainerMetadata) (<-chan *types.StatsJSON, error) {
+	return nil, errors.New("cgroup stats are not supported on windows")
+}
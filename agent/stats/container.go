@@ -22,6 +22,7 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi"
 	"github.com/aws/amazon-ecs-agent/agent/stats/resolver"
 	"github.com/cihub/seelog"
+	"github.com/docker/docker/api/types"
 )
 
 const (
@@ -107,7 +108,13 @@ func (container *StatsContainer) processStatsStream() error {
 	if container.client == nil {
 		return errors.New("container processStatsStream: Client is not set.")
 	}
-	dockerStats, err := container.client.Stats(container.ctx, dockerID, dockerclient.StatsInactivityTimeout)
+	var dockerStats <-chan *types.StatsJSON
+	var err error
+	if container.cgroupStats != nil {
+		dockerStats, err = container.cgroupStats.Stats(container.ctx, container.containerMetadata)
+	} else {
+		dockerStats, err = container.client.Stats(container.ctx, dockerID, dockerclient.StatsInactivityTimeout)
+	}
 	if err != nil {
 		return err
 	}
@@ -66,6 +66,8 @@ type DockerContainerMetadataResolver struct {
 type Engine interface {
 	GetInstanceMetrics() (*ecstcs.MetricsMetadata, []*ecstcs.TaskMetric, error)
 	ContainerDockerStats(taskARN string, containerID string) (*types.StatsJSON, error)
+	ContainerNetworkRateStats(taskARN string, containerID string) (*NetworkStatsPerSec, error)
+	TaskNetworkStats(taskARN string) (*NetworkStats, error)
 	GetTaskHealthMetrics() (*ecstcs.HealthMetadata, []*ecstcs.TaskHealth, error)
 }
 
@@ -87,6 +89,13 @@ type DockerStatsEngine struct {
 	tasksToHealthCheckContainers map[string]map[string]*StatsContainer
 	// tasksToDefinitions maps task arns to task definition name and family metadata objects.
 	tasksToDefinitions map[string]*taskDefinition
+	// gpuStats provides the usage of the GPUs assigned to containers
+	gpuStats GPUStatsProvider
+	// lastMetricsReset is when the metrics were last reported
+	lastMetricsReset time.Time
+	// cgroupStats reads the usage of the containers from their cgroups, it's
+	// nil when the usage is streamed by docker
+	cgroupStats *cgroupStats
 }
 
 // ResolveTask resolves the api task object, given container id.
@@ -118,7 +127,7 @@ func (resolver *DockerContainerMetadataResolver) ResolveContainer(dockerID strin
 // NewDockerStatsEngine creates a new instance of the DockerStatsEngine object.
 // MustInit() must be called to initialize the fields of the new event listener.
 func NewDockerStatsEngine(cfg *config.Config, client dockerapi.DockerClient, containerChangeEventStream *eventstream.EventStream) *DockerStatsEngine {
-	return &DockerStatsEngine{
+	engine := &DockerStatsEngine{
 		client:                       client,
 		resolver:                     nil,
 		disableMetrics:               cfg.DisableMetrics,
@@ -127,6 +136,10 @@ func NewDockerStatsEngine(cfg *config.Config, client dockerapi.DockerClient, con
 		tasksToDefinitions:           make(map[string]*taskDefinition),
 		containerChangeEventStream:   containerChangeEventStream,
 	}
+	if cfg.CgroupStatsEnabled {
+		engine.cgroupStats = newCgroupStats(cfg, client)
+	}
+	return engine
 }
 
 // synchronizeState goes through all the containers on the instance to synchronize the state on agent start
@@ -252,6 +265,7 @@ func (engine *DockerStatsEngine) addContainerUnsafe(dockerID string) (*StatsCont
 	if err != nil {
 		return nil, errors.Wrapf(err, "could not map docker container ID to container, ignoring container: %s", dockerID)
 	}
+	statsContainer.cgroupStats = engine.cgroupStats
 
 	seelog.Debugf("Adding container to stats watch list, id: %s, task: %s", dockerID, task.Arn)
 	engine.tasksToDefinitions[task.Arn] = &taskDefinition{family: task.Family, version: task.Version}
@@ -635,6 +649,12 @@ func (engine *DockerStatsEngine) taskContainerMetricsUnsafe(taskArn string) ([]*
 		}
 		containerMetric.StorageStatsSet = storageStatsSet
 
+		if err == nil {
+			if taskContainer, ok := task.ContainerByName(container.containerMetadata.Name); ok {
+				containerMetric.GpuStatsSet = engine.getGPUStatsSetUnsafe(taskContainer.GPUIDs)
+			}
+		}
+
 		containerMetrics = append(containerMetrics, containerMetric)
 	}
 
@@ -675,6 +695,7 @@ func (engine *DockerStatsEngine) resetStatsUnsafe() {
 			container.statsQueue.Reset()
 		}
 	}
+	engine.resetGPUStatsUnsafe()
 }
 
 // ContainerDockerStats returns the last stored raw docker stats object for a container
@@ -696,6 +717,25 @@ func (engine *DockerStatsEngine) ContainerDockerStats(taskARN string, containerI
 
 }
 
+// ContainerNetworkRateStats returns the network throughput of a container
+// computed from its two most recent docker stats
+func (engine *DockerStatsEngine) ContainerNetworkRateStats(taskARN string, containerID string) (*NetworkStatsPerSec, error) {
+	engine.lock.RLock()
+	defer engine.lock.RUnlock()
+
+	containerIDToStatsContainer, ok := engine.tasksToContainers[taskARN]
+	if !ok {
+		return nil, errors.Errorf("stats engine: task '%s' for container '%s' not found",
+			taskARN, containerID)
+	}
+
+	container, ok := containerIDToStatsContainer[containerID]
+	if !ok {
+		return nil, errors.Errorf("stats engine: container not found: %s", containerID)
+	}
+	return container.statsQueue.GetLastNetworkStatPerSec(), nil
+}
+
 // newMetricsMetadata creates the singleton metadata object.
 func newMetricsMetadata(cluster *string, containerInstance *string) *ecstcs.MetricsMetadata {
 	return &ecstcs.MetricsMetadata{
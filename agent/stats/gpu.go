@@ -0,0 +1,88 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stats
+
+import (
+	"math"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/gpu"
+	"github.com/aws/amazon-ecs-agent/agent/tcs/model/ecstcs"
+)
+
+// GPUStatsProvider provides the latest samples of the usage of the GPUs of
+// the instance
+type GPUStatsProvider interface {
+	GetDeviceStats(gpuID string) []*gpu.DeviceStats
+}
+
+// SetGPUStatsProvider sets the provider of the usage of the GPUs, which is
+// reported for the containers GPUs are assigned to
+func (engine *DockerStatsEngine) SetGPUStatsProvider(provider GPUStatsProvider) {
+	engine.lock.Lock()
+	defer engine.lock.Unlock()
+	engine.gpuStats = provider
+}
+
+// getGPUStatsSetUnsafe returns the stats set of the usage of the GPUs sampled
+// since the metrics were last reported, nil if there's no such sample
+func (engine *DockerStatsEngine) getGPUStatsSetUnsafe(gpuIDs []string) *ecstcs.GpuStatsSet {
+	if engine.gpuStats == nil || len(gpuIDs) == 0 {
+		return nil
+	}
+	var utilization, memoryUtilization, temperature []float64
+	for _, gpuID := range gpuIDs {
+		for _, sample := range engine.gpuStats.GetDeviceStats(gpuID) {
+			if !sample.Timestamp.After(engine.lastMetricsReset) {
+				continue
+			}
+			utilization = append(utilization, sample.Utilization)
+			memoryUtilization = append(memoryUtilization, sample.MemoryUtilization())
+			temperature = append(temperature, sample.Temperature)
+		}
+	}
+	if len(utilization) == 0 {
+		return nil
+	}
+	return &ecstcs.GpuStatsSet{
+		Utilization:       newCWStatsSet(utilization),
+		MemoryUtilization: newCWStatsSet(memoryUtilization),
+		Temperature:       newCWStatsSet(temperature),
+	}
+}
+
+// newCWStatsSet returns the stats set of the values
+func newCWStatsSet(values []float64) *ecstcs.CWStatsSet {
+	min := math.MaxFloat64
+	max := -math.MaxFloat64
+	sum := float64(0)
+	for _, value := range values {
+		min = math.Min(min, value)
+		max = math.Max(max, value)
+		sum += value
+	}
+	sampleCount := int64(len(values))
+	return &ecstcs.CWStatsSet{
+		Max:         &max,
+		Min:         &min,
+		SampleCount: &sampleCount,
+		Sum:         &sum,
+	}
+}
+
+// resetGPUStatsUnsafe makes the samples of the usage of the GPUs reported so
+// far excluded from the next report
+func (engine *DockerStatsEngine) resetGPUStatsUnsafe() {
+	engine.lastMetricsReset = time.Now()
+}
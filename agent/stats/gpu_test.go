@@ -0,0 +1,65 @@
+// +build unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/gpu"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeGPUStatsProvider map[string][]*gpu.DeviceStats
+
+func (provider fakeGPUStatsProvider) GetDeviceStats(gpuID string) []*gpu.DeviceStats {
+	return provider[gpuID]
+}
+
+func TestGetGPUStatsSet(t *testing.T) {
+	now := time.Now()
+	engine := NewDockerStatsEngine(&cfg, nil, eventStream("TestGetGPUStatsSet"))
+	engine.SetGPUStatsProvider(fakeGPUStatsProvider{
+		"gpu1": {
+			{Utilization: 20, MemoryUsedMiB: 100, MemoryTotalMiB: 1000, Temperature: 50, Timestamp: now},
+			{Utilization: 40, MemoryUsedMiB: 300, MemoryTotalMiB: 1000, Temperature: 60, Timestamp: now},
+		},
+		"gpu2": {
+			{Utilization: 90, MemoryUsedMiB: 500, MemoryTotalMiB: 1000, Temperature: 70, Timestamp: now},
+		},
+	})
+
+	assert.Nil(t, engine.getGPUStatsSetUnsafe(nil), "containers without GPUs shouldn't have GPU stats")
+
+	gpuStatsSet := engine.getGPUStatsSetUnsafe([]string{"gpu1", "gpu2"})
+	require.NotNil(t, gpuStatsSet)
+	assert.Equal(t, int64(3), *gpuStatsSet.Utilization.SampleCount)
+	assert.Equal(t, float64(150), *gpuStatsSet.Utilization.Sum)
+	assert.Equal(t, float64(20), *gpuStatsSet.Utilization.Min)
+	assert.Equal(t, float64(90), *gpuStatsSet.Utilization.Max)
+	assert.Equal(t, float64(90), *gpuStatsSet.MemoryUtilization.Sum)
+	assert.Equal(t, float64(70), *gpuStatsSet.Temperature.Max)
+
+	engine.resetStatsUnsafe()
+	assert.Nil(t, engine.getGPUStatsSetUnsafe([]string{"gpu1", "gpu2"}),
+		"samples already reported shouldn't be reported again")
+}
+
+func TestGetGPUStatsSetWithoutProvider(t *testing.T) {
+	engine := NewDockerStatsEngine(&cfg, nil, eventStream("TestGetGPUStatsSetWithoutProvider"))
+	assert.Nil(t, engine.getGPUStatsSetUnsafe([]string{"gpu1"}))
+}
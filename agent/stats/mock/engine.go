@@ -21,6 +21,7 @@ package mock_stats
 import (
 	reflect "reflect"
 
+	stats "github.com/aws/amazon-ecs-agent/agent/stats"
 	ecstcs "github.com/aws/amazon-ecs-agent/agent/tcs/model/ecstcs"
 	types "github.com/docker/docker/api/types"
 	gomock "github.com/golang/mock/gomock"
@@ -64,6 +65,21 @@ func (mr *MockEngineMockRecorder) ContainerDockerStats(arg0, arg1 interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ContainerDockerStats", reflect.TypeOf((*MockEngine)(nil).ContainerDockerStats), arg0, arg1)
 }
 
+// ContainerNetworkRateStats mocks base method
+func (m *MockEngine) ContainerNetworkRateStats(arg0, arg1 string) (*stats.NetworkStatsPerSec, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ContainerNetworkRateStats", arg0, arg1)
+	ret0, _ := ret[0].(*stats.NetworkStatsPerSec)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ContainerNetworkRateStats indicates an expected call of ContainerNetworkRateStats
+func (mr *MockEngineMockRecorder) ContainerNetworkRateStats(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ContainerNetworkRateStats", reflect.TypeOf((*MockEngine)(nil).ContainerNetworkRateStats), arg0, arg1)
+}
+
 // GetInstanceMetrics mocks base method
 func (m *MockEngine) GetInstanceMetrics() (*ecstcs.MetricsMetadata, []*ecstcs.TaskMetric, error) {
 	m.ctrl.T.Helper()
@@ -95,3 +111,18 @@ func (mr *MockEngineMockRecorder) GetTaskHealthMetrics() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTaskHealthMetrics", reflect.TypeOf((*MockEngine)(nil).GetTaskHealthMetrics))
 }
+
+// TaskNetworkStats mocks base method
+func (m *MockEngine) TaskNetworkStats(arg0 string) (*stats.NetworkStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TaskNetworkStats", arg0)
+	ret0, _ := ret[0].(*stats.NetworkStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TaskNetworkStats indicates an expected call of TaskNetworkStats
+func (mr *MockEngineMockRecorder) TaskNetworkStats(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TaskNetworkStats", reflect.TypeOf((*MockEngine)(nil).TaskNetworkStats), arg0)
+}
@@ -33,11 +33,12 @@ const minimumQueueDatapoints = 2
 
 // Queue abstracts a queue using UsageStats slice.
 type Queue struct {
-	buffer        []UsageStats
-	maxSize       int
-	lastResetTime time.Time
-	lastStat      *types.StatsJSON
-	lock          sync.RWMutex
+	buffer                []UsageStats
+	maxSize               int
+	lastResetTime         time.Time
+	lastStat              *types.StatsJSON
+	lastNetworkStatPerSec *NetworkStatsPerSec
+	lock                  sync.RWMutex
 }
 
 // NewQueue creates a queue.
@@ -84,6 +85,8 @@ func (queue *Queue) add(rawStat *ContainerStats) {
 		MemoryUsageInMegs: uint32(rawStat.memoryUsage / BytesInMiB),
 		StorageReadBytes:  rawStat.storageReadBytes,
 		StorageWriteBytes: rawStat.storageWriteBytes,
+		StorageReadOps:    rawStat.storageReadOps,
+		StorageWriteOps:   rawStat.storageWriteOps,
 		NetworkStats:      rawStat.networkStats,
 		Timestamp:         rawStat.timestamp,
 		cpuUsage:          rawStat.cpuUsage,
@@ -101,6 +104,7 @@ func (queue *Queue) add(rawStat *ContainerStats) {
 			// float32(1) / float32(0) = +Inf
 			seelog.Debugf("time since last stat is zero. Ignoring cpu stat")
 		}
+		queue.lastNetworkStatPerSec = getNetworkStatsPerSec(lastStat, stat)
 		if queue.maxSize == queueLength {
 			// Remove first element if queue is full.
 			queue.buffer = queue.buffer[1:queueLength]
@@ -118,6 +122,37 @@ func (queue *Queue) GetLastStat() *types.StatsJSON {
 	return queue.lastStat
 }
 
+// GetLastNetworkStatPerSec returns the network throughput computed from the last
+// two recorded stats, or nil if it can't be computed yet
+func (queue *Queue) GetLastNetworkStatPerSec() *NetworkStatsPerSec {
+	queue.lock.RLock()
+	defer queue.lock.RUnlock()
+
+	return queue.lastNetworkStatPerSec
+}
+
+// getNetworkStatsPerSec computes the network throughput between two stats. It
+// returns nil if either stat lacks network stats, if the stats are not in
+// order, or if the counters were reset in between (e.g. the container was
+// restarted)
+func getNetworkStatsPerSec(previous, current UsageStats) *NetworkStatsPerSec {
+	if previous.NetworkStats == nil || current.NetworkStats == nil {
+		return nil
+	}
+	seconds := float32(current.Timestamp.Sub(previous.Timestamp).Seconds())
+	if seconds <= 0 {
+		return nil
+	}
+	if current.NetworkStats.RxBytes < previous.NetworkStats.RxBytes ||
+		current.NetworkStats.TxBytes < previous.NetworkStats.TxBytes {
+		return nil
+	}
+	return &NetworkStatsPerSec{
+		RxBytesPerSecond: float32(current.NetworkStats.RxBytes-previous.NetworkStats.RxBytes) / seconds,
+		TxBytesPerSecond: float32(current.NetworkStats.TxBytes-previous.NetworkStats.TxBytes) / seconds,
+	}
+}
+
 // GetCPUStatsSet gets the stats set for CPU utilization.
 func (queue *Queue) GetCPUStatsSet() (*ecstcs.CWStatsSet, error) {
 	return queue.getCWStatsSet(getCPUUsagePerc)
@@ -140,6 +175,14 @@ func (queue *Queue) GetStorageStatsSet() (*ecstcs.StorageStatsSet, error) {
 	if err != nil {
 		seelog.Warnf("Error getting storage write size bytes: %v", err)
 	}
+	storageStatsSet.ReadOps, err = queue.getULongStatsSet(getStorageReadOps)
+	if err != nil {
+		seelog.Warnf("Error getting storage read ops: %v", err)
+	}
+	storageStatsSet.WriteOps, err = queue.getULongStatsSet(getStorageWriteOps)
+	if err != nil {
+		seelog.Warnf("Error getting storage write ops: %v", err)
+	}
 	return storageStatsSet, err
 }
 
@@ -286,6 +329,14 @@ func getStorageWriteBytes(s *UsageStats) uint64 {
 	return s.StorageWriteBytes
 }
 
+func getStorageReadOps(s *UsageStats) uint64 {
+	return s.StorageReadOps
+}
+
+func getStorageWriteOps(s *UsageStats) uint64 {
+	return s.StorageWriteOps
+}
+
 // getInt64WithOverflow truncates a uint64 to fit an int64
 // it returns overflow as a second int64
 func getInt64WithOverflow(uintStat uint64) (int64, int64) {
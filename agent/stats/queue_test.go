@@ -23,6 +23,7 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/tcs/model/ecstcs"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 const (
@@ -153,6 +154,8 @@ func createQueue(size int, predictableHighUtilization bool) *Queue {
 			memoryUsage:       memoryUtilizationInBytes[i],
 			storageReadBytes:  uintStats[i],
 			storageWriteBytes: uintStats[i],
+			storageReadOps:    uintStats[i],
+			storageWriteOps:   uintStats[i],
 			networkStats: &NetworkStats{
 				RxBytes:   uintStats[i],
 				RxDropped: 0,
@@ -253,6 +256,12 @@ func TestQueueAddRemove(t *testing.T) {
 		t.Error("Sum value incorrectly set: ", *storageWriteStatsSet.Sum)
 	}
 
+	for _, storageOpsStatsSet := range []*ecstcs.ULongStatsSet{storageStatsSet.ReadOps, storageStatsSet.WriteOps} {
+		require.NotNil(t, storageOpsStatsSet)
+		assert.Equal(t, int64(queueLength), *storageOpsStatsSet.SampleCount)
+		assert.NotZero(t, *storageOpsStatsSet.Sum)
+	}
+
 	netStatsSet, err := queue.GetNetworkStatsSet()
 	assert.NoError(t, err, "error getting network stats set")
 	validateNetStatsSet(t, netStatsSet, queueLength)
@@ -512,3 +521,30 @@ func TestEnoughDatapointsInBuffer(t *testing.T) {
 	enoughDataPoints = queue.enoughDatapointsInBuffer()
 	assert.False(t, enoughDataPoints, "Queue is expected to not have enough data points right after RESET")
 }
+
+func TestNetworkStatsPerSec(t *testing.T) {
+	timestamps := []time.Time{
+		parseNanoTime("2015-02-12T21:22:05.131117533Z"),
+		parseNanoTime("2015-02-12T21:22:07.131117533Z"),
+		parseNanoTime("2015-02-12T21:22:09.131117533Z"),
+	}
+	networkStats := []*NetworkStats{
+		{RxBytes: 1000, TxBytes: 2000},
+		{RxBytes: 3000, TxBytes: 6000},
+		// Counters are reset when the container is restarted
+		{RxBytes: 100, TxBytes: 200},
+	}
+
+	queue := NewQueue(3)
+	queue.add(&ContainerStats{networkStats: networkStats[0], timestamp: timestamps[0]})
+	assert.Nil(t, queue.GetLastNetworkStatPerSec(), "rate needs two datapoints")
+
+	queue.add(&ContainerStats{networkStats: networkStats[1], timestamp: timestamps[1]})
+	assert.Equal(t, &NetworkStatsPerSec{
+		RxBytesPerSecond: 1000,
+		TxBytesPerSecond: 2000,
+	}, queue.GetLastNetworkStatPerSec())
+
+	queue.add(&ContainerStats{networkStats: networkStats[2], timestamp: timestamps[2]})
+	assert.Nil(t, queue.GetLastNetworkStatPerSec(), "rate is unknown after counters are reset")
+}
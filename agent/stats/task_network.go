@@ -0,0 +1,193 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stats
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"strconv"
+	"strings"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/dockerclient"
+	"github.com/pkg/errors"
+)
+
+const (
+	// loopbackInterfaceName is the name of the loopback interface, whose
+	// traffic doesn't leave the task
+	loopbackInterfaceName = "lo"
+	// netDevHeaderLines is the number of header lines of /proc/net/dev
+	netDevHeaderLines = 2
+	// netDevFields is the number of counters of each interface in
+	// /proc/net/dev, 8 for each direction
+	netDevFields = 16
+)
+
+// TaskNetworkStats returns the network stats of the task: the stats of the
+// interfaces of the network namespace of the pause container for awsvpc
+// tasks, the sum of the stats docker reports for the veth of each container
+// otherwise
+func (engine *DockerStatsEngine) TaskNetworkStats(taskARN string) (*NetworkStats, error) {
+	engine.lock.RLock()
+	containerMap, ok := engine.tasksToContainers[taskARN]
+	if !ok {
+		engine.lock.RUnlock()
+		return nil, errors.Errorf("stats engine: task '%s' not found", taskARN)
+	}
+	containers := make([]*StatsContainer, 0, len(containerMap))
+	for _, container := range containerMap {
+		containers = append(containers, container)
+	}
+	engine.lock.RUnlock()
+
+	var task *apitask.Task
+	var err error
+	for _, container := range containers {
+		task, err = engine.resolver.ResolveTask(container.containerMetadata.DockerID)
+		if err == nil {
+			break
+		}
+	}
+	if task == nil {
+		return nil, errors.Wrapf(err, "stats engine: unable to resolve task '%s'", taskARN)
+	}
+
+	if task.IsNetworkModeAWSVPC() {
+		return engine.awsvpcTaskNetworkStats(task)
+	}
+	return bridgeTaskNetworkStats(containers), nil
+}
+
+// awsvpcTaskNetworkStats returns the stats of the interfaces of the network
+// namespace of the pause container of the task
+func (engine *DockerStatsEngine) awsvpcTaskNetworkStats(task *apitask.Task) (*NetworkStats, error) {
+	var pauseContainerID string
+	for _, container := range task.Containers {
+		if container.Type == apicontainer.ContainerCNIPause {
+			pauseContainerID = container.GetRuntimeID()
+			break
+		}
+	}
+	if pauseContainerID == "" {
+		return nil, errors.Errorf("stats engine: pause container of task '%s' not found", task.Arn)
+	}
+
+	ctx, cancel := context.WithTimeout(engine.ctx, dockerclient.InspectContainerTimeout)
+	defer cancel()
+	pauseContainer, err := engine.client.InspectContainer(ctx, pauseContainerID, dockerclient.InspectContainerTimeout)
+	if err != nil {
+		return nil, errors.Wrapf(err, "stats engine: unable to inspect pause container of task '%s'", task.Arn)
+	}
+	if pauseContainer.State == nil || pauseContainer.State.Pid == 0 {
+		return nil, errors.Errorf("stats engine: pause container of task '%s' isn't running", task.Arn)
+	}
+	return netnsNetworkStats(pauseContainer.State.Pid)
+}
+
+// bridgeTaskNetworkStats returns the sum of the latest network stats of the
+// containers that have their own network stack
+func bridgeTaskNetworkStats(containers []*StatsContainer) *NetworkStats {
+	taskStats := &NetworkStats{}
+	for _, container := range containers {
+		networkMode := container.containerMetadata.NetworkMode
+		if networkMode == hostNetworkMode || networkMode == noneNetworkMode {
+			continue
+		}
+		dockerStats := container.statsQueue.GetLastStat()
+		if dockerStats == nil {
+			continue
+		}
+		containerStats := getNetworkStats(dockerStats)
+		if containerStats == nil {
+			continue
+		}
+		taskStats.add(containerStats)
+	}
+	return taskStats
+}
+
+// add adds the counters of other to the stats
+func (stats *NetworkStats) add(other *NetworkStats) {
+	stats.RxBytes += other.RxBytes
+	stats.RxDropped += other.RxDropped
+	stats.RxErrors += other.RxErrors
+	stats.RxPackets += other.RxPackets
+	stats.TxBytes += other.TxBytes
+	stats.TxDropped += other.TxDropped
+	stats.TxErrors += other.TxErrors
+	stats.TxPackets += other.TxPackets
+}
+
+// parseNetDev returns the sum of the counters of the interfaces listed in the
+// /proc/net/dev format, except the loopback interface
+func parseNetDev(r io.Reader) (*NetworkStats, error) {
+	interfaces, err := parseNetDevInterfaces(r)
+	if err != nil {
+		return nil, err
+	}
+	stats := &NetworkStats{}
+	for _, interfaceStats := range interfaces {
+		stats.add(interfaceStats)
+	}
+	return stats, nil
+}
+
+// parseNetDevInterfaces returns the counters of each interface listed in the
+// /proc/net/dev format, except the loopback interface
+func parseNetDevInterfaces(r io.Reader) (map[string]*NetworkStats, error) {
+	interfaces := make(map[string]*NetworkStats)
+	scanner := bufio.NewScanner(r)
+	for line := 0; scanner.Scan(); line++ {
+		if line < netDevHeaderLines {
+			continue
+		}
+		parts := strings.SplitN(scanner.Text(), ":", 2)
+		if len(parts) != 2 {
+			return nil, errors.Errorf("invalid interface stats: %q", scanner.Text())
+		}
+		name := strings.TrimSpace(parts[0])
+		if name == loopbackInterfaceName {
+			continue
+		}
+		fields := strings.Fields(parts[1])
+		if len(fields) != netDevFields {
+			return nil, errors.Errorf("invalid interface stats: %q", scanner.Text())
+		}
+		counters := make([]uint64, netDevFields)
+		for i, field := range fields {
+			counter, err := strconv.ParseUint(field, 10, 64)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid interface stats: %q", scanner.Text())
+			}
+			counters[i] = counter
+		}
+		interfaces[name] = &NetworkStats{
+			RxBytes:   counters[0],
+			RxPackets: counters[1],
+			RxErrors:  counters[2],
+			RxDropped: counters[3],
+			TxBytes:   counters[8],
+			TxPackets: counters[9],
+			TxErrors:  counters[10],
+			TxDropped: counters[11],
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return interfaces, nil
+}
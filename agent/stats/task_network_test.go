@@ -0,0 +1,147 @@
+// +build unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stats
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apieni "github.com/aws/amazon-ecs-agent/agent/api/eni"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	mock_dockerapi "github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi/mocks"
+	mock_resolver "github.com/aws/amazon-ecs-agent/agent/stats/resolver/mock"
+	"github.com/docker/docker/api/types"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testNetDev = `Inter-|   Receive                                                |  Transmit
+ face |bytes    packets errs drop fifo frame compressed multicast|bytes    packets errs drop fifo colls carrier compressed
+    lo:    1000      10    0    0    0     0          0         0     1000      10    0    0    0     0       0          0
+  eth0:    2000      20    1    2    0     0          0         0     3000      30    3    4    0     0       0          0
+  eth1:     500       5    0    1    0     0          0         0      700       7    0    0    0     0       0          0
+`
+
+func TestParseNetDev(t *testing.T) {
+	stats, err := parseNetDev(strings.NewReader(testNetDev))
+	require.NoError(t, err)
+	assert.Equal(t, &NetworkStats{
+		RxBytes:   2500,
+		RxPackets: 25,
+		RxErrors:  1,
+		RxDropped: 3,
+		TxBytes:   3700,
+		TxPackets: 37,
+		TxErrors:  3,
+		TxDropped: 4,
+	}, stats, "the loopback interface should be skipped")
+}
+
+func TestParseNetDevInterfaces(t *testing.T) {
+	interfaces, err := parseNetDevInterfaces(strings.NewReader(testNetDev))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]*NetworkStats{
+		"eth0": {RxBytes: 2000, RxPackets: 20, RxErrors: 1, RxDropped: 2, TxBytes: 3000, TxPackets: 30, TxErrors: 3, TxDropped: 4},
+		"eth1": {RxBytes: 500, RxPackets: 5, RxDropped: 1, TxBytes: 700, TxPackets: 7},
+	}, interfaces)
+}
+
+func TestParseNetDevInvalid(t *testing.T) {
+	_, err := parseNetDev(strings.NewReader(testNetDev + "  eth2: 1 2 3\n"))
+	assert.Error(t, err)
+}
+
+func newTaskNetworkStatsContainer(dockerID, networkMode string, rxBytes, txBytes uint64) *StatsContainer {
+	container := &StatsContainer{
+		containerMetadata: &ContainerMetadata{
+			DockerID:    dockerID,
+			NetworkMode: networkMode,
+		},
+		statsQueue: NewQueue(1),
+	}
+	dockerStats := &types.StatsJSON{
+		Networks: map[string]types.NetworkStats{
+			"eth0": {RxBytes: rxBytes, TxBytes: txBytes},
+		},
+	}
+	container.statsQueue.setLastStat(dockerStats)
+	return container
+}
+
+func TestTaskNetworkStatsBridge(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	resolver := mock_resolver.NewMockContainerMetadataResolver(mockCtrl)
+	resolver.EXPECT().ResolveTask(gomock.Any()).Return(&apitask.Task{Arn: "t1"}, nil)
+
+	engine := NewDockerStatsEngine(&cfg, nil, eventStream("TestTaskNetworkStatsBridge"))
+	engine.resolver = resolver
+	engine.tasksToContainers["t1"] = map[string]*StatsContainer{
+		"c1": newTaskNetworkStatsContainer("c1", "bridge", 100, 200),
+		"c2": newTaskNetworkStatsContainer("c2", "bridge", 10, 20),
+		"c3": newTaskNetworkStatsContainer("c3", hostNetworkMode, 1000, 2000),
+	}
+
+	stats, err := engine.TaskNetworkStats("t1")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(110), stats.RxBytes)
+	assert.Equal(t, uint64(220), stats.TxBytes, "containers in host mode should be skipped")
+}
+
+func TestTaskNetworkStatsUnknownTask(t *testing.T) {
+	engine := NewDockerStatsEngine(&cfg, nil, eventStream("TestTaskNetworkStatsUnknownTask"))
+	_, err := engine.TaskNetworkStats("t1")
+	assert.Error(t, err)
+}
+
+func TestTaskNetworkStatsAWSVPCPauseContainerNotRunning(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	resolver := mock_resolver.NewMockContainerMetadataResolver(mockCtrl)
+	mockDockerClient := mock_dockerapi.NewMockDockerClient(mockCtrl)
+	pauseContainer := &apicontainer.Container{
+		Name: "~internal~ecs~pause",
+		Type: apicontainer.ContainerCNIPause,
+	}
+	pauseContainer.SetRuntimeID("pause")
+	task := &apitask.Task{
+		Arn:        "t1",
+		Containers: []*apicontainer.Container{pauseContainer},
+		ENIs:       []*apieni.ENI{{ID: "eni-1"}},
+	}
+	resolver.EXPECT().ResolveTask("c1").Return(task, nil)
+	mockDockerClient.EXPECT().InspectContainer(gomock.Any(), "pause", gomock.Any()).Return(&types.ContainerJSON{
+		ContainerJSONBase: &types.ContainerJSONBase{
+			State: &types.ContainerState{},
+		},
+	}, nil)
+
+	engine := NewDockerStatsEngine(&cfg, nil, eventStream("TestTaskNetworkStatsAWSVPCPauseContainerNotRunning"))
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	engine.ctx = ctx
+	engine.resolver = resolver
+	engine.client = mockDockerClient
+	engine.tasksToContainers["t1"] = map[string]*StatsContainer{
+		"c1": newTaskNetworkStatsContainer("c1", "", 0, 0),
+	}
+
+	_, err := engine.TaskNetworkStats("t1")
+	assert.Error(t, err)
+}
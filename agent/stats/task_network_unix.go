@@ -0,0 +1,35 @@
+// +build !windows
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stats
+
+import (
+	"fmt"
+	"os"
+)
+
+// netDevPathFormat is the path of the interface stats of the network namespace
+// of a process, as seen from the agent container
+const netDevPathFormat = "/host/proc/%d/net/dev"
+
+// netnsNetworkStats returns the stats of the interfaces of the network
+// namespace of the process
+func netnsNetworkStats(pid int) (*NetworkStats, error) {
+	file, err := os.Open(fmt.Sprintf(netDevPathFormat, pid))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return parseNetDev(file)
+}
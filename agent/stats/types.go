@@ -28,10 +28,21 @@ type ContainerStats struct {
 	memoryUsage       uint64
 	storageReadBytes  uint64
 	storageWriteBytes uint64
+	storageReadOps    uint64
+	storageWriteOps   uint64
 	networkStats      *NetworkStats
 	timestamp         time.Time
 }
 
+// StorageStats contains the block I/O of a container, aggregated across
+// devices
+type StorageStats struct {
+	ReadBytes  uint64 `json:"read_bytes"`
+	WriteBytes uint64 `json:"write_bytes"`
+	ReadOps    uint64 `json:"read_ops"`
+	WriteOps   uint64 `json:"write_ops"`
+}
+
 // NetworkStats contains the network stats information for a container
 type NetworkStats struct {
 	RxBytes   uint64 `json:"rxBytes"`
@@ -44,12 +55,21 @@ type NetworkStats struct {
 	TxPackets uint64 `json:"txPackets"`
 }
 
+// NetworkStatsPerSec contains the network throughput of a container, computed
+// from the two most recent stats received from docker
+type NetworkStatsPerSec struct {
+	RxBytesPerSecond float32 `json:"rx_bytes_per_sec"`
+	TxBytesPerSecond float32 `json:"tx_bytes_per_sec"`
+}
+
 // UsageStats abstracts the format in which the queue stores data.
 type UsageStats struct {
 	CPUUsagePerc      float32       `json:"cpuUsagePerc"`
 	MemoryUsageInMegs uint32        `json:"memoryUsageInMegs"`
 	StorageReadBytes  uint64        `json:"storageReadBytes"`
 	StorageWriteBytes uint64        `json:"storageWriteBytes"`
+	StorageReadOps    uint64        `json:"storageReadOps"`
+	StorageWriteOps   uint64        `json:"storageWriteOps"`
 	NetworkStats      *NetworkStats `json:"networkStats"`
 	Timestamp         time.Time     `json:"timestamp"`
 	cpuUsage          uint64
@@ -70,6 +90,9 @@ type StatsContainer struct {
 	client            dockerapi.DockerClient
 	statsQueue        *Queue
 	resolver          resolver.ContainerMetadataResolver
+	// cgroupStats reads the usage of the container from its cgroups instead
+	// of docker when it's set
+	cgroupStats *cgroupStats
 }
 
 // taskDefinition encapsulates family and version strings for a task definition
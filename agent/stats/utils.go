@@ -70,3 +70,12 @@ func getNetworkStats(dockerStats *types.StatsJSON) *NetworkStats {
 	}
 	return networkStats
 }
+
+// GetStorageStats returns the block I/O of the container, aggregated across
+// devices, from its docker stats
+func GetStorageStats(dockerStats *types.StatsJSON) *StorageStats {
+	if dockerStats == nil {
+		return nil
+	}
+	return getStorageStats(dockerStats)
+}
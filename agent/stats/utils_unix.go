@@ -24,43 +24,57 @@ import (
 // dockerStatsToContainerStats returns a new object of the ContainerStats object from docker stats.
 func dockerStatsToContainerStats(dockerStats *types.StatsJSON) (*ContainerStats, error) {
 	// The length of PercpuUsage represents the number of cores in an instance.
-	if len(dockerStats.CPUStats.CPUUsage.PercpuUsage) == 0 || numCores == uint64(0) {
+	// It isn't reported with cgroup v2, where the online cpus are reported instead
+	if (len(dockerStats.CPUStats.CPUUsage.PercpuUsage) == 0 && dockerStats.CPUStats.OnlineCPUs == 0) ||
+		numCores == uint64(0) {
 		seelog.Debug("Invalid container statistics reported, no cpu core usage reported")
 		return nil, fmt.Errorf("Invalid container statistics reported, no cpu core usage reported")
 	}
 
 	cpuUsage := dockerStats.CPUStats.CPUUsage.TotalUsage / numCores
 	memoryUsage := dockerStats.MemoryStats.Usage - dockerStats.MemoryStats.Stats["cache"]
-	storageReadBytes, storageWriteBytes := getStorageStats(dockerStats)
+	storageStats := getStorageStats(dockerStats)
 	networkStats := getNetworkStats(dockerStats)
 	return &ContainerStats{
 		cpuUsage:          cpuUsage,
 		memoryUsage:       memoryUsage,
-		storageReadBytes:  storageReadBytes,
-		storageWriteBytes: storageWriteBytes,
+		storageReadBytes:  storageStats.ReadBytes,
+		storageWriteBytes: storageStats.WriteBytes,
+		storageReadOps:    storageStats.ReadOps,
+		storageWriteOps:   storageStats.WriteOps,
 		networkStats:      networkStats,
 		timestamp:         dockerStats.Read,
 	}, nil
 }
 
-func getStorageStats(dockerStats *types.StatsJSON) (uint64, uint64) {
+// getStorageStats returns the block I/O of the container, from the bytes and
+// the number of operations serviced by each device
+func getStorageStats(dockerStats *types.StatsJSON) *StorageStats {
 	// initialize block io and loop over stats to aggregate
 	if dockerStats.BlkioStats.IoServiceBytesRecursive == nil {
 		seelog.Debug("Storage stats not reported for container")
-		return uint64(0), uint64(0)
 	}
-	storageReadBytes := uint64(0)
-	storageWriteBytes := uint64(0)
-	for _, blockStat := range dockerStats.BlkioStats.IoServiceBytesRecursive {
+	storageStats := &StorageStats{}
+	storageStats.ReadBytes, storageStats.WriteBytes = sumBlkioStats(dockerStats.BlkioStats.IoServiceBytesRecursive)
+	storageStats.ReadOps, storageStats.WriteOps = sumBlkioStats(dockerStats.BlkioStats.IoServicedRecursive)
+	return storageStats
+}
+
+// sumBlkioStats returns the sums of the read and write values of the blkio
+// entries of all devices
+func sumBlkioStats(entries []types.BlkioStatEntry) (uint64, uint64) {
+	read := uint64(0)
+	write := uint64(0)
+	for _, blockStat := range entries {
 		switch op := blockStat.Op; op {
 		case "Read":
-			storageReadBytes += blockStat.Value
+			read += blockStat.Value
 		case "Write":
-			storageWriteBytes += blockStat.Value
+			write += blockStat.Value
 		default:
 			//ignoring "Async", "Total", "Sum", etc
 			continue
 		}
 	}
-	return storageReadBytes, storageWriteBytes
+	return read, write
 }
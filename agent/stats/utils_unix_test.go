@@ -32,10 +32,23 @@ func TestDockerStatsToContainerStatsEmptyCpuUsageGeneratesError(t *testing.T) {
 	json.Unmarshal([]byte(jsonBytes), dockerStat)
 	// empty the PercpuUsage array
 	dockerStat.CPUStats.CPUUsage.PercpuUsage = make([]uint64, 0)
+	dockerStat.CPUStats.OnlineCPUs = 0
 	_, err := dockerStatsToContainerStats(dockerStat)
 	assert.Error(t, err, "expected error converting container stats with empty PercpuUsage")
 }
 
+func TestDockerStatsToContainerStatsOnlineCPUsWithoutPercpuUsage(t *testing.T) {
+	inputJsonFile, _ := filepath.Abs("./unix_test_stats.json")
+	jsonBytes, _ := ioutil.ReadFile(inputJsonFile)
+	dockerStat := &types.StatsJSON{}
+	json.Unmarshal([]byte(jsonBytes), dockerStat)
+	// cgroup v2 doesn't report the usage of each cpu
+	dockerStat.CPUStats.CPUUsage.PercpuUsage = nil
+	dockerStat.CPUStats.OnlineCPUs = 2
+	_, err := dockerStatsToContainerStats(dockerStat)
+	assert.NoError(t, err, "expected no error converting container stats with online cpus")
+}
+
 func TestDockerStatsToContainerStats(t *testing.T) {
 	// numCores is a global variable in package agent/stats
 	// which denotes the number of cpu cores
@@ -52,6 +65,8 @@ func TestDockerStatsToContainerStats(t *testing.T) {
 	// storage bytes check
 	assert.Equal(t, uint64(3), containerStats.storageReadBytes, "unexpected value for storageReadBytes", containerStats.storageReadBytes)
 	assert.Equal(t, uint64(15), containerStats.storageWriteBytes, "Unexpected value for storageWriteBytes", containerStats.storageWriteBytes)
+	assert.Equal(t, uint64(4), containerStats.storageReadOps, "unexpected value for storageReadOps")
+	assert.Equal(t, uint64(8), containerStats.storageWriteOps, "unexpected value for storageWriteOps")
 	// network stats check
 	netStats := containerStats.networkStats
 	assert.NotNil(t, netStats, "networkStats should not be nil")
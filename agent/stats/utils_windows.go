@@ -31,14 +31,25 @@ func dockerStatsToContainerStats(dockerStats *types.StatsJSON) (*ContainerStats,
 	cpuUsage := (dockerStats.CPUStats.CPUUsage.TotalUsage * 100) / numCores
 	memoryUsage := dockerStats.MemoryStats.PrivateWorkingSet
 	networkStats := getNetworkStats(dockerStats)
-	storageReadBytes := dockerStats.StorageStats.ReadSizeBytes
-	storageWriteBytes := dockerStats.StorageStats.WriteSizeBytes
+	storageStats := getStorageStats(dockerStats)
 	return &ContainerStats{
 		cpuUsage:          cpuUsage,
 		memoryUsage:       memoryUsage,
 		timestamp:         dockerStats.Read,
-		storageReadBytes:  storageReadBytes,
-		storageWriteBytes: storageWriteBytes,
+		storageReadBytes:  storageStats.ReadBytes,
+		storageWriteBytes: storageStats.WriteBytes,
+		storageReadOps:    storageStats.ReadOps,
+		storageWriteOps:   storageStats.WriteOps,
 		networkStats:      networkStats,
 	}, nil
 }
+
+// getStorageStats returns the block I/O of the container
+func getStorageStats(dockerStats *types.StatsJSON) *StorageStats {
+	return &StorageStats{
+		ReadBytes:  dockerStats.StorageStats.ReadSizeBytes,
+		WriteBytes: dockerStats.StorageStats.WriteSizeBytes,
+		ReadOps:    dockerStats.StorageStats.ReadCountNormalized,
+		WriteOps:   dockerStats.StorageStats.WriteCountNormalized,
+	}
+}
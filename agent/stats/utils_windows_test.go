@@ -61,5 +61,7 @@ func TestDockerStatsToContainerStats(t *testing.T) {
 		"unexpected value for storageReadBytes", containerStats.storageReadBytes)
 	assert.Equal(t, uint64(15), containerStats.storageWriteBytes,
 		"Unexpected value for storageWriteBytes", containerStats.storageWriteBytes)
+	assert.Equal(t, uint64(1), containerStats.storageReadOps, "unexpected value for storageReadOps")
+	assert.Equal(t, uint64(1), containerStats.storageWriteOps, "unexpected value for storageWriteOps")
 
 }
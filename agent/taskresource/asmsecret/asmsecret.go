@@ -24,19 +24,51 @@ import (
 	"github.com/pkg/errors"
 
 	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apierrors "github.com/aws/amazon-ecs-agent/agent/api/errors"
 	"github.com/aws/amazon-ecs-agent/agent/api/task/status"
 	"github.com/aws/amazon-ecs-agent/agent/asm"
 	"github.com/aws/amazon-ecs-agent/agent/asm/factory"
 	"github.com/aws/amazon-ecs-agent/agent/credentials"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource"
 	resourcestatus "github.com/aws/amazon-ecs-agent/agent/taskresource/status"
+	"github.com/aws/amazon-ecs-agent/agent/utils/retry"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
 )
 
 const (
 	// ResourceName is the name of the asmsecret resource
 	ResourceName = "asmsecret"
+
+	// retrievalAttempts is the number of times a secret value is retrieved
+	// while Secrets Manager is throttling the requests or failing them
+	retrievalAttempts          = 3
+	retrievalBackoffMin        = 250 * time.Millisecond
+	retrievalBackoffMax        = 2 * time.Second
+	retrievalBackoffJitter     = 0.2
+	retrievalBackoffMultiplier = 2
 )
 
+// newRetrievalBackoff creates the backoff between the retrievals of a secret
+// value. It's a variable so that tests can override it
+var newRetrievalBackoff = func() retry.Backoff {
+	return retry.NewExponentialBackoff(retrievalBackoffMin, retrievalBackoffMax,
+		retrievalBackoffJitter, retrievalBackoffMultiplier)
+}
+
+// secretRetrieval is a retrieval of a secret value, shared by all the secrets
+// that reference it, such as secrets selecting different keys of a JSON secret
+type secretRetrieval struct {
+	region  string
+	input   *secretsmanager.GetSecretValueInput
+	secrets map[string]apicontainer.Secret
+	// jsonKeys maps the cache keys of the secrets to the keys of the JSON
+	// object they select, if any
+	jsonKeys map[string]string
+}
+
 // ASMSecretResource represents secrets as a task resource.
 // The secrets are stored in AWS Secrets Manager.
 type ASMSecretResource struct {
@@ -265,10 +297,32 @@ func (secret *ASMSecretResource) Create() error {
 	seelog.Infof("ASM secret resource: retrieving secrets for containers in task: [%s]", secret.taskARN)
 	secret.secretData = make(map[string]string)
 
-	for _, asmsecret := range secret.getRequiredSecrets() {
+	retrievals := make(map[string]*secretRetrieval)
+	for secretKey, asmsecret := range secret.getRequiredSecrets() {
+		ref, err := asm.ParseSecretReference(asmsecret.ValueFrom)
+		if err != nil {
+			errorEvents <- err
+			continue
+		}
+		retrievalKey := strings.Join([]string{asmsecret.Region, ref.SecretID, ref.VersionStage, ref.VersionID}, "_")
+		retrieval, ok := retrievals[retrievalKey]
+		if !ok {
+			retrieval = &secretRetrieval{
+				region:   asmsecret.Region,
+				input:    ref.GetSecretValueInput(),
+				secrets:  make(map[string]apicontainer.Secret),
+				jsonKeys: make(map[string]string),
+			}
+			retrievals[retrievalKey] = retrieval
+		}
+		retrieval.secrets[secretKey] = asmsecret
+		retrieval.jsonKeys[secretKey] = ref.JSONKey
+	}
+
+	for _, retrieval := range retrievals {
 		wg.Add(1)
-		// Spin up goroutine per secret to speed up processing time
-		go secret.retrieveASMSecretValue(asmsecret, iamCredentials, &wg, errorEvents)
+		// Spin up goroutine per secret value to speed up processing time
+		go secret.retrieveASMSecretValue(retrieval, iamCredentials, &wg, errorEvents)
 	}
 
 	wg.Wait()
@@ -287,26 +341,57 @@ func (secret *ASMSecretResource) Create() error {
 	return nil
 }
 
-// retrieveASMSecretValue reads secret value from cache first, if not exists, call GetSecretFromASM to retrieve value
-// AWS secrets Manager
-func (secret *ASMSecretResource) retrieveASMSecretValue(apiSecret apicontainer.Secret, iamCredentials credentials.IAMRoleCredentials, wg *sync.WaitGroup, errorEvents chan error) {
+// retrieveASMSecretValue retrieves a secret value from AWS Secrets Manager and
+// caches it, or the key of it the secret selects, for each of the secrets that
+// reference it
+func (secret *ASMSecretResource) retrieveASMSecretValue(retrieval *secretRetrieval,
+	iamCredentials credentials.IAMRoleCredentials, wg *sync.WaitGroup, errorEvents chan error) {
 	defer wg.Done()
 
-	asmClient := secret.asmClientCreator.NewASMClient(apiSecret.Region, iamCredentials)
-	seelog.Infof("ASM secret resource: retrieving resource for secret %v in region %s for task: [%s]", apiSecret.ValueFrom, apiSecret.Region, secret.taskARN)
-	//for asm secret, ValueFrom can be arn or name
-	secretValue, err := asm.GetSecretFromASM(apiSecret.ValueFrom, asmClient)
+	asmClient := secret.asmClientCreator.NewASMClient(retrieval.region, iamCredentials)
+	seelog.Infof("ASM secret resource: retrieving resource for secret %s in region %s for task: [%s]",
+		aws.StringValue(retrieval.input.SecretId), retrieval.region, secret.taskARN)
+	var secretValue string
+	err := retry.RetryNWithBackoff(newRetrievalBackoff(), retrievalAttempts, func() error {
+		var err error
+		//for asm secret, the secret id can be arn or name
+		secretValue, err = asm.GetSecretFromASMWithInput(retrieval.input, asmClient)
+		if err != nil && !isRetriableASMError(err) {
+			return apierrors.NewRetriableError(apierrors.NewRetriable(false), err)
+		}
+		return err
+	})
 	if err != nil {
-		errorEvents <- fmt.Errorf("fetching secret data from AWS Secrets Manager in region %s: %v", apiSecret.Region, err)
+		errorEvents <- fmt.Errorf("fetching secret data from AWS Secrets Manager in region %s: %v", retrieval.region, err)
 		return
 	}
 
 	secret.lock.Lock()
 	defer secret.lock.Unlock()
 
-	// put secret value in secretData
-	secretKey := apiSecret.GetSecretResourceCacheKey()
-	secret.secretData[secretKey] = secretValue
+	for secretKey, apiSecret := range retrieval.secrets {
+		value := secretValue
+		if jsonKey := retrieval.jsonKeys[secretKey]; jsonKey != "" {
+			value, err = asm.ExtractJSONKey(secretValue, jsonKey)
+			if err != nil {
+				errorEvents <- fmt.Errorf("secret %s: %v", apiSecret.ValueFrom, err)
+				continue
+			}
+		}
+		// put secret value in secretData
+		secret.secretData[apiSecret.GetSecretResourceCacheKey()] = value
+	}
+}
+
+// isRetriableASMError returns true if the request to AWS Secrets Manager was
+// throttled or failed on the service side
+func isRetriableASMError(err error) bool {
+	cause := errors.Cause(err)
+	if request.IsErrorThrottle(cause) {
+		return true
+	}
+	reqErr, ok := cause.(awserr.RequestFailure)
+	return ok && reqErr.StatusCode() >= 500
 }
 
 // getRequiredSecrets returns the requiredSecrets field of asmsecret task resource
@@ -342,6 +427,16 @@ func (secret *ASMSecretResource) clearASMSecretValue() {
 	}
 }
 
+// GetSecretValue returns the retrieved value of the secret, so that the
+// resource is the secretprovider.Provider of the secrets of the task it retrieves
+func (secret *ASMSecretResource) GetSecretValue(apiSecret apicontainer.Secret) (string, error) {
+	value, ok := secret.GetCachedSecretValue(apiSecret.GetSecretResourceCacheKey())
+	if !ok {
+		return "", errors.Errorf("secret %s: value not retrieved", apiSecret.ValueFrom)
+	}
+	return value, nil
+}
+
 // GetCachedSecretValue retrieves the secret value from secretData field
 func (secret *ASMSecretResource) GetCachedSecretValue(secretKey string) (string, bool) {
 	secret.lock.RLock()
@@ -30,7 +30,9 @@ import (
 	mock_credentials "github.com/aws/amazon-ecs-agent/agent/credentials/mocks"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource"
 	resourcestatus "github.com/aws/amazon-ecs-agent/agent/taskresource/status"
+	"github.com/aws/amazon-ecs-agent/agent/utils/retry"
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/secretsmanager"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
@@ -268,3 +270,142 @@ func TestClearASMSecretValue(t *testing.T) {
 	asmRes.clearASMSecretValue()
 	assert.Equal(t, 0, len(asmRes.secretData))
 }
+
+func TestCreateWithJSONKeys(t *testing.T) {
+	const secretARN = "arn:aws:secretsmanager:us-west-2:123456789012:secret:db-AbCdEf"
+	usernameSecret := apicontainer.Secret{
+		Name:      "DB_USERNAME",
+		ValueFrom: secretARN + ":username:AWSCURRENT",
+		Region:    region1,
+		Provider:  "asm",
+	}
+	passwordSecret := apicontainer.Secret{
+		Name:      "DB_PASSWORD",
+		ValueFrom: secretARN + ":password:AWSCURRENT",
+		Region:    region1,
+		Provider:  "asm",
+	}
+	requiredSecretData := map[string]apicontainer.Secret{
+		usernameSecret.GetSecretResourceCacheKey(): usernameSecret,
+		passwordSecret.GetSecretResourceCacheKey(): passwordSecret,
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	credentialsManager := mock_credentials.NewMockManager(ctrl)
+	asmClientCreator := mock_factory.NewMockClientCreator(ctrl)
+	mockASMClient := mock_secretsmanageriface.NewMockSecretsManagerAPI(ctrl)
+
+	iamRoleCreds := credentials.IAMRoleCredentials{}
+	credentialsManager.EXPECT().GetTaskCredentials(executionCredentialsID).Return(
+		credentials.TaskIAMRoleCredentials{IAMRoleCredentials: iamRoleCreds}, true)
+	asmClientCreator.EXPECT().NewASMClient(region1, iamRoleCreds).Return(mockASMClient)
+	// both secrets reference the same secret value, so it's retrieved once
+	mockASMClient.EXPECT().GetSecretValue(gomock.Any()).Do(func(in *secretsmanager.GetSecretValueInput) {
+		assert.Equal(t, secretARN, aws.StringValue(in.SecretId))
+		assert.Equal(t, "AWSCURRENT", aws.StringValue(in.VersionStage))
+	}).Return(&secretsmanager.GetSecretValueOutput{
+		SecretString: aws.String(`{"username":"admin","password":"hunter2"}`),
+	}, nil)
+
+	asmRes := &ASMSecretResource{
+		executionCredentialsID: executionCredentialsID,
+		requiredSecrets:        requiredSecretData,
+		credentialsManager:     credentialsManager,
+		asmClientCreator:       asmClientCreator,
+	}
+	require.NoError(t, asmRes.Create())
+
+	value, ok := asmRes.GetCachedSecretValue(usernameSecret.GetSecretResourceCacheKey())
+	require.True(t, ok)
+	assert.Equal(t, "admin", value)
+
+	value, ok = asmRes.GetCachedSecretValue(passwordSecret.GetSecretResourceCacheKey())
+	require.True(t, ok)
+	assert.Equal(t, "hunter2", value)
+}
+
+func TestCreateWithMissingJSONKey(t *testing.T) {
+	const secretARN = "arn:aws:secretsmanager:us-west-2:123456789012:secret:db-AbCdEf"
+	apiSecret := apicontainer.Secret{
+		Name:      "DB_PASSWORD",
+		ValueFrom: secretARN + ":password::",
+		Region:    region1,
+		Provider:  "asm",
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	credentialsManager := mock_credentials.NewMockManager(ctrl)
+	asmClientCreator := mock_factory.NewMockClientCreator(ctrl)
+	mockASMClient := mock_secretsmanageriface.NewMockSecretsManagerAPI(ctrl)
+
+	iamRoleCreds := credentials.IAMRoleCredentials{}
+	credentialsManager.EXPECT().GetTaskCredentials(executionCredentialsID).Return(
+		credentials.TaskIAMRoleCredentials{IAMRoleCredentials: iamRoleCreds}, true)
+	asmClientCreator.EXPECT().NewASMClient(region1, iamRoleCreds).Return(mockASMClient)
+	mockASMClient.EXPECT().GetSecretValue(gomock.Any()).Return(&secretsmanager.GetSecretValueOutput{
+		SecretString: aws.String(`{"username":"admin"}`),
+	}, nil)
+
+	asmRes := &ASMSecretResource{
+		executionCredentialsID: executionCredentialsID,
+		requiredSecrets:        map[string]apicontainer.Secret{apiSecret.GetSecretResourceCacheKey(): apiSecret},
+		credentialsManager:     credentialsManager,
+		asmClientCreator:       asmClientCreator,
+	}
+	assert.Error(t, asmRes.Create())
+	assert.Equal(t, "secret "+apiSecret.ValueFrom+": secret value does not contain JSON key password",
+		asmRes.GetTerminalReason())
+}
+
+func TestCreateRetriesThrottledRequests(t *testing.T) {
+	defer func(original func() retry.Backoff) {
+		newRetrievalBackoff = original
+	}(newRetrievalBackoff)
+	newRetrievalBackoff = func() retry.Backoff {
+		return retry.NewExponentialBackoff(time.Millisecond, time.Millisecond, 0, 1)
+	}
+
+	requiredSecretData := map[string]apicontainer.Secret{
+		secretKeyWest1: {
+			Name:      secretName1,
+			ValueFrom: valueFrom1,
+			Region:    region1,
+			Provider:  "asm",
+		},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	credentialsManager := mock_credentials.NewMockManager(ctrl)
+	asmClientCreator := mock_factory.NewMockClientCreator(ctrl)
+	mockASMClient := mock_secretsmanageriface.NewMockSecretsManagerAPI(ctrl)
+
+	iamRoleCreds := credentials.IAMRoleCredentials{}
+	throttlingErr := awserr.NewRequestFailure(awserr.New("ThrottlingException", "rate exceeded", nil), 400, "request-id")
+	gomock.InOrder(
+		credentialsManager.EXPECT().GetTaskCredentials(executionCredentialsID).Return(
+			credentials.TaskIAMRoleCredentials{IAMRoleCredentials: iamRoleCreds}, true),
+		asmClientCreator.EXPECT().NewASMClient(region1, iamRoleCreds).Return(mockASMClient),
+		mockASMClient.EXPECT().GetSecretValue(gomock.Any()).Return(nil, throttlingErr),
+		mockASMClient.EXPECT().GetSecretValue(gomock.Any()).Return(&secretsmanager.GetSecretValueOutput{
+			SecretString: aws.String(secretValue),
+		}, nil),
+	)
+
+	asmRes := &ASMSecretResource{
+		executionCredentialsID: executionCredentialsID,
+		requiredSecrets:        requiredSecretData,
+		credentialsManager:     credentialsManager,
+		asmClientCreator:       asmClientCreator,
+	}
+	require.NoError(t, asmRes.Create())
+
+	value, ok := asmRes.GetCachedSecretValue(secretKeyWest1)
+	require.True(t, ok)
+	assert.Equal(t, secretValue, value)
+}
@@ -36,13 +36,23 @@ import (
 const (
 	memorySubsystem           = "/memory"
 	memoryUseHierarchy        = "memory.use_hierarchy"
+	cpuSubsystem              = "/cpu"
+	cpuCFSBurst               = "cpu.cfs_burst_us"
 	rootReadOnlyPermissions   = os.FileMode(400)
 	resourceName              = "cgroup"
 	resourceProvisioningError = "CgroupError: Agent could not create task's platform resources"
+	// cpuMaxBurst is the cpu burst file of the cgroup v2 unified hierarchy
+	cpuMaxBurst = "cpu.max.burst"
+	// unifiedControllersFile only exists at the root of the cgroup v2
+	// unified hierarchy
+	unifiedControllersFile = "cgroup.controllers"
 )
 
 var (
 	enableMemoryHierarchy = []byte(strconv.Itoa(1))
+	// osStat is used to detect the cgroup hierarchy and the cpu burst
+	// support of the kernel, and is overridden in tests
+	osStat = os.Stat
 )
 
 // CgroupResource represents Cgroup resource
@@ -52,6 +62,7 @@ type CgroupResource struct {
 	cgroupRoot          string
 	cgroupMountPath     string
 	resourceSpec        specs.LinuxResources
+	cpuBurst            uint64
 	ioutil              ioutilwrapper.IOUtil
 	createdAt           time.Time
 	desiredStatusUnsafe resourcestatus.ResourceStatus
@@ -104,6 +115,22 @@ func (cgroup *CgroupResource) SetIOUtil(ioutil ioutilwrapper.IOUtil) {
 	cgroup.ioutil = ioutil
 }
 
+// SetCPUBurst sets the CPU burst of the cgroup in microseconds
+func (cgroup *CgroupResource) SetCPUBurst(cpuBurst uint64) {
+	cgroup.lock.Lock()
+	defer cgroup.lock.Unlock()
+
+	cgroup.cpuBurst = cpuBurst
+}
+
+// GetCPUBurst returns the CPU burst of the cgroup in microseconds
+func (cgroup *CgroupResource) GetCPUBurst() uint64 {
+	cgroup.lock.RLock()
+	defer cgroup.lock.RUnlock()
+
+	return cgroup.cpuBurst
+}
+
 // SetDesiredStatus safely sets the desired status of the resource
 func (cgroup *CgroupResource) SetDesiredStatus(status resourcestatus.ResourceStatus) {
 	cgroup.lock.Lock()
@@ -269,13 +296,50 @@ func (cgroup *CgroupResource) setupTaskCgroup() error {
 		return errors.Wrapf(err, "cgroup resource [%s]: setup cgroup: unable to create cgroup at %s", cgroup.taskARN, cgroupRoot)
 	}
 
-	// enabling cgroup memory hierarchy by doing 'echo 1 > memory.use_hierarchy'
-	memoryHierarchyPath := filepath.Join(cgroup.cgroupMountPath, memorySubsystem, cgroupRoot, memoryUseHierarchy)
-	err = cgroup.ioutil.WriteFile(memoryHierarchyPath, enableMemoryHierarchy, rootReadOnlyPermissions)
-	if err != nil {
-		return errors.Wrapf(err, "cgroup resource [%s]: setup cgroup: unable to set use hierarchy flag", cgroup.taskARN)
+	unified := cgroup.unifiedHierarchy()
+	// the memory hierarchy is always enabled in the cgroup v2 unified hierarchy
+	if !unified {
+		// enabling cgroup memory hierarchy by doing 'echo 1 > memory.use_hierarchy'
+		memoryHierarchyPath := filepath.Join(cgroup.cgroupMountPath, memorySubsystem, cgroupRoot, memoryUseHierarchy)
+		err = cgroup.ioutil.WriteFile(memoryHierarchyPath, enableMemoryHierarchy, rootReadOnlyPermissions)
+		if err != nil {
+			return errors.Wrapf(err, "cgroup resource [%s]: setup cgroup: unable to set use hierarchy flag", cgroup.taskARN)
+		}
 	}
 
+	if cpuBurst := cgroup.GetCPUBurst(); cpuBurst > 0 {
+		return cgroup.setCPUBurst(cgroupRoot, cpuBurst, unified)
+	}
+
+	return nil
+}
+
+// unifiedHierarchy returns true if the cgroups are mounted as the cgroup v2
+// unified hierarchy
+func (cgroup *CgroupResource) unifiedHierarchy() bool {
+	_, err := osStat(filepath.Join(cgroup.cgroupMountPath, unifiedControllersFile))
+	return err == nil
+}
+
+// setCPUBurst sets the cpu burst of the task cgroup by doing
+// 'echo <burst> > cpu.cfs_burst_us' with cgroup v1, or
+// 'echo <burst> > cpu.max.burst' with cgroup v2. The burst files only exist
+// with kernels that support cfs bandwidth burst (5.14+), the task runs
+// without a burst on older kernels
+func (cgroup *CgroupResource) setCPUBurst(cgroupRoot string, cpuBurst uint64, unified bool) error {
+	cpuBurstPath := filepath.Join(cgroup.cgroupMountPath, cpuSubsystem, cgroupRoot, cpuCFSBurst)
+	if unified {
+		cpuBurstPath = filepath.Join(cgroup.cgroupMountPath, cgroupRoot, cpuMaxBurst)
+	}
+	if _, err := osStat(cpuBurstPath); os.IsNotExist(err) {
+		seelog.Warnf("Cgroup resource [%s]: cpu burst isn't supported by the kernel, which requires 5.14 or later, skipping cpu burst of %dus",
+			cgroup.taskARN, cpuBurst)
+		return nil
+	}
+	err := cgroup.ioutil.WriteFile(cpuBurstPath, []byte(strconv.FormatUint(cpuBurst, 10)), rootReadOnlyPermissions)
+	if err != nil {
+		return errors.Wrapf(err, "cgroup resource [%s]: setup cgroup: unable to set cpu burst", cgroup.taskARN)
+	}
 	return nil
 }
 
@@ -301,6 +365,7 @@ type cgroupResourceJSON struct {
 	DesiredStatus   *CgroupStatus        `json:"desiredStatus"`
 	KnownStatus     *CgroupStatus        `json:"knownStatus"`
 	LinuxSpec       specs.LinuxResources `json:"resourceSpec"`
+	CPUBurst        uint64               `json:"cpuBurst,omitempty"`
 }
 
 // MarshalJSON marshals CgroupResource object using duplicate struct CgroupResourceJSON
@@ -323,6 +388,7 @@ func (cgroup *CgroupResource) MarshalJSON() ([]byte, error) {
 			return &status
 		}(),
 		cgroup.resourceSpec,
+		cgroup.GetCPUBurst(),
 	})
 }
 
@@ -337,6 +403,7 @@ func (cgroup *CgroupResource) UnmarshalJSON(b []byte) error {
 	cgroup.cgroupRoot = temp.CgroupRoot
 	cgroup.cgroupMountPath = temp.CgroupMountPath
 	cgroup.resourceSpec = temp.LinuxSpec
+	cgroup.cpuBurst = temp.CPUBurst
 	if temp.DesiredStatus != nil {
 		cgroup.SetDesiredStatus(resourcestatus.ResourceStatus(*temp.DesiredStatus))
 	}
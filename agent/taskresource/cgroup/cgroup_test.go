@@ -18,6 +18,7 @@ package cgroup
 import (
 	"errors"
 	"fmt"
+	"os"
 	"testing"
 	"time"
 
@@ -41,25 +42,130 @@ const (
 	taskID          = "taskID"
 )
 
+// stubStat makes osStat find only the given files, and returns a function
+// that restores it
+func stubStat(files ...string) func() {
+	osStat = func(name string) (os.FileInfo, error) {
+		for _, file := range files {
+			if name == file {
+				return nil, nil
+			}
+		}
+		return nil, os.ErrNotExist
+	}
+	return func() {
+		osStat = os.Stat
+	}
+}
+
 func TestCreateHappyPath(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
+	defer stubStat()()
+
+	mockControl := mock_control.NewMockControl(ctrl)
+	mockIO := mock_ioutilwrapper.NewMockIOUtil(ctrl)
+
+	cgroupMemoryPath := fmt.Sprintf("/sys/fs/cgroup/memory/ecs/%s/memory.use_hierarchy", taskID)
+	cgroupRoot := fmt.Sprintf("/ecs/%s", taskID)
+
+	gomock.InOrder(
+		mockControl.EXPECT().Exists(gomock.Any()).Return(false),
+		mockControl.EXPECT().Create(gomock.Any()).Return(nil, nil),
+		mockIO.EXPECT().WriteFile(cgroupMemoryPath, gomock.Any(), gomock.Any()).Return(nil),
+	)
+	cgroupResource := NewCgroupResource("taskArn", mockControl, mockIO, cgroupRoot, cgroupMountPath, specs.LinuxResources{})
+	assert.NoError(t, cgroupResource.Create())
+}
+
+func TestCreateCPUBurst(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
 
 	mockControl := mock_control.NewMockControl(ctrl)
 	mockIO := mock_ioutilwrapper.NewMockIOUtil(ctrl)
 
 	cgroupMemoryPath := fmt.Sprintf("/sys/fs/cgroup/memory/ecs/%s/memory.use_hierarchy", taskID)
+	cgroupCPUBurstPath := fmt.Sprintf("/sys/fs/cgroup/cpu/ecs/%s/cpu.cfs_burst_us", taskID)
 	cgroupRoot := fmt.Sprintf("/ecs/%s", taskID)
+	defer stubStat(cgroupCPUBurstPath)()
 
 	gomock.InOrder(
 		mockControl.EXPECT().Exists(gomock.Any()).Return(false),
 		mockControl.EXPECT().Create(gomock.Any()).Return(nil, nil),
 		mockIO.EXPECT().WriteFile(cgroupMemoryPath, gomock.Any(), gomock.Any()).Return(nil),
+		mockIO.EXPECT().WriteFile(cgroupCPUBurstPath, []byte("50000"), gomock.Any()).Return(nil),
 	)
 	cgroupResource := NewCgroupResource("taskArn", mockControl, mockIO, cgroupRoot, cgroupMountPath, specs.LinuxResources{})
+	cgroupResource.SetCPUBurst(50000)
 	assert.NoError(t, cgroupResource.Create())
 }
 
+func TestCreateCPUBurstUnifiedHierarchy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockControl := mock_control.NewMockControl(ctrl)
+	mockIO := mock_ioutilwrapper.NewMockIOUtil(ctrl)
+
+	cgroupCPUBurstPath := fmt.Sprintf("/sys/fs/cgroup/ecs/%s/cpu.max.burst", taskID)
+	cgroupRoot := fmt.Sprintf("/ecs/%s", taskID)
+	defer stubStat("/sys/fs/cgroup/cgroup.controllers", cgroupCPUBurstPath)()
+
+	// The memory hierarchy is always enabled with cgroup v2
+	gomock.InOrder(
+		mockControl.EXPECT().Exists(gomock.Any()).Return(false),
+		mockControl.EXPECT().Create(gomock.Any()).Return(nil, nil),
+		mockIO.EXPECT().WriteFile(cgroupCPUBurstPath, []byte("50000"), gomock.Any()).Return(nil),
+	)
+	cgroupResource := NewCgroupResource("taskArn", mockControl, mockIO, cgroupRoot, cgroupMountPath, specs.LinuxResources{})
+	cgroupResource.SetCPUBurst(50000)
+	assert.NoError(t, cgroupResource.Create())
+}
+
+func TestCreateCPUBurstUnsupportedKernel(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	defer stubStat()()
+
+	mockControl := mock_control.NewMockControl(ctrl)
+	mockIO := mock_ioutilwrapper.NewMockIOUtil(ctrl)
+
+	cgroupMemoryPath := fmt.Sprintf("/sys/fs/cgroup/memory/ecs/%s/memory.use_hierarchy", taskID)
+	cgroupRoot := fmt.Sprintf("/ecs/%s", taskID)
+
+	// The task runs without a burst when the kernel has no burst file
+	gomock.InOrder(
+		mockControl.EXPECT().Exists(gomock.Any()).Return(false),
+		mockControl.EXPECT().Create(gomock.Any()).Return(nil, nil),
+		mockIO.EXPECT().WriteFile(cgroupMemoryPath, gomock.Any(), gomock.Any()).Return(nil),
+	)
+	cgroupResource := NewCgroupResource("taskArn", mockControl, mockIO, cgroupRoot, cgroupMountPath, specs.LinuxResources{})
+	cgroupResource.SetCPUBurst(50000)
+	assert.NoError(t, cgroupResource.Create())
+}
+
+func TestCreateCPUBurstError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockControl := mock_control.NewMockControl(ctrl)
+	mockIO := mock_ioutilwrapper.NewMockIOUtil(ctrl)
+
+	cgroupRoot := fmt.Sprintf("/ecs/%s", taskID)
+	defer stubStat(fmt.Sprintf("/sys/fs/cgroup/cpu/ecs/%s/cpu.cfs_burst_us", taskID))()
+
+	gomock.InOrder(
+		mockControl.EXPECT().Exists(gomock.Any()).Return(false),
+		mockControl.EXPECT().Create(gomock.Any()).Return(nil, nil),
+		mockIO.EXPECT().WriteFile(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil),
+		mockIO.EXPECT().WriteFile(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("permission denied")),
+	)
+	cgroupResource := NewCgroupResource("taskArn", mockControl, mockIO, cgroupRoot, cgroupMountPath, specs.LinuxResources{})
+	cgroupResource.SetCPUBurst(50000)
+	assert.Error(t, cgroupResource.Create())
+}
+
 func TestCreateCgroupPathExists(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -166,3 +272,15 @@ func TestUnmarshal(t *testing.T) {
 	assert.Equal(t, resourcestatus.ResourceStatus(CgroupCreated), unmarshalledCgroup.GetDesiredStatus())
 	assert.Equal(t, resourcestatus.ResourceStatus(CgroupStatusNone), unmarshalledCgroup.GetKnownStatus())
 }
+
+func TestMarshalUnmarshalCPUBurst(t *testing.T) {
+	cgroup := NewCgroupResource("", nil, nil, "/ecs/taskid", "/sys/fs/cgroup", specs.LinuxResources{})
+	cgroup.SetCPUBurst(50000)
+
+	bytes, err := cgroup.MarshalJSON()
+	assert.NoError(t, err)
+
+	unmarshalledCgroup := &CgroupResource{}
+	assert.NoError(t, unmarshalledCgroup.UnmarshalJSON(bytes))
+	assert.Equal(t, uint64(50000), unmarshalledCgroup.GetCPUBurst())
+}
@@ -0,0 +1,384 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package efs
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/api/task/status"
+	"github.com/aws/amazon-ecs-agent/agent/taskresource"
+	resourcestatus "github.com/aws/amazon-ecs-agent/agent/taskresource/status"
+	"github.com/cihub/seelog"
+	"github.com/pkg/errors"
+)
+
+const (
+	// ResourceName is the name of the efs resource
+	ResourceName = "efs"
+
+	resourceProvisioningError = "EFSError: Agent could not mount task's EFS volumes"
+
+	mountDirectoryPermission = 0755
+)
+
+// EFSResource represents an EFS file system mounted on the host for a volume
+// of a task, which is bind mounted into the containers of the task
+type EFSResource struct {
+	// Name is the name of the task volume
+	Name string
+	// TaskARN is the ARN of the task the file system is mounted for
+	TaskARN string
+	// VolumeConfig is the configuration of the EFS volume
+	VolumeConfig EFSVolumeConfig
+	// MountPath is the directory the agent mounts the file system at, which is
+	// the host path of the volume as seen from the agent
+	MountPath string
+	// CredentialsRelativeURI is the relative URI of the credentials endpoint of
+	// the task role, which efs-utils gets credentials from for IAM authorization
+	CredentialsRelativeURI string
+	createdAtUnsafe        time.Time
+	desiredStatusUnsafe    resourcestatus.ResourceStatus
+	knownStatusUnsafe      resourcestatus.ResourceStatus
+	// appliedStatusUnsafe is the status that has been "applied" (e.g., we've called some
+	// operation such as 'Create' on the resource) but we don't yet know that the
+	// application was successful, which may then change the known status. This is
+	// used while progressing resource states in progressTask() of task manager
+	appliedStatusUnsafe resourcestatus.ResourceStatus
+	statusToTransitions map[resourcestatus.ResourceStatus]func() error
+	mounter             Mounter
+	ctx                 context.Context
+
+	// terminalReason should be set for resource creation failures. This ensures
+	// the resource object carries some context for why provisoning failed.
+	terminalReason     string
+	terminalReasonOnce sync.Once
+
+	// lock is used for fields that are accessed and updated concurrently
+	lock sync.RWMutex
+}
+
+// NewEFSResource returns an EFS resource for the volume of the task, mounted
+// at mountPath
+func NewEFSResource(ctx context.Context,
+	name string,
+	taskARN string,
+	volumeConfig EFSVolumeConfig,
+	mountPath string,
+	credentialsRelativeURI string,
+	mounter Mounter) (*EFSResource, error) {
+
+	if err := volumeConfig.Validate(); err != nil {
+		return nil, errors.Wrapf(err, "volume [%s]", name)
+	}
+	if volumeConfig.iamEnabled() && credentialsRelativeURI == "" {
+		return nil, errors.Errorf("volume [%s]: efs volume: iam authorization requires a task role", name)
+	}
+
+	e := &EFSResource{
+		Name:                   name,
+		TaskARN:                taskARN,
+		VolumeConfig:           volumeConfig,
+		MountPath:              mountPath,
+		CredentialsRelativeURI: credentialsRelativeURI,
+		mounter:                mounter,
+		ctx:                    ctx,
+	}
+	e.initStatusToTransitions()
+	return e, nil
+}
+
+// Initialize initializes the EFS resource fields that aren't persisted in the
+// state file
+func (efs *EFSResource) Initialize(resourceFields *taskresource.ResourceFields,
+	taskKnownStatus status.TaskStatus,
+	taskDesiredStatus status.TaskStatus) {
+
+	efs.ctx = resourceFields.Ctx
+	efs.mounter = NewMounter()
+	efs.initStatusToTransitions()
+}
+
+func (efs *EFSResource) initStatusToTransitions() {
+	statusToTransitions := map[resourcestatus.ResourceStatus]func() error{
+		resourcestatus.ResourceStatus(EFSMounted): efs.Create,
+	}
+
+	efs.statusToTransitions = statusToTransitions
+}
+
+// GetName returns the name of the task volume
+func (efs *EFSResource) GetName() string {
+	return efs.Name
+}
+
+// DesiredTerminal returns true if the file system's desired status is UNMOUNTED
+func (efs *EFSResource) DesiredTerminal() bool {
+	efs.lock.RLock()
+	defer efs.lock.RUnlock()
+
+	return efs.desiredStatusUnsafe == resourcestatus.ResourceStatus(EFSUnmounted)
+}
+
+// GetTerminalReason returns an error string to propagate up through to task
+// state change messages
+func (efs *EFSResource) GetTerminalReason() string {
+	if efs.terminalReason == "" {
+		return resourceProvisioningError
+	}
+	return efs.terminalReason
+}
+
+func (efs *EFSResource) setTerminalReason(reason string) {
+	efs.terminalReasonOnce.Do(func() {
+		seelog.Infof("EFS Resource [%s]: setting terminal reason for efs resource in task: [%s]", efs.Name, efs.TaskARN)
+		efs.terminalReason = reason
+	})
+}
+
+// SetDesiredStatus safely sets the desired status of the resource
+func (efs *EFSResource) SetDesiredStatus(status resourcestatus.ResourceStatus) {
+	efs.lock.Lock()
+	defer efs.lock.Unlock()
+
+	efs.desiredStatusUnsafe = status
+}
+
+// GetDesiredStatus safely returns the desired status of the resource
+func (efs *EFSResource) GetDesiredStatus() resourcestatus.ResourceStatus {
+	efs.lock.RLock()
+	defer efs.lock.RUnlock()
+
+	return efs.desiredStatusUnsafe
+}
+
+// SetKnownStatus safely sets the currently known status of the resource
+func (efs *EFSResource) SetKnownStatus(status resourcestatus.ResourceStatus) {
+	efs.lock.Lock()
+	defer efs.lock.Unlock()
+
+	efs.knownStatusUnsafe = status
+	efs.updateAppliedStatusUnsafe(status)
+}
+
+// updateAppliedStatusUnsafe updates the resource transitioning status
+func (efs *EFSResource) updateAppliedStatusUnsafe(knownStatus resourcestatus.ResourceStatus) {
+	if efs.appliedStatusUnsafe == resourcestatus.ResourceStatus(EFSStatusNone) {
+		return
+	}
+
+	// Check if the resource transition has already finished
+	if efs.appliedStatusUnsafe <= knownStatus {
+		efs.appliedStatusUnsafe = resourcestatus.ResourceStatus(EFSStatusNone)
+	}
+}
+
+// GetKnownStatus safely returns the currently known status of the resource
+func (efs *EFSResource) GetKnownStatus() resourcestatus.ResourceStatus {
+	efs.lock.RLock()
+	defer efs.lock.RUnlock()
+
+	return efs.knownStatusUnsafe
+}
+
+// KnownCreated returns true if the file system's known status is MOUNTED
+func (efs *EFSResource) KnownCreated() bool {
+	efs.lock.RLock()
+	defer efs.lock.RUnlock()
+
+	return efs.knownStatusUnsafe == resourcestatus.ResourceStatus(EFSMounted)
+}
+
+// TerminalStatus returns the last transition state of the file system
+func (efs *EFSResource) TerminalStatus() resourcestatus.ResourceStatus {
+	return resourcestatus.ResourceStatus(EFSUnmounted)
+}
+
+// NextKnownState returns the state that the resource should
+// progress to based on its `KnownState`.
+func (efs *EFSResource) NextKnownState() resourcestatus.ResourceStatus {
+	return efs.GetKnownStatus() + 1
+}
+
+// SteadyState returns the transition state of the resource defined as "ready"
+func (efs *EFSResource) SteadyState() resourcestatus.ResourceStatus {
+	return resourcestatus.ResourceStatus(EFSMounted)
+}
+
+// ApplyTransition calls the function required to move to the specified status
+func (efs *EFSResource) ApplyTransition(nextState resourcestatus.ResourceStatus) error {
+	transitionFunc, ok := efs.statusToTransitions[nextState]
+	if !ok {
+		errW := errors.Errorf("efs [%s]: transition to %s impossible", efs.Name,
+			efs.StatusString(nextState))
+		efs.setTerminalReason(errW.Error())
+		return errW
+	}
+	return transitionFunc()
+}
+
+// SetAppliedStatus sets the applied status of resource and returns whether
+// the resource is already in a transition
+func (efs *EFSResource) SetAppliedStatus(status resourcestatus.ResourceStatus) bool {
+	efs.lock.Lock()
+	defer efs.lock.Unlock()
+
+	if efs.appliedStatusUnsafe != resourcestatus.ResourceStatus(EFSStatusNone) {
+		// return false to indicate the set operation failed
+		return false
+	}
+
+	efs.appliedStatusUnsafe = status
+	return true
+}
+
+// StatusString returns the string of the efs resource status
+func (efs *EFSResource) StatusString(status resourcestatus.ResourceStatus) string {
+	return EFSStatus(status).String()
+}
+
+// SetCreatedAt sets the timestamp for resource's creation time
+func (efs *EFSResource) SetCreatedAt(createdAt time.Time) {
+	if createdAt.IsZero() {
+		return
+	}
+	efs.lock.Lock()
+	defer efs.lock.Unlock()
+
+	efs.createdAtUnsafe = createdAt
+}
+
+// GetCreatedAt sets the timestamp for resource's creation time
+func (efs *EFSResource) GetCreatedAt() time.Time {
+	efs.lock.RLock()
+	defer efs.lock.RUnlock()
+
+	return efs.createdAtUnsafe
+}
+
+// mountOptions returns the efs-utils mount options of the file system
+func (efs *EFSResource) mountOptions() []string {
+	var options []string
+	if efs.VolumeConfig.transitEncryptionEnabled() {
+		options = append(options, "tls")
+		if efs.VolumeConfig.TransitEncryptionPort != 0 {
+			options = append(options, "tlsport="+strconv.FormatInt(efs.VolumeConfig.TransitEncryptionPort, 10))
+		}
+	}
+	if efs.VolumeConfig.AuthConfig.AccessPointID != "" {
+		options = append(options, "accesspoint="+efs.VolumeConfig.AuthConfig.AccessPointID)
+	}
+	if efs.VolumeConfig.iamEnabled() {
+		options = append(options, "iam", "awscredsuri="+efs.CredentialsRelativeURI)
+	}
+	return options
+}
+
+// Create mounts the file system
+func (efs *EFSResource) Create() error {
+	seelog.Debugf("Mounting file system %s at %s for volume %s of task %s",
+		efs.VolumeConfig.device(), efs.MountPath, efs.Name, efs.TaskARN)
+	if err := os.MkdirAll(efs.MountPath, mountDirectoryPermission); err != nil {
+		err = errors.Wrapf(err, "efs [%s]: unable to create mount directory", efs.Name)
+		efs.setTerminalReason(err.Error())
+		return err
+	}
+	if err := efs.mounter.Mount(efs.ctx, efs.VolumeConfig.device(), efs.MountPath, efs.mountOptions()); err != nil {
+		efs.setTerminalReason(err.Error())
+		return err
+	}
+	return nil
+}
+
+// Cleanup unmounts the file system and removes the mount directory. The
+// directories are only removed once empty, so that a file system that's still
+// mounted is never deleted from
+func (efs *EFSResource) Cleanup() error {
+	if efs.GetKnownStatus() < resourcestatus.ResourceStatus(EFSMounted) {
+		seelog.Debugf("EFS [%s]: file system of task %s was never mounted, not unmounting", efs.Name, efs.TaskARN)
+		return nil
+	}
+
+	seelog.Debugf("Unmounting file system at %s", efs.MountPath)
+	if err := efs.mounter.Unmount(efs.ctx, efs.MountPath); err != nil {
+		efs.setTerminalReason(err.Error())
+		return err
+	}
+	if err := os.Remove(efs.MountPath); err != nil && !os.IsNotExist(err) {
+		seelog.Warnf("EFS [%s]: unable to remove mount directory %s: %v", efs.Name, efs.MountPath, err)
+		return nil
+	}
+	// remove the directory of the task once all of its volumes are unmounted
+	if err := os.Remove(filepath.Dir(efs.MountPath)); err != nil && !os.IsNotExist(err) {
+		seelog.Debugf("EFS [%s]: not removing directory of task %s: %v", efs.Name, efs.TaskARN, err)
+	}
+	return nil
+}
+
+// efsResourceJSON duplicates EFSResource fields, only for marshalling and unmarshalling purposes
+type efsResourceJSON struct {
+	Name                   string          `json:"name"`
+	TaskARN                string          `json:"taskARN"`
+	VolumeConfig           EFSVolumeConfig `json:"efsVolumeConfiguration"`
+	MountPath              string          `json:"mountPath"`
+	CredentialsRelativeURI string          `json:"credentialsRelativeURI"`
+	CreatedAt              time.Time       `json:"createdAt"`
+	DesiredStatus          *EFSStatus      `json:"desiredStatus"`
+	KnownStatus            *EFSStatus      `json:"knownStatus"`
+}
+
+// MarshalJSON marshals EFSResource object using duplicate struct efsResourceJSON
+func (efs *EFSResource) MarshalJSON() ([]byte, error) {
+	if efs == nil {
+		return nil, nil
+	}
+	return json.Marshal(efsResourceJSON{
+		efs.Name,
+		efs.TaskARN,
+		efs.VolumeConfig,
+		efs.MountPath,
+		efs.CredentialsRelativeURI,
+		efs.GetCreatedAt(),
+		func() *EFSStatus { desiredState := EFSStatus(efs.GetDesiredStatus()); return &desiredState }(),
+		func() *EFSStatus { knownState := EFSStatus(efs.GetKnownStatus()); return &knownState }(),
+	})
+}
+
+// UnmarshalJSON unmarshals EFSResource object using duplicate struct efsResourceJSON
+func (efs *EFSResource) UnmarshalJSON(b []byte) error {
+	temp := &efsResourceJSON{}
+
+	if err := json.Unmarshal(b, &temp); err != nil {
+		return err
+	}
+
+	efs.Name = temp.Name
+	efs.TaskARN = temp.TaskARN
+	efs.VolumeConfig = temp.VolumeConfig
+	efs.MountPath = temp.MountPath
+	efs.CredentialsRelativeURI = temp.CredentialsRelativeURI
+	efs.SetCreatedAt(temp.CreatedAt)
+	if temp.DesiredStatus != nil {
+		efs.SetDesiredStatus(resourcestatus.ResourceStatus(*temp.DesiredStatus))
+	}
+	if temp.KnownStatus != nil {
+		efs.SetKnownStatus(resourcestatus.ResourceStatus(*temp.KnownStatus))
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+// +build unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package efs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	mock_efs "github.com/aws/amazon-ecs-agent/agent/taskresource/efs/mocks"
+	resourcestatus "github.com/aws/amazon-ecs-agent/agent/taskresource/status"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	taskARN        = "arn:aws:ecs:us-west-2:123456789012:task/task-id"
+	volumeName     = "data"
+	credentialsURI = "/v2/credentials/credentials-id"
+)
+
+func TestNewEFSResourceIAMWithoutTaskRole(t *testing.T) {
+	_, err := NewEFSResource(context.TODO(), volumeName, taskARN, EFSVolumeConfig{
+		FileSystemID:      "fs-12345678",
+		TransitEncryption: EFSEnabled,
+		AuthConfig:        EFSAuthConfig{IAM: EFSEnabled},
+	}, "/data/efs/task-id/data", "", nil)
+	assert.Error(t, err)
+}
+
+func TestCreateMountsWithOptions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockMounter := mock_efs.NewMockMounter(ctrl)
+
+	mountPath := filepath.Join(tempDir(t), "task-id", volumeName)
+	efs, err := NewEFSResource(context.TODO(), volumeName, taskARN, EFSVolumeConfig{
+		FileSystemID:          "fs-12345678",
+		RootDirectory:         "/shared",
+		TransitEncryption:     EFSEnabled,
+		TransitEncryptionPort: 20049,
+		AuthConfig: EFSAuthConfig{
+			AccessPointID: "fsap-12345678",
+			IAM:           EFSEnabled,
+		},
+	}, mountPath, credentialsURI, mockMounter)
+	require.NoError(t, err)
+
+	mockMounter.EXPECT().Mount(gomock.Any(), "fs-12345678:/shared", mountPath, []string{
+		"tls", "tlsport=20049", "accesspoint=fsap-12345678", "iam", "awscredsuri=" + credentialsURI,
+	}).Return(nil)
+
+	require.NoError(t, efs.Create())
+	info, err := os.Stat(mountPath)
+	require.NoError(t, err)
+	assert.True(t, info.IsDir())
+}
+
+func TestCreateMountError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockMounter := mock_efs.NewMockMounter(ctrl)
+
+	efs, err := NewEFSResource(context.TODO(), volumeName, taskARN, EFSVolumeConfig{
+		FileSystemID: "fs-12345678",
+	}, filepath.Join(tempDir(t), "task-id", volumeName), "", mockMounter)
+	require.NoError(t, err)
+
+	mockMounter.EXPECT().Mount(gomock.Any(), "fs-12345678:/", gomock.Any(), nil).Return(
+		errors.New("mount.nfs4: Connection timed out"))
+
+	assert.Error(t, efs.Create())
+	assert.Equal(t, "mount.nfs4: Connection timed out", efs.GetTerminalReason())
+}
+
+func TestCleanupUnmountsAndRemovesDirectories(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockMounter := mock_efs.NewMockMounter(ctrl)
+
+	taskDir := filepath.Join(tempDir(t), "task-id")
+	mountPath := filepath.Join(taskDir, volumeName)
+	require.NoError(t, os.MkdirAll(mountPath, mountDirectoryPermission))
+	efs, err := NewEFSResource(context.TODO(), volumeName, taskARN, EFSVolumeConfig{
+		FileSystemID: "fs-12345678",
+	}, mountPath, "", mockMounter)
+	require.NoError(t, err)
+	efs.SetKnownStatus(resourcestatus.ResourceStatus(EFSMounted))
+
+	mockMounter.EXPECT().Unmount(gomock.Any(), mountPath).Return(nil)
+
+	require.NoError(t, efs.Cleanup())
+	_, err = os.Stat(taskDir)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestCleanupUnmountError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockMounter := mock_efs.NewMockMounter(ctrl)
+
+	mountPath := filepath.Join(tempDir(t), "task-id", volumeName)
+	require.NoError(t, os.MkdirAll(mountPath, mountDirectoryPermission))
+	efs, err := NewEFSResource(context.TODO(), volumeName, taskARN, EFSVolumeConfig{
+		FileSystemID: "fs-12345678",
+	}, mountPath, "", mockMounter)
+	require.NoError(t, err)
+	efs.SetKnownStatus(resourcestatus.ResourceStatus(EFSMounted))
+
+	mockMounter.EXPECT().Unmount(gomock.Any(), mountPath).Return(errors.New("umount: target is busy"))
+
+	assert.Error(t, efs.Cleanup())
+	// the mount directory is kept while the file system may still be mounted
+	_, err = os.Stat(mountPath)
+	assert.NoError(t, err)
+}
+
+func TestCleanupNotMounted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockMounter := mock_efs.NewMockMounter(ctrl)
+
+	efs, err := NewEFSResource(context.TODO(), volumeName, taskARN, EFSVolumeConfig{
+		FileSystemID: "fs-12345678",
+	}, filepath.Join(tempDir(t), "task-id", volumeName), "", mockMounter)
+	require.NoError(t, err)
+
+	assert.NoError(t, efs.Cleanup())
+}
+
+func TestMarshalUnmarshalJSON(t *testing.T) {
+	efs, err := NewEFSResource(context.TODO(), volumeName, taskARN, EFSVolumeConfig{
+		FileSystemID:      "fs-12345678",
+		TransitEncryption: EFSEnabled,
+		AuthConfig:        EFSAuthConfig{IAM: EFSEnabled},
+		HostPath:          "/var/lib/ecs/data/efs/task-id/data",
+	}, "/data/efs/task-id/data", credentialsURI, nil)
+	require.NoError(t, err)
+	efs.SetCreatedAt(time.Now())
+	efs.SetDesiredStatus(resourcestatus.ResourceStatus(EFSMounted))
+	efs.SetKnownStatus(resourcestatus.ResourceStatus(EFSStatusNone))
+
+	bytes, err := json.Marshal(efs)
+	require.NoError(t, err)
+
+	unmarshalled := &EFSResource{}
+	require.NoError(t, json.Unmarshal(bytes, unmarshalled))
+	assert.Equal(t, efs.Name, unmarshalled.Name)
+	assert.Equal(t, efs.TaskARN, unmarshalled.TaskARN)
+	assert.Equal(t, efs.VolumeConfig, unmarshalled.VolumeConfig)
+	assert.Equal(t, efs.MountPath, unmarshalled.MountPath)
+	assert.Equal(t, credentialsURI, unmarshalled.CredentialsRelativeURI)
+	assert.WithinDuration(t, efs.GetCreatedAt(), unmarshalled.GetCreatedAt(), time.Microsecond)
+	assert.Equal(t, efs.GetDesiredStatus(), unmarshalled.GetDesiredStatus())
+	assert.Equal(t, efs.GetKnownStatus(), unmarshalled.GetKnownStatus())
+}
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "efs")
+	require.NoError(t, err)
+	return dir
+}
@@ -0,0 +1,79 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package efs
+
+import (
+	"errors"
+	"strings"
+
+	resourcestatus "github.com/aws/amazon-ecs-agent/agent/taskresource/status"
+)
+
+// EFSStatus defines resource statuses for EFS volumes
+type EFSStatus resourcestatus.ResourceStatus
+
+const (
+	// EFSStatusNone is the zero state of a task resource
+	EFSStatusNone EFSStatus = iota
+	// EFSMounted represents a task resource which has been mounted
+	EFSMounted
+	// EFSUnmounted represents a task resource which has been unmounted
+	EFSUnmounted
+)
+
+var resourceStatusMap = map[string]EFSStatus{
+	"NONE":      EFSStatusNone,
+	"MOUNTED":   EFSMounted,
+	"UNMOUNTED": EFSUnmounted,
+}
+
+// StatusString returns a human readable string representation of this object
+func (es EFSStatus) String() string {
+	for k, v := range resourceStatusMap {
+		if v == es {
+			return k
+		}
+	}
+	return "NONE"
+}
+
+// MarshalJSON overrides the logic for JSON-encoding the ResourceStatus type
+func (es *EFSStatus) MarshalJSON() ([]byte, error) {
+	if es == nil {
+		return nil, nil
+	}
+	return []byte(`"` + es.String() + `"`), nil
+}
+
+// UnmarshalJSON overrides the logic for parsing the JSON-encoded ResourceStatus data
+func (es *EFSStatus) UnmarshalJSON(b []byte) error {
+	if strings.ToLower(string(b)) == "null" {
+		*es = EFSStatusNone
+		return nil
+	}
+
+	if b[0] != '"' || b[len(b)-1] != '"' {
+		*es = EFSStatusNone
+		return errors.New("resource status unmarshal: status must be a string or null; Got " + string(b))
+	}
+
+	strStatus := string(b[1 : len(b)-1])
+	stat, ok := resourceStatusMap[strStatus]
+	if !ok {
+		*es = EFSStatusNone
+		return errors.New("resource status unmarshal: unrecognized status")
+	}
+	*es = stat
+	return nil
+}
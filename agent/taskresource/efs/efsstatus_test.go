@@ -0,0 +1,89 @@
+//go:build unit
+// +build unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package efs
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatusString(t *testing.T) {
+	var resourceStatus EFSStatus
+
+	resourceStatus = EFSStatusNone
+	assert.Equal(t, resourceStatus.String(), "NONE")
+	resourceStatus = EFSMounted
+	assert.Equal(t, resourceStatus.String(), "MOUNTED")
+	resourceStatus = EFSUnmounted
+	assert.Equal(t, resourceStatus.String(), "UNMOUNTED")
+}
+
+func TestMarshalEFSStatus(t *testing.T) {
+	status := EFSStatusNone
+	bytes, err := status.MarshalJSON()
+
+	assert.NoError(t, err)
+	assert.Equal(t, `"NONE"`, string(bytes[:]))
+}
+
+func TestMarshalNilEFSStatus(t *testing.T) {
+	var status *EFSStatus
+	bytes, err := status.MarshalJSON()
+
+	assert.Nil(t, bytes)
+	assert.Nil(t, err)
+}
+
+type testEFSStatus struct {
+	SomeStatus EFSStatus `json:"status"`
+}
+
+func TestUnmarshalEFSStatus(t *testing.T) {
+	status := EFSStatusNone
+
+	err := json.Unmarshal([]byte(`"MOUNTED"`), &status)
+	assert.NoError(t, err)
+	assert.Equal(t, EFSMounted, status, "MOUNTED should unmarshal to MOUNTED, not "+status.String())
+
+	var testStatus testEFSStatus
+	err = json.Unmarshal([]byte(`{"status":"UNMOUNTED"}`), &testStatus)
+	assert.NoError(t, err)
+	assert.Equal(t, EFSUnmounted, testStatus.SomeStatus, "UNMOUNTED should unmarshal to UNMOUNTED, not "+testStatus.SomeStatus.String())
+}
+
+func TestUnmarshalNullEFSStatus(t *testing.T) {
+	status := EFSMounted
+	err := json.Unmarshal([]byte("null"), &status)
+	assert.NoError(t, err)
+	assert.Equal(t, EFSStatusNone, status, "null should unmarshal to None, not "+status.String())
+}
+
+func TestUnmarshalNonStringEFSStatusDefaultNone(t *testing.T) {
+	status := EFSMounted
+	err := json.Unmarshal([]byte(`1`), &status)
+	assert.NotNil(t, err)
+	assert.Equal(t, EFSStatusNone, status, "non-string status should unmarshal to None, not "+status.String())
+}
+
+func TestUnmarshalUnmappedEFSStatusDefaultNone(t *testing.T) {
+	status := EFSUnmounted
+	err := json.Unmarshal([]byte(`"SOMEOTHER"`), &status)
+	assert.NotNil(t, err)
+	assert.Equal(t, EFSStatusNone, status, "Unmapped status should unmarshal to None, not "+status.String())
+}
@@ -0,0 +1,111 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package efs
+
+import (
+	"github.com/pkg/errors"
+)
+
+const (
+	// EFSEnabled is the value of the transit encryption and IAM authorization
+	// settings of an EFS volume to turn them on
+	EFSEnabled = "ENABLED"
+	// EFSDisabled is the value of the transit encryption and IAM authorization
+	// settings of an EFS volume to turn them off
+	EFSDisabled = "DISABLED"
+
+	defaultRootDirectory = "/"
+)
+
+// EFSVolumeConfig represents the configuration of an EFS volume
+type EFSVolumeConfig struct {
+	// FileSystemID is the id of the EFS file system
+	FileSystemID string `json:"fileSystemId"`
+	// RootDirectory is the directory of the file system mounted as the root
+	// of the volume
+	RootDirectory string `json:"rootDirectory"`
+	// TransitEncryption is ENABLED when the traffic to the file system is
+	// encrypted with TLS
+	TransitEncryption string `json:"transitEncryption"`
+	// TransitEncryptionPort is the port on the host the TLS tunnel to the file
+	// system listens on, picked by efs-utils when not set
+	TransitEncryptionPort int64 `json:"transitEncryptionPort"`
+	// AuthConfig is the access point and IAM authorization of the volume
+	AuthConfig EFSAuthConfig `json:"authorizationConfig"`
+	// HostPath is the path on the host the file system is mounted at, which is
+	// the source of the bind mounts of the volume into containers
+	HostPath string `json:"hostPath"`
+}
+
+// EFSAuthConfig represents the authorization configuration of an EFS volume
+type EFSAuthConfig struct {
+	// AccessPointID is the id of the access point the file system is mounted
+	// through
+	AccessPointID string `json:"accessPointId"`
+	// IAM is ENABLED when the file system is mounted with the task role
+	IAM string `json:"iam"`
+}
+
+// Source returns the path on the host the file system is mounted at
+func (cfg *EFSVolumeConfig) Source() string {
+	return cfg.HostPath
+}
+
+// Validate checks the volume configuration can be mounted
+func (cfg *EFSVolumeConfig) Validate() error {
+	if cfg.FileSystemID == "" {
+		return errors.New("efs volume: file system id is required")
+	}
+	if err := validateSetting("transit encryption", cfg.TransitEncryption); err != nil {
+		return err
+	}
+	if err := validateSetting("iam authorization", cfg.AuthConfig.IAM); err != nil {
+		return err
+	}
+	if cfg.transitEncryptionEnabled() {
+		return nil
+	}
+	if cfg.AuthConfig.AccessPointID != "" || cfg.iamEnabled() {
+		return errors.New("efs volume: access points and iam authorization require transit encryption")
+	}
+	if cfg.TransitEncryptionPort != 0 {
+		return errors.New("efs volume: transit encryption port requires transit encryption")
+	}
+	return nil
+}
+
+func validateSetting(name string, value string) error {
+	if value != "" && value != EFSEnabled && value != EFSDisabled {
+		return errors.Errorf("efs volume: %s must be %s or %s, got %q", name, EFSEnabled, EFSDisabled, value)
+	}
+	return nil
+}
+
+func (cfg *EFSVolumeConfig) transitEncryptionEnabled() bool {
+	return cfg.TransitEncryption == EFSEnabled
+}
+
+func (cfg *EFSVolumeConfig) iamEnabled() bool {
+	return cfg.AuthConfig.IAM == EFSEnabled
+}
+
+// device returns the device of the file system in the fs-id:/path format of
+// efs-utils
+func (cfg *EFSVolumeConfig) device() string {
+	rootDirectory := cfg.RootDirectory
+	if rootDirectory == "" {
+		rootDirectory = defaultRootDirectory
+	}
+	return cfg.FileSystemID + ":" + rootDirectory
+}
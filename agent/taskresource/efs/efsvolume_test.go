@@ -0,0 +1,92 @@
+// +build unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package efs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		name      string
+		config    EFSVolumeConfig
+		shouldErr bool
+	}{
+		{
+			name:   "file system only",
+			config: EFSVolumeConfig{FileSystemID: "fs-12345678"},
+		},
+		{
+			name: "access point and iam with transit encryption",
+			config: EFSVolumeConfig{
+				FileSystemID:      "fs-12345678",
+				TransitEncryption: EFSEnabled,
+				AuthConfig:        EFSAuthConfig{AccessPointID: "fsap-12345678", IAM: EFSEnabled},
+			},
+		},
+		{
+			name:      "no file system",
+			config:    EFSVolumeConfig{},
+			shouldErr: true,
+		},
+		{
+			name:      "invalid transit encryption",
+			config:    EFSVolumeConfig{FileSystemID: "fs-12345678", TransitEncryption: "yes"},
+			shouldErr: true,
+		},
+		{
+			name: "access point without transit encryption",
+			config: EFSVolumeConfig{
+				FileSystemID: "fs-12345678",
+				AuthConfig:   EFSAuthConfig{AccessPointID: "fsap-12345678"},
+			},
+			shouldErr: true,
+		},
+		{
+			name: "iam without transit encryption",
+			config: EFSVolumeConfig{
+				FileSystemID:      "fs-12345678",
+				TransitEncryption: EFSDisabled,
+				AuthConfig:        EFSAuthConfig{IAM: EFSEnabled},
+			},
+			shouldErr: true,
+		},
+		{
+			name:      "transit encryption port without transit encryption",
+			config:    EFSVolumeConfig{FileSystemID: "fs-12345678", TransitEncryptionPort: 20049},
+			shouldErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.config.Validate()
+			if tc.shouldErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestDevice(t *testing.T) {
+	assert.Equal(t, "fs-12345678:/", (&EFSVolumeConfig{FileSystemID: "fs-12345678"}).device())
+	assert.Equal(t, "fs-12345678:/shared",
+		(&EFSVolumeConfig{FileSystemID: "fs-12345678", RootDirectory: "/shared"}).device())
+}
@@ -0,0 +1,78 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package efs
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// MountHelperPath is the path of the mount helper of efs-utils, which
+	// mounts EFS file systems with the "efs" file system type and encrypts
+	// their traffic with stunnel when mounted with the "tls" option
+	MountHelperPath = "/sbin/mount.efs"
+
+	mountCommand   = "mount"
+	unmountCommand = "umount"
+	// mountTimeout bounds mounting and unmounting, which block for as long as
+	// the file system is unreachable
+	mountTimeout = 2 * time.Minute
+)
+
+// Mounter mounts and unmounts EFS file systems
+type Mounter interface {
+	// Mount mounts the device, in the fs-id:/path format, at the target
+	// directory with the mount options of efs-utils
+	Mount(ctx context.Context, device string, target string, options []string) error
+	// Unmount unmounts the file system mounted at the target directory
+	Unmount(ctx context.Context, target string) error
+}
+
+type mounter struct{}
+
+// NewMounter creates a Mounter that mounts EFS file systems with efs-utils
+func NewMounter() Mounter {
+	return &mounter{}
+}
+
+// Mount mounts the device at the target directory with efs-utils
+func (*mounter) Mount(ctx context.Context, device string, target string, options []string) error {
+	args := []string{"-t", "efs"}
+	if len(options) > 0 {
+		args = append(args, "-o", strings.Join(options, ","))
+	}
+	args = append(args, device, target)
+	return runMountCommand(ctx, mountCommand, args...)
+}
+
+// Unmount unmounts the file system mounted at the target directory
+func (*mounter) Unmount(ctx context.Context, target string) error {
+	return runMountCommand(ctx, unmountCommand, target)
+}
+
+func runMountCommand(ctx context.Context, name string, args ...string) error {
+	ctx, cancel := context.WithTimeout(ctx, mountTimeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, name, args...).CombinedOutput()
+	if err != nil {
+		return errors.Wrapf(err, "%s %s: %s", name, strings.Join(args, " "), strings.TrimSpace(string(out)))
+	}
+	return nil
+}
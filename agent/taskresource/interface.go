@@ -17,6 +17,7 @@ import (
 	"encoding/json"
 	"time"
 
+	apicontainerstatus "github.com/aws/amazon-ecs-agent/agent/api/container/status"
 	apitaskstatus "github.com/aws/amazon-ecs-agent/agent/api/task/status"
 	resourcestatus "github.com/aws/amazon-ecs-agent/agent/taskresource/status"
 )
@@ -68,3 +69,15 @@ type TaskResource interface {
 	json.Marshaler
 	json.Unmarshaler
 }
+
+// DependencyStatusResolver can be implemented by task resources that decide by
+// themselves whether they satisfy the dependencies of containers on them, e.g.
+// when their statuses aren't ordered or when a status satisfies the
+// dependencies of some container transitions only
+type DependencyStatusResolver interface {
+	// DependencySatisfied returns whether the resource satisfies the dependency
+	// of the transition of a container to dependentStatus, which is satisfied
+	// by satisfiedStatuses
+	DependencySatisfied(dependentStatus apicontainerstatus.ContainerStatus,
+		satisfiedStatuses []resourcestatus.ResourceStatus) bool
+}
@@ -0,0 +1,389 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/api/task/status"
+	"github.com/aws/amazon-ecs-agent/agent/dockerclient"
+	"github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi"
+	"github.com/aws/amazon-ecs-agent/agent/taskresource"
+	resourcestatus "github.com/aws/amazon-ecs-agent/agent/taskresource/status"
+	"github.com/aws/amazon-ecs-agent/agent/utils/retry"
+	"github.com/cihub/seelog"
+	"github.com/pkg/errors"
+)
+
+const (
+	// ResourceName is the name of the docker network resource
+	ResourceName = "dockerNetwork"
+	// LabelTaskARN is the label the agent sets on the docker networks it
+	// creates, to the ARN of the task the network was created for
+	LabelTaskARN = "com.amazonaws.ecs.task-arn"
+	// networkNamePrefix is the prefix of the name of the docker networks the
+	// agent creates for tasks
+	networkNamePrefix = "ecs-task-"
+	// maxRequestedNameLength is the maximum length of the network name a task
+	// can request
+	maxRequestedNameLength = 64
+
+	resourceProvisioningError = "NetworkError: Agent could not create task's network resources"
+
+	cleanupBackoffMin      = time.Second
+	cleanupBackoffMax      = 30 * time.Second
+	cleanupBackoffJitter   = 0.2
+	cleanupBackoffMultiple = 2
+)
+
+// networkNameRegex matches the names docker accepts for networks
+var networkNameRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+// NetworkResource represents a docker bridge network created for a task, which
+// the containers of the task in bridge network mode are attached to
+type NetworkResource struct {
+	// Name is the name of the docker network
+	Name string
+	// TaskARN is the ARN of the task the network is created for
+	TaskARN string
+	// CleanupAttempts is the number of times removing the network is tried
+	// before the network is reported as leaked
+	CleanupAttempts int
+	// networkIDUnsafe is the id docker assigned to the network
+	networkIDUnsafe     string
+	createdAtUnsafe     time.Time
+	desiredStatusUnsafe resourcestatus.ResourceStatus
+	knownStatusUnsafe   resourcestatus.ResourceStatus
+	// appliedStatusUnsafe is the status that has been "applied" (e.g., we've called some
+	// operation such as 'Create' on the resource) but we don't yet know that the
+	// application was successful, which may then change the known status. This is
+	// used while progressing resource states in progressTask() of task manager
+	appliedStatusUnsafe resourcestatus.ResourceStatus
+	statusToTransitions map[resourcestatus.ResourceStatus]func() error
+	client              dockerapi.DockerClient
+	ctx                 context.Context
+	cleanupBackoff      retry.Backoff
+
+	// terminalReason should be set for resource creation failures. This ensures
+	// the resource object carries some context for why provisoning failed.
+	terminalReason     string
+	terminalReasonOnce sync.Once
+
+	// lock is used for fields that are accessed and updated concurrently
+	lock sync.RWMutex
+}
+
+// NewNetworkResource returns a docker network wrapper object for the task
+func NewNetworkResource(ctx context.Context,
+	taskARN string,
+	name string,
+	cleanupAttempts int,
+	client dockerapi.DockerClient) *NetworkResource {
+
+	n := &NetworkResource{
+		Name:            name,
+		TaskARN:         taskARN,
+		CleanupAttempts: cleanupAttempts,
+		client:          client,
+		ctx:             ctx,
+	}
+	n.initStatusToTransitions()
+	n.initCleanupBackoff()
+	return n
+}
+
+// NetworkName returns the name of the docker network created for the task.
+// The name requested by the task, if any, is followed by the task id so that
+// the networks of the tasks of the same task definition don't collide
+func NetworkName(taskID string, requestedName string) string {
+	if requestedName == "" {
+		return networkNamePrefix + taskID
+	}
+	return networkNamePrefix + requestedName + "-" + taskID
+}
+
+// IsValidNetworkName returns true if a task can request a docker network with
+// the name
+func IsValidNetworkName(name string) bool {
+	return len(name) <= maxRequestedNameLength && networkNameRegex.MatchString(name)
+}
+
+// Initialize initializes the docker network resource fields that aren't
+// persisted in the state file
+func (network *NetworkResource) Initialize(resourceFields *taskresource.ResourceFields,
+	taskKnownStatus status.TaskStatus,
+	taskDesiredStatus status.TaskStatus) {
+
+	network.ctx = resourceFields.Ctx
+	network.client = resourceFields.DockerClient
+	network.initStatusToTransitions()
+	network.initCleanupBackoff()
+}
+
+func (network *NetworkResource) initStatusToTransitions() {
+	statusToTransitions := map[resourcestatus.ResourceStatus]func() error{
+		resourcestatus.ResourceStatus(NetworkCreated): network.Create,
+	}
+
+	network.statusToTransitions = statusToTransitions
+}
+
+func (network *NetworkResource) initCleanupBackoff() {
+	network.cleanupBackoff = retry.NewExponentialBackoff(cleanupBackoffMin, cleanupBackoffMax,
+		cleanupBackoffJitter, cleanupBackoffMultiple)
+}
+
+// GetName returns the name of the docker network
+func (network *NetworkResource) GetName() string {
+	return network.Name
+}
+
+// DesiredTerminal returns true if the network's desired status is REMOVED
+func (network *NetworkResource) DesiredTerminal() bool {
+	network.lock.RLock()
+	defer network.lock.RUnlock()
+
+	return network.desiredStatusUnsafe == resourcestatus.ResourceStatus(NetworkRemoved)
+}
+
+// GetTerminalReason returns an error string to propagate up through to task
+// state change messages
+func (network *NetworkResource) GetTerminalReason() string {
+	if network.terminalReason == "" {
+		return resourceProvisioningError
+	}
+	return network.terminalReason
+}
+
+func (network *NetworkResource) setTerminalReason(reason string) {
+	network.terminalReasonOnce.Do(func() {
+		seelog.Infof("Network Resource [%s]: setting terminal reason for network resource", network.Name)
+		network.terminalReason = reason
+	})
+}
+
+// SetDesiredStatus safely sets the desired status of the resource
+func (network *NetworkResource) SetDesiredStatus(status resourcestatus.ResourceStatus) {
+	network.lock.Lock()
+	defer network.lock.Unlock()
+
+	network.desiredStatusUnsafe = status
+}
+
+// GetDesiredStatus safely returns the desired status of the resource
+func (network *NetworkResource) GetDesiredStatus() resourcestatus.ResourceStatus {
+	network.lock.RLock()
+	defer network.lock.RUnlock()
+
+	return network.desiredStatusUnsafe
+}
+
+// SetKnownStatus safely sets the currently known status of the resource
+func (network *NetworkResource) SetKnownStatus(status resourcestatus.ResourceStatus) {
+	network.lock.Lock()
+	defer network.lock.Unlock()
+
+	network.knownStatusUnsafe = status
+}
+
+// GetKnownStatus safely returns the currently known status of the resource
+func (network *NetworkResource) GetKnownStatus() resourcestatus.ResourceStatus {
+	network.lock.RLock()
+	defer network.lock.RUnlock()
+
+	return network.knownStatusUnsafe
+}
+
+// KnownCreated returns true if the network's known status is CREATED
+func (network *NetworkResource) KnownCreated() bool {
+	network.lock.RLock()
+	defer network.lock.RUnlock()
+
+	return network.knownStatusUnsafe == resourcestatus.ResourceStatus(NetworkCreated)
+}
+
+// TerminalStatus returns the last transition state of the network
+func (network *NetworkResource) TerminalStatus() resourcestatus.ResourceStatus {
+	return resourcestatus.ResourceStatus(NetworkRemoved)
+}
+
+// NextKnownState returns the state that the resource should
+// progress to based on its `KnownState`.
+func (network *NetworkResource) NextKnownState() resourcestatus.ResourceStatus {
+	return network.GetKnownStatus() + 1
+}
+
+// SteadyState returns the transition state of the resource defined as "ready"
+func (network *NetworkResource) SteadyState() resourcestatus.ResourceStatus {
+	return resourcestatus.ResourceStatus(NetworkCreated)
+}
+
+// ApplyTransition calls the function required to move to the specified status
+func (network *NetworkResource) ApplyTransition(nextState resourcestatus.ResourceStatus) error {
+	transitionFunc, ok := network.statusToTransitions[nextState]
+	if !ok {
+		errW := errors.Errorf("network [%s]: transition to %s impossible", network.Name,
+			network.StatusString(nextState))
+		network.setTerminalReason(errW.Error())
+		return errW
+	}
+	return transitionFunc()
+}
+
+// SetAppliedStatus sets the applied status of resource and returns whether
+// the resource is already in a transition
+func (network *NetworkResource) SetAppliedStatus(status resourcestatus.ResourceStatus) bool {
+	network.lock.Lock()
+	defer network.lock.Unlock()
+
+	if network.appliedStatusUnsafe != resourcestatus.ResourceStatus(NetworkStatusNone) {
+		// return false to indicate the set operation failed
+		return false
+	}
+
+	network.appliedStatusUnsafe = status
+	return true
+}
+
+// StatusString returns the string of the network resource status
+func (network *NetworkResource) StatusString(status resourcestatus.ResourceStatus) string {
+	return NetworkStatus(status).String()
+}
+
+// SetCreatedAt sets the timestamp for resource's creation time
+func (network *NetworkResource) SetCreatedAt(createdAt time.Time) {
+	if createdAt.IsZero() {
+		return
+	}
+	network.lock.Lock()
+	defer network.lock.Unlock()
+
+	network.createdAtUnsafe = createdAt
+}
+
+// GetCreatedAt sets the timestamp for resource's creation time
+func (network *NetworkResource) GetCreatedAt() time.Time {
+	network.lock.RLock()
+	defer network.lock.RUnlock()
+
+	return network.createdAtUnsafe
+}
+
+func (network *NetworkResource) setNetworkID(networkID string) {
+	network.lock.Lock()
+	defer network.lock.Unlock()
+
+	network.networkIDUnsafe = networkID
+}
+
+// GetNetworkID returns the id docker assigned to the network
+func (network *NetworkResource) GetNetworkID() string {
+	network.lock.RLock()
+	defer network.lock.RUnlock()
+
+	return network.networkIDUnsafe
+}
+
+// Create performs resource creation
+func (network *NetworkResource) Create() error {
+	seelog.Debugf("Creating network with name %s for task %s", network.Name, network.TaskARN)
+	networkID, err := network.client.CreateNetwork(
+		network.ctx,
+		network.Name,
+		map[string]string{LabelTaskARN: network.TaskARN},
+		dockerclient.CreateNetworkTimeout)
+	if err != nil {
+		network.setTerminalReason(err.Error())
+		return err
+	}
+
+	network.setNetworkID(networkID)
+	return nil
+}
+
+// Cleanup performs resource cleanup. Removing the network is retried, as it
+// fails while containers of the task are still attached to it, and a network
+// that can't be removed is reported as leaked: leaked networks keep their
+// subnet, and enough of them exhaust the address pool of the bridge driver
+func (network *NetworkResource) Cleanup() error {
+	seelog.Debugf("Removing network with name %s", network.Name)
+	err := retry.RetryNWithBackoffCtx(network.ctx, network.cleanupBackoff, network.CleanupAttempts, func() error {
+		err := network.client.RemoveNetwork(network.ctx, network.Name, dockerclient.RemoveNetworkTimeout)
+		if err != nil {
+			seelog.Warnf("Network [%s]: unable to remove network of task %s: %v",
+				network.Name, network.TaskARN, err)
+		}
+		return err
+	})
+	if err != nil {
+		seelog.Errorf("Network [%s]: network of task %s leaked after %d removal attempts: %v",
+			network.Name, network.TaskARN, network.CleanupAttempts, err)
+		network.setTerminalReason(err.Error())
+		return err
+	}
+	return nil
+}
+
+// networkResourceJSON duplicates NetworkResource fields, only for marshalling and unmarshalling purposes
+type networkResourceJSON struct {
+	Name            string         `json:"name"`
+	TaskARN         string         `json:"taskARN"`
+	CleanupAttempts int            `json:"cleanupAttempts"`
+	NetworkID       string         `json:"networkID"`
+	CreatedAt       time.Time      `json:"createdAt"`
+	DesiredStatus   *NetworkStatus `json:"desiredStatus"`
+	KnownStatus     *NetworkStatus `json:"knownStatus"`
+}
+
+// MarshalJSON marshals NetworkResource object using duplicate struct NetworkResourceJSON
+func (network *NetworkResource) MarshalJSON() ([]byte, error) {
+	if network == nil {
+		return nil, nil
+	}
+	return json.Marshal(networkResourceJSON{
+		network.Name,
+		network.TaskARN,
+		network.CleanupAttempts,
+		network.GetNetworkID(),
+		network.GetCreatedAt(),
+		func() *NetworkStatus { desiredState := NetworkStatus(network.GetDesiredStatus()); return &desiredState }(),
+		func() *NetworkStatus { knownState := NetworkStatus(network.GetKnownStatus()); return &knownState }(),
+	})
+}
+
+// UnmarshalJSON unmarshals NetworkResource object using duplicate struct NetworkResourceJSON
+func (network *NetworkResource) UnmarshalJSON(b []byte) error {
+	temp := &networkResourceJSON{}
+
+	if err := json.Unmarshal(b, &temp); err != nil {
+		return err
+	}
+
+	network.Name = temp.Name
+	network.TaskARN = temp.TaskARN
+	network.CleanupAttempts = temp.CleanupAttempts
+	network.setNetworkID(temp.NetworkID)
+	network.SetCreatedAt(temp.CreatedAt)
+	if temp.DesiredStatus != nil {
+		network.SetDesiredStatus(resourcestatus.ResourceStatus(*temp.DesiredStatus))
+	}
+	if temp.KnownStatus != nil {
+		network.SetKnownStatus(resourcestatus.ResourceStatus(*temp.KnownStatus))
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+// +build unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/dockerclient"
+	mock_dockerapi "github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi/mocks"
+	resourcestatus "github.com/aws/amazon-ecs-agent/agent/taskresource/status"
+	"github.com/aws/amazon-ecs-agent/agent/utils/retry"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	taskARN = "arn:aws:ecs:us-west-2:123456789012:task/task-id"
+	taskID  = "task-id"
+)
+
+func newTestNetworkResource(ctx context.Context, cleanupAttempts int,
+	client *mock_dockerapi.MockDockerClient) *NetworkResource {
+	network := NewNetworkResource(ctx, taskARN, NetworkName(taskID, ""), cleanupAttempts, client)
+	network.cleanupBackoff = retry.NewExponentialBackoff(time.Millisecond, time.Millisecond, 0, 1)
+	return network
+}
+
+func TestCreateSuccess(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockClient := mock_dockerapi.NewMockDockerClient(ctrl)
+
+	mockClient.EXPECT().CreateNetwork(gomock.Any(), "ecs-task-task-id", map[string]string{LabelTaskARN: taskARN},
+		dockerclient.CreateNetworkTimeout).Return("network-id", nil)
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	network := newTestNetworkResource(ctx, 1, mockClient)
+	assert.NoError(t, network.Create())
+	assert.Equal(t, "network-id", network.GetNetworkID())
+}
+
+func TestCreateError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockClient := mock_dockerapi.NewMockDockerClient(ctrl)
+
+	mockClient.EXPECT().CreateNetwork(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return("",
+		errors.New("could not find an available, non-overlapping IPv4 address pool"))
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	network := newTestNetworkResource(ctx, 1, mockClient)
+	assert.Error(t, network.Create())
+	assert.Equal(t, "could not find an available, non-overlapping IPv4 address pool", network.GetTerminalReason())
+}
+
+func TestCleanupRetries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockClient := mock_dockerapi.NewMockDockerClient(ctrl)
+
+	gomock.InOrder(
+		mockClient.EXPECT().RemoveNetwork(gomock.Any(), "ecs-task-task-id", dockerclient.RemoveNetworkTimeout).Return(
+			errors.New("network has active endpoints")),
+		mockClient.EXPECT().RemoveNetwork(gomock.Any(), "ecs-task-task-id", dockerclient.RemoveNetworkTimeout).Return(nil),
+	)
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	network := newTestNetworkResource(ctx, 3, mockClient)
+	assert.NoError(t, network.Cleanup())
+}
+
+func TestCleanupLeak(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockClient := mock_dockerapi.NewMockDockerClient(ctrl)
+
+	mockClient.EXPECT().RemoveNetwork(gomock.Any(), gomock.Any(), gomock.Any()).Return(
+		errors.New("network has active endpoints")).Times(3)
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	network := newTestNetworkResource(ctx, 3, mockClient)
+	assert.Error(t, network.Cleanup())
+	assert.Equal(t, "network has active endpoints", network.GetTerminalReason())
+}
+
+func TestMarshalUnmarshalJSON(t *testing.T) {
+	network := NewNetworkResource(context.TODO(), taskARN, NetworkName(taskID, ""), 5, nil)
+	network.setNetworkID("network-id")
+	network.SetCreatedAt(time.Now())
+	network.SetDesiredStatus(resourcestatus.ResourceStatus(NetworkCreated))
+	network.SetKnownStatus(resourcestatus.ResourceStatus(NetworkStatusNone))
+
+	bytes, err := json.Marshal(network)
+	require.NoError(t, err)
+
+	unmarshalled := &NetworkResource{}
+	require.NoError(t, json.Unmarshal(bytes, unmarshalled))
+	assert.Equal(t, network.Name, unmarshalled.Name)
+	assert.Equal(t, taskARN, unmarshalled.TaskARN)
+	assert.Equal(t, 5, unmarshalled.CleanupAttempts)
+	assert.Equal(t, "network-id", unmarshalled.GetNetworkID())
+	assert.WithinDuration(t, network.GetCreatedAt(), unmarshalled.GetCreatedAt(), time.Microsecond)
+	assert.Equal(t, network.GetDesiredStatus(), unmarshalled.GetDesiredStatus())
+	assert.Equal(t, network.GetKnownStatus(), unmarshalled.GetKnownStatus())
+}
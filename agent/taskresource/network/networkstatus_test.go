@@ -0,0 +1,88 @@
+// +build unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package network
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatusString(t *testing.T) {
+	var resourceStatus NetworkStatus
+
+	resourceStatus = NetworkStatusNone
+	assert.Equal(t, resourceStatus.String(), "NONE")
+	resourceStatus = NetworkCreated
+	assert.Equal(t, resourceStatus.String(), "CREATED")
+	resourceStatus = NetworkRemoved
+	assert.Equal(t, resourceStatus.String(), "REMOVED")
+}
+
+func TestMarshalNetworkStatus(t *testing.T) {
+	status := NetworkStatusNone
+	bytes, err := status.MarshalJSON()
+
+	assert.NoError(t, err)
+	assert.Equal(t, `"NONE"`, string(bytes[:]))
+}
+
+func TestMarshalNilNetworkStatus(t *testing.T) {
+	var status *NetworkStatus
+	bytes, err := status.MarshalJSON()
+
+	assert.Nil(t, bytes)
+	assert.Nil(t, err)
+}
+
+type testNetworkStatus struct {
+	SomeStatus NetworkStatus `json:"status"`
+}
+
+func TestUnmarshalNetworkStatus(t *testing.T) {
+	status := NetworkStatusNone
+
+	err := json.Unmarshal([]byte(`"CREATED"`), &status)
+	assert.NoError(t, err)
+	assert.Equal(t, NetworkCreated, status, "CREATED should unmarshal to CREATED, not "+status.String())
+
+	var testStatus testNetworkStatus
+	err = json.Unmarshal([]byte(`{"status":"REMOVED"}`), &testStatus)
+	assert.NoError(t, err)
+	assert.Equal(t, NetworkRemoved, testStatus.SomeStatus, "REMOVED should unmarshal to REMOVED, not "+testStatus.SomeStatus.String())
+}
+
+func TestUnmarshalNullNetworkStatus(t *testing.T) {
+	status := NetworkCreated
+	err := json.Unmarshal([]byte("null"), &status)
+	assert.NoError(t, err)
+	assert.Equal(t, NetworkStatusNone, status, "null should unmarshal to None, not "+status.String())
+}
+
+func TestUnmarshalNonStringNetworkStatusDefaultNone(t *testing.T) {
+	status := NetworkCreated
+	err := json.Unmarshal([]byte(`1`), &status)
+	assert.NotNil(t, err)
+	assert.Equal(t, NetworkStatusNone, status, "non-string status should unmarshal to None, not "+status.String())
+}
+
+func TestUnmarshalUnmappedNetworkStatusDefaultNone(t *testing.T) {
+	status := NetworkRemoved
+	err := json.Unmarshal([]byte(`"SOMEOTHER"`), &status)
+	assert.NotNil(t, err)
+	assert.Equal(t, NetworkStatusNone, status, "Unmapped status should unmarshal to None, not "+status.String())
+}
@@ -0,0 +1,427 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package providersecret
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cihub/seelog"
+	"github.com/pkg/errors"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	"github.com/aws/amazon-ecs-agent/agent/api/task/status"
+	"github.com/aws/amazon-ecs-agent/agent/secretprovider"
+	"github.com/aws/amazon-ecs-agent/agent/taskresource"
+	resourcestatus "github.com/aws/amazon-ecs-agent/agent/taskresource/status"
+)
+
+const (
+	// ResourceName is the name of the providersecret resource
+	ResourceName = "providersecret"
+)
+
+// ProviderSecretResource represents secrets as a task resource.
+// The secrets are retrieved by the secret providers configured on the instance,
+// such as HashiCorp Vault or local files.
+type ProviderSecretResource struct {
+	taskARN             string
+	createdAt           time.Time
+	desiredStatusUnsafe resourcestatus.ResourceStatus
+	knownStatusUnsafe   resourcestatus.ResourceStatus
+	// appliedStatus is the status that has been "applied" (e.g., we've called some
+	// operation such as 'Create' on the resource) but we don't yet know that the
+	// application was successful, which may then change the known status. This is
+	// used while progressing resource states in progressTask() of task manager
+	appliedStatus                      resourcestatus.ResourceStatus
+	resourceStatusToTransitionFunction map[resourcestatus.ResourceStatus]func() error
+
+	// map to store all deduped provider secrets in the task, key is a combination of valueFrom and region
+	requiredSecrets map[string]apicontainer.Secret
+	// map to store secret values, key is a combination of valueFrom and region
+	secretData map[string]string
+
+	// secretProviders retrieves the secret values from the provider selected
+	// by the scheme of their valueFrom
+	secretProviders secretprovider.Providers
+
+	// terminalReason should be set for resource creation failures. This ensures
+	// the resource object carries some context for why provisioning failed.
+	terminalReason     string
+	terminalReasonOnce sync.Once
+
+	// lock is used for fields that are accessed and updated concurrently
+	lock sync.RWMutex
+}
+
+// NewProviderSecretResource creates a new ProviderSecretResource object
+func NewProviderSecretResource(taskARN string,
+	providerSecrets map[string]apicontainer.Secret,
+	secretProviders secretprovider.Providers) *ProviderSecretResource {
+
+	s := &ProviderSecretResource{
+		taskARN:         taskARN,
+		requiredSecrets: providerSecrets,
+		secretProviders: secretProviders,
+	}
+
+	s.initStatusToTransition()
+	return s
+}
+
+func (secret *ProviderSecretResource) initStatusToTransition() {
+	resourceStatusToTransitionFunction := map[resourcestatus.ResourceStatus]func() error{
+		resourcestatus.ResourceStatus(ProviderSecretCreated): secret.Create,
+	}
+	secret.resourceStatusToTransitionFunction = resourceStatusToTransitionFunction
+}
+
+func (secret *ProviderSecretResource) setTerminalReason(reason string) {
+	secret.terminalReasonOnce.Do(func() {
+		seelog.Infof("provider secret resource: setting terminal reason for provider secret resource in task: [%s]", secret.taskARN)
+		secret.terminalReason = reason
+	})
+}
+
+// GetTerminalReason returns an error string to propagate up through to task
+// state change messages
+func (secret *ProviderSecretResource) GetTerminalReason() string {
+	return secret.terminalReason
+}
+
+// SetDesiredStatus safely sets the desired status of the resource
+func (secret *ProviderSecretResource) SetDesiredStatus(status resourcestatus.ResourceStatus) {
+	secret.lock.Lock()
+	defer secret.lock.Unlock()
+
+	secret.desiredStatusUnsafe = status
+}
+
+// GetDesiredStatus safely returns the desired status of the task
+func (secret *ProviderSecretResource) GetDesiredStatus() resourcestatus.ResourceStatus {
+	secret.lock.RLock()
+	defer secret.lock.RUnlock()
+
+	return secret.desiredStatusUnsafe
+}
+
+// GetName safely returns the name of the resource
+func (secret *ProviderSecretResource) GetName() string {
+	secret.lock.RLock()
+	defer secret.lock.RUnlock()
+
+	return ResourceName
+}
+
+// DesiredTerminal returns true if the secret's desired status is REMOVED
+func (secret *ProviderSecretResource) DesiredTerminal() bool {
+	secret.lock.RLock()
+	defer secret.lock.RUnlock()
+
+	return secret.desiredStatusUnsafe == resourcestatus.ResourceStatus(ProviderSecretRemoved)
+}
+
+// KnownCreated returns true if the secret's known status is CREATED
+func (secret *ProviderSecretResource) KnownCreated() bool {
+	secret.lock.RLock()
+	defer secret.lock.RUnlock()
+
+	return secret.knownStatusUnsafe == resourcestatus.ResourceStatus(ProviderSecretCreated)
+}
+
+// TerminalStatus returns the last transition state of providersecret
+func (secret *ProviderSecretResource) TerminalStatus() resourcestatus.ResourceStatus {
+	return resourcestatus.ResourceStatus(ProviderSecretRemoved)
+}
+
+// NextKnownState returns the state that the resource should
+// progress to based on its `KnownState`.
+func (secret *ProviderSecretResource) NextKnownState() resourcestatus.ResourceStatus {
+	return secret.GetKnownStatus() + 1
+}
+
+// ApplyTransition calls the function required to move to the specified status
+func (secret *ProviderSecretResource) ApplyTransition(nextState resourcestatus.ResourceStatus) error {
+	transitionFunc, ok := secret.resourceStatusToTransitionFunction[nextState]
+	if !ok {
+		return errors.Errorf("resource [%s]: transition to %s impossible", secret.GetName(),
+			secret.StatusString(nextState))
+	}
+	return transitionFunc()
+}
+
+// SteadyState returns the transition state of the resource defined as "ready"
+func (secret *ProviderSecretResource) SteadyState() resourcestatus.ResourceStatus {
+	return resourcestatus.ResourceStatus(ProviderSecretCreated)
+}
+
+// SetKnownStatus safely sets the currently known status of the resource
+func (secret *ProviderSecretResource) SetKnownStatus(status resourcestatus.ResourceStatus) {
+	secret.lock.Lock()
+	defer secret.lock.Unlock()
+
+	secret.knownStatusUnsafe = status
+	secret.updateAppliedStatusUnsafe(status)
+}
+
+// updateAppliedStatusUnsafe updates the resource transitioning status
+func (secret *ProviderSecretResource) updateAppliedStatusUnsafe(knownStatus resourcestatus.ResourceStatus) {
+	if secret.appliedStatus == resourcestatus.ResourceStatus(ProviderSecretStatusNone) {
+		return
+	}
+
+	// Check if the resource transition has already finished
+	if secret.appliedStatus <= knownStatus {
+		secret.appliedStatus = resourcestatus.ResourceStatus(ProviderSecretStatusNone)
+	}
+}
+
+// SetAppliedStatus sets the applied status of resource and returns whether
+// the resource is already in a transition
+func (secret *ProviderSecretResource) SetAppliedStatus(status resourcestatus.ResourceStatus) bool {
+	secret.lock.Lock()
+	defer secret.lock.Unlock()
+
+	if secret.appliedStatus != resourcestatus.ResourceStatus(ProviderSecretStatusNone) {
+		// return false to indicate the set operation failed
+		return false
+	}
+
+	secret.appliedStatus = status
+	return true
+}
+
+// GetKnownStatus safely returns the currently known status of the task
+func (secret *ProviderSecretResource) GetKnownStatus() resourcestatus.ResourceStatus {
+	secret.lock.RLock()
+	defer secret.lock.RUnlock()
+
+	return secret.knownStatusUnsafe
+}
+
+// StatusString returns the string of the cgroup resource status
+func (secret *ProviderSecretResource) StatusString(status resourcestatus.ResourceStatus) string {
+	return ProviderSecretStatus(status).String()
+}
+
+// SetCreatedAt sets the timestamp for resource's creation time
+func (secret *ProviderSecretResource) SetCreatedAt(createdAt time.Time) {
+	if createdAt.IsZero() {
+		return
+	}
+	secret.lock.Lock()
+	defer secret.lock.Unlock()
+
+	secret.createdAt = createdAt
+}
+
+// GetCreatedAt sets the timestamp for resource's creation time
+func (secret *ProviderSecretResource) GetCreatedAt() time.Time {
+	secret.lock.RLock()
+	defer secret.lock.RUnlock()
+
+	return secret.createdAt
+}
+
+// Create retrieves the secret values from their providers. It spins up
+// multiple goroutines in order to retrieve values in parallel.
+func (secret *ProviderSecretResource) Create() error {
+	var wg sync.WaitGroup
+
+	// Get the maximum number of errors to be returned, which will be one error per goroutine
+	errorEvents := make(chan error, len(secret.requiredSecrets))
+
+	seelog.Infof("provider secret resource: retrieving secrets for containers in task: [%s]", secret.taskARN)
+	secret.secretData = make(map[string]string)
+
+	for _, providerSecret := range secret.getRequiredSecrets() {
+		wg.Add(1)
+		// Spin up goroutine per secret to speed up processing time
+		go secret.retrieveProviderSecretValue(providerSecret, &wg, errorEvents)
+	}
+
+	wg.Wait()
+	close(errorEvents)
+
+	if len(errorEvents) > 0 {
+		var terminalReasons []string
+		for err := range errorEvents {
+			terminalReasons = append(terminalReasons, err.Error())
+		}
+
+		errorString := strings.Join(terminalReasons, ";")
+		secret.setTerminalReason(errorString)
+		return errors.New(errorString)
+	}
+	return nil
+}
+
+// retrieveProviderSecretValue retrieves the secret value from the provider
+// selected by the scheme of its valueFrom and caches it into memory
+func (secret *ProviderSecretResource) retrieveProviderSecretValue(apiSecret apicontainer.Secret, wg *sync.WaitGroup, errorEvents chan error) {
+	defer wg.Done()
+
+	seelog.Infof("provider secret resource: retrieving resource for secret %v for task: [%s]", apiSecret.ValueFrom, secret.taskARN)
+	secretValue, err := secret.getSecretProviders().GetSecretValue(apiSecret)
+	if err != nil {
+		errorEvents <- fmt.Errorf("fetching secret data from %s secret provider: %v", apiSecret.GetProvider(), err)
+		return
+	}
+
+	secret.lock.Lock()
+	defer secret.lock.Unlock()
+
+	// put secret value in secretData
+	secretKey := apiSecret.GetSecretResourceCacheKey()
+	secret.secretData[secretKey] = secretValue
+}
+
+// getRequiredSecrets returns the requiredSecrets field of providersecret task resource
+func (secret *ProviderSecretResource) getRequiredSecrets() map[string]apicontainer.Secret {
+	secret.lock.RLock()
+	defer secret.lock.RUnlock()
+
+	return secret.requiredSecrets
+}
+
+// getSecretProviders returns the secret providers configured on the instance
+func (secret *ProviderSecretResource) getSecretProviders() secretprovider.Providers {
+	secret.lock.RLock()
+	defer secret.lock.RUnlock()
+
+	return secret.secretProviders
+}
+
+// Cleanup removes the secret value created for the task
+func (secret *ProviderSecretResource) Cleanup() error {
+	secret.clearProviderSecretValue()
+	return nil
+}
+
+// clearProviderSecretValue cycles through the collection of secret value data and
+// removes them from the task
+func (secret *ProviderSecretResource) clearProviderSecretValue() {
+	secret.lock.Lock()
+	defer secret.lock.Unlock()
+
+	for key := range secret.secretData {
+		delete(secret.secretData, key)
+	}
+}
+
+// GetSecretValue returns the retrieved value of the secret, so that the
+// resource is the secretprovider.Provider of the secrets of the task it retrieves
+func (secret *ProviderSecretResource) GetSecretValue(apiSecret apicontainer.Secret) (string, error) {
+	value, ok := secret.GetCachedSecretValue(apiSecret.GetSecretResourceCacheKey())
+	if !ok {
+		return "", errors.Errorf("secret %s: value not retrieved", apiSecret.ValueFrom)
+	}
+	return value, nil
+}
+
+// GetCachedSecretValue retrieves the secret value from secretData field
+func (secret *ProviderSecretResource) GetCachedSecretValue(secretKey string) (string, bool) {
+	secret.lock.RLock()
+	defer secret.lock.RUnlock()
+
+	s, ok := secret.secretData[secretKey]
+	return s, ok
+}
+
+// SetCachedSecretValue set the secret value in the secretData field given the key and value
+func (secret *ProviderSecretResource) SetCachedSecretValue(secretKey string, secretValue string) {
+	secret.lock.Lock()
+	defer secret.lock.Unlock()
+
+	if secret.secretData == nil {
+		secret.secretData = make(map[string]string)
+	}
+
+	secret.secretData[secretKey] = secretValue
+}
+
+func (secret *ProviderSecretResource) Initialize(resourceFields *taskresource.ResourceFields,
+	taskKnownStatus status.TaskStatus,
+	taskDesiredStatus status.TaskStatus) {
+	secret.initStatusToTransition()
+	secret.secretProviders = resourceFields.SecretProviders
+
+	// if task hasn't turn to 'created' status, and it's desire status is 'running'
+	// the resource status needs to be reset to 'NONE' status so the secret value
+	// will be retrieved again
+	if taskKnownStatus < status.TaskCreated &&
+		taskDesiredStatus <= status.TaskRunning {
+		secret.SetKnownStatus(resourcestatus.ResourceStatusNone)
+	}
+}
+
+// ProviderSecretResourceJSON is the json representation of the providersecret resource
+type ProviderSecretResourceJSON struct {
+	TaskARN         string                         `json:"taskARN"`
+	CreatedAt       *time.Time                     `json:"createdAt,omitempty"`
+	DesiredStatus   *ProviderSecretStatus          `json:"desiredStatus"`
+	KnownStatus     *ProviderSecretStatus          `json:"knownStatus"`
+	RequiredSecrets map[string]apicontainer.Secret `json:"secretResources"`
+}
+
+// MarshalJSON serialises the ProviderSecretResource struct to JSON
+func (secret *ProviderSecretResource) MarshalJSON() ([]byte, error) {
+	if secret == nil {
+		return nil, errors.New("providersecret resource is nil")
+	}
+	createdAt := secret.GetCreatedAt()
+	return json.Marshal(ProviderSecretResourceJSON{
+		TaskARN:   secret.taskARN,
+		CreatedAt: &createdAt,
+		DesiredStatus: func() *ProviderSecretStatus {
+			desiredState := secret.GetDesiredStatus()
+			s := ProviderSecretStatus(desiredState)
+			return &s
+		}(),
+		KnownStatus: func() *ProviderSecretStatus {
+			knownState := secret.GetKnownStatus()
+			s := ProviderSecretStatus(knownState)
+			return &s
+		}(),
+		RequiredSecrets: secret.getRequiredSecrets(),
+	})
+}
+
+// UnmarshalJSON deserialises the raw JSON to a ProviderSecretResource struct
+func (secret *ProviderSecretResource) UnmarshalJSON(b []byte) error {
+	temp := ProviderSecretResourceJSON{}
+
+	if err := json.Unmarshal(b, &temp); err != nil {
+		return err
+	}
+
+	if temp.DesiredStatus != nil {
+		secret.SetDesiredStatus(resourcestatus.ResourceStatus(*temp.DesiredStatus))
+	}
+	if temp.KnownStatus != nil {
+		secret.SetKnownStatus(resourcestatus.ResourceStatus(*temp.KnownStatus))
+	}
+	if temp.CreatedAt != nil && !temp.CreatedAt.IsZero() {
+		secret.SetCreatedAt(*temp.CreatedAt)
+	}
+	if temp.RequiredSecrets != nil {
+		secret.requiredSecrets = temp.RequiredSecrets
+	}
+	secret.taskARN = temp.TaskARN
+
+	return nil
+}
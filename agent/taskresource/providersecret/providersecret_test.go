@@ -0,0 +1,137 @@
+// +build unit
+
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package providersecret
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apitaskstatus "github.com/aws/amazon-ecs-agent/agent/api/task/status"
+	"github.com/aws/amazon-ecs-agent/agent/secretprovider"
+	"github.com/aws/amazon-ecs-agent/agent/taskresource"
+	resourcestatus "github.com/aws/amazon-ecs-agent/agent/taskresource/status"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	taskARN        = "task1"
+	vaultValueFrom = "vault://secret/data/myapp#password"
+	fileValueFrom  = "file:///etc/ecs/secrets/password"
+)
+
+// testProvider is a secretprovider.Provider returning fixed secret values
+type testProvider map[string]string
+
+func (provider testProvider) GetSecretValue(secret apicontainer.Secret) (string, error) {
+	value, ok := provider[secret.ValueFrom]
+	if !ok {
+		return "", errors.New("secret not found")
+	}
+	return value, nil
+}
+
+func requiredSecrets() map[string]apicontainer.Secret {
+	vaultSecret := apicontainer.Secret{
+		Name:      "vault-secret",
+		ValueFrom: vaultValueFrom,
+	}
+	fileSecret := apicontainer.Secret{
+		Name:      "file-secret",
+		ValueFrom: fileValueFrom,
+	}
+	return map[string]apicontainer.Secret{
+		vaultSecret.GetSecretResourceCacheKey(): vaultSecret,
+		fileSecret.GetSecretResourceCacheKey():  fileSecret,
+	}
+}
+
+func TestCreate(t *testing.T) {
+	providers := secretprovider.Providers{
+		apicontainer.SecretProviderVault: testProvider{vaultValueFrom: "vault-value"},
+		apicontainer.SecretProviderFile:  testProvider{fileValueFrom: "file-value"},
+	}
+	secretRes := NewProviderSecretResource(taskARN, requiredSecrets(), providers)
+
+	require.NoError(t, secretRes.Create())
+	value, ok := secretRes.GetCachedSecretValue(vaultValueFrom + "_")
+	require.True(t, ok)
+	assert.Equal(t, "vault-value", value)
+	value, ok = secretRes.GetCachedSecretValue(fileValueFrom + "_")
+	require.True(t, ok)
+	assert.Equal(t, "file-value", value)
+}
+
+func TestCreateReturnError(t *testing.T) {
+	providers := secretprovider.Providers{
+		apicontainer.SecretProviderVault: testProvider{},
+	}
+	secretRes := NewProviderSecretResource(taskARN, requiredSecrets(), providers)
+
+	assert.Error(t, secretRes.Create())
+	assert.Contains(t, secretRes.GetTerminalReason(), "fetching secret data from vault secret provider")
+	assert.Contains(t, secretRes.GetTerminalReason(), "secret provider file is not configured")
+}
+
+func TestMarshalUnmarshalJSON(t *testing.T) {
+	secretResIn := &ProviderSecretResource{
+		taskARN:             taskARN,
+		createdAt:           time.Now(),
+		knownStatusUnsafe:   resourcestatus.ResourceCreated,
+		desiredStatusUnsafe: resourcestatus.ResourceCreated,
+		requiredSecrets:     requiredSecrets(),
+	}
+
+	bytes, err := json.Marshal(secretResIn)
+	require.NoError(t, err)
+
+	secretResOut := &ProviderSecretResource{}
+	err = json.Unmarshal(bytes, secretResOut)
+	require.NoError(t, err)
+	assert.Equal(t, secretResIn.taskARN, secretResOut.taskARN)
+	assert.WithinDuration(t, secretResIn.createdAt, secretResOut.createdAt, time.Microsecond)
+	assert.Equal(t, secretResIn.desiredStatusUnsafe, secretResOut.desiredStatusUnsafe)
+	assert.Equal(t, secretResIn.knownStatusUnsafe, secretResOut.knownStatusUnsafe)
+	assert.Equal(t, secretResIn.requiredSecrets, secretResOut.requiredSecrets)
+}
+
+func TestInitialize(t *testing.T) {
+	providers := secretprovider.Providers{}
+	secretRes := &ProviderSecretResource{
+		knownStatusUnsafe:   resourcestatus.ResourceCreated,
+		desiredStatusUnsafe: resourcestatus.ResourceCreated,
+	}
+	secretRes.Initialize(&taskresource.ResourceFields{
+		ResourceFieldsCommon: &taskresource.ResourceFieldsCommon{
+			SecretProviders: providers,
+		},
+	}, apitaskstatus.TaskStatusNone, apitaskstatus.TaskRunning)
+	assert.Equal(t, resourcestatus.ResourceStatusNone, secretRes.GetKnownStatus())
+	assert.NotNil(t, secretRes.getSecretProviders())
+	assert.NotNil(t, secretRes.resourceStatusToTransitionFunction)
+}
+
+func TestClearProviderSecretValue(t *testing.T) {
+	secretRes := &ProviderSecretResource{}
+	secretRes.SetCachedSecretValue(vaultValueFrom+"_", "vault-value")
+
+	require.NoError(t, secretRes.Cleanup())
+	_, ok := secretRes.GetCachedSecretValue(vaultValueFrom + "_")
+	assert.False(t, ok)
+}
@@ -384,6 +384,16 @@ func (secret *SSMSecretResource) clearSSMSecretValue() {
 	}
 }
 
+// GetSecretValue returns the retrieved value of the secret, so that the
+// resource is the secretprovider.Provider of the secrets of the task it retrieves
+func (secret *SSMSecretResource) GetSecretValue(apiSecret apicontainer.Secret) (string, error) {
+	value, ok := secret.GetCachedSecretValue(apiSecret.GetSecretResourceCacheKey())
+	if !ok {
+		return "", errors.Errorf("secret %s: value not retrieved", apiSecret.ValueFrom)
+	}
+	return value, nil
+}
+
 // GetCachedSecretValue retrieves the secret value from secretData field
 func (secret *SSMSecretResource) GetCachedSecretValue(secretKey string) (string, bool) {
 	secret.lock.RLock()
@@ -518,3 +518,16 @@ func TestClearSSMSecretValue(t *testing.T) {
 	ssmRes.clearSSMSecretValue()
 	assert.Equal(t, 0, len(ssmRes.secretData))
 }
+
+func TestGetSecretValue(t *testing.T) {
+	ssmRes := &SSMSecretResource{
+		secretData: map[string]string{secretKeyWest1: secretValue},
+	}
+
+	value, err := ssmRes.GetSecretValue(apicontainer.Secret{ValueFrom: valueFrom1, Region: region1})
+	require.NoError(t, err)
+	assert.Equal(t, secretValue, value)
+
+	_, err = ssmRes.GetSecretValue(apicontainer.Secret{ValueFrom: valueFrom1, Region: region2})
+	assert.Error(t, err, "the secret isn't retrieved in the other region")
+}
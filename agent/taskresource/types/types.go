@@ -21,7 +21,10 @@ import (
 	asmauthres "github.com/aws/amazon-ecs-agent/agent/taskresource/asmauth"
 	asmsecretres "github.com/aws/amazon-ecs-agent/agent/taskresource/asmsecret"
 	cgroupres "github.com/aws/amazon-ecs-agent/agent/taskresource/cgroup"
+	"github.com/aws/amazon-ecs-agent/agent/taskresource/efs"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource/firelens"
+	"github.com/aws/amazon-ecs-agent/agent/taskresource/network"
+	providersecretres "github.com/aws/amazon-ecs-agent/agent/taskresource/providersecret"
 	ssmsecretres "github.com/aws/amazon-ecs-agent/agent/taskresource/ssmsecret"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource/volume"
 )
@@ -31,6 +34,10 @@ const (
 	CgroupKey = "cgroup"
 	// DockerVolumeKey is the string used in resources map to represent docker volume
 	DockerVolumeKey = "dockerVolume"
+	// DockerNetworkKey is the string used in resources map to represent docker network
+	DockerNetworkKey = network.ResourceName
+	// EFSKey is the string used in resources map to represent efs volume
+	EFSKey = efs.ResourceName
 	// ASMAuthKey is the string used in resources map to represent asm auth
 	ASMAuthKey = asmauthres.ResourceName
 	// SSMSecretKey is the string used in resources map to represent ssm secret
@@ -39,6 +46,8 @@ const (
 	ASMSecretKey = asmsecretres.ResourceName
 	// FirelensKey is the string used in resources map to represent firelens resource
 	FirelensKey = firelens.ResourceName
+	// ProviderSecretKey is the string used in resources map to represent provider secret
+	ProviderSecretKey = providersecretres.ResourceName
 )
 
 // ResourcesMap represents the map of resource type to the corresponding resource
@@ -68,6 +77,10 @@ func unmarshalResource(key string, value json.RawMessage, result map[string][]ta
 		return unmarshlCgroup(key, value, result)
 	case DockerVolumeKey:
 		return unmarshalDockerVolume(key, value, result)
+	case DockerNetworkKey:
+		return unmarshalDockerNetwork(key, value, result)
+	case EFSKey:
+		return unmarshalEFS(key, value, result)
 	case ASMAuthKey:
 		return unmarshalASMAuthKey(key, value, result)
 	case SSMSecretKey:
@@ -76,6 +89,8 @@ func unmarshalResource(key string, value json.RawMessage, result map[string][]ta
 		return unmarshalASMSecretKey(key, value, result)
 	case FirelensKey:
 		return unmarshalFirelensKey(key, value, result)
+	case ProviderSecretKey:
+		return unmarshalProviderSecretKey(key, value, result)
 	default:
 		return errors.New("Unsupported resource type")
 	}
@@ -115,6 +130,40 @@ func unmarshalDockerVolume(key string, value json.RawMessage, result map[string]
 	return nil
 }
 
+func unmarshalDockerNetwork(key string, value json.RawMessage, result map[string][]taskresource.TaskResource) error {
+	var networks []json.RawMessage
+	err := json.Unmarshal(value, &networks)
+	if err != nil {
+		return err
+	}
+	for _, n := range networks {
+		dockerNetwork := &network.NetworkResource{}
+		err := dockerNetwork.UnmarshalJSON(n)
+		if err != nil {
+			return err
+		}
+		result[key] = append(result[key], dockerNetwork)
+	}
+	return nil
+}
+
+func unmarshalEFS(key string, value json.RawMessage, result map[string][]taskresource.TaskResource) error {
+	var efsVolumes []json.RawMessage
+	err := json.Unmarshal(value, &efsVolumes)
+	if err != nil {
+		return err
+	}
+	for _, e := range efsVolumes {
+		efsVolume := &efs.EFSResource{}
+		err := efsVolume.UnmarshalJSON(e)
+		if err != nil {
+			return err
+		}
+		result[key] = append(result[key], efsVolume)
+	}
+	return nil
+}
+
 func unmarshalASMAuthKey(key string, value json.RawMessage, result map[string][]taskresource.TaskResource) error {
 	var asmauths []json.RawMessage
 	err := json.Unmarshal(value, &asmauths)
@@ -187,3 +236,21 @@ func unmarshalFirelensKey(key string, value json.RawMessage, result map[string][
 	}
 	return nil
 }
+
+func unmarshalProviderSecretKey(key string, value json.RawMessage, result map[string][]taskresource.TaskResource) error {
+	var providersecrets []json.RawMessage
+	err := json.Unmarshal(value, &providersecrets)
+	if err != nil {
+		return err
+	}
+
+	for _, secret := range providersecrets {
+		res := &providersecretres.ProviderSecretResource{}
+		err := res.UnmarshalJSON(secret)
+		if err != nil {
+			return err
+		}
+		result[key] = append(result[key], res)
+	}
+	return nil
+}
@@ -21,6 +21,8 @@ import (
 
 	"github.com/aws/amazon-ecs-agent/agent/taskresource"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource/asmsecret"
+	"github.com/aws/amazon-ecs-agent/agent/taskresource/efs"
+	"github.com/aws/amazon-ecs-agent/agent/taskresource/network"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource/ssmsecret"
 	resourcestatus "github.com/aws/amazon-ecs-agent/agent/taskresource/status"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource/volume"
@@ -64,6 +66,63 @@ func TestMarshalUnmarshalVolumeResource(t *testing.T) {
 	assert.Equal(t, unMarshalledVolumes[0].GetKnownStatus(), resourcestatus.ResourceStatusNone)
 }
 
+func TestMarshalUnmarshalNetworkResource(t *testing.T) {
+	resources := make(map[string][]taskresource.TaskResource)
+
+	networks := []taskresource.TaskResource{
+		&network.NetworkResource{
+			Name:            "ecs-task-task-id",
+			TaskARN:         "task-arn",
+			CleanupAttempts: 5,
+		},
+	}
+	networks[0].SetDesiredStatus(resourcestatus.ResourceCreated)
+	networks[0].SetKnownStatus(resourcestatus.ResourceStatusNone)
+
+	resources["dockerNetwork"] = networks
+	data, err := json.Marshal(resources)
+	require.NoError(t, err)
+
+	var unMarshalledResource ResourcesMap
+	err = json.Unmarshal(data, &unMarshalledResource)
+	assert.NoError(t, err, "unmarshal network resource from data failed")
+	unMarshalledNetworks, ok := unMarshalledResource["dockerNetwork"]
+	assert.True(t, ok, "network resource not found in the resource map")
+	assert.Equal(t, unMarshalledNetworks[0].GetName(), "ecs-task-task-id")
+	assert.Equal(t, unMarshalledNetworks[0].GetDesiredStatus(), resourcestatus.ResourceCreated)
+	assert.Equal(t, unMarshalledNetworks[0].GetKnownStatus(), resourcestatus.ResourceStatusNone)
+}
+
+func TestMarshalUnmarshalEFSResource(t *testing.T) {
+	resources := make(map[string][]taskresource.TaskResource)
+
+	efsVolumes := []taskresource.TaskResource{
+		&efs.EFSResource{
+			Name:    "data",
+			TaskARN: "task-arn",
+			VolumeConfig: efs.EFSVolumeConfig{
+				FileSystemID: "fs-12345678",
+			},
+			MountPath: "/data/efs/task-id/data",
+		},
+	}
+	efsVolumes[0].SetDesiredStatus(resourcestatus.ResourceCreated)
+	efsVolumes[0].SetKnownStatus(resourcestatus.ResourceStatusNone)
+
+	resources["efs"] = efsVolumes
+	data, err := json.Marshal(resources)
+	require.NoError(t, err)
+
+	var unMarshalledResource ResourcesMap
+	err = json.Unmarshal(data, &unMarshalledResource)
+	assert.NoError(t, err, "unmarshal efs resource from data failed")
+	unMarshalledEFSVolumes, ok := unMarshalledResource["efs"]
+	assert.True(t, ok, "efs resource not found in the resource map")
+	assert.Equal(t, unMarshalledEFSVolumes[0].GetName(), "data")
+	assert.Equal(t, unMarshalledEFSVolumes[0].GetDesiredStatus(), resourcestatus.ResourceCreated)
+	assert.Equal(t, unMarshalledEFSVolumes[0].GetKnownStatus(), resourcestatus.ResourceStatusNone)
+}
+
 func TestMarshalUnmarshalSSMSecretResource(t *testing.T) {
 	resources := make(map[string][]taskresource.TaskResource)
 	ssmSecrets := []taskresource.TaskResource{
@@ -16,6 +16,7 @@ package taskresource
 import (
 	asmfactory "github.com/aws/amazon-ecs-agent/agent/asm/factory"
 	"github.com/aws/amazon-ecs-agent/agent/credentials"
+	"github.com/aws/amazon-ecs-agent/agent/secretprovider"
 	ssmfactory "github.com/aws/amazon-ecs-agent/agent/ssm/factory"
 	"github.com/aws/amazon-ecs-agent/agent/utils/ioutilwrapper"
 )
@@ -24,6 +25,7 @@ type ResourceFieldsCommon struct {
 	IOUtil             ioutilwrapper.IOUtil
 	ASMClientCreator   asmfactory.ClientCreator
 	SSMClientCreator   ssmfactory.SSMClientCreator
+	SecretProviders    secretprovider.Providers
 	CredentialsManager credentials.Manager
 	EC2InstanceID      string
 }
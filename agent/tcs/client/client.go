@@ -22,6 +22,7 @@ import (
 	"time"
 
 	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/metrics"
 	"github.com/aws/amazon-ecs-agent/agent/stats"
 	"github.com/aws/amazon-ecs-agent/agent/tcs/model/ecstcs"
 	"github.com/aws/amazon-ecs-agent/agent/utils"
@@ -68,6 +69,7 @@ func New(url string,
 		publishMetricsInterval: publishMetricsInterval,
 	}
 	cs.URL = url
+	cs.LatencyMetricsService = metrics.TCSService
 	cs.AgentConfig = cfg
 	cs.CredentialProvider = credentialProvider
 	cs.ServiceError = &tcsError{}
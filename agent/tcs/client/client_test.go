@@ -60,6 +60,14 @@ func (*mockStatsEngine) ContainerDockerStats(taskARN string, id string) (*types.
 	return nil, fmt.Errorf("not implemented")
 }
 
+func (*mockStatsEngine) ContainerNetworkRateStats(taskARN string, id string) (*stats.NetworkStatsPerSec, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (*mockStatsEngine) TaskNetworkStats(taskARN string) (*stats.NetworkStats, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
 func (*mockStatsEngine) GetTaskHealthMetrics() (*ecstcs.HealthMetadata, []*ecstcs.TaskHealth, error) {
 	return nil, nil, nil
 }
@@ -74,6 +82,14 @@ func (*emptyStatsEngine) ContainerDockerStats(taskARN string, id string) (*types
 	return nil, fmt.Errorf("not implemented")
 }
 
+func (*emptyStatsEngine) ContainerNetworkRateStats(taskARN string, id string) (*stats.NetworkStatsPerSec, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (*emptyStatsEngine) TaskNetworkStats(taskARN string) (*stats.NetworkStats, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
 func (*emptyStatsEngine) GetTaskHealthMetrics() (*ecstcs.HealthMetadata, []*ecstcs.TaskHealth, error) {
 	return nil, nil, nil
 }
@@ -94,6 +110,14 @@ func (*idleStatsEngine) ContainerDockerStats(taskARN string, id string) (*types.
 	return nil, fmt.Errorf("not implemented")
 }
 
+func (*idleStatsEngine) ContainerNetworkRateStats(taskARN string, id string) (*stats.NetworkStatsPerSec, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (*idleStatsEngine) TaskNetworkStats(taskARN string) (*stats.NetworkStats, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
 func (*idleStatsEngine) GetTaskHealthMetrics() (*ecstcs.HealthMetadata, []*ecstcs.TaskHealth, error) {
 	return nil, nil, nil
 }
@@ -122,6 +146,14 @@ func (*nonIdleStatsEngine) ContainerDockerStats(taskARN string, id string) (*typ
 	return nil, fmt.Errorf("not implemented")
 }
 
+func (*nonIdleStatsEngine) ContainerNetworkRateStats(taskARN string, id string) (*stats.NetworkStatsPerSec, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (*nonIdleStatsEngine) TaskNetworkStats(taskARN string) (*stats.NetworkStats, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
 func (*nonIdleStatsEngine) GetTaskHealthMetrics() (*ecstcs.HealthMetadata, []*ecstcs.TaskHealth, error) {
 	return nil, nil, nil
 }
@@ -33,10 +33,6 @@ import (
 )
 
 const (
-	// defaultPublishMetricsInterval is the interval at which utilization
-	// metrics from stats engine are published to the backend.
-	defaultPublishMetricsInterval = 20 * time.Second
-
 	// The maximum time to wait between heartbeats without disconnecting
 	defaultHeartbeatTimeout = 1 * time.Minute
 	defaultHeartbeatJitter  = 1 * time.Minute
@@ -98,7 +94,7 @@ func startTelemetrySession(params *TelemetrySessionParams, statsEngine stats.Eng
 	}
 	url := formatURL(tcsEndpoint, params.Cfg.Cluster, params.ContainerInstanceArn, params.TaskEngine)
 	return startSession(url, params.Cfg, params.CredentialProvider, statsEngine,
-		defaultHeartbeatTimeout, defaultHeartbeatJitter, defaultPublishMetricsInterval,
+		defaultHeartbeatTimeout, defaultHeartbeatJitter, params.Cfg.TelemetryPublishInterval,
 		params.DeregisterInstanceEventStream)
 }
 
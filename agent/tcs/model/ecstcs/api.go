@@ -122,6 +122,8 @@ type ContainerMetric struct {
 
 	CpuStatsSet *CWStatsSet `locationName:"cpuStatsSet" type:"structure"`
 
+	GpuStatsSet *GpuStatsSet `locationName:"gpuStatsSet" type:"structure"`
+
 	MemoryStatsSet *CWStatsSet `locationName:"memoryStatsSet" type:"structure"`
 
 	NetworkStatsSet *NetworkStatsSet `locationName:"networkStatsSet" type:"structure"`
@@ -159,6 +161,26 @@ func (s *ContainerMetric) Validate() error {
 	return nil
 }
 
+type GpuStatsSet struct {
+	_ struct{} `type:"structure"`
+
+	MemoryUtilization *CWStatsSet `locationName:"memoryUtilization" type:"structure"`
+
+	Temperature *CWStatsSet `locationName:"temperature" type:"structure"`
+
+	Utilization *CWStatsSet `locationName:"utilization" type:"structure"`
+}
+
+// String returns the string representation
+func (s GpuStatsSet) String() string {
+	return awsutil.Prettify(s)
+}
+
+// GoString returns the string representation
+func (s GpuStatsSet) GoString() string {
+	return s.String()
+}
+
 type HealthMetadata struct {
 	_ struct{} `type:"structure"`
 
@@ -582,8 +604,12 @@ func (s StopTelemetrySessionMessage) GoString() string {
 type StorageStatsSet struct {
 	_ struct{} `type:"structure"`
 
+	ReadOps *ULongStatsSet `locationName:"readOps" type:"structure"`
+
 	ReadSizeBytes *ULongStatsSet `locationName:"readSizeBytes" type:"structure"`
 
+	WriteOps *ULongStatsSet `locationName:"writeOps" type:"structure"`
+
 	WriteSizeBytes *ULongStatsSet `locationName:"writeSizeBytes" type:"structure"`
 }
 
@@ -600,11 +626,21 @@ func (s StorageStatsSet) GoString() string {
 // Validate inspects the fields of the type to determine if they are valid.
 func (s *StorageStatsSet) Validate() error {
 	invalidParams := request.ErrInvalidParams{Context: "StorageStatsSet"}
+	if s.ReadOps != nil {
+		if err := s.ReadOps.Validate(); err != nil {
+			invalidParams.AddNested("ReadOps", err.(request.ErrInvalidParams))
+		}
+	}
 	if s.ReadSizeBytes != nil {
 		if err := s.ReadSizeBytes.Validate(); err != nil {
 			invalidParams.AddNested("ReadSizeBytes", err.(request.ErrInvalidParams))
 		}
 	}
+	if s.WriteOps != nil {
+		if err := s.WriteOps.Validate(); err != nil {
+			invalidParams.AddNested("WriteOps", err.(request.ErrInvalidParams))
+		}
+	}
 	if s.WriteSizeBytes != nil {
 		if err := s.WriteSizeBytes.Validate(); err != nil {
 			invalidParams.AddNested("WriteSizeBytes", err.(request.ErrInvalidParams))
@@ -36,6 +36,7 @@ import (
 	"crypto/tls"
 
 	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/metrics"
 	"github.com/aws/amazon-ecs-agent/agent/utils"
 	"github.com/aws/amazon-ecs-agent/agent/utils/cipher"
 	"github.com/aws/amazon-ecs-agent/agent/wsclient/wsconn"
@@ -126,6 +127,9 @@ type ClientServerImpl struct {
 	MakeRequestHook MakeRequestHookFunc
 	// URL is the full url to the backend, including path, querystring, and so on.
 	URL string
+	// LatencyMetricsService is the service under which the latencies and the
+	// state of connections to the backend are recorded
+	LatencyMetricsService string
 	// RWTimeout is the duration used for setting read and write deadlines
 	// for the websocket connection
 	RWTimeout time.Duration
@@ -189,11 +193,16 @@ func (cs *ClientServerImpl) Connect() error {
 		WriteBufferSize:  writeBufSize,
 		TLSClientConfig:  tlsConfig,
 		Proxy:            http.ProxyFromEnvironment,
-		NetDial:          timeoutDialer.Dial,
+		NetDial:          cs.timedDial(timeoutDialer, parsedURL.Host),
 		HandshakeTimeout: wsHandshakeTimeout,
 	}
 
+	connectStart := time.Now()
 	websocketConn, httpResponse, err := dialer.Dial(parsedURL.String(), request.Header)
+	if err == nil {
+		metrics.MetricsEngineGlobal.RecordConnectLatency(cs.LatencyMetricsService, parsedURL.Host,
+			time.Since(connectStart))
+	}
 	if httpResponse != nil {
 		defer httpResponse.Body.Close()
 	}
@@ -222,10 +231,26 @@ func (cs *ClientServerImpl) Connect() error {
 	defer cs.writeLock.Unlock()
 
 	cs.conn = websocketConn
+	metrics.MetricsEngineGlobal.RecordConnectionState(cs.LatencyMetricsService, true)
 	seelog.Debugf("Established a Websocket connection to %s", cs.URL)
 	return nil
 }
 
+// timedDial returns a dial function that records the time taken by the TCP
+// handshake, which is a single round trip to the endpoint (or to the proxy in
+// front of it)
+func (cs *ClientServerImpl) timedDial(dialer *net.Dialer, endpoint string) func(network, addr string) (net.Conn, error) {
+	return func(network, addr string) (net.Conn, error) {
+		dialStart := time.Now()
+		conn, err := dialer.Dial(network, addr)
+		if err == nil {
+			metrics.MetricsEngineGlobal.RecordRoundTripLatency(cs.LatencyMetricsService, endpoint,
+				time.Since(dialStart))
+		}
+		return conn, err
+	}
+}
+
 // IsReady gives a boolean response that informs the caller if the websocket
 // connection is fully established.
 func (cs *ClientServerImpl) IsReady() bool {
@@ -293,6 +318,7 @@ func (cs *ClientServerImpl) Disconnect(...interface{}) error {
 	if cs.conn == nil {
 		return fmt.Errorf("websocker client: no connection to close")
 	}
+	metrics.MetricsEngineGlobal.RecordConnectionState(cs.LatencyMetricsService, false)
 
 	// Close() in turn results in a an internal flushFrame() call in gorilla
 	// as the close frame needs to be sent to the server. Set the deadline
@@ -383,12 +409,14 @@ func (cs *ClientServerImpl) ConsumeMessages() error {
 
 		case permissibleCloseCode(err):
 			seelog.Debugf("Connection closed for a valid reason: %s", err)
+			metrics.MetricsEngineGlobal.RecordConnectionState(cs.LatencyMetricsService, false)
 			return io.EOF
 
 		default:
 			// Unexpected error occurred
 			seelog.Errorf("Error getting message from ws backend: error: [%v], messageType: [%v] ",
 				err, messageType)
+			metrics.MetricsEngineGlobal.RecordConnectionState(cs.LatencyMetricsService, false)
 			return err
 		}
 